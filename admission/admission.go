@@ -0,0 +1,87 @@
+// Package admission bounds the amount of sites pushed by peers which are validated at once.
+// Sites arriving while all slots are taken wait in a bounded queue, further sites are turned away so peers retry later
+package admission
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/u-speak/core/metrics"
+)
+
+// ErrBusy is returned when the queue is full or no slot became free in time
+var ErrBusy = errors.New("Node is busy, try again later")
+
+// Controller hands out validation slots
+type Controller struct {
+	slots chan struct{}
+	// queue holds the active and the waiting sites, so its length bounds both together
+	queue chan struct{}
+	wait  time.Duration
+}
+
+// New returns a controller validating up to workers sites at once. Up to queue more sites wait at most for the wait duration
+func New(workers, queue int, wait time.Duration) *Controller {
+	if workers < 1 {
+		workers = 1
+	}
+	if queue < 0 {
+		queue = 0
+	}
+	return &Controller{slots: make(chan struct{}, workers), queue: make(chan struct{}, workers+queue), wait: wait}
+}
+
+// Admit waits for a free slot and returns the function releasing it. It fails with ErrBusy if the queue is full
+// or no slot became free within the wait duration, and with the error of the context if it is done first
+func (c *Controller) Admit(ctx context.Context) (func(), error) {
+	select {
+	case c.queue <- struct{}{}:
+	default:
+		metrics.AdmissionRejected.Inc()
+		return nil, ErrBusy
+	}
+	c.observe()
+	t := time.NewTimer(c.wait)
+	defer t.Stop()
+	select {
+	case c.slots <- struct{}{}:
+	case <-t.C:
+		<-c.queue
+		c.observe()
+		metrics.AdmissionRejected.Inc()
+		return nil, ErrBusy
+	case <-ctx.Done():
+		<-c.queue
+		c.observe()
+		return nil, ctx.Err()
+	}
+	c.observe()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			<-c.slots
+			<-c.queue
+			c.observe()
+		})
+	}, nil
+}
+
+// Waiting returns the amount of sites waiting for a slot
+func (c *Controller) Waiting() int {
+	if w := len(c.queue) - len(c.slots); w > 0 {
+		return w
+	}
+	return 0
+}
+
+// Active returns the amount of sites holding a slot
+func (c *Controller) Active() int {
+	return len(c.slots)
+}
+
+func (c *Controller) observe() {
+	metrics.AdmissionQueue.Set(float64(c.Waiting()))
+	metrics.AdmissionActive.Set(float64(c.Active()))
+}
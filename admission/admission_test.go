@@ -0,0 +1,51 @@
+package admission
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdmit(t *testing.T) {
+	c := New(1, 1, 50*time.Millisecond)
+	release, err := c.Admit(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, c.Active())
+
+	admitted := make(chan error)
+	go func() {
+		r, err := c.Admit(context.Background())
+		if err == nil {
+			r()
+		}
+		admitted <- err
+	}()
+	for c.Waiting() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	_, err = c.Admit(context.Background())
+	assert.Equal(t, ErrBusy, err)
+
+	release()
+	release()
+	assert.NoError(t, <-admitted)
+	assert.Equal(t, 0, c.Active())
+	assert.Equal(t, 0, c.Waiting())
+}
+
+func TestAdmitTimeout(t *testing.T) {
+	c := New(1, 4, 10*time.Millisecond)
+	release, err := c.Admit(context.Background())
+	assert.NoError(t, err)
+	defer release()
+	_, err = c.Admit(context.Background())
+	assert.Equal(t, ErrBusy, err)
+	assert.Equal(t, 0, c.Waiting())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = c.Admit(ctx)
+	assert.Equal(t, context.Canceled, err)
+}
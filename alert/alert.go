@@ -0,0 +1,101 @@
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Alert is a rule which is currently firing
+type Alert struct {
+	Name    string    `json:"name"`
+	Message string    `json:"message"`
+	Since   time.Time `json:"since"`
+}
+
+// Rule is checked on every evaluation. Check returns true and a description when the rule fires
+type Rule struct {
+	Name  string
+	Check func() (bool, string)
+}
+
+// Evaluator periodically checks a set of rules and keeps track of the firing alerts
+type Evaluator struct {
+	rules   []Rule
+	webhook string
+	client  *http.Client
+	firing  map[string]*Alert
+	lock    sync.RWMutex
+}
+
+type notification struct {
+	Status string `json:"status"`
+	Alert  Alert  `json:"alert"`
+}
+
+// New returns an evaluator for the rules, posting state changes to the webhook if it is not empty.
+// A notification is given up once the webhook did not respond within timeout
+func New(webhook string, timeout time.Duration, rules ...Rule) *Evaluator {
+	return &Evaluator{rules: rules, webhook: webhook, client: &http.Client{Timeout: timeout}, firing: make(map[string]*Alert)}
+}
+
+// Evaluate runs all rules once and notifies the webhook about new and resolved alerts
+func (e *Evaluator) Evaluate() {
+	e.lock.Lock()
+	changes := []notification{}
+	for _, r := range e.rules {
+		fires, msg := r.Check()
+		a, ok := e.firing[r.Name]
+		switch {
+		case fires && !ok:
+			a = &Alert{Name: r.Name, Message: msg, Since: time.Now()}
+			e.firing[r.Name] = a
+			changes = append(changes, notification{Status: "firing", Alert: *a})
+			log.Warnf("Alert %s firing: %s", r.Name, msg)
+		case fires:
+			a.Message = msg
+		case ok:
+			delete(e.firing, r.Name)
+			changes = append(changes, notification{Status: "resolved", Alert: *a})
+			log.Infof("Alert %s resolved", r.Name)
+		}
+	}
+	e.lock.Unlock()
+	for _, c := range changes {
+		e.notify(c)
+	}
+}
+
+// Firing returns all currently firing alerts
+func (e *Evaluator) Firing() []Alert {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	as := []Alert{}
+	for _, a := range e.firing {
+		as = append(as, *a)
+	}
+	sort.Slice(as, func(i, j int) bool { return as[i].Name < as[j].Name })
+	return as
+}
+
+func (e *Evaluator) notify(n notification) {
+	if e.webhook == "" {
+		return
+	}
+	b, err := json.Marshal(n)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	resp, err := e.client.Post(e.webhook, "application/json", bytes.NewReader(b))
+	if err != nil {
+		log.Errorf("Error calling alert webhook: %s", err)
+		return
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,60 @@
+package alert
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluate(t *testing.T) {
+	fire := false
+	e := New("", time.Second, Rule{Name: "test", Check: func() (bool, string) { return fire, "broken" }})
+	e.Evaluate()
+	assert.Empty(t, e.Firing())
+	fire = true
+	e.Evaluate()
+	assert.Len(t, e.Firing(), 1)
+	assert.Equal(t, "broken", e.Firing()[0].Message)
+	since := e.Firing()[0].Since
+	e.Evaluate()
+	assert.Equal(t, since, e.Firing()[0].Since)
+	fire = false
+	e.Evaluate()
+	assert.Empty(t, e.Firing())
+}
+
+func TestWebhook(t *testing.T) {
+	received := []notification{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := notification{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&n))
+		received = append(received, n)
+	}))
+	defer srv.Close()
+	fire := true
+	e := New(srv.URL, time.Second, Rule{Name: "test", Check: func() (bool, string) { return fire, "broken" }})
+	e.Evaluate()
+	e.Evaluate()
+	fire = false
+	e.Evaluate()
+	assert.Len(t, received, 2)
+	assert.Equal(t, "firing", received[0].Status)
+	assert.Equal(t, "resolved", received[1].Status)
+	assert.Equal(t, "test", received[1].Alert.Name)
+}
+
+func TestWebhookTimeout(t *testing.T) {
+	block := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { <-block }))
+	defer srv.Close()
+	defer close(block)
+	e := New(srv.URL, 50*time.Millisecond, Rule{Name: "test", Check: func() (bool, string) { return true, "broken" }})
+	start := time.Now()
+	e.Evaluate()
+	assert.True(t, time.Since(start) < time.Second)
+	assert.Len(t, e.Firing(), 1)
+}
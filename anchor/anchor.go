@@ -0,0 +1,222 @@
+package anchor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/coreos/bbolt"
+	"github.com/u-speak/core/config"
+	"github.com/u-speak/core/migrate"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// migrations upgrade the anchor database to the current format, see migrate.Open
+var migrations = []migrate.Migration{migrate.None}
+
+var (
+	anchorBucketName = []byte("anchors")
+	// coverBucketName maps every anchored site to the key of the oldest anchor covering it
+	coverBucketName = []byte("covered")
+	// ErrNotAnchored is returned when no anchor covers the requested site
+	ErrNotAnchored = errors.New("Site is not covered by any anchor")
+	// ErrUnknownBackend is returned when the configured backend does not exist
+	ErrUnknownBackend = errors.New("Unknown anchoring backend")
+)
+
+// Anchor is a published root of the tangle. The root is the hash over the sorted tips,
+// which transitively commit to every site they validate
+type Anchor struct {
+	Root    hash.Hash   `json:"root"`
+	Tips    []hash.Hash `json:"tips"`
+	Time    time.Time   `json:"time"`
+	Backend string      `json:"backend"`
+	Proof   []byte      `json:"proof"`
+}
+
+// Service periodically anchors the tangle and keeps the proofs
+type Service struct {
+	backend  Backend
+	tangle   *tangle.Tangle
+	db       *bolt.DB
+	interval int
+}
+
+// New returns the anchoring service configured with the selected backend
+func New(c config.Configuration, t *tangle.Tangle) (*Service, error) {
+	var b Backend
+	hc := &http.Client{Timeout: time.Duration(c.Anchor.Timeout) * time.Second}
+	switch c.Anchor.Backend {
+	case "opentimestamps":
+		b = &OpenTimestamps{Calendar: c.Anchor.Calendar, Client: hc}
+	case "ethereum":
+		b = &Ethereum{RPC: c.Anchor.Ethereum.RPC, From: c.Anchor.Ethereum.From, To: c.Anchor.Ethereum.To, Client: hc}
+	default:
+		return nil, ErrUnknownBackend
+	}
+	s, err := NewWithBackend(c.Storage.AnchorPath, b, t)
+	if err != nil {
+		return nil, err
+	}
+	s.interval = c.Anchor.Interval
+	return s, nil
+}
+
+// NewWithBackend returns a service storing its proofs at path
+func NewWithBackend(path string, b Backend, t *tangle.Tangle) (*Service, error) {
+	db, err := migrate.Open(path, 0644, migrations)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		ab, err := tx.CreateBucketIfNotExists(anchorBucketName)
+		if err != nil {
+			return err
+		}
+		if tx.Bucket(coverBucketName) != nil {
+			return nil
+		}
+		cb, err := tx.CreateBucket(coverBucketName)
+		if err != nil {
+			return err
+		}
+		// Anchors created before the coverage was recorded
+		return ab.ForEach(func(k, v []byte) error {
+			a := Anchor{}
+			if err := json.Unmarshal(v, &a); err != nil {
+				log.Error(err)
+				return nil
+			}
+			return cover(cb, t, a.Tips, k)
+		})
+	})
+	return &Service{backend: b, tangle: t, db: db}, err
+}
+
+// Interval returns the anchoring period in hours
+func (s *Service) Interval() int {
+	return s.interval
+}
+
+// Root computes the current root of the tangle
+func Root(tips []*site.Site) (hash.Hash, []hash.Hash) {
+	hs := []hash.Hash{}
+	for _, t := range tips {
+		hs = append(hs, t.Hash())
+	}
+	sort.Slice(hs, func(i, j int) bool { return bytes.Compare(hs[i][:], hs[j][:]) < 0 })
+	buff := []byte{}
+	for _, h := range hs {
+		buff = append(buff, h.Slice()...)
+	}
+	return hash.New(buff), hs
+}
+
+// Anchor publishes the current root and stores the proof
+func (s *Service) Anchor() (*Anchor, error) {
+	root, tips := Root(s.tangle.Tips())
+	proof, err := s.backend.Publish(root)
+	if err != nil {
+		return nil, err
+	}
+	a := &Anchor{Root: root, Tips: tips, Time: time.Now(), Backend: s.backend.Name(), Proof: proof}
+	b, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(a.Time.UnixNano()))
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(anchorBucketName).Put(key, b); err != nil {
+			return err
+		}
+		return cover(tx.Bucket(coverBucketName), s.tangle, tips, key)
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Anchored tangle root %s using %s", root, a.Backend)
+	return a, nil
+}
+
+// List returns all anchors, oldest first
+func (s *Service) List() []Anchor {
+	as := []Anchor{}
+	_ = s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(anchorBucketName).ForEach(func(_, v []byte) error {
+			a := Anchor{}
+			if err := json.Unmarshal(v, &a); err != nil {
+				log.Error(err)
+				return nil
+			}
+			as = append(as, a)
+			return nil
+		})
+	})
+	return as
+}
+
+// Verify returns the oldest anchor whose tips validate the site, directly or indirectly
+func (s *Service) Verify(h hash.Hash) (*Anchor, error) {
+	var a *Anchor
+	err := s.db.View(func(tx *bolt.Tx) error {
+		key := tx.Bucket(coverBucketName).Get(h.Slice())
+		if key == nil {
+			return ErrNotAnchored
+		}
+		v := tx.Bucket(anchorBucketName).Get(key)
+		if v == nil {
+			return ErrNotAnchored
+		}
+		a = &Anchor{}
+		return json.Unmarshal(v, a)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// cover records the anchor with the key for all sites validated by the tips which are not covered by an older anchor.
+// Sites validated by a covered site are covered by the same or an older anchor, so the walk stops there
+func cover(b *bolt.Bucket, t *tangle.Tangle, tips []hash.Hash, key []byte) error {
+	bound := []hash.Hash{}
+	for _, h := range tips {
+		if b.Get(h.Slice()) == nil {
+			bound = append(bound, h)
+		}
+	}
+	for len(bound) > 0 {
+		h := bound[len(bound)-1]
+		bound = bound[:len(bound)-1]
+		if b.Get(h.Slice()) != nil {
+			continue
+		}
+		if err := b.Put(h.Slice(), key); err != nil {
+			return err
+		}
+		st := t.GetSite(h)
+		if st == nil {
+			continue
+		}
+		for _, v := range st.Validates {
+			if vh := v.Hash(); b.Get(vh.Slice()) == nil {
+				bound = append(bound, vh)
+			}
+		}
+	}
+	return nil
+}
+
+// Close closes the proof database
+func (s *Service) Close() {
+	_ = s.db.Close()
+}
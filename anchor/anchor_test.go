@@ -0,0 +1,84 @@
+package anchor
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/coreos/bbolt"
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/img"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+	"github.com/u-speak/core/tangle/store"
+	"github.com/u-speak/core/tangle/store/memorystore"
+)
+
+type fakeBackend struct {
+	published []hash.Hash
+}
+
+func (f *fakeBackend) Name() string { return "fake" }
+func (f *fakeBackend) Publish(root hash.Hash) ([]byte, error) {
+	f.published = append(f.published, root)
+	return root.Slice(), nil
+}
+
+func TestAnchorVerify(t *testing.T) {
+	ms := &memorystore.MemoryStore{}
+	_ = ms.Init(store.Options{})
+	dp := path.Join(os.TempDir(), "testAnchorData.db")
+	defer os.Remove(dp)
+	tngl, err := tangle.New(tangle.Options{Store: ms, DataPath: dp})
+	assert.NoError(t, err)
+	defer tngl.Close()
+	ap := path.Join(os.TempDir(), "testAnchor.db")
+	defer os.Remove(ap)
+	fb := &fakeBackend{}
+	s, err := NewWithBackend(ap, fb, tngl)
+	assert.NoError(t, err)
+
+	gen := tngl.Tips()
+	a, err := s.Anchor()
+	assert.NoError(t, err)
+	assert.Equal(t, []hash.Hash{a.Root}, fb.published)
+
+	i := &img.Image{Raw: []byte{1, 3, 3, 7}}
+	ih, _ := i.Hash()
+	s1 := &site.Site{Content: ih, Validates: gen, Type: "image"}
+	s1.Mine(1)
+	assert.NoError(t, tngl.Inject(&tangle.Object{Site: s1, Data: i}, true))
+	_, err = s.Verify(s1.Hash())
+	assert.Equal(t, ErrNotAnchored, err)
+
+	a2, err := s.Anchor()
+	assert.NoError(t, err)
+	assert.NotEqual(t, a.Root, a2.Root)
+	v, err := s.Verify(s1.Hash())
+	assert.NoError(t, err)
+	assert.Equal(t, a2.Root, v.Root)
+	v, err = s.Verify(gen[0].Hash())
+	assert.NoError(t, err)
+	assert.Equal(t, a.Root, v.Root)
+	assert.Len(t, s.List(), 2)
+
+	_, err = s.Anchor()
+	assert.NoError(t, err)
+	v, err = s.Verify(s1.Hash())
+	assert.NoError(t, err)
+	assert.Equal(t, a2.Root, v.Root)
+
+	// Databases written before the coverage was recorded
+	assert.NoError(t, s.db.Update(func(tx *bolt.Tx) error { return tx.DeleteBucket(coverBucketName) }))
+	s.Close()
+	s, err = NewWithBackend(ap, fb, tngl)
+	assert.NoError(t, err)
+	defer s.Close()
+	v, err = s.Verify(s1.Hash())
+	assert.NoError(t, err)
+	assert.Equal(t, a2.Root, v.Root)
+	v, err = s.Verify(gen[0].Hash())
+	assert.NoError(t, err)
+	assert.Equal(t, a.Root, v.Root)
+}
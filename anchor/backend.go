@@ -0,0 +1,99 @@
+package anchor
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/u-speak/core/tangle/hash"
+)
+
+// Backend publishes a root hash to an external system and returns the proof
+type Backend interface {
+	Name() string
+	Publish(root hash.Hash) ([]byte, error)
+}
+
+// OpenTimestamps submits the root to an OpenTimestamps calendar server.
+// The proof is the (pending) timestamp returned by the calendar
+type OpenTimestamps struct {
+	Calendar string
+	// Client sends the request, http.DefaultClient if nil
+	Client *http.Client
+}
+
+// Name implements Backend
+func (o *OpenTimestamps) Name() string { return "opentimestamps" }
+
+// Publish implements Backend
+func (o *OpenTimestamps) Publish(root hash.Hash) ([]byte, error) {
+	resp, err := client(o.Client).Post(strings.TrimSuffix(o.Calendar, "/")+"/digest", "application/octet-stream", bytes.NewReader(root.Slice()))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("Calendar returned " + resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// Ethereum stores the root as transaction data using the JSON-RPC interface of an ethereum node.
+// The From account has to be unlocked on the node. The proof is the transaction hash
+type Ethereum struct {
+	RPC  string
+	From string
+	To   string
+	// Client sends the request, http.DefaultClient if nil
+	Client *http.Client
+}
+
+// Name implements Backend
+func (e *Ethereum) Name() string { return "ethereum" }
+
+// Publish implements Backend
+func (e *Ethereum) Publish(root hash.Hash) ([]byte, error) {
+	req, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "eth_sendTransaction",
+		"params": []map[string]string{{
+			"from": e.From,
+			"to":   e.To,
+			"data": "0x" + hex.EncodeToString(root.Slice()),
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client(e.Client).Post(e.RPC, "application/json", bytes.NewReader(req))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	res := struct {
+		Result string `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}{}
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, err
+	}
+	if res.Error != nil {
+		return nil, errors.New(res.Error.Message)
+	}
+	return []byte(res.Result), nil
+}
+
+func client(c *http.Client) *http.Client {
+	if c == nil {
+		return http.DefaultClient
+	}
+	return c
+}
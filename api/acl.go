@@ -0,0 +1,38 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/peer"
+)
+
+func (a *API) getACL(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string][]string{
+		peer.Allow: a.node.ACL.List(peer.Allow),
+		peer.Deny:  a.node.ACL.List(peer.Deny),
+	})
+}
+
+func (a *API) addACL(c echo.Context) error {
+	e := struct {
+		Entry string `json:"entry" form:"entry"`
+	}{}
+	if err := c.Bind(&e); err != nil {
+		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	}
+	if err := a.node.ACL.Add(c.Param("list"), e.Entry); err != nil {
+		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	}
+	a.node.Fence()
+	return c.NoContent(http.StatusCreated)
+}
+
+// removeACL takes the entry as query parameter, since networks contain slashes
+func (a *API) removeACL(c echo.Context) error {
+	if err := a.node.ACL.Remove(c.Param("list"), c.QueryParam("entry")); err != nil {
+		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	}
+	a.node.Fence()
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/anchor"
+)
+
+func (a *API) getAnchors(c echo.Context) error {
+	if a.node.Anchors == nil {
+		return fail(c, http.StatusNotFound, "anchoring_disabled")
+	}
+	return c.JSON(http.StatusOK, a.node.Anchors.List())
+}
+
+func (a *API) verifyAnchor(c echo.Context) error {
+	if a.node.Anchors == nil {
+		return fail(c, http.StatusNotFound, "anchoring_disabled")
+	}
+	h, err := DecodeHash(c.Param("hash"))
+	if err != nil {
+		return fail(c, http.StatusBadRequest, "invalid_base64")
+	}
+	an, err := a.node.Anchors.Verify(h)
+	if err == anchor.ErrNotAnchored {
+		return c.JSON(http.StatusNotFound, Error{Message: err.Error(), Code: http.StatusNotFound})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Error{Message: err.Error(), Code: http.StatusInternalServerError})
+	}
+	return c.JSON(http.StatusOK, an)
+}
+
+func (a *API) addAnchor(c echo.Context) error {
+	if a.node.Anchors == nil {
+		return fail(c, http.StatusNotFound, "anchoring_disabled")
+	}
+	an, err := a.node.Anchors.Anchor()
+	if err != nil {
+		return c.JSON(http.StatusBadGateway, Error{Message: err.Error(), Code: http.StatusBadGateway})
+	}
+	return c.JSON(http.StatusCreated, an)
+}
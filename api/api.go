@@ -5,21 +5,34 @@ import (
 	"io"
 	"math/rand"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"image/jpeg"
 	"image/png"
 
 	"github.com/labstack/echo"
 	"github.com/labstack/echo/middleware"
+	"github.com/u-speak/core/challenge"
 	"github.com/u-speak/core/config"
+	"github.com/u-speak/core/decision"
 	"github.com/u-speak/core/img"
+	"github.com/u-speak/core/metrics"
 	"github.com/u-speak/core/node"
-	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/preview"
+	"github.com/u-speak/core/ranking"
+	"github.com/u-speak/core/ratelimit"
+	"github.com/u-speak/core/recovery"
+	"github.com/u-speak/core/subscription"
 	"github.com/u-speak/core/tangle"
 	"github.com/u-speak/core/tangle/datastore"
 	"github.com/u-speak/core/tangle/site"
+	"github.com/u-speak/core/tombstone"
+	"github.com/u-speak/core/tsa"
+	"github.com/u-speak/core/validate"
+	"github.com/u-speak/core/watchdog"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/u-speak/logrusmiddleware"
@@ -28,6 +41,12 @@ import (
 const (
 	// MaxLatest is the highest limit amount for getRandom
 	MaxLatest = 100
+	// MaxFeedLimit is the largest page size of the feed
+	MaxFeedLimit = 100
+	// MaxBatch is the largest amount of sites fetched in a single batch request
+	MaxBatch = 100
+	// MaxLimitedClients is the amount of clients whose requests are counted by each rate limit
+	MaxLimitedClients = 10000
 )
 
 // API is used as a container, allowing the REST API to access the node
@@ -40,12 +59,23 @@ type API struct {
 	adminEnabled    bool
 	user            string
 	password        string
+	ranker          *ranking.Ranker
+	defaultRanking  string
+	tailTimeout     time.Duration
+	challenges      *challenge.Issuer
+	policy          *Policy
+	config          config.Configuration
+	validateLimit   *ratelimit.Limiter
+	custodyLimit    *ratelimit.Limiter
+	subscribeLimit  *ratelimit.Limiter
+	keyLimit        *ratelimit.Limiter
 }
 
 // Error is returned when something has gone wrong
 type Error struct {
 	Message string `json:"message"`
 	Code    int    `json:"code"`
+	Key     string `json:"key,omitempty"`
 }
 
 type jsonSite struct {
@@ -54,21 +84,37 @@ type jsonSite struct {
 	Hash         string                 `json:"hash"`
 	Content      string                 `json:"content"`
 	Type         string                 `json:"type"`
+	Timestamp    int64                  `json:"timestamp,omitempty"`
 	BubbleBabble string                 `json:"bubblebabble"`
 	Weight       int                    `json:"weight"`
 	Data         datastore.Serializable `json:"data"`
+	Preview      *preview.Preview       `json:"preview,omitempty"`
+	Lang         string                 `json:"lang,omitempty"`
+	Flags        []string               `json:"flags,omitempty"`
 }
 
 // New returns a configured instance of the API server
 func New(c config.Configuration, n *node.Node) *API {
 	a := &API{
-		node:         n,
-		keyfile:      c.Global.SSLKey,
-		certfile:     c.Global.SSLCert,
-		Message:      c.Global.Message,
-		adminEnabled: c.Web.API.AdminEnabled,
-		user:         c.Web.API.AdminUser,
-		password:     c.Web.API.AdminPassword,
+		node:           n,
+		keyfile:        c.Global.SSLKey,
+		certfile:       c.Global.SSLCert,
+		Message:        c.Global.Message,
+		adminEnabled:   c.Web.API.AdminEnabled,
+		user:           c.Web.API.AdminUser,
+		password:       c.Web.API.AdminPassword,
+		ranker:         ranking.New(c, n.Tangle),
+		defaultRanking: c.Ranking.Default,
+		tailTimeout:    time.Duration(c.Tail.Timeout) * time.Second,
+		policy:         newPolicy(c, n.Tangle),
+		config:         c,
+		validateLimit:  ratelimit.New(c.Web.API.ValidateLimit, time.Minute, MaxLimitedClients),
+		custodyLimit:   ratelimit.New(c.Custody.ClientLimit, time.Minute, MaxLimitedClients),
+		subscribeLimit: ratelimit.New(c.Digest.SubscribeLimit, time.Minute, MaxLimitedClients),
+		keyLimit:       ratelimit.New(c.Web.API.KeyLimit, time.Minute, MaxLimitedClients),
+	}
+	if c.Challenge.Enabled {
+		a.challenges = challenge.New(c.Challenge.Difficulty, c.Challenge.MaxDifficulty, time.Duration(c.Challenge.TTL)*time.Second, c.Challenge.LoadThreshold)
 	}
 	a.ListenInterface = c.Web.API.Interface + ":" + strconv.Itoa(c.Web.API.Port)
 	return a
@@ -76,6 +122,13 @@ func New(c config.Configuration, n *node.Node) *API {
 
 // Run starts the API server as specified in the configuration
 func (a *API) Run() error {
+	e := a.server()
+	log.Infof("Starting API Server on interface %s", a.ListenInterface)
+	return e.StartTLS(a.ListenInterface, a.certfile, a.keyfile)
+}
+
+// server returns the echo instance serving all routes
+func (a *API) server() *echo.Echo {
 	e := echo.New()
 	e.HideBanner = true
 	e.HidePort = true
@@ -84,7 +137,7 @@ func (a *API) Run() error {
 		Skipper:       middleware.DefaultSkipper,
 		AllowOrigins:  []string{"*"},
 		AllowMethods:  []string{echo.GET, echo.HEAD, echo.PUT, echo.PATCH, echo.POST, echo.DELETE},
-		ExposeHeaders: []string{"X-Server-Message"},
+		ExposeHeaders: []string{"X-Server-Message", echo.HeaderLocation, "Tus-Resumable", "Upload-Offset", "Upload-Length"},
 	}))
 
 	serverMessage := func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -94,159 +147,390 @@ func (a *API) Run() error {
 		}
 	}
 
+	e.Use(recovery.Echo("api"))
 	e.Use(serverMessage)
+	e.Use(compress)
+
+	e.GET("/metrics", echo.WrapHandler(metrics.Handler()))
 
-	apiV1 := e.Group("/api/v1")
+	apiV1 := e.Group("/api/v1", projectFields)
 	apiV1.GET("/status", a.getStatus)
+	apiV1.GET("/version", a.getVersion)
+	apiV1.GET("/node/policy", a.getPolicy)
+	apiV1.GET("/node/genesis", a.getGenesis)
+	apiV1.GET("/network", a.getNetwork)
+	apiV1.GET("/alerts", a.getAlerts)
+	apiV1.GET("/messages", a.getMessages)
 	apiV1.POST("/image", a.uploadImage)
 	apiV1.GET("/image/:hash", a.getImage)
+	apiV1.GET("/image/:hash/variants", a.getVariants)
+	apiV1.GET("/media/:hash", a.getMedia)
+	apiV1.POST("/uploads", a.createUpload)
+	apiV1.HEAD("/uploads/:id", a.headUpload)
+	apiV1.PATCH("/uploads/:id", a.patchUpload)
+	apiV1.DELETE("/uploads/:id", a.deleteUpload)
+	apiV1.GET("/data/:type/:content", a.getData)
+	apiV1.GET("/feed", a.getFeed)
+	apiV1.GET("/timeline/:fingerprint", a.getTimeline)
+	apiV1.POST("/timeline", a.postTimeline)
+	apiV1.GET("/identities/:fingerprint/following", a.getFollowing)
+	apiV1.GET("/identities/:fingerprint/followers", a.getFollowers)
+	apiV1.POST("/identities/:fingerprint/export", a.exportIdentity)
+	apiV1.GET("/identities/:fingerprint/key", a.getKey, limited(a.keyLimit))
+	apiV1.GET("/anchors", a.getAnchors)
+	apiV1.GET("/anchors/verify/:hash", a.verifyAnchor)
+	apiV1.GET("/challenge", a.getChallenge)
+	custodyLimit := limited(a.custodyLimit)
+	apiV1.POST("/custody/keys", a.importKey, custodyLimit)
+	apiV1.POST("/custody/keys/:fingerprint/export", a.exportKey, custodyLimit)
+	apiV1.POST("/custody/keys/:fingerprint/sign", a.signPost, custodyLimit)
+	apiV1.DELETE("/custody/keys/:fingerprint", a.removeKey, custodyLimit)
+	apiV1.POST("/digest/subscribe", a.addSubscriber, limited(a.subscribeLimit))
+	apiV1.GET("/digest/confirm/:token", a.confirmSubscriber)
+	apiV1.GET("/explorer/address/:keyid", a.getExplorerAddress)
+	apiV1.GET("/explorer/block/:hash", a.getExplorerBlock)
+	apiV1.GET("/pending", a.getPending)
+	apiV1.GET("/pins", a.getPins)
+	apiV1.POST("/pins", a.postPin)
+	apiV1.GET("/random", a.getRandomSite)
+	apiV1.GET("/submissions/:id", a.getSubmission)
 	apiV1.GET("/tangle", a.getSearch)
+	apiV1.GET("/testvectors", a.getTestVectors)
+	apiV1.GET("/testvectors/verify", a.verifyTestVectors)
+	apiV1.POST("/validate", a.postValidate, limited(a.validateLimit))
 	apiV1.GET("/tangle/random", a.getRandom)
+	apiV1.GET("/tangle/tail", a.getTail)
+	apiV1.POST("/tangle/batch", a.getBatch)
+	apiV1.GET("/tangle/exists", a.getExists)
+	apiV1.GET("/tangle/tips", a.getTips)
+	apiV1.GET("/tangle/types/:type", a.getRange)
 	apiV1.GET("/tangle/:hash", a.getSite)
+	apiV1.HEAD("/tangle/:hash", a.headSite)
+	apiV1.GET("/tangle/:hash/conflict", a.getConflict)
+	apiV1.GET("/tangle/:hash/receipt", a.getReceipt)
+	apiV1.GET("/tangle/:hash/timestamp", a.getTimestamp)
+	apiV1.GET("/tangle/:hash/tombstone", a.getTombstone)
+	apiV1.GET("/tangle/:hash/verification", a.getVerification)
 	apiV1.POST("/tangle/:hash", a.addSite)
-	log.Infof("Starting API Server on interface %s", a.ListenInterface)
-	return e.StartTLS(a.ListenInterface, a.certfile, a.keyfile)
+
+	apiV2 := e.Group("/api/v2", projectFields)
+	apiV2.GET("/tangle/:hash", a.getSiteV2)
+
+	if a.adminEnabled {
+		admin := e.Group("/admin", middleware.BasicAuth(func(u, p string, c echo.Context) (bool, error) {
+			return u == a.user && p == a.password, nil
+		}))
+		admin.GET("/digest/subscribers", a.getSubscribers)
+		admin.POST("/digest/subscribers", a.addSubscriber)
+		admin.DELETE("/digest/subscribers/:address", a.deleteSubscriber)
+		admin.POST("/sql", a.querySQL)
+		admin.GET("/acl", a.getACL)
+		admin.GET("/config", a.getConfig)
+		admin.GET("/decisions", a.getDecisions)
+		admin.GET("/doctor", a.getDoctor)
+		admin.GET("/loglevel", a.getLogLevel)
+		admin.PUT("/loglevel", a.putLogLevel)
+		admin.GET("/maintenance", a.getMaintenance)
+		admin.PUT("/maintenance", a.putMaintenance)
+		admin.GET("/schedule", a.getSchedule)
+		admin.POST("/schedule/:task", a.runTask)
+		admin.POST("/acl/:list", a.addACL)
+		admin.DELETE("/acl/:list", a.removeACL)
+		admin.POST("/anchors", a.addAnchor)
+		admin.GET("/export", a.getExport)
+		admin.GET("/export/hashes", a.getExportHashes)
+		admin.GET("/export/sites", a.getExportSites)
+		admin.GET("/export/warc", a.getExportWARC)
+		admin.POST("/replay", a.postReplay)
+		admin.GET("/repair", a.getRepair)
+		admin.POST("/repair", a.postRepair)
+		admin.GET("/indexes", a.getIndexes)
+		admin.POST("/indexes", a.postIndexes)
+		admin.GET("/hidden", a.getHidden)
+		admin.POST("/hidden", a.addHidden)
+		admin.POST("/hidden/import", a.importHidden)
+		admin.GET("/hidden/audit", a.getHiddenAudit)
+		admin.DELETE("/hidden/:hash", a.removeHidden)
+		admin.GET("/diff", a.getDiff)
+		admin.GET("/sync/sources", a.getSources)
+		admin.PUT("/sync/source", a.putSource)
+		admin.DELETE("/sync/source", a.deleteSource)
+		admin.POST("/pins/:hash", a.addPin)
+		admin.DELETE("/pins/:hash", a.removePin)
+		admin.POST("/tombstones/:hash", a.removeSite)
+	}
+	return e
 }
 
 func (a *API) getStatus(c echo.Context) error {
 	return c.JSON(http.StatusOK, a.node.Status())
 }
 
+func (a *API) getAlerts(c echo.Context) error {
+	return c.JSON(http.StatusOK, a.node.Alerts.Firing())
+}
+
+func (a *API) querySQL(c echo.Context) error {
+	if a.node.SQLIndex == nil {
+		return fail(c, http.StatusNotFound, "sql_index_disabled")
+	}
+	q := struct {
+		Query string `json:"query" form:"query"`
+	}{}
+	if err := c.Bind(&q); err != nil {
+		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	}
+	r, err := a.node.SQLIndex.Query(c.Request().Context(), q.Query)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	}
+	return c.JSON(http.StatusOK, r)
+}
+
 func (a *API) getSite(c echo.Context) error {
 	h, err := DecodeHash(c.Param("hash"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, Error{Message: "Invalid base64 data", Code: http.StatusBadRequest})
+		return fail(c, http.StatusBadRequest, "invalid_base64")
 	}
 	s := a.node.Tangle.Get(h)
-	if s == nil {
-		return c.JSON(http.StatusNotFound, Error{Message: "Site not found", Code: http.StatusNotFound})
+	if s == nil || a.hidden(h) {
+		if p := a.pending(h); p != nil {
+			return c.JSON(http.StatusAccepted, p)
+		}
+		return a.notFound(c, h)
 	}
 	err = s.Data.JSON()
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, Error{Message: "Error preparing response", Code: http.StatusInternalServerError})
+		return fail(c, http.StatusInternalServerError, "response_error")
 	}
 	j := JSONize(s)
 	j.Weight = a.node.Tangle.Weight(s.Site)
+	j.Flags = a.flags(h)
 	return c.JSON(http.StatusOK, j)
 }
 
+func (a *API) getTimestamp(c echo.Context) error {
+	if a.node.Timestamps == nil {
+		return fail(c, http.StatusNotFound, "timestamping_disabled")
+	}
+	h, err := DecodeHash(c.Param("hash"))
+	if err != nil {
+		return fail(c, http.StatusBadRequest, "invalid_base64")
+	}
+	token, err := a.node.Timestamps.Token(h)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, Error{Message: err.Error(), Code: http.StatusNotFound})
+	}
+	return c.JSON(http.StatusOK, struct {
+		Hash   string `json:"hash"`
+		Digest []byte `json:"digest"`
+		Token  []byte `json:"token"`
+	}{Hash: h.String(), Digest: tsa.Digest(h), Token: token})
+}
+
+func (a *API) getVerification(c echo.Context) error {
+	h, err := DecodeHash(c.Param("hash"))
+	if err != nil {
+		return fail(c, http.StatusBadRequest, "invalid_base64")
+	}
+	s := a.node.Tangle.Get(h)
+	if s == nil || a.hidden(h) {
+		return fail(c, http.StatusNotFound, "site_not_found")
+	}
+	b, err := s.Bundle(a.node.Tangle.PoW())
+	if err != nil {
+		return fail(c, http.StatusInternalServerError, "response_error")
+	}
+	j := JSONize(s)
+	j.Weight = a.node.Tangle.Weight(s.Site)
+	return c.JSON(http.StatusOK, struct {
+		Site         jsonSite       `json:"site"`
+		Verification *tangle.Bundle `json:"verification"`
+	}{Site: j, Verification: b})
+}
+
 func (a *API) addSite(c echo.Context) error {
+	tr := a.node.Decisions.Begin(decision.SourceAPI)
 	s := new(jsonSite)
-	switch c.Param("hash") {
-	case "post":
-		s.Data = &post.Post{}
-	case "image":
-		s.Data = &img.Image{}
-	default:
-		return c.JSON(http.StatusBadRequest, Error{Message: "Invalid type parameter: " + c.Param("hash"), Code: http.StatusInternalServerError})
+	if s.Data = newPayload(c.Param("hash")); s.Data == nil {
+		return reject(c, tr, "type", http.StatusBadRequest, "invalid_type", c.Param("hash"))
 	}
 	if err := c.Bind(s); err != nil {
-		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
-	}
-	if err := s.Data.ReInit(); err != nil {
-		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+		return rejectErr(c, tr, "decode", http.StatusBadRequest, err)
 	}
 	sh, err := DecodeHash(s.Hash)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, Error{Message: "Could not decode provided hash", Code: http.StatusBadRequest})
+		return reject(c, tr, "hash", http.StatusBadRequest, "undecodable_hash")
+	}
+	tr.SetHash(sh)
+	if err := validate.Site(s.Nonce, s.Type, s.Data); err != nil {
+		return rejectErr(c, tr, "fields", http.StatusBadRequest, err)
 	}
+	if err := s.Data.ReInit(); err != nil {
+		return rejectErr(c, tr, "payload", http.StatusBadRequest, err)
+	}
+	if sub, ok := s.Data.(*subscription.Subscription); ok {
+		if _, err := sub.Address(); err == nil {
+			return reject(c, tr, "payload", http.StatusBadRequest, "subscription_address")
+		}
+	}
+	tr.Check("payload", nil)
+	async := c.QueryParam("async") == "true"
+	var check func() error
 	switch c.Param("hash") {
-	case "post":
-		err := verifyGPG(s.Data)
-		if err != nil {
-			return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	case "post", "subscription", "follow", "tombstone":
+		check = func() error { return verifyGPG(s.Data) }
+		if !async {
+			err := check()
+			if err != nil {
+				return rejectErr(c, tr, "signature", http.StatusBadRequest, err)
+			}
+			tr.Check("signature", nil)
+		}
+	}
+	if ts, ok := s.Data.(*tombstone.Tombstone); ok {
+		if err := a.authorize(ts); err != nil {
+			return rejectErr(c, tr, "authorization", http.StatusForbidden, err)
 		}
+		tr.Check("authorization", nil)
 	}
 	o := &tangle.Object{Data: s.Data}
 	ch, err := DecodeHash(s.Content)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, Error{Message: "Could not decode content hash", Code: http.StatusBadRequest})
+		return reject(c, tr, "content", http.StatusBadRequest, "invalid_content_hash")
 	}
 	dh, err := o.Data.Hash()
 	if err != nil || ch != dh {
 		log.Error(err)
-		return c.JSON(http.StatusBadRequest, Error{Message: "Content did not match supplied hash", Code: http.StatusBadRequest})
+		return reject(c, tr, "content", http.StatusBadRequest, "content_mismatch")
 	}
-	o.Site = &site.Site{Nonce: s.Nonce, Content: ch, Type: s.Type, Validates: []*site.Site{}}
+	tr.Check("content", nil)
+	o.Site = &site.Site{Nonce: s.Nonce, Content: ch, Type: s.Type, Timestamp: s.Timestamp, Validates: []*site.Site{}}
 	for _, b64 := range s.Validates {
 		h, err := DecodeHash(b64)
 		if err != nil {
-			return c.JSON(http.StatusBadRequest, Error{Message: "Invalid hash in validations: " + b64, Code: http.StatusBadRequest})
+			return reject(c, tr, "validations", http.StatusBadRequest, "invalid_validation", b64)
 		}
-		v := a.node.Tangle.Get(h)
+		v := a.node.Tangle.GetSite(h)
 		if v == nil {
-			return c.JSON(http.StatusBadRequest, Error{Message: "Tried to verify unknown site " + b64, Code: http.StatusBadRequest})
+			return reject(c, tr, "validations", http.StatusBadRequest, "unknown_validation", b64)
 		}
-		o.Site.Validates = append(o.Site.Validates, v.Site)
+		o.Site.Validates = append(o.Site.Validates, v)
 	}
+	tr.Check("validations", nil)
 	if o.Site.Hash() != sh {
-		return c.JSON(http.StatusBadRequest, Error{Message: "Provided hash does not match", Code: http.StatusBadRequest})
+		return reject(c, tr, "hash", http.StatusBadRequest, "hash_mismatch")
 	}
-	err = a.node.Submit(o)
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	tr.Check("hash", nil)
+	if err := a.checkChallenge(c, sh); err != nil {
+		return rejectErr(c, tr, "challenge", http.StatusForbidden, err)
 	}
-	return c.NoContent(http.StatusAccepted)
+	if async {
+		return a.submitAsync(c, o, check, tr)
+	}
+	return a.submit(c, o, tr)
 }
 
 func (a *API) uploadImage(c echo.Context) error {
+	tr := a.node.Decisions.Begin(decision.SourceAPI)
+	file, err := c.FormFile("image")
+	if err != nil {
+		return reject(c, tr, "payload", http.StatusBadRequest, "image_not_found")
+	}
+	src, err := file.Open()
+	if err != nil {
+		return reject(c, tr, "payload", http.StatusBadRequest, "image_unprocessable")
+	}
+	defer src.Close()
+
+	buff := bytes.NewBuffer([]byte{})
+	io.Copy(buff, src)
+	if buff.Len() >= node.MaxMsgSize {
+		return reject(c, tr, "payload", http.StatusBadRequest, "image_too_large")
+	}
+	return a.submitImage(c, tr, c.FormValue, buff.Bytes(), c.QueryParam("async") == "true")
+}
+
+// submitImage builds the image site from the form fields and the raw image, verifies it and submits it
+func (a *API) submitImage(c echo.Context, tr *decision.Trace, field func(string) string, raw []byte, async bool) error {
 	o := &tangle.Object{Site: &site.Site{}}
-	nonce, err := strconv.ParseUint(c.FormValue("nonce"), 10, 64)
+	nonce, err := strconv.ParseUint(field("nonce"), 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+		return rejectErr(c, tr, "fields", http.StatusBadRequest, err)
+	}
+	if err := validate.Nonce(nonce); err != nil {
+		return rejectErr(c, tr, "fields", http.StatusBadRequest, err)
 	}
 	o.Site.Nonce = nonce
 	o.Site.Type = "image"
+	if ts := field("timestamp"); ts != "" {
+		if o.Site.Timestamp, err = strconv.ParseInt(ts, 10, 64); err != nil {
+			return rejectErr(c, tr, "fields", http.StatusBadRequest, err)
+		}
+	}
 
-	vls := strings.Split(c.FormValue("validates"), ",")
+	vls := strings.Split(field("validates"), ",")
 	for _, b64 := range vls {
 		h, err := DecodeHash(b64)
 		if err != nil {
-			return c.JSON(http.StatusBadRequest, Error{Message: "Invalid hash in validations: " + b64, Code: http.StatusBadRequest})
+			return reject(c, tr, "validations", http.StatusBadRequest, "invalid_validation", b64)
 		}
-		v := a.node.Tangle.Get(h)
+		v := a.node.Tangle.GetSite(h)
 		if v == nil {
-			return c.JSON(http.StatusBadRequest, Error{Message: "Tried to verify unknown site " + b64, Code: http.StatusBadRequest})
+			return reject(c, tr, "validations", http.StatusBadRequest, "unknown_validation", b64)
 		}
-		o.Site.Validates = append(o.Site.Validates, v.Site)
+		o.Site.Validates = append(o.Site.Validates, v)
 	}
-	rh, err := DecodeHash(c.FormValue("hash"))
+	tr.Check("validations", nil)
+	rh, err := DecodeHash(field("hash"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, Error{Message: "Invalid field: Hash", Code: http.StatusBadRequest})
+		return reject(c, tr, "hash", http.StatusBadRequest, "invalid_hash_field")
 	}
-
-	file, err := c.FormFile("image")
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, Error{Message: "Could not find image", Code: http.StatusBadRequest})
+	tr.SetHash(rh)
+	tr.Check("payload", nil)
+	o.Data = &img.Image{Raw: raw}
+	o.Site.Content, _ = o.Data.Hash()
+	if o.Site.Hash() != rh {
+		return reject(c, tr, "hash", http.StatusBadRequest, "invalid_nonce")
 	}
-	src, err := file.Open()
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, Error{Message: "Could not process image", Code: http.StatusBadRequest})
+	tr.Check("hash", nil)
+	if err := a.checkChallenge(c, rh); err != nil {
+		return rejectErr(c, tr, "challenge", http.StatusForbidden, err)
 	}
-	defer src.Close()
+	if async {
+		return a.submitAsync(c, o, nil, tr)
+	}
+	return a.submit(c, o, tr)
+}
 
-	buff := bytes.NewBuffer([]byte{})
-	io.Copy(buff, src)
-	if buff.Len() >= node.MaxMsgSize {
-		return c.JSON(http.StatusBadRequest, Error{Message: "Image to large, please compress it further or crop it", Code: http.StatusBadRequest})
+// submit hands the site to the node and records the outcome in the decision log
+func (a *API) submit(c echo.Context, o *tangle.Object, tr *decision.Trace) error {
+	err := a.node.Submit(o)
+	tr.Check("submit", err)
+	if err != nil {
+		tr.Reject(err)
 	}
-	o.Data = &img.Image{Raw: buff.Bytes()}
-	o.Site.Content, _ = o.Data.Hash()
-	if o.Site.Hash() != rh {
-		return c.JSON(http.StatusBadRequest, Error{Message: "Invalid hash. Please recalculate the nonce", Code: http.StatusBadRequest})
+	if err == node.ErrMaintenance {
+		return unavailable(c, err)
+	}
+	if err == watchdog.ErrDiskFull {
+		return c.JSON(http.StatusInsufficientStorage, Error{Message: err.Error(), Code: http.StatusInsufficientStorage})
 	}
-	err = a.node.Submit(o)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
 	}
+	tr.Finish(decision.Accepted, nil)
 	return c.NoContent(http.StatusAccepted)
 }
 
 func (a *API) getImage(c echo.Context) error {
 	h, t := decodeImageHash(c.Param("hash"))
 	s := a.node.Tangle.Get(h)
+	if s == nil || a.hidden(h) {
+		return a.notFound(c, h)
+	}
 	if s.Site.Type != "image" {
-		return c.JSON(http.StatusBadRequest, Error{Message: "requested site was not an image", Code: http.StatusBadRequest})
+		return fail(c, http.StatusBadRequest, "not_an_image")
 	}
 	i, err := s.Data.(*img.Image).Image()
 	if err != nil {
@@ -262,22 +546,25 @@ func (a *API) getImage(c echo.Context) error {
 		png.Encode(c.Response().Writer, i)
 		return nil
 	default:
-		return c.JSON(http.StatusBadRequest, Error{Message: "Please indicate the requested format with the Accept header or the file type", Code: http.StatusBadRequest})
+		return fail(c, http.StatusBadRequest, "format_required")
 	}
 }
 
 func (a *API) getSearch(c echo.Context) error {
 	results := []jsonSite{}
-	sr := a.node.Tangle.Search(c.QueryParam("q"))
+	sr := a.filter(c, a.node.Tangle.Search(c.QueryParam("q")))
 	if len(sr) == 0 {
-		return c.JSON(http.StatusNotFound, Error{Message: "No results found", Code: http.StatusNotFound})
+		return fail(c, http.StatusNotFound, "no_results")
 	}
-	for _, o := range sr {
-		results = append(results, JSONize(o))
+	sort.Slice(sr, func(i, j int) bool { return sr[i].Site.Hash().String() < sr[j].Site.Hash().String() })
+	start, end, pg := offsetPage(c, "offset", len(sr))
+	for _, o := range sr[start:end] {
+		results = append(results, a.listed(o))
 	}
 	return c.JSON(http.StatusOK, struct {
-		Results []jsonSite `json:"results"`
-	}{Results: results})
+		Results    []jsonSite  `json:"results"`
+		Pagination *Pagination `json:"pagination"`
+	}{Results: results, Pagination: pg})
 }
 
 func (a *API) getRandom(c echo.Context) error {
@@ -289,7 +576,7 @@ func (a *API) getRandom(c echo.Context) error {
 			limit = ln
 		}
 	}
-	hs := a.node.Tangle.Hashes()
+	hs := a.visible(a.node.Tangle.Hashes())
 	for i := range hs {
 		j := rand.Intn(i + 1)
 		hs[i], hs[j] = hs[j], hs[i]
@@ -32,6 +32,17 @@ func TestDecodeHash(t *testing.T) {
 
 }
 
+func FuzzDecodeHash(f *testing.F) {
+	f.Add(base64.URLEncoding.EncodeToString(validHash[:]))
+	f.Add(base64.RawStdEncoding.EncodeToString(validHash[:]))
+	f.Add(invalid)
+	f.Add("xesef-disof-gytuf-katof-movif-baxux")
+	f.Fuzz(func(t *testing.T, s string) {
+		_, _ = DecodeHash(s)
+		_, _ = decodeImageHash(s)
+	})
+}
+
 func TestDecodeImageHash(t *testing.T) {
 	cases := map[string]string{
 		base64.URLEncoding.EncodeToString(validHash[:]) + ".png":     "image/png",
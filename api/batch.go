@@ -0,0 +1,75 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo"
+)
+
+func (a *API) getBatch(c echo.Context) error {
+	req := struct {
+		Hashes []string `json:"hashes"`
+	}{}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	}
+	if len(req.Hashes) > MaxBatch {
+		return fail(c, http.StatusBadRequest, "batch_too_large", MaxBatch)
+	}
+	res := struct {
+		Results []jsonSite `json:"results"`
+		Missing []string   `json:"missing"`
+	}{Results: []jsonSite{}, Missing: []string{}}
+	for _, hs := range req.Hashes {
+		h, err := DecodeHash(hs)
+		if err != nil {
+			return fail(c, http.StatusBadRequest, "invalid_hash", hs)
+		}
+		o := a.node.Tangle.Get(h)
+		if o == nil {
+			res.Missing = append(res.Missing, hs)
+			continue
+		}
+		if err := o.Data.JSON(); err != nil {
+			return fail(c, http.StatusInternalServerError, "response_error")
+		}
+		j := a.listed(o)
+		j.Weight = a.node.Tangle.Weight(o.Site)
+		res.Results = append(res.Results, j)
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+func (a *API) headSite(c echo.Context) error {
+	h, err := DecodeHash(c.Param("hash"))
+	if err != nil {
+		return c.NoContent(http.StatusBadRequest)
+	}
+	if a.node.Tangle.GetSite(h) != nil {
+		return c.NoContent(http.StatusOK)
+	}
+	if a.node.Quorum != nil && a.node.Quorum.Get(h) != nil {
+		return c.NoContent(http.StatusAccepted)
+	}
+	return c.NoContent(http.StatusNotFound)
+}
+
+func (a *API) getExists(c echo.Context) error {
+	hs := strings.Split(c.QueryParam("hashes"), ",")
+	if len(hs) > MaxBatch {
+		return fail(c, http.StatusBadRequest, "exists_too_large", MaxBatch)
+	}
+	res := make(map[string]bool)
+	for _, s := range hs {
+		if s == "" {
+			continue
+		}
+		h, err := DecodeHash(s)
+		if err != nil {
+			return fail(c, http.StatusBadRequest, "invalid_hash", s)
+		}
+		res[s] = a.node.Tangle.GetSite(h) != nil
+	}
+	return c.JSON(http.StatusOK, res)
+}
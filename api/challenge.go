@@ -0,0 +1,44 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+const (
+	// HeaderChallenge carries the id of the challenge solved for a submission
+	HeaderChallenge = "X-Challenge"
+	// HeaderChallengeSolution carries the solution of the challenge
+	HeaderChallengeSolution = "X-Challenge-Solution"
+)
+
+func (a *API) getChallenge(c echo.Context) error {
+	if a.challenges == nil {
+		return fail(c, http.StatusNotFound, "challenges_disabled")
+	}
+	ch, err := a.challenges.Issue()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Error{Message: err.Error(), Code: http.StatusInternalServerError})
+	}
+	return c.JSON(http.StatusOK, ch)
+}
+
+// checkChallenge verifies the challenge solution sent along with the submission of a site
+func (a *API) checkChallenge(c echo.Context, h hash.Hash) error {
+	if a.challenges == nil {
+		return nil
+	}
+	id := c.Request().Header.Get(HeaderChallenge)
+	if id == "" {
+		return errors.New("Missing challenge, request one at /api/v1/challenge")
+	}
+	s, err := strconv.ParseUint(c.Request().Header.Get(HeaderChallengeSolution), 10, 64)
+	if err != nil {
+		return errors.New("Invalid challenge solution")
+	}
+	return a.challenges.Verify(id, h, s)
+}
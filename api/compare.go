@@ -0,0 +1,24 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+func (a *API) getDiff(c echo.Context) error {
+	peer := c.QueryParam("peer")
+	if peer == "" {
+		return fail(c, http.StatusBadRequest, "missing_peer")
+	}
+	hd, err := a.node.Compare(peer)
+	if err != nil {
+		return c.JSON(http.StatusBadGateway, Error{Message: err.Error(), Code: http.StatusBadGateway})
+	}
+	return c.JSON(http.StatusOK, struct {
+		Peer          string      `json:"peer"`
+		MissingLocal  []hash.Hash `json:"missing_local"`
+		MissingRemote []hash.Hash `json:"missing_remote"`
+	}{Peer: peer, MissingLocal: hd.Additions, MissingRemote: hd.Deletions})
+}
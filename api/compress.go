@@ -0,0 +1,103 @@
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/labstack/echo"
+)
+
+// encoding picks the preferred compression accepted by the client, brotli before gzip
+func encoding(accept string) string {
+	accepted := map[string]bool{}
+	for _, e := range strings.Split(accept, ",") {
+		parts := strings.Split(e, ";")
+		name := strings.ToLower(strings.TrimSpace(parts[0]))
+		ok := true
+		for _, p := range parts[1:] {
+			if strings.Replace(strings.TrimSpace(p), " ", "", -1) == "q=0" {
+				ok = false
+			}
+		}
+		accepted[name] = ok
+	}
+	for _, e := range []string{"br", "gzip"} {
+		if accepted[e] {
+			return e
+		}
+	}
+	return ""
+}
+
+// compress is a middleware compressing responses with brotli or gzip
+func compress(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		res := c.Response()
+		res.Header().Add(echo.HeaderVary, echo.HeaderAcceptEncoding)
+		enc := encoding(c.Request().Header.Get(echo.HeaderAcceptEncoding))
+		if enc == "" || c.Request().Method == echo.HEAD {
+			return next(c)
+		}
+		w := &compressWriter{ResponseWriter: res.Writer, encoding: enc}
+		res.Writer = w
+		defer func() {
+			w.Close()
+			res.Writer = w.ResponseWriter
+		}()
+		return next(c)
+	}
+}
+
+// compressWriter compresses everything written to it, unless the response has no body or is an already compressed image
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	w        io.WriteCloser
+	started  bool
+}
+
+func (c *compressWriter) WriteHeader(code int) {
+	c.started = true
+	h := c.Header()
+	if code != http.StatusNoContent && code != http.StatusNotModified && h.Get(echo.HeaderContentEncoding) == "" && !strings.HasPrefix(h.Get(echo.HeaderContentType), "image/") {
+		h.Set(echo.HeaderContentEncoding, c.encoding)
+		h.Del(echo.HeaderContentLength)
+		if c.encoding == "br" {
+			c.w = brotli.NewWriter(c.ResponseWriter)
+		} else {
+			c.w = gzip.NewWriter(c.ResponseWriter)
+		}
+	}
+	c.ResponseWriter.WriteHeader(code)
+}
+
+func (c *compressWriter) Write(b []byte) (int, error) {
+	if !c.started {
+		c.WriteHeader(http.StatusOK)
+	}
+	if c.w == nil {
+		return c.ResponseWriter.Write(b)
+	}
+	return c.w.Write(b)
+}
+
+// Flush sends everything compressed so far to the client
+func (c *compressWriter) Flush() {
+	if f, ok := c.w.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+	if f, ok := c.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close finishes the compressed stream
+func (c *compressWriter) Close() error {
+	if c.w == nil {
+		return nil
+	}
+	return c.w.Close()
+}
@@ -0,0 +1,15 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncoding(t *testing.T) {
+	assert.Equal(t, "br", encoding("gzip, deflate, br"))
+	assert.Equal(t, "gzip", encoding("br;q=0, gzip;q=0.5"))
+	assert.Equal(t, "gzip", encoding("GZIP"))
+	assert.Equal(t, "", encoding("identity"))
+	assert.Equal(t, "", encoding(""))
+}
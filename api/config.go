@@ -0,0 +1,13 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/config"
+)
+
+// getConfig lists the effective configuration of the node with the source of every value. Secrets are redacted
+func (a *API) getConfig(c echo.Context) error {
+	return c.JSON(http.StatusOK, config.Describe(a.config))
+}
@@ -0,0 +1,20 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+)
+
+// getConflict reports whether the site conflicts with other sites of a type with uniqueness rules,
+// and which of them is canonical
+func (a *API) getConflict(c echo.Context) error {
+	h, err := DecodeHash(c.Param("hash"))
+	if err != nil {
+		return fail(c, http.StatusBadRequest, "invalid_base64")
+	}
+	if a.node.Tangle.GetSite(h) == nil {
+		return fail(c, http.StatusNotFound, "site_not_found")
+	}
+	return c.JSON(http.StatusOK, a.node.Conflicts.Get(h))
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/custody"
+)
+
+// custodyRequest carries the passphrase unlocking a hosted key. It is always sent in the body to keep it out of logs
+type custodyRequest struct {
+	Key        string `json:"key" form:"key"`
+	Passphrase string `json:"passphrase" form:"passphrase"`
+	Content    string `json:"content" form:"content"`
+}
+
+// custodyError maps errors of the vault to status codes
+func custodyError(c echo.Context, err error) error {
+	status := http.StatusInternalServerError
+	switch err {
+	case custody.ErrUnknownKey:
+		status = http.StatusNotFound
+	case custody.ErrPassphrase:
+		status = http.StatusForbidden
+	case custody.ErrRateLimited:
+		status = http.StatusTooManyRequests
+	case custody.ErrBusy:
+		status = http.StatusServiceUnavailable
+	}
+	return c.JSON(status, Error{Message: err.Error(), Code: status})
+}
+
+func (a *API) bindCustody(c echo.Context) (*custodyRequest, error) {
+	if a.node.Custody == nil {
+		return nil, fail(c, http.StatusNotFound, "custody_disabled")
+	}
+	r := &custodyRequest{}
+	if err := c.Bind(r); err != nil {
+		return nil, c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	}
+	if r.Passphrase == "" {
+		return nil, fail(c, http.StatusBadRequest, "missing_passphrase")
+	}
+	return r, nil
+}
+
+func (a *API) importKey(c echo.Context) error {
+	r, err := a.bindCustody(c)
+	if r == nil {
+		return err
+	}
+	fp, err := a.node.Custody.Import(r.Key, r.Passphrase)
+	if err == custody.ErrPassphrase || err == custody.ErrBusy {
+		return custodyError(c, err)
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	}
+	return c.JSON(http.StatusCreated, struct {
+		Fingerprint string `json:"fingerprint"`
+	}{Fingerprint: fp})
+}
+
+func (a *API) exportKey(c echo.Context) error {
+	r, err := a.bindCustody(c)
+	if r == nil {
+		return err
+	}
+	key, err := a.node.Custody.Export(c.Param("fingerprint"), r.Passphrase)
+	if err != nil {
+		return custodyError(c, err)
+	}
+	return c.JSON(http.StatusOK, struct {
+		Key string `json:"key"`
+	}{Key: key})
+}
+
+func (a *API) removeKey(c echo.Context) error {
+	r, err := a.bindCustody(c)
+	if r == nil {
+		return err
+	}
+	if err := a.node.Custody.Remove(c.Param("fingerprint"), r.Passphrase); err != nil {
+		return custodyError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// signPost signs the content with a hosted key. The returned post can be submitted like any client signed post
+func (a *API) signPost(c echo.Context) error {
+	r, err := a.bindCustody(c)
+	if r == nil {
+		return err
+	}
+	p, err := a.node.Custody.Sign(c.Param("fingerprint"), r.Passphrase, r.Content)
+	if err != nil {
+		return custodyError(c, err)
+	}
+	if err := p.JSON(); err != nil {
+		return fail(c, http.StatusInternalServerError, "response_error")
+	}
+	return c.JSON(http.StatusOK, p)
+}
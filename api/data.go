@@ -0,0 +1,36 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/validate"
+)
+
+// getData returns the payload with the content hash, fetching it from peers if it is missing locally
+func (a *API) getData(c echo.Context) error {
+	typ := c.Param("type")
+	if validate.Type(typ) != nil {
+		return fail(c, http.StatusBadRequest, "invalid_type", typ)
+	}
+	h, err := DecodeHash(c.Param("content"))
+	if err != nil {
+		return fail(c, http.StatusBadRequest, "invalid_content_hash")
+	}
+	if a.node.Hidden.HiddenContent(h, time.Now()) {
+		return fail(c, http.StatusGone, "site_hidden")
+	}
+	data, err := a.node.FetchData(typ, h)
+	if err == tangle.ErrBuried {
+		return fail(c, http.StatusGone, "site_removed")
+	}
+	if err != nil {
+		return fail(c, http.StatusNotFound, "data_not_found")
+	}
+	if err := data.JSON(); err != nil {
+		return fail(c, http.StatusInternalServerError, "response_error")
+	}
+	return c.JSON(http.StatusOK, data)
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/decision"
+)
+
+// getDecisions returns the recorded acceptance attempts of a site, or the most recent ones if no hash is given
+func (a *API) getDecisions(c echo.Context) error {
+	if c.QueryParam("hash") != "" {
+		h, err := DecodeHash(c.QueryParam("hash"))
+		if err != nil {
+			return fail(c, http.StatusBadRequest, "invalid_hash", c.QueryParam("hash"))
+		}
+		return c.JSON(http.StatusOK, a.node.Decisions.Find(h))
+	}
+	limit := 100
+	if l := c.QueryParam("limit"); l != "" {
+		var err error
+		if limit, err = strconv.Atoi(l); err != nil {
+			return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+		}
+	}
+	return c.JSON(http.StatusOK, a.node.Decisions.Recent(limit))
+}
+
+// reject records the failed check and responds with the message of key. The decision log keeps the english message
+func reject(c echo.Context, tr *decision.Trace, check string, status int, key string, args ...interface{}) error {
+	m := messages.Message(key, "en")
+	if len(args) > 0 {
+		m = fmt.Sprintf(m, args...)
+	}
+	tr.Check(check, errors.New(m))
+	tr.Reject(nil)
+	return fail(c, status, key, args...)
+}
+
+// rejectErr records the failed check and responds with the error
+func rejectErr(c echo.Context, tr *decision.Trace, check string, status int, err error) error {
+	tr.Check(check, err)
+	tr.Reject(nil)
+	return c.JSON(status, Error{Message: err.Error(), Code: status})
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+)
+
+func (a *API) getSubscribers(c echo.Context) error {
+	if a.node.Digest == nil {
+		return fail(c, http.StatusNotFound, "digest_disabled")
+	}
+	return c.JSON(http.StatusOK, a.node.Digest.Subscribers.List())
+}
+
+func (a *API) addSubscriber(c echo.Context) error {
+	if a.node.Digest == nil {
+		return fail(c, http.StatusNotFound, "digest_disabled")
+	}
+	s := struct {
+		Address string `json:"address" form:"address"`
+	}{}
+	if err := c.Bind(&s); err != nil {
+		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	}
+	if err := a.node.Digest.Subscribe(s.Address); err != nil {
+		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	}
+	return c.NoContent(http.StatusAccepted)
+}
+
+func (a *API) confirmSubscriber(c echo.Context) error {
+	if a.node.Digest == nil {
+		return fail(c, http.StatusNotFound, "digest_disabled")
+	}
+	if err := a.node.Digest.Confirm(c.Param("token")); err != nil {
+		return fail(c, http.StatusNotFound, "unknown_token")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (a *API) deleteSubscriber(c echo.Context) error {
+	if a.node.Digest == nil {
+		return fail(c, http.StatusNotFound, "digest_disabled")
+	}
+	if err := a.node.Digest.Subscribers.Remove(c.Param("address")); err != nil {
+		return c.JSON(http.StatusInternalServerError, Error{Message: err.Error(), Code: http.StatusInternalServerError})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
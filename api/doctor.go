@@ -0,0 +1,16 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/doctor"
+)
+
+func (a *API) getDoctor(c echo.Context) error {
+	r := doctor.Run(a.config, doctor.Options{Running: true, Tangle: a.node.Tangle})
+	if !r.Healthy {
+		return c.JSON(http.StatusServiceUnavailable, r)
+	}
+	return c.JSON(http.StatusOK, r)
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/timeindex"
+)
+
+// MaxExplorerSites is the amount of recent sites listed for an address
+const MaxExplorerSites = 20
+
+// getExplorerBlock returns a site with everything needed to navigate the tangle from it.
+// Previous and next are the sites of the same type dated directly before and after it
+func (a *API) getExplorerBlock(c echo.Context) error {
+	h, err := DecodeHash(c.Param("hash"))
+	if err != nil {
+		return fail(c, http.StatusBadRequest, "invalid_base64")
+	}
+	o := a.node.Tangle.Get(h)
+	if o == nil || a.hidden(h) {
+		return a.notFound(c, h)
+	}
+	if err := o.Data.JSON(); err != nil {
+		return fail(c, http.StatusInternalServerError, "response_error")
+	}
+	date, _ := timeindex.Date(o)
+	prev, next := a.node.Dates.Adjacent(o)
+	return c.JSON(http.StatusOK, struct {
+		Site      siteV2      `json:"site"`
+		Date      int64       `json:"date,omitempty"`
+		Previous  *hash.Hash  `json:"previous"`
+		Next      *hash.Hash  `json:"next"`
+		Approvers []hash.Hash `json:"approvers"`
+	}{Site: a.v2(o), Date: date, Previous: prev, Next: next, Approvers: a.node.Tangle.Approvers(h)})
+}
+
+// getExplorerAddress summarizes the activity of a key, given by fingerprint or key id
+func (a *API) getExplorerAddress(c echo.Context) error {
+	fp, err := a.node.Keys.Resolve(c.Param("keyid"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, Error{Message: err.Error(), Code: http.StatusNotFound})
+	}
+	key, _ := a.node.Keys.Get(fp)
+	following, tags := a.node.Follows.Following(fp)
+	r := struct {
+		Fingerprint string         `json:"fingerprint"`
+		KeyID       string         `json:"key_id"`
+		Key         string         `json:"key"`
+		Sites       int            `json:"sites"`
+		Types       map[string]int `json:"types"`
+		FirstDate   int64          `json:"first_date,omitempty"`
+		LastDate    int64          `json:"last_date,omitempty"`
+		Followers   int            `json:"followers"`
+		Following   int            `json:"following"`
+		Tags        int            `json:"tags"`
+		Recent      []hash.Hash    `json:"recent"`
+	}{Fingerprint: fp, KeyID: fp[len(fp)-16:], Key: key, Types: map[string]int{}, Recent: []hash.Hash{},
+		Followers: len(a.node.Follows.Followers(fp)), Following: len(following), Tags: len(tags)}
+
+	objs := []*tangle.Object{}
+	for _, h := range a.visible(a.node.Keys.Sites(fp)) {
+		if o := a.node.Tangle.Get(h); o != nil {
+			objs = append(objs, o)
+		}
+	}
+	dates := map[*tangle.Object]int64{}
+	for _, o := range objs {
+		r.Sites++
+		r.Types[o.Site.Type]++
+		d, _ := timeindex.Date(o)
+		dates[o] = d
+		if r.FirstDate == 0 || d < r.FirstDate {
+			r.FirstDate = d
+		}
+		if d > r.LastDate {
+			r.LastDate = d
+		}
+	}
+	sort.Slice(objs, func(i, j int) bool { return dates[objs[i]] > dates[objs[j]] })
+	for i := 0; i < len(objs) && i < MaxExplorerSites; i++ {
+		r.Recent = append(r.Recent, objs[i].Site.Hash())
+	}
+	return c.JSON(http.StatusOK, r)
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+func (a *API) getFeed(c echo.Context) error {
+	algo := c.QueryParam("algorithm")
+	if algo == "" {
+		algo = a.defaultRanking
+	}
+	cs, err := a.ranker.Rank(algo, time.Now())
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	}
+	if w := a.wanted(c); w != nil {
+		fs := cs[:0]
+		for _, s := range cs {
+			if w(s.Object.Site.Hash()) {
+				fs = append(fs, s)
+			}
+		}
+		cs = fs
+	}
+	limit := limitParam(c)
+	total := len(cs)
+	// Pages past the end are clamped before multiplying, so huge page numbers can not overflow the offsets
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page < 0 {
+		page = 0
+	}
+	if page > total/limit {
+		page = total / limit
+	}
+	pg := &Pagination{Limit: limit, Total: &total}
+	if (page+1)*limit < total {
+		pg.Next = strconv.Itoa(page + 1)
+	}
+	if page > 0 {
+		pg.Prev = strconv.Itoa(page - 1)
+	}
+	results := []jsonSite{}
+	for i := page * limit; i < len(cs) && i < (page+1)*limit; i++ {
+		if err := cs[i].Object.Data.JSON(); err != nil {
+			return fail(c, http.StatusInternalServerError, "response_error")
+		}
+		j := a.listed(cs[i].Object)
+		j.Weight = cs[i].Weight
+		results = append(results, j)
+	}
+	return c.JSON(http.StatusOK, struct {
+		Algorithm  string      `json:"algorithm"`
+		Page       int         `json:"page"`
+		Results    []jsonSite  `json:"results"`
+		Pagination *Pagination `json:"pagination"`
+	}{Algorithm: algo, Page: page, Results: results, Pagination: paginate(c, "page", pg)})
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo"
+)
+
+// fieldTree is a parsed ?fields= parameter. A nil subtree selects the whole value
+type fieldTree map[string]fieldTree
+
+// parseFields parses a comma separated list of dotted field paths
+func parseFields(s string) fieldTree {
+	t := fieldTree{}
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		cur := t
+		parts := strings.Split(f, ".")
+		for i, p := range parts {
+			sub, ok := cur[p]
+			if i == len(parts)-1 {
+				cur[p] = nil
+				break
+			}
+			if ok && sub == nil {
+				break
+			}
+			if !ok {
+				sub = fieldTree{}
+				cur[p] = sub
+			}
+			cur = sub
+		}
+	}
+	return t
+}
+
+// pick keeps only the selected fields of a decoded json value
+func (t fieldTree) pick(v interface{}) interface{} {
+	switch vt := v.(type) {
+	case map[string]interface{}:
+		m := map[string]interface{}{}
+		for k, sub := range t {
+			f, ok := vt[k]
+			if !ok {
+				continue
+			}
+			if sub == nil {
+				m[k] = f
+			} else {
+				m[k] = sub.pick(f)
+			}
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(vt))
+		for i, e := range vt {
+			l[i] = t.pick(e)
+		}
+		return l
+	}
+	return v
+}
+
+// project applies the field selection to a response. Lists wrapped in a results object
+// are projected element-wise, leaving the surrounding metadata untouched
+func project(v interface{}, t fieldTree) interface{} {
+	if m, ok := v.(map[string]interface{}); ok {
+		if rs, ok := m["results"].([]interface{}); ok {
+			m["results"] = t.pick(rs)
+			return m
+		}
+	}
+	return t.pick(v)
+}
+
+// projectFields is a middleware reducing json responses to the fields given in the fields query parameter.
+// Only successful json responses are projected, other responses and errors returned by the handler pass through untouched
+func projectFields(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		fs := c.QueryParam("fields")
+		if fs == "" {
+			return next(c)
+		}
+		res := c.Response()
+		w := res.Writer
+		pw := &projectingWriter{w: w}
+		res.Writer = pw
+		err := next(c)
+		res.Writer = w
+		if !pw.buffered() {
+			return err
+		}
+		body := pw.body.Bytes()
+		var v interface{}
+		if json.Unmarshal(body, &v) == nil {
+			if b, merr := json.Marshal(project(v, parseFields(fs))); merr == nil {
+				body = b
+			}
+		}
+		w.Header().Set(echo.HeaderContentLength, strconv.Itoa(len(body)))
+		w.WriteHeader(pw.status)
+		w.Write(body)
+		return err
+	}
+}
+
+// projectingWriter holds back successful json responses so they can be rewritten before sending.
+// Any other response is written through as soon as its header is written
+type projectingWriter struct {
+	w           http.ResponseWriter
+	status      int
+	wroteHeader bool
+	passthrough bool
+	body        bytes.Buffer
+}
+
+// buffered reports whether a response was held back
+func (p *projectingWriter) buffered() bool {
+	return p.wroteHeader && !p.passthrough
+}
+
+func (p *projectingWriter) Header() http.Header {
+	return p.w.Header()
+}
+
+func (p *projectingWriter) WriteHeader(code int) {
+	if p.wroteHeader {
+		return
+	}
+	p.status, p.wroteHeader = code, true
+	if code/100 != 2 || !strings.HasPrefix(p.w.Header().Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		p.passthrough = true
+		p.w.WriteHeader(code)
+	}
+}
+
+func (p *projectingWriter) Write(b []byte) (int, error) {
+	if !p.wroteHeader {
+		p.WriteHeader(http.StatusOK)
+	}
+	if p.passthrough {
+		return p.w.Write(b)
+	}
+	return p.body.Write(b)
+}
+
+// Flush sends responses which are written through, held back responses are sent once the handler returned
+func (p *projectingWriter) Flush() {
+	if f, ok := p.w.(http.Flusher); ok && p.passthrough {
+		f.Flush()
+	}
+}
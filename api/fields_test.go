@@ -0,0 +1,63 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProject(t *testing.T) {
+	var v interface{}
+	err := json.Unmarshal([]byte(`{"results":[{"hash":"a","content":"x","data":{"date":1,"content":"long","pubkey":"k"}}],"next":"b"}`), &v)
+	assert.NoError(t, err)
+	p := project(v, parseFields("hash, data.date,data.content"))
+	b, err := json.Marshal(p)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"results":[{"hash":"a","data":{"date":1,"content":"long"}}],"next":"b"}`, string(b))
+
+	err = json.Unmarshal([]byte(`{"hash":"a","type":"post","data":{"date":1}}`), &v)
+	assert.NoError(t, err)
+	b, err = json.Marshal(project(v, parseFields("data,data.date,type")))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type":"post","data":{"date":1}}`, string(b))
+}
+
+func TestProjectFields(t *testing.T) {
+	e := echo.New()
+	e.GET("/ok", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"hash": "a", "type": "post"})
+	}, projectFields)
+	e.GET("/missing", func(c echo.Context) error {
+		return c.JSON(http.StatusNotFound, map[string]string{"message": "Site not found", "key": "site_not_found"})
+	}, projectFields)
+	e.GET("/error", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusTeapot, "brewing")
+	}, projectFields)
+	e.GET("/text", func(c echo.Context) error {
+		return c.String(http.StatusOK, `{"hash":"a","type":"post"}`)
+	}, projectFields)
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(echo.GET, path+"?fields=hash", nil))
+		return rec
+	}
+	rec := serve("/ok")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"hash":"a"}`, rec.Body.String())
+	assert.Equal(t, strconv.Itoa(rec.Body.Len()), rec.Header().Get(echo.HeaderContentLength))
+	rec = serve("/missing")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.JSONEq(t, `{"message":"Site not found","key":"site_not_found"}`, rec.Body.String())
+	rec = serve("/error")
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+	assert.Contains(t, rec.Body.String(), "brewing")
+	rec = serve("/text")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"hash":"a","type":"post"}`, rec.Body.String())
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+// listParam splits a comma separated query parameter, e.g. ?lang=de,en
+func listParam(c echo.Context, name string) map[string]bool {
+	q := c.QueryParam(name)
+	if q == "" {
+		return nil
+	}
+	ls := map[string]bool{}
+	for _, l := range strings.Split(strings.ToLower(q), ",") {
+		if l = strings.TrimSpace(l); l != "" {
+			ls[l] = true
+		}
+	}
+	return ls
+}
+
+// wanted returns a filter for the lang and exclude_flags parameters and the sites hidden by the operator,
+// or nil if nothing has to be filtered. Sites with an undetected language are only included when "und" is requested
+func (a *API) wanted(c echo.Context) func(h hash.Hash) bool {
+	ls := listParam(c, "lang")
+	ex := listParam(c, "exclude_flags")
+	if ls == nil && (ex == nil || a.node.Flags == nil) && a.node.Hidden.Len() == 0 {
+		return nil
+	}
+	return func(h hash.Hash) bool {
+		if a.hidden(h) {
+			return false
+		}
+		if ls != nil {
+			l := a.node.Langs.Get(h)
+			if l == "" {
+				l = "und"
+			}
+			if !ls[l] {
+				return false
+			}
+		}
+		for _, f := range a.flags(h) {
+			if ex[f] {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// filter removes all objects not matching the lang and exclude_flags parameters or hidden by the operator
+func (a *API) filter(c echo.Context, os []*tangle.Object) []*tangle.Object {
+	w := a.wanted(c)
+	if w == nil {
+		return os
+	}
+	fs := []*tangle.Object{}
+	for _, o := range os {
+		if w(o.Site.Hash()) {
+			fs = append(fs, o)
+		}
+	}
+	return fs
+}
+
+// flags returns the content flags of a site, or nil if flagging is disabled
+func (a *API) flags(h hash.Hash) []string {
+	if a.node.Flags == nil {
+		return nil
+	}
+	return a.node.Flags.Get(h)
+}
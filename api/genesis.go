@@ -0,0 +1,14 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+)
+
+func (a *API) getGenesis(c echo.Context) error {
+	if a.node.Genesis == nil {
+		return fail(c, http.StatusNotFound, "builtin_genesis")
+	}
+	return c.JSON(http.StatusOK, a.node.Genesis)
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/hide"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+// hidden reports whether the operator hid the site on this node
+func (a *API) hidden(h hash.Hash) bool {
+	return a.node.Hidden.Hidden(h, time.Now())
+}
+
+// visible returns the sites which are not hidden by the operator
+func (a *API) visible(hs []hash.Hash) []hash.Hash {
+	if a.node.Hidden.Len() == 0 {
+		return hs
+	}
+	vs := make([]hash.Hash, 0, len(hs))
+	for _, h := range hs {
+		if !a.hidden(h) {
+			vs = append(vs, h)
+		}
+	}
+	return vs
+}
+
+// actor identifies the operator in the audit log of the hide list
+func (a *API) actor(c echo.Context) string {
+	return a.user + "@" + c.RealIP()
+}
+
+func (a *API) getHidden(c echo.Context) error {
+	return c.JSON(http.StatusOK, a.node.Hidden.List())
+}
+
+// addHidden hides the sites in the hashes field. Sites the tangle does not know yet are hidden as well,
+// so they are never shown once they arrive
+func (a *API) addHidden(c echo.Context) error {
+	r := struct {
+		Hashes  []string `json:"hashes"`
+		Reason  string   `json:"reason"`
+		Expires string   `json:"expires"`
+	}{}
+	if err := c.Bind(&r); err != nil {
+		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	}
+	hs := []hash.Hash{}
+	for _, s := range r.Hashes {
+		h, err := DecodeHash(s)
+		if err != nil {
+			return fail(c, http.StatusBadRequest, "invalid_hash", s)
+		}
+		hs = append(hs, h)
+	}
+	return a.hide(c, hs, r.Reason, r.Expires)
+}
+
+// importHidden hides every site of a hash list file sent as request body, see hide.Parse.
+// The reason and expires parameters apply to all of them
+func (a *API) importHidden(c echo.Context) error {
+	hs, err := hide.Parse(c.Request().Body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	}
+	return a.hide(c, hs, c.QueryParam("reason"), c.QueryParam("expires"))
+}
+
+func (a *API) hide(c echo.Context, hs []hash.Hash, reason, expires string) error {
+	var exp time.Time
+	if expires = strings.TrimSpace(expires); expires != "" {
+		var err error
+		if exp, err = time.Parse(time.RFC3339, expires); err != nil {
+			return fail(c, http.StatusBadRequest, "invalid_expiry", expires)
+		}
+	}
+	es := []hide.Entry{}
+	for _, h := range hs {
+		es = append(es, hide.Entry{Hash: h, Reason: reason, Expires: exp})
+	}
+	if err := a.node.Hidden.Hide(es, a.actor(c)); err != nil {
+		return c.JSON(http.StatusInternalServerError, Error{Message: err.Error(), Code: http.StatusInternalServerError})
+	}
+	for _, h := range hs {
+		if s := a.node.Tangle.GetSite(h); s != nil {
+			a.node.Hidden.Resolve(h, s.Content)
+		}
+	}
+	return c.JSON(http.StatusCreated, es)
+}
+
+func (a *API) removeHidden(c echo.Context) error {
+	h, err := DecodeHash(c.Param("hash"))
+	if err != nil {
+		return fail(c, http.StatusBadRequest, "invalid_base64")
+	}
+	err = a.node.Hidden.Unhide(h, a.actor(c))
+	if err == hide.ErrNotHidden {
+		return fail(c, http.StatusNotFound, "site_not_hidden")
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Error{Message: err.Error(), Code: http.StatusInternalServerError})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// getHiddenAudit returns the latest changes of the hide list, newest first
+func (a *API) getHiddenAudit(c echo.Context) error {
+	es, err := a.node.Hidden.Audit(limitParam(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Error{Message: err.Error(), Code: http.StatusInternalServerError})
+	}
+	return c.JSON(http.StatusOK, es)
+}
@@ -0,0 +1,208 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/config"
+	"github.com/u-speak/core/follow"
+	"github.com/u-speak/core/hide"
+	"github.com/u-speak/core/img"
+	"github.com/u-speak/core/miner"
+	"github.com/u-speak/core/node"
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/datastore"
+	"github.com/u-speak/core/tangle/site"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+)
+
+// defaults sets every field to the value of its default tag
+func defaults(v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		f, tag := v.Field(i), v.Type().Field(i).Tag.Get("default")
+		switch {
+		case f.Kind() == reflect.Struct:
+			defaults(f)
+		case tag == "":
+		case f.Kind() == reflect.String:
+			f.SetString(tag)
+		case f.Kind() == reflect.Bool:
+			f.SetBool(tag == "true")
+		case f.Kind() == reflect.Int:
+			i, _ := strconv.ParseInt(tag, 10, 64)
+			f.SetInt(i)
+		case f.Kind() == reflect.Uint64:
+			u, _ := strconv.ParseUint(tag, 10, 64)
+			f.SetUint(u)
+		case f.Kind() == reflect.Float64:
+			fl, _ := strconv.ParseFloat(tag, 64)
+			f.SetFloat(fl)
+		}
+	}
+}
+
+// testAPI returns the routes of an api backed by a node with the default configuration and all stores in dir
+func testAPI(t *testing.T, dir string) (*API, *echo.Echo) {
+	c := config.Configuration{}
+	defaults(reflect.ValueOf(&c).Elem())
+	s := reflect.ValueOf(&c.Storage).Elem()
+	for i := 0; i < s.NumField(); i++ {
+		if f := s.Field(i); f.Kind() == reflect.String {
+			f.SetString(strings.Replace(f.String(), "/var/lib/uspeak", dir, 1))
+		}
+	}
+	c.Storage.MinFree = 0
+	n, err := node.New(c)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	a := New(c, n)
+	return a, a.server()
+}
+
+func armoredKey(t *testing.T) string {
+	e, err := openpgp.NewEntity("Test", "", "test@example.com", nil)
+	assert.NoError(t, err)
+	buf := bytes.NewBuffer(nil)
+	w, err := armor.Encode(buf, openpgp.PrivateKeyType, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, e.SerializePrivate(w, nil))
+	assert.NoError(t, w.Close())
+	return buf.String()
+}
+
+// add mines a site for the payload onto the tips of the tangle and adds it
+func add(t *testing.T, tngl *tangle.Tangle, d datastore.Serializable) *tangle.Object {
+	h, err := d.Hash()
+	assert.NoError(t, err)
+	o := &tangle.Object{Site: &site.Site{Type: d.Type(), Content: h, Validates: tngl.RecommendTips(), Timestamp: time.Now().Unix()}, Data: d}
+	o.Site.Mine(tngl.Required(d.Type()).Weight)
+	assert.NoError(t, tngl.Add(o))
+	return o
+}
+
+func signedPost(t *testing.T, key, content string) *post.Post {
+	p, err := miner.SignPost(key, "", content, time.Now().Unix())
+	assert.NoError(t, err)
+	return p
+}
+
+func get(e *echo.Echo, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(echo.GET, path, nil))
+	return rec
+}
+
+// results decodes the hashes of the sites listed in the results of a response
+func results(t *testing.T, rec *httptest.ResponseRecorder) []string {
+	r := struct {
+		Results []struct {
+			Hash string `json:"hash"`
+		} `json:"results"`
+	}{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &r))
+	hs := []string{}
+	for _, s := range r.Results {
+		hs = append(hs, s.Hash)
+	}
+	return hs
+}
+
+func TestHiddenRoutes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "api")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	a, e := testAPI(t, dir)
+	defer a.node.Shutdown()
+	tngl := a.node.Tangle
+
+	author, reader := armoredKey(t), armoredKey(t)
+	first := add(t, tngl, signedPost(t, author, "first"))
+	hidden := add(t, tngl, signedPost(t, author, "hidden"))
+	shown := add(t, tngl, signedPost(t, author, "shown"))
+	fp := hidden.Data.(*post.Post).Fingerprint()
+	list := add(t, tngl, &follow.List{Post: *signedPost(t, reader, fp)})
+	buf := bytes.NewBuffer(nil)
+	assert.NoError(t, png.Encode(buf, image.NewGray(image.Rect(0, 0, 40, 40))))
+	im := add(t, tngl, &img.Image{Raw: buf.Bytes()})
+	a.node.Media.Add(im)
+	vs, ok := a.node.Media.Variants(im.Site.Hash())
+	assert.True(t, ok)
+
+	assert.NoError(t, a.node.Hidden.Hide([]hide.Entry{{Hash: hidden.Site.Hash()}, {Hash: im.Site.Hash()}}, "test"))
+	for _, o := range []*tangle.Object{hidden, im} {
+		a.node.Hidden.Resolve(o.Site.Hash(), o.Site.Content)
+	}
+	h, s := hidden.Site.Hash().String(), shown.Site.Hash().String()
+
+	t.Run("random site", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			rec := get(e, "/api/v1/random?type=image")
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			rec = get(e, "/api/v1/random")
+			assert.Equal(t, http.StatusOK, rec.Code)
+			r := struct {
+				Hash string `json:"hash"`
+			}{}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &r))
+			assert.NotEqual(t, h, r.Hash)
+		}
+	})
+	t.Run("random hashes", func(t *testing.T) {
+		rec := get(e, "/api/v1/tangle/random?limit=50")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), h)
+		assert.Contains(t, rec.Body.String(), s)
+	})
+	t.Run("tail", func(t *testing.T) {
+		rec := get(e, "/api/v1/tangle/tail?timeout=0&since="+first.Site.Hash().String())
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, []string{s, list.Site.Hash().String()}, results(t, rec))
+		assert.Contains(t, rec.Body.String(), `"next":"`+im.Site.Hash().String()+`"`)
+	})
+	t.Run("timeline", func(t *testing.T) {
+		rec := get(e, "/api/v1/timeline/"+list.Data.(*follow.List).Fingerprint())
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.ElementsMatch(t, []string{first.Site.Hash().String(), s}, results(t, rec))
+	})
+	t.Run("range", func(t *testing.T) {
+		rec := get(e, "/api/v1/tangle/types/post")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.ElementsMatch(t, []string{first.Site.Hash().String(), s}, results(t, rec))
+	})
+	t.Run("explorer address", func(t *testing.T) {
+		rec := get(e, "/api/v1/explorer/address/"+fp)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), h)
+		assert.Contains(t, rec.Body.String(), `"sites":2`)
+	})
+	t.Run("data", func(t *testing.T) {
+		rec := get(e, "/api/v1/data/post/"+hidden.Site.Content.String())
+		assert.Equal(t, http.StatusGone, rec.Code)
+		rec = get(e, "/api/v1/data/post/"+shown.Site.Content.String())
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+	t.Run("media", func(t *testing.T) {
+		rec := get(e, "/api/v1/media/"+vs[0].Hash.String())
+		assert.Equal(t, http.StatusGone, rec.Code)
+		assert.NoError(t, a.node.Hidden.Unhide(im.Site.Hash(), "test"))
+		rec = get(e, "/api/v1/media/"+vs[0].Hash.String())
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
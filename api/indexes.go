@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/pipeline"
+)
+
+// checkLimit returns the amount of sites to check given by the limit parameter
+func checkLimit(c echo.Context) (int, error) {
+	if l := c.QueryParam("limit"); l != "" {
+		return strconv.Atoi(l)
+	}
+	return pipeline.CheckBatch, nil
+}
+
+// getIndexes lists the sites of the next batch the derived indexes are missing without changing them
+func (a *API) getIndexes(c echo.Context) error {
+	limit, err := checkLimit(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	}
+	return c.JSON(http.StatusOK, a.node.Indexes.Check(a.node.Tangle, false, limit))
+}
+
+// postIndexes indexes the missing sites of the next batch and retries failed index updates
+func (a *API) postIndexes(c echo.Context) error {
+	limit, err := checkLimit(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	}
+	return c.JSON(http.StatusOK, a.node.Indexes.Check(a.node.Tangle, true, limit))
+}
@@ -0,0 +1,21 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo"
+)
+
+// getKey returns the public key with the fingerprint, asking peers if no local site was signed with it
+func (a *API) getKey(c echo.Context) error {
+	fp := strings.ToLower(c.Param("fingerprint"))
+	k, err := a.node.LookupKey(fp)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, Error{Message: err.Error(), Code: http.StatusNotFound})
+	}
+	return c.JSON(http.StatusOK, struct {
+		Fingerprint string `json:"fingerprint"`
+		Key         string `json:"key"`
+	}{Fingerprint: fp, Key: k})
+}
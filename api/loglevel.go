@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/logging"
+)
+
+type jsonLogLevel struct {
+	Level string          `json:"level"`
+	Debug map[string]bool `json:"debug"`
+}
+
+func (a *API) getLogLevel(c echo.Context) error {
+	return c.JSON(http.StatusOK, jsonLogLevel{Level: logging.Level(), Debug: logging.Debug()})
+}
+
+// putLogLevel changes the global log level and the debug toggles of modules at runtime.
+// Omitted fields keep their current value
+func (a *API) putLogLevel(c echo.Context) error {
+	l := jsonLogLevel{}
+	if err := c.Bind(&l); err != nil {
+		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	}
+	for m := range l.Debug {
+		if _, ok := logging.Debug()[m]; !ok {
+			return fail(c, http.StatusBadRequest, "unknown_log_module", m)
+		}
+	}
+	if l.Level != "" {
+		if err := logging.SetLevel(l.Level); err != nil {
+			return fail(c, http.StatusBadRequest, "invalid_log_level", l.Level)
+		}
+	}
+	for m, on := range l.Debug {
+		_ = logging.SetDebug(m, on)
+	}
+	return a.getLogLevel(c)
+}
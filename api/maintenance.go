@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/node"
+)
+
+// RetryAfter is the amount of seconds clients are asked to wait before resubmitting a site rejected during maintenance
+const RetryAfter = 60
+
+type jsonMaintenance struct {
+	node.Maintenance
+	Drained bool `json:"drained"`
+}
+
+type maintenanceRequest struct {
+	Enabled bool `json:"enabled"`
+	// Wait is the amount of seconds to wait for running pushes and syncs to finish
+	Wait int `json:"wait"`
+}
+
+func (a *API) getMaintenance(c echo.Context) error {
+	m := a.node.Maintenance()
+	return c.JSON(http.StatusOK, jsonMaintenance{Maintenance: m, Drained: m.InFlight == 0})
+}
+
+// putMaintenance enters or leaves maintenance mode. When entering it waits up to the given amount of seconds for running work
+func (a *API) putMaintenance(c echo.Context) error {
+	r := maintenanceRequest{}
+	if err := c.Bind(&r); err != nil {
+		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	}
+	a.node.SetMaintenance(r.Enabled)
+	drained := true
+	if r.Enabled && r.Wait > 0 {
+		drained = a.node.Drain(time.Duration(r.Wait) * time.Second)
+	}
+	m := a.node.Maintenance()
+	return c.JSON(http.StatusOK, jsonMaintenance{Maintenance: m, Drained: drained && m.InFlight == 0})
+}
+
+// unavailable tells the client to retry a submission once maintenance is over
+func unavailable(c echo.Context, err error) error {
+	c.Response().Header().Set("Retry-After", strconv.Itoa(RetryAfter))
+	return c.JSON(http.StatusServiceUnavailable, Error{Message: err.Error(), Code: http.StatusServiceUnavailable})
+}
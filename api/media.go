@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/media"
+)
+
+type jsonVariant struct {
+	media.Variant
+	URL string `json:"url"`
+}
+
+// getVariants lists the stored variants of an image, so clients can pick a size and format themselves
+func (a *API) getVariants(c echo.Context) error {
+	if a.node.Media == nil {
+		return fail(c, http.StatusNotFound, "media_disabled")
+	}
+	h, err := DecodeHash(c.Param("hash"))
+	if err != nil {
+		return fail(c, http.StatusBadRequest, "invalid_base64")
+	}
+	s := a.node.Tangle.GetSite(h)
+	if s == nil || a.hidden(h) {
+		return a.notFound(c, h)
+	}
+	if s.Type != "image" {
+		return fail(c, http.StatusBadRequest, "not_an_image")
+	}
+	vs, ok := a.node.Media.Variants(h)
+	if !ok {
+		return fail(c, http.StatusNotFound, "variants_not_found")
+	}
+	res := []jsonVariant{}
+	for _, v := range vs {
+		res = append(res, jsonVariant{Variant: v, URL: "/api/v1/media/" + v.Hash.String()})
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// getMedia serves a variant by its hash. Variants are content addressed and never change
+func (a *API) getMedia(c echo.Context) error {
+	if a.node.Media == nil {
+		return fail(c, http.StatusNotFound, "media_disabled")
+	}
+	h, err := DecodeHash(c.Param("hash"))
+	if err != nil {
+		return fail(c, http.StatusBadRequest, "invalid_base64")
+	}
+	for _, s := range a.node.Media.Sources(h) {
+		if a.hidden(s) {
+			return fail(c, http.StatusGone, "site_hidden")
+		}
+	}
+	data, ok := a.node.Media.Get(h)
+	if !ok {
+		return fail(c, http.StatusNotFound, "variant_not_found")
+	}
+	c.Response().Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	c.Response().Header().Set("ETag", `"`+h.String()+`"`)
+	return c.Blob(http.StatusOK, http.DetectContentType(data), data)
+}
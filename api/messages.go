@@ -0,0 +1,110 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/i18n"
+	"github.com/u-speak/core/logging"
+)
+
+// messages is the catalog of all error messages returned by the API, keyed by their stable error key.
+// Messages may contain fmt verbs for details like the offending input
+var messages = i18n.Catalog{
+	"anchoring_disabled":      {"en": "Anchoring is not enabled", "de": "Verankerung ist nicht aktiviert"},
+	"batch_too_large":         {"en": "At most %d hashes can be fetched at once", "de": "Es können höchstens %d Hashes auf einmal abgefragt werden"},
+	"builtin_genesis":         {"en": "This node runs on the builtin network", "de": "Dieser Knoten läuft im eingebauten Netzwerk"},
+	"challenges_disabled":     {"en": "Challenges are not enabled", "de": "Challenges sind nicht aktiviert"},
+	"content_mismatch":        {"en": "Content did not match supplied hash", "de": "Inhalt passt nicht zum angegebenen Hash"},
+	"custody_disabled":        {"en": "Custodial signing is not enabled", "de": "Signieren durch den Server ist nicht aktiviert"},
+	"data_not_found":          {"en": "No payload found for this content hash", "de": "Für diesen Inhalts-Hash wurden keine Daten gefunden"},
+	"digest_disabled":         {"en": "Digest is not enabled", "de": "Digest ist nicht aktiviert"},
+	"exists_too_large":        {"en": "At most %d hashes can be checked at once", "de": "Es können höchstens %d Hashes auf einmal geprüft werden"},
+	"follow_list_not_found":   {"en": "No follow list found for this key", "de": "Für diesen Schlüssel wurde keine Folgeliste gefunden"},
+	"format_required":         {"en": "Please indicate the requested format with the Accept header or the file type", "de": "Bitte das gewünschte Format mit dem Accept-Header oder der Dateiendung angeben"},
+	"hash_mismatch":           {"en": "Provided hash does not match", "de": "Angegebener Hash stimmt nicht überein"},
+	"image_not_found":         {"en": "Could not find image", "de": "Bild wurde nicht gefunden"},
+	"image_too_large":         {"en": "Image to large, please compress it further or crop it", "de": "Bild zu groß, bitte stärker komprimieren oder zuschneiden"},
+	"image_unprocessable":     {"en": "Could not process image", "de": "Bild konnte nicht verarbeitet werden"},
+	"invalid_candidates":      {"en": "Candidates must be between 0 and %d", "de": "Kandidaten müssen zwischen 0 und %d liegen"},
+	"invalid_base64":          {"en": "Invalid base64 data", "de": "Ungültige Base64-Daten"},
+	"invalid_content_hash":    {"en": "Could not decode content hash", "de": "Inhalts-Hash konnte nicht dekodiert werden"},
+	"invalid_cursor":          {"en": "Invalid cursor", "de": "Ungültiger Cursor"},
+	"invalid_date":            {"en": "Invalid date: %s", "de": "Ungültiges Datum: %s"},
+	"invalid_expected":        {"en": "Invalid list of expected hashes", "de": "Ungültige Liste erwarteter Hashes"},
+	"invalid_expiry":          {"en": "Invalid expiry, expected an RFC 3339 time: %s", "de": "Ungültiges Ablaufdatum, erwartet wird eine Zeit nach RFC 3339: %s"},
+	"invalid_hash":            {"en": "Invalid hash: %s", "de": "Ungültiger Hash: %s"},
+	"invalid_hash_field":      {"en": "Invalid field: Hash", "de": "Ungültiges Feld: Hash"},
+	"invalid_log_level":       {"en": "Invalid log level: %s", "de": "Ungültige Protokollstufe: %s"},
+	"invalid_nonce":           {"en": "Invalid hash. Please recalculate the nonce", "de": "Ungültiger Hash. Bitte die Nonce neu berechnen"},
+	"invalid_since":           {"en": "Invalid since hash", "de": "Ungültiger since-Hash"},
+	"invalid_timeout":         {"en": "Invalid timeout", "de": "Ungültiges Timeout"},
+	"invalid_type":            {"en": "Invalid type parameter: %s", "de": "Ungültiger Typ-Parameter: %s"},
+	"invalid_upload_length":   {"en": "Invalid Upload-Length header", "de": "Ungültiger Upload-Length-Header"},
+	"invalid_upload_metadata": {"en": "Invalid Upload-Metadata header", "de": "Ungültiger Upload-Metadata-Header"},
+	"invalid_upload_offset":   {"en": "Invalid Upload-Offset header", "de": "Ungültiger Upload-Offset-Header"},
+	"invalid_upload_type":     {"en": "Chunks must be sent as application/offset+octet-stream", "de": "Teile müssen als application/offset+octet-stream gesendet werden"},
+	"tag_requires_post":       {"en": "Tags can only be used with posts", "de": "Tags können nur mit Posts verwendet werden"},
+	"invalid_validation":      {"en": "Invalid hash in validations: %s", "de": "Ungültiger Hash in den Validierungen: %s"},
+	"key_mismatch":            {"en": "Request was not signed by key %s", "de": "Anfrage wurde nicht mit Schlüssel %s signiert"},
+	"media_disabled":          {"en": "Media variants are not enabled", "de": "Medienvarianten sind nicht aktiviert"},
+	"missing_log":             {"en": "Missing log file", "de": "Logdatei fehlt"},
+	"missing_passphrase":      {"en": "Missing passphrase", "de": "Passphrase fehlt"},
+	"missing_peer":            {"en": "Missing peer parameter", "de": "Parameter peer fehlt"},
+	"no_results":              {"en": "No results found", "de": "Keine Ergebnisse gefunden"},
+	"not_an_image":            {"en": "requested site was not an image", "de": "angefragte Site ist kein Bild"},
+	"pin_quota":               {"en": "Pin quota exceeded, remove a pin first", "de": "Pin-Kontingent ausgeschöpft, bitte zuerst einen Pin entfernen"},
+	"quorum_disabled":         {"en": "Quorum is not enabled", "de": "Quorum ist nicht aktiviert"},
+	"rate_limited":            {"en": "Too many requests, please try again later", "de": "Zu viele Anfragen, bitte später erneut versuchen"},
+	"response_error":          {"en": "Error preparing response", "de": "Fehler beim Erstellen der Antwort"},
+	"site_hidden":             {"en": "This site is hidden on this node", "de": "Diese Site ist auf diesem Knoten ausgeblendet"},
+	"site_not_found":          {"en": "Site not found", "de": "Site nicht gefunden"},
+	"site_not_hidden":         {"en": "Site is not hidden", "de": "Site ist nicht ausgeblendet"},
+	"site_pinned":             {"en": "Site is pinned, remove its pins first", "de": "Site ist angeheftet, bitte zuerst die Pins entfernen"},
+	"site_removed":            {"en": "The content of this site was removed", "de": "Der Inhalt dieser Site wurde entfernt"},
+	"sql_index_disabled":      {"en": "SQL index is not enabled", "de": "SQL-Index ist nicht aktiviert"},
+	"submission_not_found":    {"en": "Submission not found", "de": "Einreichung nicht gefunden"},
+	"subscription_address":    {"en": "Subscriptions must not publish an email address, subscribe at /api/v1/digest/subscribe", "de": "Abonnements dürfen keine E-Mail-Adresse veröffentlichen, bitte unter /api/v1/digest/subscribe abonnieren"},
+	"task_running":            {"en": "Task %s is already running", "de": "Aufgabe %s läuft bereits"},
+	"timestamping_disabled":   {"en": "Timestamping is not enabled", "de": "Zeitstempel sind nicht aktiviert"},
+	"undecodable_hash":        {"en": "Could not decode provided hash", "de": "Angegebener Hash konnte nicht dekodiert werden"},
+	"unknown_log_module":      {"en": "Unknown log module: %s", "de": "Unbekanntes Protokollmodul: %s"},
+	"unknown_task":            {"en": "Unknown task: %s", "de": "Unbekannte Aufgabe: %s"},
+	"unknown_token":           {"en": "Unknown or expired confirmation link", "de": "Unbekannter oder abgelaufener Bestätigungslink"},
+	"unknown_validation":      {"en": "Tried to verify unknown site %s", "de": "Unbekannte Site %s sollte validiert werden"},
+	"upload_not_found":        {"en": "Upload not found", "de": "Upload nicht gefunden"},
+	"upload_offset_mismatch":  {"en": "Chunk does not start at offset %d", "de": "Teil beginnt nicht bei Position %d"},
+	"variant_not_found":       {"en": "Variant not found", "de": "Variante nicht gefunden"},
+	"variants_not_found":      {"en": "No variants were generated for this image yet", "de": "Für dieses Bild wurden noch keine Varianten erzeugt"},
+}
+
+// fail responds with the error message of key in the language negotiated from the Accept-Language header
+func fail(c echo.Context, status int, key string, args ...interface{}) error {
+	lang, m := message(c, key, args...)
+	c.Response().Header().Set("Content-Language", lang)
+	logging.Debugf("api", "Responding to %s %s with %d %s", c.Request().Method, c.Request().URL.Path, status, key)
+	return c.JSON(status, Error{Message: m, Code: status, Key: key})
+}
+
+// message returns the negotiated language and the message of the key in it
+func message(c echo.Context, key string, args ...interface{}) (string, string) {
+	lang := messages.Negotiate(c.Request().Header.Get("Accept-Language"))
+	m := messages.Message(key, lang)
+	if len(args) > 0 {
+		m = fmt.Sprintf(m, args...)
+	}
+	return lang, m
+}
+
+func (a *API) getMessages(c echo.Context) error {
+	lang := c.QueryParam("lang")
+	if lang == "" {
+		lang = messages.Negotiate(c.Request().Header.Get("Accept-Language"))
+	}
+	return c.JSON(http.StatusOK, struct {
+		Language  string            `json:"language"`
+		Languages []string          `json:"languages"`
+		Messages  map[string]string `json:"messages"`
+	}{Language: lang, Languages: messages.Languages(), Messages: messages.Translate(lang)})
+}
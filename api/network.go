@@ -0,0 +1,13 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+// getNetwork returns the statistics of all nodes recently learned through gossip
+func (a *API) getNetwork(c echo.Context) error {
+	return c.JSON(http.StatusOK, a.node.Network.List(time.Now()))
+}
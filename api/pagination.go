@@ -0,0 +1,71 @@
+package api
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo"
+)
+
+// Pagination is the paging metadata included in list responses.
+// Next and Prev are the values of the cursor parameter for the neighbouring pages
+type Pagination struct {
+	Limit int    `json:"limit"`
+	Total *int   `json:"total,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+}
+
+// limitParam parses the limit query parameter, falling back to 20 if it is missing or out of range
+func limitParam(c echo.Context) int {
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil || limit <= 0 || limit > MaxFeedLimit {
+		return 20
+	}
+	return limit
+}
+
+// offsetPage pages through a list of total entries using a numeric offset passed as the cursor parameter
+func offsetPage(c echo.Context, param string, total int) (int, int, *Pagination) {
+	limit := limitParam(c)
+	offset, _ := strconv.Atoi(c.QueryParam(param))
+	if offset < 0 || offset > total {
+		offset = 0
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	p := &Pagination{Limit: limit, Total: &total}
+	if end < total {
+		p.Next = strconv.Itoa(end)
+	}
+	if offset > 0 {
+		prev := offset - limit
+		if prev < 0 {
+			prev = 0
+		}
+		p.Prev = strconv.Itoa(prev)
+	}
+	return offset, end, paginate(c, param, p)
+}
+
+// paginate sets the RFC 5988 Link header pointing to the neighbouring pages
+func paginate(c echo.Context, param string, p *Pagination) *Pagination {
+	links := []string{}
+	for _, l := range []struct{ rel, cursor string }{{"next", p.Next}, {"prev", p.Prev}} {
+		if l.cursor == "" {
+			continue
+		}
+		u := *c.Request().URL
+		q := u.Query()
+		q.Set(param, l.cursor)
+		q.Set("limit", strconv.Itoa(p.Limit))
+		u.RawQuery = q.Encode()
+		links = append(links, "<"+u.RequestURI()+`>; rel="`+l.rel+`"`)
+	}
+	if len(links) > 0 {
+		c.Response().Header().Set("Link", strings.Join(links, ", "))
+	}
+	return p
+}
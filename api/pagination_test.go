@@ -0,0 +1,56 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOffsetPage(t *testing.T) {
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest("GET", "/api/v1/tangle?q=a&offset=20&limit=10", nil), rec)
+	start, end, p := offsetPage(c, "offset", 35)
+	assert.Equal(t, 20, start)
+	assert.Equal(t, 30, end)
+	assert.Equal(t, 35, *p.Total)
+	assert.Equal(t, "30", p.Next)
+	assert.Equal(t, "10", p.Prev)
+	assert.Equal(t, `</api/v1/tangle?limit=10&offset=30&q=a>; rel="next", </api/v1/tangle?limit=10&offset=10&q=a>; rel="prev"`, rec.Header().Get("Link"))
+
+	rec = httptest.NewRecorder()
+	c = e.NewContext(httptest.NewRequest("GET", "/api/v1/tangle?offset=5", nil), rec)
+	start, end, p = offsetPage(c, "offset", 5)
+	assert.Equal(t, 5, start)
+	assert.Equal(t, 5, end)
+	assert.Empty(t, p.Next)
+	assert.Equal(t, "0", p.Prev)
+}
+
+func TestFeedHugePage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "api")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	a, e := testAPI(t, dir)
+	defer a.node.Shutdown()
+	key := armoredKey(t)
+	for _, s := range []string{"first", "second", "third"} {
+		add(t, a.node.Tangle, signedPost(t, key, s))
+	}
+
+	rec := get(e, "/api/v1/feed?algorithm=latest&page=922337203685477581&limit=2")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	r := struct {
+		Page    int               `json:"page"`
+		Results []json.RawMessage `json:"results"`
+	}{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &r))
+	assert.Equal(t, 1, r.Page)
+	assert.Len(t, r.Results, 1)
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/pin"
+	"github.com/u-speak/core/post"
+)
+
+func (a *API) getPins(c echo.Context) error {
+	ps, err := a.node.Pins.List(c.QueryParam("owner"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Error{Message: err.Error(), Code: http.StatusInternalServerError})
+	}
+	return c.JSON(http.StatusOK, ps)
+}
+
+// postPin executes a pin command signed by a user. Users can only remove their own pins
+func (a *API) postPin(c echo.Context) error {
+	p := &post.Post{}
+	if err := c.Bind(p); err != nil {
+		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	}
+	if err := verifyGPG(p); err != nil {
+		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	}
+	cmd, err := pin.ParseCommand(p.Content)
+	if err == nil {
+		err = cmd.Check(time.Now())
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	}
+	return a.pin(c, cmd.Pin, cmd.Hash.String(), p.Fingerprint())
+}
+
+func (a *API) addPin(c echo.Context) error {
+	return a.pin(c, true, c.Param("hash"), "")
+}
+
+func (a *API) removePin(c echo.Context) error {
+	return a.pin(c, false, c.Param("hash"), "")
+}
+
+func (a *API) pin(c echo.Context, add bool, hs, owner string) error {
+	h, err := DecodeHash(hs)
+	if err != nil {
+		return fail(c, http.StatusBadRequest, "invalid_base64")
+	}
+	if !add {
+		err := a.node.Pins.Remove(h, owner)
+		if err == pin.ErrNotPinned {
+			return c.JSON(http.StatusNotFound, Error{Message: err.Error(), Code: http.StatusNotFound})
+		}
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, Error{Message: err.Error(), Code: http.StatusInternalServerError})
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+	if a.node.Tangle.GetSite(h) == nil {
+		return fail(c, http.StatusNotFound, "site_not_found")
+	}
+	p, err := a.node.Pins.Add(h, owner)
+	if err == pin.ErrQuota {
+		return fail(c, http.StatusForbidden, "pin_quota")
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Error{Message: err.Error(), Code: http.StatusInternalServerError})
+	}
+	return c.JSON(http.StatusCreated, p)
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/config"
+	"github.com/u-speak/core/node"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/validate"
+)
+
+// Policy describes the limits and rules of a node, so clients can adapt to it and show it to users
+type Policy struct {
+	MaxContentSize int             `json:"max_content_size"`
+	AcceptedTypes  []string        `json:"accepted_types"`
+	Difficulty     int             `json:"difficulty"`
+	Retention      int             `json:"retention_days"`
+	Contact        string          `json:"moderation_contact,omitempty"`
+	Terms          string          `json:"terms,omitempty"`
+	Capabilities   map[string]bool `json:"capabilities"`
+	// Requirements are the weight and validations new sites need by type
+	Requirements map[string]tangle.Requirement `json:"requirements"`
+	// PoW is the algorithm the weight of new sites is computed with
+	PoW string `json:"pow"`
+	// MaxTipAge is the amount of minutes a new site may be mined before it is submitted, sites need a timestamp if it is set
+	MaxTipAge int `json:"max_tip_age,omitempty"`
+}
+
+func newPolicy(c config.Configuration, t *tangle.Tangle) *Policy {
+	p := &Policy{
+		MaxContentSize: node.MaxMsgSize,
+		AcceptedTypes:  []string{},
+		Requirements:   make(map[string]tangle.Requirement),
+		PoW:            t.PoW().Name(),
+		MaxTipAge:      c.NodeNetwork.MaxTipAge,
+		Retention:      c.Policy.Retention,
+		Contact:        c.Policy.Contact,
+		Terms:          c.Policy.Terms,
+		Capabilities: map[string]bool{
+			"anchoring":    c.Anchor.Enabled,
+			"challenges":   c.Challenge.Enabled,
+			"custody":      c.Custody.Enabled,
+			"digest":       c.Digest.Enabled,
+			"flags":        c.Flags.Classifier != "none",
+			"quorum":       c.Quorum.Enabled,
+			"sql_index":    c.SQLIndex.Enabled,
+			"timestamping": c.TSA.Enabled,
+		},
+	}
+	if c.Challenge.Enabled {
+		p.Difficulty = c.Challenge.Difficulty
+	}
+	for typ, ok := range validate.Types {
+		if ok {
+			p.AcceptedTypes = append(p.AcceptedTypes, typ)
+			p.Requirements[typ] = t.Required(typ)
+		}
+	}
+	sort.Strings(p.AcceptedTypes)
+	return p
+}
+
+func (a *API) getPolicy(c echo.Context) error {
+	return c.JSON(http.StatusOK, a.policy)
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/portability"
+	"github.com/u-speak/core/post"
+)
+
+// exportIdentity returns all sites signed by a key as a zip archive. The request has to be signed
+// by the same key, so only the owner of the data can export it
+func (a *API) exportIdentity(c echo.Context) error {
+	fp := strings.ToLower(c.Param("fingerprint"))
+	p := &post.Post{}
+	if err := c.Bind(p); err != nil {
+		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	}
+	if err := verifyGPG(p); err != nil {
+		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	}
+	r, err := portability.ParseRequest(p.Content)
+	if err == nil {
+		err = r.Check(time.Now())
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	}
+	if p.Fingerprint() != fp || r.Fingerprint != fp {
+		return fail(c, http.StatusForbidden, "key_mismatch", fp)
+	}
+	objs := portability.Authored(a.node.Tangle, fp)
+	c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename="+fp+".zip")
+	c.Response().Header().Set(echo.HeaderContentType, "application/zip")
+	c.Response().WriteHeader(http.StatusOK)
+	return portability.Write(c.Response(), fp, objs, a.node.Tangle.PoW(), time.Now())
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/quorum"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+type jsonPending struct {
+	Site     jsonSite  `json:"site"`
+	Created  time.Time `json:"created"`
+	Acks     []string  `json:"acks"`
+	Required int       `json:"required"`
+}
+
+func (a *API) jsonizePending(p *quorum.Pending) (*jsonPending, error) {
+	if err := p.Object.Data.JSON(); err != nil {
+		return nil, err
+	}
+	acks := []string{}
+	for peer := range p.Acks {
+		acks = append(acks, peer)
+	}
+	sort.Strings(acks)
+	return &jsonPending{Site: JSONize(p.Object), Created: p.Created, Acks: acks, Required: a.node.Quorum.Size()}, nil
+}
+
+// pending returns the pending site with the given hash or nil if it is not waiting for quorum
+func (a *API) pending(h hash.Hash) *jsonPending {
+	if a.node.Quorum == nil {
+		return nil
+	}
+	p := a.node.Quorum.Get(h)
+	if p == nil {
+		return nil
+	}
+	j, err := a.jsonizePending(p)
+	if err != nil {
+		return nil
+	}
+	return j
+}
+
+func (a *API) getPending(c echo.Context) error {
+	if a.node.Quorum == nil {
+		return fail(c, http.StatusNotFound, "quorum_disabled")
+	}
+	res := []*jsonPending{}
+	for _, p := range a.node.Quorum.Pending() {
+		j, err := a.jsonizePending(p)
+		if err != nil {
+			return fail(c, http.StatusInternalServerError, "response_error")
+		}
+		res = append(res, j)
+	}
+	return c.JSON(http.StatusOK, res)
+}
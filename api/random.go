@@ -0,0 +1,51 @@
+package api
+
+import (
+	"math/rand"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+// randomTypes are the site types a random site can be picked from. Only types kept in the date index are offered,
+// so picking never walks the whole tangle
+var randomTypes = map[string]bool{"post": true, "follow": true, "subscription": true}
+
+// candidates returns the hashes of all sites of the type, restricted to posts with the tag if one is given
+func (a *API) candidates(typ, tag string) []hash.Hash {
+	if tag != "" {
+		return a.node.Tags.Get(tag)
+	}
+	return a.node.Dates.Range(typ, 0, 0)
+}
+
+// getRandomSite returns a site picked uniformly from all sites matching the filters
+func (a *API) getRandomSite(c echo.Context) error {
+	typ := c.QueryParam("type")
+	if typ == "" {
+		typ = "post"
+	}
+	if !randomTypes[typ] {
+		return fail(c, http.StatusBadRequest, "invalid_type", typ)
+	}
+	tag := strings.ToLower(strings.TrimPrefix(c.QueryParam("tag"), "#"))
+	if tag != "" && typ != "post" {
+		return fail(c, http.StatusBadRequest, "tag_requires_post")
+	}
+	hs := a.visible(a.candidates(typ, tag))
+	if len(hs) == 0 {
+		return fail(c, http.StatusNotFound, "no_results")
+	}
+	o := a.node.Tangle.Get(hs[rand.Intn(len(hs))])
+	if o == nil {
+		return fail(c, http.StatusNotFound, "site_not_found")
+	}
+	if err := o.Data.JSON(); err != nil {
+		return fail(c, http.StatusInternalServerError, "response_error")
+	}
+	j := a.listed(o)
+	j.Weight = a.node.Tangle.Weight(o.Site)
+	return c.JSON(http.StatusOK, j)
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+// parseDate accepts unix timestamps and RFC 3339 dates. An empty string yields zero
+func parseDate(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if ts, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return ts, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Unix(), nil
+}
+
+func (a *API) getRange(c echo.Context) error {
+	typ := c.Param("type")
+	switch typ {
+	case "post", "follow", "subscription":
+	default:
+		return fail(c, http.StatusBadRequest, "invalid_type", typ)
+	}
+	from, err := parseDate(c.QueryParam("from"))
+	if err != nil {
+		return fail(c, http.StatusBadRequest, "invalid_date", c.QueryParam("from"))
+	}
+	to, err := parseDate(c.QueryParam("to"))
+	if err != nil {
+		return fail(c, http.StatusBadRequest, "invalid_date", c.QueryParam("to"))
+	}
+	hs := a.visible(a.node.Dates.Range(typ, from, to))
+	start, end, pg := offsetPage(c, "offset", len(hs))
+	results := []jsonSite{}
+	for _, h := range hs[start:end] {
+		o := a.node.Tangle.Get(h)
+		if o == nil {
+			continue
+		}
+		if err := o.Data.JSON(); err != nil {
+			return fail(c, http.StatusInternalServerError, "response_error")
+		}
+		results = append(results, a.listed(o))
+	}
+	return c.JSON(http.StatusOK, struct {
+		Results    []jsonSite  `json:"results"`
+		Pagination *Pagination `json:"pagination"`
+	}{Results: results, Pagination: pg})
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/ratelimit"
+)
+
+// limited is a middleware rejecting requests of clients which exceeded the limit.
+// Clients are identified by the address of the connection, since forwarding headers are chosen by the client
+func limited(l *ratelimit.Limiter) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !l.Allow(client(c), time.Now()) {
+				return fail(c, http.StatusTooManyRequests, "rate_limited")
+			}
+			return next(c)
+		}
+	}
+}
+
+// client returns the address of the connection the request arrived on
+func client(c echo.Context) string {
+	h, _, err := net.SplitHostPort(c.Request().RemoteAddr)
+	if err != nil {
+		return c.Request().RemoteAddr
+	}
+	return h
+}
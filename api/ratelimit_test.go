@@ -0,0 +1,30 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/ratelimit"
+)
+
+func TestLimited(t *testing.T) {
+	e := echo.New()
+	h := limited(ratelimit.New(1, time.Minute, 10))(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	serve := func(remote, forwarded string) int {
+		req := httptest.NewRequest(echo.POST, "/api/v1/validate", nil)
+		req.RemoteAddr = remote
+		req.Header.Set(echo.HeaderXForwardedFor, forwarded)
+		rec := httptest.NewRecorder()
+		assert.NoError(t, h(e.NewContext(req, rec)))
+		return rec.Code
+	}
+	assert.Equal(t, http.StatusOK, serve("10.0.0.1:1234", "1.1.1.1"))
+	assert.Equal(t, http.StatusTooManyRequests, serve("10.0.0.1:1235", "2.2.2.2"))
+	assert.Equal(t, http.StatusOK, serve("10.0.0.2:1234", "1.1.1.1"))
+}
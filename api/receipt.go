@@ -0,0 +1,20 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+)
+
+// getReceipt returns the receipt this node signed when it accepted the site
+func (a *API) getReceipt(c echo.Context) error {
+	h, err := DecodeHash(c.Param("hash"))
+	if err != nil {
+		return fail(c, http.StatusBadRequest, "invalid_base64")
+	}
+	r, err := a.node.Receipts.Get(h)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, Error{Message: err.Error(), Code: http.StatusNotFound})
+	}
+	return c.JSON(http.StatusOK, r)
+}
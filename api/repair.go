@@ -0,0 +1,22 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+)
+
+// getRepair lists the damage of the stores without changing them
+func (a *API) getRepair(c echo.Context) error {
+	return c.JSON(http.StatusOK, a.node.Tangle.Check())
+}
+
+// postRepair repairs the stores, fetching from the peer parameter or all connected peers.
+// With truncate=true sites and payloads which can not be fetched are removed
+func (a *API) postRepair(c echo.Context) error {
+	r := a.node.Repair(c.QueryParam("peer"), c.QueryParam("truncate") == "true")
+	if len(r.Remaining) > 0 {
+		return c.JSON(http.StatusConflict, r)
+	}
+	return c.JSON(http.StatusOK, r)
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/replay"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+func (a *API) getExport(c echo.Context) error {
+	c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename=tangle.log")
+	c.Response().Header().Set(echo.HeaderContentType, echo.MIMEOctetStream)
+	c.Response().WriteHeader(http.StatusOK)
+	return replay.Export(a.node.Tangle, c.Response())
+}
+
+func (a *API) getExportHashes(c echo.Context) error {
+	buf := &bytes.Buffer{}
+	if err := replay.Export(a.node.Tangle, buf); err != nil {
+		return c.JSON(http.StatusInternalServerError, Error{Message: err.Error(), Code: http.StatusInternalServerError})
+	}
+	hs, err := replay.Hashes(buf, a.node.Tangle.Rules())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Error{Message: err.Error(), Code: http.StatusInternalServerError})
+	}
+	return streamArray(c, func(emit func(interface{}) error) error {
+		for _, h := range hs {
+			if err := emit(h); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (a *API) getExportSites(c echo.Context) error {
+	hs := a.node.Tangle.Hashes()
+	sort.Slice(hs, func(i, j int) bool { return hs[i].String() < hs[j].String() })
+	return streamArray(c, func(emit func(interface{}) error) error {
+		for _, h := range hs {
+			o := a.node.Tangle.Get(h)
+			if o == nil {
+				continue
+			}
+			if err := o.Data.JSON(); err != nil {
+				return err
+			}
+			if err := emit(JSONize(o)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (a *API) postReplay(c echo.Context) error {
+	lf, err := c.FormFile("log")
+	if err != nil {
+		return fail(c, http.StatusBadRequest, "missing_log")
+	}
+	l, err := lf.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	}
+	defer l.Close()
+	var expected []hash.Hash
+	if e := c.FormValue("expected"); e != "" {
+		if err := json.Unmarshal([]byte(e), &expected); err != nil {
+			return fail(c, http.StatusBadRequest, "invalid_expected")
+		}
+	}
+	res, err := replay.Replay(l, a.node.Tangle.Rules(), expected, nil)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	}
+	return c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/schedule"
+)
+
+func (a *API) getSchedule(c echo.Context) error {
+	return c.JSON(http.StatusOK, a.node.Scheduler.List())
+}
+
+// runTask starts a scheduled task immediately
+func (a *API) runTask(c echo.Context) error {
+	switch err := a.node.Scheduler.Run(c.Param("task")); err {
+	case nil:
+		return c.NoContent(http.StatusAccepted)
+	case schedule.ErrUnknownTask:
+		return fail(c, http.StatusNotFound, "unknown_task", c.Param("task"))
+	case schedule.ErrRunning:
+		return fail(c, http.StatusConflict, "task_running", c.Param("task"))
+	default:
+		return c.JSON(http.StatusInternalServerError, Error{Message: err.Error(), Code: http.StatusInternalServerError})
+	}
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+)
+
+// getSources lists the connected peers ranked as sync sources, best first
+func (a *API) getSources(c echo.Context) error {
+	return c.JSON(http.StatusOK, a.node.SyncSources())
+}
+
+// putSource makes the connected peer the first sync source regardless of its rank
+func (a *API) putSource(c echo.Context) error {
+	e := struct {
+		Address string `json:"address" form:"address"`
+	}{}
+	if err := c.Bind(&e); err != nil {
+		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	}
+	if e.Address == "" {
+		return fail(c, http.StatusBadRequest, "missing_peer")
+	}
+	if err := a.node.PreferSource(e.Address); err != nil {
+		return c.JSON(http.StatusNotFound, Error{Message: err.Error(), Code: http.StatusNotFound})
+	}
+	return a.getSources(c)
+}
+
+// deleteSource returns to ranking the sync sources by their measurements
+func (a *API) deleteSource(c echo.Context) error {
+	_ = a.node.PreferSource("")
+	return c.NoContent(http.StatusNoContent)
+}
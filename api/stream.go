@@ -0,0 +1,44 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/labstack/echo"
+	log "github.com/sirupsen/logrus"
+)
+
+// streamFlushEvery is the amount of array elements written between flushes
+const streamFlushEvery = 100
+
+// streamArray writes a json array element by element instead of encoding it in memory.
+// each is called with a function emitting the next element.
+// Once streaming started errors can not be reported to the client anymore, so the array is cut off and the error is logged
+func streamArray(c echo.Context, each func(emit func(interface{}) error) error) error {
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+	res.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(res)
+	n := 0
+	if _, err := res.Write([]byte("[")); err != nil {
+		return err
+	}
+	err := each(func(v interface{}) error {
+		if n > 0 {
+			if _, err := res.Write([]byte(",")); err != nil {
+				return err
+			}
+		}
+		n++
+		if n%streamFlushEvery == 0 {
+			res.Flush()
+		}
+		return enc.Encode(v)
+	})
+	if err != nil {
+		log.Errorf("Aborted streaming response: %s", err)
+		return nil
+	}
+	_, err = res.Write([]byte("]"))
+	return err
+}
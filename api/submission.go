@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/decision"
+	"github.com/u-speak/core/node"
+	"github.com/u-speak/core/submission"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/watchdog"
+)
+
+func (a *API) submitAsync(c echo.Context, o *tangle.Object, check func() error, tr *decision.Trace) error {
+	s, err := a.node.SubmitAsync(o, check)
+	tr.Check("queue", err)
+	if err != nil {
+		tr.Reject(err)
+	}
+	if err == node.ErrMaintenance {
+		return unavailable(c, err)
+	}
+	if err == watchdog.ErrDiskFull {
+		return c.JSON(http.StatusInsufficientStorage, Error{Message: err.Error(), Code: http.StatusInsufficientStorage})
+	}
+	if err == submission.ErrQueueFull {
+		return c.JSON(http.StatusServiceUnavailable, Error{Message: err.Error(), Code: http.StatusServiceUnavailable})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Error{Message: err.Error(), Code: http.StatusInternalServerError})
+	}
+	tr.Finish(decision.Queued, nil)
+	c.Response().Header().Set(echo.HeaderLocation, "/api/v1/submissions/"+s.ID)
+	return c.JSON(http.StatusAccepted, s)
+}
+
+func (a *API) getSubmission(c echo.Context) error {
+	s := a.node.Submissions.Get(c.Param("id"))
+	if s == nil {
+		return fail(c, http.StatusNotFound, "submission_not_found")
+	}
+	return c.JSON(http.StatusOK, s)
+}
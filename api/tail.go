@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/tail"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+func (a *API) getTail(c echo.Context) error {
+	var since hash.Hash
+	next := ""
+	if l := a.node.Tail.Latest(); l != (hash.Hash{}) {
+		next = l.String()
+	}
+	if s := c.QueryParam("since"); s != "" {
+		h, err := DecodeHash(s)
+		if err != nil {
+			return fail(c, http.StatusBadRequest, "invalid_since")
+		}
+		since = h
+		next = s
+	}
+	timeout := a.tailTimeout
+	if t := c.QueryParam("timeout"); t != "" {
+		secs, err := strconv.Atoi(t)
+		if err != nil || secs < 0 {
+			return fail(c, http.StatusBadRequest, "invalid_timeout")
+		}
+		if d := time.Duration(secs) * time.Second; d < timeout {
+			timeout = d
+		}
+	}
+	hs, err := a.node.Tail.Wait(since, c.QueryParam("type"), timeout)
+	if err == tail.ErrUnknownCursor {
+		return c.JSON(http.StatusGone, Error{Message: err.Error(), Code: http.StatusGone})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Error{Message: err.Error(), Code: http.StatusInternalServerError})
+	}
+	res := struct {
+		Results []jsonSite `json:"results"`
+		Next    string     `json:"next"`
+	}{Results: []jsonSite{}, Next: next}
+	for _, h := range hs {
+		o := a.node.Tangle.Get(h)
+		if o == nil {
+			continue
+		}
+		if a.hidden(h) {
+			res.Next = h.String()
+			continue
+		}
+		if err := o.Data.JSON(); err != nil {
+			return fail(c, http.StatusInternalServerError, "response_error")
+		}
+		res.Results = append(res.Results, a.listed(o))
+		res.Next = h.String()
+	}
+	return c.JSON(http.StatusOK, res)
+}
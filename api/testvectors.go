@@ -0,0 +1,30 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/testvectors"
+)
+
+// getTestVectors returns the protocol test vectors, so other implementations can check themselves against them
+func (a *API) getTestVectors(c echo.Context) error {
+	vs, err := testvectors.Load()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Error{Message: err.Error(), Code: http.StatusInternalServerError})
+	}
+	return c.JSON(http.StatusOK, vs)
+}
+
+// verifyTestVectors checks the implementation of this node against the test vectors
+func (a *API) verifyTestVectors(c echo.Context) error {
+	fs, err := testvectors.Verify()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Error{Message: err.Error(), Code: http.StatusInternalServerError})
+	}
+	return c.JSON(http.StatusOK, struct {
+		Passed   bool                  `json:"passed"`
+		Version  string                `json:"version"`
+		Failures []testvectors.Failure `json:"failures"`
+	}{Passed: len(fs) == 0, Version: a.node.Version, Failures: fs})
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/follow"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/timeline"
+)
+
+func (a *API) getTimeline(c echo.Context) error {
+	l := a.node.Follows.Get(strings.ToLower(c.Param("fingerprint")))
+	if l == nil {
+		return fail(c, http.StatusNotFound, "follow_list_not_found")
+	}
+	return a.timeline(c, l)
+}
+
+func (a *API) postTimeline(c echo.Context) error {
+	l := &follow.List{}
+	if err := c.Bind(l); err != nil {
+		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	}
+	if err := verifyGPG(l); err != nil {
+		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	}
+	return a.timeline(c, l)
+}
+
+func (a *API) timeline(c echo.Context, l *follow.List) error {
+	limit := limitParam(c)
+	cursor := hash.Hash{}
+	var err error
+	if cs := c.QueryParam("cursor"); cs != "" {
+		cursor, err = DecodeHash(cs)
+		if err != nil {
+			return fail(c, http.StatusBadRequest, "invalid_cursor")
+		}
+	}
+	pg, err := timeline.Assemble(a.node.Tangle, l, cursor, limit)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	}
+	res := struct {
+		Results    []jsonSite  `json:"results"`
+		Next       string      `json:"next,omitempty"`
+		Pagination *Pagination `json:"pagination"`
+	}{Results: []jsonSite{}}
+	for _, o := range pg.Objects {
+		if a.hidden(o.Site.Hash()) {
+			continue
+		}
+		if err := o.Data.JSON(); err != nil {
+			return fail(c, http.StatusInternalServerError, "response_error")
+		}
+		res.Results = append(res.Results, a.listed(o))
+	}
+	if pg.Next != (hash.Hash{}) {
+		res.Next = pg.Next.String()
+	}
+	res.Pagination = paginate(c, "cursor", &Pagination{Limit: limit, Next: res.Next})
+	return c.JSON(http.StatusOK, res)
+}
+
+func (a *API) getFollowing(c echo.Context) error {
+	authors, tags := a.node.Follows.Following(strings.ToLower(c.Param("fingerprint")))
+	start, end, pg := offsetPage(c, "offset", len(authors))
+	return c.JSON(http.StatusOK, struct {
+		Count        int         `json:"count"`
+		Fingerprints []string    `json:"fingerprints"`
+		Tags         []string    `json:"tags"`
+		Pagination   *Pagination `json:"pagination"`
+	}{Count: len(authors), Fingerprints: authors[start:end], Tags: tags, Pagination: pg})
+}
+
+func (a *API) getFollowers(c echo.Context) error {
+	fs := a.node.Follows.Followers(strings.ToLower(c.Param("fingerprint")))
+	start, end, pg := offsetPage(c, "offset", len(fs))
+	return c.JSON(http.StatusOK, struct {
+		Count        int         `json:"count"`
+		Fingerprints []string    `json:"fingerprints"`
+		Pagination   *Pagination `json:"pagination"`
+	}{Count: len(fs), Fingerprints: fs[start:end], Pagination: pg})
+}
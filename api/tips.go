@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+// MaxTipCandidates is the largest amount of selection candidates returned by getTips
+const MaxTipCandidates = 32
+
+type jsonTip struct {
+	Hash   string `json:"hash"`
+	Weight int    `json:"weight"`
+	Age    int64  `json:"age"`
+}
+
+type jsonTips struct {
+	Tips       []jsonTip `json:"tips"`
+	Candidates []string  `json:"candidates,omitempty"`
+}
+
+// getTips lists the current tips with their weight and age in seconds.
+// The candidates parameter requests that many sites from the tip selection
+func (a *API) getTips(c echo.Context) error {
+	n := 0
+	if p := c.QueryParam("candidates"); p != "" {
+		var err error
+		n, err = strconv.Atoi(p)
+		if err != nil || n < 0 || n > MaxTipCandidates {
+			return fail(c, http.StatusBadRequest, "invalid_candidates", MaxTipCandidates)
+		}
+	}
+	now := time.Now()
+	res := jsonTips{Tips: []jsonTip{}}
+	for _, s := range a.node.Tangle.Tips() {
+		t := jsonTip{Hash: s.Hash().String(), Weight: a.node.Tangle.Weight(s)}
+		if since, ok := a.node.Tangle.TipSince(s.Hash()); ok {
+			t.Age = int64(now.Sub(since) / time.Second)
+		}
+		res.Tips = append(res.Tips, t)
+	}
+	if n > 0 {
+		for _, s := range a.node.Tangle.SelectTips(n) {
+			res.Candidates = append(res.Candidates, s.Hash().String())
+		}
+	}
+	return c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/node"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tombstone"
+)
+
+// notFound responds with 410 Gone for sites whose payload was removed or which are hidden, and 404 for unknown sites
+func (a *API) notFound(c echo.Context, h hash.Hash) error {
+	if a.hidden(h) {
+		return fail(c, http.StatusGone, "site_hidden")
+	}
+	if _, buried := a.node.Tangle.Buried(h); buried {
+		return fail(c, http.StatusGone, "site_removed")
+	}
+	return fail(c, http.StatusNotFound, "site_not_found")
+}
+
+// authorize checks that the site removed by the tombstone exists and belongs to its signer
+func (a *API) authorize(ts *tombstone.Tombstone) error {
+	target, err := ts.Target()
+	if err != nil {
+		return err
+	}
+	o := a.node.Tangle.Get(target)
+	if o == nil {
+		return tombstone.ErrNotAuthor
+	}
+	return ts.Authorize(o.Data)
+}
+
+// getTombstone reports whether the payload of a site was removed, and by which tombstone.
+// Payloads hidden by the operator of this node have no tombstone
+func (a *API) getTombstone(c echo.Context) error {
+	h, err := DecodeHash(c.Param("hash"))
+	if err != nil {
+		return fail(c, http.StatusBadRequest, "invalid_base64")
+	}
+	if a.node.Tangle.GetSite(h) == nil {
+		return fail(c, http.StatusNotFound, "site_not_found")
+	}
+	by, buried := a.node.Tangle.Buried(h)
+	r := struct {
+		Hash      hash.Hash  `json:"hash"`
+		Removed   bool       `json:"removed"`
+		Tombstone *hash.Hash `json:"tombstone,omitempty"`
+	}{Hash: h, Removed: buried}
+	if buried && by != (hash.Hash{}) {
+		r.Tombstone = &by
+	}
+	return c.JSON(http.StatusOK, r)
+}
+
+// removeSite hides the payload of a site on this node only, without publishing a tombstone
+func (a *API) removeSite(c echo.Context) error {
+	h, err := DecodeHash(c.Param("hash"))
+	if err != nil {
+		return fail(c, http.StatusBadRequest, "invalid_base64")
+	}
+	if a.node.Tangle.GetSite(h) == nil {
+		return fail(c, http.StatusNotFound, "site_not_found")
+	}
+	err = a.node.Remove(h, hash.Hash{})
+	if err == node.ErrPinned {
+		return fail(c, http.StatusConflict, "site_pinned")
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Error{Message: err.Error(), Code: http.StatusInternalServerError})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
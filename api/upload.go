@@ -0,0 +1,106 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/decision"
+	"github.com/u-speak/core/node"
+	"github.com/u-speak/core/upload"
+)
+
+// TusVersion is the version of the tus protocol spoken by the upload endpoints
+const TusVersion = "1.0.0"
+
+// createUpload starts a resumable image upload. The length of the image is given in the Upload-Length header,
+// the form fields of uploadImage (nonce, hash, validates, timestamp and async) in the Upload-Metadata header
+func (a *API) createUpload(c echo.Context) error {
+	c.Response().Header().Set("Tus-Resumable", TusVersion)
+	if a.node.InMaintenance() {
+		return unavailable(c, node.ErrMaintenance)
+	}
+	length, err := strconv.ParseInt(c.Request().Header.Get("Upload-Length"), 10, 64)
+	if err != nil {
+		return fail(c, http.StatusBadRequest, "invalid_upload_length")
+	}
+	meta, err := upload.ParseMetadata(c.Request().Header.Get("Upload-Metadata"))
+	if err != nil {
+		return fail(c, http.StatusBadRequest, "invalid_upload_metadata")
+	}
+	if _, err := DecodeHash(meta["hash"]); err != nil {
+		return fail(c, http.StatusBadRequest, "invalid_hash_field")
+	}
+	s, err := a.node.Uploads.Create(length, meta)
+	if err == upload.ErrTooLarge {
+		return fail(c, http.StatusRequestEntityTooLarge, "image_too_large")
+	}
+	if err == upload.ErrTooMany {
+		return c.JSON(http.StatusServiceUnavailable, Error{Message: err.Error(), Code: http.StatusServiceUnavailable})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Error{Message: err.Error(), Code: http.StatusInternalServerError})
+	}
+	c.Response().Header().Set(echo.HeaderLocation, "/api/v1/uploads/"+s.ID)
+	return c.NoContent(http.StatusCreated)
+}
+
+func (a *API) headUpload(c echo.Context) error {
+	c.Response().Header().Set("Tus-Resumable", TusVersion)
+	c.Response().Header().Set("Cache-Control", "no-store")
+	s := a.node.Uploads.Get(c.Param("id"))
+	if s == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+	c.Response().Header().Set("Upload-Offset", strconv.FormatInt(s.Offset, 10))
+	c.Response().Header().Set("Upload-Length", strconv.FormatInt(s.Length, 10))
+	return c.NoContent(http.StatusOK)
+}
+
+// patchUpload appends a chunk to the upload. The image is submitted as soon as all of it arrived
+func (a *API) patchUpload(c echo.Context) error {
+	c.Response().Header().Set("Tus-Resumable", TusVersion)
+	if c.Request().Header.Get(echo.HeaderContentType) != "application/offset+octet-stream" {
+		return fail(c, http.StatusUnsupportedMediaType, "invalid_upload_type")
+	}
+	offset, err := strconv.ParseInt(c.Request().Header.Get("Upload-Offset"), 10, 64)
+	if err != nil {
+		return fail(c, http.StatusBadRequest, "invalid_upload_offset")
+	}
+	id := c.Param("id")
+	s, err := a.node.Uploads.Append(id, offset, c.Request().Body)
+	switch err {
+	case nil:
+	case upload.ErrNotFound:
+		return fail(c, http.StatusNotFound, "upload_not_found")
+	case upload.ErrOffset:
+		return fail(c, http.StatusConflict, "upload_offset_mismatch", s.Offset)
+	case upload.ErrBusy:
+		return c.JSON(http.StatusConflict, Error{Message: err.Error(), Code: http.StatusConflict})
+	case upload.ErrTooLarge:
+		return fail(c, http.StatusRequestEntityTooLarge, "image_too_large")
+	default:
+		return c.JSON(http.StatusInternalServerError, Error{Message: err.Error(), Code: http.StatusInternalServerError})
+	}
+	c.Response().Header().Set("Upload-Offset", strconv.FormatInt(s.Offset, 10))
+	if !s.Complete() {
+		return c.NoContent(http.StatusNoContent)
+	}
+	raw, err := a.node.Uploads.Data(id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Error{Message: err.Error(), Code: http.StatusInternalServerError})
+	}
+	a.node.Uploads.Remove(id)
+	field := func(k string) string { return s.Metadata[k] }
+	tr := a.node.Decisions.Begin(decision.SourceAPI)
+	return a.submitImage(c, tr, field, raw, s.Metadata["async"] == "true" || c.QueryParam("async") == "true")
+}
+
+func (a *API) deleteUpload(c echo.Context) error {
+	c.Response().Header().Set("Tus-Resumable", TusVersion)
+	if a.node.Uploads.Get(c.Param("id")) == nil {
+		return fail(c, http.StatusNotFound, "upload_not_found")
+	}
+	a.node.Uploads.Remove(c.Param("id"))
+	return c.NoContent(http.StatusNoContent)
+}
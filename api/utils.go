@@ -1,15 +1,13 @@
 package api
 
 import (
-	"encoding/base64"
-	"errors"
 	"strings"
 
-	"github.com/u-speak/core/post"
 	"github.com/u-speak/core/tangle"
 	"github.com/u-speak/core/tangle/datastore"
 	"github.com/u-speak/core/tangle/hash"
 	"github.com/u-speak/core/util"
+	"github.com/u-speak/core/validate"
 )
 
 // JSONize converts an object into a jsonSite
@@ -25,11 +23,23 @@ func JSONize(o *tangle.Object) jsonSite {
 		Validates:    vals,
 		Content:      o.Site.Content.String(),
 		Type:         o.Site.Type,
+		Timestamp:    o.Site.Timestamp,
 		BubbleBabble: util.EncodeBubbleBabble(h),
 		Data:         o.Data,
 	}
 }
 
+// listed converts an object into a jsonSite for list responses, including the preview of posts
+func (a *API) listed(o *tangle.Object) jsonSite {
+	j := JSONize(o)
+	if p, ok := a.node.Previews.Get(o.Site.Hash()); ok {
+		j.Preview = &p
+	}
+	j.Lang = a.node.Langs.Get(o.Site.Hash())
+	j.Flags = a.flags(o.Site.Hash())
+	return j
+}
+
 func decodeImageHash(s string) (hash.Hash, string) {
 	a := strings.Split(s, ".")
 	h, _ := DecodeHash(a[0])
@@ -47,40 +57,12 @@ func decodeImageHash(s string) (hash.Hash, string) {
 
 // DecodeHash is a utility function, allowing the decoding of various formats
 func DecodeHash(s string) (hash.Hash, error) {
-	h := [32]byte{}
-	var hs []byte
-	h, err := util.DecodeBubbleBabble(s)
-	if err == nil {
-		return h, nil
-	}
-	hs, err = base64.URLEncoding.DecodeString(s)
-	if err == nil {
-		copy(h[:], hs)
-		return h, nil
-	}
-	hs, err = base64.StdEncoding.DecodeString(s)
-	if err == nil {
-		copy(h[:], hs)
-		return h, nil
-	}
-	hs, err = base64.RawURLEncoding.DecodeString(s)
-	if err == nil {
-		copy(h[:], hs)
-		return h, nil
-	}
-	hs, err = base64.RawStdEncoding.DecodeString(s)
-	if err == nil {
-		copy(h[:], hs)
-		return h, nil
-	}
-	return [32]byte{}, errors.New("Could not parse base64 data")
+	return validate.Hash(s)
 }
 
 func verifyGPG(s datastore.Serializable) error {
-	err := s.ReInit()
-	if err != nil {
+	if err := validate.Payload(s); err != nil {
 		return err
 	}
-	_, err = s.(*post.Post).Verify()
-	return err
+	return validate.Signature(s)
 }
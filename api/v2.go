@@ -0,0 +1,96 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/datastore"
+	"github.com/u-speak/core/util"
+
+	"golang.org/x/crypto/openpgp"
+)
+
+// SchemaVersion is the version of the site representation returned by the /api/v2 endpoints
+const SchemaVersion = 2
+
+// siteV2 is the versioned site representation. Everything clients would otherwise
+// have to compute themselves, like hash encodings and signature checks, is done by the server
+type siteV2 struct {
+	Version      int                    `json:"version"`
+	Hash         hashV2                 `json:"hash"`
+	Type         string                 `json:"type"`
+	Nonce        uint64                 `json:"nonce"`
+	Content      string                 `json:"content"`
+	Validates    []string               `json:"validates"`
+	Fingerprint  string                 `json:"fingerprint,omitempty"`
+	Verification verificationV2         `json:"verification"`
+	Data         datastore.Serializable `json:"data"`
+}
+
+// hashV2 contains the canonical url-safe base64 encoding of a hash and its human readable form
+type hashV2 struct {
+	Base64       string `json:"base64"`
+	BubbleBabble string `json:"bubblebabble"`
+}
+
+type verificationV2 struct {
+	Signed           bool `json:"signed"`
+	SignatureValid   bool `json:"signature_valid"`
+	Weight           int  `json:"weight"`
+	CumulativeWeight int  `json:"cumulative_weight"`
+	Depth            int  `json:"depth"`
+	Tip              bool `json:"tip"`
+}
+
+// signed is implemented by all payloads carrying a PGP signature
+type signed interface {
+	Verify() (*openpgp.Entity, error)
+	Fingerprint() string
+}
+
+// v2 converts an object into the version 2 representation
+func (a *API) v2(o *tangle.Object) siteV2 {
+	h := o.Site.Hash()
+	vals := []string{}
+	for _, v := range o.Site.Validates {
+		vals = append(vals, v.Hash().String())
+	}
+	s := siteV2{
+		Version:   SchemaVersion,
+		Hash:      hashV2{Base64: h.String(), BubbleBabble: util.EncodeBubbleBabble(h)},
+		Type:      o.Site.Type,
+		Nonce:     o.Site.Nonce,
+		Content:   o.Site.Content.String(),
+		Validates: vals,
+		Verification: verificationV2{
+			Weight:           a.node.Tangle.Work(o.Site),
+			CumulativeWeight: a.node.Tangle.Weight(o.Site),
+			Depth:            a.node.Tangle.Depth(o.Site),
+			Tip:              a.node.Tangle.HasTip(h),
+		},
+		Data: o.Data,
+	}
+	if p, ok := o.Data.(signed); ok {
+		_, err := p.Verify()
+		s.Fingerprint = p.Fingerprint()
+		s.Verification.Signed = true
+		s.Verification.SignatureValid = err == nil
+	}
+	return s
+}
+
+func (a *API) getSiteV2(c echo.Context) error {
+	h, err := DecodeHash(c.Param("hash"))
+	if err != nil {
+		return fail(c, http.StatusBadRequest, "invalid_base64")
+	}
+	s := a.node.Tangle.Get(h)
+	if s == nil || a.hidden(h) {
+		return a.notFound(c, h)
+	}
+	if err := s.Data.JSON(); err != nil {
+		return fail(c, http.StatusInternalServerError, "response_error")
+	}
+	return c.JSON(http.StatusOK, a.v2(s))
+}
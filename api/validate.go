@@ -0,0 +1,142 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/follow"
+	"github.com/u-speak/core/img"
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/subscription"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/datastore"
+	"github.com/u-speak/core/tangle/site"
+	"github.com/u-speak/core/tombstone"
+	"github.com/u-speak/core/validate"
+)
+
+type jsonCheck struct {
+	Check  string `json:"check"`
+	Passed bool   `json:"passed"`
+	Error  string `json:"error,omitempty"`
+}
+
+type jsonValidation struct {
+	Valid  bool        `json:"valid"`
+	Checks []jsonCheck `json:"checks"`
+}
+
+// newPayload returns an empty payload of the site type, or nil if sites of the type can not be submitted
+func newPayload(typ string) datastore.Serializable {
+	switch typ {
+	case "post":
+		return &post.Post{}
+	case "image":
+		return &img.Image{}
+	case "subscription":
+		return &subscription.Subscription{}
+	case "follow":
+		return &follow.List{}
+	case "tombstone":
+		return &tombstone.Tombstone{}
+	}
+	return nil
+}
+
+// postValidate runs the checks of addSite on a site without submitting it and reports the result of each.
+// Checks depending on a failed one are not run. Challenges are neither checked nor used up
+func (a *API) postValidate(c echo.Context) error {
+	r := jsonValidation{Checks: []jsonCheck{}}
+	check := func(name string, err error) bool {
+		ch := jsonCheck{Check: name, Passed: err == nil}
+		if err != nil {
+			ch.Error = err.Error()
+		}
+		r.Checks = append(r.Checks, ch)
+		return err == nil
+	}
+	failed := func(name, key string, args ...interface{}) {
+		_, m := message(c, key, args...)
+		r.Checks = append(r.Checks, jsonCheck{Check: name, Error: m})
+	}
+	respond := func() error {
+		r.Valid = true
+		for _, ch := range r.Checks {
+			r.Valid = r.Valid && ch.Passed
+		}
+		return c.JSON(http.StatusOK, r)
+	}
+
+	body, err := ioutil.ReadAll(c.Request().Body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Error{Message: err.Error(), Code: http.StatusBadRequest})
+	}
+	s := new(jsonSite)
+	if !check("decode", json.Unmarshal(body, &struct {
+		Type *string `json:"type"`
+	}{&s.Type})) {
+		return respond()
+	}
+	if s.Data = newPayload(s.Type); s.Data == nil {
+		failed("type", "invalid_type", s.Type)
+		return respond()
+	}
+	if !check("decode", json.Unmarshal(body, s)) {
+		return respond()
+	}
+	if !check("fields", validate.Site(s.Nonce, s.Type, s.Data)) || !check("payload", s.Data.ReInit()) {
+		return respond()
+	}
+	if s.Type != "image" {
+		check("signature", verifyGPG(s.Data))
+	}
+	if ts, ok := s.Data.(*tombstone.Tombstone); ok {
+		check("authorization", a.authorize(ts))
+	}
+	sh, err := DecodeHash(s.Hash)
+	if err != nil {
+		failed("hash", "undecodable_hash")
+		return respond()
+	}
+	o := &tangle.Object{Data: s.Data}
+	ch, err := DecodeHash(s.Content)
+	if err != nil {
+		failed("content", "invalid_content_hash")
+		return respond()
+	}
+	if dh, err := o.Data.Hash(); err != nil || ch != dh {
+		failed("content", "content_mismatch")
+		return respond()
+	}
+	check("content", nil)
+	o.Site = &site.Site{Nonce: s.Nonce, Content: ch, Type: s.Type, Timestamp: s.Timestamp, Validates: []*site.Site{}}
+	for _, b64 := range s.Validates {
+		h, err := DecodeHash(b64)
+		if err != nil {
+			failed("validations", "invalid_validation", b64)
+			return respond()
+		}
+		v := a.node.Tangle.GetSite(h)
+		if v == nil {
+			failed("validations", "unknown_validation", b64)
+			return respond()
+		}
+		o.Site.Validates = append(o.Site.Validates, v)
+	}
+	check("validations", nil)
+	if o.Site.Hash() != sh {
+		failed("hash", "hash_mismatch")
+		return respond()
+	}
+	check("hash", nil)
+	if w, r := a.node.Tangle.Work(o.Site), a.node.Tangle.Required(s.Type).Weight; w < r {
+		check("weight", &tangle.WeightError{Type: s.Type, Weight: w, Required: r})
+		return respond()
+	}
+	check("weight", nil)
+	check("tangle", a.node.Tangle.Validate(o))
+	check("storage", a.node.Watchdog.Writable())
+	return respond()
+}
@@ -0,0 +1,13 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/build"
+)
+
+// getVersion returns the version and build of this node
+func (a *API) getVersion(c echo.Context) error {
+	return c.JSON(http.StatusOK, build.Get(a.node.Version))
+}
@@ -0,0 +1,20 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/warc"
+)
+
+func (a *API) getExportWARC(c echo.Context) error {
+	compress := c.QueryParam("gzip") == "true"
+	name := "tangle.warc"
+	if compress {
+		name += ".gz"
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename="+name)
+	c.Response().Header().Set(echo.HeaderContentType, "application/warc")
+	c.Response().WriteHeader(http.StatusOK)
+	return warc.Export(a.node.Tangle, a.node.APIAddr, "u-speak core "+a.node.Version, warc.NewWriter(c.Response(), compress))
+}
@@ -0,0 +1,46 @@
+// Package build holds the metadata compiled into the binary.
+// Commit and Date are set through the linker:
+//
+//	go build -ldflags "-X github.com/u-speak/core/build.Commit=$(git rev-parse HEAD) -X github.com/u-speak/core/build.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package build
+
+import "runtime"
+
+// Protocol is the version of the node to node protocol. It is raised whenever nodes of different versions can no longer talk to each other
+const Protocol = 1
+
+// Unknown is reported for metadata which was not set at build time
+const Unknown = "unknown"
+
+var (
+	// Commit is the git commit the binary was built from
+	Commit = Unknown
+	// Date is the time the binary was built at
+	Date = Unknown
+)
+
+// Info describes the build of a node
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	GoVersion string `json:"go_version"`
+	Protocol  int    `json:"protocol"`
+}
+
+// Get returns the build info of the running binary with the given release version
+func Get(version string) Info {
+	return Info{
+		Version:   version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+		Protocol:  Protocol,
+	}
+}
+
+// Compatible reports whether a node speaking the protocol version can talk to this one.
+// Nodes built before the protocol was versioned report 0 and are assumed to be compatible
+func Compatible(protocol int) bool {
+	return protocol == 0 || protocol == Protocol
+}
@@ -0,0 +1,22 @@
+package build
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGet(t *testing.T) {
+	i := Get("1.2.3")
+	assert.Equal(t, "1.2.3", i.Version)
+	assert.Equal(t, Unknown, i.Commit)
+	assert.Equal(t, runtime.Version(), i.GoVersion)
+	assert.Equal(t, Protocol, i.Protocol)
+}
+
+func TestCompatible(t *testing.T) {
+	assert.True(t, Compatible(0))
+	assert.True(t, Compatible(Protocol))
+	assert.False(t, Compatible(Protocol+1))
+}
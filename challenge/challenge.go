@@ -0,0 +1,181 @@
+package challenge
+
+import (
+	"container/list"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math/bits"
+	"sync"
+	"time"
+
+	"github.com/u-speak/core/tangle/hash"
+)
+
+var (
+	// ErrUnknownChallenge is returned for challenges that were never issued, already used or expired
+	ErrUnknownChallenge = errors.New("Unknown or expired challenge")
+	// ErrInvalidSolution is returned when the solution does not meet the difficulty of the challenge
+	ErrInvalidSolution = errors.New("Challenge solution does not meet the difficulty")
+)
+
+// MaxChallenges is the amount of outstanding challenges an issuer keeps. Once reached, the oldest challenge is dropped
+const MaxChallenges = 1 << 16
+
+// Challenge has to be solved for a site before it is accepted.
+// A solution is a number for which Hash has at least Difficulty leading zero bits
+type Challenge struct {
+	ID         string    `json:"id"`
+	Salt       []byte    `json:"salt"`
+	Difficulty int       `json:"difficulty"`
+	Expires    time.Time `json:"expires"`
+}
+
+// Hash computes the digest a solution for the site is checked against
+func (c *Challenge) Hash(site hash.Hash, solution uint64) hash.Hash {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, solution)
+	return hash.New(append(append(append([]byte{}, c.Salt...), site[:]...), b...))
+}
+
+// Solved checks whether the solution meets the difficulty
+func (c *Challenge) Solved(site hash.Hash, solution uint64) bool {
+	return LeadingZeroBits(c.Hash(site, solution)) >= c.Difficulty
+}
+
+// Solve searches the first solution for the site
+func (c *Challenge) Solve(site hash.Hash) uint64 {
+	var s uint64
+	for !c.Solved(site, s) {
+		s++
+	}
+	return s
+}
+
+// LeadingZeroBits counts the leading zero bits of a hash
+func LeadingZeroBits(h hash.Hash) int {
+	n := 0
+	for _, b := range h {
+		if b != 0 {
+			return n + bits.LeadingZeros8(b)
+		}
+		n += 8
+	}
+	return n
+}
+
+// Issuer hands out challenges and raises their difficulty when many sites are submitted
+type Issuer struct {
+	base       int
+	max        int
+	ttl        time.Duration
+	threshold  int
+	capacity   int
+	challenges map[string]*list.Element
+	// order holds the outstanding challenges by their expiry, all challenges live for the same ttl
+	order  *list.List
+	solved []time.Time
+	lock   sync.Mutex
+}
+
+// New creates an issuer starting at the base difficulty. For every threshold solutions within a minute the difficulty is raised by one bit, up to max
+func New(base, max int, ttl time.Duration, threshold int) *Issuer {
+	return &Issuer{
+		base:       base,
+		max:        max,
+		ttl:        ttl,
+		threshold:  threshold,
+		capacity:   MaxChallenges,
+		challenges: make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// Issue creates a new challenge
+func (i *Issuer) Issue() (*Challenge, error) {
+	id := make([]byte, 16)
+	salt := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	now := time.Now()
+	i.expire(now)
+	c := &Challenge{
+		ID:         hex.EncodeToString(id),
+		Salt:       salt,
+		Difficulty: i.difficulty(now),
+		Expires:    now.Add(i.ttl),
+	}
+	for i.order.Len() >= i.capacity {
+		i.remove(i.order.Front())
+	}
+	i.challenges[c.ID] = i.order.PushBack(c)
+	return c, nil
+}
+
+// Verify checks the solution for the site and consumes the challenge
+func (i *Issuer) Verify(id string, site hash.Hash, solution uint64) error {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	now := time.Now()
+	i.expire(now)
+	e, ok := i.challenges[id]
+	if !ok {
+		return ErrUnknownChallenge
+	}
+	if !e.Value.(*Challenge).Solved(site, solution) {
+		return ErrInvalidSolution
+	}
+	i.remove(e)
+	i.solved = append(i.solved, now)
+	return nil
+}
+
+// Difficulty returns the difficulty of newly issued challenges
+func (i *Issuer) Difficulty() int {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	return i.difficulty(time.Now())
+}
+
+func (i *Issuer) difficulty(now time.Time) int {
+	recent := i.solved[:0]
+	for _, t := range i.solved {
+		if now.Sub(t) < time.Minute {
+			recent = append(recent, t)
+		}
+	}
+	i.solved = recent
+	d := i.base
+	if i.threshold > 0 {
+		d += len(recent) / i.threshold
+	}
+	if d > i.max {
+		d = i.max
+	}
+	return d
+}
+
+// Outstanding returns the amount of issued challenges that were neither used nor dropped yet
+func (i *Issuer) Outstanding() int {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	return i.order.Len()
+}
+
+func (i *Issuer) expire(now time.Time) {
+	for e := i.order.Front(); e != nil && now.After(e.Value.(*Challenge).Expires); e = i.order.Front() {
+		i.remove(e)
+	}
+}
+
+func (i *Issuer) remove(e *list.Element) {
+	i.order.Remove(e)
+	delete(i.challenges, e.Value.(*Challenge).ID)
+}
@@ -0,0 +1,68 @@
+package challenge
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+func TestLeadingZeroBits(t *testing.T) {
+	assert.Equal(t, 0, LeadingZeroBits(hash.Hash{0x80}))
+	assert.Equal(t, 7, LeadingZeroBits(hash.Hash{0x01}))
+	assert.Equal(t, 12, LeadingZeroBits(hash.Hash{0, 0x08}))
+	assert.Equal(t, 256, LeadingZeroBits(hash.Hash{}))
+}
+
+func TestVerify(t *testing.T) {
+	i := New(4, 8, time.Minute, 0)
+	c, err := i.Issue()
+	assert.NoError(t, err)
+	assert.Equal(t, 4, c.Difficulty)
+	site := hash.New([]byte("site"))
+	s := c.Solve(site)
+	if s > 0 {
+		assert.Equal(t, ErrInvalidSolution, i.Verify(c.ID, site, s-1))
+	}
+	assert.NoError(t, i.Verify(c.ID, site, s))
+	assert.Equal(t, ErrUnknownChallenge, i.Verify(c.ID, site, s))
+	assert.Equal(t, ErrUnknownChallenge, i.Verify("unknown", site, s))
+}
+
+func TestExpired(t *testing.T) {
+	i := New(1, 1, -time.Second, 0)
+	c, _ := i.Issue()
+	assert.Equal(t, ErrUnknownChallenge, i.Verify(c.ID, hash.Hash{}, c.Solve(hash.Hash{})))
+}
+
+func TestDifficulty(t *testing.T) {
+	i := New(1, 3, time.Minute, 2)
+	site := hash.Hash{}
+	for n := 0; n < 6; n++ {
+		c, _ := i.Issue()
+		assert.NoError(t, i.Verify(c.ID, site, c.Solve(site)))
+	}
+	assert.Equal(t, 3, i.Difficulty())
+}
+
+func TestBounded(t *testing.T) {
+	i := New(1, 1, time.Minute, 0)
+	i.capacity = 3
+	cs := []*Challenge{}
+	for n := 0; n < 5; n++ {
+		c, _ := i.Issue()
+		cs = append(cs, c)
+	}
+	assert.Equal(t, 3, i.Outstanding())
+	site := hash.Hash{}
+	assert.Equal(t, ErrUnknownChallenge, i.Verify(cs[0].ID, site, cs[0].Solve(site)))
+	assert.NoError(t, i.Verify(cs[4].ID, site, cs[4].Solve(site)))
+	assert.Equal(t, 2, i.Outstanding())
+
+	i = New(1, 1, -time.Second, 0)
+	for n := 0; n < 5; n++ {
+		i.Issue()
+	}
+	assert.Equal(t, 1, i.Outstanding())
+}
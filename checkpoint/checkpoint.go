@@ -0,0 +1,93 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/u-speak/core/reconcile"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/util"
+)
+
+// Version of the checkpoint format. Checkpoints of other versions are ignored
+const Version = 1
+
+var (
+	// ErrVersion is returned when the checkpoint was written in another format
+	ErrVersion = errors.New("Checkpoint has an unsupported version")
+	// ErrStale is returned when the tangle changed after the checkpoint was written
+	ErrStale = errors.New("Checkpoint does not match the tangle")
+)
+
+// Checkpoint is the state of the in-memory indexes written on shutdown
+type Checkpoint struct {
+	Version int         `json:"version"`
+	Created time.Time   `json:"created"`
+	Size    int         `json:"size"`
+	Root    hash.Hash   `json:"root"`
+	Tips    []hash.Hash `json:"tips"`
+	Follows []hash.Hash `json:"follows"`
+	Tail    []hash.Hash `json:"tail"`
+}
+
+// New creates a checkpoint of the tangle. The index state has to be filled in by the caller
+func New(t *tangle.Tangle) *Checkpoint {
+	c := &Checkpoint{
+		Version: Version,
+		Created: time.Now(),
+		Size:    t.Size(),
+		Root:    reconcile.Summarize(t.Hashes()).Root,
+		Tips:    []hash.Hash{},
+	}
+	for _, s := range t.Tips() {
+		c.Tips = append(c.Tips, s.Hash())
+	}
+	return c
+}
+
+// Save writes the checkpoint atomically to path
+func (c *Checkpoint) Save(path string) error {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return util.WriteFile(path, b, 0644)
+}
+
+// Load reads the checkpoint at path and removes it, so it is never applied twice
+func Load(path string) (*Checkpoint, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	os.Remove(path)
+	c := &Checkpoint{}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, err
+	}
+	if c.Version != Version {
+		return nil, ErrVersion
+	}
+	return c, nil
+}
+
+// Validate checks that the tangle is still in the state the checkpoint was written for
+func (c *Checkpoint) Validate(t *tangle.Tangle) error {
+	if t.Size() != c.Size || reconcile.Summarize(t.Hashes()).Root != c.Root {
+		return ErrStale
+	}
+	tips := t.Tips()
+	if len(tips) != len(c.Tips) {
+		return ErrStale
+	}
+	for _, h := range c.Tips {
+		if !t.HasTip(h) {
+			return ErrStale
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package checkpoint
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/img"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/site"
+	"github.com/u-speak/core/tangle/store"
+	"github.com/u-speak/core/tangle/store/memorystore"
+)
+
+func TestCheckpoint(t *testing.T) {
+	ms := &memorystore.MemoryStore{}
+	_ = ms.Init(store.Options{})
+	dp := path.Join(os.TempDir(), "testCheckpointData.db")
+	defer os.Remove(dp)
+	tngl, err := tangle.New(tangle.Options{Store: ms, DataPath: dp})
+	assert.NoError(t, err)
+	defer tngl.Close()
+
+	cp := path.Join(os.TempDir(), "testCheckpoint.json")
+	c := New(tngl)
+	assert.NoError(t, c.Save(cp))
+	l, err := Load(cp)
+	assert.NoError(t, err)
+	assert.Equal(t, c.Root, l.Root)
+	assert.NoError(t, l.Validate(tngl))
+	_, err = Load(cp)
+	assert.True(t, os.IsNotExist(err))
+
+	i := &img.Image{Raw: []byte("1337")}
+	h, _ := i.Hash()
+	o := &tangle.Object{Site: &site.Site{Content: h, Type: "image", Validates: tngl.Tips()}, Data: i}
+	o.Site.Mine(1)
+	assert.NoError(t, tngl.Add(o))
+	assert.Equal(t, ErrStale, l.Validate(tngl))
+
+	c.Version = 0
+	assert.NoError(t, c.Save(cp))
+	_, err = Load(cp)
+	assert.Equal(t, ErrVersion, err)
+}
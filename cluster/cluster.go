@@ -0,0 +1,136 @@
+package cluster
+
+import (
+	"errors"
+	"sync"
+)
+
+const (
+	// Standalone nodes accept and store writes on their own
+	Standalone = "standalone"
+	// Writer nodes are the single point in a cluster where writes are applied
+	Writer = "writer"
+	// Frontend nodes serve reads and forward all writes to the writer
+	Frontend = "frontend"
+	// Replica nodes follow the site stream of the writer, serve reads and forward all writes to the writer
+	Replica = "replica"
+)
+
+var (
+	// ErrInvalidRole is returned when the configured role is unknown
+	ErrInvalidRole = errors.New("Invalid cluster role")
+	// ErrNoWriter is returned when a frontend is configured without a writer
+	ErrNoWriter = errors.New("Frontend and replica nodes need a writer address")
+	// ErrReadOnly is returned when a replica is asked to store a site directly
+	ErrReadOnly = errors.New("Replicas only accept sites from their writer")
+	// ErrClosed is returned when a write is queued after the cluster was closed
+	ErrClosed = errors.New("Write queue is closed")
+)
+
+// Cluster coordinates writes between nodes sharing the same tangle
+type Cluster struct {
+	Role   string
+	Writer string
+	jobs   chan job
+	// done is closed by Close, stopped once the queue applied the remaining writes
+	done    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+}
+
+type job struct {
+	fn  func() error
+	err chan error
+}
+
+// New creates the cluster configuration for a node and starts its write queue
+func New(role, writer string, size int) (*Cluster, error) {
+	switch role {
+	case "":
+		role = Standalone
+	case Standalone, Writer:
+	case Frontend, Replica:
+		if writer == "" {
+			return nil, ErrNoWriter
+		}
+	default:
+		return nil, ErrInvalidRole
+	}
+	c := &Cluster{
+		Role:    role,
+		Writer:  writer,
+		jobs:    make(chan job, size),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go c.run()
+	return c, nil
+}
+
+// Forwarding reports whether writes have to be forwarded to the writer
+func (c *Cluster) Forwarding() bool {
+	return c.Role == Frontend || c.Role == Replica
+}
+
+// ReadOnly reports whether the local tangle may only be changed by the replication stream
+func (c *Cluster) ReadOnly() bool {
+	return c.Role == Replica
+}
+
+// Do queues fn and waits for it to be applied. Queued functions are run one at a time in the order they were queued.
+// While the queue is full Do waits for a free place, it returns ErrClosed if the cluster is closed before fn was applied
+func (c *Cluster) Do(fn func() error) error {
+	select {
+	case <-c.done:
+		return ErrClosed
+	default:
+	}
+	j := job{fn: fn, err: make(chan error, 1)}
+	select {
+	case c.jobs <- j:
+	case <-c.done:
+		return ErrClosed
+	}
+	select {
+	case err := <-j.err:
+		return err
+	case <-c.stopped:
+	}
+	// The job may have been applied right before the queue stopped
+	select {
+	case err := <-j.err:
+		return err
+	default:
+		return ErrClosed
+	}
+}
+
+// Pending returns the number of writes waiting in the queue
+func (c *Cluster) Pending() int {
+	return len(c.jobs)
+}
+
+// Close stops the write queue after the pending writes have been applied
+func (c *Cluster) Close() {
+	c.once.Do(func() { close(c.done) })
+	<-c.stopped
+}
+
+func (c *Cluster) run() {
+	defer close(c.stopped)
+	for {
+		select {
+		case j := <-c.jobs:
+			j.err <- j.fn()
+		case <-c.done:
+			for {
+				select {
+				case j := <-c.jobs:
+					j.err <- j.fn()
+				default:
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package cluster
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	c, err := New("", "", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, Standalone, c.Role)
+	assert.False(t, c.Forwarding())
+	_, err = New(Frontend, "", 1)
+	assert.Equal(t, ErrNoWriter, err)
+	_, err = New("leader", "", 1)
+	assert.Equal(t, ErrInvalidRole, err)
+	c, err = New(Frontend, "127.0.0.1:6969", 1)
+	assert.NoError(t, err)
+	assert.True(t, c.Forwarding())
+	assert.False(t, c.ReadOnly())
+	c, err = New(Replica, "127.0.0.1:6969", 1)
+	assert.NoError(t, err)
+	assert.True(t, c.Forwarding())
+	assert.True(t, c.ReadOnly())
+}
+
+func TestDo(t *testing.T) {
+	c, err := New(Writer, "", 4)
+	assert.NoError(t, err)
+	e := errors.New("failed")
+	assert.Equal(t, e, c.Do(func() error { return e }))
+	running := 0
+	max := 0
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Do(func() error {
+				lock.Lock()
+				running++
+				if running > max {
+					max = running
+				}
+				lock.Unlock()
+				// Writes running at the same time would overlap while this one sleeps
+				time.Sleep(time.Millisecond)
+				lock.Lock()
+				running--
+				lock.Unlock()
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, max)
+	c.Close()
+	assert.Equal(t, ErrClosed, c.Do(func() error { return nil }))
+}
+
+func TestCloseFullQueue(t *testing.T) {
+	c, err := New(Writer, "", 1)
+	assert.NoError(t, err)
+	block, started := make(chan struct{}), make(chan struct{}, 3)
+	errs := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		go func() { errs <- c.Do(func() error { started <- struct{}{}; <-block; return nil }) }()
+	}
+	// One write runs, one waits in the queue and one waits for a place in the queue
+	<-started
+	assert.Eventually(t, func() bool { return c.Pending() == 1 }, time.Second, time.Millisecond)
+	closed := make(chan struct{})
+	go func() {
+		c.Close()
+		close(closed)
+	}()
+	assert.Equal(t, ErrClosed, <-errs)
+	close(block)
+	<-closed
+	assert.NoError(t, <-errs)
+	assert.NoError(t, <-errs)
+}
@@ -0,0 +1,46 @@
+package cluster
+
+import (
+	"sync"
+	"time"
+)
+
+// Lag describes how far a replica is behind its writer
+type Lag struct {
+	Connected  bool      `json:"connected"`
+	Writer     uint64    `json:"writer"`
+	Local      uint64    `json:"local"`
+	Behind     int64     `json:"behind"`
+	LastUpdate time.Time `json:"last_update"`
+}
+
+// Tracker keeps the replication lag of a replica up to date
+type Tracker struct {
+	lag  Lag
+	lock sync.RWMutex
+}
+
+// Update records the sizes of the writer and the local tangle after an update was received
+func (t *Tracker) Update(writer, local uint64) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.lag.Connected = true
+	t.lag.Writer = writer
+	t.lag.Local = local
+	t.lag.Behind = int64(writer) - int64(local)
+	t.lag.LastUpdate = time.Now()
+}
+
+// Disconnected marks the replication stream as interrupted
+func (t *Tracker) Disconnected() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.lag.Connected = false
+}
+
+// Lag returns the last known replication lag
+func (t *Tracker) Lag() Lag {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return t.lag
+}
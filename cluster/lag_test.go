@@ -0,0 +1,20 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracker(t *testing.T) {
+	tr := &Tracker{}
+	assert.False(t, tr.Lag().Connected)
+	tr.Update(10, 7)
+	l := tr.Lag()
+	assert.True(t, l.Connected)
+	assert.Equal(t, int64(3), l.Behind)
+	assert.False(t, l.LastUpdate.IsZero())
+	tr.Disconnected()
+	assert.False(t, tr.Lag().Connected)
+	assert.Equal(t, int64(3), tr.Lag().Behind)
+}
@@ -1,6 +1,7 @@
 package config
 
-// Configuration is the exportable type of the node configuration
+// Configuration is the exportable type of the node configuration.
+// Fields tagged as secret are redacted by Describe
 type Configuration struct {
 	Version string
 	Logger  struct {
@@ -14,19 +15,273 @@ type Configuration struct {
 		DNS     string `default:"discovery.uspeak.io"`
 	}
 	Storage struct {
-		DataPath   string `default:"/var/lib/uspeak/data.db" env:"DATA_PATH"`
-		TanglePath string `default:"/var/lib/uspeak/tangle.db" env:"TANGLE_PATH"`
+		DataPath       string `default:"/var/lib/uspeak/data.db" env:"DATA_PATH"`
+		TanglePath     string `default:"/var/lib/uspeak/tangle.db" env:"TANGLE_PATH"`
+		DigestPath     string `default:"/var/lib/uspeak/digest.db" env:"DIGEST_PATH"`
+		SQLIndexPath   string `default:"/var/lib/uspeak/index.sqlite" env:"SQL_INDEX_PATH"`
+		AnchorPath     string `default:"/var/lib/uspeak/anchor.db" env:"ANCHOR_PATH"`
+		TSAPath        string `default:"/var/lib/uspeak/tsa.db" env:"TSA_PATH"`
+		CheckpointPath string `default:"/var/lib/uspeak/checkpoint.json" env:"CHECKPOINT_PATH"`
+		IdentityPath   string `default:"/var/lib/uspeak/identity.key" env:"IDENTITY_PATH"`
+		RecentPath     string `default:"/var/lib/uspeak/recent.bin" env:"RECENT_PATH"`
+		PinPath        string `default:"/var/lib/uspeak/pins.db" env:"PIN_PATH"`
+		CustodyPath    string `default:"/var/lib/uspeak/custody.db" env:"CUSTODY_PATH"`
+		FlagPath       string `default:"/var/lib/uspeak/flags.db" env:"FLAG_PATH"`
+		ReceiptPath    string `default:"/var/lib/uspeak/receipts.db" env:"RECEIPT_PATH"`
+		UploadPath     string `default:"/var/lib/uspeak/uploads" env:"UPLOAD_PATH"`
+		MediaPath      string `default:"/var/lib/uspeak/media.db" env:"MEDIA_PATH"`
+		HidePath       string `default:"/var/lib/uspeak/hidden.db" env:"HIDE_PATH"`
+		MinFree        uint64 `default:"100" env:"STORAGE_MIN_FREE"`
+		Compression    string `default:"none" env:"DATA_COMPRESSION"`
+		EncryptionKey  string `env:"STORAGE_KEY" secret:"true"`
+		KeyCommand     string `env:"STORAGE_KEY_COMMAND"`
+		// SlowLog is the duration in milliseconds after which store operations are logged
+		SlowLog int `default:"100" env:"STORAGE_SLOW_LOG"`
+		// JournalPath is the write-ahead journal of additions to the tangle, replayed after a crash. Empty disables the journal.
+		// Payloads are kept unencrypted in the journal until they are written to the data store
+		JournalPath string `default:"/var/lib/uspeak/journal.wal" env:"JOURNAL_PATH"`
+		// Durability is either "full", syncing every commit to disk, or "relaxed", leaving it to the operating system
+		Durability string `default:"full" env:"STORAGE_DURABILITY"`
+	}
+	// Identity selects how the private key of the node is protected, see identity.Options
+	Identity struct {
+		AgeIdentity   string `env:"IDENTITY_AGE_IDENTITY"`
+		AgePassphrase string `env:"IDENTITY_AGE_PASSPHRASE" secret:"true"`
+		Token         struct {
+			Module string
+			Label  string
+			PIN    string `env:"IDENTITY_TOKEN_PIN" secret:"true"`
+			Key    string `default:"uspeak"`
+		}
 	}
 	NodeNetwork struct {
-		Port      int    `default:"6969" env:"NODE_PORT"`
-		Interface string `default:"127.0.0.1" env:"NODE_INTERFACE"`
+		Port        int    `default:"6969" env:"NODE_PORT"`
+		Interface   string `default:"127.0.0.1" env:"NODE_INTERFACE"`
+		Bootstrap   []Peer
+		DoH         string `env:"NODE_DOH"`
+		MaxInbound  int    `default:"32" env:"NODE_MAX_INBOUND"`
+		MaxOutbound int    `default:"16" env:"NODE_MAX_OUTBOUND"`
+		// PingInterval and PingTimeout are given in seconds
+		PingInterval int `default:"5" env:"NODE_PING_INTERVAL"`
+		PingTimeout  int `default:"2" env:"NODE_PING_TIMEOUT"`
+		ReplayWindow int `default:"4096" env:"NODE_REPLAY_WINDOW"`
+		MaxPerSubnet int `default:"4" env:"NODE_MAX_PER_SUBNET"`
+		// Checkpoints are trusted site hashes. Peers can not add sites which only validate the history below them
+		Checkpoints []string
+		// Rotation is the interval in seconds at which an outbound peer is replaced, 0 disables rotation
+		Rotation int `default:"1800" env:"NODE_ROTATION"`
+		// Gossip is the interval in seconds at which statistics are exchanged with a random peer, 0 disables gossip
+		Gossip int `default:"60" env:"NODE_GOSSIP"`
+		// Genesis is the path of the genesis file of the network, the builtin network is joined if empty
+		Genesis string `env:"NODE_GENESIS"`
+		// Allow and Deny are IPs, CIDR networks or peer IDs. If Allow is not empty only matching peers can connect
+		Allow []string
+		Deny  []string
+		// Orphans is the amount of sites kept while their parents are fetched, OrphanTTL how many seconds they are kept
+		Orphans   int `default:"1024" env:"NODE_ORPHANS"`
+		OrphanTTL int `default:"600" env:"NODE_ORPHAN_TTL"`
+		// SolidifyRate is the amount of missing sites and payloads fetched per second after a restart, 0 disables solidification.
+		// SolidifyInterval is the amount of seconds between walks
+		SolidifyRate     int `default:"10" env:"NODE_SOLIDIFY_RATE"`
+		SolidifyInterval int `default:"3600" env:"NODE_SOLIDIFY_INTERVAL"`
+		// RelayTTL is the amount of seconds the node remembers which peers have a site
+		RelayTTL int `default:"600" env:"NODE_RELAY_TTL"`
+		// Reverse decides whether the node connects back to peers calling it: off, verify (the caller has to prove its address) or on.
+		// MaxReverse caps the amount of reverse connections
+		Reverse    string `default:"verify" env:"NODE_REVERSE"`
+		MaxReverse int    `default:"16" env:"NODE_MAX_REVERSE"`
+		// MaxTipAge is the maximum age in minutes of the tips new sites validate, 0 accepts sites without timestamp.
+		// MaxClockSkew is the amount of seconds timestamps may lie in the future
+		MaxTipAge    int `default:"0" env:"NODE_MAX_TIP_AGE"`
+		MaxClockSkew int `default:"120" env:"NODE_MAX_CLOCK_SKEW"`
+		// MinWeight is the weight, in leading zero bits of the proof of work, every new site needs.
+		// Requirements can raise or lower it by site type
+		MinWeight int `default:"1" env:"NODE_MIN_WEIGHT"`
+		// TLSCert and TLSKey are the PEM files the node serves TLS with. Peers connecting to it have to pin the
+		// fingerprint of the certificate in their bootstrap configuration, without them the node serves plain connections
+		TLSCert string `env:"NODE_TLS_CERT"`
+		TLSKey  string `env:"NODE_TLS_KEY"`
 	}
 	Diagnostics struct {
 		Port      int    `default:"1337" env:"DIAG_PORT"`
 		Interface string `default:"127.0.0.1" env:"DIAG_INTERFACE"`
 	}
+	Cluster struct {
+		Role      string `default:"standalone" env:"CLUSTER_ROLE"`
+		Writer    string `env:"CLUSTER_WRITER"`
+		QueueSize int    `default:"64"`
+	}
+	Tail struct {
+		Buffer  int `default:"1024"`
+		Timeout int `default:"30"`
+	}
+	Preview struct {
+		Length int `default:"280"`
+	}
+	Challenge struct {
+		Enabled       bool `default:"false"`
+		Difficulty    int  `default:"16"`
+		MaxDifficulty int  `default:"24"`
+		TTL           int  `default:"300"`
+		LoadThreshold int  `default:"60"`
+	}
+	Submissions struct {
+		Workers   int `default:"4"`
+		Queue     int `default:"256"`
+		Retention int `default:"60"`
+	}
+	// Admission bounds the sites pushed by peers which are validated at once. Up to Queue more sites wait
+	// at most Wait seconds for one of the Workers, further sites are rejected as busy and retried by the peer
+	Admission struct {
+		Workers int `default:"8" env:"ADMISSION_WORKERS"`
+		Queue   int `default:"64" env:"ADMISSION_QUEUE"`
+		Wait    int `default:"5" env:"ADMISSION_WAIT"`
+	}
+	// Uploads configures resumable image uploads. Sessions is the amount of unfinished uploads kept at once,
+	// TTL the amount of minutes an unfinished upload is kept after its last chunk
+	Uploads struct {
+		Sessions int `default:"256" env:"UPLOAD_SESSIONS"`
+		TTL      int `default:"1440" env:"UPLOAD_TTL"`
+	}
+	Quorum struct {
+		Enabled bool `default:"false"`
+		Size    int  `default:"2"`
+		Peers   []string
+		Timeout int `default:"10"`
+	}
 	Hooks struct {
-		PreAdd string
+		PreAdd     string
+		SendBundle bool
+		// Timeout and Cooldown are given in seconds
+		Timeout          int `default:"5"`
+		Queue            int `default:"64"`
+		FailureThreshold int `default:"5"`
+		Cooldown         int `default:"60"`
+	}
+	Alerts struct {
+		Webhook     string `env:"ALERT_WEBHOOK" secret:"true"`
+		PeerTimeout int    `default:"10"`
+		// Timeout is the amount of seconds the webhook may take to accept a notification
+		Timeout int `default:"10"`
+	}
+	SQLIndex struct {
+		Enabled bool `default:"false"`
+		MaxRows int  `default:"1000"`
+		// Timeout is the amount of seconds a query may run
+		Timeout int `default:"10"`
+	}
+	Ranking struct {
+		// Default is the algorithm used when the feed request does not select one: latest, top or hot
+		Default string `default:"hot"`
+		// WeightFactor scales the cumulative weight of a post in the hot algorithm
+		WeightFactor float64 `default:"1"`
+		// Gravity is how fast the hot score of a post decays with its age in hours
+		Gravity float64 `default:"1.8"`
+		// Refresh is how many seconds the cumulative weights of posts are reused before they are computed again
+		Refresh int `default:"60"`
+	}
+	Anchor struct {
+		Enabled  bool   `default:"false"`
+		Backend  string `default:"opentimestamps"`
+		Interval int    `default:"24"`
+		Calendar string `default:"https://a.pool.opentimestamps.org"`
+		// Timeout is the amount of seconds the calendar or the ethereum node may take to publish a root
+		Timeout  int `default:"30"`
+		Ethereum struct {
+			RPC  string `default:"http://127.0.0.1:8545" secret:"true"`
+			From string
+			To   string
+		}
+	}
+	TSA struct {
+		Enabled bool   `default:"false"`
+		URL     string `default:"https://freetsa.org/tsr"`
+		// Timeout is given in seconds, Queue is the amount of sites waiting for their token
+		Timeout int `default:"10"`
+		Queue   int `default:"256"`
+	}
+	Custody struct {
+		Enabled bool `default:"false"`
+		// Interval is the time in seconds a hosted key is locked after being used
+		Interval int `default:"10"`
+		// ClientLimit is the amount of custody requests a client may make per minute, 0 disables the limit
+		ClientLimit int `default:"10"`
+	}
+	// Media generates variants of images. Widths are the thumbnail widths in pixels, media.DefaultWidths if empty,
+	// Quality is the JPEG quality of the variants
+	Media struct {
+		Enabled bool `default:"true" env:"MEDIA_ENABLED"`
+		Widths  []int
+		Quality int `default:"80"`
+		// MaxPixels is the largest image in pixels variants are generated for, larger images are not decoded
+		MaxPixels int `default:"40000000" env:"MEDIA_MAX_PIXELS"`
+	}
+	// Flags selects the classifier of new posts and images: heuristic, webhook or none
+	Flags struct {
+		Classifier string `default:"heuristic"`
+		Webhook    string `env:"FLAGS_WEBHOOK" secret:"true"`
+		Timeout    int    `default:"5"`
+		// Queue is the amount of sites waiting for their classification
+		Queue int `default:"256"`
+	}
+	// Hide configures the sites the operator hides on this node. They are always left out of the API,
+	// with Refuse they are neither pushed nor served to peers either
+	Hide struct {
+		Refuse bool `default:"false" env:"HIDE_REFUSE"`
+	}
+	// Pins limits how many sites every user may pin with signed commands, 0 removes the limit
+	Pins struct {
+		Quota int `default:"100" env:"PINS_QUOTA"`
+	}
+	// Requirements set the weight and validations new sites need by type. Unset values use the defaults of the tangle
+	Requirements map[string]Requirement
+	// Policy is published to clients at /api/v1/node/policy
+	Policy struct {
+		Contact string `env:"POLICY_CONTACT"`
+		Terms   string `env:"POLICY_TERMS"`
+		// Retention is the number of days payloads are kept before they are removed, except for pinned sites. 0 keeps them forever
+		Retention int `default:"0"`
+	}
+	Digest struct {
+		Enabled  bool `default:"false"`
+		Interval int  `default:"24"`
+		Limit    int  `default:"10"`
+		// ConfirmTTL is how many hours the link confirming a subscription is valid.
+		// SubscribeLimit is the amount of subscriptions a client may request per minute, 0 disables the limit
+		ConfirmTTL     int `default:"48"`
+		SubscribeLimit int `default:"5"`
+		SMTP           struct {
+			Host     string `default:"localhost" env:"SMTP_HOST"`
+			Port     int    `default:"25" env:"SMTP_PORT"`
+			User     string
+			Password string `env:"SMTP_PASSWORD" secret:"true"`
+			From     string `default:"digest@uspeak.io"`
+		}
+	}
+	// Decisions is the amount of acceptance attempts kept for GET /admin/decisions, 0 disables the decision log
+	Decisions struct {
+		Size int `default:"1024" env:"DECISION_LOG_SIZE"`
+	}
+	// Schedule configures when the recurring tasks of the node run.
+	// Jitter is the maximum amount of seconds an activation is delayed at random
+	Schedule struct {
+		Jitter int `default:"30" env:"SCHEDULE_JITTER"`
+		Tasks  []Task
+	}
+	// Simulation configures the in-process network run by core.RunSimulation.
+	// Latency and Jitter are given in milliseconds, Duration and Timeout in seconds
+	Simulation struct {
+		Nodes     int     `default:"8"`
+		Degree    int     `default:"3"`
+		Latency   int     `default:"50"`
+		Jitter    int     `default:"20"`
+		Duration  int     `default:"30"`
+		Timeout   int     `default:"30"`
+		PostRate  float64 `default:"5"`
+		ImageRate float64 `default:"1"`
+		PostSize  int     `default:"280"`
+		ImageSize int     `default:"65536"`
+		Keys      int     `default:"4"`
 	}
 	Web struct {
 		Static struct {
@@ -45,7 +300,32 @@ type Configuration struct {
 			PublicEndpoint string
 			AdminEnabled   bool   `default:"false"`
 			AdminUser      string `default:"admin"`
-			AdminPassword  string `default:"admin"`
+			AdminPassword  string `default:"admin" secret:"true"`
+			// ValidateLimit is the amount of validations a client may request per minute, 0 disables the limit
+			ValidateLimit int `default:"30" env:"API_VALIDATE_LIMIT"`
+			// KeyLimit is the amount of keys a client may look up per minute, 0 disables the limit
+			KeyLimit int `default:"30" env:"API_KEY_LIMIT"`
 		}
 	}
 }
+
+// Requirement is the proof of work demanded from sites of a type
+type Requirement struct {
+	Weight      int
+	Validations int
+}
+
+// Peer is a node connected to on startup
+type Peer struct {
+	Address      string
+	Priority     int
+	Fingerprint  string
+	MaxBandwidth int
+}
+
+// Task overrides the cron expression of a builtin task like "*/5 * * * *", "@daily" or "@every 90s".
+// The expression "off" disables the task
+type Task struct {
+	Name string
+	Cron string
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+)
+
+// Sources of a configuration value
+const (
+	SourceDefault = "default"
+	SourceEnv     = "env"
+	SourceFile    = "file"
+)
+
+// Redacted replaces the value of secrets which are set
+const Redacted = "<redacted>"
+
+// Setting is a single effective configuration value and where it came from.
+// Values matching the default are reported as defaults even if the configuration file repeats them
+type Setting struct {
+	Key     string      `json:"key"`
+	Value   interface{} `json:"value"`
+	Source  string      `json:"source"`
+	Default string      `json:"default,omitempty"`
+	Env     string      `json:"env,omitempty"`
+}
+
+// Describe lists all values of the configuration by their dotted key
+func Describe(c Configuration) []Setting {
+	return describe("", reflect.ValueOf(c), reflect.StructField{})
+}
+
+func describe(key string, v reflect.Value, f reflect.StructField) []Setting {
+	if v.Kind() == reflect.Struct {
+		ss := []Setting{}
+		for i := 0; i < v.NumField(); i++ {
+			sf := v.Type().Field(i)
+			k := sf.Name
+			if key != "" {
+				k = key + "." + sf.Name
+			}
+			ss = append(ss, describe(k, v.Field(i), sf)...)
+		}
+		return ss
+	}
+	s := Setting{Key: key, Value: v.Interface(), Source: SourceFile, Default: f.Tag.Get("default"), Env: f.Tag.Get("env")}
+	if _, ok := os.LookupEnv(s.Env); s.Env != "" && ok {
+		s.Source = SourceEnv
+	} else if v.Kind() != reflect.Slice && v.Kind() != reflect.Map && fmt.Sprint(s.Value) == s.Default {
+		s.Source = SourceDefault
+	} else if isZero(v) && s.Default == "" {
+		s.Source = SourceDefault
+	}
+	if f.Tag.Get("secret") == "true" {
+		s.Default = ""
+		if !isZero(v) {
+			s.Value = Redacted
+		}
+	}
+	return []Setting{s}
+}
+
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.Interface() == reflect.Zero(v.Type()).Interface()
+}
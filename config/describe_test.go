@@ -0,0 +1,37 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribe(t *testing.T) {
+	c := Configuration{}
+	c.NodeNetwork.Port = 6969
+	c.NodeNetwork.Interface = "0.0.0.0"
+	c.Web.API.AdminPassword = "hunter2"
+	c.Storage.EncryptionKey = ""
+	c.Alerts.Webhook = "https://hooks.example.com/T000/secret"
+	assert.NoError(t, os.Setenv("API_PORT", "3001"))
+	defer os.Unsetenv("API_PORT")
+	c.Web.API.Port = 3001
+
+	ss := map[string]Setting{}
+	for _, s := range Describe(c) {
+		ss[s.Key] = s
+	}
+	assert.Equal(t, Setting{Key: "NodeNetwork.Port", Value: 6969, Source: SourceDefault, Default: "6969", Env: "NODE_PORT"}, ss["NodeNetwork.Port"])
+	assert.Equal(t, SourceFile, ss["NodeNetwork.Interface"].Source)
+	assert.Equal(t, "0.0.0.0", ss["NodeNetwork.Interface"].Value)
+	assert.Equal(t, SourceEnv, ss["Web.API.Port"].Source)
+	assert.Equal(t, Redacted, ss["Web.API.AdminPassword"].Value)
+	assert.Empty(t, ss["Web.API.AdminPassword"].Default)
+	assert.Equal(t, "", ss["Storage.EncryptionKey"].Value)
+	assert.Equal(t, SourceDefault, ss["NodeNetwork.Bootstrap"].Source)
+	assert.Contains(t, ss, "Identity.Token.PIN")
+	assert.Equal(t, Redacted, ss["Alerts.Webhook"].Value)
+	assert.Equal(t, "", ss["Flags.Webhook"].Value)
+	assert.Empty(t, ss["Anchor.Ethereum.RPC"].Default)
+}
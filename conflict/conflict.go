@@ -0,0 +1,151 @@
+package conflict
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/u-speak/core/follow"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+// Rule returns the key a site of the type has to hold exclusively. Sites with the same key conflict
+type Rule func(o *tangle.Object) (string, bool)
+
+// Rules are the uniqueness rules per site type.
+// An author can only publish one follow list per timestamp, a second one would make the latest list ambiguous
+var Rules = map[string]Rule{
+	"follow": func(o *tangle.Object) (string, bool) {
+		l, ok := o.Data.(*follow.List)
+		if !ok || l.Fingerprint() == "" {
+			return "", false
+		}
+		if _, err := l.Verify(); err != nil {
+			return "", false
+		}
+		return l.Fingerprint() + "@" + strconv.FormatInt(l.Timestamp, 10), true
+	},
+}
+
+// Status describes the conflict a site is part of.
+// Canonical is the conflicting site confirmed by the heaviest cumulative weight
+type Status struct {
+	Conflicting bool        `json:"conflicting"`
+	Canonical   hash.Hash   `json:"canonical"`
+	Sites       []hash.Hash `json:"sites"`
+}
+
+// Index groups the sites of types with uniqueness rules by their key
+type Index struct {
+	keys   map[hash.Hash]string
+	sites  map[string][]hash.Hash
+	weight func(hash.Hash) int
+	lock   sync.RWMutex
+}
+
+// NewIndex returns an empty conflict index. weight returns the cumulative weight of a site
+func NewIndex(weight func(hash.Hash) int) *Index {
+	return &Index{keys: make(map[hash.Hash]string), sites: make(map[string][]hash.Hash), weight: weight}
+}
+
+// Add indexes the object if its type has a uniqueness rule
+func (i *Index) Add(o *tangle.Object) {
+	r, ok := Rules[o.Site.Type]
+	if !ok {
+		return
+	}
+	k, ok := r(o)
+	if !ok {
+		return
+	}
+	k = o.Site.Type + "/" + k
+	h := o.Site.Hash()
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if _, seen := i.keys[h]; seen {
+		return
+	}
+	i.keys[h] = k
+	i.sites[k] = append(i.sites[k], h)
+}
+
+// Indexed reports whether the object is reflected in the index. Only types with uniqueness rules are indexed
+func (i *Index) Indexed(o *tangle.Object) bool {
+	r, ok := Rules[o.Site.Type]
+	if !ok {
+		return true
+	}
+	if _, ok := r(o); !ok {
+		return true
+	}
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	_, seen := i.keys[o.Site.Hash()]
+	return seen
+}
+
+// Sync indexes all sites stored in the tangle whose types have uniqueness rules
+func (i *Index) Sync(t *tangle.Tangle) {
+	for _, h := range t.Hashes() {
+		s := t.GetSite(h)
+		if s == nil {
+			continue
+		}
+		if _, ok := Rules[s.Type]; !ok {
+			continue
+		}
+		if o := t.Get(h); o != nil {
+			i.Add(o)
+		}
+	}
+}
+
+// Get returns the conflict status of the site. Sites without conflicts are their own canonical site
+func (i *Index) Get(h hash.Hash) Status {
+	i.lock.RLock()
+	hs := []hash.Hash{h}
+	if k, ok := i.keys[h]; ok {
+		hs = append([]hash.Hash{}, i.sites[k]...)
+	}
+	i.lock.RUnlock()
+	return Status{Conflicting: len(hs) > 1, Canonical: i.canonical(hs), Sites: hs}
+}
+
+// Canonical reports if the site is the canonical one of its conflict
+func (i *Index) Canonical(h hash.Hash) bool {
+	return i.Get(h).Canonical == h
+}
+
+// Conflicts returns the sites of all conflicts, grouped by conflict
+func (i *Index) Conflicts() [][]hash.Hash {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	cs := [][]hash.Hash{}
+	for _, hs := range i.sites {
+		if len(hs) > 1 {
+			cs = append(cs, append([]hash.Hash{}, hs...))
+		}
+	}
+	return cs
+}
+
+// canonical picks the site with the highest weight, ties are broken by the lower hash so all nodes agree
+func (i *Index) canonical(hs []hash.Hash) hash.Hash {
+	if len(hs) == 1 {
+		return hs[0]
+	}
+	weights := make(map[hash.Hash]int, len(hs))
+	for _, h := range hs {
+		weights[h] = i.weight(h)
+	}
+	sorted := append([]hash.Hash{}, hs...)
+	sort.Slice(sorted, func(a, b int) bool {
+		if weights[sorted[a]] != weights[sorted[b]] {
+			return weights[sorted[a]] > weights[sorted[b]]
+		}
+		return bytes.Compare(sorted[a][:], sorted[b][:]) < 0
+	})
+	return sorted[0]
+}
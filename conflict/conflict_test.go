@@ -0,0 +1,64 @@
+package conflict
+
+import (
+	"bytes"
+	"crypto"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/follow"
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/packet"
+)
+
+var pgpConfig = &packet.Config{DefaultHash: crypto.SHA256}
+
+func list(nonce uint64, e *openpgp.Entity, content string, ts int64) *tangle.Object {
+	buff := bytes.NewBuffer(nil)
+	_ = openpgp.ArmoredDetachSignText(buff, e, strings.NewReader(content), pgpConfig)
+	p := post.Post{Content: content, Pubkey: e, Signature: buff.String(), Timestamp: ts}
+	return &tangle.Object{Site: &site.Site{Type: "follow", Nonce: nonce}, Data: &follow.List{Post: p}}
+}
+
+func TestIndex(t *testing.T) {
+	e, err := openpgp.NewEntity("Test", "test", "test@example.com", pgpConfig)
+	assert.NoError(t, err)
+	weights := map[hash.Hash]int{}
+	i := NewIndex(func(h hash.Hash) int { return weights[h] })
+
+	a := list(1, e, "a", 5)
+	b := list(2, e, "b", 5)
+	c := list(3, e, "c", 6)
+	forged := list(5, e, "forged", 5)
+	forged.Data.(*follow.List).Content = "changed"
+	p := &tangle.Object{Site: &site.Site{Type: "post", Nonce: 4}, Data: &post.Post{Content: "p", Pubkey: e, Timestamp: 5}}
+	for _, o := range []*tangle.Object{a, b, c, p, a, forged} {
+		i.Add(o)
+	}
+
+	s := i.Get(c.Site.Hash())
+	assert.False(t, s.Conflicting)
+	assert.Equal(t, c.Site.Hash(), s.Canonical)
+	assert.True(t, i.Canonical(p.Site.Hash()))
+
+	weights[b.Site.Hash()] = 3
+	weights[a.Site.Hash()] = 1
+	s = i.Get(a.Site.Hash())
+	assert.True(t, s.Conflicting)
+	assert.ElementsMatch(t, []hash.Hash{a.Site.Hash(), b.Site.Hash()}, s.Sites)
+	assert.Equal(t, b.Site.Hash(), s.Canonical)
+	assert.False(t, i.Canonical(a.Site.Hash()))
+	assert.Len(t, i.Conflicts(), 1)
+
+	weights[a.Site.Hash()] = 3
+	first := a.Site.Hash()
+	if bs := b.Site.Hash(); bytes.Compare(bs[:], first[:]) < 0 {
+		first = b.Site.Hash()
+	}
+	assert.Equal(t, first, i.Get(b.Site.Hash()).Canonical)
+}
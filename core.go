@@ -1,11 +1,18 @@
 package core
 
 import (
+	"io/ioutil"
+	"time"
+
 	"github.com/u-speak/core/api"
 	"github.com/u-speak/core/config"
 	"github.com/u-speak/core/diag"
+	"github.com/u-speak/core/doctor"
+	"github.com/u-speak/core/genesis"
 	"github.com/u-speak/core/minui"
 	"github.com/u-speak/core/node"
+	"github.com/u-speak/core/simulation"
+	"github.com/u-speak/core/tangle"
 	"github.com/u-speak/core/webserver"
 
 	log "github.com/sirupsen/logrus"
@@ -40,3 +47,79 @@ func RunMinUI(n *node.Node) {
 	s := minui.New(Config, n)
 	s.Run()
 }
+
+// RunSimulation runs an in-process network generating synthetic load and logs how the sites propagated
+func RunSimulation() {
+	r, err := simulation.Run(simulation.FromConfig(Config))
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	log.Info(r)
+}
+
+// CreateGenesis writes the genesis file of a new network founded by the armored public keys in keyFiles.
+// algorithm names the proof of work of the network, the hash weight of the builtin network if empty.
+// Nodes join the network by setting the path of the file as NodeNetwork.Genesis
+func CreateGenesis(network string, keyFiles []string, algorithm string, out string) error {
+	keys := []string{}
+	for _, f := range keyFiles {
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, string(b))
+	}
+	g, err := genesis.Create(network, time.Now(), keys, tangle.MinimumWeight, algorithm)
+	if err != nil {
+		return err
+	}
+	if err := g.Save(out); err != nil {
+		return err
+	}
+	log.Infof("Created genesis of network %s with tips %s and %s", network, g.Sites()[0].Hash(), g.Sites()[1].Hash())
+	return nil
+}
+
+// RunDoctor checks the configuration and environment before the node is started and logs the results.
+// It returns false if any check failed
+func RunDoctor() bool {
+	r := doctor.Run(Config, doctor.Options{})
+	for _, res := range r.Results {
+		l := log.WithField("check", res.Check)
+		switch res.Status {
+		case doctor.Fail:
+			l.Error(res.Message)
+		case doctor.Warn:
+			l.Warn(res.Message)
+		default:
+			l.Info(res.Message)
+		}
+	}
+	return r.Healthy
+}
+
+// RepairTangle checks the stores of the node for damage left by a crash and repairs it.
+// Missing sites and payloads are fetched from peer, sites and payloads which can not be fetched are only removed if truncate is set.
+// It returns false if damage is left
+func RepairTangle(peer string, truncate bool) bool {
+	n, err := node.New(Config)
+	if err != nil {
+		log.Errorf("Could not open the node: %s", err)
+		return false
+	}
+	defer func() {
+		if err := n.Shutdown(); err != nil {
+			log.Error(err)
+		}
+	}()
+	r := n.Repair(peer, truncate)
+	for _, d := range r.Found {
+		log.Infof("Found %s at %s", d.Kind, d.Site)
+	}
+	for _, d := range r.Remaining {
+		log.Errorf("Could not repair %s at %s", d.Kind, d.Site)
+	}
+	log.Infof("Repaired %d of %d problems", len(r.Found)-len(r.Remaining), len(r.Found))
+	return len(r.Remaining) == 0
+}
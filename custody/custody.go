@@ -0,0 +1,293 @@
+package custody
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/bbolt"
+	"github.com/u-speak/core/migrate"
+	"github.com/u-speak/core/post"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	"golang.org/x/crypto/openpgp/packet"
+	"golang.org/x/crypto/scrypt"
+)
+
+// migrations upgrade the key database to the current format, see migrate.Open
+var migrations = []migrate.Migration{migrate.None}
+
+const (
+	saltSize = 16
+	// scrypt parameters recommended for interactive logins
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+	// MaxDerivations is the amount of keys derived from passphrases at once by all vaults.
+	// Derivations wait at most derivationWait for a slot
+	MaxDerivations = 4
+	derivationWait = time.Second
+)
+
+// derivations holds a slot for every running scrypt derivation
+var derivations = make(chan struct{}, MaxDerivations)
+
+var (
+	keyBucketName = []byte("keys")
+	// ErrUnknownKey is returned for fingerprints without a stored key
+	ErrUnknownKey = errors.New("No key stored for this fingerprint")
+	// ErrPassphrase is returned when the key can not be unlocked with the passphrase
+	ErrPassphrase = errors.New("Wrong passphrase")
+	// ErrNoPrivateKey is returned when importing a key without its private part
+	ErrNoPrivateKey = errors.New("Key does not contain a private key")
+	// ErrRateLimited is returned when a key is used again before the interval passed
+	ErrRateLimited = errors.New("Too many requests for this key, please try again later")
+	// ErrBusy is returned when too many passphrases are checked at once
+	ErrBusy = errors.New("Too many keys are unlocked at the moment, please try again later")
+)
+
+// Vault stores the private keys of hosted identities. Every key is encrypted with a key
+// derived from the passphrase of its owner, which is never stored. A key can only be unlocked
+// once per interval, failed attempts do not count, so guessing can not lock out the owner.
+// Guessing is slowed down by the global limit on derivations instead
+type Vault struct {
+	db       *bolt.DB
+	interval time.Duration
+	last     map[string]time.Time
+	lock     sync.Mutex
+}
+
+// New opens the key database at path, allowing each key to be unlocked once per interval
+func New(path string, interval time.Duration) (*Vault, error) {
+	db, err := migrate.Open(path, 0600, migrations)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(keyBucketName)
+		return err
+	})
+	return &Vault{db: db, interval: interval, last: make(map[string]time.Time)}, err
+}
+
+// Import stores an armored private key, returning its fingerprint.
+// Keys protected by a passphrase have to use the same passphrase as the vault
+func (v *Vault) Import(armored, passphrase string) (string, error) {
+	b, err := armor.Decode(strings.NewReader(armored))
+	if err != nil {
+		return "", err
+	}
+	raw, err := ioutil.ReadAll(b.Body)
+	if err != nil {
+		return "", err
+	}
+	e, err := unlock(raw, passphrase)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := seal(raw, passphrase)
+	if err != nil {
+		return "", err
+	}
+	fp := fingerprint(e)
+	return fp, v.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(keyBucketName).Put([]byte(fp), sealed)
+	})
+}
+
+// Export returns the armored private key exactly as it was imported
+func (v *Vault) Export(fp, passphrase string) (string, error) {
+	raw, err := v.open(fp, passphrase)
+	if err != nil {
+		return "", err
+	}
+	buff := bytes.NewBuffer(nil)
+	w, err := armor.Encode(buff, openpgp.PrivateKeyType, make(map[string]string))
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write(raw); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return buff.String(), nil
+}
+
+// Sign creates a post with the content, signed by the stored key
+func (v *Vault) Sign(fp, passphrase, content string) (*post.Post, error) {
+	raw, err := v.open(fp, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	e, err := unlock(raw, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	sig := bytes.NewBuffer(nil)
+	err = openpgp.ArmoredDetachSignText(sig, e, strings.NewReader(content), nil)
+	if err != nil {
+		return nil, err
+	}
+	return &post.Post{Content: content, Pubkey: e, Signature: sig.String(), Timestamp: time.Now().Unix()}, nil
+}
+
+// Remove deletes the stored key. The passphrase is required to prove ownership
+func (v *Vault) Remove(fp, passphrase string) error {
+	if _, err := v.open(fp, passphrase); err != nil {
+		return err
+	}
+	return v.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(keyBucketName).Delete([]byte(fp))
+	})
+}
+
+// Close closes the key database
+func (v *Vault) Close() error {
+	return v.db.Close()
+}
+
+// open decrypts the stored key if it was not unlocked within the interval
+func (v *Vault) open(fp, passphrase string) ([]byte, error) {
+	if v.locked(fp, time.Now()) {
+		return nil, ErrRateLimited
+	}
+	var sealed []byte
+	v.db.View(func(tx *bolt.Tx) error {
+		if b := tx.Bucket(keyBucketName).Get([]byte(fp)); b != nil {
+			sealed = append([]byte{}, b...)
+		}
+		return nil
+	})
+	if sealed == nil {
+		return nil, ErrUnknownKey
+	}
+	raw, err := unseal(sealed, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if !v.allow(fp, time.Now()) {
+		return nil, ErrRateLimited
+	}
+	return raw, nil
+}
+
+// locked reports whether the key was unlocked within the interval
+func (v *Vault) locked(fp string, now time.Time) bool {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	t, ok := v.last[fp]
+	return ok && now.Sub(t) < v.interval
+}
+
+// allow records a successful unlock of the key unless another one happened within the interval
+func (v *Vault) allow(fp string, now time.Time) bool {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	for k, t := range v.last {
+		if now.Sub(t) >= v.interval {
+			delete(v.last, k)
+		}
+	}
+	if _, ok := v.last[fp]; ok {
+		return false
+	}
+	v.last[fp] = now
+	return true
+}
+
+// unlock parses the key and decrypts its private parts if they are protected
+func unlock(raw []byte, passphrase string) (*openpgp.Entity, error) {
+	e, err := openpgp.ReadEntity(packet.NewReader(bytes.NewReader(raw)))
+	if err != nil {
+		return nil, err
+	}
+	if e.PrivateKey == nil {
+		return nil, ErrNoPrivateKey
+	}
+	if e.PrivateKey.Encrypted {
+		if err := e.PrivateKey.Decrypt([]byte(passphrase)); err != nil {
+			return nil, ErrPassphrase
+		}
+	}
+	for _, s := range e.Subkeys {
+		if s.PrivateKey != nil && s.PrivateKey.Encrypted {
+			if err := s.PrivateKey.Decrypt([]byte(passphrase)); err != nil {
+				return nil, ErrPassphrase
+			}
+		}
+	}
+	return e, nil
+}
+
+func fingerprint(e *openpgp.Entity) string {
+	return hex.EncodeToString(e.PrimaryKey.Fingerprint[:])
+}
+
+// derive returns the cipher of the key derived from the passphrase. It fails with ErrBusy if
+// MaxDerivations are running for longer than derivationWait
+func derive(passphrase string, salt []byte) (cipher.AEAD, error) {
+	t := time.NewTimer(derivationWait)
+	defer t.Stop()
+	select {
+	case derivations <- struct{}{}:
+	case <-t.C:
+		return nil, ErrBusy
+	}
+	k, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, 32)
+	<-derivations
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(k)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts b as salt || nonce || ciphertext
+func seal(b []byte, passphrase string) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := derive(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := append(salt, nonce...)
+	return aead.Seal(out, nonce, b, nil), nil
+}
+
+func unseal(b []byte, passphrase string) ([]byte, error) {
+	if len(b) < saltSize {
+		return nil, ErrPassphrase
+	}
+	aead, err := derive(passphrase, b[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+	b = b[saltSize:]
+	if len(b) < aead.NonceSize() {
+		return nil, ErrPassphrase
+	}
+	out, err := aead.Open(nil, b[:aead.NonceSize()], b[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrPassphrase
+	}
+	return out, nil
+}
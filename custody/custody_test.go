@@ -0,0 +1,108 @@
+package custody
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+)
+
+func armoredKey(t *testing.T) (string, *openpgp.Entity) {
+	e, err := openpgp.NewEntity("Test", "test", "test@example.com", nil)
+	assert.NoError(t, err)
+	buff := bytes.NewBuffer(nil)
+	w, err := armor.Encode(buff, openpgp.PrivateKeyType, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, e.SerializePrivate(w, nil))
+	assert.NoError(t, w.Close())
+	return buff.String(), e
+}
+
+func TestVault(t *testing.T) {
+	p := path.Join(os.TempDir(), "custody.db")
+	defer os.Remove(p)
+	v, err := New(p, 0)
+	assert.NoError(t, err)
+	defer v.Close()
+
+	key, e := armoredKey(t)
+	fp, err := v.Import(key, "secret")
+	assert.NoError(t, err)
+
+	ps, err := v.Sign(fp, "secret", "foo")
+	assert.NoError(t, err)
+	assert.Equal(t, fp, ps.Fingerprint())
+	signer, err := ps.Verify()
+	assert.NoError(t, err)
+	assert.Equal(t, e.PrimaryKey.Fingerprint, signer.PrimaryKey.Fingerprint)
+
+	_, err = v.Sign(fp, "wrong", "foo")
+	assert.Equal(t, ErrPassphrase, err)
+	_, err = v.Sign("unknown", "secret", "foo")
+	assert.Equal(t, ErrUnknownKey, err)
+
+	exported, err := v.Export(fp, "secret")
+	assert.NoError(t, err)
+	again, err := v.Import(exported, "other")
+	assert.NoError(t, err)
+	assert.Equal(t, fp, again)
+
+	assert.NoError(t, v.Remove(fp, "other"))
+	_, err = v.Export(fp, "other")
+	assert.Equal(t, ErrUnknownKey, err)
+}
+
+func TestPublicKeyOnly(t *testing.T) {
+	p := path.Join(os.TempDir(), "custody_public.db")
+	defer os.Remove(p)
+	v, err := New(p, 0)
+	assert.NoError(t, err)
+	defer v.Close()
+
+	_, e := armoredKey(t)
+	buff := bytes.NewBuffer(nil)
+	w, _ := armor.Encode(buff, openpgp.PublicKeyType, nil)
+	assert.NoError(t, e.Serialize(w))
+	assert.NoError(t, w.Close())
+	_, err = v.Import(buff.String(), "secret")
+	assert.Equal(t, ErrNoPrivateKey, err)
+}
+
+func TestRateLimit(t *testing.T) {
+	p := path.Join(os.TempDir(), "custody_rate.db")
+	defer os.Remove(p)
+	v, err := New(p, time.Hour)
+	assert.NoError(t, err)
+	defer v.Close()
+
+	key, _ := armoredKey(t)
+	fp, err := v.Import(key, "secret")
+	assert.NoError(t, err)
+	_, err = v.Sign(fp, "wrong", "foo")
+	assert.Equal(t, ErrPassphrase, err)
+	_, err = v.Sign(fp, "secret", "foo")
+	assert.NoError(t, err)
+	_, err = v.Sign(fp, "secret", "foo")
+	assert.Equal(t, ErrRateLimited, err)
+	_, err = v.Sign(fp, "wrong", "foo")
+	assert.Equal(t, ErrRateLimited, err)
+}
+
+func TestDerivations(t *testing.T) {
+	for i := 0; i < MaxDerivations; i++ {
+		derivations <- struct{}{}
+	}
+	_, err := seal([]byte("foo"), "secret")
+	assert.Equal(t, ErrBusy, err)
+	for i := 0; i < MaxDerivations; i++ {
+		<-derivations
+	}
+	_, err = seal([]byte("foo"), "secret")
+	assert.NoError(t, err)
+}
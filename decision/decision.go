@@ -0,0 +1,158 @@
+// Package decision records why sites were accepted or rejected, so the outcome of a submission can be looked up later
+package decision
+
+import (
+	"sync"
+	"time"
+
+	"github.com/u-speak/core/tangle/hash"
+)
+
+// Outcomes of an acceptance attempt
+const (
+	Accepted  = "accepted"
+	Rejected  = "rejected"
+	Orphaned  = "orphaned"
+	Duplicate = "duplicate"
+	Queued    = "queued"
+)
+
+// Sources a site can be received from
+const (
+	SourceAPI      = "api"
+	SourcePeer     = "peer"
+	SourceSplice   = "splice"
+	SourceOrphan   = "orphan"
+	SourceSolidify = "solidify"
+)
+
+// Check is a single step of the acceptance pipeline
+type Check struct {
+	Name     string        `json:"name"`
+	Passed   bool          `json:"passed"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Decision is the record of one attempt to accept a site
+type Decision struct {
+	Seq      uint64        `json:"seq"`
+	Hash     hash.Hash     `json:"hash"`
+	Source   string        `json:"source"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+	Checks   []Check       `json:"checks"`
+	Outcome  string        `json:"outcome"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Trace collects the checks of an attempt until it is finished and stored in the log
+type Trace struct {
+	d    Decision
+	log  *Log
+	mark time.Time
+}
+
+// SetHash records which site the attempt is about once it is known
+func (t *Trace) SetHash(h hash.Hash) {
+	t.d.Hash = h
+}
+
+// Check records the outcome of a check and the time spent since the previous one. It reports whether the check passed
+func (t *Trace) Check(name string, err error) bool {
+	now := time.Now()
+	c := Check{Name: name, Passed: err == nil, Duration: now.Sub(t.mark)}
+	if err != nil {
+		c.Error = err.Error()
+	}
+	t.d.Checks = append(t.d.Checks, c)
+	t.mark = now
+	return err == nil
+}
+
+// Finish stores the attempt with its outcome in the log. err is the reason of a rejection
+func (t *Trace) Finish(outcome string, err error) {
+	t.d.Outcome = outcome
+	if err != nil {
+		t.d.Error = err.Error()
+	}
+	t.d.Duration = time.Since(t.d.Start)
+	t.log.add(t.d)
+}
+
+// Reject finishes the attempt as rejected, with the error of the last failed check if err is nil
+func (t *Trace) Reject(err error) {
+	if err == nil {
+		for i := len(t.d.Checks) - 1; i >= 0; i-- {
+			if !t.d.Checks[i].Passed {
+				t.d.Error = t.d.Checks[i].Error
+				break
+			}
+		}
+	}
+	t.Finish(Rejected, err)
+}
+
+// Log keeps the most recent decisions
+type Log struct {
+	size      int
+	seq       uint64
+	decisions []Decision
+	lock      sync.Mutex
+}
+
+// New creates a log remembering up to size decisions
+func New(size int) *Log {
+	return &Log{size: size}
+}
+
+// Begin starts tracing an attempt to accept a site received from source
+func (l *Log) Begin(source string) *Trace {
+	now := time.Now()
+	return &Trace{d: Decision{Source: source, Start: now, Checks: []Check{}}, log: l, mark: now}
+}
+
+func (l *Log) add(d Decision) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.size <= 0 {
+		return
+	}
+	l.seq++
+	d.Seq = l.seq
+	l.decisions = append(l.decisions, d)
+	if len(l.decisions) > l.size {
+		l.decisions = l.decisions[len(l.decisions)-l.size:]
+	}
+}
+
+// Find returns all remembered decisions about the site, newest first
+func (l *Log) Find(h hash.Hash) []Decision {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	ds := []Decision{}
+	for i := len(l.decisions) - 1; i >= 0; i-- {
+		if l.decisions[i].Hash == h {
+			ds = append(ds, l.decisions[i])
+		}
+	}
+	return ds
+}
+
+// Recent returns up to limit decisions, newest first. A limit of 0 returns all of them
+func (l *Log) Recent(limit int) []Decision {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	ds := []Decision{}
+	for i := len(l.decisions) - 1; i >= 0 && (limit <= 0 || len(ds) < limit); i-- {
+		ds = append(ds, l.decisions[i])
+	}
+	return ds
+}
+
+// Len returns the amount of remembered decisions
+func (l *Log) Len() int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return len(l.decisions)
+}
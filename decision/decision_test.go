@@ -0,0 +1,50 @@
+package decision
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+func TestLog(t *testing.T) {
+	l := New(2)
+	a := hash.New([]byte("a"))
+	b := hash.New([]byte("b"))
+
+	tr := l.Begin("api")
+	tr.SetHash(a)
+	assert.True(t, tr.Check("fields", nil))
+	assert.False(t, tr.Check("tangle", errors.New("not validating")))
+	tr.Reject(nil)
+
+	tr = l.Begin("peer")
+	tr.SetHash(a)
+	tr.Check("tangle", nil)
+	tr.Finish(Accepted, nil)
+
+	ds := l.Find(a)
+	assert.Len(t, ds, 2)
+	assert.Equal(t, Accepted, ds[0].Outcome)
+	assert.Equal(t, Rejected, ds[1].Outcome)
+	assert.Equal(t, "not validating", ds[1].Error)
+	assert.Len(t, ds[1].Checks, 2)
+	assert.Equal(t, "api", ds[1].Source)
+
+	tr = l.Begin("peer")
+	tr.SetHash(b)
+	tr.Finish(Duplicate, nil)
+	assert.Equal(t, 2, l.Len())
+	assert.Len(t, l.Find(a), 1)
+	r := l.Recent(1)
+	assert.Len(t, r, 1)
+	assert.Equal(t, b, r[0].Hash)
+	assert.Equal(t, uint64(3), r[0].Seq)
+}
+
+func TestDisabled(t *testing.T) {
+	l := New(0)
+	l.Begin("api").Finish(Accepted, nil)
+	assert.Equal(t, 0, l.Len())
+}
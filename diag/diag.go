@@ -7,6 +7,7 @@ import (
 
 	"github.com/u-speak/core/config"
 	"github.com/u-speak/core/node"
+	"github.com/u-speak/core/recovery"
 	"github.com/u-speak/core/tangle/hash"
 	"github.com/u-speak/core/tangle/site"
 
@@ -33,6 +34,7 @@ func Run(c config.Configuration, n *node.Node) error {
 	e.HideBanner = true
 	e.HidePort = true
 	e.Logger = logrusmiddleware.Logger{log.StandardLogger()}
+	e.Use(recovery.Echo("diag"))
 	e.GET("/", s.getIndex)
 	e.GET("/static/:name", s.getStatic)
 	e.GET("/tangle/graph", s.getGraph)
@@ -0,0 +1,226 @@
+package digest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"net/mail"
+	"net/smtp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/u-speak/core/config"
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// MaxSummaryLength is the longest summary of a post included in the digest
+	MaxSummaryLength = 120
+)
+
+// Digest compiles the heaviest posts of a period and mails them to all subscribers.
+// Addresses are only subscribed once the link mailed to them was followed, they never appear on the tangle
+type Digest struct {
+	Subscribers *Store
+	tangle      *tangle.Tangle
+	interval    int
+	limit       int
+	confirmTTL  time.Duration
+	endpoint    string
+	smtpAddr    string
+	auth        smtp.Auth
+	from        string
+	// send delivers a mail, smtp.SendMail outside of tests
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// Entry is a single post inside the digest
+type Entry struct {
+	Hash    hash.Hash
+	Weight  int
+	Summary string
+	Link    string
+}
+
+var mailTemplate = template.Must(template.New("digest").Parse(`From: {{.From}}
+To: {{.To}}
+Subject: u-speak digest: {{len .Entries}} top posts
+Content-Type: text/plain; charset=UTF-8
+
+The heaviest posts of the last {{.Interval}} hours:
+{{range .Entries}}
+* {{.Summary}}
+  Weight: {{.Weight}} - {{.Link}}
+{{end}}`))
+
+var confirmTemplate = template.Must(template.New("confirm").Parse(`From: {{.From}}
+To: {{.To}}
+Subject: Confirm your u-speak digest subscription
+Content-Type: text/plain; charset=UTF-8
+
+Someone asked to send the u-speak digest to this address every {{.Interval}} hours.
+To receive it, confirm the subscription within {{.TTL}} hours:
+
+{{.Link}}
+
+If you did not ask for the digest, ignore this mail and the address will not be used again.
+`))
+
+// New returns a digest reading posts from the tangle
+func New(c config.Configuration, t *tangle.Tangle) (*Digest, error) {
+	s, err := NewStore(c.Storage.DigestPath)
+	if err != nil {
+		return nil, err
+	}
+	d := &Digest{
+		Subscribers: s,
+		tangle:      t,
+		interval:    c.Digest.Interval,
+		limit:       c.Digest.Limit,
+		confirmTTL:  time.Duration(c.Digest.ConfirmTTL) * time.Hour,
+		endpoint:    c.Web.API.PublicEndpoint,
+		smtpAddr:    c.Digest.SMTP.Host + ":" + strconv.Itoa(c.Digest.SMTP.Port),
+		from:        c.Digest.SMTP.From,
+		send:        smtp.SendMail,
+	}
+	if c.Digest.SMTP.User != "" {
+		d.auth = smtp.PlainAuth("", c.Digest.SMTP.User, c.Digest.SMTP.Password, c.Digest.SMTP.Host)
+	}
+	return d, nil
+}
+
+// Interval returns the digest period in hours
+func (d *Digest) Interval() int {
+	return d.interval
+}
+
+// Compile returns the heaviest posts published in the period before now
+func (d *Digest) Compile(now time.Time) []Entry {
+	since := now.Add(-time.Duration(d.interval) * time.Hour).Unix()
+	posts := []*tangle.Object{}
+	sites := []*site.Site{}
+	for _, h := range d.tangle.Hashes() {
+		o := d.tangle.Get(h)
+		if o == nil || o.Site.Type != "post" {
+			continue
+		}
+		p := o.Data.(*post.Post)
+		if p.Timestamp < since || p.Timestamp > now.Unix() {
+			continue
+		}
+		posts = append(posts, o)
+		sites = append(sites, o.Site)
+	}
+	ws := d.tangle.Weights(sites)
+	entries := []Entry{}
+	for _, o := range posts {
+		h := o.Site.Hash()
+		entries = append(entries, Entry{
+			Hash:    h,
+			Weight:  ws[h],
+			Summary: Summarize(o.Data.(*post.Post).Content),
+			Link:    d.link(h),
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Weight > entries[j].Weight })
+	if len(entries) > d.limit {
+		entries = entries[:d.limit]
+	}
+	return entries
+}
+
+// Send compiles the digest and mails it to every subscriber
+func (d *Digest) Send() error {
+	entries := d.Compile(time.Now())
+	if len(entries) == 0 {
+		log.Info("No posts for digest, skipping")
+		return nil
+	}
+	for _, to := range d.Subscribers.List() {
+		msg, err := d.render(to, entries)
+		if err != nil {
+			return err
+		}
+		err = d.send(d.smtpAddr, d.auth, d.from, []string{to}, msg)
+		if err != nil {
+			log.Errorf("Could not send digest to %s: %s", to, err)
+		}
+	}
+	log.Infof("Sent digest with %d posts", len(entries))
+	return nil
+}
+
+// Subscribe validates the address and mails it a link confirming the subscription.
+// Addresses which are subscribed already are not mailed again
+func (d *Digest) Subscribe(addr string) error {
+	a, err := mail.ParseAddress(addr)
+	if err != nil {
+		return err
+	}
+	if d.Subscribers.Subscribed(a.Address) {
+		return nil
+	}
+	t := make([]byte, 16)
+	if _, err := rand.Read(t); err != nil {
+		return err
+	}
+	token := hex.EncodeToString(t)
+	now := time.Now()
+	if err := d.Subscribers.Propose(a.Address, token, now.Add(d.confirmTTL), now); err != nil {
+		return err
+	}
+	buff := bytes.NewBuffer(nil)
+	err = confirmTemplate.Execute(buff, struct {
+		From     string
+		To       string
+		Interval int
+		TTL      int
+		Link     string
+	}{From: d.from, To: a.Address, Interval: d.interval, TTL: int(d.confirmTTL.Hours()), Link: d.endpoint + "/api/v1/digest/confirm/" + token})
+	if err != nil {
+		return err
+	}
+	return d.send(d.smtpAddr, d.auth, d.from, []string{a.Address}, bytes.Replace(buff.Bytes(), []byte("\n"), []byte("\r\n"), -1))
+}
+
+// Confirm subscribes the address the token was mailed to
+func (d *Digest) Confirm(token string) error {
+	addr, err := d.Subscribers.Confirm(token, time.Now())
+	if err != nil {
+		return err
+	}
+	log.Infof("Confirmed digest subscription of %s", addr)
+	return nil
+}
+
+// Summarize returns the first line of a post, shortened to MaxSummaryLength characters
+func Summarize(s string) string {
+	s = strings.TrimSpace(strings.SplitN(strings.TrimSpace(s), "\n", 2)[0])
+	if r := []rune(s); len(r) > MaxSummaryLength {
+		return string(r[:MaxSummaryLength-3]) + "..."
+	}
+	return s
+}
+
+func (d *Digest) render(to string, entries []Entry) ([]byte, error) {
+	buff := bytes.NewBuffer(nil)
+	err := mailTemplate.Execute(buff, struct {
+		From     string
+		To       string
+		Interval int
+		Entries  []Entry
+	}{From: d.from, To: to, Interval: d.interval, Entries: entries})
+	return bytes.Replace(buff.Bytes(), []byte("\n"), []byte("\r\n"), -1), err
+}
+
+func (d *Digest) link(h hash.Hash) string {
+	return d.endpoint + "/api/v1/tangle/" + h.String()
+}
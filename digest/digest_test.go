@@ -0,0 +1,131 @@
+package digest
+
+import (
+	"bytes"
+	"crypto"
+	"net/smtp"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/site"
+	"github.com/u-speak/core/tangle/store"
+	"github.com/u-speak/core/tangle/store/memorystore"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/packet"
+)
+
+func testPost(content string, ts int64) *post.Post {
+	c := &packet.Config{DefaultHash: crypto.SHA256}
+	e, _ := openpgp.NewEntity("Test", "test", "test@example.com", c)
+	buff := bytes.NewBuffer(nil)
+	_ = openpgp.ArmoredDetachSignText(buff, e, strings.NewReader(content), c)
+	return &post.Post{Content: content, Pubkey: e, Signature: buff.String(), Timestamp: ts}
+}
+
+func TestStore(t *testing.T) {
+	p := path.Join(os.TempDir(), "testDigestStore.db")
+	defer os.Remove(p)
+	s, err := NewStore(p)
+	assert.NoError(t, err)
+	defer s.Close()
+	assert.Empty(t, s.List())
+	assert.NoError(t, s.Add("a@example.com"))
+	assert.NoError(t, s.Add("b@example.com"))
+	assert.NoError(t, s.Add("a@example.com"))
+	assert.Len(t, s.List(), 2)
+	assert.NoError(t, s.Remove("a@example.com"))
+	assert.Equal(t, []string{"b@example.com"}, s.List())
+}
+
+func TestSummarize(t *testing.T) {
+	assert.Equal(t, "Hello", Summarize("  Hello\nWorld"))
+	assert.Len(t, Summarize(strings.Repeat("a", 500)), MaxSummaryLength)
+	s := Summarize(strings.Repeat("ä", 500))
+	assert.True(t, utf8.ValidString(s))
+	assert.Equal(t, MaxSummaryLength, utf8.RuneCountInString(s))
+}
+
+func TestPending(t *testing.T) {
+	p := path.Join(os.TempDir(), "testDigestPending.db")
+	defer os.Remove(p)
+	s, err := NewStore(p)
+	assert.NoError(t, err)
+	defer s.Close()
+	now := time.Now()
+	assert.NoError(t, s.Propose("a@example.com", "a", now.Add(time.Hour), now))
+	assert.NoError(t, s.Propose("b@example.com", "b", now.Add(time.Hour), now))
+	assert.Empty(t, s.List())
+	_, err = s.Confirm("unknown", now)
+	assert.Equal(t, ErrUnknownToken, err)
+	addr, err := s.Confirm("a", now)
+	assert.NoError(t, err)
+	assert.Equal(t, "a@example.com", addr)
+	assert.True(t, s.Subscribed("a@example.com"))
+	_, err = s.Confirm("a", now)
+	assert.Equal(t, ErrUnknownToken, err)
+	_, err = s.Confirm("b", now.Add(2*time.Hour))
+	assert.Equal(t, ErrUnknownToken, err)
+	assert.Equal(t, []string{"a@example.com"}, s.List())
+
+	for i := 0; i < MaxPending; i++ {
+		assert.NoError(t, s.Propose("c@example.com", strconv.Itoa(i), now.Add(time.Hour), now))
+	}
+	assert.Equal(t, ErrTooManyPending, s.Propose("c@example.com", "full", now.Add(time.Hour), now))
+	assert.NoError(t, s.Propose("c@example.com", "full", now.Add(3*time.Hour), now.Add(2*time.Hour)))
+}
+
+func TestSubscribe(t *testing.T) {
+	p := path.Join(os.TempDir(), "testDigestSubscribe.db")
+	defer os.Remove(p)
+	s, err := NewStore(p)
+	assert.NoError(t, err)
+	defer s.Close()
+	sent := []string{}
+	d := &Digest{Subscribers: s, interval: 24, confirmTTL: time.Hour, endpoint: "https://example.com",
+		send: func(_ string, _ smtp.Auth, _ string, to []string, msg []byte) error {
+			sent = append(sent, string(msg))
+			return nil
+		}}
+	assert.Error(t, d.Subscribe("no address"))
+	assert.NoError(t, d.Subscribe("Reader <reader@example.com>"))
+	assert.Len(t, sent, 1)
+	assert.Empty(t, s.List())
+	i := strings.Index(sent[0], "/api/v1/digest/confirm/")
+	assert.True(t, i > 0)
+	token := strings.TrimSpace(strings.SplitN(sent[0][i+len("/api/v1/digest/confirm/"):], "\r\n", 2)[0])
+	assert.Equal(t, ErrUnknownToken, d.Confirm("forged"))
+	assert.NoError(t, d.Confirm(token))
+	assert.Equal(t, []string{"reader@example.com"}, s.List())
+	assert.NoError(t, d.Subscribe("reader@example.com"))
+	assert.Len(t, sent, 1)
+}
+
+func TestCompile(t *testing.T) {
+	ms := &memorystore.MemoryStore{}
+	_ = ms.Init(store.Options{})
+	dp := path.Join(os.TempDir(), "testDigestCompile.db")
+	defer os.Remove(dp)
+	tngl, err := tangle.New(tangle.Options{Store: ms, DataPath: dp})
+	assert.NoError(t, err)
+	defer tngl.Close()
+	now := time.Now()
+	tips := tngl.Tips()
+	for _, p := range []*post.Post{testPost("recent", now.Unix()), testPost("old", now.Add(-48*time.Hour).Unix())} {
+		h, _ := p.Hash()
+		o := &tangle.Object{Site: &site.Site{Content: h, Type: "post", Validates: tips}, Data: p}
+		o.Site.Mine(1)
+		assert.NoError(t, tngl.Inject(o, false))
+	}
+	d := &Digest{tangle: tngl, interval: 24, limit: 10}
+	es := d.Compile(now)
+	assert.Len(t, es, 1)
+	assert.Equal(t, "recent", es[0].Summary)
+}
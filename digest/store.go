@@ -0,0 +1,151 @@
+package digest
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/coreos/bbolt"
+	"github.com/u-speak/core/migrate"
+)
+
+// migrations upgrade the subscriber database to the current format, see migrate.Open
+var migrations = []migrate.Migration{migrate.None}
+
+// MaxPending is the amount of unconfirmed subscriptions kept at once
+const MaxPending = 1024
+
+var (
+	// ErrUnknownToken is returned when confirming with a token that was never issued, used already or expired
+	ErrUnknownToken = errors.New("Unknown or expired confirmation token")
+	// ErrTooManyPending is returned when MaxPending subscriptions wait for their confirmation
+	ErrTooManyPending = errors.New("Too many unconfirmed subscriptions, please try again later")
+)
+
+var (
+	subscriberBucketName = []byte("subscribers")
+	pendingBucketName    = []byte("pending")
+)
+
+// Store persists the addresses subscribed to the digest and the subscriptions waiting for their confirmation
+type Store struct {
+	db *bolt.DB
+}
+
+type pending struct {
+	Address string    `json:"address"`
+	Expires time.Time `json:"expires"`
+}
+
+// NewStore opens or creates the subscriber database
+func NewStore(path string) (*Store, error) {
+	db, err := migrate.Open(path, 0644, migrations)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, b := range [][]byte{subscriberBucketName, pendingBucketName} {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return &Store{db: db}, err
+}
+
+// Propose records a subscription of the address which is added once it is confirmed with the token before it expires.
+// Expired subscriptions are dropped first, further subscriptions are refused while MaxPending remain
+func (s *Store) Propose(addr, token string, expires, now time.Time) error {
+	v, err := json.Marshal(pending{Address: addr, Expires: expires})
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(pendingBucketName)
+		expired := [][]byte{}
+		live := 0
+		_ = b.ForEach(func(k, v []byte) error {
+			p := pending{}
+			if json.Unmarshal(v, &p) != nil || now.After(p.Expires) {
+				expired = append(expired, append([]byte{}, k...))
+			} else {
+				live++
+			}
+			return nil
+		})
+		for _, k := range expired {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		if live >= MaxPending {
+			return ErrTooManyPending
+		}
+		return b.Put([]byte(token), v)
+	})
+}
+
+// Confirm subscribes the address proposed with the token and returns it
+func (s *Store) Confirm(token string, now time.Time) (string, error) {
+	p := pending{}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(pendingBucketName)
+		v := b.Get([]byte(token))
+		if v == nil {
+			return ErrUnknownToken
+		}
+		if json.Unmarshal(v, &p) != nil || now.After(p.Expires) {
+			p.Address = ""
+		}
+		if err := b.Delete([]byte(token)); err != nil || p.Address == "" {
+			return err
+		}
+		return tx.Bucket(subscriberBucketName).Put([]byte(p.Address), []byte{})
+	})
+	if err == nil && p.Address == "" {
+		err = ErrUnknownToken
+	}
+	return p.Address, err
+}
+
+// Subscribed reports whether the address receives the digest
+func (s *Store) Subscribed(addr string) bool {
+	found := false
+	_ = s.db.View(func(tx *bolt.Tx) error {
+		found = tx.Bucket(subscriberBucketName).Get([]byte(addr)) != nil
+		return nil
+	})
+	return found
+}
+
+// Add subscribes an address
+func (s *Store) Add(addr string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(subscriberBucketName).Put([]byte(addr), []byte{})
+	})
+}
+
+// Remove unsubscribes an address
+func (s *Store) Remove(addr string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(subscriberBucketName).Delete([]byte(addr))
+	})
+}
+
+// List returns all subscribed addresses
+func (s *Store) List() []string {
+	addrs := []string{}
+	_ = s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(subscriberBucketName).ForEach(func(k, _ []byte) error {
+			addrs = append(addrs, string(k))
+			return nil
+		})
+	})
+	return addrs
+}
+
+// Close closes the underlying database
+func (s *Store) Close() {
+	_ = s.db.Close()
+}
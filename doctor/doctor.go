@@ -0,0 +1,277 @@
+// Package doctor checks the configuration and environment of a node and reports everything likely to keep it from running
+package doctor
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/u-speak/core/cluster"
+	"github.com/u-speak/core/config"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/datastore"
+	"github.com/u-speak/core/util"
+)
+
+const (
+	// MaxSkew is the largest clock difference to the time server which is not reported
+	MaxSkew = 30 * time.Second
+	// CertWarning is how long before the expiry of the certificate a warning is reported
+	CertWarning = 14 * 24 * time.Hour
+	// Timeout is the default timeout of network checks
+	Timeout = 5 * time.Second
+)
+
+// Status is the outcome of a single check
+type Status string
+
+// Outcomes of checks. Failures keep the node from working correctly
+const (
+	OK   Status = "ok"
+	Warn Status = "warn"
+	Fail Status = "fail"
+)
+
+// Result of a single check
+type Result struct {
+	Check   string `json:"check"`
+	Status  Status `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// Report lists the results of all checks. It is healthy if no check failed
+type Report struct {
+	Healthy bool     `json:"healthy"`
+	Results []Result `json:"results"`
+}
+
+// Options select the checks to run
+type Options struct {
+	// Running skips the port checks, since a running node holds its ports itself
+	Running bool
+	// Tangle is checked for tips if set
+	Tangle *tangle.Tangle
+	// TimeServer is queried for the Date header, https:// and the discovery domain if empty
+	TimeServer string
+	Timeout    time.Duration
+}
+
+// Run checks the configuration and environment
+func Run(c config.Configuration, o Options) *Report {
+	if o.Timeout == 0 {
+		o.Timeout = Timeout
+	}
+	if o.TimeServer == "" {
+		o.TimeServer = "https://" + c.Global.DNS
+	}
+	r := &Report{Healthy: true}
+	r.add(checkConfig(c)...)
+	r.add(checkStores(c)...)
+	if o.Tangle != nil {
+		r.add(checkTangle(o.Tangle))
+	}
+	r.add(checkCertificate(c.Global.SSLCert, c.Global.SSLKey, time.Now()))
+	if !o.Running {
+		r.add(checkPorts(c)...)
+	}
+	r.add(checkClock(o.TimeServer, o.Timeout))
+	r.add(checkPeers(c, o.Timeout)...)
+	return r
+}
+
+func (r *Report) add(rs ...Result) {
+	for _, res := range rs {
+		if res.Status == Fail {
+			r.Healthy = false
+		}
+		r.Results = append(r.Results, res)
+	}
+}
+
+// listener is the address a server listens on
+type listener struct {
+	name string
+	addr string
+}
+
+// listeners returns the addresses of all enabled servers
+func listeners(c config.Configuration) []listener {
+	l := []listener{
+		{"node", c.NodeNetwork.Interface + ":" + strconv.Itoa(c.NodeNetwork.Port)},
+		{"api", c.Web.API.Interface + ":" + strconv.Itoa(c.Web.API.Port)},
+		{"diagnostics", c.Diagnostics.Interface + ":" + strconv.Itoa(c.Diagnostics.Port)},
+		{"static", c.Web.Static.Interface + ":" + strconv.Itoa(c.Web.Static.Port)},
+	}
+	if c.Web.MinUI.Enabled {
+		l = append(l, listener{"minui", c.Web.MinUI.Interface + ":" + strconv.Itoa(c.Web.MinUI.Port)})
+	}
+	return l
+}
+
+func checkConfig(c config.Configuration) []Result {
+	rs := []Result{}
+	problem := func(s Status, msg string, args ...interface{}) {
+		rs = append(rs, Result{Check: "config", Status: s, Message: fmt.Sprintf(msg, args...)})
+	}
+	if c.Storage.Durability != "full" && c.Storage.Durability != "relaxed" {
+		problem(Fail, "Unknown durability %s", c.Storage.Durability)
+	}
+	if c.Storage.Compression != datastore.None && c.Storage.Compression != datastore.Zstd {
+		problem(Fail, "Unknown compression %s", c.Storage.Compression)
+	}
+	switch c.Cluster.Role {
+	case "", cluster.Standalone, cluster.Writer:
+	case cluster.Frontend, cluster.Replica:
+		if c.Cluster.Writer == "" {
+			problem(Fail, "Cluster role %s requires a writer", c.Cluster.Role)
+		}
+	default:
+		problem(Fail, "Unknown cluster role %s", c.Cluster.Role)
+	}
+	switch c.Flags.Classifier {
+	case "heuristic", "none":
+	case "webhook":
+		if c.Flags.Webhook == "" {
+			problem(Fail, "The webhook classifier requires a webhook URL")
+		}
+	default:
+		problem(Fail, "Unknown classifier %s", c.Flags.Classifier)
+	}
+	if c.Web.API.AdminEnabled && c.Web.API.AdminPassword == "admin" {
+		problem(Warn, "The admin API uses the default password")
+	}
+	seen := map[string]string{}
+	for _, l := range listeners(c) {
+		if other, ok := seen[l.addr]; ok {
+			problem(Fail, "The %s and %s servers both listen on %s", other, l.name, l.addr)
+		}
+		seen[l.addr] = l.name
+	}
+	if len(rs) == 0 {
+		rs = append(rs, Result{Check: "config", Status: OK})
+	}
+	return rs
+}
+
+func checkStores(c config.Configuration) []Result {
+	paths := []string{c.Storage.DataPath, c.Storage.TanglePath, c.Storage.CheckpointPath, c.Storage.IdentityPath, c.Storage.RecentPath}
+	seen := map[string]bool{}
+	rs := []Result{}
+	for _, p := range paths {
+		d := filepath.Dir(p)
+		if p == "" || seen[d] {
+			continue
+		}
+		seen[d] = true
+		res := Result{Check: "store " + d, Status: OK}
+		f, err := ioutil.TempFile(d, ".doctor")
+		if err != nil {
+			res.Status, res.Message = Fail, err.Error()
+			rs = append(rs, res)
+			continue
+		}
+		f.Close()
+		os.Remove(f.Name())
+		free, _, err := util.DiskUsage(d)
+		if err == nil && free < c.Storage.MinFree*1024*1024 {
+			res.Status, res.Message = Warn, fmt.Sprintf("Only %d MiB left", free/1024/1024)
+		}
+		rs = append(rs, res)
+	}
+	return rs
+}
+
+func checkTangle(t *tangle.Tangle) Result {
+	if len(t.Tips()) == 0 {
+		return Result{Check: "tangle", Status: Fail, Message: "The tangle has no tips"}
+	}
+	return Result{Check: "tangle", Status: OK, Message: fmt.Sprintf("%d sites", t.Size())}
+}
+
+func checkCertificate(cert, key string, now time.Time) Result {
+	pair, err := tls.LoadX509KeyPair(cert, key)
+	if err != nil {
+		return Result{Check: "certificate", Status: Fail, Message: err.Error()}
+	}
+	leaf, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return Result{Check: "certificate", Status: Fail, Message: err.Error()}
+	}
+	switch {
+	case now.After(leaf.NotAfter):
+		return Result{Check: "certificate", Status: Fail, Message: "Expired at " + leaf.NotAfter.Format(time.RFC3339)}
+	case now.Before(leaf.NotBefore):
+		return Result{Check: "certificate", Status: Fail, Message: "Not valid before " + leaf.NotBefore.Format(time.RFC3339)}
+	case leaf.NotAfter.Sub(now) < CertWarning:
+		return Result{Check: "certificate", Status: Warn, Message: "Expires at " + leaf.NotAfter.Format(time.RFC3339)}
+	}
+	return Result{Check: "certificate", Status: OK, Message: "Valid until " + leaf.NotAfter.Format(time.RFC3339)}
+}
+
+func checkPorts(c config.Configuration) []Result {
+	rs := []Result{}
+	for _, l := range listeners(c) {
+		res := Result{Check: "port " + l.name, Status: OK, Message: l.addr}
+		ln, err := net.Listen("tcp", l.addr)
+		if err != nil {
+			res.Status, res.Message = Fail, err.Error()
+		} else {
+			ln.Close()
+		}
+		rs = append(rs, res)
+	}
+	return rs
+}
+
+// checkClock compares the local time with the Date header of the time server
+func checkClock(server string, timeout time.Duration) Result {
+	cl := &http.Client{Timeout: timeout}
+	start := time.Now()
+	resp, err := cl.Head(server)
+	if err != nil {
+		return Result{Check: "clock", Status: Warn, Message: "Could not reach time server: " + err.Error()}
+	}
+	resp.Body.Close()
+	remote, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return Result{Check: "clock", Status: Warn, Message: "Time server sent no valid date"}
+	}
+	// The Date header is truncated to seconds and sent some time during the request
+	local := start.Add(time.Since(start) / 2).Truncate(time.Second)
+	skew := local.Sub(remote)
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > MaxSkew {
+		return Result{Check: "clock", Status: Warn, Message: "Clock is off by " + skew.String()}
+	}
+	return Result{Check: "clock", Status: OK}
+}
+
+// checkPeers dials the bootstrap peers. The check fails if none of them is reachable
+func checkPeers(c config.Configuration, timeout time.Duration) []Result {
+	rs := []Result{}
+	reachable := 0
+	for _, p := range c.NodeNetwork.Bootstrap {
+		res := Result{Check: "peer " + p.Address, Status: OK}
+		conn, err := net.DialTimeout("tcp", p.Address, timeout)
+		if err != nil {
+			res.Status, res.Message = Warn, err.Error()
+		} else {
+			conn.Close()
+			reachable++
+		}
+		rs = append(rs, res)
+	}
+	if len(c.NodeNetwork.Bootstrap) > 0 && reachable == 0 {
+		rs = append(rs, Result{Check: "peers", Status: Fail, Message: "No bootstrap peer is reachable"})
+	}
+	return rs
+}
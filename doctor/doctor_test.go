@@ -0,0 +1,107 @@
+package doctor
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/config"
+)
+
+func testConfig(dir string) config.Configuration {
+	c := config.Configuration{}
+	c.Storage.Durability = "full"
+	c.Storage.Compression = "none"
+	c.Storage.DataPath = path.Join(dir, "data.db")
+	c.Storage.TanglePath = path.Join(dir, "tangle.db")
+	c.Flags.Classifier = "heuristic"
+	c.NodeNetwork.Port = 6969
+	c.Web.API.Port = 3000
+	c.Diagnostics.Port = 1337
+	c.Web.Static.Port = 4000
+	return c
+}
+
+func TestConfig(t *testing.T) {
+	c := testConfig("/tmp")
+	assert.Equal(t, []Result{{Check: "config", Status: OK}}, checkConfig(c))
+	c.Storage.Durability = "sometimes"
+	c.Cluster.Role = "replica"
+	c.Web.API.Port = 6969
+	c.Web.API.AdminEnabled = true
+	c.Web.API.AdminPassword = "admin"
+	rs := checkConfig(c)
+	assert.Len(t, rs, 4)
+	assert.Equal(t, Warn, rs[2].Status)
+	assert.Equal(t, "The node and api servers both listen on :6969", rs[3].Message)
+}
+
+func TestStores(t *testing.T) {
+	dir, err := ioutil.TempDir("", "doctor")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	c := testConfig(dir)
+	c.Storage.RecentPath = "/nonexistent/recent.bin"
+	rs := checkStores(c)
+	assert.Len(t, rs, 2)
+	assert.Equal(t, OK, rs[0].Status)
+	assert.Equal(t, Fail, rs[1].Status)
+}
+
+func TestCertificate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "doctor")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	now := time.Now()
+	tmpl := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "node"}, NotBefore: now.Add(-time.Hour), NotAfter: now.Add(30 * 24 * time.Hour)}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.NoError(t, err)
+	kb, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+	cert, priv := path.Join(dir, "cert.pem"), path.Join(dir, "key.pem")
+	assert.NoError(t, ioutil.WriteFile(cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assert.NoError(t, ioutil.WriteFile(priv, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: kb}), 0600))
+
+	assert.Equal(t, OK, checkCertificate(cert, priv, now).Status)
+	assert.Equal(t, Warn, checkCertificate(cert, priv, now.Add(20*24*time.Hour)).Status)
+	assert.Equal(t, Fail, checkCertificate(cert, priv, now.Add(40*24*time.Hour)).Status)
+	assert.Equal(t, Fail, checkCertificate(path.Join(dir, "missing"), priv, now).Status)
+}
+
+func TestClock(t *testing.T) {
+	offset := time.Duration(0)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", time.Now().Add(offset).UTC().Format(http.TimeFormat))
+	}))
+	defer s.Close()
+	assert.Equal(t, OK, checkClock(s.URL, time.Second).Status)
+	offset = time.Hour
+	assert.Equal(t, Warn, checkClock(s.URL, time.Second).Status)
+}
+
+func TestPeers(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	c := config.Configuration{}
+	c.NodeNetwork.Bootstrap = []config.Peer{{Address: l.Addr().String()}}
+	assert.Equal(t, []Result{{Check: "peer " + l.Addr().String(), Status: OK}}, checkPeers(c, time.Second))
+	l.Close()
+	rs := checkPeers(c, time.Second)
+	assert.Len(t, rs, 2)
+	assert.Equal(t, Fail, rs[1].Status)
+}
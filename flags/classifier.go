@@ -0,0 +1,88 @@
+package flags
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/pow"
+	"github.com/u-speak/core/tangle"
+)
+
+// NSFW marks content which is not safe for work
+const NSFW = "nsfw"
+
+// Classifier assigns content flags to a site
+type Classifier interface {
+	Classify(o *tangle.Object) ([]string, error)
+}
+
+// nsfwTags are hashtags authors use to mark their posts
+var nsfwTags = map[string]bool{"nsfw": true, "nsfl": true, "porn": true, "gore": true}
+
+// Heuristic flags posts tagged as nsfw by their authors. Images are not classified
+type Heuristic struct{}
+
+// Classify implements Classifier
+func (Heuristic) Classify(o *tangle.Object) ([]string, error) {
+	p, ok := o.Data.(*post.Post)
+	if !ok || o.Site.Type != "post" {
+		return nil, nil
+	}
+	for _, t := range p.Tags() {
+		if nsfwTags[t] {
+			return []string{NSFW}, nil
+		}
+	}
+	return nil, nil
+}
+
+// Webhook sends the verification bundle of a site to an external classifier,
+// which has to respond with {"flags": [...]}
+type Webhook struct {
+	URL    string
+	pow    pow.Algorithm
+	client *http.Client
+}
+
+// NewWebhook returns a classifier calling u with bundles weighed by the proof of work algorithm a
+func NewWebhook(u string, a pow.Algorithm, timeout time.Duration) *Webhook {
+	return &Webhook{URL: u, pow: a, client: &http.Client{Timeout: timeout}}
+}
+
+// Classify implements Classifier
+func (w *Webhook) Classify(o *tangle.Object) ([]string, error) {
+	b, err := o.Bundle(w.pow)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := w.client.Post(w.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Classifier returned %s", resp.Status)
+	}
+	r := struct {
+		Flags []string `json:"flags"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+	fs := []string{}
+	for _, f := range r.Flags {
+		if f = strings.ToLower(strings.TrimSpace(f)); f != "" {
+			fs = append(fs, f)
+		}
+	}
+	return fs, nil
+}
@@ -0,0 +1,114 @@
+package flags
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/coreos/bbolt"
+	"github.com/u-speak/core/migrate"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// migrations upgrade the flag database to the current format, see migrate.Open
+var migrations = []migrate.Migration{migrate.None}
+
+var flagBucketName = []byte("flags")
+
+// Store classifies accepted images and posts and keeps their flags.
+// Sites are classified one at a time by a background worker
+type Store struct {
+	classifier Classifier
+	db         *bolt.DB
+	jobs       chan *tangle.Object
+	done       chan struct{}
+	stopped    chan struct{}
+}
+
+// New returns a store classifying sites with c, keeping the flags at path. Up to queue sites wait for their classification
+func New(c Classifier, path string, queue int) (*Store, error) {
+	db, err := migrate.Open(path, 0644, migrations)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(flagBucketName)
+		return err
+	})
+	s := &Store{classifier: c, db: db, jobs: make(chan *tangle.Object, queue), done: make(chan struct{}), stopped: make(chan struct{})}
+	go s.work()
+	return s, err
+}
+
+// Add queues the site for classification without blocking. Sites are left unclassified if the queue is full
+func (s *Store) Add(o *tangle.Object) {
+	if o.Site.Type != "post" && o.Site.Type != "image" {
+		return
+	}
+	select {
+	case s.jobs <- o:
+	default:
+		log.Warnf("Flag queue is full, not classifying site %s", o.Site.Hash())
+	}
+}
+
+func (s *Store) work() {
+	defer close(s.stopped)
+	for {
+		select {
+		case o := <-s.jobs:
+			if err := s.Classify(o); err != nil {
+				log.Errorf("Could not classify site %s: %s", o.Site.Hash(), err)
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Classify runs the classifier on the site and stores the resulting flags
+func (s *Store) Classify(o *tangle.Object) error {
+	fs, err := s.classifier.Classify(o)
+	if err != nil {
+		return err
+	}
+	return s.Set(o.Site.Hash(), fs)
+}
+
+// Set replaces the flags of a site
+func (s *Store) Set(h hash.Hash, fs []string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(flagBucketName)
+		if len(fs) == 0 {
+			return b.Delete(h.Slice())
+		}
+		fs = append([]string{}, fs...)
+		sort.Strings(fs)
+		v, err := json.Marshal(fs)
+		if err != nil {
+			return err
+		}
+		return b.Put(h.Slice(), v)
+	})
+}
+
+// Get returns the flags of a site
+func (s *Store) Get(h hash.Hash) []string {
+	fs := []string{}
+	s.db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(flagBucketName).Get(h.Slice()); v != nil {
+			return json.Unmarshal(v, &fs)
+		}
+		return nil
+	})
+	return fs
+}
+
+// Close stops the worker and closes the flag database
+func (s *Store) Close() {
+	close(s.done)
+	<-s.stopped
+	_ = s.db.Close()
+}
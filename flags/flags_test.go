@@ -0,0 +1,51 @@
+package flags
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/img"
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/pow"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/site"
+)
+
+func TestHeuristic(t *testing.T) {
+	fs, err := Heuristic{}.Classify(&tangle.Object{Site: &site.Site{Type: "post"}, Data: &post.Post{Content: "look #NSFW"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{NSFW}, fs)
+	fs, _ = Heuristic{}.Classify(&tangle.Object{Site: &site.Site{Type: "post"}, Data: &post.Post{Content: "#cats"}})
+	assert.Empty(t, fs)
+}
+
+func TestStore(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		json.NewEncoder(w).Encode(map[string][]string{"flags": {" Violence", "NSFW", ""}})
+	}))
+	defer srv.Close()
+	p := path.Join(os.TempDir(), "testFlags.db")
+	defer os.Remove(p)
+	s, err := New(NewWebhook(srv.URL, pow.Default, 0), p, 1)
+	assert.NoError(t, err)
+	defer s.Close()
+
+	i := &img.Image{Raw: []byte("image")}
+	c, _ := i.Hash()
+	o := &tangle.Object{Site: &site.Site{Content: c, Type: "image"}, Data: i}
+	assert.Empty(t, s.Get(o.Site.Hash()))
+	assert.NoError(t, s.Classify(o))
+	assert.Equal(t, []string{NSFW, "violence"}, s.Get(o.Site.Hash()))
+	assert.NoError(t, s.Set(o.Site.Hash(), nil))
+	assert.Empty(t, s.Get(o.Site.Hash()))
+
+	s.Add(o)
+	assert.Eventually(t, func() bool { return len(s.Get(o.Site.Hash())) == 2 }, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,33 @@
+package follow
+
+import (
+	"strings"
+
+	"github.com/u-speak/core/post"
+)
+
+// List is a signed list of followed key fingerprints and #tags, one entry per line
+type List struct {
+	post.Post
+}
+
+// Type implements tangle/datastore.serializable
+func (l *List) Type() string {
+	return "follow"
+}
+
+// Entries splits the list into followed fingerprints and tags
+func (l *List) Entries() (authors []string, tags []string) {
+	authors, tags = []string{}, []string{}
+	for _, e := range strings.Split(l.Content, "\n") {
+		e = strings.ToLower(strings.TrimSpace(e))
+		switch {
+		case e == "":
+		case strings.HasPrefix(e, "#"):
+			tags = append(tags, e[1:])
+		default:
+			authors = append(authors, strings.Replace(e, " ", "", -1))
+		}
+	}
+	return authors, tags
+}
@@ -0,0 +1,15 @@
+package follow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/post"
+)
+
+func TestEntries(t *testing.T) {
+	l := &List{Post: post.Post{Content: "ABCD EF01\n\n#Golang\n1234\n"}}
+	a, tags := l.Entries()
+	assert.Equal(t, []string{"abcdef01", "1234"}, a)
+	assert.Equal(t, []string{"golang"}, tags)
+}
@@ -0,0 +1,152 @@
+// Package genesis creates and loads the genesis sites of a network.
+// Nodes started with the same genesis file share the same two initial tips and can merge their tangles
+package genesis
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"strconv"
+	"time"
+
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/pow"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+	"github.com/u-speak/core/util"
+)
+
+var (
+	// ErrNoNetwork is returned when the network has no name
+	ErrNoNetwork = errors.New("The network needs a name")
+	// ErrNoFounders is returned when a network is created without founder keys
+	ErrNoFounders = errors.New("At least one founder key is required")
+	// ErrInvalidFile is returned when the nonces of a genesis file do not reach its weight
+	ErrInvalidFile = errors.New("Invalid genesis file")
+)
+
+// Founder is a key taking part in the creation of the network
+type Founder struct {
+	Fingerprint string `json:"fingerprint"`
+	Key         string `json:"key"`
+}
+
+// File describes a network and the nonces of its genesis sites
+type File struct {
+	Network  string    `json:"network"`
+	Created  int64     `json:"created"`
+	Founders []Founder `json:"founders"`
+	Weight   int       `json:"weight"`
+	Nonces   [2]uint64 `json:"nonces"`
+	// PoW is the proof of work algorithm of the network, the hash weight if empty
+	PoW string `json:"pow,omitempty"`
+}
+
+// Create mines the genesis sites for a new network founded by the armored public keys.
+// New sites of the network have to provide their proof of work with the named algorithm
+func Create(network string, created time.Time, keys []string, weight int, algorithm string) (*File, error) {
+	if network == "" {
+		return nil, ErrNoNetwork
+	}
+	if len(keys) == 0 {
+		return nil, ErrNoFounders
+	}
+	a, err := pow.Get(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	f := &File{Network: network, Created: created.Unix(), Weight: weight, PoW: algorithm}
+	for _, k := range keys {
+		p := &post.Post{PubkeyStr: k}
+		if err := p.ReInit(); err != nil {
+			return nil, err
+		}
+		a, err := p.ArmoredPubkey()
+		if err != nil {
+			return nil, err
+		}
+		f.Founders = append(f.Founders, Founder{Fingerprint: p.Fingerprint(), Key: a})
+	}
+	for i, s := range f.sites() {
+		pow.Mine(a, s, weight)
+		f.Nonces[i] = s.Nonce
+	}
+	return f, nil
+}
+
+// Load reads and verifies a genesis file
+func Load(path string) (*File, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f := &File{}
+	if err := json.Unmarshal(b, f); err != nil {
+		return nil, err
+	}
+	return f, f.Verify()
+}
+
+// Save writes the genesis file to path
+func (f *File) Save(path string) error {
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return util.WriteFile(path, b, 0644)
+}
+
+// Verify checks that the founder keys match their fingerprints and the sites reach the weight of the file
+func (f *File) Verify() error {
+	if f.Network == "" {
+		return ErrNoNetwork
+	}
+	if len(f.Founders) == 0 {
+		return ErrNoFounders
+	}
+	for _, fd := range f.Founders {
+		p := &post.Post{PubkeyStr: fd.Key}
+		if err := p.ReInit(); err != nil {
+			return err
+		}
+		if p.Fingerprint() != fd.Fingerprint {
+			return ErrInvalidFile
+		}
+	}
+	a, err := f.Algorithm()
+	if err != nil {
+		return err
+	}
+	for _, s := range f.Sites() {
+		if pow.Weight(a, s) < f.Weight {
+			return ErrInvalidFile
+		}
+	}
+	return nil
+}
+
+// Algorithm returns the proof of work algorithm of the network
+func (f *File) Algorithm() (pow.Algorithm, error) {
+	return pow.Get(f.PoW)
+}
+
+// Sites returns the genesis sites of the network
+func (f *File) Sites() []*site.Site {
+	ss := f.sites()
+	for i, s := range ss {
+		s.Nonce = f.Nonces[i]
+	}
+	return ss
+}
+
+// sites returns the genesis sites without nonces. Their content commits to the description of the network
+func (f *File) sites() []*site.Site {
+	d := "N" + f.Network + "C" + strconv.FormatInt(f.Created, 10)
+	for _, fd := range f.Founders {
+		d += "F" + fd.Fingerprint
+	}
+	return []*site.Site{
+		{Content: hash.New([]byte(d + "I0")), Type: "genesis"},
+		{Content: hash.New([]byte(d + "I1")), Type: "genesis"},
+	}
+}
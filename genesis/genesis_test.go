@@ -0,0 +1,68 @@
+package genesis
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/pow"
+
+	"golang.org/x/crypto/openpgp"
+)
+
+func TestCreate(t *testing.T) {
+	founder, err := openpgp.NewEntity("Founder", "", "founder@example.com", nil)
+	assert.NoError(t, err)
+	p := &post.Post{Pubkey: founder}
+	key, err := p.ArmoredPubkey()
+	assert.NoError(t, err)
+
+	_, err = Create("", time.Now(), []string{key}, 1, "")
+	assert.Equal(t, ErrNoNetwork, err)
+	_, err = Create("testnet", time.Now(), nil, 1, "")
+	assert.Equal(t, ErrNoFounders, err)
+	_, err = Create("testnet", time.Now(), []string{"broken"}, 1, "")
+	assert.Error(t, err)
+
+	f, err := Create("testnet", time.Unix(1500000000, 0), []string{key}, 1, "")
+	assert.NoError(t, err)
+	assert.Equal(t, p.Fingerprint(), f.Founders[0].Fingerprint)
+	ss := f.Sites()
+	assert.Len(t, ss, 2)
+	assert.NotEqual(t, ss[0].Hash(), ss[1].Hash())
+	for _, s := range ss {
+		assert.Equal(t, "genesis", s.Type)
+		assert.True(t, s.Hash().Weight() >= 1)
+	}
+
+	other, err := Create("othernet", time.Unix(1500000000, 0), []string{key}, 1, "")
+	assert.NoError(t, err)
+	assert.NotEqual(t, ss[0].Hash(), other.Sites()[0].Hash())
+
+	path := "/tmp/testGenesis.json"
+	defer os.Remove(path)
+	assert.NoError(t, f.Save(path))
+	l, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, f, l)
+	assert.Equal(t, ss[0].Hash(), l.Sites()[0].Hash())
+
+	l.Nonces[0]++
+	for l.Sites()[0].Hash().Weight() >= 1 {
+		l.Nonces[0]++
+	}
+	assert.Equal(t, ErrInvalidFile, l.Verify())
+	l = &File{}
+	*l = *f
+	l.Founders = []Founder{{Fingerprint: "abc", Key: key}}
+	assert.Equal(t, ErrInvalidFile, l.Verify())
+	l = &File{}
+	*l = *f
+	l.PoW = "sha1"
+	assert.Equal(t, pow.ErrUnknownAlgorithm, l.Verify())
+
+	_, err = Create("testnet", time.Now(), []string{key}, 1, "sha1")
+	assert.Equal(t, pow.ErrUnknownAlgorithm, err)
+}
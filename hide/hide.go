@@ -0,0 +1,344 @@
+// Package hide keeps the sites the operator hides on this node. Unlike tombstones, hiding is not published to the
+// network and does not remove anything: hidden sites stay in the tangle and can be shown again
+package hide
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/bbolt"
+	"github.com/u-speak/core/migrate"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/validate"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// migrations upgrade the hide database to the current format, see migrate.Open
+var migrations = []migrate.Migration{migrate.None}
+
+// Actions recorded in the audit log
+const (
+	ActionHide   = "hide"
+	ActionUnhide = "unhide"
+	ActionExpire = "expire"
+)
+
+// MaxAudit is the amount of audit events kept, older events are dropped
+const MaxAudit = 10000
+
+var (
+	entryBucketName = []byte("hidden")
+	auditBucketName = []byte("audit")
+	// ErrNotHidden is returned when showing a site which is not hidden
+	ErrNotHidden = errors.New("Site is not hidden")
+)
+
+// Entry is a hidden site. Sites with a zero Expires stay hidden until they are shown again
+type Entry struct {
+	Hash    hash.Hash `json:"hash"`
+	Reason  string    `json:"reason,omitempty"`
+	Created time.Time `json:"created"`
+	Expires time.Time `json:"expires,omitempty"`
+}
+
+// Expired reports whether the entry no longer hides the site
+func (e Entry) Expired(now time.Time) bool {
+	return !e.Expires.IsZero() && !now.Before(e.Expires)
+}
+
+// Event is a change of the hide list recorded in the audit log. Actor is the operator who made it,
+// empty for entries which expired
+type Event struct {
+	Seq     uint64    `json:"seq"`
+	Time    time.Time `json:"time"`
+	Action  string    `json:"action"`
+	Hash    hash.Hash `json:"hash"`
+	Actor   string    `json:"actor,omitempty"`
+	Reason  string    `json:"reason,omitempty"`
+	Expires time.Time `json:"expires,omitempty"`
+}
+
+// Store keeps the hidden sites in memory for lookups and persists them with the audit log
+type Store struct {
+	db      *bolt.DB
+	entries map[hash.Hash]Entry
+	// content maps hidden sites to their content hash once it is known, byContent is its reverse.
+	// Sites can be hidden before they arrive, so their content is recorded by Resolve
+	content   map[hash.Hash]hash.Hash
+	byContent map[hash.Hash]map[hash.Hash]bool
+	lock      sync.RWMutex
+}
+
+// New opens the hide database at path
+func New(path string) (*Store, error) {
+	db, err := migrate.Open(path, 0644, migrations)
+	if err != nil {
+		return nil, err
+	}
+	s := &Store{db: db, entries: make(map[hash.Hash]Entry), content: make(map[hash.Hash]hash.Hash), byContent: make(map[hash.Hash]map[hash.Hash]bool)}
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(auditBucketName); err != nil {
+			return err
+		}
+		b, err := tx.CreateBucketIfNotExists(entryBucketName)
+		if err != nil {
+			return err
+		}
+		return b.ForEach(func(k, v []byte) error {
+			e := Entry{}
+			if err := json.Unmarshal(v, &e); err != nil {
+				return err
+			}
+			s.entries[e.Hash] = e
+			return nil
+		})
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Hide adds the entries to the list in a single transaction, replacing the entries of sites which are hidden already
+func (s *Store) Hide(es []Entry, actor string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := time.Now()
+	for i := range es {
+		if es[i].Created.IsZero() {
+			es[i].Created = now
+		}
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(entryBucketName)
+		for _, e := range es {
+			v, err := json.Marshal(e)
+			if err != nil {
+				return err
+			}
+			if err := b.Put(e.Hash.Slice(), v); err != nil {
+				return err
+			}
+			err = audit(tx, Event{Time: now, Action: ActionHide, Hash: e.Hash, Actor: actor, Reason: e.Reason, Expires: e.Expires})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, e := range es {
+		s.entries[e.Hash] = e
+		log.WithFields(log.Fields{"actor": actor, "reason": e.Reason}).Infof("Hid site %s", e.Hash)
+	}
+	return nil
+}
+
+// Unhide shows the site again
+func (s *Store) Unhide(h hash.Hash, actor string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.entries[h]; !ok {
+		return ErrNotHidden
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(entryBucketName).Delete(h.Slice()); err != nil {
+			return err
+		}
+		return audit(tx, Event{Time: time.Now(), Action: ActionUnhide, Hash: h, Actor: actor})
+	})
+	if err != nil {
+		return err
+	}
+	s.remove(h)
+	log.WithField("actor", actor).Infof("Showing site %s again", h)
+	return nil
+}
+
+// Hidden reports whether the site is hidden at the given time
+func (s *Store) Hidden(h hash.Hash, now time.Time) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	e, ok := s.entries[h]
+	return ok && !e.Expired(now)
+}
+
+// Resolve records the content hash of the site if it is hidden, so HiddenContent also hides its payload
+func (s *Store) Resolve(h, content hash.Hash) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.entries[h]; !ok {
+		return
+	}
+	if _, ok := s.content[h]; ok {
+		return
+	}
+	s.content[h] = content
+	if s.byContent[content] == nil {
+		s.byContent[content] = make(map[hash.Hash]bool)
+	}
+	s.byContent[content][h] = true
+}
+
+// HiddenContent reports whether a site with the content is hidden at the given time.
+// Only sites whose content was recorded with Resolve are considered
+func (s *Store) HiddenContent(content hash.Hash, now time.Time) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	for h := range s.byContent[content] {
+		if !s.entries[h].Expired(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// remove forgets the entry of the site, the caller has to hold the lock
+func (s *Store) remove(h hash.Hash) {
+	delete(s.entries, h)
+	c, ok := s.content[h]
+	if !ok {
+		return
+	}
+	delete(s.content, h)
+	delete(s.byContent[c], h)
+	if len(s.byContent[c]) == 0 {
+		delete(s.byContent, c)
+	}
+}
+
+// Get returns the entry of a hidden site
+func (s *Store) Get(h hash.Hash) (Entry, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	e, ok := s.entries[h]
+	return e, ok
+}
+
+// List returns all entries, oldest first
+func (s *Store) List() []Entry {
+	s.lock.RLock()
+	es := make([]Entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		es = append(es, e)
+	}
+	s.lock.RUnlock()
+	sort.Slice(es, func(i, j int) bool { return es[i].Created.Before(es[j].Created) })
+	return es
+}
+
+// Len returns the amount of hidden sites
+func (s *Store) Len() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return len(s.entries)
+}
+
+// Expire removes the entries which expired before now and returns how many there were
+func (s *Store) Expire(now time.Time) (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	expired := []hash.Hash{}
+	for h, e := range s.entries {
+		if e.Expired(now) {
+			expired = append(expired, h)
+		}
+	}
+	if len(expired) == 0 {
+		return 0, nil
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		for _, h := range expired {
+			if err := tx.Bucket(entryBucketName).Delete(h.Slice()); err != nil {
+				return err
+			}
+			if err := audit(tx, Event{Time: now, Action: ActionExpire, Hash: h}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, h := range expired {
+		s.remove(h)
+	}
+	return len(expired), nil
+}
+
+// Audit returns up to limit audit events, newest first
+func (s *Store) Audit(limit int) ([]Event, error) {
+	es := []Event{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(auditBucketName).Cursor()
+		for k, v := c.Last(); k != nil && len(es) < limit; k, v = c.Prev() {
+			e := Event{}
+			if err := json.Unmarshal(v, &e); err != nil {
+				return err
+			}
+			es = append(es, e)
+		}
+		return nil
+	})
+	return es, err
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// audit appends the event to the audit log, dropping the oldest event once MaxAudit is exceeded
+func audit(tx *bolt.Tx, e Event) error {
+	b := tx.Bucket(auditBucketName)
+	seq, err := b.NextSequence()
+	if err != nil {
+		return err
+	}
+	e.Seq = seq
+	v, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, seq)
+	if err := b.Put(k, v); err != nil {
+		return err
+	}
+	if seq <= MaxAudit {
+		return nil
+	}
+	binary.BigEndian.PutUint64(k, seq-MaxAudit)
+	return b.Delete(k)
+}
+
+// Parse reads a hash list with one site hash per line. Blank lines and lines starting with # are ignored
+func Parse(r io.Reader) ([]hash.Hash, error) {
+	hs := []hash.Hash{}
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		l := strings.TrimSpace(sc.Text())
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+		h, err := validate.Hash(l)
+		if err != nil {
+			return nil, fmt.Errorf("Line %d: %s", n, err)
+		}
+		hs = append(hs, h)
+	}
+	return hs, sc.Err()
+}
@@ -0,0 +1,92 @@
+package hide
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hide")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "hidden.db")
+	s, err := New(path)
+	assert.NoError(t, err)
+
+	now := time.Now()
+	a, b := hash.New([]byte("a")), hash.New([]byte("b"))
+	assert.NoError(t, s.Hide([]Entry{{Hash: a, Reason: "spam"}, {Hash: b, Expires: now.Add(time.Hour)}}, "admin"))
+	assert.True(t, s.Hidden(a, now))
+	assert.True(t, s.Hidden(b, now))
+	assert.False(t, s.Hidden(b, now.Add(2*time.Hour)))
+	assert.Equal(t, 2, s.Len())
+
+	n, err := s.Expire(now.Add(2 * time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, ErrNotHidden, s.Unhide(b, "admin"))
+
+	assert.NoError(t, s.Close())
+	s, err = New(path)
+	assert.NoError(t, err)
+	defer s.Close()
+	e, ok := s.Get(a)
+	assert.True(t, ok)
+	assert.Equal(t, "spam", e.Reason)
+	assert.NoError(t, s.Unhide(a, "admin"))
+	assert.False(t, s.Hidden(a, now))
+	assert.Empty(t, s.List())
+
+	es, err := s.Audit(10)
+	assert.NoError(t, err)
+	if assert.Len(t, es, 4) {
+		assert.Equal(t, ActionUnhide, es[0].Action)
+		assert.Equal(t, ActionExpire, es[1].Action)
+		assert.Equal(t, b, es[1].Hash)
+		assert.Equal(t, ActionHide, es[3].Action)
+		assert.Equal(t, "admin", es[3].Actor)
+	}
+}
+
+func TestHiddenContent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hide")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	s, err := New(filepath.Join(dir, "hidden.db"))
+	assert.NoError(t, err)
+	defer s.Close()
+
+	now := time.Now()
+	a, b, content := hash.New([]byte("a")), hash.New([]byte("b")), hash.New([]byte("content"))
+	s.Resolve(a, content)
+	assert.False(t, s.HiddenContent(content, now))
+	assert.NoError(t, s.Hide([]Entry{{Hash: a}, {Hash: b, Expires: now.Add(time.Hour)}}, "admin"))
+	assert.False(t, s.HiddenContent(content, now))
+	s.Resolve(a, content)
+	s.Resolve(b, content)
+	assert.True(t, s.HiddenContent(content, now))
+	assert.NoError(t, s.Unhide(a, "admin"))
+	assert.True(t, s.HiddenContent(content, now))
+	assert.False(t, s.HiddenContent(content, now.Add(2*time.Hour)))
+	_, err = s.Expire(now.Add(2 * time.Hour))
+	assert.NoError(t, err)
+	assert.False(t, s.HiddenContent(content, now))
+}
+
+func TestParse(t *testing.T) {
+	a, b := hash.New([]byte("a")), hash.New([]byte("b"))
+	hs, err := Parse(strings.NewReader("# takedown\n" + a.String() + "\n\n  " + b.String() + "\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []hash.Hash{a, b}, hs)
+	_, err = Parse(strings.NewReader(a.String() + "\nnope\n"))
+	if assert.Error(t, err) {
+		assert.True(t, strings.HasPrefix(err.Error(), "Line 2: "))
+	}
+}
@@ -0,0 +1,89 @@
+package hook
+
+import (
+	"sync"
+	"time"
+)
+
+// Breaker states
+const (
+	Closed   = "closed"
+	Open     = "open"
+	HalfOpen = "half-open"
+)
+
+// Breaker disables a hook after too many consecutive failures.
+// After the cooldown a single call is let through to probe whether the hook recovered
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	opened    time.Time
+	probing   bool
+	lock      sync.Mutex
+}
+
+// NewBreaker returns a closed breaker opening after threshold consecutive failures
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{threshold: threshold, cooldown: cooldown}
+}
+
+// Allow reports whether a call may be made
+func (b *Breaker) Allow() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	switch b.state() {
+	case Closed:
+		return true
+	case HalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	}
+	return false
+}
+
+// Success records a successful call and closes the breaker
+func (b *Breaker) Success() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.failures = 0
+	b.probing = false
+}
+
+// Failure records a failed call, opening the breaker once the threshold is reached
+func (b *Breaker) Failure() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.failures++
+	if b.probing || (b.threshold > 0 && b.failures >= b.threshold) {
+		b.opened = time.Now()
+	}
+	b.probing = false
+}
+
+// release gives back a probe that was allowed but never made
+func (b *Breaker) release() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.probing = false
+}
+
+// State returns the current state of the breaker
+func (b *Breaker) State() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.state()
+}
+
+func (b *Breaker) state() string {
+	if b.threshold <= 0 || b.failures < b.threshold {
+		return Closed
+	}
+	if time.Since(b.opened) < b.cooldown {
+		return Open
+	}
+	return HalfOpen
+}
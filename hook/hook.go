@@ -0,0 +1,119 @@
+package hook
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/u-speak/core/metrics"
+	"github.com/u-speak/core/pow"
+	"github.com/u-speak/core/tangle"
+)
+
+// Hook calls an external url for every site it is fired with.
+// Calls are made from a background worker, so a slow hook never delays the caller
+type Hook struct {
+	Name       string
+	URL        string
+	SendBundle bool
+	Breaker    *Breaker
+	pow        pow.Algorithm
+	pub        string
+	client     *http.Client
+	jobs       chan *tangle.Object
+}
+
+// New starts a hook calling u. pub is passed along as the public api endpoint of this node,
+// bundles are weighed with the proof of work algorithm a of the network
+func New(name, u, pub string, sendBundle bool, a pow.Algorithm, timeout time.Duration, queue int, breaker *Breaker) *Hook {
+	h := &Hook{
+		Name:       name,
+		URL:        u,
+		SendBundle: sendBundle,
+		Breaker:    breaker,
+		pow:        a,
+		pub:        pub,
+		client:     &http.Client{Timeout: timeout},
+		jobs:       make(chan *tangle.Object, queue),
+	}
+	go h.work()
+	return h
+}
+
+// Fire queues a call of the hook for the object. It reports false if the call was dropped
+func (h *Hook) Fire(o *tangle.Object) bool {
+	if !h.Breaker.Allow() {
+		metrics.HookDropped.WithLabelValues(h.Name, "open").Inc()
+		return false
+	}
+	select {
+	case h.jobs <- o:
+		return true
+	default:
+		h.Breaker.release()
+		metrics.HookDropped.WithLabelValues(h.Name, "queue").Inc()
+		return false
+	}
+}
+
+func (h *Hook) work() {
+	for o := range h.jobs {
+		start := time.Now()
+		err := h.call(o)
+		metrics.HookLatency.WithLabelValues(h.Name).Observe(time.Since(start).Seconds())
+		if err != nil {
+			metrics.HookFailures.WithLabelValues(h.Name).Inc()
+			h.Breaker.Failure()
+			log.Errorf("Error running %s hook: %s", h.Name, err)
+			if h.Breaker.State() == Open {
+				log.Warnf("Disabling %s hook after repeated failures", h.Name)
+			}
+			continue
+		}
+		h.Breaker.Success()
+	}
+}
+
+func (h *Hook) call(o *tangle.Object) error {
+	u, err := url.Parse(h.URL)
+	if err != nil {
+		return err
+	}
+	q := u.Query()
+	q.Add("hash", base64.URLEncoding.EncodeToString(o.Site.Hash().Slice()))
+	q.Add("pub", h.pub)
+	u.RawQuery = q.Encode()
+	log.Debugf("Calling %s hook with URL: %s", h.Name, u.String())
+	var resp *http.Response
+	if h.SendBundle {
+		resp, err = h.post(u.String(), o)
+	} else {
+		resp, err = h.client.Get(u.String())
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("Hook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// post sends the verification bundle of the object to the hook
+func (h *Hook) post(u string, o *tangle.Object) (*http.Response, error) {
+	b, err := o.Bundle(h.pow)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	return h.client.Post(u, "application/json", bytes.NewReader(body))
+}
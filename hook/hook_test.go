@@ -0,0 +1,54 @@
+package hook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/pow"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/site"
+)
+
+func TestBreaker(t *testing.T) {
+	b := NewBreaker(2, 20*time.Millisecond)
+	assert.True(t, b.Allow())
+	b.Failure()
+	assert.Equal(t, Closed, b.State())
+	b.Failure()
+	assert.Equal(t, Open, b.State())
+	assert.False(t, b.Allow())
+	time.Sleep(25 * time.Millisecond)
+	assert.Equal(t, HalfOpen, b.State())
+	assert.True(t, b.Allow())
+	assert.False(t, b.Allow())
+	b.Failure()
+	assert.Equal(t, Open, b.State())
+	time.Sleep(25 * time.Millisecond)
+	assert.True(t, b.Allow())
+	b.Success()
+	assert.Equal(t, Closed, b.State())
+	assert.True(t, b.Allow())
+}
+
+func TestHook(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		assert.NotEmpty(t, r.URL.Query().Get("hash"))
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	h := New("test", srv.URL, "pub", false, pow.Default, time.Second, 8, NewBreaker(2, time.Minute))
+	o := &tangle.Object{Site: &site.Site{Type: "dummy"}}
+	for i := 0; i < 2; i++ {
+		assert.True(t, h.Fire(o))
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.Equal(t, Open, h.Breaker.State())
+	assert.False(t, h.Fire(o))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,83 @@
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Default is the language used when none of the requested languages is available
+const Default = "en"
+
+// Catalog maps message keys to their translations by language
+type Catalog map[string]map[string]string
+
+// Message returns the translation of key, falling back to the default language and finally to the key itself
+func (c Catalog) Message(key, lang string) string {
+	ts, ok := c[key]
+	if !ok {
+		return key
+	}
+	if m, ok := ts[lang]; ok {
+		return m
+	}
+	if m, ok := ts[Default]; ok {
+		return m
+	}
+	return key
+}
+
+// Languages returns all languages with at least one translation
+func (c Catalog) Languages() []string {
+	seen := map[string]bool{}
+	ls := []string{}
+	for _, ts := range c {
+		for l := range ts {
+			if !seen[l] {
+				seen[l] = true
+				ls = append(ls, l)
+			}
+		}
+	}
+	sort.Strings(ls)
+	return ls
+}
+
+// Translate returns all messages of the catalog in the language
+func (c Catalog) Translate(lang string) map[string]string {
+	ms := make(map[string]string, len(c))
+	for k := range c {
+		ms[k] = c.Message(k, lang)
+	}
+	return ms
+}
+
+// Negotiate picks the best language of the catalog for an Accept-Language header.
+// Regional variants match their base language
+func (c Catalog) Negotiate(accept string) string {
+	available := map[string]bool{}
+	for _, l := range c.Languages() {
+		available[l] = true
+	}
+	best, bestQ := Default, 0.0
+	for _, part := range strings.Split(accept, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		q := 1.0
+		for _, f := range fields[1:] {
+			f = strings.TrimSpace(f)
+			if strings.HasPrefix(f, "q=") {
+				if v, err := strconv.ParseFloat(f[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if !available[tag] {
+			tag = strings.SplitN(tag, "-", 2)[0]
+		}
+		if available[tag] && q > bestQ {
+			best, bestQ = tag, q
+		}
+	}
+	return best
+}
@@ -0,0 +1,28 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testCatalog = Catalog{
+	"not_found": {"en": "Not found", "de": "Nicht gefunden"},
+	"only_en":   {"en": "English only"},
+}
+
+func TestMessage(t *testing.T) {
+	assert.Equal(t, "Nicht gefunden", testCatalog.Message("not_found", "de"))
+	assert.Equal(t, "English only", testCatalog.Message("only_en", "de"))
+	assert.Equal(t, "unknown", testCatalog.Message("unknown", "de"))
+	assert.Equal(t, []string{"de", "en"}, testCatalog.Languages())
+	assert.Equal(t, map[string]string{"not_found": "Nicht gefunden", "only_en": "English only"}, testCatalog.Translate("de"))
+}
+
+func TestNegotiate(t *testing.T) {
+	assert.Equal(t, "de", testCatalog.Negotiate("de-AT,de;q=0.9,en;q=0.8"))
+	assert.Equal(t, "en", testCatalog.Negotiate("fr-FR, en;q=0.5, de;q=0.4"))
+	assert.Equal(t, "en", testCatalog.Negotiate("fr"))
+	assert.Equal(t, "en", testCatalog.Negotiate(""))
+	assert.Equal(t, "de", testCatalog.Negotiate("de;q=0.1"))
+}
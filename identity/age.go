@@ -0,0 +1,95 @@
+package identity
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io/ioutil"
+	"os"
+
+	"filippo.io/age"
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/u-speak/core/util"
+)
+
+// ageKeys returns the identity decrypting and the recipient encrypting the key file
+func ageKeys(o Options) (age.Identity, age.Recipient, error) {
+	if o.AgeIdentity == "" {
+		id, err := age.NewScryptIdentity(o.AgePassphrase)
+		if err != nil {
+			return nil, nil, err
+		}
+		r, err := age.NewScryptRecipient(o.AgePassphrase)
+		return id, r, err
+	}
+	f, err := os.Open(o.AgeIdentity)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	ids, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, id := range ids {
+		if x, ok := id.(*age.X25519Identity); ok {
+			return x, x.Recipient(), nil
+		}
+	}
+	return nil, nil, errors.New("No X25519 identity found in " + o.AgeIdentity)
+}
+
+// loadAge works like Load for key files encrypted with age.
+// Existing unencrypted key files are encrypted on first use
+func loadAge(o Options) (*Identity, error) {
+	id, r, err := ageKeys(o)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadFile(o.Path)
+	if os.IsNotExist(err) {
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeAge(o.Path, priv, r); err != nil {
+			return nil, err
+		}
+		return fromPrivate(priv)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// An age file is always larger than a bare key because of its header
+	if len(b) == ed25519.PrivateKeySize {
+		if err := writeAge(o.Path, b, r); err != nil {
+			return nil, err
+		}
+		return fromPrivate(b)
+	}
+	dec, err := age.Decrypt(bytes.NewReader(b), id)
+	if err != nil {
+		return nil, err
+	}
+	priv, err := ioutil.ReadAll(dec)
+	if err != nil {
+		return nil, err
+	}
+	return fromPrivate(priv)
+}
+
+func writeAge(path string, priv []byte, r age.Recipient) error {
+	buff := bytes.NewBuffer(nil)
+	w, err := age.Encrypt(buff, r)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(priv); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return util.WriteFile(path, buff.Bytes(), 0600)
+}
@@ -0,0 +1,114 @@
+package identity
+
+import (
+	"crypto"
+	"crypto/rand"
+	"errors"
+	"io/ioutil"
+	"os"
+
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/util"
+	"golang.org/x/crypto/ed25519"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrInvalidKey is returned when the stored key has the wrong size
+var ErrInvalidKey = errors.New("Invalid identity key")
+
+// Identity is the long-lived key pair of a node. Peers are identified by the hash of their public key, independent of their address
+type Identity struct {
+	public ed25519.PublicKey
+	signer crypto.Signer
+}
+
+// Options select where the private key is kept. Keys in a PKCS#11 token take precedence over key files encrypted with age
+type Options struct {
+	Path string
+	// AgeIdentity is the path of an age identity file the key file is encrypted to
+	AgeIdentity string
+	// AgePassphrase encrypts the key file with a passphrase if no AgeIdentity is set
+	AgePassphrase string
+	Token         Token
+}
+
+// Token locates an Ed25519 key pair in a PKCS#11 token. Module is the path of the PKCS#11 library,
+// Label the label of the token and Key the label of the key pair
+type Token struct {
+	Module string
+	Label  string
+	PIN    string
+	Key    string
+}
+
+// Open loads the identity as selected by the options
+func Open(o Options) (*Identity, error) {
+	if o.Token.Module != "" {
+		return openToken(o.Token)
+	}
+	if o.AgeIdentity != "" || o.AgePassphrase != "" {
+		return loadAge(o)
+	}
+	return Load(o.Path)
+}
+
+// Load reads the private key stored at path, generating and storing a new one if there is none
+func Load(path string) (*Identity, error) {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		if err := util.WriteFile(path, priv, 0600); err != nil {
+			return nil, err
+		}
+		return &Identity{public: pub, signer: priv}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return fromPrivate(b)
+}
+
+func fromPrivate(b []byte) (*Identity, error) {
+	if len(b) != ed25519.PrivateKeySize {
+		return nil, ErrInvalidKey
+	}
+	priv := ed25519.PrivateKey(b)
+	return &Identity{public: priv.Public().(ed25519.PublicKey), signer: priv}, nil
+}
+
+// PublicKey returns the public key shared with peers
+func (i *Identity) PublicKey() []byte {
+	return i.public
+}
+
+// ID returns the peer id of this node
+func (i *Identity) ID() string {
+	return IDOf(i.public)
+}
+
+// Sign signs the message with the private key. Failures of hardware tokens are logged and return an empty signature
+func (i *Identity) Sign(msg []byte) []byte {
+	sig, err := i.signer.Sign(rand.Reader, msg, crypto.Hash(0))
+	if err != nil {
+		log.Errorf("Could not sign with identity key: %s", err)
+		return nil
+	}
+	return sig
+}
+
+// IDOf returns the peer id belonging to a public key
+func IDOf(pub []byte) string {
+	return hash.New(pub).String()
+}
+
+// Verify checks that the message was signed by the owner of the public key
+func Verify(pub, msg, sig []byte) bool {
+	if len(pub) != ed25519.PublicKeySize {
+		return false
+	}
+	return ed25519.Verify(ed25519.PublicKey(pub), msg, sig)
+}
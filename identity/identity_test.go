@@ -0,0 +1,65 @@
+package identity
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestLoad(t *testing.T) {
+	p := path.Join(os.TempDir(), "testIdentity.key")
+	os.Remove(p)
+	defer os.Remove(p)
+	i, err := Load(p)
+	assert.NoError(t, err)
+	r, err := Load(p)
+	assert.NoError(t, err)
+	assert.Equal(t, i.ID(), r.ID())
+
+	sig := i.Sign([]byte("127.0.0.1:6969"))
+	assert.True(t, Verify(r.PublicKey(), []byte("127.0.0.1:6969"), sig))
+	assert.False(t, Verify(r.PublicKey(), []byte("127.0.0.1:6970"), sig))
+	assert.False(t, Verify([]byte("short"), []byte("127.0.0.1:6969"), sig))
+
+	assert.NoError(t, ioutil.WriteFile(p, []byte("broken"), 0600))
+	_, err = Load(p)
+	assert.Equal(t, ErrInvalidKey, err)
+}
+
+func TestAge(t *testing.T) {
+	p := path.Join(os.TempDir(), "testIdentityAge.key")
+	idp := path.Join(os.TempDir(), "testIdentityAge.txt")
+	os.Remove(p)
+	defer os.Remove(p)
+	defer os.Remove(idp)
+
+	plain, err := Load(p)
+	assert.NoError(t, err)
+	id, err := age.GenerateX25519Identity()
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(idp, []byte(id.String()+"\n"), 0600))
+
+	o := Options{Path: p, AgeIdentity: idp}
+	i, err := Open(o)
+	assert.NoError(t, err)
+	assert.Equal(t, plain.ID(), i.ID())
+	b, err := ioutil.ReadFile(p)
+	assert.NoError(t, err)
+	assert.NotEqual(t, ed25519.PrivateKeySize, len(b))
+
+	r, err := Open(o)
+	assert.NoError(t, err)
+	assert.Equal(t, i.ID(), r.ID())
+	assert.True(t, Verify(r.PublicKey(), []byte("foo"), i.Sign([]byte("foo"))))
+
+	other, err := age.GenerateX25519Identity()
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(idp, []byte(other.String()+"\n"), 0600))
+	_, err = Open(o)
+	assert.Error(t, err)
+}
@@ -0,0 +1,124 @@
+//go:build pkcs11
+// +build pkcs11
+
+package identity
+
+import (
+	"crypto"
+	"encoding/asn1"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/miekg/pkcs11"
+	"golang.org/x/crypto/ed25519"
+)
+
+// ckmEdDSA is the EdDSA mechanism introduced with PKCS#11 3.0
+const ckmEdDSA = 0x1057
+
+// tokenSigner signs with a private key which never leaves the token
+type tokenSigner struct {
+	ctx     *pkcs11.Ctx
+	session pkcs11.SessionHandle
+	key     pkcs11.ObjectHandle
+	public  ed25519.PublicKey
+	lock    sync.Mutex
+}
+
+// Public implements crypto.Signer
+func (s *tokenSigner) Public() crypto.PublicKey {
+	return s.public
+}
+
+// Sign implements crypto.Signer. Ed25519 signs the message itself, so opts are ignored
+func (s *tokenSigner) Sign(_ io.Reader, msg []byte, _ crypto.SignerOpts) ([]byte, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.ctx.SignInit(s.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(ckmEdDSA, nil)}, s.key); err != nil {
+		return nil, err
+	}
+	return s.ctx.Sign(s.session, msg)
+}
+
+func openToken(t Token) (*Identity, error) {
+	ctx := pkcs11.New(t.Module)
+	if ctx == nil {
+		return nil, errors.New("Could not load PKCS#11 module " + t.Module)
+	}
+	if err := ctx.Initialize(); err != nil {
+		return nil, err
+	}
+	slot, err := findSlot(ctx, t.Label)
+	if err != nil {
+		return nil, err
+	}
+	session, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Login(session, pkcs11.CKU_USER, t.PIN); err != nil {
+		return nil, err
+	}
+	priv, err := findObject(ctx, session, pkcs11.CKO_PRIVATE_KEY, t.Key)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := findObject(ctx, session, pkcs11.CKO_PUBLIC_KEY, t.Key)
+	if err != nil {
+		return nil, err
+	}
+	attrs, err := ctx.GetAttributeValue(session, pub, []*pkcs11.Attribute{pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil)})
+	if err != nil {
+		return nil, err
+	}
+	// The point is stored DER encoded as an octet string, some tokens omit the encoding
+	point := attrs[0].Value
+	if len(point) != ed25519.PublicKeySize {
+		if _, err := asn1.Unmarshal(attrs[0].Value, &point); err != nil {
+			return nil, err
+		}
+	}
+	if len(point) != ed25519.PublicKeySize {
+		return nil, ErrInvalidKey
+	}
+	s := &tokenSigner{ctx: ctx, session: session, key: priv, public: ed25519.PublicKey(point)}
+	return &Identity{public: s.public, signer: s}, nil
+}
+
+// findSlot returns the slot holding the token with the label or the first slot with a token if no label is given
+func findSlot(ctx *pkcs11.Ctx, label string) (uint, error) {
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return 0, err
+	}
+	for _, s := range slots {
+		info, err := ctx.GetTokenInfo(s)
+		if err != nil {
+			return 0, err
+		}
+		if label == "" || info.Label == label {
+			return s, nil
+		}
+	}
+	return 0, errors.New("PKCS#11 token " + label + " not found")
+}
+
+func findObject(ctx *pkcs11.Ctx, session pkcs11.SessionHandle, class uint, label string) (pkcs11.ObjectHandle, error) {
+	err := ctx.FindObjectsInit(session, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer ctx.FindObjectsFinal(session)
+	os, _, err := ctx.FindObjects(session, 1)
+	if err != nil {
+		return 0, err
+	}
+	if len(os) == 0 {
+		return 0, errors.New("PKCS#11 key " + label + " not found")
+	}
+	return os[0], nil
+}
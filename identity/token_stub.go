@@ -0,0 +1,11 @@
+//go:build !pkcs11
+// +build !pkcs11
+
+package identity
+
+import "errors"
+
+// openToken is only available in builds with the pkcs11 tag, as it requires cgo
+func openToken(t Token) (*Identity, error) {
+	return nil, errors.New("PKCS#11 support is not compiled in, rebuild with -tags pkcs11")
+}
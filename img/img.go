@@ -21,8 +21,12 @@ type Image struct {
 
 // Hash returns the hash for storage
 func (i *Image) Hash() (hash.Hash, error) {
-	b := base64.URLEncoding.EncodeToString(i.Raw)
-	return hash.New([]byte(b)), nil
+	return hash.New(i.Canonical()), nil
+}
+
+// Canonical returns the bytes the hash of the image is computed from
+func (i *Image) Canonical() []byte {
+	return []byte(base64.URLEncoding.EncodeToString(i.Raw))
 }
 
 // Serialize implements tangle/datastore.serializable
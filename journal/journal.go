@@ -0,0 +1,255 @@
+// Package journal is a write-ahead log for operations spanning several stores.
+// An operation is appended before the stores are written and marked done afterwards.
+// Operations which were appended but never marked done, e.g. because the process crashed, are returned by Pending after reopening the journal,
+// so they can be applied again. Operations therefore have to be idempotent.
+// Operations which can not be applied at all are moved to a quarantine file next to the journal, so they neither block recovery nor get lost
+package journal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"sort"
+	"sync"
+)
+
+const (
+	kindAppend byte = 1
+	kindDone   byte = 2
+	headerSize      = 8
+)
+
+var (
+	// ErrClosed is returned when writing to a closed journal
+	ErrClosed = errors.New("Journal is closed")
+	// errCorrupt marks a record that was not written completely
+	errCorrupt = errors.New("Corrupt journal record")
+)
+
+// Entry is an operation which was not marked done
+type Entry struct {
+	Seq     uint64
+	Payload []byte
+}
+
+// Journal is an append only file of operations. Records are framed by their length and a CRC32 checksum,
+// a torn record at the end of the file is discarded when the journal is opened
+type Journal struct {
+	f       *os.File
+	path    string
+	noSync  bool
+	next    uint64
+	pending map[uint64][]byte
+	lock    sync.Mutex
+}
+
+// Open opens the journal at path, creating it if necessary. Unless noSync is set every appended operation is synced to disk
+func Open(path string, noSync bool) (*Journal, error) {
+	j := &Journal{path: path, noSync: noSync, pending: make(map[uint64][]byte)}
+	if err := j.load(path); err != nil {
+		return nil, err
+	}
+	if err := j.compact(path); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	j.f = f
+	return j, nil
+}
+
+// Append records the operation and returns its sequence number
+func (j *Journal) Append(payload []byte) (uint64, error) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if j.f == nil {
+		return 0, ErrClosed
+	}
+	j.next++
+	seq := j.next
+	if err := j.write(kindAppend, seq, payload); err != nil {
+		return 0, err
+	}
+	if !j.noSync {
+		if err := j.f.Sync(); err != nil {
+			return 0, err
+		}
+	}
+	j.pending[seq] = payload
+	return seq, nil
+}
+
+// Done marks the operation as applied. The journal is truncated once no operation is pending
+func (j *Journal) Done(seq uint64) error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if j.f == nil {
+		return ErrClosed
+	}
+	if _, ok := j.pending[seq]; !ok {
+		return nil
+	}
+	delete(j.pending, seq)
+	if len(j.pending) == 0 {
+		return j.f.Truncate(0)
+	}
+	return j.write(kindDone, seq, nil)
+}
+
+// Quarantine appends the pending operation to the quarantine file and marks it done.
+// It is used for operations which failed in a way retrying can not fix
+func (j *Journal) Quarantine(seq uint64) error {
+	j.lock.Lock()
+	p, ok := j.pending[seq]
+	j.lock.Unlock()
+	if !ok {
+		return nil
+	}
+	f, err := os.OpenFile(j.QuarantinePath(), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(record(kindAppend, seq, p)); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return j.Done(seq)
+}
+
+// QuarantinePath returns the file quarantined operations are appended to
+func (j *Journal) QuarantinePath() string {
+	return j.path + ".quarantine"
+}
+
+// Pending returns the operations which were not marked done, oldest first
+func (j *Journal) Pending() []Entry {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	es := []Entry{}
+	for s, p := range j.pending {
+		es = append(es, Entry{Seq: s, Payload: p})
+	}
+	sort.Slice(es, func(a, b int) bool { return es[a].Seq < es[b].Seq })
+	return es
+}
+
+// Close closes the journal file
+func (j *Journal) Close() error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if j.f == nil {
+		return nil
+	}
+	err := j.f.Close()
+	j.f = nil
+	return err
+}
+
+func (j *Journal) write(kind byte, seq uint64, payload []byte) error {
+	_, err := j.f.Write(record(kind, seq, payload))
+	return err
+}
+
+func record(kind byte, seq uint64, payload []byte) []byte {
+	body := make([]byte, 9+len(payload))
+	body[0] = kind
+	binary.BigEndian.PutUint64(body[1:9], seq)
+	copy(body[9:], payload)
+	r := make([]byte, headerSize, headerSize+len(body))
+	binary.BigEndian.PutUint32(r[0:4], uint32(len(body)))
+	binary.BigEndian.PutUint32(r[4:8], crc32.ChecksumIEEE(body))
+	return append(r, body...)
+}
+
+func readRecord(r io.Reader) (byte, uint64, []byte, error) {
+	h := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, h); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return 0, 0, nil, errCorrupt
+		}
+		return 0, 0, nil, err
+	}
+	n := binary.BigEndian.Uint32(h[0:4])
+	if n < 9 {
+		return 0, 0, nil, errCorrupt
+	}
+	body := make([]byte, n)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, 0, nil, errCorrupt
+	}
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(h[4:8]) {
+		return 0, 0, nil, errCorrupt
+	}
+	return body[0], binary.BigEndian.Uint64(body[1:9]), body[9:], nil
+}
+
+// load reads the pending operations, stopping at the first incomplete record
+func (j *Journal) load(path string) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	for {
+		kind, seq, payload, err := readRecord(r)
+		if err == io.EOF || err == errCorrupt {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if seq > j.next {
+			j.next = seq
+		}
+		switch kind {
+		case kindAppend:
+			j.pending[seq] = payload
+		case kindDone:
+			delete(j.pending, seq)
+		}
+	}
+}
+
+// compact rewrites the journal with only the pending operations, dropping a torn record at the end
+func (j *Journal) compact(path string) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	seqs := []uint64{}
+	for s := range j.pending {
+		seqs = append(seqs, s)
+	}
+	sort.Slice(seqs, func(a, b int) bool { return seqs[a] < seqs[b] })
+	for _, s := range seqs {
+		if _, err := f.Write(record(kindAppend, s, j.pending[s])); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
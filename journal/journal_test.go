@@ -0,0 +1,106 @@
+package journal
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journal")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "journal.wal")
+
+	j, err := Open(path, false)
+	assert.NoError(t, err)
+	a, err := j.Append([]byte("a"))
+	assert.NoError(t, err)
+	b, err := j.Append([]byte("b"))
+	assert.NoError(t, err)
+	assert.NoError(t, j.Done(a))
+	assert.Equal(t, []Entry{{Seq: b, Payload: []byte("b")}}, j.Pending())
+	assert.NoError(t, j.Close())
+
+	j, err = Open(path, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []Entry{{Seq: b, Payload: []byte("b")}}, j.Pending())
+	c, err := j.Append([]byte("c"))
+	assert.NoError(t, err)
+	assert.True(t, c > b)
+	assert.NoError(t, j.Done(b))
+	assert.NoError(t, j.Done(c))
+	assert.Empty(t, j.Pending())
+	fi, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), fi.Size())
+	assert.NoError(t, j.Close())
+	_, err = j.Append([]byte("d"))
+	assert.Equal(t, ErrClosed, err)
+}
+
+func TestTornRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journal")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "journal.wal")
+
+	j, err := Open(path, true)
+	assert.NoError(t, err)
+	_, err = j.Append([]byte("complete"))
+	assert.NoError(t, err)
+	_, err = j.Append([]byte("torn"))
+	assert.NoError(t, err)
+	assert.NoError(t, j.Close())
+	fi, _ := os.Stat(path)
+	assert.NoError(t, os.Truncate(path, fi.Size()-2))
+
+	j, err = Open(path, true)
+	assert.NoError(t, err)
+	ps := j.Pending()
+	assert.Len(t, ps, 1)
+	assert.Equal(t, []byte("complete"), ps[0].Payload)
+	s, err := j.Append([]byte("next"))
+	assert.NoError(t, err)
+	assert.NoError(t, j.Close())
+
+	j, err = Open(path, true)
+	assert.NoError(t, err)
+	assert.Len(t, j.Pending(), 2)
+	assert.Equal(t, s, j.Pending()[1].Seq)
+	assert.NoError(t, j.Close())
+}
+
+func TestQuarantine(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journal")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "journal.wal")
+
+	j, err := Open(path, true)
+	assert.NoError(t, err)
+	a, err := j.Append([]byte("broken"))
+	assert.NoError(t, err)
+	b, err := j.Append([]byte("fine"))
+	assert.NoError(t, err)
+	assert.NoError(t, j.Quarantine(a))
+	assert.Equal(t, []Entry{{Seq: b, Payload: []byte("fine")}}, j.Pending())
+	assert.NoError(t, j.Close())
+
+	f, err := os.Open(j.QuarantinePath())
+	assert.NoError(t, err)
+	defer f.Close()
+	kind, seq, payload, err := readRecord(f)
+	assert.NoError(t, err)
+	assert.Equal(t, kindAppend, kind)
+	assert.Equal(t, a, seq)
+	assert.Equal(t, []byte("broken"), payload)
+
+	j, err = Open(path, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []Entry{{Seq: b, Payload: []byte("fine")}}, j.Pending())
+	assert.NoError(t, j.Close())
+}
@@ -0,0 +1,136 @@
+package keys
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+var (
+	// ErrNotFound is returned when no key is known for a fingerprint
+	ErrNotFound = errors.New("No key found for this fingerprint")
+	// ErrMismatch is returned when a key does not have the requested fingerprint
+	ErrMismatch = errors.New("Key does not match the fingerprint")
+)
+
+// signed is implemented by all site types carrying the key of their author
+type signed interface {
+	Fingerprint() string
+	ArmoredPubkey() (string, error)
+}
+
+// Index maps fingerprints to the armored public keys of authors and the sites they signed
+type Index struct {
+	keys  map[string]string
+	sites map[string][]hash.Hash
+	seen  map[hash.Hash]bool
+	lock  sync.RWMutex
+}
+
+// NewIndex returns an empty key index
+func NewIndex() *Index {
+	return &Index{keys: make(map[string]string), sites: make(map[string][]hash.Hash), seen: make(map[hash.Hash]bool)}
+}
+
+// Add indexes the key of the author if the site is signed
+func (i *Index) Add(o *tangle.Object) {
+	s, ok := o.Data.(signed)
+	if !ok || s.Fingerprint() == "" {
+		return
+	}
+	k, err := s.ArmoredPubkey()
+	if err != nil {
+		return
+	}
+	h := o.Site.Hash()
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.keys[s.Fingerprint()] = k
+	if !i.seen[h] {
+		i.seen[h] = true
+		i.sites[s.Fingerprint()] = append(i.sites[s.Fingerprint()], h)
+	}
+}
+
+// Indexed reports whether the object is reflected in the index. Only signed sites are indexed
+func (i *Index) Indexed(o *tangle.Object) bool {
+	s, ok := o.Data.(signed)
+	if !ok || s.Fingerprint() == "" {
+		return true
+	}
+	if _, err := s.ArmoredPubkey(); err != nil {
+		return true
+	}
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	return i.seen[o.Site.Hash()]
+}
+
+// Sync indexes the keys of all signed sites stored in the tangle
+func (i *Index) Sync(t *tangle.Tangle) {
+	for _, h := range t.Hashes() {
+		if o := t.Get(h); o != nil {
+			i.Add(o)
+		}
+	}
+}
+
+// Put validates that the armored key has the fingerprint and caches it
+func (i *Index) Put(fingerprint, armored string) error {
+	fingerprint = strings.ToLower(fingerprint)
+	p := &post.Post{PubkeyStr: armored}
+	if err := p.ReInit(); err != nil {
+		return err
+	}
+	if p.Fingerprint() != fingerprint {
+		return ErrMismatch
+	}
+	k, err := p.ArmoredPubkey()
+	if err != nil {
+		return err
+	}
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.keys[fingerprint] = k
+	return nil
+}
+
+// Get returns the armored key with the fingerprint
+func (i *Index) Get(fingerprint string) (string, error) {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	k, ok := i.keys[strings.ToLower(fingerprint)]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return k, nil
+}
+
+// Sites returns the hashes of all sites signed by the key, in the order they were added
+func (i *Index) Sites(fingerprint string) []hash.Hash {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	return append([]hash.Hash{}, i.sites[strings.ToLower(fingerprint)]...)
+}
+
+// Resolve returns the fingerprint of a known key given its fingerprint or 16 digit key id
+func (i *Index) Resolve(id string) (string, error) {
+	id = strings.ToLower(id)
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	if _, ok := i.keys[id]; ok {
+		return id, nil
+	}
+	if len(id) == 16 {
+		for fp := range i.keys {
+			if strings.HasSuffix(fp, id) {
+				return fp, nil
+			}
+		}
+	}
+	return "", ErrNotFound
+}
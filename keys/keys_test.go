@@ -0,0 +1,47 @@
+package keys
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+
+	"golang.org/x/crypto/openpgp"
+)
+
+func TestIndex(t *testing.T) {
+	author, err := openpgp.NewEntity("Author", "", "author@example.com", nil)
+	assert.NoError(t, err)
+	other, err := openpgp.NewEntity("Other", "", "other@example.com", nil)
+	assert.NoError(t, err)
+	p := &post.Post{Content: "foo", Pubkey: author}
+	q := &post.Post{Pubkey: other}
+	armored, err := q.ArmoredPubkey()
+	assert.NoError(t, err)
+
+	i := NewIndex()
+	_, err = i.Get(p.Fingerprint())
+	assert.Equal(t, ErrNotFound, err)
+	o := &tangle.Object{Site: &site.Site{Type: "post"}, Data: p}
+	i.Add(o)
+	i.Add(o)
+	k, err := i.Get(p.Fingerprint())
+	assert.NoError(t, err)
+	assert.Equal(t, p.PubkeyStr, k)
+	assert.Equal(t, []hash.Hash{o.Site.Hash()}, i.Sites(p.Fingerprint()))
+	fp, err := i.Resolve(p.Fingerprint()[24:])
+	assert.NoError(t, err)
+	assert.Equal(t, p.Fingerprint(), fp)
+	_, err = i.Resolve(q.Fingerprint())
+	assert.Equal(t, ErrNotFound, err)
+
+	assert.Equal(t, ErrMismatch, i.Put(p.Fingerprint(), armored))
+	assert.Error(t, i.Put(q.Fingerprint(), "broken"))
+	assert.NoError(t, i.Put(q.Fingerprint(), armored))
+	k, err = i.Get(q.Fingerprint())
+	assert.NoError(t, err)
+	assert.Equal(t, armored, k)
+}
@@ -0,0 +1,40 @@
+package kms
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// ErrInvalidKey is returned when a key is neither hex nor base64 encoded
+var ErrInvalidKey = errors.New("Key has to be hex or base64 encoded")
+
+// Decode parses a hex or base64 encoded key
+func Decode(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if b, err := hex.DecodeString(s); err == nil {
+		return b, nil
+	}
+	if b, err := base64.StdEncoding.DecodeString(s); err == nil {
+		return b, nil
+	}
+	return nil, ErrInvalidKey
+}
+
+// Load returns the storage encryption key. It is either given directly or printed by command,
+// which allows fetching it from an external key management service. Without either nil is returned
+func Load(key, command string) ([]byte, error) {
+	if key != "" {
+		return Decode(key)
+	}
+	if command == "" {
+		return nil, nil
+	}
+	out, err := exec.Command("sh", "-c", command).Output()
+	if err != nil {
+		return nil, err
+	}
+	return Decode(string(out))
+}
@@ -0,0 +1,26 @@
+package kms
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	k, err := Load("", "")
+	assert.NoError(t, err)
+	assert.Nil(t, k)
+
+	k, err = Load("00ff", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x00, 0xff}, k)
+
+	k, err = Load("", "echo AP8=")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x00, 0xff}, k)
+
+	_, err = Load("not a key!", "")
+	assert.Equal(t, ErrInvalidKey, err)
+	_, err = Load("", "exit 1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,54 @@
+package lang
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MinMatches is the number of stopwords a text needs to contain before a language is assigned
+const MinMatches = 2
+
+// stopwords are frequent words which rarely occur in other languages
+var stopwords = map[string][]string{
+	"de": {"der", "die", "das", "und", "ist", "nicht", "ein", "eine", "ich", "mit", "sich", "auf", "für", "auch", "wir", "sind", "wie", "noch", "aber", "oder"},
+	"en": {"the", "and", "is", "of", "to", "with", "that", "this", "it", "for", "are", "was", "you", "not", "have", "be", "but", "they", "from", "what"},
+	"es": {"el", "los", "las", "que", "y", "es", "una", "por", "con", "para", "pero", "como", "del", "muy", "está", "son", "yo", "también", "porque", "esta"},
+	"fr": {"le", "les", "et", "est", "une", "des", "que", "pas", "pour", "avec", "dans", "sur", "nous", "vous", "mais", "ce", "je", "sont", "du", "qui"},
+	"it": {"il", "che", "e", "è", "di", "gli", "una", "per", "non", "sono", "con", "anche", "ma", "questo", "della", "come", "io", "noi", "molto", "perché"},
+	"nl": {"de", "het", "een", "en", "is", "niet", "van", "dat", "ik", "met", "zijn", "voor", "ook", "maar", "wij", "op", "er", "nog", "wat", "naar"},
+	"pt": {"o", "os", "as", "que", "e", "é", "um", "uma", "não", "com", "para", "mas", "como", "do", "da", "eu", "nós", "muito", "também", "são"},
+}
+
+var lookup = func() map[string][]string {
+	l := map[string][]string{}
+	for lang, ws := range stopwords {
+		for _, w := range ws {
+			l[w] = append(l[w], lang)
+		}
+	}
+	return l
+}()
+
+// Detect returns the ISO 639-1 code of the language the text is most likely written in.
+// An empty string is returned if the text is too short or ambiguous
+func Detect(text string) string {
+	scores := map[string]int{}
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) })
+	for _, w := range words {
+		for _, l := range lookup[w] {
+			scores[l]++
+		}
+	}
+	best, top, second := "", 0, 0
+	for l, s := range scores {
+		if s > top {
+			best, top, second = l, s, top
+		} else if s > second {
+			second = s
+		}
+	}
+	if top < MinMatches || top == second {
+		return ""
+	}
+	return best
+}
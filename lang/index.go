@@ -0,0 +1,62 @@
+package lang
+
+import (
+	"sync"
+
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+// Index stores the detected language of every post
+type Index struct {
+	langs map[hash.Hash]string
+	lock  sync.RWMutex
+}
+
+// NewIndex returns an empty language index
+func NewIndex() *Index {
+	return &Index{langs: make(map[hash.Hash]string)}
+}
+
+// Add detects the language of the object if it is a post
+func (i *Index) Add(o *tangle.Object) {
+	if o.Site.Type != "post" {
+		return
+	}
+	l := Detect(o.Data.(*post.Post).Content)
+	if l == "" {
+		return
+	}
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.langs[o.Site.Hash()] = l
+}
+
+// Indexed reports whether the object is reflected in the index. Posts whose language can not be detected are not stored
+func (i *Index) Indexed(o *tangle.Object) bool {
+	if o.Site.Type != "post" || Detect(o.Data.(*post.Post).Content) == "" {
+		return true
+	}
+	return i.Get(o.Site.Hash()) != ""
+}
+
+// Sync indexes all posts stored in the tangle
+func (i *Index) Sync(t *tangle.Tangle) {
+	for _, h := range t.Hashes() {
+		s := t.GetSite(h)
+		if s == nil || s.Type != "post" {
+			continue
+		}
+		if o := t.Get(h); o != nil {
+			i.Add(o)
+		}
+	}
+}
+
+// Get returns the language of a site, or an empty string if it is unknown
+func (i *Index) Get(h hash.Hash) string {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	return i.langs[h]
+}
@@ -0,0 +1,28 @@
+package lang
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/site"
+)
+
+func TestDetect(t *testing.T) {
+	assert.Equal(t, "en", Detect("This is the tangle and it works with the nodes"))
+	assert.Equal(t, "de", Detect("Das ist nicht der Tangle, aber er funktioniert auch"))
+	assert.Equal(t, "fr", Detect("Le noeud est dans le réseau et nous sommes contents"))
+	assert.Equal(t, "", Detect("tangle"))
+	assert.Equal(t, "", Detect("que que"))
+}
+
+func TestIndex(t *testing.T) {
+	i := NewIndex()
+	a := &tangle.Object{Site: &site.Site{Type: "post", Nonce: 1}, Data: &post.Post{Content: "Ich bin nicht mit dem Tangle vertraut"}}
+	b := &tangle.Object{Site: &site.Site{Type: "post", Nonce: 2}, Data: &post.Post{Content: "#go"}}
+	i.Add(a)
+	i.Add(b)
+	assert.Equal(t, "de", i.Get(a.Site.Hash()))
+	assert.Equal(t, "", i.Get(b.Site.Hash()))
+}
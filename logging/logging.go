@@ -0,0 +1,76 @@
+package logging
+
+import (
+	"errors"
+	"os"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Modules can log debug messages while the global level is above debug
+var Modules = []string{"node", "sync", "api", "store"}
+
+// ErrUnknownModule is returned when toggling a module which is not in Modules
+var ErrUnknownModule = errors.New("Unknown log module")
+
+var (
+	debug = make(map[string]bool)
+	lock  sync.RWMutex
+)
+
+// SetLevel changes the level of the standard logger at runtime
+func SetLevel(level string) error {
+	l, err := log.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	log.SetLevel(l)
+	return nil
+}
+
+// Level returns the level of the standard logger
+func Level() string {
+	return log.GetLevel().String()
+}
+
+// SetDebug enables or disables debug messages of a module
+func SetDebug(module string, on bool) error {
+	for _, m := range Modules {
+		if m == module {
+			lock.Lock()
+			defer lock.Unlock()
+			debug[module] = on
+			return nil
+		}
+	}
+	return ErrUnknownModule
+}
+
+// Debug returns the debug toggle of every module
+func Debug() map[string]bool {
+	lock.RLock()
+	defer lock.RUnlock()
+	d := make(map[string]bool, len(Modules))
+	for _, m := range Modules {
+		d[m] = debug[m]
+	}
+	return d
+}
+
+// Debugf logs a debug message of the module if either the global level or the toggle of the module allows it
+func Debugf(module, format string, args ...interface{}) {
+	if log.IsLevelEnabled(log.DebugLevel) {
+		log.WithField("module", module).Debugf(format, args...)
+		return
+	}
+	lock.RLock()
+	on := debug[module]
+	lock.RUnlock()
+	if !on {
+		return
+	}
+	std := log.StandardLogger()
+	l := &log.Logger{Out: std.Out, Formatter: std.Formatter, Hooks: std.Hooks, Level: log.DebugLevel, ExitFunc: os.Exit}
+	l.WithField("module", module).Debugf(format, args...)
+}
@@ -0,0 +1,38 @@
+package logging
+
+import (
+	"bytes"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebugf(t *testing.T) {
+	buff := bytes.NewBuffer(nil)
+	out := log.StandardLogger().Out
+	log.SetOutput(buff)
+	defer log.SetOutput(out)
+	assert.NoError(t, SetLevel("info"))
+	assert.Equal(t, "info", Level())
+	assert.Error(t, SetLevel("chatty"))
+
+	Debugf("sync", "hidden")
+	assert.Empty(t, buff.String())
+
+	assert.NoError(t, SetDebug("sync", true))
+	assert.Equal(t, ErrUnknownModule, SetDebug("gui", true))
+	assert.True(t, Debug()["sync"])
+	assert.False(t, Debug()["api"])
+	Debugf("api", "hidden")
+	Debugf("sync", "shown")
+	assert.NotContains(t, buff.String(), "hidden")
+	assert.Contains(t, buff.String(), "shown")
+	assert.Contains(t, buff.String(), "module=sync")
+
+	assert.NoError(t, SetDebug("sync", false))
+	assert.NoError(t, SetLevel("debug"))
+	defer SetLevel("info")
+	Debugf("api", "global")
+	assert.Contains(t, buff.String(), "global")
+}
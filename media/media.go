@@ -0,0 +1,120 @@
+// Package media derives variants like thumbnails and format conversions from images and keeps them content addressed,
+// indexed by the site of the original image
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strconv"
+
+	"github.com/u-speak/core/img"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+// DefaultWidths are the thumbnail widths generated if none are configured
+var DefaultWidths = []int{160, 480, 1080}
+
+// ErrTooLarge is returned for images with more pixels than allowed, they are not decoded at all
+var ErrTooLarge = errors.New("Image has too many pixels")
+
+// Variant is a derived version of an image. Its hash is computed like the content hash of an image site,
+// so the original variant has the content hash of its site
+type Variant struct {
+	Name   string    `json:"name"`
+	Type   string    `json:"type"`
+	Width  int       `json:"width"`
+	Height int       `json:"height"`
+	Size   int       `json:"size"`
+	Hash   hash.Hash `json:"hash"`
+}
+
+func variant(name, typ string, b image.Rectangle, raw []byte) Variant {
+	h, _ := (&img.Image{Raw: raw}).Hash()
+	return Variant{Name: name, Type: typ, Width: b.Dx(), Height: b.Dy(), Size: len(raw), Hash: h}
+}
+
+// Generate decodes the image and derives its variants: the original, full size PNG and JPEG conversions
+// and JPEG thumbnails for all widths smaller than the image. The data of each variant is returned at the same index.
+// Images with more than maxPixels pixels are rejected before decoding, maxPixels <= 0 allows all sizes
+func Generate(raw []byte, widths []int, quality, maxPixels int) ([]Variant, [][]byte, error) {
+	c, _, err := image.DecodeConfig(bytes.NewReader(raw))
+	if err != nil {
+		return nil, nil, err
+	}
+	if maxPixels > 0 && int64(c.Width)*int64(c.Height) > int64(maxPixels) {
+		return nil, nil, ErrTooLarge
+	}
+	i, format, err := image.Decode(bytes.NewReader(raw))
+	if err != nil {
+		return nil, nil, err
+	}
+	vs := []Variant{variant("original", "image/"+format, i.Bounds(), raw)}
+	data := [][]byte{raw}
+	add := func(name, typ string, i image.Image) error {
+		buf := bytes.NewBuffer(nil)
+		var err error
+		if typ == "image/png" {
+			err = png.Encode(buf, i)
+		} else {
+			err = jpeg.Encode(buf, i, &jpeg.Options{Quality: quality})
+		}
+		if err != nil {
+			return err
+		}
+		vs = append(vs, variant(name, typ, i.Bounds(), buf.Bytes()))
+		data = append(data, buf.Bytes())
+		return nil
+	}
+	if format != "png" {
+		if err := add("png", "image/png", i); err != nil {
+			return nil, nil, err
+		}
+	}
+	if format != "jpeg" {
+		if err := add("jpeg", "image/jpeg", i); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, w := range widths {
+		if w <= 0 || w >= i.Bounds().Dx() {
+			continue
+		}
+		if err := add("w"+strconv.Itoa(w), "image/jpeg", Scale(i, w)); err != nil {
+			return nil, nil, err
+		}
+	}
+	return vs, data, nil
+}
+
+// Scale shrinks the image to the width keeping its aspect ratio, averaging the pixels covered by each target pixel
+func Scale(src image.Image, width int) image.Image {
+	b := src.Bounds()
+	height := b.Dy() * width / b.Dx()
+	if height < 1 {
+		height = 1
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := b.Min.Y+y*b.Dy()/height, b.Min.Y+(y+1)*b.Dy()/height
+		for x := 0; x < width; x++ {
+			x0, x1 := b.Min.X+x*b.Dx()/width, b.Min.X+(x+1)*b.Dx()/width
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, bl, a = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			if n == 0 {
+				continue
+			}
+			dst.Set(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(bl / n), A: uint16(a / n)})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,122 @@
+package media
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coreos/bbolt"
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/img"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+)
+
+func testImage(t *testing.T, w, h int) []byte {
+	i := image.NewRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			i.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	buf := bytes.NewBuffer(nil)
+	assert.NoError(t, png.Encode(buf, i))
+	return buf.Bytes()
+}
+
+func TestGenerate(t *testing.T) {
+	raw := testImage(t, 200, 100)
+	vs, data, err := Generate(raw, []int{50, 400}, 80, 0)
+	assert.NoError(t, err)
+	assert.Len(t, vs, 3)
+	assert.Len(t, data, 3)
+
+	content, _ := (&img.Image{Raw: raw}).Hash()
+	assert.Equal(t, Variant{Name: "original", Type: "image/png", Width: 200, Height: 100, Size: len(raw), Hash: content}, vs[0])
+	assert.Equal(t, "jpeg", vs[1].Name)
+	assert.Equal(t, "w50", vs[2].Name)
+	assert.Equal(t, 50, vs[2].Width)
+	assert.Equal(t, 25, vs[2].Height)
+	thumb, format, err := image.Decode(bytes.NewReader(data[2]))
+	assert.NoError(t, err)
+	assert.Equal(t, "jpeg", format)
+	assert.Equal(t, 50, thumb.Bounds().Dx())
+
+	_, _, err = Generate([]byte("no image"), nil, 80, 0)
+	assert.Error(t, err)
+	_, _, err = Generate(raw, nil, 80, 200*100-1)
+	assert.Equal(t, ErrTooLarge, err)
+}
+
+func TestIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "media")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	i, err := New(filepath.Join(dir, "media.db"), Options{Widths: []int{20}, Quality: 80})
+	assert.NoError(t, err)
+	defer i.Close()
+
+	o := &tangle.Object{Site: &site.Site{Type: "image", Content: hash.New([]byte("content"))}, Data: &img.Image{Raw: testImage(t, 40, 40)}}
+	assert.False(t, i.Indexed(o))
+	assert.NoError(t, i.Add(o))
+	assert.True(t, i.Indexed(o))
+	vs, ok := i.Variants(o.Site.Hash())
+	assert.True(t, ok)
+	assert.Len(t, vs, 3)
+	data, ok := i.Get(vs[2].Hash)
+	assert.True(t, ok)
+	assert.Equal(t, vs[2].Size, len(data))
+
+	shared := &tangle.Object{Site: &site.Site{Type: "image", Content: hash.New([]byte("other"))}, Data: o.Data}
+	i.Add(shared)
+	assert.ElementsMatch(t, []hash.Hash{o.Site.Hash(), shared.Site.Hash()}, i.Sources(vs[2].Hash))
+	assert.NoError(t, i.Remove(o.Site.Hash()))
+	_, ok = i.Variants(o.Site.Hash())
+	assert.False(t, ok)
+	_, ok = i.Get(vs[2].Hash)
+	assert.True(t, ok)
+	assert.Equal(t, []hash.Hash{shared.Site.Hash()}, i.Sources(vs[2].Hash))
+	assert.NoError(t, i.Remove(shared.Site.Hash()))
+	_, ok = i.Get(vs[2].Hash)
+	assert.False(t, ok)
+
+	broken := &tangle.Object{Site: &site.Site{Type: "image", Content: hash.New([]byte("broken"))}, Data: &img.Image{Raw: []byte("no image")}}
+	assert.NoError(t, i.Add(broken))
+	assert.True(t, i.Indexed(broken))
+	vs, ok = i.Variants(broken.Site.Hash())
+	assert.True(t, ok)
+	assert.Empty(t, vs)
+}
+
+func TestIndexSources(t *testing.T) {
+	dir, err := ioutil.TempDir("", "media")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "media.db")
+	s, v := hash.New([]byte("site")), hash.New([]byte("variant"))
+	db, err := bolt.Open(path, 0644, nil)
+	assert.NoError(t, err)
+	db.Update(func(tx *bolt.Tx) error {
+		vb, _ := tx.CreateBucket(variantBucketName)
+		mb, _ := tx.CreateBucket(mediaBucketName)
+		b, _ := json.Marshal([]Variant{{Hash: v}})
+		vb.Put(s.Slice(), b)
+		return mb.Put(v.Slice(), []byte("data"))
+	})
+	db.Close()
+
+	i, err := New(path, Options{})
+	assert.NoError(t, err)
+	defer i.Close()
+	assert.Equal(t, []hash.Hash{s}, i.Sources(v))
+	assert.NoError(t, i.Remove(s))
+	_, ok := i.Get(v)
+	assert.False(t, ok)
+}
@@ -0,0 +1,296 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/coreos/bbolt"
+	"github.com/u-speak/core/img"
+	"github.com/u-speak/core/migrate"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// migrations upgrade the media database to the current format, see migrate.Open
+var migrations = []migrate.Migration{migrate.None, countRefs, indexSources}
+
+var (
+	variantBucketName = []byte("variants")
+	mediaBucketName   = []byte("media")
+	refBucketName     = []byte("refs")
+	// sourceBucketName holds an empty entry keyed by variant hash and site hash for every site deriving a variant
+	sourceBucketName = []byte("sources")
+)
+
+// Index stores the variants of all image sites. Variants are content addressed and shared by all sites
+// deriving the same data, their data is deleted once no site derives it anymore
+type Index struct {
+	db        *bolt.DB
+	widths    []int
+	quality   int
+	maxPixels int
+}
+
+// Options configure how variants are generated
+type Options struct {
+	// Widths are the thumbnail widths, DefaultWidths if empty
+	Widths []int
+	// Quality is the JPEG quality of the variants
+	Quality int
+	// MaxPixels is the largest image in pixels variants are generated for, all sizes if <= 0
+	MaxPixels int
+}
+
+// New opens the media database at path
+func New(path string, o Options) (*Index, error) {
+	db, err := migrate.Open(path, 0644, migrations)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, b := range [][]byte{variantBucketName, mediaBucketName, sourceBucketName} {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if len(o.Widths) == 0 {
+		o.Widths = DefaultWidths
+	}
+	return &Index{db: db, widths: o.Widths, quality: o.Quality, maxPixels: o.MaxPixels}, err
+}
+
+// Add generates and stores the variants of the object if it is an image.
+// Images which can not be decoded or exceed MaxPixels are stored without variants, so they count as indexed
+func (i *Index) Add(o *tangle.Object) error {
+	im, ok := o.Data.(*img.Image)
+	if !ok {
+		return nil
+	}
+	vs, data, err := Generate(im.Raw, i.widths, i.quality, i.maxPixels)
+	if err != nil {
+		log.Warnf("Could not generate variants of image %s: %s", o.Site.Hash(), err)
+		vs, data = []Variant{}, nil
+	}
+	return i.Put(o.Site.Hash(), vs, data)
+}
+
+// Indexed reports whether the variants of the object were generated. Only images have variants
+func (i *Index) Indexed(o *tangle.Object) bool {
+	if _, ok := o.Data.(*img.Image); !ok {
+		return true
+	}
+	_, ok := i.Variants(o.Site.Hash())
+	return ok
+}
+
+// Sync generates the variants of all stored images which have none yet
+func (i *Index) Sync(t *tangle.Tangle) {
+	for _, h := range t.Hashes() {
+		s := t.GetSite(h)
+		if s == nil || s.Type != "image" {
+			continue
+		}
+		if _, ok := i.Variants(h); ok {
+			continue
+		}
+		if o := t.Get(h); o != nil {
+			if err := i.Add(o); err != nil {
+				log.Errorf("Could not store variants of image %s: %s", h, err)
+			}
+		}
+	}
+}
+
+// Put stores the variants of the site with their data at the same index
+func (i *Index) Put(site hash.Hash, vs []Variant, data [][]byte) error {
+	b, err := json.Marshal(vs)
+	if err != nil {
+		return err
+	}
+	return i.db.Update(func(tx *bolt.Tx) error {
+		if err := unref(tx, site); err != nil {
+			return err
+		}
+		m := tx.Bucket(mediaBucketName)
+		for j, v := range vs {
+			if err := m.Put(v.Hash.Slice(), data[j]); err != nil {
+				return err
+			}
+			if err := tx.Bucket(sourceBucketName).Put(sourceKey(v.Hash, site), []byte{}); err != nil {
+				return err
+			}
+		}
+		return tx.Bucket(variantBucketName).Put(site.Slice(), b)
+	})
+}
+
+// Variants returns the variants of the site
+func (i *Index) Variants(site hash.Hash) ([]Variant, bool) {
+	vs := []Variant{}
+	found := false
+	i.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(variantBucketName).Get(site.Slice())
+		if b == nil {
+			return nil
+		}
+		found = json.Unmarshal(b, &vs) == nil
+		return nil
+	})
+	return vs, found
+}
+
+// Get returns the data of the variant with the hash
+func (i *Index) Get(h hash.Hash) ([]byte, bool) {
+	var data []byte
+	i.db.View(func(tx *bolt.Tx) error {
+		if b := tx.Bucket(mediaBucketName).Get(h.Slice()); b != nil {
+			data = append([]byte{}, b...)
+		}
+		return nil
+	})
+	return data, data != nil
+}
+
+// Sources returns the sites deriving the variant with the hash
+func (i *Index) Sources(h hash.Hash) []hash.Hash {
+	hs := []hash.Hash{}
+	i.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(sourceBucketName).Cursor()
+		for k, _ := c.Seek(h.Slice()); k != nil && bytes.HasPrefix(k, h.Slice()); k, _ = c.Next() {
+			hs = append(hs, hash.FromSlice(k[hash.HashSize:]))
+		}
+		return nil
+	})
+	return hs
+}
+
+// Remove deletes the variants of the site. Their data is deleted unless other sites share it
+func (i *Index) Remove(site hash.Hash) error {
+	return i.db.Update(func(tx *bolt.Tx) error {
+		if err := unref(tx, site); err != nil {
+			return err
+		}
+		return tx.Bucket(variantBucketName).Delete(site.Slice())
+	})
+}
+
+// unref releases the variants stored for the site, deleting the data no site derives anymore
+func unref(tx *bolt.Tx, site hash.Hash) error {
+	b := tx.Bucket(variantBucketName).Get(site.Slice())
+	if b == nil {
+		return nil
+	}
+	vs := []Variant{}
+	if err := json.Unmarshal(b, &vs); err != nil {
+		return err
+	}
+	src := tx.Bucket(sourceBucketName)
+	for _, v := range vs {
+		if err := src.Delete(sourceKey(v.Hash, site)); err != nil {
+			return err
+		}
+		if k, _ := src.Cursor().Seek(v.Hash.Slice()); k != nil && bytes.HasPrefix(k, v.Hash.Slice()) {
+			continue
+		}
+		if err := tx.Bucket(mediaBucketName).Delete(v.Hash.Slice()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sourceKey(variant, site hash.Hash) []byte {
+	return append(variant.Slice(), site.Slice()...)
+}
+
+// ref adds delta to the amount of sites referring to the variant data and returns the new amount.
+// It is only used by countRefs, stores record the sites deriving a variant since indexSources
+func ref(tx *bolt.Tx, h []byte, delta int64) (int64, error) {
+	b := tx.Bucket(refBucketName)
+	n := int64(0)
+	if v := b.Get(h); len(v) == 8 {
+		n = int64(binary.BigEndian.Uint64(v))
+	}
+	n += delta
+	if n <= 0 {
+		return 0, b.Delete(h)
+	}
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(n))
+	return n, b.Put(h, buf)
+}
+
+// countRefs counts the references to the variant data of stores written before data was shared
+func countRefs(tx *bolt.Tx) error {
+	if _, err := tx.CreateBucketIfNotExists(refBucketName); err != nil {
+		return err
+	}
+	vb := tx.Bucket(variantBucketName)
+	if vb == nil {
+		return nil
+	}
+	counts := map[string]int64{}
+	err := vb.ForEach(func(k, b []byte) error {
+		vs := []Variant{}
+		if err := json.Unmarshal(b, &vs); err != nil {
+			return nil
+		}
+		for _, v := range vs {
+			counts[string(v.Hash.Slice())]++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for h, n := range counts {
+		if _, err := ref(tx, []byte(h), n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexSources replaces the reference counts with the sites deriving each variant,
+// so variants can be traced back to their images
+func indexSources(tx *bolt.Tx) error {
+	src, err := tx.CreateBucketIfNotExists(sourceBucketName)
+	if err != nil {
+		return err
+	}
+	if vb := tx.Bucket(variantBucketName); vb != nil {
+		keys := [][]byte{}
+		err := vb.ForEach(func(k, b []byte) error {
+			vs := []Variant{}
+			if err := json.Unmarshal(b, &vs); err != nil {
+				return nil
+			}
+			for _, v := range vs {
+				keys = append(keys, sourceKey(v.Hash, hash.FromSlice(k)))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			if err := src.Put(k, []byte{}); err != nil {
+				return err
+			}
+		}
+	}
+	if tx.Bucket(refBucketName) != nil {
+		return tx.DeleteBucket(refBucketName)
+	}
+	return nil
+}
+
+// Close closes the underlying database
+func (i *Index) Close() {
+	_ = i.db.Close()
+}
@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "uspeak"
+
+var (
+	// DiskFree is the amount of free bytes on the filesystems used by the stores
+	DiskFree = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "disk_free_bytes",
+		Help:      "Free bytes on the filesystem containing the store",
+	}, []string{"path"})
+	// DiskTotal is the size in bytes of the filesystems used by the stores
+	DiskTotal = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "disk_total_bytes",
+		Help:      "Size of the filesystem containing the store",
+	}, []string{"path"})
+	// ReplicationLag is the amount of sites a replica is missing compared to its writer
+	ReplicationLag = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "replication_lag_sites",
+		Help:      "Sites the replica is behind its writer",
+	})
+	// StoreOperations is the amount of operations performed on each store
+	StoreOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "store_operations_total",
+		Help:      "Operations performed on the store",
+	}, []string{"store", "op"})
+	// StoreLatency is the duration of store operations
+	StoreLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "store_operation_duration_seconds",
+		Help:      "Duration of store operations",
+		Buckets:   []float64{.0001, .0005, .001, .005, .01, .05, .1, .5, 1},
+	}, []string{"store", "op"})
+	// HookLatency is the duration of hook calls
+	HookLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "hook_duration_seconds",
+		Help:      "Duration of hook calls",
+	}, []string{"hook"})
+	// HookFailures is the amount of failed hook calls
+	HookFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "hook_failures_total",
+		Help:      "Failed hook calls",
+	}, []string{"hook"})
+	// HookDropped is the amount of hook calls skipped because the breaker was open or the queue was full
+	HookDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "hook_dropped_total",
+		Help:      "Hook calls that were not made",
+	}, []string{"hook", "reason"})
+	// Panics is the amount of panics recovered in request handlers
+	Panics = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "recovered_panics_total",
+		Help:      "Panics recovered in request handlers",
+	}, []string{"server"})
+	// RelaySuppressed is the amount of pushes skipped because the peer already had the site
+	RelaySuppressed = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "relay_suppressed_total",
+		Help:      "Pushes skipped because the peer sent or was already sent the site",
+	})
+	// RelayExpired is the amount of relay cache entries dropped after their TTL
+	RelayExpired = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "relay_expired_total",
+		Help:      "Relay cache entries dropped after their TTL",
+	})
+	// RelayCacheSize is the amount of sites in the relay cache
+	RelayCacheSize = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "relay_cache_sites",
+		Help:      "Sites in the relay cache",
+	})
+	// AdmissionQueue is the amount of pushed sites waiting to be validated
+	AdmissionQueue = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "admission_queue_sites",
+		Help:      "Pushed sites waiting for a validation slot",
+	})
+	// AdmissionActive is the amount of pushed sites being validated
+	AdmissionActive = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "admission_active_sites",
+		Help:      "Pushed sites being validated",
+	})
+	// AdmissionRejected is the amount of pushed sites turned away because the node was busy
+	AdmissionRejected = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "admission_rejected_total",
+		Help:      "Pushed sites rejected because the validation queue was full",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(DiskFree, DiskTotal, ReplicationLag, HookLatency, HookFailures, HookDropped, StoreOperations, StoreLatency, Panics,
+		RelaySuppressed, RelayExpired, RelayCacheSize, AdmissionQueue, AdmissionActive, AdmissionRejected)
+}
+
+// Handler exposes all registered metrics in the prometheus format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
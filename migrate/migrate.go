@@ -0,0 +1,113 @@
+package migrate
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"strconv"
+
+	"github.com/coreos/bbolt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	metaBucketName = []byte("meta")
+	versionKey     = []byte("version")
+	// ErrNewerFormat is returned when a store was written by a newer version of the node
+	ErrNewerFormat = errors.New("Store was written in a newer format, please upgrade")
+)
+
+// Migration upgrades a store by one version. It runs in the same transaction which stores the new version
+type Migration func(tx *bolt.Tx) error
+
+// None is the first migration of all stores. Stores written before formats were versioned have version 0,
+// their format is identical to version 1
+func None(*bolt.Tx) error { return nil }
+
+// Open opens the bolt database at path and upgrades it to the current version, which is len(migrations).
+// Migration i upgrades a store from version i to i+1. Before the first migration runs,
+// the store is copied to path.v<version>.bak. New stores are created with the current version
+func Open(path string, mode os.FileMode, migrations []Migration) (*bolt.DB, error) {
+	db, err := bolt.Open(path, mode, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := upgrade(db, path, mode, migrations); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// Version returns the format version of the database, 0 for stores created before formats were versioned
+func Version(db *bolt.DB) (int, error) {
+	v := 0
+	return v, db.View(func(tx *bolt.Tx) error {
+		v = version(tx)
+		return nil
+	})
+}
+
+func upgrade(db *bolt.DB, path string, mode os.FileMode, migrations []Migration) error {
+	current := len(migrations)
+	v, fresh := 0, true
+	err := db.View(func(tx *bolt.Tx) error {
+		v = version(tx)
+		return tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+			fresh = false
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	if v > current {
+		return ErrNewerFormat
+	}
+	if fresh {
+		return db.Update(func(tx *bolt.Tx) error { return setVersion(tx, current) })
+	}
+	if v == current {
+		return nil
+	}
+	backup := path + ".v" + strconv.Itoa(v) + ".bak"
+	if err := db.View(func(tx *bolt.Tx) error { return tx.CopyFile(backup, mode) }); err != nil {
+		return err
+	}
+	log.Infof("Migrating %s from version %d to %d, a backup was written to %s", path, v, current, backup)
+	for ; v < current; v++ {
+		err := db.Update(func(tx *bolt.Tx) error {
+			if err := migrations[v](tx); err != nil {
+				return err
+			}
+			return setVersion(tx, v+1)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func version(tx *bolt.Tx) int {
+	b := tx.Bucket(metaBucketName)
+	if b == nil {
+		return 0
+	}
+	v := b.Get(versionKey)
+	if len(v) != 8 {
+		return 0
+	}
+	return int(binary.BigEndian.Uint64(v))
+}
+
+func setVersion(tx *bolt.Tx, v int) error {
+	b, err := tx.CreateBucketIfNotExists(metaBucketName)
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(v))
+	return b.Put(versionKey, buf)
+}
@@ -0,0 +1,68 @@
+package migrate
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/coreos/bbolt"
+	"github.com/stretchr/testify/assert"
+)
+
+var bucketName = []byte("data")
+
+func TestOpen(t *testing.T) {
+	p := path.Join(os.TempDir(), "testMigrate.db")
+	os.Remove(p)
+	defer os.Remove(p)
+	defer os.Remove(p + ".v1.bak")
+
+	db, err := bolt.Open(p, 0644, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucket(bucketName)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte("key"), []byte("old"))
+	}))
+	db.Close()
+
+	db, err = Open(p, 0644, []Migration{None})
+	assert.NoError(t, err)
+	v, err := Version(db)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+	db.Close()
+	_, err = os.Stat(p + ".v0.bak")
+	assert.NoError(t, err)
+	os.Remove(p + ".v0.bak")
+
+	rewrite := func(tx *bolt.Tx) error { return tx.Bucket(bucketName).Put([]byte("key"), []byte("new")) }
+	db, err = Open(p, 0644, []Migration{None, rewrite})
+	assert.NoError(t, err)
+	assert.NoError(t, db.View(func(tx *bolt.Tx) error {
+		assert.Equal(t, []byte("new"), tx.Bucket(bucketName).Get([]byte("key")))
+		return nil
+	}))
+	db.Close()
+	_, err = os.Stat(p + ".v1.bak")
+	assert.NoError(t, err)
+
+	_, err = Open(p, 0644, []Migration{None})
+	assert.Equal(t, ErrNewerFormat, err)
+}
+
+func TestOpenFresh(t *testing.T) {
+	p := path.Join(os.TempDir(), "testMigrateFresh.db")
+	os.Remove(p)
+	defer os.Remove(p)
+	db, err := Open(p, 0644, []Migration{None, None})
+	assert.NoError(t, err)
+	defer db.Close()
+	v, err := Version(db)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, v)
+	_, err = os.Stat(p + ".v0.bak")
+	assert.True(t, os.IsNotExist(err))
+}
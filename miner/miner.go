@@ -0,0 +1,82 @@
+// Package miner prepares sites on the client side: it signs posts and mines the nonce of a site.
+// It only depends on the hashing and encoding packages the node itself uses, without file or network access,
+// so web clients can use the compiled WebAssembly module in miner/wasm instead of reimplementing the hashing rules
+package miner
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/pow"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+
+	"golang.org/x/crypto/openpgp"
+)
+
+var (
+	// ErrNoPrivateKey is returned when the armored key does not contain a private key
+	ErrNoPrivateKey = errors.New("Key does not contain a private key")
+	// ErrPassphrase is returned when the private key can not be decrypted with the passphrase
+	ErrPassphrase = errors.New("Wrong passphrase")
+)
+
+// Site is a site to be mined, referring to the sites it validates by their hashes.
+// Nonce and Hash are set by MineSite
+type Site struct {
+	Type      string      `json:"type"`
+	Content   hash.Hash   `json:"content"`
+	Validates []hash.Hash `json:"validates"`
+	Timestamp int64       `json:"timestamp,omitempty"`
+	Nonce     uint64      `json:"nonce"`
+	Hash      hash.Hash   `json:"hash"`
+}
+
+// MineSite searches the nonce giving the site at least the weight under the named proof of work algorithm,
+// starting at the nonce the site already has
+func MineSite(s *Site, weight int, algorithm string) error {
+	a, err := pow.Get(algorithm)
+	if err != nil {
+		return err
+	}
+	for {
+		c := site.CanonicalOf(s.Content, s.Nonce, s.Type, s.Timestamp, s.Validates)
+		if a.Proof(c).Weight() >= weight {
+			s.Hash = hash.New(c)
+			return nil
+		}
+		s.Nonce++
+	}
+}
+
+// SignPost signs the content with the armored private key and returns the post ready to be submitted
+func SignPost(armoredKey, passphrase, content string, timestamp int64) (*post.Post, error) {
+	el, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armoredKey))
+	if err != nil {
+		return nil, err
+	}
+	e := el[0]
+	if e.PrivateKey == nil {
+		return nil, ErrNoPrivateKey
+	}
+	if e.PrivateKey.Encrypted {
+		if err := e.PrivateKey.Decrypt([]byte(passphrase)); err != nil {
+			return nil, ErrPassphrase
+		}
+	}
+	for _, s := range e.Subkeys {
+		if s.PrivateKey != nil && s.PrivateKey.Encrypted {
+			if err := s.PrivateKey.Decrypt([]byte(passphrase)); err != nil {
+				return nil, ErrPassphrase
+			}
+		}
+	}
+	sig := bytes.NewBuffer(nil)
+	if err := openpgp.ArmoredDetachSignText(sig, e, strings.NewReader(content), nil); err != nil {
+		return nil, err
+	}
+	p := &post.Post{Content: content, Pubkey: e, Signature: sig.String(), Timestamp: timestamp}
+	return p, p.JSON()
+}
@@ -0,0 +1,50 @@
+package miner
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/pow"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+)
+
+func TestMineSite(t *testing.T) {
+	parent := &site.Site{Content: hash.New([]byte("parent")), Type: "genesis"}
+	s := &Site{Type: "post", Content: hash.New([]byte("content")), Validates: []hash.Hash{parent.Hash()}, Timestamp: 1500000000}
+	assert.NoError(t, MineSite(s, 1, ""))
+	full := &site.Site{Type: "post", Content: s.Content, Validates: []*site.Site{parent}, Timestamp: 1500000000, Nonce: s.Nonce}
+	assert.Equal(t, full.Hash(), s.Hash)
+	assert.True(t, pow.Weight(pow.Default, full) >= 1)
+
+	assert.Equal(t, pow.ErrUnknownAlgorithm, MineSite(s, 1, "sha1"))
+}
+
+func TestSignPost(t *testing.T) {
+	e, err := openpgp.NewEntity("Author", "", "author@example.com", nil)
+	assert.NoError(t, err)
+	buf := bytes.NewBuffer(nil)
+	w, err := armor.Encode(buf, openpgp.PrivateKeyType, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, e.SerializePrivate(w, nil))
+	assert.NoError(t, w.Close())
+
+	p, err := SignPost(buf.String(), "", "Hello World", 1500000000)
+	assert.NoError(t, err)
+	r := &post.Post{Content: p.Content, PubkeyStr: p.PubkeyStr, Signature: p.Signature, Timestamp: p.Timestamp}
+	assert.NoError(t, r.ReInit())
+	_, err = r.Verify()
+	assert.NoError(t, err)
+
+	pub := bytes.NewBuffer(nil)
+	w, _ = armor.Encode(pub, openpgp.PublicKeyType, nil)
+	assert.NoError(t, e.Serialize(w))
+	assert.NoError(t, w.Close())
+	_, err = SignPost(pub.String(), "", "Hello World", 1500000000)
+	assert.Equal(t, ErrNoPrivateKey, err)
+}
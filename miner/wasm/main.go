@@ -0,0 +1,48 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Command wasm exposes the miner package to JavaScript. Build it with
+//
+//	GOOS=js GOARCH=wasm go build -o miner.wasm github.com/u-speak/core/miner/wasm
+//
+// and load it with the wasm_exec.js of the Go distribution. It registers uspeakMineSite(site, weight, algorithm)
+// and uspeakSignPost(armoredKey, passphrase, content, timestamp), taking and returning JSON encoded sites and posts.
+// Errors are returned as {"error": "..."}
+package main
+
+import (
+	"encoding/json"
+	"syscall/js"
+
+	"github.com/u-speak/core/miner"
+)
+
+func respond(v interface{}, err error) interface{} {
+	if err != nil {
+		v = map[string]string{"error": err.Error()}
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(map[string]string{"error": err.Error()})
+	}
+	return string(b)
+}
+
+func mineSite(this js.Value, args []js.Value) interface{} {
+	s := &miner.Site{}
+	if err := json.Unmarshal([]byte(args[0].String()), s); err != nil {
+		return respond(nil, err)
+	}
+	return respond(s, miner.MineSite(s, args[1].Int(), args[2].String()))
+}
+
+func signPost(this js.Value, args []js.Value) interface{} {
+	p, err := miner.SignPost(args[0].String(), args[1].String(), args[2].String(), int64(args[3].Int()))
+	return respond(p, err)
+}
+
+func main() {
+	js.Global().Set("uspeakMineSite", js.FuncOf(mineSite))
+	js.Global().Set("uspeakSignPost", js.FuncOf(signPost))
+	select {}
+}
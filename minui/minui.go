@@ -1,7 +1,6 @@
 package minui
 
 import (
-	"encoding/hex"
 	"html/template"
 	"io"
 	"math/rand"
@@ -19,6 +18,7 @@ import (
 	"github.com/u-speak/core/config"
 	"github.com/u-speak/core/node"
 	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/recovery"
 	"github.com/u-speak/core/tangle"
 	"github.com/u-speak/core/tangle/datastore"
 
@@ -90,7 +90,7 @@ var (
 			return err == nil
 		},
 		"Fingerprint": func(p *post.Post) string {
-			return hex.EncodeToString(p.Pubkey.PrimaryKey.Fingerprint[:])
+			return p.Fingerprint()
 		},
 	}
 )
@@ -129,6 +129,7 @@ func (s *Server) Run() {
 	e.Renderer = r
 
 	e.Logger = logrusmiddleware.Logger{log.StandardLogger()}
+	e.Use(recovery.Echo("minui"))
 
 	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -0,0 +1,128 @@
+package netmap
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/u-speak/core/identity"
+)
+
+// ErrInvalidSignature is returned for statistics not signed by the node they describe
+var ErrInvalidSignature = errors.New("Statistics are not signed by the node")
+
+const (
+	// MaxNodes is the default amount of nodes a map keeps
+	MaxNodes = 4096
+	// MaxMerge is the amount of statistics considered per merge, further ones are ignored
+	MaxMerge = 256
+)
+
+// Stats are the statistics a node publishes about itself
+type Stats struct {
+	ID        string    `json:"id"`
+	Address   string    `json:"address"`
+	Version   string    `json:"version"`
+	Length    uint64    `json:"length"`
+	Peers     uint32    `json:"peers"`
+	LastSeen  time.Time `json:"last_seen"`
+	PublicKey []byte    `json:"-"`
+	Signature []byte    `json:"-"`
+}
+
+// Message returns the bytes covered by the signature
+func (s *Stats) Message() []byte {
+	return []byte("A" + s.Address + "V" + s.Version + "L" + strconv.FormatUint(s.Length, 10) +
+		"P" + strconv.FormatUint(uint64(s.Peers), 10) + "T" + strconv.FormatInt(s.LastSeen.Unix(), 10))
+}
+
+// Verify checks that the statistics were signed by the node they describe
+func (s *Stats) Verify() error {
+	if s.ID != identity.IDOf(s.PublicKey) || !identity.Verify(s.PublicKey, s.Message(), s.Signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Map is the aggregated view of all nodes learned through gossip
+type Map struct {
+	ttl   time.Duration
+	max   int
+	nodes map[string]Stats
+	lock  sync.RWMutex
+}
+
+// New returns an empty map of up to max nodes, forgetting nodes which were not seen for ttl
+func New(ttl time.Duration, max int) *Map {
+	return &Map{ttl: ttl, max: max, nodes: make(map[string]Stats)}
+}
+
+// Merge adds valid statistics which are newer than the known ones. Only the first MaxMerge statistics are considered.
+// A full map makes room by dropping the node seen longest ago, if it was seen before the new one.
+// It returns the number of updated nodes
+func (m *Map) Merge(ss []Stats, now time.Time) int {
+	if len(ss) > MaxMerge {
+		ss = ss[:MaxMerge]
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	updated := 0
+	for _, s := range ss {
+		if now.Sub(s.LastSeen) > m.ttl || s.LastSeen.After(now.Add(m.ttl)) {
+			continue
+		}
+		k, ok := m.nodes[s.ID]
+		if ok && !s.LastSeen.After(k.LastSeen) {
+			continue
+		}
+		if s.Verify() != nil {
+			continue
+		}
+		if !ok && len(m.nodes) >= m.max && !m.evict(s.LastSeen) {
+			continue
+		}
+		m.nodes[s.ID] = s
+		updated++
+	}
+	return updated
+}
+
+// Len returns the amount of known nodes
+func (m *Map) Len() int {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return len(m.nodes)
+}
+
+// evict drops the node seen longest ago if it was seen before the given time
+func (m *Map) evict(before time.Time) bool {
+	oldest := ""
+	for id, s := range m.nodes {
+		if oldest == "" || s.LastSeen.Before(m.nodes[oldest].LastSeen) {
+			oldest = id
+		}
+	}
+	if oldest == "" || !m.nodes[oldest].LastSeen.Before(before) {
+		return false
+	}
+	delete(m.nodes, oldest)
+	return true
+}
+
+// List returns all nodes seen within the ttl, ordered by id
+func (m *Map) List(now time.Time) []Stats {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	ss := []Stats{}
+	for id, s := range m.nodes {
+		if now.Sub(s.LastSeen) > m.ttl {
+			delete(m.nodes, id)
+			continue
+		}
+		ss = append(ss, s)
+	}
+	sort.Slice(ss, func(i, j int) bool { return ss[i].ID < ss[j].ID })
+	return ss
+}
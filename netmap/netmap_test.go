@@ -0,0 +1,71 @@
+package netmap
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/identity"
+)
+
+func signed(t *testing.T, i *identity.Identity, seen time.Time) Stats {
+	s := Stats{ID: i.ID(), Address: "127.0.0.1:6969", Version: "test", Length: 3, Peers: 1, LastSeen: seen, PublicKey: i.PublicKey()}
+	s.Signature = i.Sign(s.Message())
+	return s
+}
+
+func TestMerge(t *testing.T) {
+	p := path.Join(os.TempDir(), "testNetmap.key")
+	defer os.Remove(p)
+	i, err := identity.Load(p)
+	assert.NoError(t, err)
+	now := time.Unix(time.Now().Unix(), 0)
+	m := New(time.Hour, MaxNodes)
+
+	s := signed(t, i, now)
+	assert.NoError(t, s.Verify())
+	assert.Equal(t, 1, m.Merge([]Stats{s}, now))
+	assert.Equal(t, 0, m.Merge([]Stats{signed(t, i, now.Add(-time.Minute))}, now))
+
+	forged := signed(t, i, now.Add(time.Minute))
+	forged.Length = 100
+	assert.Equal(t, ErrInvalidSignature, forged.Verify())
+	assert.Equal(t, 0, m.Merge([]Stats{forged}, now))
+	assert.Equal(t, []Stats{s}, m.List(now))
+
+	assert.Empty(t, m.List(now.Add(2*time.Hour)))
+}
+
+func TestBounded(t *testing.T) {
+	now := time.Unix(time.Now().Unix(), 0)
+	ss := []Stats{}
+	for n := 0; n < MaxMerge+1; n++ {
+		p := path.Join(os.TempDir(), "testNetmapBounded.key")
+		os.Remove(p)
+		i, err := identity.Load(p)
+		assert.NoError(t, err)
+		ss = append(ss, signed(t, i, now.Add(time.Duration(n)*time.Second)))
+	}
+	defer os.Remove(path.Join(os.TempDir(), "testNetmapBounded.key"))
+
+	m := New(time.Hour, 2)
+	assert.Equal(t, 3, m.Merge(ss[:3], now))
+	assert.Equal(t, 2, m.Len())
+	assert.ElementsMatch(t, []string{ss[1].ID, ss[2].ID}, ids(m.List(now)))
+	assert.Equal(t, 0, m.Merge(ss[:1], now))
+	assert.ElementsMatch(t, []string{ss[1].ID, ss[2].ID}, ids(m.List(now)))
+
+	m = New(time.Hour, MaxNodes)
+	assert.Equal(t, MaxMerge, m.Merge(ss, now))
+	assert.Equal(t, MaxMerge, m.Len())
+}
+
+func ids(ss []Stats) []string {
+	r := []string{}
+	for _, s := range ss {
+		r = append(r, s.ID)
+	}
+	return r
+}
@@ -0,0 +1,84 @@
+package node
+
+import (
+	"net"
+
+	"github.com/u-speak/core/peer"
+
+	log "github.com/sirupsen/logrus"
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcpeer "google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// handshakeMethods are the RPCs peers introduce and prove themselves with, they are exempt from the allowlist
+var handshakeMethods = map[string]bool{
+	"/DistributionService/GetInfo": true,
+	"/DistributionService/Prove":   true,
+}
+
+// authenticate records the id of the peer at remote once it proved its identity in a handshake.
+// Calls from the host of the peer are attributed to the id from then on
+func (n *Node) authenticate(remote, id string) {
+	n.remoteLock.Lock()
+	defer n.remoteLock.Unlock()
+	n.authenticated[host(remote)] = id
+}
+
+// authenticatedID returns the id authenticated for the host of the address, empty if there is none
+func (n *Node) authenticatedID(address string) string {
+	n.remoteLock.RLock()
+	defer n.remoteLock.RUnlock()
+	return n.authenticated[host(address)]
+}
+
+func host(address string) string {
+	h, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+	return h
+}
+
+// fenced reports whether the caller of an RPC is rejected by the access lists.
+// Only ids authenticated by a handshake count, so peers allowed by id are fenced off until they completed one
+func (n *Node) fenced(ctx context.Context, handshake bool) bool {
+	p, ok := grpcpeer.FromContext(ctx)
+	if !ok {
+		return false
+	}
+	addr := p.Addr.String()
+	id := n.authenticatedID(addr)
+	if handshake {
+		return n.ACL.Denied(addr, id)
+	}
+	return !n.ACL.Allowed(addr, id)
+}
+
+// unaryACL rejects calls from denied addresses before they reach the handlers
+func (n *Node) unaryACL(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if n.fenced(ctx, handshakeMethods[info.FullMethod]) {
+		return nil, status.Error(codes.PermissionDenied, peer.ErrDenied.Error())
+	}
+	return handler(ctx, req)
+}
+
+// streamACL rejects streams from denied addresses
+func (n *Node) streamACL(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if n.fenced(ss.Context(), false) {
+		return status.Error(codes.PermissionDenied, peer.ErrDenied.Error())
+	}
+	return handler(srv, ss)
+}
+
+// Fence disconnects established peers which are no longer allowed by the access lists
+func (n *Node) Fence() {
+	for _, r := range n.remotes() {
+		if !n.ACL.Allowed(r, n.authenticatedID(r)) {
+			log.Infof("Disconnecting %s, it is fenced off by the access lists", r)
+			n.disconnect(r)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package node
+
+import (
+	"github.com/u-speak/core/admission"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// busyError converts a failed admission into the status returned to peers. A full queue is reported as retriable
+func busyError(err error) error {
+	if err == admission.ErrBusy {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return status.Error(codes.Canceled, err.Error())
+}
+
+// isBusy reports whether a peer rejected a call because its validation queue was full
+func isBusy(err error) bool {
+	s, ok := status.FromError(err)
+	return ok && s.Code() == codes.Unavailable && s.Message() == admission.ErrBusy.Error()
+}
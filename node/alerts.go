@@ -0,0 +1,56 @@
+package node
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/u-speak/core/alert"
+	"github.com/u-speak/core/config"
+)
+
+func (n *Node) alertRules(c config.Configuration) []alert.Rule {
+	lastPeer := time.Now()
+	peerTimeout := time.Duration(c.Alerts.PeerTimeout) * time.Minute
+	return []alert.Rule{
+		{
+			Name: "TangleInvalid",
+			Check: func() (bool, string) {
+				err := n.Tangle.Verify()
+				if err != nil {
+					return true, err.Error()
+				}
+				return false, ""
+			},
+		},
+		{
+			Name: "NoPeers",
+			Check: func() (bool, string) {
+				if len(n.remotes()) > 0 {
+					lastPeer = time.Now()
+					return false, ""
+				}
+				return time.Since(lastPeer) > peerTimeout, "No connected peers since " + lastPeer.Format(time.RFC3339)
+			},
+		},
+		{
+			Name: "SyncFailing",
+			Check: func() (bool, string) {
+				if n.syncErr != nil {
+					return true, n.syncErr.Error()
+				}
+				return false, ""
+			},
+		},
+		{
+			Name: "DiskFull",
+			Check: func() (bool, string) {
+				for _, u := range n.Watchdog.Usage() {
+					if u.Low {
+						return true, "Only " + strconv.FormatUint(u.Free/1024/1024, 10) + "MB free on " + u.Path
+					}
+				}
+				return false, ""
+			},
+		},
+	}
+}
@@ -0,0 +1,77 @@
+package node
+
+import (
+	"os"
+
+	"github.com/u-speak/core/checkpoint"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// restore fills the in-memory indexes from the checkpoint written on the last shutdown,
+// falling back to a full rebuild if there is none or the tangle changed since
+func (n *Node) restore() {
+	if err := n.Recent.Load(n.recentPath); err != nil && !os.IsNotExist(err) {
+		log.Warnf("Could not load replay window: %s", err)
+	}
+	c, err := checkpoint.Load(n.checkpoint)
+	if err == nil {
+		err = c.Validate(n.Tangle)
+	}
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warnf("Ignoring checkpoint: %s", err)
+		}
+		n.Follows.Sync(n.Tangle)
+		return
+	}
+	for _, h := range c.Follows {
+		if o := n.Tangle.Get(h); o != nil {
+			n.Follows.Add(o)
+		}
+	}
+	for _, h := range c.Tail {
+		if o := n.Tangle.Get(h); o != nil {
+			n.Tail.Add(o)
+		}
+	}
+	log.Infof("Restored indexes from checkpoint of %s", c.Created)
+}
+
+// Shutdown writes a checkpoint of the in-memory indexes and closes the stores
+func (n *Node) Shutdown() error {
+	n.Cluster.Close()
+	c := checkpoint.New(n.Tangle)
+	c.Follows = n.Follows.Sites()
+	c.Tail = n.Tail.Hashes()
+	err := c.Save(n.checkpoint)
+	if rerr := n.Recent.Save(n.recentPath); rerr != nil {
+		log.Errorf("Could not save replay window: %s", rerr)
+	}
+	if n.SQLIndex != nil {
+		n.SQLIndex.Close()
+	}
+	if n.Anchors != nil {
+		n.Anchors.Close()
+	}
+	if n.Timestamps != nil {
+		n.Timestamps.Close()
+	}
+	n.Pins.Close()
+	n.Hidden.Close()
+	n.Receipts.Close()
+	if n.Custody != nil {
+		n.Custody.Close()
+	}
+	if n.Flags != nil {
+		n.Flags.Close()
+	}
+	if n.Media != nil {
+		n.Media.Close()
+	}
+	if n.Digest != nil {
+		n.Digest.Subscribers.Close()
+	}
+	n.Tangle.Close()
+	return err
+}
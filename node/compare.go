@@ -0,0 +1,133 @@
+package node
+
+import (
+	"errors"
+
+	"github.com/u-speak/core/logging"
+	"github.com/u-speak/core/reconcile"
+	"github.com/u-speak/core/tangle/hash"
+
+	d "github.com/u-speak/core/node/internal"
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// MinTableSize is the amount of cells in the first lookup table sent during reconciliation
+	MinTableSize = 48
+	// MaxTableSize is the largest lookup table tried before falling back to bucket summaries
+	MaxTableSize = 3072
+)
+
+var errUndecodable = errors.New("Difference too large for lookup tables")
+
+// CompareState returns the local hashes of all buckets that differ from the remote summary
+func (n *Node) CompareState(ctx context.Context, s *d.Summary) (*d.StateDiff, error) {
+	rs, err := reconcile.FromSlices(s.Buckets)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	hs := n.Tangle.Hashes()
+	bs := reconcile.Summarize(hs).Differing(rs)
+	sd := &d.StateDiff{}
+	for _, b := range bs {
+		sd.Buckets = append(sd.Buckets, uint32(b))
+	}
+	for _, h := range reconcile.Filter(hs, bs) {
+		sd.Hashes = append(sd.Hashes, h.Slice())
+	}
+	return sd, nil
+}
+
+// Reconcile subtracts a lookup table of the local hashes from the remote table and returns the decoded difference
+func (n *Node) Reconcile(ctx context.Context, t *d.Table) (*d.Reconciliation, error) {
+	rt, err := reconcile.ParseIBLT(t.Cells)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	diff, err := rt.Subtract(reconcile.TableOf(rt.Size(), n.Tangle.Hashes()))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	extra, missing, err := diff.Decode()
+	if err != nil {
+		return &d.Reconciliation{Decoded: false}, nil
+	}
+	rec := &d.Reconciliation{Decoded: true}
+	for _, h := range missing {
+		rec.Missing = append(rec.Missing, h.Slice())
+	}
+	for _, h := range extra {
+		rec.Extra = append(rec.Extra, h.Slice())
+	}
+	return rec, nil
+}
+
+// Compare determines which sites are missing on either side.
+// Small differences are found with lookup tables, larger ones by exchanging bucket summaries.
+// Remotes that support neither are compared by their full hash list
+func (n *Node) Compare(r string) (*HashDiff, error) {
+	conn, err := n.dial(r)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	client := d.NewDistributionServiceClient(conn)
+	local := n.Tangle.Hashes()
+	hd, err := compareTables(client, local)
+	if err == nil {
+		return hd, nil
+	}
+	if err != errUndecodable && status.Code(err) != codes.Unimplemented {
+		return nil, err
+	}
+	logging.Debugf("sync", "Falling back to bucket summaries for %s: %s", r, err)
+	hd, err = compareSummaries(client, local)
+	if status.Code(err) == codes.Unimplemented {
+		s, err := n.RemoteStatus(r)
+		if err != nil {
+			return nil, err
+		}
+		return &s.HashDiff, nil
+	}
+	return hd, err
+}
+
+func compareTables(client d.DistributionServiceClient, local []hash.Hash) (*HashDiff, error) {
+	for size := MinTableSize; size <= MaxTableSize; size *= 4 {
+		rec, err := client.Reconcile(context.Background(), &d.Table{Cells: reconcile.TableOf(size, local).Bytes()})
+		if err != nil {
+			return nil, err
+		}
+		if !rec.Decoded {
+			continue
+		}
+		hd := &HashDiff{Additions: []hash.Hash{}, Deletions: []hash.Hash{}}
+		for _, h := range rec.Missing {
+			hd.Additions = append(hd.Additions, hash.FromSlice(h))
+		}
+		for _, h := range rec.Extra {
+			hd.Deletions = append(hd.Deletions, hash.FromSlice(h))
+		}
+		return hd, nil
+	}
+	return nil, errUndecodable
+}
+
+func compareSummaries(client d.DistributionServiceClient, local []hash.Hash) (*HashDiff, error) {
+	sd, err := client.CompareState(context.Background(), &d.Summary{Buckets: reconcile.Summarize(local).Slices()})
+	if err != nil {
+		return nil, err
+	}
+	bs := []int{}
+	for _, b := range sd.Buckets {
+		bs = append(bs, int(b))
+	}
+	remote := []hash.Hash{}
+	for _, h := range sd.Hashes {
+		remote = append(remote, hash.FromSlice(h))
+	}
+	a, del := reconcile.Diff(local, remote, bs)
+	return &HashDiff{Additions: a, Deletions: del}, nil
+}
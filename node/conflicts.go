@@ -0,0 +1,29 @@
+package node
+
+import (
+	"github.com/u-speak/core/tangle/hash"
+)
+
+// weightOf returns the cumulative weight of the site, or 0 if it is unknown
+func (n *Node) weightOf(h hash.Hash) int {
+	s := n.Tangle.GetSite(h)
+	if s == nil {
+		return 0
+	}
+	return n.Tangle.Weight(s)
+}
+
+// resolveConflicts makes the indexes follow the canonical site of every conflict.
+// Weights grow as sites are confirmed, so the canonical site can change after the conflicting sites were added
+func (n *Node) resolveConflicts() {
+	for _, hs := range n.Conflicts.Conflicts() {
+		c := n.Conflicts.Get(hs[0]).Canonical
+		o := n.Tangle.Get(c)
+		if o == nil {
+			continue
+		}
+		if o.Site.Type == "follow" {
+			n.Follows.Replace(o)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package node
+
+import (
+	"errors"
+	"time"
+
+	"github.com/u-speak/core/logging"
+	"github.com/u-speak/core/tangle/datastore"
+	"github.com/u-speak/core/tangle/hash"
+
+	log "github.com/sirupsen/logrus"
+	d "github.com/u-speak/core/node/internal"
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errDataNotFound = errors.New("No peer could provide the payload")
+
+// GetData returns a stored payload by its content hash
+func (n *Node) GetData(ctx context.Context, r *d.DataRequest) (*d.Data, error) {
+	data, err := n.Tangle.GetData(r.Type, hash.FromSlice(r.Content))
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	b, err := data.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	return &d.Data{Data: b}, nil
+}
+
+// FetchData returns the payload with the content hash. Payloads missing locally are requested from all peers in turn,
+// the first one hashing to the content hash is stored
+func (n *Node) FetchData(typ string, content hash.Hash) (datastore.Serializable, error) {
+	data, err := n.Tangle.GetData(typ, content)
+	if err != datastore.ErrNotFound {
+		return data, err
+	}
+	for _, r := range n.sources() {
+		data, err := n.requestData(r, typ, content)
+		if err != nil {
+			logging.Debugf("sync", "Peer %s could not provide payload %s: %s", r, content, err)
+			continue
+		}
+		if h, err := data.Hash(); err != nil || h != content {
+			log.Warnf("Peer %s sent a payload not matching %s", r, content)
+			n.Peers.Failed(r)
+			continue
+		}
+		if err := n.Tangle.PutData(data); err != nil {
+			return nil, err
+		}
+		return data, nil
+	}
+	return nil, errDataNotFound
+}
+
+func (n *Node) requestData(r, typ string, content hash.Hash) (datastore.Serializable, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), siteFetchTimeout)
+	defer cancel()
+	conn, err := n.dial(r)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	start := time.Now()
+	data, err := d.NewDistributionServiceClient(conn).GetData(ctx, &d.DataRequest{Content: content.Slice(), Type: typ})
+	if err != nil {
+		return nil, err
+	}
+	n.Health.Transferred(r, len(data.Data), time.Since(start))
+	return d.Decode(typ, data.Data)
+}
@@ -0,0 +1,69 @@
+package node
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	d "github.com/u-speak/core/node/internal"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+var (
+	serverKeepalive = grpc.KeepaliveParams(keepalive.ServerParameters{Time: 10 * time.Second, Timeout: 3 * time.Second})
+	serverEnforce   = grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{MinTime: 5 * time.Second, PermitWithoutStream: true})
+	clientKeepalive = grpc.WithKeepaliveParams(keepalive.ClientParameters{Time: 10 * time.Second, Timeout: 3 * time.Second, PermitWithoutStream: true})
+)
+
+// Ping answers a liveness probe of another node
+func (n *Node) Ping(ctx context.Context, h *d.Heartbeat) (*d.Heartbeat, error) {
+	return &d.Heartbeat{Time: time.Now().UnixNano(), Maintenance: n.InMaintenance()}, nil
+}
+
+// heartbeat pings all connected peers in parallel until the node is stopped
+func (n *Node) heartbeat() {
+	if n.pingInterval <= 0 {
+		return
+	}
+	for range time.Tick(n.pingInterval) {
+		var wg sync.WaitGroup
+		for _, r := range n.remotes() {
+			wg.Add(1)
+			go func(r string) {
+				defer wg.Done()
+				n.ping(r)
+			}(r)
+		}
+		wg.Wait()
+	}
+}
+
+func (n *Node) ping(r string) {
+	ctx, cancel := context.WithTimeout(context.Background(), n.pingTimeout)
+	defer cancel()
+	start := time.Now()
+	err := func() error {
+		conn, err := n.dial(r)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		hb, err := d.NewDistributionServiceClient(conn).Ping(ctx, &d.Heartbeat{Time: start.UnixNano()})
+		if err == nil {
+			n.Peers.SetMaintenance(r, hb.Maintenance)
+		}
+		return err
+	}()
+	if err != nil {
+		if n.Health.Missed(r) {
+			log.Warnf("Peer %s stopped responding: %s", r, err)
+		}
+		return
+	}
+	if !n.Health.Healthy(r) {
+		log.Infof("Peer %s is responding again", r)
+	}
+	n.Health.Alive(r, time.Since(start))
+}
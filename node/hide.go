@@ -0,0 +1,21 @@
+package node
+
+import (
+	"time"
+
+	"github.com/u-speak/core/tangle/hash"
+)
+
+// resolveHidden records the content of all known hidden sites, so their payloads are hidden as well
+func (n *Node) resolveHidden() {
+	for _, e := range n.Hidden.List() {
+		if s := n.Tangle.GetSite(e.Hash); s != nil {
+			n.Hidden.Resolve(e.Hash, s.Content)
+		}
+	}
+}
+
+// refused reports whether the site is hidden by the operator and must not be relayed to peers
+func (n *Node) refused(h hash.Hash) bool {
+	return n.refuseHidden && n.Hidden.Hidden(h, time.Now())
+}
@@ -0,0 +1,48 @@
+package node
+
+import (
+	"github.com/u-speak/core/pipeline"
+	"github.com/u-speak/core/tangle"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// stage wraps an index whose updates can not fail
+func stage(name string, add func(*tangle.Object), indexed func(*tangle.Object) bool) pipeline.Stage {
+	return pipeline.Stage{
+		Name:    name,
+		Add:     func(o *tangle.Object) error { add(o); return nil },
+		Indexed: indexed,
+	}
+}
+
+// indexes registers the derived indexes with the pipeline. Optional indexes have to be set up before
+func (n *Node) indexes() {
+	n.Indexes = pipeline.New()
+	n.Indexes.Register(stage("follows", n.Follows.Add, nil))
+	n.Indexes.Register(stage("previews", n.Previews.Add, n.Previews.Indexed))
+	n.Indexes.Register(stage("dates", n.Dates.Add, n.Dates.Indexed))
+	n.Indexes.Register(stage("tags", n.Tags.Add, n.Tags.Indexed))
+	n.Indexes.Register(stage("langs", n.Langs.Add, n.Langs.Indexed))
+	n.Indexes.Register(stage("keys", n.Keys.Add, n.Keys.Indexed))
+	n.Indexes.Register(stage("conflicts", n.Conflicts.Add, n.Conflicts.Indexed))
+	if n.SQLIndex != nil {
+		n.Indexes.Register(pipeline.Stage{Name: "sql", Add: n.SQLIndex.Add, Indexed: n.SQLIndex.Indexed})
+	}
+	if n.Media != nil {
+		n.Indexes.Register(pipeline.Stage{Name: "media", Add: n.Media.Add, Indexed: n.Media.Indexed})
+	}
+	if n.Flags != nil {
+		n.Indexes.Register(stage("flags", n.Flags.Add, nil))
+	}
+	n.Tangle.OnAdd(n.Indexes.Add)
+}
+
+// reindex repairs the next batch of drifted sites and the failed index updates
+func (n *Node) reindex() error {
+	d := n.Indexes.Check(n.Tangle, true, pipeline.CheckBatch)
+	for s, hs := range d.Missing {
+		log.Warnf("Index %s was missing %d sites, repaired %d", s, len(hs), d.Repaired[s])
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package node
+
+import (
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// chainUnary runs the interceptors in order before the handler
+func chainUnary(is ...grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		h := handler
+		for i := len(is) - 1; i >= 0; i-- {
+			next, ic := h, is[i]
+			h = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return ic(ctx, req, info, next)
+			}
+		}
+		return h(ctx, req)
+	}
+}
+
+// chainStream runs the interceptors in order before the handler
+func chainStream(is ...grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		h := handler
+		for i := len(is) - 1; i >= 0; i-- {
+			next, ic := h, is[i]
+			h = func(srv interface{}, ss grpc.ServerStream) error {
+				return ic(srv, ss, info, next)
+			}
+		}
+		return h(srv, ss)
+	}
+}
@@ -12,6 +12,22 @@ It has these top-level messages:
 	Void
 	Site
 	SuccessReturn
+	Ack
+	Summary
+	StateDiff
+	Table
+	Reconciliation
+	Update
+	Heartbeat
+	KeyRequest
+	Key
+	NodeStats
+	Gossip
+	SiteRequest
+	DataRequest
+	Data
+	Challenge
+	Proof
 */
 package node
 
@@ -41,6 +57,17 @@ type Info struct {
 	ListenInterface string   `protobuf:"bytes,3,opt,name=ListenInterface" json:"ListenInterface,omitempty"`
 	Connections     []string `protobuf:"bytes,4,rep,name=Connections" json:"Connections,omitempty"`
 	Hashes          [][]byte `protobuf:"bytes,5,rep,name=Hashes,proto3" json:"Hashes,omitempty"`
+	PublicKey       []byte   `protobuf:"bytes,6,opt,name=PublicKey,proto3" json:"PublicKey,omitempty"`
+	Signature       []byte   `protobuf:"bytes,7,opt,name=Signature,proto3" json:"Signature,omitempty"`
+	Tips            uint32   `protobuf:"varint,8,opt,name=Tips" json:"Tips,omitempty"`
+	AverageWeight   float64  `protobuf:"fixed64,9,opt,name=AverageWeight" json:"AverageWeight,omitempty"`
+	AverageDepth    float64  `protobuf:"fixed64,10,opt,name=AverageDepth" json:"AverageDepth,omitempty"`
+	MaxDepth        uint32   `protobuf:"varint,11,opt,name=MaxDepth" json:"MaxDepth,omitempty"`
+	Commit          string   `protobuf:"bytes,12,opt,name=Commit" json:"Commit,omitempty"`
+	BuildDate       string   `protobuf:"bytes,13,opt,name=BuildDate" json:"BuildDate,omitempty"`
+	GoVersion       string   `protobuf:"bytes,14,opt,name=GoVersion" json:"GoVersion,omitempty"`
+	Protocol        uint32   `protobuf:"varint,15,opt,name=Protocol" json:"Protocol,omitempty"`
+	Maintenance     bool     `protobuf:"varint,16,opt,name=Maintenance" json:"Maintenance,omitempty"`
 }
 
 func (m *Info) Reset()                    { *m = Info{} }
@@ -83,6 +110,83 @@ func (m *Info) GetHashes() [][]byte {
 	return nil
 }
 
+func (m *Info) GetPublicKey() []byte {
+	if m != nil {
+		return m.PublicKey
+	}
+	return nil
+}
+
+func (m *Info) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+func (m *Info) GetTips() uint32 {
+	if m != nil {
+		return m.Tips
+	}
+	return 0
+}
+
+func (m *Info) GetAverageWeight() float64 {
+	if m != nil {
+		return m.AverageWeight
+	}
+	return 0
+}
+
+func (m *Info) GetAverageDepth() float64 {
+	if m != nil {
+		return m.AverageDepth
+	}
+	return 0
+}
+
+func (m *Info) GetMaxDepth() uint32 {
+	if m != nil {
+		return m.MaxDepth
+	}
+	return 0
+}
+
+func (m *Info) GetCommit() string {
+	if m != nil {
+		return m.Commit
+	}
+	return ""
+}
+
+func (m *Info) GetBuildDate() string {
+	if m != nil {
+		return m.BuildDate
+	}
+	return ""
+}
+
+func (m *Info) GetGoVersion() string {
+	if m != nil {
+		return m.GoVersion
+	}
+	return ""
+}
+
+func (m *Info) GetProtocol() uint32 {
+	if m != nil {
+		return m.Protocol
+	}
+	return 0
+}
+
+func (m *Info) GetMaintenance() bool {
+	if m != nil {
+		return m.Maintenance
+	}
+	return false
+}
+
 type Void struct {
 }
 
@@ -98,6 +202,8 @@ type Site struct {
 	Type      string   `protobuf:"bytes,4,opt,name=Type" json:"Type,omitempty"`
 	Data      []byte   `protobuf:"bytes,5,opt,name=Data,proto3" json:"Data,omitempty"`
 	Tip       bool     `protobuf:"varint,6,opt,name=Tip" json:"Tip,omitempty"`
+	Timestamp int64    `protobuf:"varint,7,opt,name=Timestamp" json:"Timestamp,omitempty"`
+	Buried    bool     `protobuf:"varint,8,opt,name=Buried" json:"Buried,omitempty"`
 }
 
 func (m *Site) Reset()                    { *m = Site{} }
@@ -147,6 +253,20 @@ func (m *Site) GetTip() bool {
 	return false
 }
 
+func (m *Site) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *Site) GetBuried() bool {
+	if m != nil {
+		return m.Buried
+	}
+	return false
+}
+
 type SuccessReturn struct {
 }
 
@@ -155,11 +275,411 @@ func (m *SuccessReturn) String() string            { return proto.CompactTextStr
 func (*SuccessReturn) ProtoMessage()               {}
 func (*SuccessReturn) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
 
+type Ack struct {
+	Accepted bool   `protobuf:"varint,1,opt,name=Accepted" json:"Accepted,omitempty"`
+	Reason   string `protobuf:"bytes,2,opt,name=Reason" json:"Reason,omitempty"`
+}
+
+func (m *Ack) Reset()                    { *m = Ack{} }
+func (m *Ack) String() string            { return proto.CompactTextString(m) }
+func (*Ack) ProtoMessage()               {}
+func (*Ack) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *Ack) GetAccepted() bool {
+	if m != nil {
+		return m.Accepted
+	}
+	return false
+}
+
+func (m *Ack) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+type Summary struct {
+	Buckets [][]byte `protobuf:"bytes,1,rep,name=Buckets,proto3" json:"Buckets,omitempty"`
+}
+
+func (m *Summary) Reset()                    { *m = Summary{} }
+func (m *Summary) String() string            { return proto.CompactTextString(m) }
+func (*Summary) ProtoMessage()               {}
+func (*Summary) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *Summary) GetBuckets() [][]byte {
+	if m != nil {
+		return m.Buckets
+	}
+	return nil
+}
+
+type StateDiff struct {
+	Buckets []uint32 `protobuf:"varint,1,rep,packed,name=Buckets" json:"Buckets,omitempty"`
+	Hashes  [][]byte `protobuf:"bytes,2,rep,name=Hashes,proto3" json:"Hashes,omitempty"`
+}
+
+func (m *StateDiff) Reset()                    { *m = StateDiff{} }
+func (m *StateDiff) String() string            { return proto.CompactTextString(m) }
+func (*StateDiff) ProtoMessage()               {}
+func (*StateDiff) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *StateDiff) GetBuckets() []uint32 {
+	if m != nil {
+		return m.Buckets
+	}
+	return nil
+}
+
+func (m *StateDiff) GetHashes() [][]byte {
+	if m != nil {
+		return m.Hashes
+	}
+	return nil
+}
+
+type Table struct {
+	Cells []byte `protobuf:"bytes,1,opt,name=Cells,proto3" json:"Cells,omitempty"`
+}
+
+func (m *Table) Reset()                    { *m = Table{} }
+func (m *Table) String() string            { return proto.CompactTextString(m) }
+func (*Table) ProtoMessage()               {}
+func (*Table) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *Table) GetCells() []byte {
+	if m != nil {
+		return m.Cells
+	}
+	return nil
+}
+
+type Reconciliation struct {
+	Decoded bool     `protobuf:"varint,1,opt,name=Decoded" json:"Decoded,omitempty"`
+	Missing [][]byte `protobuf:"bytes,2,rep,name=Missing,proto3" json:"Missing,omitempty"`
+	Extra   [][]byte `protobuf:"bytes,3,rep,name=Extra,proto3" json:"Extra,omitempty"`
+}
+
+func (m *Reconciliation) Reset()                    { *m = Reconciliation{} }
+func (m *Reconciliation) String() string            { return proto.CompactTextString(m) }
+func (*Reconciliation) ProtoMessage()               {}
+func (*Reconciliation) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *Reconciliation) GetDecoded() bool {
+	if m != nil {
+		return m.Decoded
+	}
+	return false
+}
+
+func (m *Reconciliation) GetMissing() [][]byte {
+	if m != nil {
+		return m.Missing
+	}
+	return nil
+}
+
+func (m *Reconciliation) GetExtra() [][]byte {
+	if m != nil {
+		return m.Extra
+	}
+	return nil
+}
+
+type Update struct {
+	Site   *Site  `protobuf:"bytes,1,opt,name=Site" json:"Site,omitempty"`
+	Length uint64 `protobuf:"varint,2,opt,name=Length" json:"Length,omitempty"`
+}
+
+func (m *Update) Reset()                    { *m = Update{} }
+func (m *Update) String() string            { return proto.CompactTextString(m) }
+func (*Update) ProtoMessage()               {}
+func (*Update) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *Update) GetSite() *Site {
+	if m != nil {
+		return m.Site
+	}
+	return nil
+}
+
+func (m *Update) GetLength() uint64 {
+	if m != nil {
+		return m.Length
+	}
+	return 0
+}
+
+type Heartbeat struct {
+	Time        int64 `protobuf:"varint,1,opt,name=Time" json:"Time,omitempty"`
+	Maintenance bool  `protobuf:"varint,2,opt,name=Maintenance" json:"Maintenance,omitempty"`
+}
+
+func (m *Heartbeat) Reset()                    { *m = Heartbeat{} }
+func (m *Heartbeat) String() string            { return proto.CompactTextString(m) }
+func (*Heartbeat) ProtoMessage()               {}
+func (*Heartbeat) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *Heartbeat) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *Heartbeat) GetMaintenance() bool {
+	if m != nil {
+		return m.Maintenance
+	}
+	return false
+}
+
+type KeyRequest struct {
+	Fingerprint string `protobuf:"bytes,1,opt,name=Fingerprint" json:"Fingerprint,omitempty"`
+}
+
+func (m *KeyRequest) Reset()                    { *m = KeyRequest{} }
+func (m *KeyRequest) String() string            { return proto.CompactTextString(m) }
+func (*KeyRequest) ProtoMessage()               {}
+func (*KeyRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+func (m *KeyRequest) GetFingerprint() string {
+	if m != nil {
+		return m.Fingerprint
+	}
+	return ""
+}
+
+type Key struct {
+	Armored string `protobuf:"bytes,1,opt,name=Armored" json:"Armored,omitempty"`
+}
+
+func (m *Key) Reset()                    { *m = Key{} }
+func (m *Key) String() string            { return proto.CompactTextString(m) }
+func (*Key) ProtoMessage()               {}
+func (*Key) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *Key) GetArmored() string {
+	if m != nil {
+		return m.Armored
+	}
+	return ""
+}
+
+type NodeStats struct {
+	ID        string `protobuf:"bytes,1,opt,name=ID" json:"ID,omitempty"`
+	Address   string `protobuf:"bytes,2,opt,name=Address" json:"Address,omitempty"`
+	Version   string `protobuf:"bytes,3,opt,name=Version" json:"Version,omitempty"`
+	Length    uint64 `protobuf:"varint,4,opt,name=Length" json:"Length,omitempty"`
+	Peers     uint32 `protobuf:"varint,5,opt,name=Peers" json:"Peers,omitempty"`
+	Time      int64  `protobuf:"varint,6,opt,name=Time" json:"Time,omitempty"`
+	PublicKey []byte `protobuf:"bytes,7,opt,name=PublicKey,proto3" json:"PublicKey,omitempty"`
+	Signature []byte `protobuf:"bytes,8,opt,name=Signature,proto3" json:"Signature,omitempty"`
+}
+
+func (m *NodeStats) Reset()                    { *m = NodeStats{} }
+func (m *NodeStats) String() string            { return proto.CompactTextString(m) }
+func (*NodeStats) ProtoMessage()               {}
+func (*NodeStats) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+func (m *NodeStats) GetID() string {
+	if m != nil {
+		return m.ID
+	}
+	return ""
+}
+
+func (m *NodeStats) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *NodeStats) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *NodeStats) GetLength() uint64 {
+	if m != nil {
+		return m.Length
+	}
+	return 0
+}
+
+func (m *NodeStats) GetPeers() uint32 {
+	if m != nil {
+		return m.Peers
+	}
+	return 0
+}
+
+func (m *NodeStats) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *NodeStats) GetPublicKey() []byte {
+	if m != nil {
+		return m.PublicKey
+	}
+	return nil
+}
+
+func (m *NodeStats) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+type Gossip struct {
+	Stats []*NodeStats `protobuf:"bytes,1,rep,name=Stats" json:"Stats,omitempty"`
+}
+
+func (m *Gossip) Reset()                    { *m = Gossip{} }
+func (m *Gossip) String() string            { return proto.CompactTextString(m) }
+func (*Gossip) ProtoMessage()               {}
+func (*Gossip) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *Gossip) GetStats() []*NodeStats {
+	if m != nil {
+		return m.Stats
+	}
+	return nil
+}
+
+type SiteRequest struct {
+	Hash []byte `protobuf:"bytes,1,opt,name=Hash,proto3" json:"Hash,omitempty"`
+}
+
+func (m *SiteRequest) Reset()                    { *m = SiteRequest{} }
+func (m *SiteRequest) String() string            { return proto.CompactTextString(m) }
+func (*SiteRequest) ProtoMessage()               {}
+func (*SiteRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+func (m *SiteRequest) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+type DataRequest struct {
+	Content []byte `protobuf:"bytes,1,opt,name=Content,proto3" json:"Content,omitempty"`
+	Type    string `protobuf:"bytes,2,opt,name=Type" json:"Type,omitempty"`
+}
+
+func (m *DataRequest) Reset()                    { *m = DataRequest{} }
+func (m *DataRequest) String() string            { return proto.CompactTextString(m) }
+func (*DataRequest) ProtoMessage()               {}
+func (*DataRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *DataRequest) GetContent() []byte {
+	if m != nil {
+		return m.Content
+	}
+	return nil
+}
+
+func (m *DataRequest) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+type Data struct {
+	Data []byte `protobuf:"bytes,1,opt,name=Data,proto3" json:"Data,omitempty"`
+}
+
+func (m *Data) Reset()                    { *m = Data{} }
+func (m *Data) String() string            { return proto.CompactTextString(m) }
+func (*Data) ProtoMessage()               {}
+func (*Data) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+
+func (m *Data) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type Challenge struct {
+	Nonce   []byte `protobuf:"bytes,1,opt,name=Nonce,proto3" json:"Nonce,omitempty"`
+	Address string `protobuf:"bytes,2,opt,name=Address" json:"Address,omitempty"`
+}
+
+func (m *Challenge) Reset()                    { *m = Challenge{} }
+func (m *Challenge) String() string            { return proto.CompactTextString(m) }
+func (*Challenge) ProtoMessage()               {}
+func (*Challenge) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+
+func (m *Challenge) GetNonce() []byte {
+	if m != nil {
+		return m.Nonce
+	}
+	return nil
+}
+
+func (m *Challenge) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+type Proof struct {
+	PublicKey []byte `protobuf:"bytes,1,opt,name=PublicKey,proto3" json:"PublicKey,omitempty"`
+	Signature []byte `protobuf:"bytes,2,opt,name=Signature,proto3" json:"Signature,omitempty"`
+}
+
+func (m *Proof) Reset()                    { *m = Proof{} }
+func (m *Proof) String() string            { return proto.CompactTextString(m) }
+func (*Proof) ProtoMessage()               {}
+func (*Proof) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+
+func (m *Proof) GetPublicKey() []byte {
+	if m != nil {
+		return m.PublicKey
+	}
+	return nil
+}
+
+func (m *Proof) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*Info)(nil), "Info")
 	proto.RegisterType((*Void)(nil), "Void")
 	proto.RegisterType((*Site)(nil), "Site")
 	proto.RegisterType((*SuccessReturn)(nil), "SuccessReturn")
+	proto.RegisterType((*Ack)(nil), "Ack")
+	proto.RegisterType((*Summary)(nil), "Summary")
+	proto.RegisterType((*StateDiff)(nil), "StateDiff")
+	proto.RegisterType((*Table)(nil), "Table")
+	proto.RegisterType((*Reconciliation)(nil), "Reconciliation")
+	proto.RegisterType((*Update)(nil), "Update")
+	proto.RegisterType((*Heartbeat)(nil), "Heartbeat")
+	proto.RegisterType((*KeyRequest)(nil), "KeyRequest")
+	proto.RegisterType((*Key)(nil), "Key")
+	proto.RegisterType((*NodeStats)(nil), "NodeStats")
+	proto.RegisterType((*Gossip)(nil), "Gossip")
+	proto.RegisterType((*SiteRequest)(nil), "SiteRequest")
+	proto.RegisterType((*DataRequest)(nil), "DataRequest")
+	proto.RegisterType((*Data)(nil), "Data")
+	proto.RegisterType((*Challenge)(nil), "Challenge")
+	proto.RegisterType((*Proof)(nil), "Proof")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -176,6 +696,16 @@ type DistributionServiceClient interface {
 	GetInfo(ctx context.Context, in *Info, opts ...grpc.CallOption) (*Info, error)
 	AddSite(ctx context.Context, in *Site, opts ...grpc.CallOption) (*SuccessReturn, error)
 	Splice(ctx context.Context, opts ...grpc.CallOption) (DistributionService_SpliceClient, error)
+	Subscribe(ctx context.Context, in *Info, opts ...grpc.CallOption) (DistributionService_SubscribeClient, error)
+	Propose(ctx context.Context, in *Site, opts ...grpc.CallOption) (*Ack, error)
+	CompareState(ctx context.Context, in *Summary, opts ...grpc.CallOption) (*StateDiff, error)
+	Reconcile(ctx context.Context, in *Table, opts ...grpc.CallOption) (*Reconciliation, error)
+	Ping(ctx context.Context, in *Heartbeat, opts ...grpc.CallOption) (*Heartbeat, error)
+	GetKey(ctx context.Context, in *KeyRequest, opts ...grpc.CallOption) (*Key, error)
+	ExchangeStats(ctx context.Context, in *Gossip, opts ...grpc.CallOption) (*Gossip, error)
+	GetSite(ctx context.Context, in *SiteRequest, opts ...grpc.CallOption) (*Site, error)
+	GetData(ctx context.Context, in *DataRequest, opts ...grpc.CallOption) (*Data, error)
+	Prove(ctx context.Context, in *Challenge, opts ...grpc.CallOption) (*Proof, error)
 }
 
 type distributionServiceClient struct {
@@ -238,12 +768,135 @@ func (x *distributionServiceSpliceClient) CloseAndRecv() (*SuccessReturn, error)
 	return m, nil
 }
 
+func (c *distributionServiceClient) Subscribe(ctx context.Context, in *Info, opts ...grpc.CallOption) (DistributionService_SubscribeClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_DistributionService_serviceDesc.Streams[1], c.cc, "/DistributionService/Subscribe", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &distributionServiceSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DistributionService_SubscribeClient interface {
+	Recv() (*Update, error)
+	grpc.ClientStream
+}
+
+type distributionServiceSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *distributionServiceSubscribeClient) Recv() (*Update, error) {
+	m := new(Update)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *distributionServiceClient) Propose(ctx context.Context, in *Site, opts ...grpc.CallOption) (*Ack, error) {
+	out := new(Ack)
+	err := grpc.Invoke(ctx, "/DistributionService/Propose", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *distributionServiceClient) CompareState(ctx context.Context, in *Summary, opts ...grpc.CallOption) (*StateDiff, error) {
+	out := new(StateDiff)
+	err := grpc.Invoke(ctx, "/DistributionService/CompareState", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *distributionServiceClient) Reconcile(ctx context.Context, in *Table, opts ...grpc.CallOption) (*Reconciliation, error) {
+	out := new(Reconciliation)
+	err := grpc.Invoke(ctx, "/DistributionService/Reconcile", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *distributionServiceClient) Ping(ctx context.Context, in *Heartbeat, opts ...grpc.CallOption) (*Heartbeat, error) {
+	out := new(Heartbeat)
+	err := grpc.Invoke(ctx, "/DistributionService/Ping", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *distributionServiceClient) GetKey(ctx context.Context, in *KeyRequest, opts ...grpc.CallOption) (*Key, error) {
+	out := new(Key)
+	err := grpc.Invoke(ctx, "/DistributionService/GetKey", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *distributionServiceClient) ExchangeStats(ctx context.Context, in *Gossip, opts ...grpc.CallOption) (*Gossip, error) {
+	out := new(Gossip)
+	err := grpc.Invoke(ctx, "/DistributionService/ExchangeStats", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *distributionServiceClient) GetSite(ctx context.Context, in *SiteRequest, opts ...grpc.CallOption) (*Site, error) {
+	out := new(Site)
+	err := grpc.Invoke(ctx, "/DistributionService/GetSite", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *distributionServiceClient) GetData(ctx context.Context, in *DataRequest, opts ...grpc.CallOption) (*Data, error) {
+	out := new(Data)
+	err := grpc.Invoke(ctx, "/DistributionService/GetData", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *distributionServiceClient) Prove(ctx context.Context, in *Challenge, opts ...grpc.CallOption) (*Proof, error) {
+	out := new(Proof)
+	err := grpc.Invoke(ctx, "/DistributionService/Prove", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for DistributionService service
 
 type DistributionServiceServer interface {
 	GetInfo(context.Context, *Info) (*Info, error)
 	AddSite(context.Context, *Site) (*SuccessReturn, error)
 	Splice(DistributionService_SpliceServer) error
+	Subscribe(*Info, DistributionService_SubscribeServer) error
+	Propose(context.Context, *Site) (*Ack, error)
+	CompareState(context.Context, *Summary) (*StateDiff, error)
+	Reconcile(context.Context, *Table) (*Reconciliation, error)
+	Ping(context.Context, *Heartbeat) (*Heartbeat, error)
+	GetKey(context.Context, *KeyRequest) (*Key, error)
+	ExchangeStats(context.Context, *Gossip) (*Gossip, error)
+	GetSite(context.Context, *SiteRequest) (*Site, error)
+	GetData(context.Context, *DataRequest) (*Data, error)
+	Prove(context.Context, *Challenge) (*Proof, error)
 }
 
 func RegisterDistributionServiceServer(s *grpc.Server, srv DistributionServiceServer) {
@@ -312,6 +965,189 @@ func (x *distributionServiceSpliceServer) Recv() (*Site, error) {
 	return m, nil
 }
 
+func _DistributionService_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Info)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DistributionServiceServer).Subscribe(m, &distributionServiceSubscribeServer{stream})
+}
+
+type DistributionService_SubscribeServer interface {
+	Send(*Update) error
+	grpc.ServerStream
+}
+
+type distributionServiceSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *distributionServiceSubscribeServer) Send(m *Update) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _DistributionService_Propose_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Site)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DistributionServiceServer).Propose(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/DistributionService/Propose",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DistributionServiceServer).Propose(ctx, req.(*Site))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DistributionService_CompareState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Summary)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DistributionServiceServer).CompareState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/DistributionService/CompareState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DistributionServiceServer).CompareState(ctx, req.(*Summary))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DistributionService_Reconcile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Table)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DistributionServiceServer).Reconcile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/DistributionService/Reconcile",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DistributionServiceServer).Reconcile(ctx, req.(*Table))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DistributionService_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Heartbeat)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DistributionServiceServer).Ping(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/DistributionService/Ping",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DistributionServiceServer).Ping(ctx, req.(*Heartbeat))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DistributionService_GetKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DistributionServiceServer).GetKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/DistributionService/GetKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DistributionServiceServer).GetKey(ctx, req.(*KeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DistributionService_ExchangeStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Gossip)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DistributionServiceServer).ExchangeStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/DistributionService/ExchangeStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DistributionServiceServer).ExchangeStats(ctx, req.(*Gossip))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DistributionService_GetSite_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SiteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DistributionServiceServer).GetSite(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/DistributionService/GetSite",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DistributionServiceServer).GetSite(ctx, req.(*SiteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DistributionService_GetData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DistributionServiceServer).GetData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/DistributionService/GetData",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DistributionServiceServer).GetData(ctx, req.(*DataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DistributionService_Prove_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Challenge)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DistributionServiceServer).Prove(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/DistributionService/Prove",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DistributionServiceServer).Prove(ctx, req.(*Challenge))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _DistributionService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "DistributionService",
 	HandlerType: (*DistributionServiceServer)(nil),
@@ -324,6 +1160,42 @@ var _DistributionService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "AddSite",
 			Handler:    _DistributionService_AddSite_Handler,
 		},
+		{
+			MethodName: "Propose",
+			Handler:    _DistributionService_Propose_Handler,
+		},
+		{
+			MethodName: "CompareState",
+			Handler:    _DistributionService_CompareState_Handler,
+		},
+		{
+			MethodName: "Reconcile",
+			Handler:    _DistributionService_Reconcile_Handler,
+		},
+		{
+			MethodName: "Ping",
+			Handler:    _DistributionService_Ping_Handler,
+		},
+		{
+			MethodName: "GetKey",
+			Handler:    _DistributionService_GetKey_Handler,
+		},
+		{
+			MethodName: "ExchangeStats",
+			Handler:    _DistributionService_ExchangeStats_Handler,
+		},
+		{
+			MethodName: "GetSite",
+			Handler:    _DistributionService_GetSite_Handler,
+		},
+		{
+			MethodName: "GetData",
+			Handler:    _DistributionService_GetData_Handler,
+		},
+		{
+			MethodName: "Prove",
+			Handler:    _DistributionService_Prove_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -331,6 +1203,11 @@ var _DistributionService_serviceDesc = grpc.ServiceDesc{
 			Handler:       _DistributionService_Splice_Handler,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "Subscribe",
+			Handler:       _DistributionService_Subscribe_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "node.proto",
 }
@@ -338,26 +1215,70 @@ var _DistributionService_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("node.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 322 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x51, 0xcb, 0x6a, 0xeb, 0x30,
-	0x14, 0x8c, 0xae, 0x65, 0xe7, 0xe6, 0xdc, 0xdc, 0xa6, 0xa8, 0xa5, 0xa8, 0xa1, 0x0b, 0xa3, 0x6e,
-	0xb4, 0xf2, 0xa2, 0xfd, 0x82, 0x92, 0x40, 0x1b, 0x08, 0x5d, 0xc8, 0x21, 0x7b, 0xc7, 0x3e, 0x69,
-	0x04, 0x41, 0x32, 0x96, 0x52, 0xc8, 0x4f, 0xf4, 0x0b, 0xfa, 0xb1, 0x45, 0x4a, 0x42, 0x1f, 0xd0,
-	0xd5, 0x99, 0x19, 0x46, 0x47, 0x33, 0x12, 0x80, 0xb1, 0x0d, 0x16, 0x6d, 0x67, 0xbd, 0x15, 0xef,
-	0x04, 0xe8, 0xcc, 0xac, 0x2d, 0xe3, 0xd0, 0x5f, 0x62, 0xe7, 0xb4, 0x35, 0x9c, 0xe4, 0x44, 0x0e,
-	0xd4, 0x89, 0xb2, 0x2b, 0xc8, 0xe6, 0x68, 0x5e, 0xfc, 0x86, 0xff, 0xc9, 0x89, 0xa4, 0xea, 0xc8,
-	0x98, 0x84, 0xd1, 0x5c, 0x3b, 0x8f, 0x66, 0x66, 0x3c, 0x76, 0xeb, 0xaa, 0x46, 0x9e, 0xc4, 0x93,
-	0x3f, 0x65, 0x96, 0xc3, 0xbf, 0x89, 0x35, 0x06, 0x6b, 0xaf, 0xad, 0x71, 0x9c, 0xe6, 0x89, 0x1c,
-	0xa8, 0xaf, 0x52, 0xb8, 0xe3, 0xa9, 0x72, 0x1b, 0x74, 0x3c, 0xcd, 0x13, 0x39, 0x54, 0x47, 0x26,
-	0x32, 0xa0, 0x4b, 0xab, 0x1b, 0xf1, 0x46, 0x80, 0x96, 0xda, 0x23, 0xbb, 0x81, 0xc1, 0xb2, 0xda,
-	0xea, 0xa6, 0xf2, 0xe8, 0x38, 0x89, 0xde, 0x4f, 0x81, 0x5d, 0x42, 0xfa, 0x6c, 0x4d, 0x8d, 0xc7,
-	0xa4, 0x07, 0x12, 0xaa, 0x4d, 0xac, 0xf1, 0x68, 0x7c, 0x0c, 0x38, 0x54, 0x27, 0xca, 0x18, 0xd0,
-	0xc5, 0xbe, 0x45, 0x4e, 0x63, 0xee, 0x88, 0x83, 0x36, 0xad, 0x7c, 0xc5, 0xd3, 0x68, 0x8d, 0x98,
-	0x9d, 0x43, 0xb2, 0xd0, 0x2d, 0xcf, 0x72, 0x22, 0xff, 0xaa, 0x00, 0xc5, 0x08, 0xfe, 0x97, 0xbb,
-	0xba, 0x46, 0xe7, 0x14, 0xfa, 0x5d, 0x67, 0xee, 0xf6, 0x70, 0x31, 0xd5, 0xce, 0x77, 0x7a, 0xb5,
-	0x0b, 0x95, 0x4a, 0xec, 0x5e, 0x75, 0x8d, 0xec, 0x1a, 0xfa, 0x8f, 0xe8, 0xe3, 0x0b, 0xa7, 0x45,
-	0x18, 0xe3, 0xc3, 0x10, 0x3d, 0x26, 0xa0, 0xff, 0xd0, 0x34, 0xb1, 0x55, 0x5a, 0x84, 0x31, 0x3e,
-	0x2b, 0xbe, 0xed, 0x14, 0x3d, 0x76, 0x0b, 0x59, 0xd9, 0x6e, 0xc3, 0xa2, 0xdf, 0x2c, 0x92, 0xac,
-	0xb2, 0xf8, 0x95, 0xf7, 0x1f, 0x01, 0x00, 0x00, 0xff, 0xff, 0x14, 0x70, 0x01, 0x73, 0xd8, 0x01,
-	0x00, 0x00,
+	// 1036 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x56, 0x4d, 0x6f, 0x1b, 0x37,
+	0x13, 0xd6, 0x6a, 0x57, 0x1f, 0x3b, 0x92, 0xec, 0x80, 0x6f, 0xf0, 0x62, 0xbb, 0x68, 0x10, 0x95,
+	0x29, 0x0a, 0xa1, 0x87, 0x45, 0x91, 0x9e, 0x0a, 0xa3, 0x07, 0x59, 0x4a, 0x1d, 0x23, 0x71, 0x20,
+	0x50, 0xae, 0x0b, 0xf4, 0x46, 0xad, 0xc6, 0x12, 0x61, 0x69, 0xb9, 0x25, 0x29, 0xc3, 0xfe, 0x7f,
+	0xbd, 0xe6, 0x5f, 0xf4, 0x87, 0x14, 0xe4, 0x7e, 0x48, 0x72, 0xe3, 0x9c, 0xc4, 0x67, 0x66, 0x96,
+	0xc3, 0x79, 0xe6, 0x99, 0xb1, 0x01, 0x32, 0xb9, 0xc4, 0x24, 0x57, 0xd2, 0x48, 0xfa, 0x8f, 0x0f,
+	0xc1, 0x65, 0x76, 0x2b, 0x49, 0x04, 0x9d, 0x1b, 0x54, 0x5a, 0xc8, 0x2c, 0xf2, 0x86, 0xde, 0x28,
+	0x64, 0x15, 0x24, 0xff, 0x87, 0xf6, 0x47, 0xcc, 0x56, 0x66, 0x1d, 0x35, 0x87, 0xde, 0x28, 0x60,
+	0x25, 0x22, 0x23, 0x38, 0xfd, 0x28, 0xb4, 0xc1, 0xec, 0x32, 0x33, 0xa8, 0x6e, 0x79, 0x8a, 0x91,
+	0xef, 0xbe, 0x7c, 0x6a, 0x26, 0x43, 0xe8, 0x4d, 0x64, 0x96, 0x61, 0x6a, 0x84, 0xcc, 0x74, 0x14,
+	0x0c, 0xfd, 0x51, 0xc8, 0x0e, 0x4d, 0x36, 0xc7, 0x7b, 0xae, 0xd7, 0xa8, 0xa3, 0xd6, 0xd0, 0x1f,
+	0xf5, 0x59, 0x89, 0xc8, 0xb7, 0x10, 0xce, 0x76, 0x8b, 0x8d, 0x48, 0x3f, 0xe0, 0x63, 0xd4, 0x1e,
+	0x7a, 0xa3, 0x3e, 0xdb, 0x1b, 0xac, 0x77, 0x2e, 0x56, 0x19, 0x37, 0x3b, 0x85, 0x51, 0xa7, 0xf0,
+	0xd6, 0x06, 0x42, 0x20, 0xb8, 0x16, 0xb9, 0x8e, 0xba, 0x43, 0x6f, 0x34, 0x60, 0xee, 0x4c, 0xbe,
+	0x87, 0xc1, 0xf8, 0x1e, 0x15, 0x5f, 0xe1, 0x1f, 0x28, 0x56, 0x6b, 0x13, 0x85, 0x43, 0x6f, 0xe4,
+	0xb1, 0x63, 0x23, 0xa1, 0xd0, 0x2f, 0x0d, 0x53, 0xcc, 0xcd, 0x3a, 0x02, 0x17, 0x74, 0x64, 0x23,
+	0x31, 0x74, 0xaf, 0xf8, 0x43, 0xe1, 0xef, 0xb9, 0x0c, 0x35, 0xb6, 0xd5, 0x4c, 0xe4, 0x76, 0x2b,
+	0x4c, 0xd4, 0x77, 0x84, 0x94, 0xc8, 0xbe, 0xf7, 0x7c, 0x27, 0x36, 0xcb, 0x29, 0x37, 0x18, 0x0d,
+	0x9c, 0x6b, 0x6f, 0xb0, 0xde, 0x0b, 0x59, 0xf5, 0xe0, 0xa4, 0xf0, 0xd6, 0x06, 0x9b, 0x6f, 0x66,
+	0x3b, 0x96, 0xca, 0x4d, 0x74, 0x5a, 0xe4, 0xab, 0xb0, 0xe5, 0xf7, 0x8a, 0x8b, 0xcc, 0x60, 0xc6,
+	0xb3, 0x14, 0xa3, 0x17, 0x43, 0x6f, 0xd4, 0x65, 0x87, 0x26, 0xda, 0x86, 0xe0, 0x46, 0x8a, 0x25,
+	0xfd, 0xdb, 0x83, 0x60, 0x2e, 0x8a, 0x64, 0x37, 0x7c, 0x23, 0x96, 0xdc, 0xa0, 0x8e, 0x3c, 0xc7,
+	0xf9, 0xde, 0x40, 0x5e, 0x42, 0xeb, 0x93, 0xb4, 0x57, 0x15, 0x1d, 0x2f, 0x80, 0x95, 0xc8, 0x44,
+	0xda, 0x2b, 0x8d, 0x6b, 0x74, 0x9f, 0x55, 0xd0, 0x51, 0xfd, 0x98, 0x63, 0x14, 0xb8, 0x57, 0xbb,
+	0xb3, 0xb5, 0x4d, 0xb9, 0xe1, 0x51, 0xcb, 0x85, 0xba, 0x33, 0x79, 0x01, 0xfe, 0xb5, 0xc8, 0x5d,
+	0x23, 0xbb, 0xcc, 0x1e, 0xed, 0x3b, 0xae, 0xc5, 0x16, 0xb5, 0xe1, 0xdb, 0xdc, 0xb5, 0xd0, 0x67,
+	0x7b, 0x83, 0x25, 0xf2, 0x7c, 0xa7, 0x04, 0x2e, 0x5d, 0x13, 0xbb, 0xac, 0x44, 0xf4, 0x14, 0x06,
+	0xf3, 0x5d, 0x9a, 0xa2, 0xd6, 0x0c, 0xcd, 0x4e, 0x65, 0xf4, 0x17, 0xf0, 0xc7, 0xe9, 0x9d, 0x25,
+	0x69, 0x9c, 0xa6, 0x98, 0x1b, 0x5c, 0x3a, 0x15, 0x77, 0x59, 0x8d, 0xed, 0x5d, 0x0c, 0xb9, 0x96,
+	0x99, 0x2b, 0x2a, 0x64, 0x25, 0xa2, 0x6f, 0xa0, 0x33, 0xdf, 0x6d, 0xb7, 0x5c, 0x3d, 0xda, 0x02,
+	0xcf, 0x77, 0xe9, 0x1d, 0x9a, 0x8a, 0x92, 0x0a, 0xd2, 0x5f, 0x21, 0x9c, 0x1b, 0x6e, 0x70, 0x2a,
+	0x6e, 0x6f, 0x9f, 0x86, 0x0d, 0xea, 0xb0, 0x03, 0x19, 0x37, 0x0f, 0x65, 0x4c, 0x5f, 0x41, 0xeb,
+	0x9a, 0x2f, 0x36, 0x68, 0x89, 0x9d, 0xe0, 0x66, 0xa3, 0xdd, 0xeb, 0xfa, 0xac, 0x00, 0xf4, 0x4f,
+	0x38, 0x61, 0x98, 0xca, 0x2c, 0x15, 0x1b, 0xc1, 0xed, 0x40, 0xd8, 0x14, 0x53, 0x4c, 0xe5, 0xb2,
+	0xae, 0xa3, 0x82, 0xd6, 0x73, 0x25, 0xb4, 0x16, 0xd9, 0xaa, 0xcc, 0x51, 0x41, 0x7b, 0xf7, 0xbb,
+	0x07, 0xa3, 0x78, 0xe4, 0x3b, 0x7b, 0x01, 0xe8, 0x19, 0xb4, 0x7f, 0xcf, 0x6d, 0x57, 0xc9, 0x37,
+	0x45, 0xeb, 0xdd, 0x85, 0xbd, 0xb7, 0xad, 0xc4, 0x02, 0xe6, 0x4c, 0xcf, 0x8d, 0x38, 0x1d, 0x43,
+	0xf8, 0x1e, 0xb9, 0x32, 0x0b, 0xe4, 0x45, 0x93, 0xc5, 0xb6, 0xf8, 0xde, 0x67, 0xee, 0xfc, 0x54,
+	0x79, 0xcd, 0xff, 0x2a, 0x2f, 0x01, 0xf8, 0x80, 0x8f, 0x0c, 0xff, 0xda, 0xa1, 0x36, 0x36, 0xfe,
+	0x37, 0x91, 0xad, 0x50, 0xe5, 0x4a, 0x64, 0xa6, 0xdc, 0x34, 0x87, 0x26, 0xfa, 0x1a, 0x7c, 0x3b,
+	0xda, 0x11, 0x74, 0xc6, 0x6a, 0x2b, 0x55, 0x49, 0x40, 0xc8, 0x2a, 0x48, 0x3f, 0x7b, 0x10, 0x7e,
+	0x92, 0x4b, 0xb4, 0xfd, 0xd0, 0xe4, 0x04, 0x9a, 0x97, 0xd3, 0x32, 0xa4, 0x79, 0x39, 0x75, 0xdf,
+	0x2d, 0x97, 0x0a, 0xb5, 0x2e, 0xdb, 0x5c, 0xc1, 0xc3, 0x05, 0xe7, 0x3f, 0xb7, 0xe0, 0x82, 0xa3,
+	0x05, 0xf7, 0x12, 0x5a, 0x33, 0x44, 0xa5, 0x9d, 0x84, 0x07, 0xac, 0x00, 0x35, 0x0d, 0xed, 0x03,
+	0x1a, 0x8e, 0xd6, 0x54, 0xe7, 0xab, 0x6b, 0xaa, 0xfb, 0x64, 0x4d, 0xd1, 0x1f, 0xa1, 0x7d, 0x21,
+	0xb5, 0x16, 0x39, 0x19, 0x42, 0xcb, 0x15, 0xe5, 0x54, 0xd5, 0x7b, 0x0b, 0x49, 0x5d, 0x26, 0x2b,
+	0x1c, 0xf4, 0x3b, 0xe8, 0xb9, 0xae, 0x95, 0x6c, 0x12, 0x08, 0xac, 0xc0, 0x4a, 0x31, 0xb9, 0x33,
+	0x3d, 0x83, 0x9e, 0x1d, 0xb5, 0x2a, 0xe4, 0x60, 0x66, 0xbd, 0x2f, 0xcf, 0x6c, 0x73, 0x3f, 0xb3,
+	0x34, 0x2e, 0x66, 0xb6, 0x9e, 0x5d, 0x6f, 0x3f, 0xbb, 0xf4, 0x0c, 0xc2, 0xc9, 0x9a, 0x6f, 0x36,
+	0x98, 0xad, 0x70, 0xbf, 0x20, 0x4a, 0x1d, 0xd7, 0x0b, 0xe2, 0xcb, 0xe4, 0xd3, 0x09, 0xb4, 0x66,
+	0x4a, 0xca, 0xdb, 0x63, 0xa6, 0xbc, 0xaf, 0x32, 0xd5, 0x7c, 0xc2, 0xd4, 0xdb, 0xcf, 0x3e, 0xfc,
+	0x6f, 0x2a, 0xb4, 0x51, 0x62, 0xb1, 0xb3, 0x53, 0x32, 0x47, 0x75, 0x2f, 0x52, 0x2b, 0xec, 0xce,
+	0x05, 0x1a, 0xf7, 0x57, 0xac, 0x95, 0xd8, 0x9f, 0xb8, 0xf8, 0xa1, 0x0d, 0x42, 0xdd, 0x8b, 0x9c,
+	0xc6, 0x0b, 0xc1, 0xc7, 0x27, 0xc9, 0xf1, 0xe6, 0x68, 0x90, 0x37, 0xd0, 0x9e, 0xe7, 0x1b, 0x91,
+	0x3e, 0x1f, 0x32, 0xf2, 0xc8, 0x6b, 0x08, 0xe7, 0xbb, 0x85, 0x4e, 0x95, 0x58, 0x60, 0x95, 0xa5,
+	0x93, 0x14, 0x93, 0x45, 0x1b, 0x3f, 0x79, 0xb6, 0xf6, 0x99, 0x92, 0xb9, 0xd4, 0xf5, 0x35, 0x41,
+	0x32, 0x4e, 0xef, 0x68, 0x83, 0xfc, 0x00, 0xfd, 0x89, 0xdc, 0xe6, 0x5c, 0xb9, 0x5e, 0x22, 0xe9,
+	0x26, 0xe5, 0xbe, 0x89, 0x21, 0xa9, 0x97, 0x8a, 0x8b, 0x0b, 0xab, 0x2d, 0x80, 0xa4, 0x9d, 0xb8,
+	0x85, 0x11, 0x9f, 0x26, 0xc7, 0x9b, 0x81, 0x36, 0xc8, 0x10, 0x82, 0x99, 0x9d, 0x77, 0x48, 0xea,
+	0xd9, 0x8c, 0x0f, 0xce, 0xb4, 0x41, 0x5e, 0x41, 0xfb, 0x02, 0x8d, 0x25, 0xb4, 0x97, 0xec, 0x87,
+	0x2f, 0x0e, 0x2c, 0x70, 0x05, 0x0f, 0xde, 0x3d, 0xa4, 0x6b, 0x9e, 0xad, 0xca, 0x21, 0xea, 0x24,
+	0x85, 0x02, 0xe3, 0xea, 0xe0, 0xb2, 0x58, 0x52, 0x1d, 0x73, 0xfd, 0xe4, 0x40, 0x74, 0x71, 0x51,
+	0x5d, 0x1d, 0xe1, 0xf4, 0xd2, 0x4f, 0x0e, 0x34, 0x17, 0xb7, 0x1c, 0x72, 0xef, 0xb0, 0x5d, 0xbf,
+	0x47, 0x02, 0x49, 0x2d, 0x9d, 0xb8, 0x9d, 0x38, 0x25, 0xd0, 0xc6, 0xa2, 0xed, 0xfe, 0x05, 0xf9,
+	0xf9, 0xdf, 0x01, 0x00, 0x87, 0xe7, 0xd9, 0x90, 0x90, 0x08, 0x00, 0x00,
 }
@@ -1,24 +1,108 @@
 package node
 
 import (
+	"errors"
+
+	"github.com/u-speak/core/follow"
+	"github.com/u-speak/core/img"
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/subscription"
 	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/datastore"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+	"github.com/u-speak/core/tombstone"
+	"github.com/u-speak/core/validate"
 )
 
 // FromObject converts a regular site into a distribution ready site
 func FromObject(o *tangle.Object) (*Site, error) {
-	vs := [][]byte{}
-	for _, v := range o.Site.Validates {
-		vs = append(vs, v.Hash().Slice())
-	}
 	data, err := o.Data.Serialize()
 	if err != nil {
 		return nil, err
 	}
+	s := FromHeader(o.Site)
+	s.Data = data
+	s.Buried = false
+	return s, nil
+}
+
+// FromHeader converts a site whose payload was removed into a distribution ready site without data
+func FromHeader(h *site.Site) *Site {
+	vs := [][]byte{}
+	for _, v := range h.Validates {
+		vs = append(vs, v.Hash().Slice())
+	}
 	return &Site{
 		Validates: vs,
-		Nonce:     o.Site.Nonce,
-		Content:   o.Site.Content.Slice(),
-		Type:      o.Site.Type,
-		Data:      data,
-	}, nil
+		Nonce:     h.Nonce,
+		Content:   h.Content.Slice(),
+		Type:      h.Type,
+		Timestamp: h.Timestamp,
+		Buried:    true,
+	}
+}
+
+// Hash computes the hash of the site it will have in the tangle
+func (s *Site) Hash() hash.Hash {
+	return hash.New(s.Canonical())
+}
+
+// Canonical returns the bytes the hash and the proof of work of the site are computed from
+func (s *Site) Canonical() []byte {
+	vs := make([]hash.Hash, len(s.Validates))
+	for i, v := range s.Validates {
+		vs[i] = hash.FromSlice(v)
+	}
+	return site.CanonicalOf(hash.FromSlice(s.Content), s.Nonce, s.Type, s.Timestamp, vs)
+}
+
+// Validate checks the fields of a received site before it is decoded
+func (s *Site) Validate() error {
+	if err := validate.Nonce(s.Nonce); err != nil {
+		return err
+	}
+	if err := validate.Type(s.Type); err != nil {
+		return err
+	}
+	if err := validate.HashBytes(s.Content); err != nil {
+		return err
+	}
+	for _, v := range s.Validates {
+		if err := validate.HashBytes(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Payload decodes the data of a received site according to its type
+func (s *Site) Payload() (datastore.Serializable, error) {
+	return Decode(s.Type, s.Data)
+}
+
+// Decode restores a payload of the site type
+func Decode(typ string, data []byte) (datastore.Serializable, error) {
+	var d datastore.Serializable
+	switch typ {
+	case "post":
+		d = &post.Post{}
+	case "image":
+		d = &img.Image{}
+	case "subscription":
+		d = &subscription.Subscription{}
+	case "follow":
+		d = &follow.List{}
+	case "tombstone":
+		d = &tombstone.Tombstone{}
+	default:
+		return nil, errors.New("Invalid site type")
+	}
+	if err := d.Deserialize(data); err != nil {
+		return nil, err
+	}
+	if err := validate.Payload(d); err != nil {
+		return nil, err
+	}
+	return d, nil
 }
@@ -0,0 +1,33 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+)
+
+func FuzzPayload(f *testing.F) {
+	for _, s := range []*Site{
+		{Type: "post", Data: []byte{0x85}},
+		{Type: "image", Data: []byte{0x89, 'P', 'N', 'G'}},
+		{Type: "follow", Content: make([]byte, 32), Validates: [][]byte{make([]byte, 32)}},
+		{Type: "genesis"},
+	} {
+		b, err := proto.Marshal(s)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(b)
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		s := &Site{}
+		if err := proto.Unmarshal(b, s); err != nil {
+			return
+		}
+		d, err := s.Payload()
+		if err != nil {
+			return
+		}
+		_, _ = d.Hash()
+	})
+}
@@ -0,0 +1,142 @@
+package node
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/u-speak/core/keys"
+	"github.com/u-speak/core/logging"
+
+	log "github.com/sirupsen/logrus"
+	d "github.com/u-speak/core/node/internal"
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// keyLookupTimeout limits how long peers are asked for a key
+	keyLookupTimeout = 5 * time.Second
+	// keyMissTTL is how long a key no peer could provide is not asked for again
+	keyMissTTL = 10 * time.Minute
+	// MaxKeyMisses limits the remembered unknown keys, the oldest one is forgotten first
+	MaxKeyMisses = 4096
+)
+
+// keyMisses remembers the fingerprints no peer could provide, so repeated lookups do not reach out to all peers again
+type keyMisses struct {
+	fingerprints map[string]*list.Element
+	// order holds the misses by time, so expired ones are always at the front
+	order *list.List
+	lock  sync.Mutex
+}
+
+type keyMiss struct {
+	fingerprint string
+	at          time.Time
+}
+
+// add remembers that the key could not be found
+func (m *keyMisses) add(fingerprint string, now time.Time) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.fingerprints == nil {
+		m.fingerprints = make(map[string]*list.Element)
+		m.order = list.New()
+	}
+	if e, ok := m.fingerprints[fingerprint]; ok {
+		m.order.Remove(e)
+	}
+	for m.order.Len() >= MaxKeyMisses {
+		m.remove(m.order.Front())
+	}
+	m.fingerprints[fingerprint] = m.order.PushBack(&keyMiss{fingerprint: fingerprint, at: now})
+}
+
+// recent reports whether the key could not be found within keyMissTTL
+func (m *keyMisses) recent(fingerprint string, now time.Time) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.order == nil {
+		return false
+	}
+	for e := m.order.Front(); e != nil && now.Sub(e.Value.(*keyMiss).at) > keyMissTTL; e = m.order.Front() {
+		m.remove(e)
+	}
+	_, ok := m.fingerprints[fingerprint]
+	return ok
+}
+
+func (m *keyMisses) remove(e *list.Element) {
+	delete(m.fingerprints, e.Value.(*keyMiss).fingerprint)
+	m.order.Remove(e)
+}
+
+// GetKey returns the armored public key with the requested fingerprint if any site signed with it is known
+func (n *Node) GetKey(ctx context.Context, r *d.KeyRequest) (*d.Key, error) {
+	k, err := n.Keys.Get(r.Fingerprint)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return &d.Key{Armored: k}, nil
+}
+
+// LookupKey returns the key with the fingerprint. Unknown keys are requested from all peers at once within keyLookupTimeout,
+// the first one matching the fingerprint is cached. Keys no peer could provide are not requested again for keyMissTTL
+func (n *Node) LookupKey(fingerprint string) (string, error) {
+	if k, err := n.Keys.Get(fingerprint); err == nil {
+		return k, nil
+	}
+	if n.keyMisses.recent(fingerprint, time.Now()) {
+		return "", keys.ErrNotFound
+	}
+	type response struct {
+		peer string
+		key  string
+		err  error
+	}
+	rs := n.sources()
+	ctx, cancel := context.WithTimeout(context.Background(), keyLookupTimeout)
+	defer cancel()
+	responses := make(chan response, len(rs))
+	for _, r := range rs {
+		go func(r string) {
+			k, err := n.requestKey(ctx, r, fingerprint)
+			responses <- response{peer: r, key: k, err: err}
+		}(r)
+	}
+	for range rs {
+		var res response
+		select {
+		case res = <-responses:
+		case <-ctx.Done():
+			n.keyMisses.add(fingerprint, time.Now())
+			return "", keys.ErrNotFound
+		}
+		if res.err != nil {
+			logging.Debugf("sync", "Peer %s could not provide key %s: %s", res.peer, fingerprint, res.err)
+			continue
+		}
+		if err := n.Keys.Put(fingerprint, res.key); err != nil {
+			log.Warnf("Peer %s sent invalid key for %s: %s", res.peer, fingerprint, err)
+			continue
+		}
+		return n.Keys.Get(fingerprint)
+	}
+	n.keyMisses.add(fingerprint, time.Now())
+	return "", keys.ErrNotFound
+}
+
+func (n *Node) requestKey(ctx context.Context, r, fingerprint string) (string, error) {
+	conn, err := n.dial(r)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	k, err := d.NewDistributionServiceClient(conn).GetKey(ctx, &d.KeyRequest{Fingerprint: fingerprint})
+	if err != nil {
+		return "", err
+	}
+	return k.Armored, nil
+}
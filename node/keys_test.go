@@ -0,0 +1,25 @@
+package node
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyMisses(t *testing.T) {
+	m := keyMisses{}
+	now := time.Now()
+	assert.False(t, m.recent("a", now))
+	m.add("a", now)
+	assert.True(t, m.recent("a", now.Add(keyMissTTL)))
+	assert.False(t, m.recent("a", now.Add(keyMissTTL+time.Second)))
+
+	for i := 0; i <= MaxKeyMisses; i++ {
+		m.add(strconv.Itoa(i), now)
+	}
+	assert.Equal(t, MaxKeyMisses, m.order.Len())
+	assert.False(t, m.recent("0", now))
+	assert.True(t, m.recent(strconv.Itoa(MaxKeyMisses), now))
+}
@@ -0,0 +1,107 @@
+package node
+
+import (
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrMaintenance is returned for new sites while the node is in maintenance mode, they can be retried later
+var ErrMaintenance = errors.New("Node is in maintenance mode, try again later")
+
+// drainPoll is the interval at which Drain checks for unfinished work
+const drainPoll = 100 * time.Millisecond
+
+// Maintenance reports whether the node accepts new sites and how much work is still running
+type Maintenance struct {
+	Enabled  bool      `json:"enabled"`
+	Since    time.Time `json:"since,omitempty"`
+	InFlight int       `json:"in_flight"`
+}
+
+// Maintenance returns the current maintenance state
+func (n *Node) Maintenance() Maintenance {
+	n.maintLock.Lock()
+	defer n.maintLock.Unlock()
+	return Maintenance{Enabled: n.maintenance, Since: n.maintenanceSince, InFlight: n.inFlight}
+}
+
+// InMaintenance reports whether the node rejects new sites
+func (n *Node) InMaintenance() bool {
+	n.maintLock.Lock()
+	defer n.maintLock.Unlock()
+	return n.maintenance
+}
+
+// SetMaintenance enters or leaves maintenance mode. Running pushes and syncs are finished, new sites from the API and peers are rejected
+func (n *Node) SetMaintenance(on bool) {
+	n.maintLock.Lock()
+	defer n.maintLock.Unlock()
+	if on && !n.maintenance {
+		n.maintenanceSince = time.Now()
+	}
+	if !on {
+		n.maintenanceSince = time.Time{}
+	}
+	n.maintenance = on
+}
+
+// Drain waits until all pushes and syncs started before maintenance mode are finished.
+// It returns false if work is still running after the timeout
+func (n *Node) Drain(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		if n.Maintenance().InFlight == 0 {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(drainPoll)
+	}
+}
+
+// begin registers new work, it is rejected with ErrMaintenance while the node is in maintenance mode.
+// The returned function has to be called once the work is finished
+func (n *Node) begin() (func(), error) {
+	n.maintLock.Lock()
+	defer n.maintLock.Unlock()
+	if n.maintenance {
+		return nil, ErrMaintenance
+	}
+	n.inFlight++
+	return n.finish, nil
+}
+
+// track registers work which was accepted before and has to be finished even in maintenance mode
+func (n *Node) track() func() {
+	n.maintLock.Lock()
+	defer n.maintLock.Unlock()
+	n.inFlight++
+	return n.finish
+}
+
+func (n *Node) finish() {
+	n.maintLock.Lock()
+	defer n.maintLock.Unlock()
+	n.inFlight--
+}
+
+// draining reports whether the peer at the address announced maintenance mode
+func (n *Node) draining(r string) bool {
+	p := n.Peers.ByAddress(r)
+	return p != nil && p.Maintenance
+}
+
+// maintenanceError converts ErrMaintenance into the retriable status returned to peers
+func maintenanceError() error {
+	return status.Error(codes.Unavailable, ErrMaintenance.Error())
+}
+
+// isMaintenance reports whether a peer rejected a call because it is in maintenance mode
+func isMaintenance(err error) bool {
+	s, ok := status.FromError(err)
+	return ok && s.Code() == codes.Unavailable && s.Message() == ErrMaintenance.Error()
+}
@@ -0,0 +1,451 @@
+package node
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/admission"
+	"github.com/u-speak/core/config"
+	"github.com/u-speak/core/identity"
+	"github.com/u-speak/core/miner"
+	"github.com/u-speak/core/netmap"
+	"github.com/u-speak/core/peer"
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+	"github.com/u-speak/core/tombstone"
+
+	d "github.com/u-speak/core/node/internal"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	grpcpeer "google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// defaults sets the fields of the configuration to their default tags
+func defaults(v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		f, tag := v.Field(i), v.Type().Field(i).Tag.Get("default")
+		switch {
+		case f.Kind() == reflect.Struct:
+			defaults(f)
+		case tag == "":
+		case f.Kind() == reflect.String:
+			f.SetString(tag)
+		case f.Kind() == reflect.Bool:
+			f.SetBool(tag == "true")
+		case f.Kind() == reflect.Int:
+			i, _ := strconv.ParseInt(tag, 10, 64)
+			f.SetInt(i)
+		case f.Kind() == reflect.Uint64:
+			u, _ := strconv.ParseUint(tag, 10, 64)
+			f.SetUint(u)
+		case f.Kind() == reflect.Float64:
+			fl, _ := strconv.ParseFloat(tag, 64)
+			f.SetFloat(fl)
+		}
+	}
+}
+
+// testNode serves a node with the default configuration and all stores in dir on a random port
+func testNode(t *testing.T, dir string) (*Node, string, func()) {
+	c := config.Configuration{}
+	defaults(reflect.ValueOf(&c).Elem())
+	s := reflect.ValueOf(&c.Storage).Elem()
+	for i := 0; i < s.NumField(); i++ {
+		if f := s.Field(i); f.Kind() == reflect.String {
+			f.SetString(strings.Replace(f.String(), "/var/lib/uspeak", dir, 1))
+		}
+	}
+	c.Storage.MinFree = 0
+	n, err := New(c)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	srv := n.server()
+	go srv.Serve(lis)
+	return n, lis.Addr().String(), func() {
+		srv.Stop()
+		n.Shutdown()
+	}
+}
+
+// misbehavingPeer speaks the peer protocol to a node under test and breaks it on purpose.
+// It announces addr as its listen interface, which the node must not trust to decide whom to hold responsible
+type misbehavingPeer struct {
+	addr   string
+	conn   *grpc.ClientConn
+	client d.DistributionServiceClient
+}
+
+func dialMisbehaving(target, addr string) (*misbehavingPeer, error) {
+	conn, err := grpc.Dial(target, grpc.WithInsecure(),
+		grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(4*MaxMsgSize), grpc.MaxCallRecvMsgSize(MaxMsgSize)))
+	if err != nil {
+		return nil, err
+	}
+	return &misbehavingPeer{addr: addr, conn: conn, client: d.NewDistributionServiceClient(conn)}, nil
+}
+
+func (p *misbehavingPeer) context() context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), originHeader, p.addr)
+}
+
+// push sends the site like a peer relaying it
+func (p *misbehavingPeer) push(s *d.Site) error {
+	_, err := p.client.AddSite(p.context(), s)
+	return err
+}
+
+// splice sends the sites on a splice stream, stalling for the pause before closing it
+func (p *misbehavingPeer) splice(ss []*d.Site, pause time.Duration) error {
+	stream, err := p.client.Splice(p.context())
+	if err != nil {
+		return err
+	}
+	for _, s := range ss {
+		if err := stream.Send(s); err != nil {
+			break
+		}
+	}
+	time.Sleep(pause)
+	_, err = stream.CloseAndRecv()
+	return err
+}
+
+func (p *misbehavingPeer) alive() error {
+	_, err := p.client.Ping(context.Background(), &d.Heartbeat{})
+	return err
+}
+
+// signedPost returns a post signed by a fresh key and mined onto the current tips of the node
+func signedPost(t *testing.T, n *Node, content string) (*post.Post, *d.Site) {
+	return keyedPost(t, n, newKey(t), content)
+}
+
+// newKey returns a fresh armored private key
+func newKey(t *testing.T) string {
+	e, err := openpgp.NewEntity("Peer", "", "peer@example.com", nil)
+	assert.NoError(t, err)
+	buf := bytes.NewBuffer(nil)
+	w, err := armor.Encode(buf, openpgp.PrivateKeyType, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, e.SerializePrivate(w, nil))
+	assert.NoError(t, w.Close())
+	return buf.String()
+}
+
+// keyedPost returns a post signed by the key and mined onto the current tips of the node
+func keyedPost(t *testing.T, n *Node, key, content string) (*post.Post, *d.Site) {
+	p, err := miner.SignPost(key, "", content, time.Now().Unix())
+	assert.NoError(t, err)
+	h, err := p.Hash()
+	assert.NoError(t, err)
+	s := &site.Site{Type: "post", Content: h, Validates: n.Tangle.Tips(), Timestamp: time.Now().Unix()}
+	s.Mine(n.Tangle.Required("post").Weight)
+	ds, err := d.FromObject(&tangle.Object{Site: s, Data: p})
+	assert.NoError(t, err)
+	return p, ds
+}
+
+func TestMisbehavingPeer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "node")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	n, target, stop := testNode(t, dir)
+	defer stop()
+	n.spliceIdle = 200 * time.Millisecond
+	tips := [][]byte{}
+	for _, h := range n.Tangle.Tips() {
+		tips = append(tips, h.Hash().Slice())
+	}
+
+	n.Peers.Seen("victim", "192.0.2.2:6969")
+	spoofer, err := dialMisbehaving(target, "192.0.2.2:6969")
+	assert.NoError(t, err)
+	defer spoofer.conn.Close()
+	err = spoofer.push(&d.Site{Type: "post", Content: make([]byte, 31), Validates: tips})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Zero(t, n.Peers.ByAddress(spoofer.addr).Failures)
+
+	p, err := dialMisbehaving(target, "192.0.2.1:6969")
+	assert.NoError(t, err)
+	defer p.conn.Close()
+	n.Peers.Seen("misbehaving", p.addr)
+	n.authenticate(target, "misbehaving")
+	failures := func() int { return n.Peers.ByAddress(p.addr).Failures }
+	forged, _ := signedPost(t, n, "original")
+	forged.Content = "forged"
+	forgedData, err := forged.Serialize()
+	assert.NoError(t, err)
+	forgedHash, _ := forged.Hash()
+
+	for _, c := range []struct {
+		name     string
+		site     *d.Site
+		code     codes.Code
+		penalize bool
+	}{
+		{"short content hash", &d.Site{Type: "post", Content: make([]byte, 31), Validates: tips}, codes.InvalidArgument, true},
+		{"short validation", &d.Site{Type: "post", Content: make([]byte, 32), Validates: [][]byte{tips[0], {1, 2, 3}}}, codes.InvalidArgument, true},
+		{"unknown type", &d.Site{Type: "block", Content: make([]byte, 32), Validates: tips}, codes.InvalidArgument, true},
+		{"malformed payload", &d.Site{Type: "post", Content: make([]byte, 32), Validates: tips, Data: []byte{0x85, 0xff, 0x00}}, codes.InvalidArgument, true},
+		{"forged signature", &d.Site{Type: "post", Content: forgedHash.Slice(), Validates: tips, Data: forgedData, Timestamp: time.Now().Unix()}, codes.Unauthenticated, true},
+		{"giant message", &d.Site{Type: "image", Content: make([]byte, 32), Validates: tips, Data: make([]byte, MaxMsgSize+1)}, codes.ResourceExhausted, false},
+	} {
+		before := failures()
+		err := p.push(c.site)
+		assert.Equal(t, c.code, status.Code(err), c.name)
+		if c.penalize {
+			assert.Equal(t, before+1, failures(), c.name)
+		} else {
+			assert.Equal(t, before, failures(), c.name)
+		}
+		assert.NoError(t, p.alive(), c.name)
+	}
+
+	before := failures()
+	err = p.splice([]*d.Site{{Type: "post", Content: make([]byte, 32), Validates: tips, Data: []byte{0x85}}}, 0)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, before+1, failures())
+
+	before = failures()
+	err = p.splice(nil, 2*n.spliceIdle)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	assert.Equal(t, before+1, failures())
+	assert.NoError(t, p.alive())
+
+	_, ds := signedPost(t, n, "still accepting sites")
+	assert.NoError(t, p.push(ds))
+	assert.NotNil(t, n.Tangle.Get(ds.Hash()))
+}
+
+func TestFlood(t *testing.T) {
+	dir, err := ioutil.TempDir("", "node")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	n, target, stop := testNode(t, dir)
+	defer stop()
+	n.Admission = admission.New(1, 0, 10*time.Millisecond)
+	p, err := dialMisbehaving(target, "192.0.2.1:6969")
+	assert.NoError(t, err)
+	defer p.conn.Close()
+	n.Peers.Seen("flooding", p.addr)
+
+	release, err := n.Admission.Admit(context.Background())
+	assert.NoError(t, err)
+	_, ds := signedPost(t, n, "flood")
+	err = p.push(ds)
+	assert.True(t, isBusy(err))
+	assert.Zero(t, n.Peers.ByAddress(p.addr).Failures)
+	assert.Nil(t, n.Tangle.Get(ds.Hash()))
+	assert.True(t, isBusy(p.splice([]*d.Site{ds}, 0)))
+	assert.Nil(t, n.Tangle.Get(ds.Hash()))
+
+	release()
+	assert.NoError(t, p.push(ds))
+	assert.NotNil(t, n.Tangle.Get(ds.Hash()))
+	// The claimed origin is not authenticated, so relaying to it is not suppressed
+	assert.False(t, n.Relays.Has(ds.Hash(), p.addr))
+}
+
+func TestFencedByID(t *testing.T) {
+	dir, err := ioutil.TempDir("", "node")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	n, target, stop := testNode(t, dir)
+	defer stop()
+	p, err := dialMisbehaving(target, "192.0.2.1:6969")
+	assert.NoError(t, err)
+	defer p.conn.Close()
+
+	assert.NoError(t, n.ACL.Add(peer.Allow, "friend"))
+	assert.Equal(t, codes.PermissionDenied, status.Code(p.alive()))
+	_, err = p.client.GetInfo(context.Background(), &d.Info{})
+	assert.NotEqual(t, codes.PermissionDenied, status.Code(err))
+
+	n.authenticate("127.0.0.1:6969", "stranger")
+	assert.Equal(t, codes.PermissionDenied, status.Code(p.alive()))
+	n.authenticate("127.0.0.1:6969", "friend")
+	assert.NoError(t, p.alive())
+}
+
+func TestHandshakeReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "node")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, os.Mkdir(dir+"/honest", 0755))
+	assert.NoError(t, os.Mkdir(dir+"/other", 0755))
+	honest, _, stopHonest := testNode(t, dir+"/honest")
+	defer stopHonest()
+	other, target, stopOther := testNode(t, dir+"/other")
+	defer stopOther()
+	p, err := dialMisbehaving(target, target)
+	assert.NoError(t, err)
+	defer p.conn.Close()
+
+	_, err = honest.handshake(p.client, honest.Info(), target)
+	assert.Equal(t, errInvalidIdentity, err)
+	id, err := honest.handshake(p.client, other.Info(), target)
+	assert.NoError(t, err)
+	assert.Equal(t, other.Identity.ID(), id)
+}
+
+func TestTombstoneBeforeTarget(t *testing.T) {
+	dir, err := ioutil.TempDir("", "node")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	n, target, stop := testNode(t, dir)
+	defer stop()
+	p, err := dialMisbehaving(target, "192.0.2.1:6969")
+	assert.NoError(t, err)
+	defer p.conn.Close()
+
+	key := newKey(t)
+	_, removed := keyedPost(t, n, key, "removed before it arrived")
+	tp, err := miner.SignPost(key, "", removed.Hash().String(), time.Now().Unix())
+	assert.NoError(t, err)
+	ts := &tombstone.Tombstone{Post: *tp}
+	th, _ := ts.Hash()
+	s := &site.Site{Type: "tombstone", Content: th, Validates: n.Tangle.Tips(), Timestamp: time.Now().Unix()}
+	s.Mine(n.Tangle.Required("tombstone").Weight)
+	ds, err := d.FromObject(&tangle.Object{Site: s, Data: ts})
+	assert.NoError(t, err)
+	assert.NoError(t, p.push(ds))
+	assert.NoError(t, p.push(removed))
+
+	assert.Nil(t, n.Tangle.Get(removed.Hash()))
+	by, buried := n.Tangle.Buried(removed.Hash())
+	assert.True(t, buried)
+	assert.Equal(t, s.Hash(), by)
+	header, err := p.client.GetSite(context.Background(), &d.SiteRequest{Hash: removed.Hash().Slice()})
+	assert.NoError(t, err)
+	assert.True(t, header.Buried)
+	assert.Empty(t, header.Data)
+	assert.Equal(t, removed.Hash(), header.Hash())
+
+	header.Tip = false
+	assert.Equal(t, codes.InvalidArgument, status.Code(p.push(header)))
+}
+
+func TestOrphanFlood(t *testing.T) {
+	dir, err := ioutil.TempDir("", "node")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	n, target, stop := testNode(t, dir)
+	defer stop()
+	p, err := dialMisbehaving(target, "192.0.2.1:6969")
+	assert.NoError(t, err)
+	defer p.conn.Close()
+
+	orphan := func(parents int, mined bool) *d.Site {
+		_, ds := signedPost(t, n, "orphan")
+		ds.Validates = nil
+		for i := 0; i < parents; i++ {
+			ds.Validates = append(ds.Validates, hash.New([]byte("unknown "+strconv.Itoa(i))).Slice())
+		}
+		required := n.Tangle.Required("post").Weight
+		for (n.Tangle.CanonicalWork(ds.Canonical()) >= required) != mined {
+			ds.Nonce++
+		}
+		return ds
+	}
+	err = p.push(orphan(2, false))
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	err = p.push(orphan(MaxOrphanParents+1, true))
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Zero(t, n.Orphans.Len())
+	assert.NoError(t, p.push(orphan(2, true)))
+	assert.Equal(t, 1, n.Orphans.Len())
+}
+
+func TestPinnedTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "node")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	n, _, stop := testNode(t, dir)
+	defer stop()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	tmpl := &x509.Certificate{SerialNumber: big.NewInt(1), NotBefore: time.Now(), NotAfter: time.Now().Add(time.Hour)}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.NoError(t, err)
+	n.serverCreds = credentials.NewServerTLSFromCert(&tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key})
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	srv := n.server()
+	go srv.Serve(lis)
+	defer srv.Stop()
+	addr := lis.Addr().String()
+
+	ping := func(fingerprint string) error {
+		n.peerOptions[addr] = config.Peer{Address: addr, Fingerprint: fingerprint}
+		conn, err := n.dial(addr)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_, err = d.NewDistributionServiceClient(conn).Ping(ctx, &d.Heartbeat{})
+		return err
+	}
+	sum := sha256.Sum256(der)
+	assert.NoError(t, ping(hex.EncodeToString(sum[:])))
+	assert.Error(t, ping(strings.Repeat("00", sha256.Size)))
+}
+
+func TestGossipFromPeers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "node")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	n, _, stop := testNode(t, dir)
+	defer stop()
+	i, err := identity.Load(dir + "/other.key")
+	assert.NoError(t, err)
+	s := netmap.Stats{ID: i.ID(), Address: "192.0.2.1:6969", Version: "test", LastSeen: time.Unix(time.Now().Unix(), 0), PublicKey: i.PublicKey()}
+	s.Signature = i.Sign(s.Message())
+	ctx := grpcpeer.NewContext(context.Background(), &grpcpeer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 40000}})
+
+	_, err = n.ExchangeStats(ctx, toGossip([]netmap.Stats{s}))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n.Network.Len())
+
+	n.authenticate("192.0.2.1:40000", i.ID())
+	n.Peers.Seen(i.ID(), "192.0.2.1:6969")
+	_, err = n.ExchangeStats(ctx, toGossip([]netmap.Stats{s}))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n.Network.Len())
+
+	n.establish("192.0.2.1:6969", true)
+	_, err = n.ExchangeStats(ctx, toGossip([]netmap.Stats{s}))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n.Network.Len())
+}
@@ -0,0 +1,95 @@
+package node
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/u-speak/core/logging"
+	"github.com/u-speak/core/netmap"
+
+	d "github.com/u-speak/core/node/internal"
+	context "golang.org/x/net/context"
+)
+
+// stats returns the signed statistics of this node
+func (n *Node) stats() netmap.Stats {
+	s := netmap.Stats{
+		ID:        n.Identity.ID(),
+		Address:   n.ListenInterface,
+		Version:   n.Version,
+		Length:    uint64(n.Tangle.Size()),
+		Peers:     uint32(len(n.remotes())),
+		LastSeen:  time.Unix(time.Now().Unix(), 0),
+		PublicKey: n.Identity.PublicKey(),
+	}
+	s.Signature = n.Identity.Sign(s.Message())
+	return s
+}
+
+// ExchangeStats merges the statistics known to the remote and returns the ones known to this node.
+// Statistics are only merged from authenticated peers this node is connected to
+func (n *Node) ExchangeStats(ctx context.Context, g *d.Gossip) (*d.Gossip, error) {
+	if r := n.sender(ctx); r != "" && n.connected(r) {
+		n.Network.Merge(fromGossip(g), time.Now())
+	}
+	n.Network.Merge([]netmap.Stats{n.stats()}, time.Now())
+	return toGossip(n.Network.List(time.Now())), nil
+}
+
+// gossip exchanges statistics with a random peer
+func (n *Node) gossip() {
+	n.Network.Merge([]netmap.Stats{n.stats()}, time.Now())
+	conns := n.conns("")
+	if len(conns) == 0 {
+		return
+	}
+	r := conns[rand.Intn(len(conns))].Address
+	ctx, cancel := context.WithTimeout(context.Background(), n.pingTimeout)
+	defer cancel()
+	conn, err := n.dial(r)
+	if err != nil {
+		logging.Debugf("sync", "Could not gossip with %s: %s", r, err)
+		return
+	}
+	defer conn.Close()
+	g, err := d.NewDistributionServiceClient(conn).ExchangeStats(ctx, toGossip(n.Network.List(time.Now())))
+	if err != nil {
+		logging.Debugf("sync", "Could not gossip with %s: %s", r, err)
+		return
+	}
+	n.Network.Merge(fromGossip(g), time.Now())
+}
+
+func toGossip(ss []netmap.Stats) *d.Gossip {
+	g := &d.Gossip{}
+	for _, s := range ss {
+		g.Stats = append(g.Stats, &d.NodeStats{
+			ID:        s.ID,
+			Address:   s.Address,
+			Version:   s.Version,
+			Length:    s.Length,
+			Peers:     s.Peers,
+			Time:      s.LastSeen.Unix(),
+			PublicKey: s.PublicKey,
+			Signature: s.Signature,
+		})
+	}
+	return g
+}
+
+func fromGossip(g *d.Gossip) []netmap.Stats {
+	ss := []netmap.Stats{}
+	for _, s := range g.Stats {
+		ss = append(ss, netmap.Stats{
+			ID:        s.ID,
+			Address:   s.Address,
+			Version:   s.Version,
+			Length:    s.Length,
+			Peers:     s.Peers,
+			LastSeen:  time.Unix(s.Time, 0),
+			PublicKey: s.PublicKey,
+			Signature: s.Signature,
+		})
+	}
+	return ss
+}
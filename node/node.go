@@ -1,58 +1,203 @@
 package node
 
 import (
-	"encoding/base64"
 	"errors"
+	"fmt"
 	"io"
 	"net"
-	"net/http"
-	"net/url"
+	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
+	"github.com/u-speak/core/admission"
+	"github.com/u-speak/core/alert"
+	"github.com/u-speak/core/anchor"
+	"github.com/u-speak/core/build"
+	"github.com/u-speak/core/cluster"
 	"github.com/u-speak/core/config"
-	"github.com/u-speak/core/img"
-	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/conflict"
+	"github.com/u-speak/core/custody"
+	"github.com/u-speak/core/decision"
+	"github.com/u-speak/core/digest"
+	"github.com/u-speak/core/flags"
+	"github.com/u-speak/core/genesis"
+	"github.com/u-speak/core/hide"
+	"github.com/u-speak/core/hook"
+	"github.com/u-speak/core/identity"
+	"github.com/u-speak/core/journal"
+	"github.com/u-speak/core/keys"
+	"github.com/u-speak/core/kms"
+	"github.com/u-speak/core/lang"
+	"github.com/u-speak/core/logging"
+	"github.com/u-speak/core/media"
+	"github.com/u-speak/core/metrics"
+	"github.com/u-speak/core/netmap"
+	"github.com/u-speak/core/orphan"
+	"github.com/u-speak/core/peer"
+	"github.com/u-speak/core/pin"
+	"github.com/u-speak/core/pipeline"
+	"github.com/u-speak/core/pow"
+	"github.com/u-speak/core/preview"
+	"github.com/u-speak/core/quorum"
+	"github.com/u-speak/core/receipt"
+	"github.com/u-speak/core/recent"
+	"github.com/u-speak/core/recovery"
+	"github.com/u-speak/core/relay"
+	"github.com/u-speak/core/resolver"
+	"github.com/u-speak/core/schedule"
+	"github.com/u-speak/core/sqlindex"
+	"github.com/u-speak/core/submission"
+	"github.com/u-speak/core/tags"
+	"github.com/u-speak/core/tail"
 	"github.com/u-speak/core/tangle"
 	"github.com/u-speak/core/tangle/datastore"
 	"github.com/u-speak/core/tangle/hash"
 	"github.com/u-speak/core/tangle/site"
 	"github.com/u-speak/core/tangle/store"
 	"github.com/u-speak/core/tangle/store/boltstore"
+	"github.com/u-speak/core/tangle/store/metricstore"
+	"github.com/u-speak/core/timeindex"
+	"github.com/u-speak/core/timeline"
+	"github.com/u-speak/core/tsa"
+	"github.com/u-speak/core/upload"
+	"github.com/u-speak/core/validate"
+	"github.com/u-speak/core/watchdog"
 
-	"github.com/jasonlvhit/gocron"
+	"github.com/golang/protobuf/proto"
 	log "github.com/sirupsen/logrus"
 	d "github.com/u-speak/core/node/internal"
 	context "golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 const (
 	// MaxMsgSize specifies the largest packet size for grpc calls
 	MaxMsgSize = 5242880
+	// MaxTrainingSamples is the largest amount of posts the compression dictionary is trained on
+	MaxTrainingSamples = 1000
+	// SpliceIdleTimeout is how long a peer may keep a splice stream open without sending a site
+	SpliceIdleTimeout = 30 * time.Second
+	// originHeader carries the listen interface of the node pushing a site. Receivers identify the sender by its authenticated id instead
+	originHeader = "uspeak-origin"
 )
 
-// Node is a wrapper around the chain. Nodes are the backbone of the network
+// Node is a wrapper around the tangle. Nodes are the backbone of the network
 type Node struct {
 	Tangle           *tangle.Tangle
 	ListenInterface  string
 	Version          string
 	remoteInterfaces map[string]struct{}
+	remoteLock       sync.RWMutex
 	APIAddr          string
-	Hooks            struct {
-		PreAdd string
-	}
+	PreAdd           *hook.Hook
+	Digest           *digest.Digest
+	Alerts           *alert.Evaluator
+	Watchdog         *watchdog.Watchdog
+	SQLIndex         *sqlindex.Index
+	Follows          *timeline.Index
+	Anchors          *anchor.Service
+	Timestamps       *tsa.Service
+	Identity         *identity.Identity
+	Peers            *peer.Table
+	Cluster          *cluster.Cluster
+	Replication      *cluster.Tracker
+	Quorum           *quorum.Quorum
+	Submissions      *submission.Tracker
+	Tail             *tail.Log
+	Health           *peer.Monitor
+	Recent           *recent.Window
+	Previews         *preview.Index
+	Dates            *timeindex.Index
+	Tags             *tags.Index
+	Langs            *lang.Index
+	Keys             *keys.Index
+	Network          *netmap.Map
+	Pins             *pin.Store
+	Custody          *custody.Vault
+	Flags            *flags.Store
+	Receipts         *receipt.Store
+	Genesis          *genesis.File
+	ACL              *peer.ACL
+	Orphans          *orphan.Pool
+	Conflicts        *conflict.Index
+	Relays           *relay.Cache
+	Scheduler        *schedule.Scheduler
+	Decisions        *decision.Log
+	Uploads          *upload.Store
+	Media            *media.Index
+	Indexes          *pipeline.Pipeline
+	Admission        *admission.Controller
+	Hidden           *hide.Store
+	syncErr          error
+	refuseHidden     bool
+	checkpoint       string
+	recentPath       string
+	bootstrap        []config.Peer
+	peerOptions      map[string]config.Peer
+	serverCreds      credentials.TransportCredentials
+	slots            peer.Slots
+	inbound          map[string]bool
+	authenticated    map[string]string
+	tombstones       pendingTombstones
+	fetches          fetches
+	keyMisses        keyMisses
+	pingInterval     time.Duration
+	pingTimeout      time.Duration
+	rotation         time.Duration
+	gossipInterval   time.Duration
+	orphanTTL        time.Duration
+	retention        time.Duration
+	spliceIdle       time.Duration
+	solidifyInterval time.Duration
+	tangleStatsCache tangle.Stats
+	statsLock        sync.RWMutex
+	solidifyRate     int
+	solidification   Solidification
+	solidLock        sync.Mutex
+	preferredSource  string
+	reversePolicy    string
+	maintenance      bool
+	maintenanceSince time.Time
+	inFlight         int
+	maintLock        sync.Mutex
+	maxReverse       int
+	sourceLock       sync.RWMutex
+	resolver         resolver.Resolver
+	subscribers      map[chan *d.Site]struct{}
+	// subHosts counts the subscriptions of each remote host
+	subHosts map[string]int
+	subLock  sync.Mutex
 }
 
 // Status is used for reporting this nodes configuration to other nodes
 type Status struct {
-	Address        string      `json:"address"`
-	Version        string      `json:"version"`
-	Length         uint64      `json:"length"`
-	Connections    []string    `json:"connections"`
-	Recomendations []string    `json:"recomendations"`
-	Hashes         []hash.Hash `json:"-"`
-	HashDiff       HashDiff    `json:"-"`
+	ID             string           `json:"id"`
+	Address        string           `json:"address"`
+	Role           string           `json:"role"`
+	Version        string           `json:"version"`
+	Length         uint64           `json:"length"`
+	Connections    []string         `json:"connections"`
+	Recomendations []string         `json:"recomendations"`
+	Disk           []watchdog.Usage `json:"disk"`
+	Peers          []peer.Peer      `json:"peers"`
+	Unhealthy      []string         `json:"unhealthy"`
+	Replication    *cluster.Lag     `json:"replication,omitempty"`
+	Checkpoints    []hash.Hash      `json:"missing_checkpoints,omitempty"`
+	Tangle         tangle.Stats     `json:"tangle"`
+	Orphans        int              `json:"orphans"`
+	Solidification Solidification   `json:"solidification"`
+	Build          build.Info       `json:"build"`
+	Maintenance    Maintenance      `json:"maintenance"`
+	Hashes         []hash.Hash      `json:"-"`
+	HashDiff       HashDiff         `json:"-"`
 }
 
 // HashDiff stores the diff between two tangles
@@ -67,41 +212,316 @@ func New(c config.Configuration) (*Node, error) {
 		ListenInterface:  c.NodeNetwork.Interface + ":" + strconv.Itoa(c.NodeNetwork.Port),
 		Version:          c.Version,
 		remoteInterfaces: make(map[string]struct{}),
-		Hooks:            c.Hooks,
 		APIAddr:          c.Web.API.PublicEndpoint,
+		Watchdog:         watchdog.New(c.Storage.MinFree*1024*1024, c.Storage.TanglePath, c.Storage.DataPath),
+		Follows:          timeline.NewIndex(),
+		Peers:            peer.NewTable(),
+		Health:           peer.NewMonitor(),
+		Recent:           recent.New(c.NodeNetwork.ReplayWindow),
+		Previews:         preview.NewIndex(c.Preview.Length),
+		Dates:            timeindex.NewIndex(),
+		Tags:             tags.NewIndex(),
+		Langs:            lang.NewIndex(),
+		Keys:             keys.NewIndex(),
+		Network:          netmap.New(time.Duration(10*c.NodeNetwork.Gossip)*time.Second, netmap.MaxNodes),
+		Tail:             tail.New(c.Tail.Buffer),
+		subscribers:      make(map[chan *d.Site]struct{}),
+		subHosts:         make(map[string]int),
+		checkpoint:       c.Storage.CheckpointPath,
+		recentPath:       c.Storage.RecentPath,
+		bootstrap:        c.NodeNetwork.Bootstrap,
+		peerOptions:      make(map[string]config.Peer),
+		authenticated:    make(map[string]string),
+		slots:            peer.Slots{MaxInbound: c.NodeNetwork.MaxInbound, MaxOutbound: c.NodeNetwork.MaxOutbound, MaxPerSubnet: c.NodeNetwork.MaxPerSubnet},
+		rotation:         time.Duration(c.NodeNetwork.Rotation) * time.Second,
+		gossipInterval:   time.Duration(c.NodeNetwork.Gossip) * time.Second,
+		Orphans:          orphan.New(c.NodeNetwork.Orphans),
+		orphanTTL:        time.Duration(c.NodeNetwork.OrphanTTL) * time.Second,
+		retention:        time.Duration(c.Policy.Retention) * 24 * time.Hour,
+		spliceIdle:       SpliceIdleTimeout,
+		Decisions:        decision.New(c.Decisions.Size),
+		Admission:        admission.New(c.Admission.Workers, c.Admission.Queue, time.Duration(c.Admission.Wait)*time.Second),
+		Relays:           relay.New(time.Duration(c.NodeNetwork.RelayTTL)*time.Second, relay.MaxSites),
+		solidifyRate:     c.NodeNetwork.SolidifyRate,
+		solidifyInterval: time.Duration(c.NodeNetwork.SolidifyInterval) * time.Second,
+		inbound:          make(map[string]bool),
+		reversePolicy:    c.NodeNetwork.Reverse,
+		maxReverse:       c.NodeNetwork.MaxReverse,
+		pingInterval:     time.Duration(c.NodeNetwork.PingInterval) * time.Second,
+		pingTimeout:      time.Duration(c.NodeNetwork.PingTimeout) * time.Second,
+		resolver:         resolver.New(c.NodeNetwork.DoH),
+	}
+	for _, p := range c.NodeNetwork.Bootstrap {
+		n.peerOptions[p.Address] = p
+	}
+	if c.NodeNetwork.TLSCert != "" {
+		creds, err := credentials.NewServerTLSFromFile(c.NodeNetwork.TLSCert, c.NodeNetwork.TLSKey)
+		if err != nil {
+			return n, err
+		}
+		n.serverCreds = creds
+	}
+	id, err := identity.Open(identity.Options{
+		Path:          c.Storage.IdentityPath,
+		AgeIdentity:   c.Identity.AgeIdentity,
+		AgePassphrase: c.Identity.AgePassphrase,
+		Token:         identity.Token(c.Identity.Token),
+	})
+	if err != nil {
+		return nil, err
+	}
+	n.Identity = id
+	n.ACL, err = peer.NewACL(c.NodeNetwork.Allow, c.NodeNetwork.Deny)
+	if err != nil {
+		return nil, err
+	}
+	cl, err := cluster.New(c.Cluster.Role, c.Cluster.Writer, c.Cluster.QueueSize)
+	if err != nil {
+		return nil, err
+	}
+	n.Cluster = cl
+	if cl.ReadOnly() {
+		n.Replication = &cluster.Tracker{}
 	}
-	bs, err := boltstore.New(store.Options{Path: c.Storage.TanglePath})
+	noSync := false
+	switch c.Storage.Durability {
+	case "full":
+	case "relaxed":
+		noSync = true
+	default:
+		return nil, errors.New("Unknown durability " + c.Storage.Durability)
+	}
+	switch c.NodeNetwork.Reverse {
+	case ReverseOff, ReverseVerify, ReverseOn:
+	default:
+		return nil, errors.New("Unknown reverse connection policy " + c.NodeNetwork.Reverse)
+	}
+	var gen []*site.Site
+	algorithm := pow.Default
+	if c.NodeNetwork.Genesis != "" {
+		n.Genesis, err = genesis.Load(c.NodeNetwork.Genesis)
+		if err != nil {
+			return nil, err
+		}
+		gen = n.Genesis.Sites()
+		if algorithm, err = n.Genesis.Algorithm(); err != nil {
+			return nil, err
+		}
+	}
+	bs, err := boltstore.New(store.Options{Path: c.Storage.TanglePath, NoSync: noSync})
 	if err != nil {
 		return nil, err
 	}
-	tngl, err := tangle.New(tangle.Options{Store: bs, DataPath: c.Storage.DataPath})
+	key, err := kms.Load(c.Storage.EncryptionKey, c.Storage.KeyCommand)
+	if err != nil {
+		return nil, err
+	}
+	var jnl *journal.Journal
+	if c.Storage.JournalPath != "" {
+		if jnl, err = journal.Open(c.Storage.JournalPath, noSync); err != nil {
+			return nil, err
+		}
+	}
+	slow := time.Duration(c.Storage.SlowLog) * time.Millisecond
+	data := &metricstore.Observer{Name: "data", Slow: slow}
+	tngl, err := tangle.New(tangle.Options{
+		Store:         metricstore.New("tangle", bs, slow),
+		DataPath:      c.Storage.DataPath,
+		Compression:   c.Storage.Compression,
+		EncryptionKey: key,
+		Observe:       data.Observe,
+		NoSync:        noSync,
+		Genesis:       gen,
+		MinWeight:     c.NodeNetwork.MinWeight,
+		Requirements:  requirements(c.Requirements),
+		PoW:           algorithm,
+		MaxTipAge:     time.Duration(c.NodeNetwork.MaxTipAge) * time.Minute,
+		MaxClockSkew:  time.Duration(c.NodeNetwork.MaxClockSkew) * time.Second,
+		Journal:       jnl,
+	})
 	n.Tangle = tngl
-	return n, err
+	if err != nil {
+		return n, err
+	}
+	if c.Hooks.PreAdd != "" {
+		n.PreAdd = hook.New("preadd", c.Hooks.PreAdd, n.APIAddr, c.Hooks.SendBundle, algorithm,
+			time.Duration(c.Hooks.Timeout)*time.Second, c.Hooks.Queue, hook.NewBreaker(c.Hooks.FailureThreshold, time.Duration(c.Hooks.Cooldown)*time.Second))
+	}
+	cps := []hash.Hash{}
+	for _, s := range c.NodeNetwork.Checkpoints {
+		var h hash.Hash
+		if err := h.UnmarshalText([]byte(s)); err != nil {
+			return n, errors.New("Invalid checkpoint " + s + ": " + err.Error())
+		}
+		cps = append(cps, h)
+	}
+	tngl.SetCheckpoints(cps)
+	for _, h := range tngl.MissingCheckpoints() {
+		log.Warnf("Checkpoint %s is not part of the tangle yet", h)
+	}
+	if c.Storage.Compression == datastore.Zstd && !tngl.Compressed() {
+		go func() {
+			if err := tngl.TrainCompression(MaxTrainingSamples); err != nil {
+				log.Infof("Not training a compression dictionary yet: %s", err)
+				return
+			}
+			log.Info("Trained compression dictionary for payloads")
+		}()
+	}
+	n.Alerts = alert.New(c.Alerts.Webhook, time.Duration(c.Alerts.Timeout)*time.Second, n.alertRules(c)...)
+	n.restore()
+	n.Conflicts = conflict.NewIndex(n.weightOf)
+	tngl.OnAdd(n.broadcast)
+	tngl.OnAdd(n.Tail.Add)
+	tngl.OnAdd(n.bury)
+	tngl.OnAdd(func(o *tangle.Object) { n.Recent.Seen(o.Site.Hash()) })
+	tngl.OnAdd(n.attach)
+	if c.SQLIndex.Enabled {
+		n.SQLIndex, err = sqlindex.New(c.Storage.SQLIndexPath, c.SQLIndex.MaxRows, time.Duration(c.SQLIndex.Timeout)*time.Second, tngl.Work)
+		if err != nil {
+			return n, err
+		}
+	}
+	n.Submissions = submission.New(n.processSubmission, c.Submissions.Workers, c.Submissions.Queue, time.Duration(c.Submissions.Retention)*time.Minute)
+	n.Uploads, err = upload.New(c.Storage.UploadPath, MaxMsgSize, c.Uploads.Sessions, time.Duration(c.Uploads.TTL)*time.Minute)
+	if err != nil {
+		return n, err
+	}
+	if c.Quorum.Enabled {
+		n.Quorum, err = quorum.New(c.Quorum.Size, c.Quorum.Peers, time.Duration(c.Quorum.Timeout)*time.Minute)
+		if err != nil {
+			return n, err
+		}
+	}
+	if c.Anchor.Enabled {
+		n.Anchors, err = anchor.New(c, tngl)
+		if err != nil {
+			return n, err
+		}
+	}
+	n.Pins, err = pin.New(c.Storage.PinPath, c.Pins.Quota)
+	if err != nil {
+		return n, err
+	}
+	n.Hidden, err = hide.New(c.Storage.HidePath)
+	if err != nil {
+		return n, err
+	}
+	n.refuseHidden = c.Hide.Refuse
+	n.resolveHidden()
+	n.Tangle.OnAdd(func(o *tangle.Object) { n.Hidden.Resolve(o.Site.Hash(), o.Site.Content) })
+	n.Receipts, err = receipt.New(n.Identity, c.Storage.ReceiptPath)
+	if err != nil {
+		return n, err
+	}
+	tngl.OnAdd(n.Receipts.Add)
+	if c.TSA.Enabled {
+		n.Timestamps, err = tsa.New(c.TSA.URL, c.Storage.TSAPath, time.Duration(c.TSA.Timeout)*time.Second, c.TSA.Queue)
+		if err != nil {
+			return n, err
+		}
+		tngl.OnAdd(n.Timestamps.Add)
+	}
+	if c.Custody.Enabled {
+		n.Custody, err = custody.New(c.Storage.CustodyPath, time.Duration(c.Custody.Interval)*time.Second)
+		if err != nil {
+			return n, err
+		}
+	}
+	if c.Media.Enabled {
+		n.Media, err = media.New(c.Storage.MediaPath, media.Options{
+			Widths:    c.Media.Widths,
+			Quality:   c.Media.Quality,
+			MaxPixels: c.Media.MaxPixels,
+		})
+		if err != nil {
+			return n, err
+		}
+	}
+	if c.Flags.Classifier != "none" {
+		var cl flags.Classifier = flags.Heuristic{}
+		if c.Flags.Classifier == "webhook" {
+			cl = flags.NewWebhook(c.Flags.Webhook, algorithm, time.Duration(c.Flags.Timeout)*time.Second)
+		}
+		n.Flags, err = flags.New(cl, c.Storage.FlagPath, c.Flags.Queue)
+		if err != nil {
+			return n, err
+		}
+	}
+	n.indexes()
+	for s, hs := range n.Indexes.Sync(tngl).Missing {
+		log.Infof("Indexed %d sites missing from the %s index", len(hs), s)
+	}
+	n.resolveConflicts()
+	n.updateStats()
+	if c.Digest.Enabled {
+		n.Digest, err = digest.New(c, tngl)
+		if err != nil {
+			return n, err
+		}
+	}
+	recovered, err := tngl.Recover()
+	if err != nil {
+		log.Errorf("Could not recover the journal, continuing without the remaining entries: %s", err)
+	}
+	if recovered > 0 {
+		log.Warnf("Recovered %d sites from the journal which were not completely stored before the last shutdown", recovered)
+	}
+	return n, n.schedule(c)
 }
 
 // Status returns the current running configuration of the node
 func (n *Node) Status() Status {
-	cons := []string{}
-	for k := range n.remoteInterfaces {
-		cons = append(cons, k)
-	}
+	cons := n.remotes()
 	recs := []string{}
 	for _, s := range n.Tangle.RecommendTips() {
 		recs = append(recs, s.Hash().String())
 	}
+	var lag *cluster.Lag
+	if n.Replication != nil {
+		l := n.Replication.Lag()
+		lag = &l
+	}
 	return Status{
+		Replication:    lag,
+		ID:             n.Identity.ID(),
+		Peers:          n.Peers.List(),
+		Unhealthy:      n.Health.Unhealthy(),
 		Address:        n.ListenInterface,
+		Role:           n.Cluster.Role,
 		Length:         uint64(n.Tangle.Size()),
 		Connections:    cons,
 		Version:        n.Version,
 		Hashes:         n.Tangle.Hashes(),
 		Recomendations: recs,
+		Disk:           n.Watchdog.Usage(),
+		Checkpoints:    n.Tangle.MissingCheckpoints(),
+		Tangle:         n.tangleStats(),
+		Orphans:        n.Orphans.Len(),
+		Solidification: n.solidificationStatus(),
+		Build:          build.Get(n.Version),
+		Maintenance:    n.Maintenance(),
 	}
 }
 
+// tangleStats returns the statistics of the tangle computed by the last cron run
+func (n *Node) tangleStats() tangle.Stats {
+	n.statsLock.RLock()
+	defer n.statsLock.RUnlock()
+	return n.tangleStatsCache
+}
+
+// updateStats recomputes the statistics of the tangle. Walking the whole tangle is too slow for every status request
+func (n *Node) updateStats() {
+	s := n.Tangle.Stats()
+	n.statsLock.Lock()
+	n.tangleStatsCache = s
+	n.statsLock.Unlock()
+}
+
 // RemoteStatus returns the status of a connected remote
 func (n *Node) RemoteStatus(s string) (*Status, error) {
-	conn, err := dial(s)
+	conn, err := n.dial(s)
 	if err != nil {
 		return nil, err
 	}
@@ -118,21 +538,27 @@ func (n *Node) RemoteStatus(s string) (*Status, error) {
 	a, d := hash.Diff(n.Tangle.Hashes(), hs)
 	return &Status{
 		Version:     i.Version,
+		Build:       build.Info{Version: i.Version, Commit: i.Commit, Date: i.BuildDate, GoVersion: i.GoVersion, Protocol: int(i.Protocol)},
 		Length:      i.Length,
 		Connections: i.Connections,
 		Address:     i.ListenInterface,
 		Hashes:      hs,
 		HashDiff:    HashDiff{Additions: a, Deletions: d},
+		Tangle: tangle.Stats{
+			Size:          int(i.Length),
+			Tips:          int(i.Tips),
+			AverageWeight: i.AverageWeight,
+			AverageDepth:  i.AverageDepth,
+			MaxDepth:      int(i.MaxDepth),
+		},
 	}, nil
 }
 
-// Info returns the serializable info struct
+// Info returns the serializable info struct.
+// Peers prove their identity by answering a challenge, the signed address is only kept for older peers
 func (n *Node) Info() *d.Info {
 	s := n.Status()
-	cons := []string{}
-	for k := range n.remoteInterfaces {
-		cons = append(cons, k)
-	}
+	cons := n.remotes()
 	hs := [][]byte{}
 	for _, h := range n.Tangle.Hashes() {
 		hs = append(hs, h.Slice())
@@ -143,14 +569,32 @@ func (n *Node) Info() *d.Info {
 		Version:         n.Version,
 		Connections:     cons,
 		Hashes:          hs,
+		PublicKey:       n.Identity.PublicKey(),
+		Signature:       n.Identity.Sign([]byte(s.Address)),
+		Tips:            uint32(s.Tangle.Tips),
+		AverageWeight:   s.Tangle.AverageWeight,
+		AverageDepth:    s.Tangle.AverageDepth,
+		MaxDepth:        uint32(s.Tangle.MaxDepth),
+		Commit:          s.Build.Commit,
+		BuildDate:       s.Build.Date,
+		GoVersion:       s.Build.GoVersion,
+		Protocol:        uint32(s.Build.Protocol),
+		Maintenance:     s.Maintenance.Enabled,
 	}
 }
 
-// GetInfo is a all purpose status request
+// GetInfo is a all purpose status request.
+// The identity of the caller is not proven by the request, it is only recorded once the connection back to it completed a handshake
 func (n *Node) GetInfo(ctx context.Context, r *d.Info) (*d.Info, error) {
-	if _, ok := n.remoteInterfaces[r.ListenInterface]; !ok && n.ListenInterface != r.ListenInterface {
+	if len(r.PublicKey) == 0 && !n.ACL.Allowed(r.ListenInterface, "") {
+		return nil, status.Error(codes.Unauthenticated, peer.ErrDenied.Error())
+	}
+	self := len(r.PublicKey) > 0 && identity.IDOf(r.PublicKey) == n.Identity.ID()
+	if !n.connected(r.ListenInterface) && n.ListenInterface != r.ListenInterface && !self {
 		log.Infof("Establishing reverse connection with %s", r.ListenInterface)
-		n.Connect(r.ListenInterface)
+		if err := n.reverse(ctx, r); err != nil {
+			log.Infof("Not connecting back to %s: %s", r.ListenInterface, err)
+		}
 	}
 	return n.Info(), nil
 }
@@ -162,68 +606,137 @@ func (n *Node) Run() {
 	if err != nil {
 		log.Errorf("Could not listen on %s: %s", n.ListenInterface, err)
 	}
-	// Set MsgSize to 5MB
-	grpcServer := grpc.NewServer(grpc.MaxRecvMsgSize(MaxMsgSize), grpc.MaxRecvMsgSize(MaxMsgSize))
-	d.RegisterDistributionServiceServer(grpcServer, n)
-
-	log.Info("Starting cronjobs")
-	go n.startCron()
+	grpcServer := n.server()
+	if n.Cluster.ReadOnly() {
+		go n.Replicate()
+	} else if n.Cluster.Forwarding() {
+		go func() {
+			if err := n.Connect(n.Cluster.Writer); err != nil {
+				log.Errorf("Could not connect to writer %s: %s", n.Cluster.Writer, err)
+			}
+		}()
+	}
+	go func() {
+		n.Bootstrap()
+		n.Solidify()
+	}()
+	go n.heartbeat()
+	log.Info("Starting scheduler")
+	n.Scheduler.Start()
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		<-sig
+		log.Info("Shutting down")
+		n.Scheduler.Stop()
+		grpcServer.GracefulStop()
+		if err := n.Shutdown(); err != nil {
+			log.Errorf("Shutdown failed: %s", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}()
 	log.Fatal(grpcServer.Serve(lis))
 }
 
-func (n *Node) startCron() {
-	gocron.Every(1).Minute().Do(func() {
-		for r := range n.remoteInterfaces {
-			s, err := n.RemoteStatus(r)
-			if err != nil {
-				log.Error(err)
-				continue
-			}
-			if len(s.HashDiff.Additions) == 0 && len(s.HashDiff.Deletions) == 0 {
-				continue
-			}
-			err = n.Merge(r)
-			if err != nil {
-				log.Error(err)
-			}
+// server returns the gRPC server answering other nodes
+func (n *Node) server() *grpc.Server {
+	// Set MsgSize to 5MB
+	opts := []grpc.ServerOption{grpc.MaxRecvMsgSize(MaxMsgSize), grpc.MaxRecvMsgSize(MaxMsgSize), serverKeepalive, serverEnforce,
+		grpc.UnaryInterceptor(chainUnary(recovery.Unary("node"), n.unaryACL)), grpc.StreamInterceptor(chainStream(recovery.Stream("node"), n.streamACL))}
+	if n.serverCreds != nil {
+		opts = append(opts, grpc.Creds(n.serverCreds))
+	}
+	s := grpc.NewServer(opts...)
+	d.RegisterDistributionServiceServer(s, n)
+	return s
+}
+
+// synchronize merges with every sync source which differs from this node. No new syncs are started in maintenance mode
+func (n *Node) synchronize() {
+	done, err := n.begin()
+	if err != nil {
+		logging.Debugf("sync", "Skipping synchronisation in maintenance mode")
+		return
+	}
+	defer done()
+	n.syncErr = nil
+	for _, r := range n.sources() {
+		hd, err := n.Compare(r)
+		if err != nil {
+			log.Error(err)
+			n.syncErr = err
+			continue
 		}
-	})
-	<-gocron.Start()
+		if len(hd.Additions) == 0 && len(hd.Deletions) == 0 {
+			continue
+		}
+		err = n.Merge(r)
+		if err != nil {
+			log.Error(err)
+			n.syncErr = err
+			n.Peers.Failed(r)
+			continue
+		}
+		n.Peers.Synced(r)
+	}
 }
 
-func (n *Node) connect(remote string) error {
-	if _, ok := n.remoteInterfaces[remote]; ok {
+func (n *Node) connect(remote string, inbound bool) error {
+	if n.ACL.Denied(remote, "") {
+		return peer.ErrDenied
+	}
+	if !n.reserve(remote) {
 		return errors.New("Attempted to add an allready established interface")
 	}
-	n.remoteInterfaces[remote] = struct{}{}
-	conn, err := dial(remote)
+	conn, err := n.dial(remote)
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 	client := d.NewDistributionServiceClient(conn)
-	_, err = client.GetInfo(context.Background(), n.Info())
+	i, err := client.GetInfo(context.Background(), n.Info())
+	id := ""
+	if err == nil {
+		id, err = n.handshake(client, i, remote)
+	}
+	if err == nil {
+		err = n.admit(remote, inbound)
+	}
 	if err != nil {
-		delete(n.remoteInterfaces, remote)
+		n.release(remote)
 		return err
 	}
-	n.remoteInterfaces[remote] = struct{}{}
+	n.establish(remote, inbound)
+	if id != "" {
+		n.authenticate(remote, id)
+	}
 	log.Infof("Added connection %s", remote)
 	return nil
 }
 
 // Connect connects to a new remote
 func (n *Node) Connect(r string) error {
+	return n.open(r, false)
+}
+
+func (n *Node) open(r string, inbound bool) error {
 	s := strings.Split(r, ":")
 	port := s[1]
 	addr := s[0]
-	i, err := net.LookupIP(addr)
+	i, err := n.resolver.LookupIP(addr)
 	if err != nil {
 		return err
 	}
+	opts, hasOpts := n.peerOption(r)
 	for _, ip := range i {
 		if ip.To4() != nil {
-			err := n.connect(ip.String() + ":" + port)
+			if hasOpts {
+				n.remoteLock.Lock()
+				n.peerOptions[ip.String()+":"+port] = opts
+				n.remoteLock.Unlock()
+			}
+			err := n.connect(ip.String()+":"+port, inbound)
 			if err != nil {
 				log.Error(err)
 			}
@@ -236,101 +749,300 @@ func (n *Node) Connect(r string) error {
 
 // Submit is called whenever a new site is submitted to the network
 func (n *Node) Submit(o *tangle.Object) error {
+	if n.InMaintenance() {
+		return ErrMaintenance
+	}
+	_, err := n.submit(o)
+	return err
+}
+
+// submit hands the site to the writer, the quorum or the connected nodes and returns the amount of nodes it was sent to
+func (n *Node) submit(o *tangle.Object) (int, error) {
+	defer n.track()()
+	if err := n.Watchdog.Writable(); err != nil {
+		return 0, err
+	}
+	if n.Cluster.Forwarding() {
+		log.Infof("Forwarding site %s to writer %s", o.Site.Hash(), n.Cluster.Writer)
+		ds, err := d.FromObject(o)
+		if err != nil {
+			return 0, err
+		}
+		if err := n.pushTo(n.Cluster.Writer, ds); err != nil {
+			return 0, err
+		}
+		return 1, nil
+	}
+	if n.Quorum != nil {
+		if err := n.Tangle.Validate(o); err != nil {
+			return 0, err
+		}
+		if err := n.Quorum.Propose(o); err != nil {
+			return 0, err
+		}
+		log.Infof("Proposing site %s to %d trusted peers", o.Site.Hash(), len(n.Quorum.Peers()))
+		go n.propose(o)
+		return 0, nil
+	}
 	log.Infof("Pushing site %s to network", o.Site.Hash())
-	return n.Push(o)
+	return n.push(o)
+}
+
+// SubmitAsync queues the site for validation and submission by the submission workers.
+// validate is run before the site is checked against the tangle and may be nil
+func (n *Node) SubmitAsync(o *tangle.Object, validate func() error) (*submission.Submission, error) {
+	if n.InMaintenance() {
+		return nil, ErrMaintenance
+	}
+	if err := n.Watchdog.Writable(); err != nil {
+		return nil, err
+	}
+	return n.Submissions.Submit(o, validate)
+}
+
+func (n *Node) processSubmission(o *tangle.Object, s *submission.Stage) error {
+	if err := n.Tangle.Validate(o); err != nil {
+		return err
+	}
+	s.Validated()
+	peers, err := n.submit(o)
+	if err != nil {
+		return err
+	}
+	s.Accepted(peers)
+	return nil
 }
 
 // Push sends a site to all connected nodes
 func (n *Node) Push(o *tangle.Object) error {
+	_, err := n.push(o)
+	return err
+}
+
+func (n *Node) push(o *tangle.Object) (int, error) {
 	ds, err := d.FromObject(o)
 	if err != nil {
-		return err
+		return 0, err
 	}
-	for r := range n.remoteInterfaces {
-		conn, err := dial(r)
-		if err != nil {
-			log.Error(err)
+	peers := 0
+	h := o.Site.Hash()
+	if n.refused(h) {
+		logging.Debugf("node", "Not relaying hidden site %s", h)
+		return 0, nil
+	}
+	for _, r := range n.remotes() {
+		if !n.Health.Healthy(r) {
+			logging.Debugf("node", "Skipping dead peer %s", r)
 			continue
 		}
-		defer conn.Close()
-		client := d.NewDistributionServiceClient(conn)
-		_, err = client.AddSite(context.Background(), ds)
-		if err != nil {
+		if n.draining(r) {
+			logging.Debugf("node", "Skipping peer %s in maintenance mode", r)
+			continue
+		}
+		if n.Relays.Has(h, r) {
+			logging.Debugf("node", "Skipping peer %s which already has %s", r, h)
+			metrics.RelaySuppressed.Inc()
+			continue
+		}
+		if err := n.pushTo(r, ds); err != nil {
+			if isMaintenance(err) {
+				log.Infof("Peer %s entered maintenance mode", r)
+				n.Peers.SetMaintenance(r, true)
+				continue
+			}
+			if isBusy(err) {
+				logging.Debugf("node", "Peer %s is busy, leaving %s to the next sync", r, h)
+				continue
+			}
 			log.Error(err)
+			n.Health.Missed(r)
+			continue
 		}
+		n.Relays.Mark(h, r, time.Now())
+		peers++
 	}
-	return nil
+	return peers, nil
+}
+
+func (n *Node) pushTo(r string, ds *d.Site) error {
+	conn, err := n.dial(r)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	client := d.NewDistributionServiceClient(conn)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), originHeader, n.ListenInterface)
+	_, err = client.AddSite(ctx, ds)
+	n.throttle(r, proto.Size(ds))
+	return err
 }
 
 // AddSite receives a sent Site from other node
 func (n *Node) AddSite(ctx context.Context, s *d.Site) (*d.SuccessReturn, error) {
-	o, err := n.toObject(s)
+	if n.Cluster.ReadOnly() {
+		return nil, status.Error(codes.FailedPrecondition, cluster.ErrReadOnly.Error())
+	}
+	if err := n.Watchdog.Writable(); err != nil {
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	}
+	done, err := n.begin()
 	if err != nil {
+		return nil, maintenanceError()
+	}
+	defer done()
+	// Only authenticated peers are remembered, a claimed origin could suppress relaying to any peer
+	r := n.sender(ctx)
+	if r != "" {
+		n.Relays.Mark(s.Hash(), r, time.Now())
+	}
+	release, err := n.Admission.Admit(ctx)
+	if err != nil {
+		return nil, busyError(err)
+	}
+	defer release()
+	err = siteError(n.receive(s, true, decision.SourcePeer))
+	n.penalize(r, err)
+	return &d.SuccessReturn{}, err
+}
+
+// siteError converts the reason a pushed site was rejected to a gRPC status, so peers can tell malformed sites,
+// forged signatures and sites which do not fit the local tangle apart
+func siteError(err error) error {
+	if err == nil {
+		return nil
+	}
+	c := codes.Unknown
+	switch err.(type) {
+	case malformed:
+		c = codes.InvalidArgument
+	// The required weight is configured by every node, so peers can not be blamed for sites below it
+	case *tangle.WeightError:
+		c = codes.FailedPrecondition
+	}
+	switch err {
+	case validate.ErrNonce, validate.ErrType, validate.ErrHashLength, validate.ErrTimestamp, validate.ErrPublicKey,
+		tangle.ErrTooFewValidations, tangle.ErrNoTimestamp:
+		c = codes.InvalidArgument
+	case validate.ErrSignature:
+		c = codes.Unauthenticated
+	case tangle.ErrNotValidating, tangle.ErrBelowCheckpoint, tangle.ErrStale, tangle.ErrClockSkew:
+		c = codes.FailedPrecondition
+	case cluster.ErrReadOnly:
+		c = codes.FailedPrecondition
+	}
+	return status.Error(c, err.Error())
+}
+
+// receive adds a site sent by a peer. Sites validating unknown sites are kept as orphans until their parents arrive.
+// Every attempt is recorded in the decision log under the given source
+func (n *Node) receive(s *d.Site, tip bool, source string) error {
+	tr := n.Decisions.Begin(source)
+	tr.SetHash(s.Hash())
+	if err := s.Validate(); !tr.Check("fields", err) {
 		log.Error(err)
-		return nil, err
+		tr.Reject(err)
+		return err
 	}
-	log.Debugf("Received Site %s", o.Site.Hash())
-	if n.Hooks.PreAdd != "" {
-		u, err := url.Parse(n.Hooks.PreAdd)
-		if err != nil {
-			log.Errorf("Error running PreAdd hook: %s", err.Error())
-		}
-		q := u.Query()
-		q.Add("hash", base64.URLEncoding.EncodeToString(o.Site.Hash().Slice()))
-		q.Add("pub", n.APIAddr)
-		u.RawQuery = q.Encode()
-		log.Debugf("Calling PreAdd Hook with URL: %s", u.String())
-		_, err = http.Get(u.String())
-		if err != nil {
-			log.Errorf("Error running PreAdd hook: %s", err.Error())
+	// Pushed sites are new, only peers synchronising their tangle send sites whose payload was removed
+	if source == decision.SourcePeer && s.Buried {
+		err := malformed{errBuriedPush}
+		tr.Check("payload", err)
+		tr.Reject(err)
+		return err
+	}
+	// Pushed sites are checked before they are kept as orphans, sites fetched while synchronising are trusted like the tangle they come from
+	if source == decision.SourcePeer {
+		if err := verifySignature(s); !tr.Check("signature", err) {
+			log.Warnf("Rejecting site %s: %s", s.Hash(), err)
+			tr.Reject(err)
+			return err
 		}
 	}
-	err = n.Tangle.Inject(o, true)
-	if err != nil {
+	if ms := n.missing(s); len(ms) > 0 {
+		tr.Check("parents", fmt.Errorf("Missing %d parents", len(ms)))
+		if err := n.adopt(s, tip, ms); !tr.Check("orphan", err) {
+			tr.Reject(err)
+			return err
+		}
+		tr.Finish(decision.Orphaned, nil)
+		return nil
+	}
+	tr.Check("parents", nil)
+	o, err := n.toObject(s)
+	if !tr.Check("payload", err) {
+		log.Error(err)
+		tr.Reject(err)
+		return err
+	}
+	if n.Recent.Contains(o.Site.Hash()) {
+		logging.Debugf("node", "Dropping replayed site %s", o.Site.Hash())
+		tr.Finish(decision.Duplicate, nil)
+		return nil
+	}
+	// Pushed sites are new, sites fetched while synchronising may have been mined long ago
+	if source == decision.SourcePeer && n.Tangle.GetSite(o.Site.Hash()) == nil {
+		if err := n.Tangle.Fresh(o.Site, time.Now()); !tr.Check("fresh", err) {
+			tr.Reject(err)
+			return err
+		}
+	}
+	logging.Debugf("node", "Received Site %s", o.Site.Hash())
+	if n.PreAdd != nil && o.Data != nil {
+		n.PreAdd.Fire(o)
+		tr.Check("preadd", nil)
+	}
+	err = n.inject(o, tip)
+	if !tr.Check("tangle", err) {
 		log.Errorf("Failed to add site: %s", err)
+		tr.Reject(err)
 	} else {
 		log.Infof("Successfully added site: %s", o.Site.Hash())
+		tr.Finish(decision.Accepted, nil)
 	}
-	return &d.SuccessReturn{}, err
+	return err
 }
 
 // Merge requests to merge with a remote
 func (n *Node) Merge(r string) error {
-	s, err := n.RemoteStatus(r)
+	hd, err := n.Compare(r)
 	if err != nil {
 		return err
 	}
-	if len(s.HashDiff.Additions) == 0 && len(s.HashDiff.Deletions) == 0 {
+	if len(hd.Additions) == 0 && len(hd.Deletions) == 0 {
 		return errors.New("Nodes are up to date - No merge needed")
 	}
-	log.Infof("Merge Summary: %d local additions, %d remote additions", len(s.HashDiff.Additions), len(s.HashDiff.Deletions))
-	conn, err := dial(r)
+	log.Infof("Merge Summary: %d local additions, %d remote additions", len(hd.Additions), len(hd.Deletions))
+	conn, err := n.dial(r)
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 	client := d.NewDistributionServiceClient(conn)
-	stream, err := client.Splice(context.Background())
+	ctx := metadata.AppendToOutgoingContext(context.Background(), originHeader, n.ListenInterface)
+	stream, err := client.Splice(ctx)
 	if err != nil {
 		return err
 	}
-	for _, h := range s.HashDiff.Deletions {
-		o := n.Tangle.Get(h)
-		if o == nil {
+	for _, h := range hd.Deletions {
+		if n.refused(h) {
 			continue
 		}
-		do, err := d.FromObject(o)
+		do, err := n.distributable(h)
 		if err != nil {
 			return err
 		}
-		if n.Tangle.HasTip(o.Site.Hash()) {
+		if do == nil {
+			continue
+		}
+		if n.Tangle.HasTip(h) {
 			do.Tip = true
 		}
 		err = stream.Send(do)
 		if err != nil {
 			return err
 		}
-		log.Infof("Sent %s", o.Site.Hash())
+		n.throttle(r, proto.Size(do))
+		log.Infof("Sent %s", h)
 	}
 	_, err = stream.CloseAndRecv()
 	if err == io.EOF {
@@ -341,41 +1053,64 @@ func (n *Node) Merge(r string) error {
 
 // Splice injects the recieved sites into the tangle
 func (n *Node) Splice(stream d.DistributionService_SpliceServer) error {
-	canLink := func(o *d.Site) bool {
-		for _, s := range o.Validates {
-			h := hash.FromSlice(s)
-			if n.Tangle.Get(h) == nil {
-				return false
-			}
-		}
-		return true
+	if n.Cluster.ReadOnly() {
+		return status.Error(codes.FailedPrecondition, cluster.ErrReadOnly.Error())
 	}
+	if err := n.Watchdog.Writable(); err != nil {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	done, err := n.begin()
+	if err != nil {
+		return maintenanceError()
+	}
+	defer done()
+	r := n.sender(stream.Context())
+	// Every site takes an admission slot like a pushed one, so a splice can not validate more sites at once than AddSite
 	inj := func(o *d.Site) error {
-		s, err := n.toObject(o)
+		release, err := n.Admission.Admit(stream.Context())
 		if err != nil {
+			return busyError(err)
+		}
+		defer release()
+		tr := n.Decisions.Begin(decision.SourceSplice)
+		tr.SetHash(o.Hash())
+		s, err := n.toObject(o)
+		if !tr.Check("payload", err) {
+			tr.Reject(err)
+			err = siteError(err)
+			n.penalize(r, err)
 			return err
 		}
 		log.Infof("Received Site %s", s.Site.Hash())
-		err = n.Tangle.Inject(s, o.Tip)
-		if err != nil {
+		err = n.inject(s, o.Tip)
+		if !tr.Check("tangle", err) {
 			log.Error(err)
-			return err
+			tr.Reject(err)
+			return siteError(err)
 		}
+		tr.Finish(decision.Accepted, nil)
 		return nil
 	}
 	log.Info("Starting Splice")
 	buff := make(map[*d.Site]bool)
+	recv := n.receiver(stream)
 	for {
-		in, err := stream.Recv()
+		in, err := recv()
 		if err == io.EOF {
 			log.Info("Finished Splicing")
 			break
 		}
+		if err == errIdle {
+			log.Warnf("Aborting splice stream of %s: %s", r, err)
+			err = status.Error(codes.DeadlineExceeded, err.Error())
+			n.penalize(r, err)
+			return err
+		}
 		if err != nil {
 			log.Error(err)
 			return err
 		}
-		if canLink(in) {
+		if n.canLink(in) {
 			err := inj(in)
 			if err != nil {
 				log.Error(err)
@@ -389,7 +1124,7 @@ func (n *Node) Splice(stream d.DistributionService_SpliceServer) error {
 	for len(buff) > 0 {
 		origlen := len(buff)
 		for s := range buff {
-			if canLink(s) {
+			if n.canLink(s) {
 				err := inj(s)
 				if err != nil {
 					log.Error(err)
@@ -405,27 +1140,39 @@ func (n *Node) Splice(stream d.DistributionService_SpliceServer) error {
 	return nil
 }
 
+// canLink checks whether all sites validated by the site are known
+func (n *Node) canLink(o *d.Site) bool {
+	for _, s := range o.Validates {
+		h := hash.FromSlice(s)
+		if n.Tangle.GetSite(h) == nil {
+			return false
+		}
+	}
+	return true
+}
+
 func (n *Node) toObject(s *d.Site) (*tangle.Object, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
 	vs := []*site.Site{}
 	for _, h := range s.Validates {
-		o := n.Tangle.Get(hash.FromSlice(h))
-		if o == nil {
+		v := n.Tangle.GetSite(hash.FromSlice(h))
+		if v == nil {
 			return nil, errors.New("This node does not know about hash " + hash.FromSlice(h).String())
 		}
-		vs = append(vs, o.Site)
+		vs = append(vs, v)
 	}
 	var d datastore.Serializable
-	switch s.Type {
-	case "post":
-		d = &post.Post{}
-	case "image":
-		d = &img.Image{}
-	default:
-		return nil, errors.New("Invalid site type")
-	}
-	err := d.Deserialize(s.Data)
-	if err != nil {
-		return nil, err
+	if s.Buried {
+		if len(s.Data) > 0 {
+			return nil, malformed{errBuriedData}
+		}
+	} else {
+		var err error
+		if d, err = s.Payload(); err != nil {
+			return nil, malformed{err}
+		}
 	}
 	return &tangle.Object{
 		Site: &site.Site{
@@ -433,14 +1180,43 @@ func (n *Node) toObject(s *d.Site) (*tangle.Object, error) {
 			Nonce:     s.Nonce,
 			Content:   hash.FromSlice(s.Content),
 			Type:      s.Type,
+			Timestamp: s.Timestamp,
 		},
 		Data: d,
 	}, nil
 }
 
-func dial(r string) (*grpc.ClientConn, error) {
+// malformed marks payloads which could not be decoded
+type malformed struct {
+	error
+}
+
+// verifySignature checks that signed payloads were signed by the public key they carry
+func verifySignature(s *d.Site) error {
+	p, err := s.Payload()
+	if err != nil {
+		return malformed{err}
+	}
+	return validate.Signature(p)
+}
+
+// requirements converts the configured requirements for the tangle
+func requirements(c map[string]config.Requirement) map[string]tangle.Requirement {
+	rs := make(map[string]tangle.Requirement)
+	for t, r := range c {
+		rs[t] = tangle.Requirement(r)
+	}
+	return rs
+}
+
+func (n *Node) dial(r string) (*grpc.ClientConn, error) {
+	creds := grpc.WithInsecure()
+	if p, ok := n.peerOption(r); ok && p.Fingerprint != "" {
+		creds = grpc.WithTransportCredentials(pinnedTLS(p.Fingerprint))
+	}
 	return grpc.Dial(r,
-		grpc.WithInsecure(),
+		creds,
+		clientKeepalive,
 		grpc.WithDefaultCallOptions(
 			grpc.MaxCallRecvMsgSize(MaxMsgSize),
 			grpc.MaxCallSendMsgSize(MaxMsgSize),
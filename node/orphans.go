@@ -0,0 +1,168 @@
+package node
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/u-speak/core/decision"
+	"github.com/u-speak/core/logging"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+
+	"github.com/golang/protobuf/proto"
+	log "github.com/sirupsen/logrus"
+	d "github.com/u-speak/core/node/internal"
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// siteFetchTimeout limits how long a single peer is asked for a site
+const siteFetchTimeout = 5 * time.Second
+
+// MaxOrphanParents is the most sites an orphan may validate. Every missing parent is requested from the peers
+const MaxOrphanParents = 2 * tangle.MaxRecommendations
+
+var (
+	errSiteNotFound   = errors.New("No peer could provide the site")
+	errTooManyParents = errors.New("Orphan validates too many sites")
+)
+
+// fetches tracks the parents currently requested from the peers, so every parent is only requested once
+type fetches struct {
+	inflight map[hash.Hash]bool
+	lock     sync.Mutex
+}
+
+// start claims the hash and returns false if it is requested already
+func (f *fetches) start(h hash.Hash) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.inflight == nil {
+		f.inflight = make(map[hash.Hash]bool)
+	}
+	if f.inflight[h] {
+		return false
+	}
+	f.inflight[h] = true
+	return true
+}
+
+func (f *fetches) done(h hash.Hash) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	delete(f.inflight, h)
+}
+
+// orphanedSite is a received site waiting for the sites it validates
+type orphanedSite struct {
+	site *d.Site
+	tip  bool
+}
+
+// GetSite returns a known site including its payload, or without it if the payload was removed
+func (n *Node) GetSite(ctx context.Context, r *d.SiteRequest) (*d.Site, error) {
+	h := hash.FromSlice(r.Hash)
+	if n.refused(h) {
+		return nil, status.Error(codes.NotFound, "Unknown site")
+	}
+	s, err := n.distributable(h)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, status.Error(codes.NotFound, "Unknown site")
+	}
+	return s, nil
+}
+
+// FetchSite requests the site from all peers in turn and returns the first one with the requested hash
+func (n *Node) FetchSite(h hash.Hash) (*d.Site, error) {
+	for _, r := range n.sources() {
+		s, err := n.requestSite(r, h)
+		if err != nil {
+			logging.Debugf("sync", "Peer %s could not provide site %s: %s", r, h, err)
+			continue
+		}
+		if err := s.Validate(); err != nil || s.Hash() != h {
+			log.Warnf("Peer %s sent an invalid site for %s", r, h)
+			n.Peers.Failed(r)
+			continue
+		}
+		return s, nil
+	}
+	return nil, errSiteNotFound
+}
+
+func (n *Node) requestSite(r string, h hash.Hash) (*d.Site, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), siteFetchTimeout)
+	defer cancel()
+	conn, err := n.dial(r)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	start := time.Now()
+	s, err := d.NewDistributionServiceClient(conn).GetSite(ctx, &d.SiteRequest{Hash: h.Slice()})
+	if err != nil {
+		return nil, err
+	}
+	n.Health.Transferred(r, proto.Size(s), time.Since(start))
+	return s, nil
+}
+
+// missing returns the hashes of validated sites which are not in the tangle
+func (n *Node) missing(s *d.Site) []hash.Hash {
+	hs := []hash.Hash{}
+	for _, v := range s.Validates {
+		h := hash.FromSlice(v)
+		if n.Tangle.GetSite(h) == nil {
+			hs = append(hs, h)
+		}
+	}
+	return hs
+}
+
+// adopt keeps the site in the orphanage and requests its missing parents from the peers.
+// Orphans have to carry the work required for their type, since each of them costs requests to all peers
+func (n *Node) adopt(s *d.Site, tip bool, missing []hash.Hash) error {
+	if len(s.Validates) > MaxOrphanParents {
+		return malformed{errTooManyParents}
+	}
+	if w, r := n.Tangle.CanonicalWork(s.Canonical()), n.Tangle.Required(s.Type).Weight; w < r {
+		return &tangle.WeightError{Type: s.Type, Weight: w, Required: r}
+	}
+	if !n.Orphans.Add(s.Hash(), &orphanedSite{site: s, tip: tip}, missing, time.Now()) {
+		return nil
+	}
+	logging.Debugf("sync", "Site %s is missing %d parents, keeping it as orphan", s.Hash(), len(missing))
+	go func() {
+		for _, h := range missing {
+			if n.Tangle.GetSite(h) != nil || !n.fetches.start(h) {
+				continue
+			}
+			p, err := n.FetchSite(h)
+			n.fetches.done(h)
+			if err != nil {
+				logging.Debugf("sync", "Could not fetch parent %s: %s", h, err)
+				continue
+			}
+			if err := n.receive(p, false, decision.SourceOrphan); err != nil {
+				log.Warnf("Could not add parent %s: %s", h, err)
+			}
+		}
+	}()
+	return nil
+}
+
+// attach adds the orphans which were only waiting for the added site
+func (n *Node) attach(o *tangle.Object) {
+	for _, v := range n.Orphans.Resolve(o.Site.Hash()) {
+		go func(or *orphanedSite) {
+			if err := n.receive(or.site, or.tip, decision.SourceOrphan); err != nil {
+				log.Warnf("Could not attach orphan %s: %s", or.site.Hash(), err)
+			}
+		}(v.(*orphanedSite))
+	}
+}
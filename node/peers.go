@@ -0,0 +1,217 @@
+package node
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/u-speak/core/build"
+	"github.com/u-speak/core/config"
+	"github.com/u-speak/core/identity"
+	"github.com/u-speak/core/peer"
+
+	log "github.com/sirupsen/logrus"
+	d "github.com/u-speak/core/node/internal"
+	"google.golang.org/grpc/credentials"
+)
+
+var (
+	errNoCertificate       = errors.New("Peer did not present a certificate")
+	errFingerprintMismatch = errors.New("Peer certificate does not match the configured fingerprint")
+	errInvalidIdentity     = errors.New("Peer identity signature is invalid")
+)
+
+// Bootstrap connects to the configured bootstrap peers, highest priority first
+func (n *Node) Bootstrap() {
+	ps := append([]config.Peer{}, n.bootstrap...)
+	sort.SliceStable(ps, func(i, j int) bool { return ps[i].Priority > ps[j].Priority })
+	for _, p := range ps {
+		log.Infof("Connecting to bootstrap peer %s", p.Address)
+		if err := n.Connect(p.Address); err != nil {
+			log.Errorf("Could not connect to bootstrap peer %s: %s", p.Address, err)
+		}
+	}
+}
+
+// throttle delays the next transfer to a peer so its configured bandwidth in KiB/s is not exceeded
+func (n *Node) throttle(r string, size int) {
+	p, ok := n.peerOption(r)
+	if !ok || p.MaxBandwidth <= 0 {
+		return
+	}
+	time.Sleep(time.Duration(size) * time.Second / time.Duration(p.MaxBandwidth*1024))
+}
+
+// peerOption returns the configuration of a bootstrap peer. Resolved addresses of bootstrap peers are added
+// while connecting, so the options are guarded by the remote lock
+func (n *Node) peerOption(r string) (config.Peer, bool) {
+	n.remoteLock.RLock()
+	defer n.remoteLock.RUnlock()
+	p, ok := n.peerOptions[r]
+	return p, ok
+}
+
+// pinnedTLS only accepts peers presenting a certificate with the given SHA-256 fingerprint
+func pinnedTLS(fingerprint string) credentials.TransportCredentials {
+	want := strings.ToLower(strings.Replace(fingerprint, ":", "", -1))
+	return credentials.NewTLS(&tls.Config{
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(raw [][]byte, _ [][]*x509.Certificate) error {
+			if len(raw) == 0 {
+				return errNoCertificate
+			}
+			sum := sha256.Sum256(raw[0])
+			if hex.EncodeToString(sum[:]) != want {
+				return errFingerprintMismatch
+			}
+			return nil
+		},
+	})
+}
+
+// handshake verifies the identity presented by the peer dialed at the address and records it under the address.
+// The peer has to sign a fresh challenge for the address, so identities can not be replayed by other nodes.
+// A known peer showing up under a new address replaces its old connection.
+// Peers without an identity are accepted and return an empty id, peers fenced off by the access lists are rejected
+func (n *Node) handshake(client d.DistributionServiceClient, i *d.Info, address string) (string, error) {
+	if len(i.PublicKey) == 0 {
+		if !n.ACL.Allowed(address, "") {
+			return "", peer.ErrDenied
+		}
+		return "", nil
+	}
+	if err := n.prove(client, address, i.PublicKey); err != nil {
+		return "", errInvalidIdentity
+	}
+	id := identity.IDOf(i.PublicKey)
+	if !n.ACL.Allowed(address, id) {
+		return "", peer.ErrDenied
+	}
+	if id == n.Identity.ID() {
+		return id, nil
+	}
+	if old, moved := n.Peers.Seen(id, address); moved && old != "" {
+		log.Infof("Peer %s moved from %s to %s", id, old, address)
+		n.disconnect(old)
+	}
+	n.Peers.Built(id, i.Version, i.Commit, int(i.Protocol))
+	n.Peers.SetMaintenance(address, i.Maintenance)
+	if !build.Compatible(int(i.Protocol)) {
+		log.Warnf("Peer %s at %s speaks protocol %d, this node speaks %d", id, address, i.Protocol, build.Protocol)
+	}
+	return id, nil
+}
+
+// admit reserves a slot for the connection, evicting a worse peer if all slots are taken.
+// Bootstrap peers are static and always get a slot
+func (n *Node) admit(remote string, inbound bool) error {
+	_, static := n.peerOption(remote)
+	evict, err := n.slots.Admit(peer.Conn{Address: remote, Inbound: inbound, Static: static}, n.conns(remote), n.Peers)
+	if err != nil {
+		return err
+	}
+	if evict != "" {
+		log.Infof("Evicting %s to make room for %s", evict, remote)
+		n.disconnect(evict)
+	}
+	return nil
+}
+
+// conns lists the established connections except the given address
+func (n *Node) conns(except string) []peer.Conn {
+	conns := []peer.Conn{}
+	n.remoteLock.RLock()
+	defer n.remoteLock.RUnlock()
+	for r := range n.remoteInterfaces {
+		if r == except {
+			continue
+		}
+		_, s := n.peerOptions[r]
+		conns = append(conns, peer.Conn{Address: r, Inbound: n.inbound[r], Static: s})
+	}
+	return conns
+}
+
+// remotes returns the addresses of the connected peers. Connections are added and removed
+// from concurrent RPC handlers, so the map is only accessed through these helpers
+func (n *Node) remotes() []string {
+	n.remoteLock.RLock()
+	defer n.remoteLock.RUnlock()
+	rs := make([]string, 0, len(n.remoteInterfaces))
+	for r := range n.remoteInterfaces {
+		rs = append(rs, r)
+	}
+	return rs
+}
+
+// connected reports whether the address is a connected or connecting peer
+func (n *Node) connected(r string) bool {
+	n.remoteLock.RLock()
+	defer n.remoteLock.RUnlock()
+	_, ok := n.remoteInterfaces[r]
+	return ok
+}
+
+// reserve claims the address while the connection is set up. It returns false if the address was claimed already
+func (n *Node) reserve(r string) bool {
+	n.remoteLock.Lock()
+	defer n.remoteLock.Unlock()
+	if _, ok := n.remoteInterfaces[r]; ok {
+		return false
+	}
+	n.remoteInterfaces[r] = struct{}{}
+	return true
+}
+
+// release gives up a reservation whose connection could not be set up
+func (n *Node) release(r string) {
+	n.remoteLock.Lock()
+	defer n.remoteLock.Unlock()
+	delete(n.remoteInterfaces, r)
+}
+
+// establish records the reserved address as connected peer
+func (n *Node) establish(r string, inbound bool) {
+	n.remoteLock.Lock()
+	defer n.remoteLock.Unlock()
+	n.remoteInterfaces[r] = struct{}{}
+	n.inbound[r] = inbound
+}
+
+func (n *Node) disconnect(r string) {
+	n.remoteLock.Lock()
+	delete(n.remoteInterfaces, r)
+	delete(n.inbound, r)
+	delete(n.authenticated, host(r))
+	n.remoteLock.Unlock()
+	n.Health.Forget(r)
+}
+
+// rotate replaces a random outbound peer with a known peer from a less crowded subnet.
+// If there are free outbound slots the new peer is added without dropping one
+func (n *Node) rotate() {
+	conns := n.conns("")
+	drop, dial := peer.Rotate(conns, n.Peers.List(), rand.New(rand.NewSource(time.Now().UnixNano())))
+	if dial == "" {
+		return
+	}
+	out := 0
+	for _, c := range conns {
+		if !c.Inbound {
+			out++
+		}
+	}
+	if n.slots.MaxOutbound > 0 && out >= n.slots.MaxOutbound {
+		log.Infof("Rotating outbound peer %s for %s", drop, dial)
+		n.disconnect(drop)
+	}
+	if err := n.connect(dial, false); err != nil {
+		log.Errorf("Could not connect to %s: %s", dial, err)
+	}
+}
@@ -0,0 +1,78 @@
+package node
+
+import (
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	d "github.com/u-speak/core/node/internal"
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	grpcpeer "google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+var errIdle = errors.New("No site received in time")
+
+// sender returns the address of the known peer which sent a request, or an empty string.
+// The caller is identified by the transport address and the id it authenticated, never by what it claims in headers
+func (n *Node) sender(ctx context.Context) string {
+	p, ok := grpcpeer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	id := n.authenticatedID(p.Addr.String())
+	if id == "" {
+		return ""
+	}
+	if known := n.Peers.ByID(id); known != nil {
+		return known.Address
+	}
+	return ""
+}
+
+// penalize lowers the reputation of a peer which sent a malformed or forged site or stalled a stream.
+// Sites which merely do not fit the local tangle are no misbehaviour
+func (n *Node) penalize(r string, err error) {
+	if r == "" || err == nil {
+		return
+	}
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.Unauthenticated, codes.DeadlineExceeded:
+		log.Warnf("Penalizing peer %s: %s", r, err)
+		n.Peers.Failed(r)
+	}
+}
+
+// receiver returns a function receiving the next site of the splice stream, failing with errIdle
+// if the peer does not send one within the idle timeout
+func (n *Node) receiver(stream d.DistributionService_SpliceServer) func() (*d.Site, error) {
+	type received struct {
+		site *d.Site
+		err  error
+	}
+	ch := make(chan received)
+	go func() {
+		for {
+			s, err := stream.Recv()
+			select {
+			case ch <- received{s, err}:
+			case <-stream.Context().Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return func() (*d.Site, error) {
+		t := time.NewTimer(n.spliceIdle)
+		defer t.Stop()
+		select {
+		case r := <-ch:
+			return r.site, r.err
+		case <-t.C:
+			return nil, errIdle
+		}
+	}
+}
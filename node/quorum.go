@@ -0,0 +1,70 @@
+package node
+
+import (
+	"github.com/u-speak/core/tangle"
+
+	log "github.com/sirupsen/logrus"
+	d "github.com/u-speak/core/node/internal"
+	context "golang.org/x/net/context"
+)
+
+// Propose is the first phase of the quorum push. The site is checked against the local tangle without storing it
+func (n *Node) Propose(ctx context.Context, s *d.Site) (*d.Ack, error) {
+	o, err := n.toObject(s)
+	if err != nil {
+		return &d.Ack{Accepted: false, Reason: err.Error()}, nil
+	}
+	if err := n.Tangle.Validate(o); err != nil {
+		return &d.Ack{Accepted: false, Reason: err.Error()}, nil
+	}
+	return &d.Ack{Accepted: true}, nil
+}
+
+// propose collects the acknowledgements of the trusted peers and commits the site once the quorum is reached
+func (n *Node) propose(o *tangle.Object) {
+	ds, err := d.FromObject(o)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	h := o.Site.Hash()
+	for _, p := range n.Quorum.Peers() {
+		ack, err := n.proposeTo(p, ds)
+		if err != nil {
+			log.Errorf("Could not propose site %s to %s: %s", h, p, err)
+			continue
+		}
+		if !ack.Accepted {
+			log.Warnf("Peer %s rejected site %s: %s", p, h, ack.Reason)
+			continue
+		}
+		if c := n.Quorum.Ack(h, p); c != nil {
+			n.commit(c)
+			return
+		}
+	}
+	log.Warnf("Site %s is waiting for quorum", h)
+}
+
+// commit stores a site that reached the quorum and pushes it to the network
+func (n *Node) commit(o *tangle.Object) {
+	err := n.Cluster.Do(func() error { return n.Tangle.Inject(o, true) })
+	if err != nil {
+		log.Errorf("Failed to commit site %s: %s", o.Site.Hash(), err)
+		return
+	}
+	log.Infof("Site %s reached quorum", o.Site.Hash())
+	if err := n.Push(o); err != nil {
+		log.Error(err)
+	}
+}
+
+func (n *Node) proposeTo(r string, ds *d.Site) (*d.Ack, error) {
+	conn, err := n.dial(r)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	client := d.NewDistributionServiceClient(conn)
+	return client.Propose(context.Background(), ds)
+}
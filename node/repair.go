@@ -0,0 +1,109 @@
+package node
+
+import (
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/datastore"
+	"github.com/u-speak/core/tangle/hash"
+
+	log "github.com/sirupsen/logrus"
+	d "github.com/u-speak/core/node/internal"
+)
+
+// maxRepairPasses limits how often the stores are checked again. Truncating a site can reveal damage of its children
+const maxRepairPasses = 3
+
+// RepairReport lists the damage found in the stores and the damage left after repairing
+type RepairReport struct {
+	Found     []tangle.Damage `json:"found"`
+	Remaining []tangle.Damage `json:"remaining"`
+}
+
+// Repair fixes the damage found by Tangle.Check. Missing parents are restored from the copies embedded in the sites validating them,
+// other sites and payloads are fetched from the peer, or from all connected peers if peer is empty.
+// If truncate is set, sites which could not be fetched are removed and payloads which could not be fetched are hidden
+func (n *Node) Repair(peer string, truncate bool) RepairReport {
+	r := RepairReport{Found: n.Tangle.Check()}
+	r.Remaining = r.Found
+	for i := 0; i < maxRepairPasses && len(r.Remaining) > 0; i++ {
+		for _, dmg := range r.Remaining {
+			if err := n.repair(dmg, peer, truncate); err != nil {
+				log.Warnf("Could not repair %s of %s: %s", dmg.Kind, dmg.Site, err)
+			}
+		}
+		n.Tangle.RebuildTips()
+		r.Remaining = n.Tangle.Check()
+	}
+	return r
+}
+
+func (n *Node) repair(dmg tangle.Damage, peer string, truncate bool) error {
+	switch dmg.Kind {
+	case tangle.MissingParent:
+		return n.Tangle.RestoreParent(dmg.Site, dmg.Missing)
+	case tangle.DanglingTip:
+		// The site was never stored completely, dropping the tip loses nothing
+		return n.Tangle.Truncate(dmg.Site)
+	case tangle.Unreadable:
+		err := n.Tangle.Restore(dmg.Site)
+		if err == tangle.ErrNotEmbedded {
+			err = n.refetch(peer, dmg.Site)
+		}
+		if err != nil && truncate {
+			return n.Tangle.Truncate(dmg.Site)
+		}
+		return err
+	case tangle.MissingPayload:
+		_, err := n.fetchDataFrom(peer, dmg.Type, dmg.Missing)
+		if err != nil && truncate {
+			return n.Tangle.Bury(dmg.Site, hash.Hash{})
+		}
+		return err
+	}
+	return nil
+}
+
+// refetch replaces an unreadable site with the copy of a peer
+func (n *Node) refetch(peer string, h hash.Hash) error {
+	s, err := n.fetchSiteFrom(peer, h)
+	if err != nil {
+		return err
+	}
+	o, err := n.toObject(s)
+	if err != nil {
+		return err
+	}
+	if err := n.Tangle.RestoreSite(o.Site); err != nil {
+		return err
+	}
+	return n.Tangle.PutData(o.Data)
+}
+
+// fetchSiteFrom requests the site from the peer, or from all connected peers if peer is empty
+func (n *Node) fetchSiteFrom(peer string, h hash.Hash) (*d.Site, error) {
+	if peer == "" {
+		return n.FetchSite(h)
+	}
+	s, err := n.requestSite(peer, h)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Validate(); err != nil || s.Hash() != h {
+		return nil, errSiteNotFound
+	}
+	return s, nil
+}
+
+// fetchDataFrom requests the payload from the peer, or from all connected peers if peer is empty
+func (n *Node) fetchDataFrom(peer, typ string, content hash.Hash) (datastore.Serializable, error) {
+	if peer == "" {
+		return n.FetchData(typ, content)
+	}
+	data, err := n.requestData(peer, typ, content)
+	if err != nil {
+		return nil, err
+	}
+	if h, err := data.Hash(); err != nil || h != content {
+		return nil, errDataNotFound
+	}
+	return data, n.Tangle.PutData(data)
+}
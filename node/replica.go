@@ -0,0 +1,223 @@
+package node
+
+import (
+	"errors"
+	"time"
+
+	"github.com/u-speak/core/metrics"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+
+	log "github.com/sirupsen/logrus"
+	d "github.com/u-speak/core/node/internal"
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	grpcpeer "google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// HeartbeatInterval is the time between length updates sent to idle replicas
+	HeartbeatInterval = 10 * time.Second
+	// ReconnectInterval is the time a replica waits before resubscribing to its writer
+	ReconnectInterval = 5 * time.Second
+	// MaxReplicaPending limits the received sites a replica keeps until the sites they validate arrive
+	MaxReplicaPending = 4096
+	// MaxSubscribers limits the replicas streaming from a writer at the same time
+	MaxSubscribers = 64
+	// MaxHostSubscribers limits the concurrent subscriptions from a single remote host
+	MaxHostSubscribers = 4
+	subscriberBuffer   = 256
+)
+
+var (
+	errSlowSubscriber = errors.New("Subscriber could not keep up with the site stream")
+	errTooManyPending = errors.New("Too many replicated sites wait for the sites they validate")
+	errTooManySubs    = errors.New("Too many replicas are subscribed")
+)
+
+// Subscribe streams all sites the subscriber is missing, followed by every site added to the tangle afterwards
+func (n *Node) Subscribe(i *d.Info, stream d.DistributionService_SubscribeServer) error {
+	if n.Cluster.Forwarding() {
+		return status.Error(codes.FailedPrecondition, "Replicas have to subscribe to a writer")
+	}
+	remote := ""
+	if p, ok := grpcpeer.FromContext(stream.Context()); ok {
+		remote = host(p.Addr.String())
+	}
+	ch := make(chan *d.Site, subscriberBuffer)
+	n.subLock.Lock()
+	if len(n.subscribers) >= MaxSubscribers || n.subHosts[remote] >= MaxHostSubscribers {
+		n.subLock.Unlock()
+		return status.Error(codes.ResourceExhausted, errTooManySubs.Error())
+	}
+	n.subscribers[ch] = struct{}{}
+	n.subHosts[remote]++
+	n.subLock.Unlock()
+	defer func() {
+		n.subLock.Lock()
+		delete(n.subscribers, ch)
+		if n.subHosts[remote]--; n.subHosts[remote] == 0 {
+			delete(n.subHosts, remote)
+		}
+		n.subLock.Unlock()
+	}()
+	log.Infof("Replica %s subscribed", i.ListenInterface)
+
+	hs := []hash.Hash{}
+	for _, h := range i.Hashes {
+		hs = append(hs, hash.FromSlice(h))
+	}
+	// Sites added while the missing ones are sent are forwarded in between, so the buffer does not overflow
+	// as long as the subscriber keeps up with the stream
+	forward := func() error {
+		for {
+			select {
+			case ds, ok := <-ch:
+				if !ok {
+					return status.Error(codes.ResourceExhausted, errSlowSubscriber.Error())
+				}
+				if err := stream.Send(&d.Update{Site: ds, Length: uint64(n.Tangle.Size())}); err != nil {
+					return err
+				}
+			default:
+				return nil
+			}
+		}
+	}
+	_, missing := hash.Diff(n.Tangle.Hashes(), hs)
+	for _, h := range missing {
+		if err := forward(); err != nil {
+			return err
+		}
+		ds, err := n.distributable(h)
+		if err != nil {
+			return err
+		}
+		if ds == nil {
+			continue
+		}
+		ds.Tip = n.Tangle.HasTip(h)
+		err = stream.Send(&d.Update{Site: ds, Length: uint64(n.Tangle.Size())})
+		if err != nil {
+			return err
+		}
+	}
+
+	heartbeat := time.NewTicker(HeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		var u *d.Update
+		select {
+		case ds, ok := <-ch:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, errSlowSubscriber.Error())
+			}
+			u = &d.Update{Site: ds, Length: uint64(n.Tangle.Size())}
+		case <-heartbeat.C:
+			u = &d.Update{Length: uint64(n.Tangle.Size())}
+		case <-stream.Context().Done():
+			log.Infof("Replica %s unsubscribed", i.ListenInterface)
+			return nil
+		}
+		if err := stream.Send(u); err != nil {
+			return err
+		}
+	}
+}
+
+// broadcast hands a freshly added site to all subscribed replicas. Replicas that fall too far behind are dropped and have to resubscribe
+func (n *Node) broadcast(o *tangle.Object) {
+	n.subLock.Lock()
+	defer n.subLock.Unlock()
+	if len(n.subscribers) == 0 {
+		return
+	}
+	ds, err := d.FromObject(o)
+	if err != nil {
+		log.Errorf("Could not broadcast site %s: %s", o.Site.Hash(), err)
+		return
+	}
+	ds.Tip = n.Tangle.HasTip(o.Site.Hash())
+	for ch := range n.subscribers {
+		select {
+		case ch <- ds:
+		default:
+			log.Warn(errSlowSubscriber)
+			close(ch)
+			delete(n.subscribers, ch)
+		}
+	}
+}
+
+// Replicate follows the site stream of the writer until the node is stopped, resubscribing whenever the stream breaks
+func (n *Node) Replicate() {
+	for {
+		err := n.replicate()
+		n.Replication.Disconnected()
+		log.Errorf("Replication from %s interrupted: %v", n.Cluster.Writer, err)
+		time.Sleep(ReconnectInterval)
+	}
+}
+
+func (n *Node) replicate() error {
+	conn, err := n.dial(n.Cluster.Writer)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	client := d.NewDistributionServiceClient(conn)
+	stream, err := client.Subscribe(context.Background(), n.Info())
+	if err != nil {
+		return err
+	}
+	log.Infof("Replicating from %s", n.Cluster.Writer)
+	pending := make(map[*d.Site]bool)
+	for {
+		u, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		if u.Site != nil {
+			pending[u.Site] = true
+			err = n.applyPending(pending)
+			if err != nil {
+				return err
+			}
+			// Resubscribing sends the sites this replica has, so the writer streams the missing ones again
+			if len(pending) > MaxReplicaPending {
+				return errTooManyPending
+			}
+		}
+		n.Replication.Update(u.Length, uint64(n.Tangle.Size()))
+		metrics.ReplicationLag.Set(float64(n.Replication.Lag().Behind))
+	}
+}
+
+// applyPending injects every pending site whose validated sites are already known
+func (n *Node) applyPending(pending map[*d.Site]bool) error {
+	for {
+		progress := false
+		for s := range pending {
+			if !n.canLink(s) {
+				continue
+			}
+			delete(pending, s)
+			progress = true
+			o, err := n.toObject(s)
+			if err != nil {
+				return err
+			}
+			if n.Tangle.GetSite(o.Site.Hash()) != nil {
+				continue
+			}
+			err = n.inject(o, s.Tip)
+			if err != nil {
+				return err
+			}
+		}
+		if !progress {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package node
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+
+	d "github.com/u-speak/core/node/internal"
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcpeer "google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// subscription records the updates sent to a replica
+type subscription struct {
+	grpc.ServerStream
+	ctx    context.Context
+	onSend func(*d.Update)
+}
+
+func (s *subscription) Send(u *d.Update) error {
+	s.onSend(u)
+	return nil
+}
+
+func (s *subscription) Context() context.Context {
+	return s.ctx
+}
+
+func TestSubscribeForwardsDuringDiff(t *testing.T) {
+	dir, err := ioutil.TempDir("", "node")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	n, _, stop := testNode(t, dir)
+	defer stop()
+
+	gen := n.Tangle.Get(n.Tangle.Tips()[0].Hash())
+	added := &tangle.Object{Site: &site.Site{Content: hash.Hash{9}, Type: "genesis"}, Data: gen.Data}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sent := 0
+	stream := &subscription{ctx: ctx, onSend: func(u *d.Update) {
+		sent++
+		// Sending each of the two missing sites takes long enough for more sites to be added than the buffer holds in total
+		if u.Site.Hash() != added.Site.Hash() {
+			for i := 0; i < subscriberBuffer*3/4; i++ {
+				n.broadcast(added)
+			}
+		}
+		if sent == 2+2*(subscriberBuffer*3/4) {
+			cancel()
+		}
+	}}
+	assert.NoError(t, n.Subscribe(&d.Info{}, stream))
+	assert.Equal(t, 2+2*(subscriberBuffer*3/4), sent)
+}
+
+func TestSubscriberLimits(t *testing.T) {
+	dir, err := ioutil.TempDir("", "node")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	n, _, stop := testNode(t, dir)
+	defer stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	subscribe := func(ip string) error {
+		pctx := grpcpeer.NewContext(ctx, &grpcpeer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 4000}})
+		return n.Subscribe(&d.Info{}, &subscription{ctx: pctx, onSend: func(*d.Update) {}})
+	}
+	subscribed := func() int {
+		n.subLock.Lock()
+		defer n.subLock.Unlock()
+		return len(n.subscribers)
+	}
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+	start := func(ip string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, subscribe(ip))
+		}()
+	}
+	for i := 0; i < MaxHostSubscribers; i++ {
+		start("10.0.0.1")
+	}
+	assert.Eventually(t, func() bool { return subscribed() == MaxHostSubscribers }, time.Second, time.Millisecond)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(subscribe("10.0.0.1")))
+
+	for i := MaxHostSubscribers; i < MaxSubscribers; i++ {
+		start(fmt.Sprintf("10.0.1.%d", i))
+	}
+	assert.Eventually(t, func() bool { return subscribed() == MaxSubscribers }, time.Second, time.Millisecond)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(subscribe("10.0.2.1")))
+
+	cancel()
+	wg.Wait()
+	assert.Empty(t, n.subHosts)
+}
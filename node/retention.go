@@ -0,0 +1,38 @@
+package node
+
+import (
+	"time"
+
+	"github.com/u-speak/core/tangle/hash"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// expiring are the site types whose payload is removed after the retention period.
+// Follow lists, subscriptions and tombstones are kept since other sites depend on them
+var expiring = map[string]bool{"post": true, "image": true}
+
+// expire removes the payloads of posts and images mined before the retention period, except for pinned sites.
+// Sites without timestamp are kept since their age is unknown
+func (n *Node) expire() error {
+	deadline := time.Now().Add(-n.retention).Unix()
+	removed := 0
+	for _, h := range n.Tangle.Hashes() {
+		s := n.Tangle.GetSite(h)
+		if s == nil || !expiring[s.Type] || s.Timestamp == 0 || s.Timestamp >= deadline {
+			continue
+		}
+		if _, buried := n.Tangle.Buried(h); buried || n.Pins.Pinned(h) {
+			continue
+		}
+		if err := n.Remove(h, hash.Hash{}); err != nil {
+			log.Errorf("Could not expire the payload of %s: %s", h, err)
+			continue
+		}
+		removed++
+	}
+	if removed > 0 {
+		log.Infof("Removed %d payloads older than the retention period", removed)
+	}
+	return nil
+}
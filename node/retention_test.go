@@ -0,0 +1,45 @@
+package node
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/miner"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+)
+
+func TestRetention(t *testing.T) {
+	dir, err := ioutil.TempDir("", "node")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	n, _, stop := testNode(t, dir)
+	defer stop()
+
+	key := newKey(t)
+	add := func(content string) hash.Hash {
+		p, err := miner.SignPost(key, "", content, time.Now().Unix())
+		assert.NoError(t, err)
+		h, err := p.Hash()
+		assert.NoError(t, err)
+		s := &site.Site{Type: "post", Content: h, Validates: n.Tangle.RecommendTips(), Timestamp: time.Now().Unix()}
+		s.Mine(n.Tangle.Required("post").Weight)
+		assert.NoError(t, n.Tangle.Add(&tangle.Object{Site: s, Data: p}))
+		return s.Hash()
+	}
+	expired, pinned := add("expired"), add("pinned")
+	_, err = n.Pins.Add(pinned, "")
+	assert.NoError(t, err)
+
+	// Every site is older than a retention period ending in the future
+	n.retention = -time.Hour
+	assert.NoError(t, n.expire())
+	_, buried := n.Tangle.Buried(expired)
+	assert.True(t, buried)
+	assert.NotNil(t, n.Tangle.Get(pinned))
+	assert.Equal(t, ErrPinned, n.Remove(pinned, hash.Hash{}))
+}
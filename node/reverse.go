@@ -0,0 +1,141 @@
+package node
+
+import (
+	"crypto/rand"
+	"errors"
+	"net"
+
+	"github.com/u-speak/core/identity"
+	d "github.com/u-speak/core/node/internal"
+
+	context "golang.org/x/net/context"
+	grpcpeer "google.golang.org/grpc/peer"
+)
+
+// Policies for reverse connections to peers which called GetInfo
+const (
+	// ReverseOff never connects back to callers
+	ReverseOff = "off"
+	// ReverseVerify connects back to callers which proved that they are listening on the address they claimed
+	ReverseVerify = "verify"
+	// ReverseOn connects back to every caller
+	ReverseOn = "on"
+)
+
+// proofPrefix separates proofs from other signatures made with the node identity
+const proofPrefix = "prove:"
+
+// nonceSize is the amount of random bytes in a dial-back challenge
+const nonceSize = 32
+
+var (
+	errReverseLimit    = errors.New("Too many reverse connections")
+	errAnonymous       = errors.New("Caller did not present an identity")
+	errAddressMismatch = errors.New("Claimed address does not belong to the caller")
+	errInvalidProof    = errors.New("Claimed address did not answer the challenge with the callers identity")
+)
+
+// Prove signs the challenge with the node identity, showing the caller who is listening on this address
+func (n *Node) Prove(ctx context.Context, c *d.Challenge) (*d.Proof, error) {
+	return &d.Proof{
+		PublicKey: n.Identity.PublicKey(),
+		Signature: n.Identity.Sign(proofMessage(c.Nonce, c.Address)),
+	}, nil
+}
+
+// proofMessage binds the signed nonce to the address the challenger dialed, so proofs can not be relayed
+func proofMessage(nonce []byte, address string) []byte {
+	return append(append([]byte(proofPrefix), nonce...), address...)
+}
+
+// reverse connects back to the caller of GetInfo according to the reverse connection policy
+func (n *Node) reverse(ctx context.Context, i *d.Info) error {
+	if n.reversePolicy == ReverseOff {
+		return nil
+	}
+	if n.reverseCount() >= n.maxReverse {
+		return errReverseLimit
+	}
+	if n.reversePolicy == ReverseVerify {
+		if err := n.verifyCaller(ctx, i); err != nil {
+			return err
+		}
+	}
+	return n.open(i.ListenInterface, true)
+}
+
+// reverseCount returns the amount of established reverse connections
+func (n *Node) reverseCount() int {
+	n.remoteLock.RLock()
+	defer n.remoteLock.RUnlock()
+	c := 0
+	for _, in := range n.inbound {
+		if in {
+			c++
+		}
+	}
+	return c
+}
+
+// verifyCaller checks that the claimed listen interface resolves to the address of the caller
+// and that the node listening there holds the identity the caller presented
+func (n *Node) verifyCaller(ctx context.Context, i *d.Info) error {
+	if len(i.PublicKey) == 0 {
+		return errAnonymous
+	}
+	p, ok := grpcpeer.FromContext(ctx)
+	if !ok {
+		return errAddressMismatch
+	}
+	caller, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return err
+	}
+	host, port, err := net.SplitHostPort(i.ListenInterface)
+	if err != nil {
+		return err
+	}
+	ips, err := n.resolver.LookupIP(host)
+	if err != nil {
+		return err
+	}
+	matched := false
+	for _, ip := range ips {
+		if ip.Equal(net.ParseIP(caller)) {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return errAddressMismatch
+	}
+	return n.challenge(net.JoinHostPort(caller, port), i.PublicKey)
+}
+
+// challenge dials the address and requires the node listening there to sign a random nonce with the given key
+func (n *Node) challenge(address string, pub []byte) error {
+	conn, err := n.dial(address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return n.prove(d.NewDistributionServiceClient(conn), address, pub)
+}
+
+// prove requires the node reached through the client at the address to sign a random nonce with the given key
+func (n *Node) prove(client d.DistributionServiceClient, address string, pub []byte) error {
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), n.pingTimeout)
+	defer cancel()
+	p, err := client.Prove(ctx, &d.Challenge{Nonce: nonce, Address: address})
+	if err != nil {
+		return err
+	}
+	if identity.IDOf(p.PublicKey) != identity.IDOf(pub) || !identity.Verify(pub, proofMessage(nonce, address), p.Signature) {
+		return errInvalidProof
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/u-speak/core/config"
+	"github.com/u-speak/core/metrics"
+	"github.com/u-speak/core/schedule"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Names of the builtin tasks which can be rescheduled in the configuration
+const (
+	TaskHousekeeping = "housekeeping"
+	TaskSync         = "sync"
+	TaskGossip       = "gossip"
+	TaskRotate       = "rotate"
+	TaskSolidify     = "solidify"
+	TaskDigest       = "digest"
+	TaskAnchor       = "anchor"
+	TaskReindex      = "reindex"
+	TaskRetention    = "retention"
+)
+
+// taskOff disables a task in the configuration
+const taskOff = "off"
+
+var tasks = []string{TaskHousekeeping, TaskSync, TaskGossip, TaskRotate, TaskSolidify, TaskDigest, TaskAnchor, TaskReindex, TaskRetention}
+
+// schedule registers the builtin tasks. Intervals from the older configuration options are used unless a cron expression is configured
+func (n *Node) schedule(c config.Configuration) error {
+	n.Scheduler = schedule.New(time.Duration(c.Schedule.Jitter) * time.Second)
+	specs := map[string]string{}
+	for _, t := range c.Schedule.Tasks {
+		specs[t.Name] = t.Cron
+	}
+	for name := range specs {
+		if !knownTask(name) {
+			return errors.New("Unknown scheduled task " + name)
+		}
+	}
+	add := func(name string, interval time.Duration, f func() error) error {
+		spec, ok := specs[name]
+		if !ok {
+			if interval <= 0 {
+				return nil
+			}
+			spec = fmt.Sprintf("@every %s", interval)
+		}
+		if spec == taskOff {
+			return nil
+		}
+		if err := n.Scheduler.Add(name, spec, f); err != nil {
+			return fmt.Errorf("Could not schedule %s: %s", name, err)
+		}
+		return nil
+	}
+	if err := add(TaskHousekeeping, time.Minute, n.housekeeping); err != nil {
+		return err
+	}
+	if err := add(TaskSync, time.Minute, func() error {
+		n.synchronize()
+		n.Alerts.Evaluate()
+		return n.syncErr
+	}); err != nil {
+		return err
+	}
+	if err := add(TaskGossip, n.gossipInterval, func() error { n.gossip(); return nil }); err != nil {
+		return err
+	}
+	if err := add(TaskRotate, n.rotation, func() error { n.rotate(); return nil }); err != nil {
+		return err
+	}
+	if err := add(TaskSolidify, n.solidifyInterval, func() error { n.Solidify(); return nil }); err != nil {
+		return err
+	}
+	if err := add(TaskReindex, time.Hour, n.reindex); err != nil {
+		return err
+	}
+	if n.retention > 0 {
+		if err := add(TaskRetention, time.Hour, n.expire); err != nil {
+			return err
+		}
+	}
+	if n.Digest != nil {
+		if err := add(TaskDigest, time.Duration(n.Digest.Interval())*time.Hour, n.Digest.Send); err != nil {
+			return err
+		}
+	}
+	if n.Anchors != nil {
+		err := add(TaskAnchor, time.Duration(n.Anchors.Interval())*time.Hour, func() error {
+			_, err := n.Anchors.Anchor()
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func knownTask(name string) bool {
+	for _, t := range tasks {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+// housekeeping expires caches and queues and refreshes the statistics
+func (n *Node) housekeeping() error {
+	n.Watchdog.Check()
+	n.Submissions.Expire(time.Now())
+	if dropped := n.Uploads.Expire(time.Now()); dropped > 0 {
+		log.Infof("Removed %d unfinished uploads", dropped)
+	}
+	if expired, err := n.Hidden.Expire(time.Now()); err != nil {
+		log.Errorf("Could not expire hidden sites: %s", err)
+	} else if expired > 0 {
+		log.Infof("Showing %d sites whose hiding expired", expired)
+	}
+	n.updateStats()
+	n.resolveConflicts()
+	metrics.RelayExpired.Add(float64(n.Relays.Expire(time.Now())))
+	metrics.RelayCacheSize.Set(float64(n.Relays.Len()))
+	if dropped := n.Orphans.Expire(time.Now().Add(-n.orphanTTL)); dropped > 0 {
+		log.Infof("Dropped %d orphans whose parents did not arrive", dropped)
+	}
+	if n.Quorum != nil {
+		for _, h := range n.Quorum.Expire(time.Now()) {
+			log.Warnf("Site %s did not reach quorum in time", h)
+		}
+		for _, p := range n.Quorum.Pending() {
+			go n.propose(p.Object)
+		}
+	}
+	return nil
+}
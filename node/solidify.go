@@ -0,0 +1,105 @@
+package node
+
+import (
+	"time"
+
+	"github.com/u-speak/core/decision"
+	"github.com/u-speak/core/logging"
+	"github.com/u-speak/core/tangle/datastore"
+	"github.com/u-speak/core/tangle/hash"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Solidification reports the progress of the last walk looking for missing parents and payloads
+type Solidification struct {
+	Running  bool      `json:"running"`
+	Started  time.Time `json:"started,omitempty"`
+	Finished time.Time `json:"finished,omitempty"`
+	Total    int       `json:"total"`
+	Checked  int       `json:"checked"`
+	Missing  int       `json:"missing"`
+	Fetched  int       `json:"fetched"`
+	Failed   int       `json:"failed"`
+}
+
+// solidificationStatus returns a copy of the current solidification progress
+func (n *Node) solidificationStatus() Solidification {
+	n.solidLock.Lock()
+	defer n.solidLock.Unlock()
+	return n.solidification
+}
+
+// progress updates the solidification progress while holding the lock
+func (n *Node) progress(f func(s *Solidification)) {
+	n.solidLock.Lock()
+	f(&n.solidification)
+	n.solidLock.Unlock()
+}
+
+// Solidify walks all known sites and fetches missing parents and payloads from the peers.
+// At most solidifyRate fetches are made per second. Only one walk runs at a time, replicas leave this to the writer
+func (n *Node) Solidify() {
+	n.solidLock.Lock()
+	if n.solidification.Running || n.solidifyRate <= 0 || n.Cluster.ReadOnly() {
+		n.solidLock.Unlock()
+		return
+	}
+	hs := n.Tangle.Hashes()
+	n.solidification = Solidification{Running: true, Started: time.Now(), Total: len(hs)}
+	n.solidLock.Unlock()
+
+	for _, h := range n.Orphans.Missing() {
+		n.solidifySite(h)
+	}
+	for _, h := range hs {
+		s := n.Tangle.GetSite(h)
+		if s != nil {
+			for _, v := range s.Validates {
+				if n.Tangle.GetSite(v.Hash()) == nil {
+					n.solidifySite(v.Hash())
+				}
+			}
+			if _, err := n.Tangle.GetData(s.Type, s.Content); err == datastore.ErrNotFound {
+				n.solidifyData(s.Type, s.Content)
+			}
+		}
+		n.progress(func(s *Solidification) { s.Checked++ })
+	}
+
+	n.progress(func(s *Solidification) {
+		s.Running = false
+		s.Finished = time.Now()
+		if s.Missing > 0 {
+			log.Infof("Solidification fetched %d of %d missing sites and payloads", s.Fetched, s.Missing)
+		}
+	})
+}
+
+// solidifySite fetches a missing parent. The site passes through receive, so its own missing parents are adopted
+func (n *Node) solidifySite(h hash.Hash) {
+	n.progress(func(s *Solidification) { s.Missing++ })
+	time.Sleep(time.Second / time.Duration(n.solidifyRate))
+	p, err := n.FetchSite(h)
+	if err == nil {
+		err = n.receive(p, false, decision.SourceSolidify)
+	}
+	if err != nil {
+		logging.Debugf("sync", "Could not solidify site %s: %s", h, err)
+		n.progress(func(s *Solidification) { s.Failed++ })
+		return
+	}
+	n.progress(func(s *Solidification) { s.Fetched++ })
+}
+
+// solidifyData fetches a missing payload
+func (n *Node) solidifyData(typ string, content hash.Hash) {
+	n.progress(func(s *Solidification) { s.Missing++ })
+	time.Sleep(time.Second / time.Duration(n.solidifyRate))
+	if _, err := n.FetchData(typ, content); err != nil {
+		logging.Debugf("sync", "Could not solidify payload %s: %s", content, err)
+		n.progress(func(s *Solidification) { s.Failed++ })
+		return
+	}
+	n.progress(func(s *Solidification) { s.Fetched++ })
+}
@@ -0,0 +1,50 @@
+package node
+
+import (
+	"errors"
+
+	"github.com/u-speak/core/peer"
+)
+
+var errNotConnected = errors.New("Not connected to this peer")
+
+// SyncSources ranks the connected peers as sources to synchronise and fetch sites from.
+// Peers in maintenance mode are left out
+func (n *Node) SyncSources() []peer.Source {
+	as := []string{}
+	for _, r := range n.remotes() {
+		if n.draining(r) {
+			continue
+		}
+		as = append(as, r)
+	}
+	return peer.Rank(as, n.PreferredSource(), n.Health, n.Peers)
+}
+
+// sources returns the addresses of the connected peers, best sync source first
+func (n *Node) sources() []string {
+	as := []string{}
+	for _, s := range n.SyncSources() {
+		as = append(as, s.Address)
+	}
+	return as
+}
+
+// PreferredSource returns the peer set to be asked first, or an empty string if the ranking decides
+func (n *Node) PreferredSource() string {
+	n.sourceLock.RLock()
+	defer n.sourceLock.RUnlock()
+	return n.preferredSource
+}
+
+// PreferSource overrides the ranking so the connected peer at the address is always asked first.
+// An empty address returns to the ranking
+func (n *Node) PreferSource(address string) error {
+	if address != "" && !n.connected(address) {
+		return errNotConnected
+	}
+	n.sourceLock.Lock()
+	defer n.sourceLock.Unlock()
+	n.preferredSource = address
+	return nil
+}
@@ -0,0 +1,151 @@
+package node
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tombstone"
+
+	log "github.com/sirupsen/logrus"
+	d "github.com/u-speak/core/node/internal"
+)
+
+// MaxPendingTombstones limits the tombstones kept until the site they remove arrives
+const MaxPendingTombstones = 4096
+
+var (
+	// ErrPinned is returned when removing the payload of a pinned site
+	ErrPinned     = errors.New("Site is pinned, remove its pins first")
+	errBuriedData = errors.New("Site is marked as removed but carries a payload")
+	errBuriedPush = errors.New("Removed sites are only sent while synchronising")
+)
+
+// pendingTombstones are tombstones whose target site or its payload was not received yet, by target
+type pendingTombstones struct {
+	byTarget map[hash.Hash]*tangle.Object
+	lock     sync.Mutex
+}
+
+// keep remembers the tombstone until its target arrives. It returns false if too many tombstones are pending
+func (p *pendingTombstones) keep(target hash.Hash, ts *tangle.Object) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.byTarget == nil {
+		p.byTarget = make(map[hash.Hash]*tangle.Object)
+	}
+	if _, ok := p.byTarget[target]; !ok && len(p.byTarget) >= MaxPendingTombstones {
+		return false
+	}
+	p.byTarget[target] = ts
+	return true
+}
+
+// take returns and forgets the tombstone waiting for the target
+func (p *pendingTombstones) take(target hash.Hash) *tangle.Object {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	ts := p.byTarget[target]
+	delete(p.byTarget, target)
+	return ts
+}
+
+// distributable returns the site ready to be sent to a peer. Sites whose payload was removed are sent
+// without it, so peers still learn the structure of the tangle. It returns nil for unknown sites
+func (n *Node) distributable(h hash.Hash) (*d.Site, error) {
+	if o := n.Tangle.Get(h); o != nil {
+		return d.FromObject(o)
+	}
+	s := n.Tangle.GetSite(h)
+	if s == nil {
+		return nil, nil
+	}
+	if _, buried := n.Tangle.Buried(h); !buried {
+		return nil, nil
+	}
+	return d.FromHeader(s), nil
+}
+
+// inject adds a received site. Sites without payload are added as headers,
+// sites removed by a tombstone which arrived before them are added as removed right away
+func (n *Node) inject(o *tangle.Object, tip bool) error {
+	if o.Data == nil {
+		return n.Cluster.Do(func() error { return n.Tangle.InjectHeader(o.Site, tip) })
+	}
+	h := o.Site.Hash()
+	if pending := n.tombstones.take(h); pending != nil {
+		if err := pending.Data.(*tombstone.Tombstone).Authorize(o.Data); err != nil {
+			log.Warnf("Ignoring tombstone %s: %s", pending.Site.Hash(), err)
+		} else if n.Pins.Pinned(h) {
+			log.Infof("Not applying tombstone %s to the pinned site %s", pending.Site.Hash(), h)
+		} else {
+			if err := n.Cluster.Do(func() error { return n.Tangle.InjectHeader(o.Site, tip) }); err != nil {
+				return err
+			}
+			log.Infof("Applying tombstone %s to the received site %s", pending.Site.Hash(), h)
+			return n.Tangle.Bury(h, pending.Site.Hash())
+		}
+	}
+	return n.Cluster.Do(func() error { return n.Tangle.Inject(o, tip) })
+}
+
+// Remove drops the payload of the site and everything derived from it, keeping the site in the tangle.
+// by is the tombstone requesting the removal, or the zero hash if the operator removes it locally.
+// Pinned sites are kept, ErrPinned is returned instead
+func (n *Node) Remove(h, by hash.Hash) error {
+	if n.Pins.Pinned(h) {
+		return ErrPinned
+	}
+	if err := n.Tangle.Bury(h, by); err != nil {
+		return err
+	}
+	n.Previews.Remove(h)
+	if n.Media != nil {
+		if err := n.Media.Remove(h); err != nil {
+			log.Errorf("Could not remove variants of %s: %s", h, err)
+		}
+	}
+	log.Infof("Removed payload of site %s", h)
+	return nil
+}
+
+// bury applies a tombstone added to the tangle if it was signed by the author of the site it removes.
+// Tombstones arriving before their target are applied when the target is received, see inject
+func (n *Node) bury(o *tangle.Object) {
+	ts, ok := o.Data.(*tombstone.Tombstone)
+	if !ok {
+		return
+	}
+	target, err := ts.Target()
+	if err != nil {
+		log.Warnf("Ignoring tombstone %s: %s", o.Site.Hash(), err)
+		return
+	}
+	if _, err := ts.Verify(); err != nil {
+		log.Warnf("Ignoring tombstone %s: %s", o.Site.Hash(), err)
+		return
+	}
+	if _, buried := n.Tangle.Buried(target); buried {
+		log.Infof("Tombstone %s removes already removed site %s", o.Site.Hash(), target)
+		return
+	}
+	orig := n.Tangle.Get(target)
+	if orig == nil {
+		if n.tombstones.keep(target, o) {
+			log.Infof("Tombstone %s removes unknown site %s, applying it once the site arrives", o.Site.Hash(), target)
+		} else {
+			log.Warnf("Ignoring tombstone %s: too many tombstones are waiting for their sites", o.Site.Hash())
+		}
+		return
+	}
+	if err := ts.Authorize(orig.Data); err != nil {
+		log.Warnf("Ignoring tombstone %s: %s", o.Site.Hash(), err)
+		return
+	}
+	if err := n.Remove(target, o.Site.Hash()); err == ErrPinned {
+		log.Infof("Not applying tombstone %s to the pinned site %s", o.Site.Hash(), target)
+	} else if err != nil {
+		log.Errorf("Could not apply tombstone %s: %s", o.Site.Hash(), err)
+	}
+}
@@ -0,0 +1,128 @@
+// Package orphan holds sites which validate sites the node does not know yet, until their parents arrive
+package orphan
+
+import (
+	"sync"
+	"time"
+
+	"github.com/u-speak/core/tangle/hash"
+)
+
+// Orphan is a site waiting for its parents
+type Orphan struct {
+	Value   interface{}
+	missing map[hash.Hash]bool
+	added   time.Time
+}
+
+// Pool keeps at most max orphans by key, evicting the oldest first
+type Pool struct {
+	max     int
+	orphans map[hash.Hash]*Orphan
+	waiting map[hash.Hash]map[hash.Hash]bool
+	lock    sync.Mutex
+}
+
+// New returns an empty pool for at most max orphans
+func New(max int) *Pool {
+	return &Pool{max: max, orphans: make(map[hash.Hash]*Orphan), waiting: make(map[hash.Hash]map[hash.Hash]bool)}
+}
+
+// Add keeps the value until all missing parents are resolved. It reports whether the orphan is new
+func (p *Pool) Add(key hash.Hash, v interface{}, missing []hash.Hash, now time.Time) bool {
+	if p.max <= 0 || len(missing) == 0 {
+		return false
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if _, ok := p.orphans[key]; ok {
+		return false
+	}
+	for len(p.orphans) >= p.max {
+		p.remove(p.oldest())
+	}
+	o := &Orphan{Value: v, missing: make(map[hash.Hash]bool), added: now}
+	for _, m := range missing {
+		o.missing[m] = true
+		if p.waiting[m] == nil {
+			p.waiting[m] = make(map[hash.Hash]bool)
+		}
+		p.waiting[m][key] = true
+	}
+	p.orphans[key] = o
+	return true
+}
+
+// Resolve marks the parent as known and returns the values of the orphans which are no longer missing any parent
+func (p *Pool) Resolve(parent hash.Hash) []interface{} {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	vs := []interface{}{}
+	for key := range p.waiting[parent] {
+		o := p.orphans[key]
+		delete(o.missing, parent)
+		if len(o.missing) == 0 {
+			vs = append(vs, o.Value)
+			p.remove(key)
+		}
+	}
+	delete(p.waiting, parent)
+	return vs
+}
+
+// Missing returns the parents the orphans are waiting for
+func (p *Pool) Missing() []hash.Hash {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	hs := []hash.Hash{}
+	for h := range p.waiting {
+		hs = append(hs, h)
+	}
+	return hs
+}
+
+// Expire drops orphans added before the deadline and returns how many were dropped
+func (p *Pool) Expire(deadline time.Time) int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	n := 0
+	for key, o := range p.orphans {
+		if o.added.Before(deadline) {
+			p.remove(key)
+			n++
+		}
+	}
+	return n
+}
+
+// Len returns the amount of orphans in the pool
+func (p *Pool) Len() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return len(p.orphans)
+}
+
+func (p *Pool) oldest() hash.Hash {
+	var key hash.Hash
+	var first time.Time
+	for k, o := range p.orphans {
+		if first.IsZero() || o.added.Before(first) {
+			key, first = k, o.added
+		}
+	}
+	return key
+}
+
+func (p *Pool) remove(key hash.Hash) {
+	o, ok := p.orphans[key]
+	if !ok {
+		return
+	}
+	for m := range o.missing {
+		delete(p.waiting[m], key)
+		if len(p.waiting[m]) == 0 {
+			delete(p.waiting, m)
+		}
+	}
+	delete(p.orphans, key)
+}
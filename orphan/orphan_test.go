@@ -0,0 +1,35 @@
+package orphan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+func TestPool(t *testing.T) {
+	now := time.Unix(1000, 0)
+	p := New(2)
+	a, b, c := hash.Hash{1}, hash.Hash{2}, hash.Hash{3}
+	assert.False(t, p.Add(hash.Hash{10}, "none", nil, now))
+	assert.True(t, p.Add(hash.Hash{10}, "ab", []hash.Hash{a, b}, now))
+	assert.False(t, p.Add(hash.Hash{10}, "ab", []hash.Hash{a, b}, now))
+	assert.True(t, p.Add(hash.Hash{11}, "a", []hash.Hash{a}, now.Add(time.Second)))
+	assert.ElementsMatch(t, []hash.Hash{a, b}, p.Missing())
+
+	assert.Equal(t, []interface{}{"a"}, p.Resolve(a))
+	assert.Equal(t, 1, p.Len())
+	assert.Equal(t, []hash.Hash{b}, p.Missing())
+	assert.Equal(t, []interface{}{"ab"}, p.Resolve(b))
+	assert.Equal(t, 0, p.Len())
+	assert.Empty(t, p.Resolve(c))
+
+	p.Add(hash.Hash{12}, "old", []hash.Hash{a}, now)
+	p.Add(hash.Hash{13}, "new", []hash.Hash{b}, now.Add(time.Minute))
+	p.Add(hash.Hash{14}, "newer", []hash.Hash{c}, now.Add(2*time.Minute))
+	assert.Equal(t, 2, p.Len())
+	assert.ElementsMatch(t, []hash.Hash{b, c}, p.Missing())
+	assert.Equal(t, 1, p.Expire(now.Add(90*time.Second)))
+	assert.Equal(t, []hash.Hash{c}, p.Missing())
+}
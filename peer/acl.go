@@ -0,0 +1,139 @@
+package peer
+
+import (
+	"errors"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrDenied is returned when a peer is fenced off by the access lists
+	ErrDenied = errors.New("Peer is not allowed to connect")
+	// ErrUnknownList is returned for lists other than Allow and Deny
+	ErrUnknownList = errors.New("Unknown access list")
+	// ErrInvalidEntry is returned for empty entries and malformed networks
+	ErrInvalidEntry = errors.New("Invalid access list entry")
+)
+
+// Names of the access lists
+const (
+	Allow = "allow"
+	Deny  = "deny"
+)
+
+// ACL decides which peers may connect by their address and identity.
+// Entries are IPs, CIDR networks or peer IDs. Denied peers are always rejected,
+// if the allowlist is not empty only peers matching it are accepted
+type ACL struct {
+	lists map[string]map[string]bool
+	lock  sync.RWMutex
+}
+
+// NewACL returns access lists with the given entries
+func NewACL(allow, deny []string) (*ACL, error) {
+	a := &ACL{lists: map[string]map[string]bool{Allow: {}, Deny: {}}}
+	for _, e := range allow {
+		if err := a.Add(Allow, e); err != nil {
+			return nil, err
+		}
+	}
+	for _, e := range deny {
+		if err := a.Add(Deny, e); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// Allowed reports whether a peer with the address and id may connect.
+// The id has to be authenticated by a handshake, peers allowed by id are rejected without one
+func (a *ACL) Allowed(address, id string) bool {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	if matches(a.lists[Deny], address, id) {
+		return false
+	}
+	if len(a.lists[Allow]) == 0 {
+		return true
+	}
+	return matches(a.lists[Allow], address, id)
+}
+
+// Denied reports whether the address or id is on the denylist.
+// It decides whether a handshake is attempted at all, before the id of the peer is authenticated
+func (a *ACL) Denied(address, id string) bool {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return matches(a.lists[Deny], address, id)
+}
+
+// Add puts the entry on the list
+func (a *ACL) Add(list, entry string) error {
+	if entry == "" {
+		return ErrInvalidEntry
+	}
+	if _, _, err := net.ParseCIDR(entry); err != nil && strings.Contains(entry, "/") {
+		return ErrInvalidEntry
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	l, ok := a.lists[list]
+	if !ok {
+		return ErrUnknownList
+	}
+	l[entry] = true
+	return nil
+}
+
+// Remove deletes the entry from the list
+func (a *ACL) Remove(list, entry string) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	l, ok := a.lists[list]
+	if !ok {
+		return ErrUnknownList
+	}
+	delete(l, entry)
+	return nil
+}
+
+// List returns the sorted entries of the list
+func (a *ACL) List(list string) []string {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	es := []string{}
+	for e := range a.lists[list] {
+		es = append(es, e)
+	}
+	sort.Strings(es)
+	return es
+}
+
+// matches reports whether an entry of the list covers the address or id
+func matches(list map[string]bool, address, id string) bool {
+	if id != "" && list[id] {
+		return true
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	if list[host] {
+		return true
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for e := range list {
+		if _, n, err := net.ParseCIDR(e); err == nil && n.Contains(ip) {
+			return true
+		}
+		if o := net.ParseIP(e); o != nil && o.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
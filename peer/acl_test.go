@@ -0,0 +1,36 @@
+package peer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestACL(t *testing.T) {
+	_, err := NewACL([]string{"10.0.0.0/33"}, nil)
+	assert.Equal(t, ErrInvalidEntry, err)
+
+	a, err := NewACL(nil, []string{"10.0.0.0/8", "192.168.1.1", "evil"})
+	assert.NoError(t, err)
+	assert.False(t, a.Allowed("10.1.2.3:6969", ""))
+	assert.False(t, a.Allowed("192.168.1.1:6969", ""))
+	assert.True(t, a.Allowed("192.168.1.2:6969", ""))
+	assert.False(t, a.Allowed("192.168.1.2:6969", "evil"))
+	assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.1", "evil"}, a.List(Deny))
+
+	assert.NoError(t, a.Remove(Deny, "10.0.0.0/8"))
+	assert.True(t, a.Allowed("10.1.2.3:6969", ""))
+	assert.NoError(t, a.Add(Allow, "172.16.0.0/12"))
+	assert.False(t, a.Allowed("10.1.2.3:6969", ""))
+	assert.True(t, a.Allowed("172.16.5.5:6969", ""))
+	assert.NoError(t, a.Add(Allow, "friend"))
+	assert.False(t, a.Allowed("10.1.2.3:6969", ""))
+	assert.False(t, a.Denied("10.1.2.3:6969", ""))
+	assert.True(t, a.Denied("10.1.2.3:6969", "evil"))
+	assert.False(t, a.Allowed("10.1.2.3:6969", "stranger"))
+	assert.True(t, a.Allowed("10.1.2.3:6969", "friend"))
+
+	assert.Equal(t, ErrUnknownList, a.Add("other", "x"))
+	assert.Equal(t, ErrUnknownList, a.Remove("other", "x"))
+	assert.Equal(t, ErrInvalidEntry, a.Add(Deny, ""))
+}
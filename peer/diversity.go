@@ -0,0 +1,64 @@
+package peer
+
+import (
+	"math/rand"
+	"net"
+)
+
+// Subnet returns the /24 network of an IPv4 peer address. Addresses which are not IPv4 are their own subnet
+func Subnet(address string) string {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	ip := net.ParseIP(host).To4()
+	if ip == nil {
+		return host
+	}
+	return ip.Mask(net.CIDRMask(24, 32)).String() + "/24"
+}
+
+// crowding counts the non-static connections per subnet
+func crowding(conns []Conn) map[string]int {
+	c := make(map[string]int)
+	for _, o := range conns {
+		if !o.Static {
+			c[Subnet(o.Address)]++
+		}
+	}
+	return c
+}
+
+// Rotate picks a random non-static outbound connection to drop and a known peer to dial instead,
+// preferring peers from the least crowded subnets. Regularly rotating outbound connections keeps
+// a node from being stuck with peers an attacker placed early. Empty strings mean nothing to rotate
+func Rotate(conns []Conn, known []Peer, r *rand.Rand) (drop string, dial string) {
+	connected := make(map[string]bool)
+	out := []string{}
+	for _, c := range conns {
+		connected[c.Address] = true
+		if !c.Inbound && !c.Static {
+			out = append(out, c.Address)
+		}
+	}
+	crowd := crowding(conns)
+	best := -1
+	cands := []string{}
+	for _, p := range known {
+		if connected[p.Address] || p.Address == "" {
+			continue
+		}
+		n := crowd[Subnet(p.Address)]
+		if best == -1 || n < best {
+			best = n
+			cands = cands[:0]
+		}
+		if n == best {
+			cands = append(cands, p.Address)
+		}
+	}
+	if len(out) == 0 || len(cands) == 0 {
+		return "", ""
+	}
+	return out[r.Intn(len(out))], cands[r.Intn(len(cands))]
+}
@@ -0,0 +1,56 @@
+package peer
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubnet(t *testing.T) {
+	assert.Equal(t, "10.1.2.0/24", Subnet("10.1.2.3:6969"))
+	assert.Equal(t, "10.1.2.0/24", Subnet("10.1.2.200"))
+	assert.Equal(t, "example.com", Subnet("example.com:6969"))
+}
+
+func TestAdmitSubnet(t *testing.T) {
+	tbl := NewTable()
+	tbl.Seen("a", "10.0.0.1:1")
+	tbl.Seen("b", "10.0.0.2:1")
+	tbl.Seen("c", "10.0.0.3:1")
+	tbl.Seen("d", "10.0.1.1:1")
+	tbl.Synced("10.0.0.3:1")
+	s := &Slots{MaxInbound: 8, MaxOutbound: 3, MaxPerSubnet: 2}
+	conns := []Conn{{Address: "10.0.0.1:1"}, {Address: "10.0.0.2:1"}}
+
+	_, err := s.Admit(Conn{Address: "10.0.0.4:1"}, conns, tbl)
+	assert.Equal(t, ErrSubnetFull, err)
+	evict, err := s.Admit(Conn{Address: "10.0.0.3:1"}, conns, tbl)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.2:1", evict)
+	evict, err = s.Admit(Conn{Address: "10.0.0.4:1", Static: true}, conns, tbl)
+	assert.NoError(t, err)
+	assert.Empty(t, evict)
+
+	// The crowded subnet is evicted first once the slots are full
+	conns = []Conn{{Address: "10.0.1.1:1"}, {Address: "10.0.0.1:1"}, {Address: "10.0.0.2:1"}}
+	tbl.Synced("10.0.1.1:1")
+	tbl.Seen("e", "10.0.2.1:1")
+	tbl.Synced("10.0.2.1:1")
+	evict, err = s.Admit(Conn{Address: "10.0.2.1:1"}, conns, tbl)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.2:1", evict)
+}
+
+func TestRotate(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	conns := []Conn{{Address: "10.0.0.1:1"}, {Address: "10.0.0.2:1", Static: true}, {Address: "10.0.2.1:1", Inbound: true}}
+	known := []Peer{{Address: "10.0.0.1:1"}, {Address: "10.0.0.5:1"}, {Address: "10.0.1.1:1"}}
+	drop, dial := Rotate(conns, known, r)
+	assert.Equal(t, "10.0.0.1:1", drop)
+	assert.Equal(t, "10.0.1.1:1", dial)
+
+	drop, dial = Rotate(conns, known[:1], r)
+	assert.Empty(t, drop)
+	assert.Empty(t, dial)
+}
@@ -0,0 +1,118 @@
+package peer
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// ThroughputSmoothing is the weight of a new measurement in the moving average of the throughput
+const ThroughputSmoothing = 0.3
+
+// Health is the liveness state of a connected peer
+type Health struct {
+	Address  string        `json:"address"`
+	Alive    bool          `json:"alive"`
+	RTT      time.Duration `json:"rtt"`
+	LastPing time.Time     `json:"last_ping"`
+	Misses   int           `json:"misses"`
+	// Throughput is the moving average of bytes per second received from the peer, 0 if nothing was received yet
+	Throughput float64 `json:"throughput"`
+}
+
+// Monitor keeps track of which peers answer pings
+type Monitor struct {
+	peers map[string]*Health
+	lock  sync.RWMutex
+}
+
+// NewMonitor returns an empty monitor
+func NewMonitor() *Monitor {
+	return &Monitor{peers: make(map[string]*Health)}
+}
+
+// Alive records a successful ping of the peer at the address
+func (m *Monitor) Alive(address string, rtt time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	h := m.get(address)
+	h.Alive = true
+	h.RTT = rtt
+	h.LastPing = time.Now()
+	h.Misses = 0
+}
+
+// Missed marks the peer at the address as dead. It reports whether the peer was alive before
+func (m *Monitor) Missed(address string) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	h := m.get(address)
+	was := h.Alive
+	h.Alive = false
+	h.Misses++
+	return was
+}
+
+// Transferred records that size bytes were received from the peer at the address within took
+func (m *Monitor) Transferred(address string, size int, took time.Duration) {
+	if took <= 0 {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	h := m.get(address)
+	bps := float64(size) / took.Seconds()
+	if h.Throughput == 0 {
+		h.Throughput = bps
+		return
+	}
+	h.Throughput = (1-ThroughputSmoothing)*h.Throughput + ThroughputSmoothing*bps
+}
+
+// Get returns a copy of the health of the peer at the address
+func (m *Monitor) Get(address string) Health {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	if h, ok := m.peers[address]; ok {
+		return *h
+	}
+	return Health{Address: address, Alive: true}
+}
+
+// Healthy reports whether the peer is believed to be reachable. Peers that were never pinged are healthy
+func (m *Monitor) Healthy(address string) bool {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	h, ok := m.peers[address]
+	return !ok || h.Alive
+}
+
+// Forget removes the peer from the monitor
+func (m *Monitor) Forget(address string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	delete(m.peers, address)
+}
+
+// Unhealthy returns the addresses of all dead peers
+func (m *Monitor) Unhealthy() []string {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	as := []string{}
+	for a, h := range m.peers {
+		if !h.Alive {
+			as = append(as, a)
+		}
+	}
+	sort.Strings(as)
+	return as
+}
+
+func (m *Monitor) get(address string) *Health {
+	h, ok := m.peers[address]
+	if !ok {
+		h = &Health{Address: address, Alive: true}
+		m.peers[address] = h
+	}
+	return h
+}
@@ -0,0 +1,24 @@
+package peer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMonitor(t *testing.T) {
+	m := NewMonitor()
+	assert.True(t, m.Healthy("a:1"))
+	assert.True(t, m.Missed("a:1"))
+	assert.False(t, m.Missed("a:1"))
+	assert.False(t, m.Healthy("a:1"))
+	m.Alive("b:1", time.Millisecond)
+	assert.Equal(t, []string{"a:1"}, m.Unhealthy())
+	m.Alive("a:1", time.Millisecond)
+	assert.True(t, m.Healthy("a:1"))
+	assert.Empty(t, m.Unhealthy())
+	m.Missed("b:1")
+	m.Forget("b:1")
+	assert.True(t, m.Healthy("b:1"))
+}
@@ -0,0 +1,138 @@
+package peer
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Peer is the state kept for a remote node across address changes
+type Peer struct {
+	ID         string    `json:"id"`
+	Address    string    `json:"address"`
+	Reputation int       `json:"reputation"`
+	FirstSeen  time.Time `json:"first_seen"`
+	LastSeen   time.Time `json:"last_seen"`
+	LastSync   time.Time `json:"last_sync"`
+	Syncs      int       `json:"syncs"`
+	Failures   int       `json:"failures"`
+	Version    string    `json:"version,omitempty"`
+	Commit     string    `json:"commit,omitempty"`
+	Protocol   int       `json:"protocol,omitempty"`
+	// Maintenance is set while the peer announces that it does not accept new sites
+	Maintenance bool `json:"maintenance,omitempty"`
+}
+
+// Table keeps all known peers by id
+type Table struct {
+	peers     map[string]*Peer
+	addresses map[string]string
+	lock      sync.RWMutex
+}
+
+// NewTable returns an empty peer table
+func NewTable() *Table {
+	return &Table{peers: make(map[string]*Peer), addresses: make(map[string]string)}
+}
+
+// Seen records that the peer is reachable at the address.
+// If the peer was known under another address, the old address is returned
+func (t *Table) Seen(id, address string) (string, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	now := time.Now()
+	p, ok := t.peers[id]
+	if !ok {
+		p = &Peer{ID: id, Address: address, FirstSeen: now}
+		t.peers[id] = p
+	}
+	p.LastSeen = now
+	old := p.Address
+	if old == address {
+		t.addresses[address] = id
+		return "", false
+	}
+	delete(t.addresses, old)
+	p.Address = address
+	t.addresses[address] = id
+	return old, true
+}
+
+// Synced records a successful synchronisation with the peer at the address
+func (t *Table) Synced(address string) {
+	t.update(address, func(p *Peer) {
+		p.LastSync = time.Now()
+		p.Syncs++
+		p.Reputation++
+	})
+}
+
+// Built records the build a peer reported in its handshake
+func (t *Table) Built(id, version, commit string, protocol int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if p, ok := t.peers[id]; ok {
+		p.Version = version
+		p.Commit = commit
+		p.Protocol = protocol
+	}
+}
+
+// SetMaintenance records whether the peer at the address is in maintenance mode
+func (t *Table) SetMaintenance(address string, on bool) {
+	t.update(address, func(p *Peer) {
+		p.Maintenance = on
+	})
+}
+
+// Failed records a failed interaction with the peer at the address
+func (t *Table) Failed(address string) {
+	t.update(address, func(p *Peer) {
+		p.Failures++
+		p.Reputation--
+	})
+}
+
+// ByAddress returns the peer currently reachable at the address
+func (t *Table) ByAddress(address string) *Peer {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	p, ok := t.peers[t.addresses[address]]
+	if !ok {
+		return nil
+	}
+	c := *p
+	return &c
+}
+
+// ByID returns the peer with the id
+func (t *Table) ByID(id string) *Peer {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	p, ok := t.peers[id]
+	if !ok {
+		return nil
+	}
+	c := *p
+	return &c
+}
+
+// List returns all known peers ordered by id
+func (t *Table) List() []Peer {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	ps := []Peer{}
+	for _, p := range t.peers {
+		ps = append(ps, *p)
+	}
+	sort.Slice(ps, func(i, j int) bool { return ps[i].ID < ps[j].ID })
+	return ps
+}
+
+func (t *Table) update(address string, f func(*Peer)) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if p, ok := t.peers[t.addresses[address]]; ok {
+		f(p)
+	}
+}
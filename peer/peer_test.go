@@ -0,0 +1,39 @@
+package peer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTable(t *testing.T) {
+	tbl := NewTable()
+	_, changed := tbl.Seen("a", "10.0.0.1:6969")
+	assert.False(t, changed)
+	tbl.Synced("10.0.0.1:6969")
+	tbl.Synced("10.0.0.1:6969")
+	tbl.Failed("10.0.0.1:6969")
+
+	old, changed := tbl.Seen("a", "10.0.0.2:6969")
+	assert.True(t, changed)
+	assert.Equal(t, "10.0.0.1:6969", old)
+	assert.Nil(t, tbl.ByAddress("10.0.0.1:6969"))
+	p := tbl.ByAddress("10.0.0.2:6969")
+	assert.Equal(t, "a", p.ID)
+	assert.Equal(t, 2, p.Syncs)
+	assert.Equal(t, 1, p.Reputation)
+	tbl.Built("a", "1.0.0", "abc", 1)
+	tbl.Built("z", "1.0.0", "abc", 1)
+	p = tbl.ByAddress("10.0.0.2:6969")
+	assert.Equal(t, "1.0.0", p.Version)
+	assert.Equal(t, 1, p.Protocol)
+	tbl.SetMaintenance("10.0.0.2:6969", true)
+	assert.True(t, tbl.ByAddress("10.0.0.2:6969").Maintenance)
+
+	tbl.Seen("b", "10.0.0.3:6969")
+	tbl.Failed("10.0.0.9:6969")
+	ps := tbl.List()
+	assert.Len(t, ps, 2)
+	assert.Equal(t, "b", ps[1].ID)
+	assert.Equal(t, 0, ps[1].Failures)
+}
@@ -0,0 +1,76 @@
+package peer
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// DefaultRTT is assumed for peers which were not pinged yet
+	DefaultRTT = 200 * time.Millisecond
+	// ReferenceSize is the transfer size sync sources are compared by, about one image
+	ReferenceSize = 64 * 1024
+)
+
+// Source is a candidate to synchronise from with the measurements it was ranked by
+type Source struct {
+	Address    string        `json:"address"`
+	Preferred  bool          `json:"preferred"`
+	Alive      bool          `json:"alive"`
+	Reputation int           `json:"reputation"`
+	RTT        time.Duration `json:"rtt"`
+	Throughput float64       `json:"throughput"`
+	// Expected is the estimated time to fetch ReferenceSize bytes
+	Expected time.Duration `json:"expected"`
+}
+
+// expected estimates how long fetching ReferenceSize bytes takes. Unknown throughput only counts the round trip
+func expected(h Health) time.Duration {
+	rtt := h.RTT
+	if rtt <= 0 {
+		rtt = DefaultRTT
+	}
+	if h.Throughput <= 0 {
+		return rtt
+	}
+	return rtt + time.Duration(ReferenceSize/h.Throughput*float64(time.Second))
+}
+
+// Rank orders the addresses by how good they are as sync sources.
+// The preferred address comes first, dead peers and peers with a negative reputation last.
+// Among the others the faster expected transfer wins, then the higher reputation
+func Rank(addresses []string, preferred string, m *Monitor, t *Table) []Source {
+	ss := []Source{}
+	for _, a := range addresses {
+		h := m.Get(a)
+		s := Source{Address: a, Preferred: a == preferred, Alive: h.Alive, RTT: h.RTT, Throughput: h.Throughput, Expected: expected(h)}
+		if p := t.ByAddress(a); p != nil {
+			s.Reputation = p.Reputation
+		}
+		ss = append(ss, s)
+	}
+	class := func(s Source) int {
+		switch {
+		case s.Preferred:
+			return 0
+		case !s.Alive:
+			return 3
+		case s.Reputation < 0:
+			return 2
+		}
+		return 1
+	}
+	sort.SliceStable(ss, func(i, j int) bool {
+		if ci, cj := class(ss[i]), class(ss[j]); ci != cj {
+			return ci < cj
+		}
+		if ss[i].Expected != ss[j].Expected {
+			return ss[i].Expected < ss[j].Expected
+		}
+		if ss[i].Reputation != ss[j].Reputation {
+			return ss[i].Reputation > ss[j].Reputation
+		}
+		return ss[i].Address < ss[j].Address
+	})
+	return ss
+}
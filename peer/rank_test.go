@@ -0,0 +1,49 @@
+package peer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func addresses(ss []Source) []string {
+	as := []string{}
+	for _, s := range ss {
+		as = append(as, s.Address)
+	}
+	return as
+}
+
+func TestRank(t *testing.T) {
+	m := NewMonitor()
+	tbl := NewTable()
+	m.Alive("fast:1", 10*time.Millisecond)
+	m.Transferred("fast:1", 1024*1024, time.Second)
+	m.Alive("slow:1", 10*time.Millisecond)
+	m.Transferred("slow:1", 1024, time.Second)
+	m.Alive("near:1", 5*time.Millisecond)
+	m.Missed("dead:1")
+	tbl.Seen("bad", "bad:1")
+	tbl.Failed("bad:1")
+	tbl.Seen("good", "near:1")
+	tbl.Synced("near:1")
+
+	all := []string{"dead:1", "slow:1", "bad:1", "unknown:1", "fast:1", "near:1"}
+	assert.Equal(t, []string{"near:1", "fast:1", "unknown:1", "slow:1", "bad:1", "dead:1"}, addresses(Rank(all, "", m, tbl)))
+	ss := Rank(all, "slow:1", m, tbl)
+	assert.Equal(t, "slow:1", ss[0].Address)
+	assert.True(t, ss[0].Preferred)
+	assert.Equal(t, 1, ss[1].Reputation)
+	assert.Equal(t, DefaultRTT, ss[3].Expected)
+}
+
+func TestTransferred(t *testing.T) {
+	m := NewMonitor()
+	m.Transferred("a:1", 1000, 0)
+	assert.Zero(t, m.Get("a:1").Throughput)
+	m.Transferred("a:1", 1000, time.Second)
+	assert.Equal(t, 1000.0, m.Get("a:1").Throughput)
+	m.Transferred("a:1", 2000, time.Second)
+	assert.InDelta(t, 1300.0, m.Get("a:1").Throughput, 0.001)
+}
@@ -0,0 +1,86 @@
+package peer
+
+import "errors"
+
+var (
+	// ErrSlotsFull is returned when a connection does not fit into the available slots
+	ErrSlotsFull = errors.New("No free peer slots")
+	// ErrSubnetFull is returned when a subnet already holds MaxPerSubnet connections
+	ErrSubnetFull = errors.New("Too many peers from this subnet")
+)
+
+// Conn is an established connection to a peer
+type Conn struct {
+	Address string
+	Inbound bool
+	Static  bool
+}
+
+// Slots limits the amount of inbound and outbound connections and the connections per /24 subnet
+type Slots struct {
+	MaxInbound   int
+	MaxOutbound  int
+	MaxPerSubnet int
+}
+
+// Admit decides whether a new connection fits into the slots of its direction.
+// If the slots are full the connection to evict is returned. Static peers always get a slot,
+// other peers only replace connections with a lower reputation, ties are won by the longer-lived connection.
+// Connections from crowded subnets are evicted first, and a subnet holding MaxPerSubnet connections only
+// admits a peer by evicting one of its own, so an attacker can not monopolize the slots from a few networks
+func (s *Slots) Admit(c Conn, conns []Conn, t *Table) (string, error) {
+	max := s.MaxOutbound
+	if c.Inbound {
+		max = s.MaxInbound
+	}
+	crowd := crowding(conns)
+	subnet := Subnet(c.Address)
+	full := !c.Static && s.MaxPerSubnet > 0 && crowd[subnet] >= s.MaxPerSubnet
+	used := 0
+	var worst *Peer
+	worstAddr := ""
+	for _, o := range conns {
+		if o.Inbound != c.Inbound {
+			continue
+		}
+		used++
+		if o.Static || (full && Subnet(o.Address) != subnet) {
+			continue
+		}
+		p := t.ByAddress(o.Address)
+		if p == nil {
+			p = &Peer{Address: o.Address}
+		}
+		if worst == nil || crowd[Subnet(o.Address)] > crowd[Subnet(worstAddr)] ||
+			(crowd[Subnet(o.Address)] == crowd[Subnet(worstAddr)] && worse(p, worst)) {
+			worst = p
+			worstAddr = o.Address
+		}
+	}
+	if full {
+		cand := t.ByAddress(c.Address)
+		if worst == nil || cand == nil || cand.Reputation <= worst.Reputation {
+			return "", ErrSubnetFull
+		}
+		return worstAddr, nil
+	}
+	if max <= 0 || used < max {
+		return "", nil
+	}
+	if c.Static {
+		return worstAddr, nil
+	}
+	cand := t.ByAddress(c.Address)
+	if worst == nil || cand == nil || cand.Reputation <= worst.Reputation {
+		return "", ErrSlotsFull
+	}
+	return worstAddr, nil
+}
+
+// worse reports whether a should be evicted before b
+func worse(a, b *Peer) bool {
+	if a.Reputation != b.Reputation {
+		return a.Reputation < b.Reputation
+	}
+	return a.FirstSeen.After(b.FirstSeen)
+}
@@ -0,0 +1,38 @@
+package peer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdmit(t *testing.T) {
+	tbl := NewTable()
+	tbl.Seen("a", "a:1")
+	tbl.Seen("b", "b:1")
+	tbl.Seen("c", "c:1")
+	tbl.Synced("a:1")
+	tbl.Synced("c:1")
+	tbl.Synced("c:1")
+	s := &Slots{MaxInbound: 1, MaxOutbound: 2}
+	conns := []Conn{{Address: "a:1"}, {Address: "s:1", Static: true}, {Address: "b:1", Inbound: true}}
+
+	evict, err := s.Admit(Conn{Address: "x:1"}, conns, tbl)
+	assert.Equal(t, ErrSlotsFull, err)
+	evict, err = s.Admit(Conn{Address: "c:1"}, conns, tbl)
+	assert.NoError(t, err)
+	assert.Equal(t, "a:1", evict)
+	evict, err = s.Admit(Conn{Address: "t:1", Static: true}, conns, tbl)
+	assert.NoError(t, err)
+	assert.Equal(t, "a:1", evict)
+
+	evict, err = s.Admit(Conn{Address: "c:1", Inbound: true}, conns, tbl)
+	assert.NoError(t, err)
+	assert.Equal(t, "b:1", evict)
+	_, err = s.Admit(Conn{Address: "x:1", Inbound: true}, conns, tbl)
+	assert.Equal(t, ErrSlotsFull, err)
+
+	evict, err = (&Slots{}).Admit(Conn{Address: "x:1"}, conns, tbl)
+	assert.NoError(t, err)
+	assert.Empty(t, evict)
+}
@@ -0,0 +1,54 @@
+package pin
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/u-speak/core/tangle/hash"
+)
+
+// MaxCommandAge is how long a signed pin command stays valid. This prevents replaying old commands
+const MaxCommandAge = 5 * time.Minute
+
+var (
+	// ErrInvalidCommand is returned when the signed content is not a pin command
+	ErrInvalidCommand = errors.New("Expected \"pin <hash> <unix time>\" or \"unpin <hash> <unix time>\"")
+	// ErrExpired is returned for commands signed too long ago or in the future
+	ErrExpired = errors.New("Pin command is expired")
+)
+
+// Command is a pin or unpin request signed by a user
+type Command struct {
+	Pin  bool
+	Hash hash.Hash
+	Time time.Time
+}
+
+// ParseCommand parses the content of a signed pin command
+func ParseCommand(s string) (*Command, error) {
+	f := strings.Fields(s)
+	if len(f) != 3 || (f[0] != "pin" && f[0] != "unpin") {
+		return nil, ErrInvalidCommand
+	}
+	c := &Command{Pin: f[0] == "pin"}
+	if err := c.Hash.UnmarshalText([]byte(f[1])); err != nil {
+		return nil, ErrInvalidCommand
+	}
+	ts, err := strconv.ParseInt(f[2], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCommand
+	}
+	c.Time = time.Unix(ts, 0)
+	return c, nil
+}
+
+// Check verifies that the command was signed recently
+func (c *Command) Check(now time.Time) error {
+	d := now.Sub(c.Time)
+	if d > MaxCommandAge || d < -MaxCommandAge {
+		return ErrExpired
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package pin
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/coreos/bbolt"
+	"github.com/u-speak/core/migrate"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+// migrations upgrade the pin database to the current format, see migrate.Open
+var migrations = []migrate.Migration{migrate.None, countOwners}
+
+var (
+	pinBucketName = []byte("pins")
+	// ownerBucketName holds the amount of pins of every user, keyed by owner
+	ownerBucketName = []byte("owners")
+	// ErrNotPinned is returned when removing a pin that does not exist
+	ErrNotPinned = errors.New("Site is not pinned by this owner")
+	// ErrQuota is returned when a user already has as many pins as allowed
+	ErrQuota = errors.New("Pin quota exceeded, remove a pin first")
+)
+
+// Pin exempts a site from retention. Pins set by the node operator have no owner,
+// user pins are owned by the fingerprint of the key that signed them
+type Pin struct {
+	Hash    hash.Hash `json:"hash"`
+	Owner   string    `json:"owner,omitempty"`
+	Created time.Time `json:"created"`
+}
+
+// Store keeps all pins
+type Store struct {
+	db    *bolt.DB
+	quota int
+}
+
+// New opens the pin database at path. Every user may pin at most quota sites, 0 removes the limit.
+// The pins of the operator are not limited
+func New(path string, quota int) (*Store, error) {
+	db, err := migrate.Open(path, 0644, migrations)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(pinBucketName); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(ownerBucketName)
+		return err
+	})
+	return &Store{db: db, quota: quota}, err
+}
+
+func key(h hash.Hash, owner string) []byte {
+	return append(h.Slice(), owner...)
+}
+
+// count returns the amount of pins of the owner
+func count(tx *bolt.Tx, owner string) uint64 {
+	v := tx.Bucket(ownerBucketName).Get([]byte(owner))
+	if len(v) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(v)
+}
+
+// setCount stores the amount of pins of the owner, forgetting owners without pins
+func setCount(tx *bolt.Tx, owner string, n uint64) error {
+	if n == 0 {
+		return tx.Bucket(ownerBucketName).Delete([]byte(owner))
+	}
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint64(v, n)
+	return tx.Bucket(ownerBucketName).Put([]byte(owner), v)
+}
+
+// Add pins the site for the owner. Pinning a site again only renews the pin and does not count against the quota
+func (s *Store) Add(h hash.Hash, owner string) (*Pin, error) {
+	p := &Pin{Hash: h, Owner: owner, Created: time.Now()}
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return p, s.db.Update(func(tx *bolt.Tx) error {
+		pb := tx.Bucket(pinBucketName)
+		if owner != "" && pb.Get(key(h, owner)) == nil {
+			n := count(tx, owner)
+			if s.quota > 0 && n >= uint64(s.quota) {
+				return ErrQuota
+			}
+			if err := setCount(tx, owner, n+1); err != nil {
+				return err
+			}
+		}
+		return pb.Put(key(h, owner), b)
+	})
+}
+
+// Remove deletes the pin of the owner
+func (s *Store) Remove(h hash.Hash, owner string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(pinBucketName)
+		if b.Get(key(h, owner)) == nil {
+			return ErrNotPinned
+		}
+		if owner != "" {
+			if n := count(tx, owner); n > 0 {
+				if err := setCount(tx, owner, n-1); err != nil {
+					return err
+				}
+			}
+		}
+		return b.Delete(key(h, owner))
+	})
+}
+
+// Count returns the amount of sites pinned by the owner
+func (s *Store) Count(owner string) int {
+	n := uint64(0)
+	s.db.View(func(tx *bolt.Tx) error {
+		n = count(tx, owner)
+		return nil
+	})
+	return int(n)
+}
+
+// Pinned reports whether anyone pinned the site
+func (s *Store) Pinned(h hash.Hash) bool {
+	pinned := false
+	s.db.View(func(tx *bolt.Tx) error {
+		k, _ := tx.Bucket(pinBucketName).Cursor().Seek(h.Slice())
+		pinned = k != nil && hash.FromSlice(k[:hash.HashSize]) == h
+		return nil
+	})
+	return pinned
+}
+
+// List returns all pins, optionally restricted to an owner. Pass "-" to list the pins of the operator
+func (s *Store) List(owner string) ([]Pin, error) {
+	ps := []Pin{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(pinBucketName).ForEach(func(k, v []byte) error {
+			p := Pin{}
+			if err := json.Unmarshal(v, &p); err != nil {
+				return err
+			}
+			if owner == "" || owner == p.Owner || (owner == "-" && p.Owner == "") {
+				ps = append(ps, p)
+			}
+			return nil
+		})
+	})
+	sort.Slice(ps, func(i, j int) bool { return ps[i].Created.Before(ps[j].Created) })
+	return ps, err
+}
+
+// countOwners adds the amount of pins of every user, which the quota is checked against
+func countOwners(tx *bolt.Tx) error {
+	if _, err := tx.CreateBucketIfNotExists(ownerBucketName); err != nil {
+		return err
+	}
+	pb := tx.Bucket(pinBucketName)
+	if pb == nil {
+		return nil
+	}
+	counts := map[string]uint64{}
+	err := pb.ForEach(func(k, _ []byte) error {
+		if owner := string(k[hash.HashSize:]); owner != "" {
+			counts[owner]++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for owner, n := range counts {
+		if err := setCount(tx, owner, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the pin database
+func (s *Store) Close() {
+	_ = s.db.Close()
+}
@@ -0,0 +1,90 @@
+package pin
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pin")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	s, err := New(filepath.Join(dir, "pins.db"), 0)
+	assert.NoError(t, err)
+	defer s.Close()
+
+	a, b := hash.New([]byte("a")), hash.New([]byte("b"))
+	_, err = s.Add(a, "")
+	assert.NoError(t, err)
+	_, err = s.Add(a, "abcd")
+	assert.NoError(t, err)
+	assert.True(t, s.Pinned(a))
+	assert.False(t, s.Pinned(b))
+
+	ps, err := s.List("")
+	assert.NoError(t, err)
+	assert.Len(t, ps, 2)
+	ps, err = s.List("-")
+	assert.NoError(t, err)
+	assert.Len(t, ps, 1)
+	assert.Equal(t, "", ps[0].Owner)
+
+	assert.NoError(t, s.Remove(a, ""))
+	assert.True(t, s.Pinned(a))
+	assert.NoError(t, s.Remove(a, "abcd"))
+	assert.False(t, s.Pinned(a))
+	assert.Equal(t, ErrNotPinned, s.Remove(a, "abcd"))
+}
+
+func TestQuota(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pin")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	s, err := New(filepath.Join(dir, "pins.db"), 2)
+	assert.NoError(t, err)
+	defer s.Close()
+
+	a, b, c := hash.New([]byte("a")), hash.New([]byte("b")), hash.New([]byte("c"))
+	for _, h := range []hash.Hash{a, b, a} {
+		_, err = s.Add(h, "abcd")
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 2, s.Count("abcd"))
+	_, err = s.Add(c, "abcd")
+	assert.Equal(t, ErrQuota, err)
+	assert.False(t, s.Pinned(c))
+	_, err = s.Add(c, "ef01")
+	assert.NoError(t, err)
+	_, err = s.Add(c, "")
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.Remove(a, "abcd"))
+	assert.Equal(t, 1, s.Count("abcd"))
+	_, err = s.Add(c, "abcd")
+	assert.NoError(t, err)
+}
+
+func TestParseCommand(t *testing.T) {
+	h := hash.New([]byte("a"))
+	c, err := ParseCommand("pin " + h.String() + " 1000")
+	assert.NoError(t, err)
+	assert.True(t, c.Pin)
+	assert.Equal(t, h, c.Hash)
+	assert.NoError(t, c.Check(c.Time.Add(time.Minute)))
+	assert.Equal(t, ErrExpired, c.Check(c.Time.Add(time.Hour)))
+
+	c, err = ParseCommand("unpin " + h.String() + " 1000")
+	assert.NoError(t, err)
+	assert.False(t, c.Pin)
+
+	for _, s := range []string{"pin", "keep " + h.String() + " 1", "pin abc 1", "pin " + h.String() + " x"} {
+		_, err = ParseCommand(s)
+		assert.Equal(t, ErrInvalidCommand, err)
+	}
+}
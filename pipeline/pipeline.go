@@ -0,0 +1,213 @@
+// Package pipeline updates all indexes derived from the tangle for every added site, and checks them for drift.
+// The pipeline runs as a single tangle listener, so an addition whose stages did not all run before a crash
+// stays pending in the tangle journal and is passed through the whole pipeline again on recovery
+package pipeline
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// MaxFailures is the amount of failed index updates kept for retries
+	MaxFailures = 1024
+	// CheckBatch is the default amount of sites compared to the stages by one check
+	CheckBatch = 4096
+)
+
+// Stage is a derived index
+type Stage struct {
+	Name string
+	// Add indexes the object. Objects which were indexed before have to be ignored
+	Add func(o *tangle.Object) error
+	// Indexed reports whether the object is reflected in the index. Stages without it are only repaired after failures
+	Indexed func(o *tangle.Object) bool
+}
+
+// Failure is a site a stage could not index
+type Failure struct {
+	Stage string    `json:"stage"`
+	Hash  hash.Hash `json:"hash"`
+	Error string    `json:"error"`
+	Time  time.Time `json:"time"`
+}
+
+// Drift is the result of a consistency check. Missing lists the sites not reflected by each stage,
+// Repaired how many of them were indexed again. Next is the last site checked, the next check continues after it.
+// It is empty once a check reached the end of the tangle
+type Drift struct {
+	Checked  int                    `json:"checked"`
+	Next     hash.Hash              `json:"next"`
+	Missing  map[string][]hash.Hash `json:"missing"`
+	Repaired map[string]int         `json:"repaired"`
+	Failures []Failure              `json:"failures"`
+}
+
+type failureKey struct {
+	stage string
+	hash  hash.Hash
+}
+
+// Pipeline runs the registered stages in order
+type Pipeline struct {
+	stages   []Stage
+	failures map[failureKey]Failure
+	// cursor is the last site compared by the previous check
+	cursor hash.Hash
+	lock   sync.RWMutex
+}
+
+// New returns a pipeline without stages
+func New() *Pipeline {
+	return &Pipeline{failures: make(map[failureKey]Failure)}
+}
+
+// Register appends a stage. Stages have to be registered before the first site is added
+func (p *Pipeline) Register(s Stage) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.stages = append(p.stages, s)
+}
+
+// Stages returns the names of the registered stages
+func (p *Pipeline) Stages() []string {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	ns := []string{}
+	for _, s := range p.stages {
+		ns = append(ns, s.Name)
+	}
+	return ns
+}
+
+// Add passes the object through all stages. A failing stage does not keep the others from indexing the object,
+// it is recorded and retried by the next repair
+func (p *Pipeline) Add(o *tangle.Object) {
+	p.lock.RLock()
+	stages := p.stages
+	p.lock.RUnlock()
+	h := o.Site.Hash()
+	for _, s := range stages {
+		err := run(s, o)
+		p.record(s.Name, h, err)
+		if err != nil {
+			log.Errorf("Could not update %s index for site %s: %s", s.Name, h, err)
+		}
+	}
+}
+
+// Failures returns the index updates which failed and were not repaired yet, oldest first
+func (p *Pipeline) Failures() []Failure {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	fs := []Failure{}
+	for _, f := range p.failures {
+		fs = append(fs, f)
+	}
+	sort.Slice(fs, func(i, j int) bool { return fs[i].Time.Before(fs[j].Time) })
+	return fs
+}
+
+// Check compares the stages to up to limit stored sites, continuing in hash order after the sites of the previous check
+// and starting over once the end of the tangle was reached. All failed updates are checked as well.
+// With repair, missing sites and failed updates are indexed again
+func (p *Pipeline) Check(t *tangle.Tangle, repair bool, limit int) Drift {
+	p.lock.RLock()
+	stages, cursor := p.stages, p.cursor
+	p.lock.RUnlock()
+	d := Drift{Missing: make(map[string][]hash.Hash), Repaired: make(map[string]int)}
+	failed := make(map[failureKey]bool)
+	for _, f := range p.Failures() {
+		failed[failureKey{f.Stage, f.Hash}] = true
+		o := t.Get(f.Hash)
+		if o == nil {
+			p.record(f.Stage, f.Hash, nil)
+			continue
+		}
+		for _, s := range stages {
+			if s.Name == f.Stage {
+				p.check(&d, s, o, repair)
+			}
+		}
+	}
+	hs := t.Hashes()
+	sort.Slice(hs, func(i, j int) bool { return bytes.Compare(hs[i][:], hs[j][:]) < 0 })
+	start := sort.Search(len(hs), func(i int) bool { return bytes.Compare(hs[i][:], cursor[:]) > 0 })
+	end := len(hs)
+	if limit > 0 && start+limit < end {
+		end = start + limit
+		d.Next = hs[end-1]
+	}
+	for _, h := range hs[start:end] {
+		o := t.Get(h)
+		if o == nil {
+			continue
+		}
+		d.Checked++
+		for _, s := range stages {
+			if !failed[failureKey{s.Name, h}] && s.Indexed != nil && !s.Indexed(o) {
+				p.check(&d, s, o, repair)
+			}
+		}
+	}
+	p.lock.Lock()
+	p.cursor = d.Next
+	p.lock.Unlock()
+	d.Failures = p.Failures()
+	return d
+}
+
+// Sync compares the stages to all stored sites in a single pass from the start of the tangle and indexes the missing ones.
+// Stages without Indexed are skipped, they have to be restored separately
+func (p *Pipeline) Sync(t *tangle.Tangle) Drift {
+	p.lock.Lock()
+	p.cursor = hash.Hash{}
+	p.lock.Unlock()
+	return p.Check(t, true, 0)
+}
+
+// check records the object as missing from the stage and indexes it again with repair
+func (p *Pipeline) check(d *Drift, s Stage, o *tangle.Object, repair bool) {
+	h := o.Site.Hash()
+	d.Missing[s.Name] = append(d.Missing[s.Name], h)
+	if !repair {
+		return
+	}
+	err := run(s, o)
+	p.record(s.Name, h, err)
+	if err == nil && (s.Indexed == nil || s.Indexed(o)) {
+		d.Repaired[s.Name]++
+	}
+}
+
+// run calls the stage, turning a panic into an error
+func run(s Stage, o *tangle.Object) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return s.Add(o)
+}
+
+func (p *Pipeline) record(stage string, h hash.Hash, err error) {
+	k := failureKey{stage, h}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if err == nil {
+		delete(p.failures, k)
+		return
+	}
+	if _, ok := p.failures[k]; !ok && len(p.failures) >= MaxFailures {
+		return
+	}
+	p.failures[k] = Failure{Stage: stage, Hash: h, Error: err.Error(), Time: time.Now()}
+}
@@ -0,0 +1,89 @@
+package pipeline
+
+import (
+	"errors"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/img"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+	"github.com/u-speak/core/tangle/store"
+	"github.com/u-speak/core/tangle/store/memorystore"
+)
+
+func TestPipeline(t *testing.T) {
+	ms := &memorystore.MemoryStore{}
+	_ = ms.Init(store.Options{})
+	dp := path.Join(os.TempDir(), "testPipelineData.db")
+	defer os.Remove(dp)
+	tngl, err := tangle.New(tangle.Options{Store: ms, DataPath: dp})
+	assert.NoError(t, err)
+	defer tngl.Close()
+
+	indexed := map[hash.Hash]bool{}
+	broken := true
+	p := New()
+	p.Register(Stage{
+		Name:    "images",
+		Add:     func(o *tangle.Object) error { indexed[o.Site.Hash()] = true; return nil },
+		Indexed: func(o *tangle.Object) bool { return o.Site.Type != "image" || indexed[o.Site.Hash()] },
+	})
+	p.Register(Stage{
+		Name: "flaky",
+		Add: func(o *tangle.Object) error {
+			if broken {
+				return errors.New("unavailable")
+			}
+			return nil
+		},
+	})
+	p.Register(Stage{Name: "panics", Add: func(o *tangle.Object) error { panic("bug") }})
+	assert.Equal(t, []string{"images", "flaky", "panics"}, p.Stages())
+	tngl.OnAdd(p.Add)
+
+	i := &img.Image{Raw: []byte{1, 3, 3, 7}}
+	ih, _ := i.Hash()
+	s := &site.Site{Content: ih, Validates: tngl.Tips(), Type: "image"}
+	s.Mine(1)
+	assert.NoError(t, tngl.Inject(&tangle.Object{Site: s, Data: i}, true))
+	assert.True(t, indexed[s.Hash()])
+	assert.Len(t, p.Failures(), 2)
+
+	delete(indexed, s.Hash())
+	d := p.Check(tngl, false, 0)
+	assert.Equal(t, 3, d.Checked)
+	assert.Equal(t, []hash.Hash{s.Hash()}, d.Missing["images"])
+	assert.Equal(t, []hash.Hash{s.Hash()}, d.Missing["flaky"])
+	assert.Empty(t, d.Repaired)
+
+	broken = false
+	d = p.Check(tngl, true, 0)
+	assert.Equal(t, 1, d.Repaired["images"])
+	assert.Equal(t, 1, d.Repaired["flaky"])
+	assert.Equal(t, 0, d.Repaired["panics"])
+	assert.Len(t, d.Failures, 1)
+	assert.Equal(t, "panics", d.Failures[0].Stage)
+	assert.True(t, indexed[s.Hash()])
+
+	delete(indexed, s.Hash())
+	missing := 0
+	for n := 0; n < 3; n++ {
+		d = p.Check(tngl, false, 1)
+		assert.Equal(t, 1, d.Checked)
+		assert.Equal(t, n == 2, d.Next == hash.Hash{})
+		missing += len(d.Missing["images"])
+	}
+	assert.Equal(t, 1, missing)
+	assert.Equal(t, 3, p.Check(tngl, false, 5).Checked)
+
+	p.Check(tngl, false, 2)
+	d = p.Sync(tngl)
+	assert.Equal(t, 3, d.Checked)
+	assert.Equal(t, 1, d.Repaired["images"])
+	assert.True(t, indexed[s.Hash()])
+	assert.Equal(t, hash.Hash{}, d.Next)
+}
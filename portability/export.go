@@ -0,0 +1,69 @@
+package portability
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/u-speak/core/pow"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+// Manifest describes the contents of an export archive
+type Manifest struct {
+	Fingerprint string      `json:"fingerprint"`
+	Exported    time.Time   `json:"exported"`
+	Sites       []hash.Hash `json:"sites"`
+}
+
+// Authored returns all sites signed by the key with the fingerprint, sorted by hash
+func Authored(t *tangle.Tangle, fingerprint string) []*tangle.Object {
+	hs := t.Hashes()
+	sort.Slice(hs, func(i, j int) bool { return hs[i].String() < hs[j].String() })
+	objs := []*tangle.Object{}
+	for _, h := range hs {
+		o := t.Get(h)
+		if o == nil {
+			continue
+		}
+		if s, ok := o.Data.(interface{ Fingerprint() string }); ok && s.Fingerprint() == fingerprint {
+			objs = append(objs, o)
+		}
+	}
+	return objs
+}
+
+// Write creates a zip archive containing a manifest.json and the verification bundle of every site
+// as sites/<hash>.json. Bundles carry the signed content and are weighed with the proof of work algorithm a,
+// so the archive can be verified without a node
+func Write(w io.Writer, fingerprint string, objs []*tangle.Object, a pow.Algorithm, now time.Time) error {
+	z := zip.NewWriter(w)
+	m := Manifest{Fingerprint: fingerprint, Exported: now.UTC(), Sites: []hash.Hash{}}
+	for _, o := range objs {
+		b, err := o.Bundle(a)
+		if err != nil {
+			return err
+		}
+		if err := writeJSON(z, "sites/"+b.Hash.String()+".json", b, now); err != nil {
+			return err
+		}
+		m.Sites = append(m.Sites, b.Hash)
+	}
+	if err := writeJSON(z, "manifest.json", m, now); err != nil {
+		return err
+	}
+	return z.Close()
+}
+
+func writeJSON(z *zip.Writer, name string, v interface{}, now time.Time) error {
+	f, err := z.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: now})
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
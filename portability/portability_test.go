@@ -0,0 +1,51 @@
+package portability
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/img"
+	"github.com/u-speak/core/pow"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+)
+
+func TestParseRequest(t *testing.T) {
+	now := time.Now()
+	r, err := ParseRequest("export ABCDEF " + strconv.FormatInt(now.Unix(), 10))
+	assert.NoError(t, err)
+	assert.Equal(t, "abcdef", r.Fingerprint)
+	assert.NoError(t, r.Check(now))
+	assert.Equal(t, ErrExpired, r.Check(now.Add(time.Hour)))
+
+	for _, s := range []string{"", "export abcdef", "pin abcdef 1", "export abcdef soon"} {
+		_, err := ParseRequest(s)
+		assert.Equal(t, ErrInvalidRequest, err)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	i := &img.Image{Raw: []byte("image")}
+	c, _ := i.Hash()
+	o := &tangle.Object{Site: &site.Site{Content: c, Type: "image"}, Data: i}
+	buff := bytes.NewBuffer(nil)
+	assert.NoError(t, Write(buff, "abcdef", []*tangle.Object{o}, pow.Default, time.Now()))
+
+	z, err := zip.NewReader(bytes.NewReader(buff.Bytes()), int64(buff.Len()))
+	assert.NoError(t, err)
+	assert.Len(t, z.File, 2)
+	assert.Equal(t, "sites/"+o.Site.Hash().String()+".json", z.File[0].Name)
+
+	f, err := z.File[1].Open()
+	assert.NoError(t, err)
+	m := Manifest{}
+	assert.NoError(t, json.NewDecoder(f).Decode(&m))
+	assert.Equal(t, "abcdef", m.Fingerprint)
+	assert.Equal(t, []hash.Hash{o.Site.Hash()}, m.Sites)
+}
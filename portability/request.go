@@ -0,0 +1,46 @@
+package portability
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxRequestAge is how long a signed export request stays valid. This prevents replaying old requests
+const MaxRequestAge = 5 * time.Minute
+
+var (
+	// ErrInvalidRequest is returned when the signed content is not an export request
+	ErrInvalidRequest = errors.New("Expected \"export <fingerprint> <unix time>\"")
+	// ErrExpired is returned for requests signed too long ago or in the future
+	ErrExpired = errors.New("Export request is expired")
+)
+
+// Request is an export request signed by the key whose data is exported
+type Request struct {
+	Fingerprint string
+	Time        time.Time
+}
+
+// ParseRequest parses the content of a signed export request
+func ParseRequest(s string) (*Request, error) {
+	f := strings.Fields(s)
+	if len(f) != 3 || f[0] != "export" {
+		return nil, ErrInvalidRequest
+	}
+	ts, err := strconv.ParseInt(f[2], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidRequest
+	}
+	return &Request{Fingerprint: strings.ToLower(f[1]), Time: time.Unix(ts, 0)}, nil
+}
+
+// Check verifies that the request was signed recently
+func (r *Request) Check(now time.Time) error {
+	d := now.Sub(r.Time)
+	if d > MaxRequestAge || d < -MaxRequestAge {
+		return ErrExpired
+	}
+	return nil
+}
@@ -4,6 +4,8 @@ package post
 
 import (
 	"bytes"
+	"encoding/hex"
+	"errors"
 	"io"
 	"strconv"
 	"strings"
@@ -24,18 +26,32 @@ type Post struct {
 	Timestamp int64           `json:"date"`
 }
 
+// ErrNoPubkey is returned when a post without a public key is verified or serialized
+var ErrNoPubkey = errors.New("Post has no public key")
+
 type serializable interface {
 	Serialize(w io.Writer) error
 }
 
 // Hash returns the hashed post for storage
 func (p *Post) Hash() (hash.Hash, error) {
-	h := "C" + p.Content + "D" + strconv.FormatInt(p.Timestamp, 10) + "P" + p.Pubkey.PrimaryKey.KeyIdString() + "S" + p.Signature
-	return hash.New([]byte(h)), nil
+	return hash.New(p.Canonical()), nil
+}
+
+// Canonical returns the bytes the hash of the post is computed from
+func (p *Post) Canonical() []byte {
+	id := ""
+	if p.hasPubkey() {
+		id = p.Pubkey.PrimaryKey.KeyIdString()
+	}
+	return []byte("C" + p.Content + "D" + strconv.FormatInt(p.Timestamp, 10) + "P" + id + "S" + p.Signature)
 }
 
 // Verify returns no error when the signature is valid
 func (p *Post) Verify() (*openpgp.Entity, error) {
+	if !p.hasPubkey() {
+		return nil, ErrNoPubkey
+	}
 	var kr openpgp.EntityList
 	kr = append(kr, p.Pubkey)
 	return openpgp.CheckArmoredDetachedSignature(kr, strings.NewReader(p.Content), strings.NewReader(p.Signature))
@@ -51,6 +67,9 @@ func (p *Post) Serialize() ([]byte, error) {
 }
 
 func (p *Post) storePGPStr() error {
+	if !p.hasPubkey() {
+		return ErrNoPubkey
+	}
 	pk, err := asciiEncode(p.Pubkey, openpgp.PublicKeyType)
 	if err != nil {
 		return err
@@ -89,6 +108,44 @@ func (p *Post) Type() string {
 	return "post"
 }
 
+// Fingerprint returns the hex encoded fingerprint of the authors key
+func (p *Post) Fingerprint() string {
+	if !p.hasPubkey() {
+		return ""
+	}
+	return hex.EncodeToString(p.Pubkey.PrimaryKey.Fingerprint[:])
+}
+
+// ArmoredPubkey returns the ascii armored public key of the author
+func (p *Post) ArmoredPubkey() (string, error) {
+	if err := p.storePGPStr(); err != nil {
+		return "", err
+	}
+	return p.PubkeyStr, nil
+}
+
+func (p *Post) hasPubkey() bool {
+	return p.Pubkey != nil && p.Pubkey.PrimaryKey != nil
+}
+
+// Tags returns the lowercased #hashtags contained in the post
+func (p *Post) Tags() []string {
+	tags := []string{}
+	seen := make(map[string]bool)
+	for _, w := range strings.Fields(p.Content) {
+		if len(w) < 2 || w[0] != '#' {
+			continue
+		}
+		t := strings.ToLower(strings.TrimRight(w[1:], ".,;:!?"))
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		tags = append(tags, t)
+	}
+	return tags
+}
+
 func asciiDecodeEntity(s string) (*openpgp.Entity, error) {
 	buff := strings.NewReader(s)
 	block, err := armor.Decode(buff)
@@ -86,3 +86,40 @@ bATkpmKdxC/qCgICRaXugOdehrLpoEEMSrHlc3xG3bmSvNa0U7Y=
 	assert.NoError(t, err)
 	t.Log(enc)
 }
+
+func TestTags(t *testing.T) {
+	p := &Post{Content: "Hello #World, this is #uspeak.\n#world #"}
+	assert.Equal(t, []string{"world", "uspeak"}, p.Tags())
+}
+
+func FuzzDeserialize(f *testing.F) {
+	b, err := post(nil).Serialize()
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(b)
+	f.Add([]byte{})
+	empty, _ := (&Post{Content: "foo"}).MarshalMsg(nil)
+	f.Add(empty)
+	f.Fuzz(func(t *testing.T, b []byte) {
+		p := &Post{}
+		if err := p.Deserialize(b); err != nil {
+			return
+		}
+		_, _ = p.Hash()
+		_, _ = p.Verify()
+		_, _ = p.Serialize()
+		_ = p.Fingerprint()
+	})
+}
+
+func TestNoPubkey(t *testing.T) {
+	p := &Post{Content: "foo"}
+	_, err := p.Hash()
+	assert.NoError(t, err)
+	_, err = p.Verify()
+	assert.Equal(t, ErrNoPubkey, err)
+	_, err = p.Serialize()
+	assert.Equal(t, ErrNoPubkey, err)
+	assert.Equal(t, "", p.Fingerprint())
+}
@@ -0,0 +1,96 @@
+// Package pow defines the proof of work algorithms a network can require from new sites.
+// The weight of a site is the amount of leading zero bytes of its proof. The builtin network uses the hash of the site itself as proof,
+// networks with a genesis file can pick a memory-hard algorithm instead to make specialized hardware less effective
+package pow
+
+import (
+	"errors"
+
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+	"golang.org/x/crypto/argon2"
+)
+
+// Names of the builtin algorithms
+const (
+	HashWeight = "hashweight"
+	Argon2id   = "argon2id"
+)
+
+// ErrUnknownAlgorithm is returned for algorithm names which are not builtin
+var ErrUnknownAlgorithm = errors.New("Unknown proof of work algorithm")
+
+// Algorithm computes the proof of work of a site
+type Algorithm interface {
+	Name() string
+	// Proof returns the proof of the canonical bytes of a site
+	Proof(canonical []byte) hash.Hash
+}
+
+// Default is the algorithm of the builtin network
+var Default Algorithm = hashWeight{}
+
+var algorithms = map[string]Algorithm{
+	HashWeight: Default,
+	Argon2id:   Argon2{Time: 1, Memory: 16 * 1024, Threads: 1},
+}
+
+// Get returns the builtin algorithm with the name. An empty name returns Default
+func Get(name string) (Algorithm, error) {
+	if name == "" {
+		return Default, nil
+	}
+	a, ok := algorithms[name]
+	if !ok {
+		return nil, ErrUnknownAlgorithm
+	}
+	return a, nil
+}
+
+// Weight returns the weight of the site under the algorithm
+func Weight(a Algorithm, s *site.Site) int {
+	if _, ok := a.(hashWeight); ok {
+		return s.Hash().Weight()
+	}
+	return a.Proof(s.Canonical()).Weight()
+}
+
+// Mine increases the nonce of the site until it reaches the target weight under the algorithm
+func Mine(a Algorithm, s *site.Site, target int) {
+	for Weight(a, s) < target {
+		s.Nonce++
+	}
+}
+
+// hashWeight uses the hash identifying the site as proof
+type hashWeight struct{}
+
+func (hashWeight) Name() string {
+	return HashWeight
+}
+
+func (hashWeight) Proof(canonical []byte) hash.Hash {
+	return hash.New(canonical)
+}
+
+// argon2Salt separates proofs from other uses of argon2
+var argon2Salt = []byte("u-speak proof of work")
+
+// Argon2 is a memory-hard algorithm. Memory is given in KiB
+type Argon2 struct {
+	Time    uint32
+	Memory  uint32
+	Threads uint8
+}
+
+// Name of the algorithm
+func (Argon2) Name() string {
+	return Argon2id
+}
+
+// Proof derives the proof from the canonical bytes with argon2id
+func (a Argon2) Proof(canonical []byte) hash.Hash {
+	var h hash.Hash
+	copy(h[:], argon2.IDKey(canonical, argon2Salt, a.Time, a.Memory, a.Threads, hash.HashSize))
+	return h
+}
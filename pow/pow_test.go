@@ -0,0 +1,34 @@
+package pow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+)
+
+func TestGet(t *testing.T) {
+	a, err := Get("")
+	assert.NoError(t, err)
+	assert.Equal(t, HashWeight, a.Name())
+	a, err = Get(Argon2id)
+	assert.NoError(t, err)
+	assert.Equal(t, Argon2id, a.Name())
+	_, err = Get("sha1")
+	assert.Equal(t, ErrUnknownAlgorithm, err)
+}
+
+func TestMine(t *testing.T) {
+	s := &site.Site{Content: hash.New([]byte("content")), Type: "post"}
+	Mine(Default, s, 1)
+	assert.True(t, s.Hash().Weight() >= 1)
+	assert.Equal(t, s.Hash().Weight(), Weight(Default, s))
+
+	small := Argon2{Time: 1, Memory: 64, Threads: 1}
+	s = &site.Site{Content: hash.New([]byte("content")), Type: "post"}
+	Mine(small, s, 1)
+	assert.True(t, Weight(small, s) >= 1)
+	assert.Equal(t, small.Proof(s.Canonical()), small.Proof(s.Canonical()))
+	assert.NotEqual(t, s.Hash(), small.Proof(s.Canonical()))
+}
@@ -0,0 +1,120 @@
+package preview
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+// WordsPerMinute is the reading speed used for the reading time estimate
+const WordsPerMinute = 200
+
+// Preview is a short plain text version of a post
+type Preview struct {
+	Snippet     string `json:"snippet"`
+	Truncated   bool   `json:"truncated"`
+	Words       int    `json:"words"`
+	ReadingTime int    `json:"reading_time"`
+}
+
+var (
+	codeBlock  = regexp.MustCompile("(?s)```.*?```")
+	image      = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	link       = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	html       = regexp.MustCompile(`<[^>]+>`)
+	lineMarker = regexp.MustCompile(`(?m)^\s*(#{1,6}|>|[-*+]|\d+\.)\s+`)
+	emphasis   = regexp.MustCompile("[*_~`]+")
+)
+
+// Strip removes the markdown formatting from s, leaving the plain text
+func Strip(s string) string {
+	s = codeBlock.ReplaceAllString(s, " ")
+	s = image.ReplaceAllString(s, "$1")
+	s = link.ReplaceAllString(s, "$1")
+	s = html.ReplaceAllString(s, "")
+	s = lineMarker.ReplaceAllString(s, "")
+	s = emphasis.ReplaceAllString(s, "")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Generate returns the preview of markdown content, cutting the snippet at a word boundary after at most length runes
+func Generate(content string, length int) Preview {
+	text := Strip(content)
+	words := len(strings.Fields(text))
+	p := Preview{Snippet: text, Words: words, ReadingTime: (words + WordsPerMinute - 1) / WordsPerMinute}
+	if utf8.RuneCountInString(text) <= length {
+		return p
+	}
+	cut := string([]rune(text)[:length])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	p.Snippet = strings.TrimRight(cut, " .,;:") + "…"
+	p.Truncated = true
+	return p
+}
+
+// Index stores the previews of all posts
+type Index struct {
+	length   int
+	previews map[hash.Hash]Preview
+	lock     sync.RWMutex
+}
+
+// NewIndex returns an empty index generating snippets of at most length runes
+func NewIndex(length int) *Index {
+	return &Index{length: length, previews: make(map[hash.Hash]Preview)}
+}
+
+// Add generates the preview of the object if it is a post
+func (i *Index) Add(o *tangle.Object) {
+	if o.Site.Type != "post" {
+		return
+	}
+	p := Generate(o.Data.(*post.Post).Content, i.length)
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.previews[o.Site.Hash()] = p
+}
+
+// Indexed reports whether the object is reflected in the index. Only posts have previews
+func (i *Index) Indexed(o *tangle.Object) bool {
+	if o.Site.Type != "post" {
+		return true
+	}
+	_, ok := i.Get(o.Site.Hash())
+	return ok
+}
+
+// Sync generates the previews of all posts stored in the tangle
+func (i *Index) Sync(t *tangle.Tangle) {
+	for _, h := range t.Hashes() {
+		s := t.GetSite(h)
+		if s == nil || s.Type != "post" {
+			continue
+		}
+		if o := t.Get(h); o != nil {
+			i.Add(o)
+		}
+	}
+}
+
+// Get returns the preview of the post with the hash
+func (i *Index) Get(h hash.Hash) (Preview, bool) {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	p, ok := i.previews[h]
+	return p, ok
+}
+
+// Remove deletes the preview of the post with the hash
+func (i *Index) Remove(h hash.Hash) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	delete(i.previews, h)
+}
@@ -0,0 +1,26 @@
+package preview
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrip(t *testing.T) {
+	md := "# Title\n\nSome **bold** and _italic_ text with a [link](http://example.com) and ![pic](a.png).\n\n```\ncode\n```\n> quoted\n- item"
+	assert.Equal(t, "Title Some bold and italic text with a link and pic. quoted item", Strip(md))
+}
+
+func TestGenerate(t *testing.T) {
+	p := Generate("short *post*", 20)
+	assert.Equal(t, Preview{Snippet: "short post", Words: 2, ReadingTime: 1}, p)
+
+	p = Generate("the quick brown fox jumps over the lazy dog", 18)
+	assert.True(t, p.Truncated)
+	assert.Equal(t, "the quick brown…", p.Snippet)
+
+	p = Generate(strings.Repeat("word ", 450), 10)
+	assert.Equal(t, 450, p.Words)
+	assert.Equal(t, 3, p.ReadingTime)
+}
@@ -0,0 +1,127 @@
+package quorum
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+var (
+	// ErrAlreadyPending is returned when a site is proposed twice
+	ErrAlreadyPending = errors.New("Site is already waiting for quorum")
+	// ErrNotEnoughPeers is returned when the quorum can never be reached with the trusted peers
+	ErrNotEnoughPeers = errors.New("Quorum is larger than the amount of trusted peers")
+)
+
+// Pending is a site waiting for acknowledgements of trusted peers
+type Pending struct {
+	Object  *tangle.Object
+	Created time.Time
+	Acks    map[string]bool
+}
+
+// Quorum keeps track of the sites waiting for acknowledgements
+type Quorum struct {
+	size    int
+	peers   map[string]bool
+	timeout time.Duration
+	pending map[hash.Hash]*Pending
+	lock    sync.RWMutex
+}
+
+// New creates a quorum of size out of the trusted peers. Pending sites are dropped after timeout
+func New(size int, peers []string, timeout time.Duration) (*Quorum, error) {
+	if size > len(peers) {
+		return nil, ErrNotEnoughPeers
+	}
+	q := &Quorum{
+		size:    size,
+		peers:   make(map[string]bool),
+		timeout: timeout,
+		pending: make(map[hash.Hash]*Pending),
+	}
+	for _, p := range peers {
+		q.peers[p] = true
+	}
+	return q, nil
+}
+
+// Size returns the amount of acknowledgements needed to commit a site
+func (q *Quorum) Size() int {
+	return q.size
+}
+
+// Peers returns the trusted peers asked for acknowledgements
+func (q *Quorum) Peers() []string {
+	ps := []string{}
+	for p := range q.peers {
+		ps = append(ps, p)
+	}
+	sort.Strings(ps)
+	return ps
+}
+
+// Propose marks the object as pending
+func (q *Quorum) Propose(o *tangle.Object) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	h := o.Site.Hash()
+	if _, ok := q.pending[h]; ok {
+		return ErrAlreadyPending
+	}
+	q.pending[h] = &Pending{Object: o, Created: time.Now(), Acks: make(map[string]bool)}
+	return nil
+}
+
+// Ack records the acknowledgement of a peer. Once the quorum is reached the object is removed from the pending set and returned
+func (q *Quorum) Ack(h hash.Hash, peer string) *tangle.Object {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	p, ok := q.pending[h]
+	if !ok || !q.peers[peer] {
+		return nil
+	}
+	p.Acks[peer] = true
+	if len(p.Acks) < q.size {
+		return nil
+	}
+	delete(q.pending, h)
+	return p.Object
+}
+
+// Get returns the pending entry of a site or nil if the site is not pending
+func (q *Quorum) Get(h hash.Hash) *Pending {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+	return q.pending[h]
+}
+
+// Pending returns all sites waiting for quorum, oldest first
+func (q *Quorum) Pending() []*Pending {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+	ps := []*Pending{}
+	for _, p := range q.pending {
+		ps = append(ps, p)
+	}
+	sort.Slice(ps, func(i, j int) bool { return ps[i].Created.Before(ps[j].Created) })
+	return ps
+}
+
+// Expire drops all sites that did not reach the quorum in time and returns their hashes
+func (q *Quorum) Expire(now time.Time) []hash.Hash {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	hs := []hash.Hash{}
+	for h, p := range q.pending {
+		if now.Sub(p.Created) > q.timeout {
+			delete(q.pending, h)
+			hs = append(hs, h)
+		}
+	}
+	return hs
+}
@@ -0,0 +1,52 @@
+package quorum
+
+import (
+	"testing"
+	"time"
+
+	"github.com/u-speak/core/img"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/site"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func object(raw string) *tangle.Object {
+	i := &img.Image{Raw: []byte(raw)}
+	h, _ := i.Hash()
+	return &tangle.Object{Site: &site.Site{Content: h, Type: "image"}, Data: i}
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(3, []string{"a", "b"}, time.Minute)
+	assert.Equal(t, ErrNotEnoughPeers, err)
+	q, err := New(2, []string{"b", "a"}, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, q.Peers())
+}
+
+func TestAck(t *testing.T) {
+	q, _ := New(2, []string{"a", "b", "c"}, time.Minute)
+	o := object("1337")
+	h := o.Site.Hash()
+	assert.NoError(t, q.Propose(o))
+	assert.Equal(t, ErrAlreadyPending, q.Propose(o))
+	assert.Nil(t, q.Ack(h, "a"))
+	assert.Nil(t, q.Ack(h, "a"))
+	assert.Nil(t, q.Ack(h, "mallory"))
+	assert.Len(t, q.Get(h).Acks, 1)
+	assert.Equal(t, o, q.Ack(h, "c"))
+	assert.Nil(t, q.Get(h))
+	assert.Empty(t, q.Pending())
+}
+
+func TestExpire(t *testing.T) {
+	q, _ := New(1, []string{"a"}, time.Minute)
+	o := object("1337")
+	q.Propose(o)
+	q.Propose(object("4242"))
+	assert.Len(t, q.Pending(), 2)
+	assert.Empty(t, q.Expire(time.Now()))
+	assert.Len(t, q.Expire(time.Now().Add(2*time.Minute)), 2)
+	assert.Nil(t, q.Ack(o.Site.Hash(), "a"))
+}
@@ -0,0 +1,136 @@
+// Package ranking orders posts for the feed. Posts are scored by recency and by their cumulative weight,
+// the amount of sites validating them directly or indirectly, which is how the tangle reflects reactions.
+// The builtin algorithms are latest (recency), top (weight) and hot (weight decayed by age)
+package ranking
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/u-speak/core/config"
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+)
+
+var (
+	// ErrUnknownAlgorithm is returned when ranking with an algorithm which is not registered
+	ErrUnknownAlgorithm = errors.New("Unknown ranking algorithm")
+)
+
+// Candidate is a post considered for the feed, with the signals available to algorithms
+type Candidate struct {
+	Object    *tangle.Object
+	Timestamp int64
+	Weight    int
+	Score     float64
+}
+
+// Algorithm scores a candidate, higher scores are ranked first
+type Algorithm func(c *Candidate, now time.Time) float64
+
+// Ranker orders the posts of a tangle using pluggable algorithms
+type Ranker struct {
+	tangle     *tangle.Tangle
+	algorithms map[string]Algorithm
+	// weights are the cumulative weights of the posts computed at the time in computed, reused for refresh
+	refresh     time.Duration
+	weights     map[hash.Hash]int
+	computed    time.Time
+	weightsLock sync.Mutex
+}
+
+// New returns a ranker with the builtin algorithms latest, top and hot
+func New(c config.Configuration, t *tangle.Tangle) *Ranker {
+	r := &Ranker{tangle: t, algorithms: make(map[string]Algorithm), refresh: time.Duration(c.Ranking.Refresh) * time.Second}
+	r.Register("latest", Latest)
+	r.Register("top", Top)
+	r.Register("hot", Hot(c.Ranking.WeightFactor, c.Ranking.Gravity))
+	return r
+}
+
+// Register adds or replaces an algorithm
+func (r *Ranker) Register(name string, a Algorithm) {
+	r.algorithms[name] = a
+}
+
+// Rank scores all posts with the named algorithm and returns them ordered by descending score
+func (r *Ranker) Rank(name string, now time.Time) ([]*Candidate, error) {
+	a, ok := r.algorithms[name]
+	if !ok {
+		return nil, ErrUnknownAlgorithm
+	}
+	objs := []*tangle.Object{}
+	for _, h := range r.tangle.Hashes() {
+		if o := r.tangle.Get(h); o != nil && o.Site.Type == "post" {
+			objs = append(objs, o)
+		}
+	}
+	ws := r.weigh(objs, now)
+	cs := []*Candidate{}
+	for _, o := range objs {
+		c := &Candidate{Object: o, Timestamp: o.Data.(*post.Post).Timestamp, Weight: ws[o.Site.Hash()]}
+		c.Score = a(c, now)
+		cs = append(cs, c)
+	}
+	sort.SliceStable(cs, func(i, j int) bool {
+		if cs[i].Score == cs[j].Score {
+			return cs[i].Timestamp > cs[j].Timestamp
+		}
+		return cs[i].Score > cs[j].Score
+	})
+	return cs, nil
+}
+
+// weigh returns the cumulative weights of the objects. Weights computed within the refresh interval are reused,
+// only objects added since are weighed
+// The returned map is not changed afterwards, so it can be read after the lock is released
+func (r *Ranker) weigh(objs []*tangle.Object, now time.Time) map[hash.Hash]int {
+	r.weightsLock.Lock()
+	defer r.weightsLock.Unlock()
+	if now.Sub(r.computed) >= r.refresh {
+		r.weights, r.computed = nil, now
+	}
+	ws := make(map[hash.Hash]int, len(objs))
+	missing := []*site.Site{}
+	for _, o := range objs {
+		h := o.Site.Hash()
+		if w, ok := r.weights[h]; ok {
+			ws[h] = w
+		} else {
+			missing = append(missing, o.Site)
+		}
+	}
+	if len(missing) > 0 {
+		for h, w := range r.tangle.Weights(missing) {
+			ws[h] = w
+		}
+	}
+	r.weights = ws
+	return ws
+}
+
+// Latest ranks the newest posts first
+func Latest(c *Candidate, now time.Time) float64 {
+	return float64(c.Timestamp)
+}
+
+// Top ranks posts by their cumulative weight
+func Top(c *Candidate, now time.Time) float64 {
+	return float64(c.Weight)
+}
+
+// Hot decays the cumulative weight of a post with its age in hours
+func Hot(weightFactor, gravity float64) Algorithm {
+	return func(c *Candidate, now time.Time) float64 {
+		age := now.Sub(time.Unix(c.Timestamp, 0)).Hours()
+		if age < 0 {
+			age = 0
+		}
+		return weightFactor * float64(c.Weight) / math.Pow(age+2, gravity)
+	}
+}
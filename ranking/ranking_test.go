@@ -0,0 +1,90 @@
+package ranking
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/config"
+	"github.com/u-speak/core/miner"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+	"github.com/u-speak/core/tangle/store"
+	"github.com/u-speak/core/tangle/store/memorystore"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+)
+
+func TestAlgorithms(t *testing.T) {
+	now := time.Now()
+	old := &Candidate{Timestamp: now.Add(-48 * time.Hour).Unix(), Weight: 20}
+	recent := &Candidate{Timestamp: now.Add(-time.Hour).Unix(), Weight: 5}
+	assert.True(t, Latest(recent, now) > Latest(old, now))
+	assert.True(t, Top(old, now) > Top(recent, now))
+	hot := Hot(1, 1.8)
+	assert.True(t, hot(recent, now) > hot(old, now))
+	assert.True(t, Hot(1, 0)(old, now) > Hot(1, 0)(recent, now))
+}
+
+func TestUnknownAlgorithm(t *testing.T) {
+	r := &Ranker{algorithms: make(map[string]Algorithm)}
+	_, err := r.Rank("nonexistent", time.Now())
+	assert.Equal(t, ErrUnknownAlgorithm, err)
+}
+
+func TestRankWeights(t *testing.T) {
+	ms := &memorystore.MemoryStore{}
+	_ = ms.Init(store.Options{})
+	dp := path.Join(os.TempDir(), "testRankingData.db")
+	defer os.Remove(dp)
+	tngl, err := tangle.New(tangle.Options{Store: ms, DataPath: dp})
+	assert.NoError(t, err)
+	defer tngl.Close()
+	e, err := openpgp.NewEntity("Test", "", "test@example.com", nil)
+	assert.NoError(t, err)
+	buf := &bytes.Buffer{}
+	w, err := armor.Encode(buf, openpgp.PrivateKeyType, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, e.SerializePrivate(w, nil))
+	assert.NoError(t, w.Close())
+	add := func(content string) *site.Site {
+		p, err := miner.SignPost(buf.String(), "", content, time.Now().Unix())
+		assert.NoError(t, err)
+		h, _ := p.Hash()
+		s := &site.Site{Content: h, Type: "post", Validates: tngl.RecommendTips()}
+		s.Mine(1)
+		assert.NoError(t, tngl.Inject(&tangle.Object{Site: s, Data: p}, true))
+		return s
+	}
+	first := add("first")
+
+	c := config.Configuration{}
+	c.Ranking.Refresh = 60
+	r := New(c, tngl)
+	now := time.Now()
+	cs, err := r.Rank("top", now)
+	assert.NoError(t, err)
+	assert.Len(t, cs, 1)
+	assert.Equal(t, tngl.Weight(first), cs[0].Weight)
+
+	second := add("second")
+	cs, err = r.Rank("top", now.Add(time.Second))
+	assert.NoError(t, err)
+	assert.Len(t, cs, 2)
+	weights := map[hash.Hash]int{}
+	for _, c := range cs {
+		weights[c.Object.Site.Hash()] = c.Weight
+	}
+	assert.Equal(t, first.Hash().Weight(), weights[first.Hash()])
+	assert.Equal(t, tngl.Weight(second), weights[second.Hash()])
+
+	cs, err = r.Rank("top", now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, first.Hash(), cs[0].Object.Site.Hash())
+	assert.Equal(t, tngl.Weight(first), cs[0].Weight)
+}
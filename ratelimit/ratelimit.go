@@ -0,0 +1,77 @@
+// Package ratelimit limits how often clients may use expensive endpoints.
+// Every client gets a fixed amount of requests per window, the windows of at most a fixed amount of clients are kept
+package ratelimit
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Limiter counts the requests of each client within its current window
+type Limiter struct {
+	limit   int
+	window  time.Duration
+	max     int
+	clients map[string]*list.Element
+	// order holds the windows by their start, so expired windows are always at the front
+	order *list.List
+	lock  sync.Mutex
+}
+
+type client struct {
+	key   string
+	start time.Time
+	count int
+}
+
+// New returns a limiter allowing limit requests per window to each of up to max clients.
+// Once max clients are tracked, the client with the oldest window is forgotten first
+func New(limit int, window time.Duration, max int) *Limiter {
+	if max < 1 {
+		max = 1
+	}
+	return &Limiter{limit: limit, window: window, max: max, clients: make(map[string]*list.Element), order: list.New()}
+}
+
+// Allow counts a request of the client and reports whether it is within the limit.
+// A limit of zero or less allows every request
+func (l *Limiter) Allow(key string, now time.Time) bool {
+	if l.limit <= 0 {
+		return true
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.expire(now)
+	if e, ok := l.clients[key]; ok {
+		c := e.Value.(*client)
+		if c.count >= l.limit {
+			return false
+		}
+		c.count++
+		return true
+	}
+	for l.order.Len() >= l.max {
+		l.remove(l.order.Front())
+	}
+	l.clients[key] = l.order.PushBack(&client{key: key, start: now, count: 1})
+	return true
+}
+
+// Len returns the amount of tracked clients
+func (l *Limiter) Len() int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.order.Len()
+}
+
+func (l *Limiter) expire(now time.Time) {
+	for e := l.order.Front(); e != nil && now.Sub(e.Value.(*client).start) >= l.window; e = l.order.Front() {
+		l.remove(e)
+	}
+}
+
+func (l *Limiter) remove(e *list.Element) {
+	l.order.Remove(e)
+	delete(l.clients, e.Value.(*client).key)
+}
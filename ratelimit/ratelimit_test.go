@@ -0,0 +1,39 @@
+package ratelimit
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllow(t *testing.T) {
+	now := time.Now()
+	l := New(2, time.Minute, 10)
+	assert.True(t, l.Allow("a", now))
+	assert.True(t, l.Allow("a", now))
+	assert.False(t, l.Allow("a", now.Add(time.Second)))
+	assert.True(t, l.Allow("b", now.Add(time.Second)))
+	assert.True(t, l.Allow("a", now.Add(time.Minute)))
+	assert.Equal(t, 2, l.Len())
+	assert.True(t, l.Allow("c", now.Add(2*time.Minute)))
+	assert.Equal(t, 1, l.Len())
+}
+
+func TestBounded(t *testing.T) {
+	now := time.Now()
+	l := New(1, time.Minute, 3)
+	for i := 0; i < 10; i++ {
+		assert.True(t, l.Allow(strconv.Itoa(i), now))
+	}
+	assert.Equal(t, 3, l.Len())
+	assert.False(t, l.Allow("9", now))
+}
+
+func TestUnlimited(t *testing.T) {
+	l := New(0, time.Minute, 1)
+	for i := 0; i < 10; i++ {
+		assert.True(t, l.Allow("a", time.Now()))
+	}
+}
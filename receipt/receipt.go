@@ -0,0 +1,116 @@
+package receipt
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/coreos/bbolt"
+	"github.com/u-speak/core/identity"
+	"github.com/u-speak/core/migrate"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// migrations upgrade the receipt database to the current format, see migrate.Open
+var migrations = []migrate.Migration{migrate.None}
+
+var (
+	receiptBucketName = []byte("receipts")
+	// ErrNoReceipt is returned when this node issued no receipt for a site
+	ErrNoReceipt = errors.New("No receipt available for this site")
+)
+
+// Signer is the key receipts are signed with, usually the identity of the node
+type Signer interface {
+	PublicKey() []byte
+	Sign(msg []byte) []byte
+}
+
+// Receipt proves that a node accepted a site at a point in time
+type Receipt struct {
+	Hash      hash.Hash `json:"hash"`
+	Node      string    `json:"node"`
+	PublicKey []byte    `json:"public_key"`
+	Accepted  time.Time `json:"accepted"`
+	Signature []byte    `json:"signature"`
+}
+
+// Message returns the bytes covered by the signature
+func (r *Receipt) Message() []byte {
+	return []byte("R" + r.Hash.String() + "N" + r.Node + "T" + strconv.FormatInt(r.Accepted.Unix(), 10))
+}
+
+// Verify checks that the receipt was signed by the node it names
+func (r *Receipt) Verify() bool {
+	return r.Node == identity.IDOf(r.PublicKey) && identity.Verify(r.PublicKey, r.Message(), r.Signature)
+}
+
+// Store issues receipts for accepted sites and keeps them
+type Store struct {
+	signer Signer
+	db     *bolt.DB
+}
+
+// New returns a store signing receipts with s, keeping them at path
+func New(s Signer, path string) (*Store, error) {
+	db, err := migrate.Open(path, 0644, migrations)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(receiptBucketName)
+		return err
+	})
+	return &Store{signer: s, db: db}, err
+}
+
+// Add issues a receipt for the site unless one exists already
+func (s *Store) Add(o *tangle.Object) {
+	h := o.Site.Hash()
+	if _, err := s.Get(h); err == nil {
+		return
+	}
+	if _, err := s.Issue(h, time.Now()); err != nil {
+		log.Errorf("Could not issue receipt for site %s: %s", h, err)
+	}
+}
+
+// Issue signs and stores a receipt for the site
+func (s *Store) Issue(h hash.Hash, accepted time.Time) (*Receipt, error) {
+	pub := s.signer.PublicKey()
+	r := &Receipt{Hash: h, Node: identity.IDOf(pub), PublicKey: pub, Accepted: accepted.UTC().Truncate(time.Second)}
+	r.Signature = s.signer.Sign(r.Message())
+	if r.Signature == nil {
+		return nil, errors.New("Could not sign receipt")
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return r, s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(receiptBucketName).Put(h.Slice(), b)
+	})
+}
+
+// Get returns the receipt issued for a site
+func (s *Store) Get(h hash.Hash) (*Receipt, error) {
+	var r *Receipt
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(receiptBucketName).Get(h.Slice())
+		if b == nil {
+			return ErrNoReceipt
+		}
+		r = &Receipt{}
+		return json.Unmarshal(b, r)
+	})
+	return r, err
+}
+
+// Close closes the receipt database
+func (s *Store) Close() {
+	_ = s.db.Close()
+}
@@ -0,0 +1,39 @@
+package receipt
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/identity"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+func TestStore(t *testing.T) {
+	kp := path.Join(os.TempDir(), "testReceipt.key")
+	p := path.Join(os.TempDir(), "testReceipt.db")
+	defer os.Remove(kp)
+	defer os.Remove(p)
+	i, err := identity.Load(kp)
+	assert.NoError(t, err)
+	s, err := New(i, p)
+	assert.NoError(t, err)
+	defer s.Close()
+
+	h := hash.Hash{1, 3, 3, 7}
+	_, err = s.Get(h)
+	assert.Equal(t, ErrNoReceipt, err)
+	now := time.Now()
+	_, err = s.Issue(h, now)
+	assert.NoError(t, err)
+
+	r, err := s.Get(h)
+	assert.NoError(t, err)
+	assert.Equal(t, i.ID(), r.Node)
+	assert.Equal(t, now.Unix(), r.Accepted.Unix())
+	assert.True(t, r.Verify())
+	r.Accepted = r.Accepted.Add(time.Hour)
+	assert.False(t, r.Verify())
+}
@@ -0,0 +1,107 @@
+package recent
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"sync"
+
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/util"
+)
+
+// Version of the file format written by Save
+const Version = 1
+
+// magic starts every saved window. Windows saved before the format was versioned have no header
+var magic = []byte("USRW")
+
+// ErrVersion is returned when the window was saved in a newer format
+var ErrVersion = errors.New("Replay window was saved in a newer format")
+
+// Window is a rolling set of the most recently seen hashes
+type Window struct {
+	ring []hash.Hash
+	set  map[hash.Hash]struct{}
+	next int
+	full bool
+	lock sync.Mutex
+}
+
+// New returns an empty window remembering up to size hashes
+func New(size int) *Window {
+	if size < 1 {
+		size = 1
+	}
+	return &Window{ring: make([]hash.Hash, size), set: make(map[hash.Hash]struct{}, size)}
+}
+
+// Seen adds the hash to the window and reports whether it was already contained
+func (w *Window) Seen(h hash.Hash) bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if _, ok := w.set[h]; ok {
+		return true
+	}
+	w.add(h)
+	return false
+}
+
+// Contains reports whether the hash was seen recently
+func (w *Window) Contains(h hash.Hash) bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	_, ok := w.set[h]
+	return ok
+}
+
+// Hashes returns the contents of the window from oldest to newest
+func (w *Window) Hashes() []hash.Hash {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	hs := []hash.Hash{}
+	if w.full {
+		hs = append(hs, w.ring[w.next:]...)
+	}
+	return append(hs, w.ring[:w.next]...)
+}
+
+// Save writes the window atomically to path
+func (w *Window) Save(path string) error {
+	b := append(append([]byte{}, magic...), Version)
+	for _, h := range w.Hashes() {
+		b = append(b, h.Slice()...)
+	}
+	return util.WriteFile(path, b, 0644)
+}
+
+// Load adds the hashes saved at path to the window
+func (w *Window) Load(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if len(b)%hash.HashSize == len(magic)+1 && bytes.HasPrefix(b, magic) {
+		if int(b[len(magic)]) > Version {
+			return ErrVersion
+		}
+		b = b[len(magic)+1:]
+	}
+	for i := 0; i+hash.HashSize <= len(b); i += hash.HashSize {
+		w.Seen(hash.FromSlice(b[i : i+hash.HashSize]))
+	}
+	return nil
+}
+
+func (w *Window) add(h hash.Hash) {
+	if w.full {
+		delete(w.set, w.ring[w.next])
+	}
+	w.ring[w.next] = h
+	w.set[h] = struct{}{}
+	w.next++
+	if w.next == len(w.ring) {
+		w.next = 0
+		w.full = true
+	}
+}
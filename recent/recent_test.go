@@ -0,0 +1,41 @@
+package recent
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+func TestWindow(t *testing.T) {
+	w := New(2)
+	a, b, c := hash.New([]byte("a")), hash.New([]byte("b")), hash.New([]byte("c"))
+	assert.False(t, w.Seen(a))
+	assert.True(t, w.Seen(a))
+	assert.False(t, w.Seen(b))
+	assert.False(t, w.Seen(c))
+	assert.False(t, w.Contains(a))
+	assert.True(t, w.Contains(b))
+	assert.Equal(t, []hash.Hash{b, c}, w.Hashes())
+
+	dir, err := ioutil.TempDir("", "recent")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	p := filepath.Join(dir, "recent")
+	assert.NoError(t, w.Save(p))
+	l := New(2)
+	assert.NoError(t, l.Load(p))
+	assert.Equal(t, w.Hashes(), l.Hashes())
+	assert.True(t, l.Contains(c))
+
+	legacy := New(2)
+	assert.NoError(t, ioutil.WriteFile(p, append(b.Slice(), c.Slice()...), 0644))
+	assert.NoError(t, legacy.Load(p))
+	assert.Equal(t, []hash.Hash{b, c}, legacy.Hashes())
+
+	assert.NoError(t, ioutil.WriteFile(p, append(append(append([]byte{}, magic...), Version+1), c.Slice()...), 0644))
+	assert.Equal(t, ErrVersion, New(2).Load(p))
+}
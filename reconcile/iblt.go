@@ -0,0 +1,204 @@
+package reconcile
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/u-speak/core/tangle/hash"
+)
+
+const (
+	// hashCount is the amount of cells every hash is stored in
+	hashCount = 3
+	cellSize  = 4 + hash.HashSize + 8
+)
+
+var (
+	// ErrInvalidTable is returned when tables of different sizes are combined or a serialized table is malformed
+	ErrInvalidTable = errors.New("Invalid lookup table")
+	// ErrUndecodable is returned when the difference is too large for the table
+	ErrUndecodable = errors.New("Lookup table could not be decoded")
+	// ErrDuplicateKey is returned when decoding yields the same hash twice, which only forged tables do
+	ErrDuplicateKey = errors.New("Lookup table contains a hash twice")
+)
+
+type cell struct {
+	count   int32
+	keySum  hash.Hash
+	hashSum uint64
+}
+
+// IBLT is an invertible bloom lookup table over site hashes.
+// Subtracting the tables of two sets and decoding the result yields their difference,
+// with a table size proportional to the size of the difference instead of the sets
+type IBLT struct {
+	cells []cell
+}
+
+// NewIBLT creates an empty table. The size is rounded up to a multiple of the hash count
+func NewIBLT(size int) *IBLT {
+	if r := size % hashCount; r != 0 {
+		size += hashCount - r
+	}
+	if size == 0 {
+		size = hashCount
+	}
+	return &IBLT{cells: make([]cell, size)}
+}
+
+// TableOf creates a table of the given size containing all hashes
+func TableOf(size int, hs []hash.Hash) *IBLT {
+	t := NewIBLT(size)
+	for _, h := range hs {
+		t.Insert(h)
+	}
+	return t
+}
+
+// Size returns the amount of cells in the table
+func (t *IBLT) Size() int {
+	return len(t.cells)
+}
+
+func checksum(h hash.Hash) uint64 {
+	c := hash.New(h[:])
+	return binary.LittleEndian.Uint64(c[:8])
+}
+
+// indices returns one cell per partition of the table
+func (t *IBLT) indices(h hash.Hash) [hashCount]int {
+	c := hash.New(append([]byte("iblt"), h[:]...))
+	part := len(t.cells) / hashCount
+	is := [hashCount]int{}
+	for i := range is {
+		is[i] = i*part + int(binary.LittleEndian.Uint32(c[i*4:])%uint32(part))
+	}
+	return is
+}
+
+func (t *IBLT) update(h hash.Hash, d int32) {
+	cs := checksum(h)
+	for _, i := range t.indices(h) {
+		c := &t.cells[i]
+		c.count += d
+		for j := range c.keySum {
+			c.keySum[j] ^= h[j]
+		}
+		c.hashSum ^= cs
+	}
+}
+
+// Insert adds a hash to the table
+func (t *IBLT) Insert(h hash.Hash) {
+	t.update(h, 1)
+}
+
+// Delete removes a hash from the table
+func (t *IBLT) Delete(h hash.Hash) {
+	t.update(h, -1)
+}
+
+// Subtract returns a table containing the difference between t and o
+func (t *IBLT) Subtract(o *IBLT) (*IBLT, error) {
+	if len(t.cells) != len(o.cells) {
+		return nil, ErrInvalidTable
+	}
+	r := NewIBLT(len(t.cells))
+	for i := range t.cells {
+		a, b := t.cells[i], o.cells[i]
+		r.cells[i].count = a.count - b.count
+		for j := range a.keySum {
+			r.cells[i].keySum[j] = a.keySum[j] ^ b.keySum[j]
+		}
+		r.cells[i].hashSum = a.hashSum ^ b.hashSum
+	}
+	return r, nil
+}
+
+// pure reports whether the cell at index i holds exactly one hash which is stored in that cell
+func (t *IBLT) pure(i int) bool {
+	c := t.cells[i]
+	if (c.count != 1 && c.count != -1) || checksum(c.keySum) != c.hashSum {
+		return false
+	}
+	for _, j := range t.indices(c.keySum) {
+		if j == i {
+			return true
+		}
+	}
+	return false
+}
+
+func (c cell) empty() bool {
+	return c.count == 0 && c.hashSum == 0 && c.keySum == hash.Hash{}
+}
+
+// Decode lists the hashes of a subtracted table.
+// It returns the hashes only contained in the minuend and the hashes only contained in the subtrahend.
+// Every pass decodes at least one hash and a table never holds more hashes than cells,
+// so forged tables are rejected after at most as many passes as there are cells
+func (t *IBLT) Decode() ([]hash.Hash, []hash.Hash, error) {
+	w := NewIBLT(len(t.cells))
+	copy(w.cells, t.cells)
+	pos, neg := []hash.Hash{}, []hash.Hash{}
+	seen := map[hash.Hash]bool{}
+	for pass := 0; pass < len(w.cells); pass++ {
+		progress := false
+		for i := range w.cells {
+			if !w.pure(i) {
+				continue
+			}
+			c := w.cells[i]
+			if seen[c.keySum] {
+				return nil, nil, ErrDuplicateKey
+			}
+			seen[c.keySum] = true
+			if len(seen) > len(w.cells) {
+				return nil, nil, ErrUndecodable
+			}
+			if c.count == 1 {
+				pos = append(pos, c.keySum)
+			} else {
+				neg = append(neg, c.keySum)
+			}
+			w.update(c.keySum, -c.count)
+			progress = true
+		}
+		if !progress {
+			break
+		}
+	}
+	for _, c := range w.cells {
+		if !c.empty() {
+			return nil, nil, ErrUndecodable
+		}
+	}
+	return pos, neg, nil
+}
+
+// Bytes serializes the table
+func (t *IBLT) Bytes() []byte {
+	b := make([]byte, len(t.cells)*cellSize)
+	for i, c := range t.cells {
+		o := b[i*cellSize:]
+		binary.LittleEndian.PutUint32(o, uint32(c.count))
+		copy(o[4:], c.keySum[:])
+		binary.LittleEndian.PutUint64(o[4+hash.HashSize:], c.hashSum)
+	}
+	return b
+}
+
+// ParseIBLT restores a table serialized by Bytes
+func ParseIBLT(b []byte) (*IBLT, error) {
+	if len(b) == 0 || len(b)%cellSize != 0 || (len(b)/cellSize)%hashCount != 0 {
+		return nil, ErrInvalidTable
+	}
+	t := NewIBLT(len(b) / cellSize)
+	for i := range t.cells {
+		c := b[i*cellSize:]
+		t.cells[i].count = int32(binary.LittleEndian.Uint32(c))
+		t.cells[i].keySum = hash.FromSlice(c[4 : 4+hash.HashSize])
+		t.cells[i].hashSum = binary.LittleEndian.Uint64(c[4+hash.HashSize:])
+	}
+	return t, nil
+}
@@ -0,0 +1,62 @@
+package reconcile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIBLT(t *testing.T) {
+	local := hashes(0, 10000)
+	remote := append(hashes(5, 10000), hashes(20000, 20007)...)
+	lt, rt := TableOf(60, local), TableOf(60, remote)
+	d, err := lt.Subtract(rt)
+	assert.NoError(t, err)
+	onlyLocal, onlyRemote, err := d.Decode()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, hashes(0, 5), onlyLocal)
+	assert.ElementsMatch(t, hashes(20000, 20007), onlyRemote)
+
+	same, _ := lt.Subtract(TableOf(60, local))
+	a, b, err := same.Decode()
+	assert.NoError(t, err)
+	assert.Empty(t, a)
+	assert.Empty(t, b)
+}
+
+func TestIBLTUndecodable(t *testing.T) {
+	d, _ := TableOf(6, hashes(0, 100)).Subtract(TableOf(6, hashes(100, 200)))
+	_, _, err := d.Decode()
+	assert.Equal(t, ErrUndecodable, err)
+	_, err = TableOf(6, nil).Subtract(TableOf(9, nil))
+	assert.Equal(t, ErrInvalidTable, err)
+}
+
+func TestIBLTForged(t *testing.T) {
+	h := hashes(0, 1)[0]
+	misplaced := NewIBLT(6)
+	is := misplaced.indices(h)
+	other := 0
+	for other == is[0] || other == is[1] || other == is[2] {
+		other++
+	}
+	misplaced.cells[other] = cell{count: 1, keySum: h, hashSum: checksum(h)}
+	_, _, err := misplaced.Decode()
+	assert.Equal(t, ErrUndecodable, err)
+
+	twice := NewIBLT(6)
+	twice.cells[is[0]] = cell{count: 1, keySum: h, hashSum: checksum(h)}
+	twice.cells[is[1]] = cell{count: 2}
+	_, _, err = twice.Decode()
+	assert.Equal(t, ErrDuplicateKey, err)
+}
+
+func TestIBLTBytes(t *testing.T) {
+	lt := TableOf(30, hashes(0, 50))
+	r, err := ParseIBLT(lt.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, lt, r)
+	assert.Equal(t, 30, r.Size())
+	_, err = ParseIBLT(lt.Bytes()[1:])
+	assert.Equal(t, ErrInvalidTable, err)
+}
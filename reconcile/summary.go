@@ -0,0 +1,103 @@
+package reconcile
+
+import (
+	"errors"
+
+	"github.com/u-speak/core/tangle/hash"
+)
+
+const (
+	// Buckets is the amount of partitions a hash set is split into
+	Buckets = 256
+)
+
+// ErrInvalidSummary is returned when a received summary does not have the expected amount of buckets
+var ErrInvalidSummary = errors.New("Summary has an invalid amount of buckets")
+
+// Summary is a compact fingerprint of a set of hashes.
+// The set is partitioned by the last byte of each hash and every partition is folded into a single digest
+type Summary struct {
+	Root    hash.Hash
+	Buckets [Buckets]hash.Hash
+}
+
+// bucket returns the partition of a hash. The leading bytes of site hashes are zero because of the proof of work, so the last byte is used
+func bucket(h hash.Hash) int {
+	return int(h[hash.HashSize-1])
+}
+
+// Summarize builds the summary of a hash set
+func Summarize(hs []hash.Hash) *Summary {
+	s := &Summary{}
+	for _, h := range hs {
+		b := &s.Buckets[bucket(h)]
+		for i := range b {
+			b[i] ^= h[i]
+		}
+	}
+	root := []byte{}
+	for _, b := range s.Buckets {
+		root = append(root, b[:]...)
+	}
+	s.Root = hash.New(root)
+	return s
+}
+
+// FromSlices restores a summary from its serialized buckets
+func FromSlices(buckets [][]byte) (*Summary, error) {
+	if len(buckets) != Buckets {
+		return nil, ErrInvalidSummary
+	}
+	s := &Summary{}
+	root := []byte{}
+	for i, b := range buckets {
+		s.Buckets[i] = hash.FromSlice(b)
+		root = append(root, s.Buckets[i][:]...)
+	}
+	s.Root = hash.New(root)
+	return s, nil
+}
+
+// Slices serializes the buckets of the summary
+func (s *Summary) Slices() [][]byte {
+	bs := [][]byte{}
+	for _, b := range s.Buckets {
+		bs = append(bs, b.Slice())
+	}
+	return bs
+}
+
+// Differing returns the buckets whose digests do not match
+func (s *Summary) Differing(o *Summary) []int {
+	d := []int{}
+	if s.Root == o.Root {
+		return d
+	}
+	for i := range s.Buckets {
+		if s.Buckets[i] != o.Buckets[i] {
+			d = append(d, i)
+		}
+	}
+	return d
+}
+
+// Filter returns the hashes that fall into the given buckets
+func Filter(hs []hash.Hash, buckets []int) []hash.Hash {
+	want := make(map[int]bool)
+	for _, b := range buckets {
+		want[b] = true
+	}
+	res := []hash.Hash{}
+	for _, h := range hs {
+		if want[bucket(h)] {
+			res = append(res, h)
+		}
+	}
+	return res
+}
+
+// Diff compares the local hashes against the remote hashes of the differing buckets.
+// It returns the hashes missing locally and the hashes missing on the remote
+func Diff(local, remote []hash.Hash, buckets []int) ([]hash.Hash, []hash.Hash) {
+	return hash.Diff(Filter(local, buckets), remote)
+}
@@ -0,0 +1,41 @@
+package reconcile
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+func hashes(from, to int) []hash.Hash {
+	hs := []hash.Hash{}
+	for i := from; i < to; i++ {
+		hs = append(hs, hash.New([]byte(strconv.Itoa(i))))
+	}
+	return hs
+}
+
+func TestSummary(t *testing.T) {
+	a := Summarize(hashes(0, 1000))
+	b := Summarize(append(hashes(500, 1000), hashes(0, 500)...))
+	assert.Equal(t, a.Root, b.Root)
+	assert.Empty(t, a.Differing(b))
+
+	r, err := FromSlices(a.Slices())
+	assert.NoError(t, err)
+	assert.Equal(t, a, r)
+	_, err = FromSlices(a.Slices()[1:])
+	assert.Equal(t, ErrInvalidSummary, err)
+}
+
+func TestDiff(t *testing.T) {
+	local := hashes(0, 1000)
+	remote := append(hashes(2, 1000), hashes(2000, 2003)...)
+	ls, rs := Summarize(local), Summarize(remote)
+	d := ls.Differing(rs)
+	assert.True(t, len(d) > 0 && len(d) <= 5)
+	missingLocal, missingRemote := Diff(local, Filter(remote, d), d)
+	assert.ElementsMatch(t, hashes(2000, 2003), missingLocal)
+	assert.ElementsMatch(t, hashes(0, 2), missingRemote)
+}
@@ -0,0 +1,67 @@
+// Package recovery turns panics in request handlers into logged errors, so a single bad request can not take down the node
+package recovery
+
+import (
+	"net/http"
+	"runtime/debug"
+
+	"github.com/labstack/echo"
+	"github.com/u-speak/core/metrics"
+
+	log "github.com/sirupsen/logrus"
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errPanic is returned to the caller instead of the recovered value, which may contain internals
+const errPanic = "Internal error"
+
+// report logs the recovered value with the stack of the panicking goroutine and counts it
+func report(server, method string, r interface{}) {
+	metrics.Panics.WithLabelValues(server).Inc()
+	log.WithFields(log.Fields{"server": server, "method": method, "stack": string(debug.Stack())}).Errorf("Recovered from panic: %v", r)
+}
+
+// Echo returns a middleware recovering from panics in the handlers of the named server.
+// The request fails with an internal server error
+func Echo(server string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					report(server, c.Request().Method+" "+c.Path(), r)
+					err = echo.NewHTTPError(http.StatusInternalServerError)
+				}
+			}()
+			return next(c)
+		}
+	}
+}
+
+// Unary returns an interceptor recovering from panics in the unary methods of the named server
+func Unary(server string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				report(server, info.FullMethod, r)
+				err = status.Error(codes.Internal, errPanic)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// Stream returns an interceptor recovering from panics in the streaming methods of the named server
+func Stream(server string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				report(server, info.FullMethod, r)
+				err = status.Error(codes.Internal, errPanic)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,47 @@
+package recovery
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestEcho(t *testing.T) {
+	e := echo.New()
+	e.Use(Echo("test"))
+	e.GET("/panic", func(c echo.Context) error { panic("boom") })
+	e.GET("/ok", func(c echo.Context) error { return c.NoContent(http.StatusNoContent) })
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "boom")
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ok", nil))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func TestGRPC(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/test/Panic"}
+	_, err := Unary("test")(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("boom")
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	resp, err := Unary("test")(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+
+	err = Stream("test")(nil, nil, &grpc.StreamServerInfo{FullMethod: "/test/Stream"}, func(srv interface{}, ss grpc.ServerStream) error {
+		panic("boom")
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
@@ -0,0 +1,84 @@
+// Package relay remembers which peers already have a site, so it is not sent back and forth between them
+package relay
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/u-speak/core/tangle/hash"
+)
+
+// MaxSites is the default amount of sites remembered, the oldest site is forgotten first
+const MaxSites = 1 << 16
+
+type entry struct {
+	hash  hash.Hash
+	peers map[string]bool
+	added time.Time
+}
+
+// Cache records by site the peers it was received from or sent to. Entries are dropped after the TTL
+type Cache struct {
+	ttl     time.Duration
+	max     int
+	entries map[hash.Hash]*list.Element
+	// order holds the entries by the time they were added, so expired entries are always at the front
+	order *list.List
+	lock  sync.Mutex
+}
+
+// New returns an empty cache keeping entries for ttl. At most max sites are remembered
+func New(ttl time.Duration, max int) *Cache {
+	if max < 1 {
+		max = 1
+	}
+	return &Cache{ttl: ttl, max: max, entries: make(map[hash.Hash]*list.Element), order: list.New()}
+}
+
+// Mark records that the peer has the site
+func (c *Cache) Mark(h hash.Hash, peer string, now time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	el, ok := c.entries[h]
+	if !ok {
+		for c.order.Len() >= c.max {
+			c.remove(c.order.Front())
+		}
+		el = c.order.PushBack(&entry{hash: h, peers: make(map[string]bool), added: now})
+		c.entries[h] = el
+	}
+	el.Value.(*entry).peers[peer] = true
+}
+
+// Has reports whether the peer is known to have the site
+func (c *Cache) Has(h hash.Hash, peer string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	el, ok := c.entries[h]
+	return ok && el.Value.(*entry).peers[peer]
+}
+
+// Expire drops all entries older than the TTL and returns how many were dropped
+func (c *Cache) Expire(now time.Time) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	n := 0
+	for el := c.order.Front(); el != nil && now.Sub(el.Value.(*entry).added) >= c.ttl; el = c.order.Front() {
+		c.remove(el)
+		n++
+	}
+	return n
+}
+
+// Len returns the amount of sites in the cache
+func (c *Cache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.entries)
+}
+
+func (c *Cache) remove(el *list.Element) {
+	delete(c.entries, el.Value.(*entry).hash)
+	c.order.Remove(el)
+}
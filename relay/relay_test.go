@@ -0,0 +1,42 @@
+package relay
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+func TestCache(t *testing.T) {
+	c := New(time.Minute, MaxSites)
+	now := time.Now()
+	a, b := hash.Hash{1}, hash.Hash{2}
+	c.Mark(a, "p:1", now)
+	c.Mark(a, "q:1", now.Add(30*time.Second))
+	c.Mark(b, "p:1", now.Add(30*time.Second))
+	assert.True(t, c.Has(a, "p:1"))
+	assert.True(t, c.Has(a, "q:1"))
+	assert.False(t, c.Has(b, "q:1"))
+	assert.False(t, c.Has(hash.Hash{3}, "p:1"))
+	assert.Equal(t, 2, c.Len())
+
+	assert.Equal(t, 1, c.Expire(now.Add(time.Minute)))
+	assert.False(t, c.Has(a, "p:1"))
+	assert.True(t, c.Has(b, "p:1"))
+	assert.Equal(t, 1, c.Expire(now.Add(2*time.Minute)))
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestBounded(t *testing.T) {
+	c := New(time.Minute, 2)
+	now := time.Now()
+	c.Mark(hash.Hash{1}, "p:1", now)
+	c.Mark(hash.Hash{2}, "p:1", now)
+	c.Mark(hash.Hash{1}, "q:1", now)
+	c.Mark(hash.Hash{3}, "p:1", now)
+	assert.Equal(t, 2, c.Len())
+	assert.False(t, c.Has(hash.Hash{1}, "q:1"))
+	assert.True(t, c.Has(hash.Hash{2}, "p:1"))
+	assert.True(t, c.Has(hash.Hash{3}, "p:1"))
+}
@@ -0,0 +1,220 @@
+package replay
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+
+	"github.com/u-speak/core/follow"
+	"github.com/u-speak/core/img"
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/subscription"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/datastore"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+	"github.com/u-speak/core/tangle/store"
+	"github.com/u-speak/core/tangle/store/memorystore"
+
+	"github.com/vmihailenco/msgpack"
+)
+
+var (
+	// ErrUnknownSite is returned when an entry validates a site that was not replayed before
+	ErrUnknownSite = errors.New("Entry validates a site missing from the log")
+	// ErrUnknownGenesis is returned when a genesis entry is not a genesis site of the network the log is replayed on
+	ErrUnknownGenesis = errors.New("Genesis entry belongs to another network")
+)
+
+// Entry is a single site in an exported log
+type Entry struct {
+	Validates []hash.Hash
+	Nonce     uint64
+	Content   hash.Hash
+	Type      string
+	Data      []byte
+	Timestamp int64 `msgpack:",omitempty"`
+}
+
+// Step describes the outcome of replaying a single entry
+type Step struct {
+	Index    int       `json:"index"`
+	Hash     hash.Hash `json:"hash"`
+	Expected hash.Hash `json:"expected"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Diverged reports whether the replayed site did not match the expected one
+func (s Step) Diverged() bool {
+	return s.Error != "" || s.Hash != s.Expected
+}
+
+// Result is the summary of a replay
+type Result struct {
+	Steps      int         `json:"steps"`
+	Divergence *Step       `json:"divergence,omitempty"`
+	Tips       []hash.Hash `json:"tips"`
+}
+
+// Export writes all sites of the tangle to w, every site after the sites it validates.
+// Sites are visited in hash order so two identical tangles produce identical logs.
+// Genesis sites are written without data, so a replay can tell whether the log belongs to its network
+func Export(t *tangle.Tangle, w io.Writer) error {
+	hs := t.Hashes()
+	sort.Slice(hs, func(i, j int) bool { return hs[i].String() < hs[j].String() })
+	enc := msgpack.NewEncoder(w)
+	done := make(map[hash.Hash]bool)
+	var visit func(h hash.Hash) error
+	visit = func(h hash.Hash) error {
+		if done[h] {
+			return nil
+		}
+		done[h] = true
+		o := t.Get(h)
+		if o == nil {
+			return ErrUnknownSite
+		}
+		e := Entry{Nonce: o.Site.Nonce, Content: o.Site.Content, Type: o.Site.Type, Timestamp: o.Site.Timestamp}
+		for _, v := range o.Site.Validates {
+			e.Validates = append(e.Validates, v.Hash())
+			if err := visit(v.Hash()); err != nil {
+				return err
+			}
+		}
+		if o.Site.Type == "genesis" {
+			return enc.Encode(&e)
+		}
+		d, err := o.Data.Serialize()
+		if err != nil {
+			return err
+		}
+		e.Data = d
+		return enc.Encode(&e)
+	}
+	for _, h := range hs {
+		if err := visit(h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Hashes replays the log and returns the resulting site hashes in order.
+// The hashes of a healthy node's log can be used as the expected list when replaying another log
+func Hashes(r io.Reader, rules tangle.Options) ([]hash.Hash, error) {
+	hs := []hash.Hash{}
+	res, err := Replay(r, rules, nil, func(s Step) {
+		hs = append(hs, s.Hash)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.Divergence != nil && res.Divergence.Error != "" {
+		return nil, errors.New(res.Divergence.Error)
+	}
+	return hs, nil
+}
+
+// Replay applies the log to a fresh tangle one entry at a time.
+// The tangle is opened with the genesis, weights and proof of work of rules, usually the Rules of the node's tangle.
+// It stops at the first entry that fails to apply or whose hash differs from the expected list.
+// If onStep is not nil it is called after every entry
+func Replay(r io.Reader, rules tangle.Options, expected []hash.Hash, onStep func(Step)) (*Result, error) {
+	dir, err := ioutil.TempDir("", "uspeak-replay")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	ms := &memorystore.MemoryStore{}
+	ms.Init(store.Options{})
+	rules.Store, rules.DataPath = ms, path.Join(dir, "data.db")
+	rules.EncryptionKey, rules.Journal, rules.Observe = nil, nil, nil
+	t, err := tangle.New(rules)
+	if err != nil {
+		return nil, err
+	}
+	defer t.Close()
+
+	res := &Result{}
+	dec := msgpack.NewDecoder(r)
+	for i := 0; ; i++ {
+		e := Entry{}
+		err := dec.Decode(&e)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		s := Step{Index: i}
+		if i < len(expected) {
+			s.Expected = expected[i]
+		}
+		o, err := toObject(t, e)
+		if err == nil {
+			s.Hash = o.Site.Hash()
+			if o.Site.Type == "genesis" {
+				if t.GetSite(s.Hash) == nil {
+					err = ErrUnknownGenesis
+				}
+			} else {
+				err = t.Inject(o, true)
+			}
+		}
+		if err != nil {
+			s.Error = err.Error()
+		}
+		res.Steps++
+		if onStep != nil {
+			onStep(s)
+		}
+		if s.Diverged() && (expected != nil || s.Error != "") {
+			res.Divergence = &s
+			break
+		}
+	}
+	if res.Divergence == nil && res.Steps < len(expected) {
+		res.Divergence = &Step{Index: res.Steps, Expected: expected[res.Steps], Error: "Log ended early"}
+	}
+	for _, s := range t.Tips() {
+		res.Tips = append(res.Tips, s.Hash())
+	}
+	sort.Slice(res.Tips, func(i, j int) bool { return res.Tips[i].String() < res.Tips[j].String() })
+	return res, nil
+}
+
+func toObject(t *tangle.Tangle, e Entry) (*tangle.Object, error) {
+	vs := []*site.Site{}
+	for _, h := range e.Validates {
+		s := t.GetSite(h)
+		if s == nil {
+			return nil, ErrUnknownSite
+		}
+		vs = append(vs, s)
+	}
+	var d datastore.Serializable
+	switch e.Type {
+	case "genesis":
+		return &tangle.Object{Site: &site.Site{Validates: vs, Nonce: e.Nonce, Content: e.Content, Type: e.Type, Timestamp: e.Timestamp}}, nil
+	case "post":
+		d = &post.Post{}
+	case "image":
+		d = &img.Image{}
+	case "subscription":
+		d = &subscription.Subscription{}
+	case "follow":
+		d = &follow.List{}
+	default:
+		return nil, errors.New("Invalid site type")
+	}
+	if err := d.Deserialize(e.Data); err != nil {
+		return nil, err
+	}
+	return &tangle.Object{
+		Site: &site.Site{Validates: vs, Nonce: e.Nonce, Content: e.Content, Type: e.Type, Timestamp: e.Timestamp},
+		Data: d,
+	}, nil
+}
@@ -0,0 +1,94 @@
+package replay
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/img"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+	"github.com/u-speak/core/tangle/store"
+	"github.com/u-speak/core/tangle/store/memorystore"
+)
+
+func testTangle(t *testing.T, o tangle.Options) *tangle.Tangle {
+	ms := &memorystore.MemoryStore{}
+	_ = ms.Init(store.Options{})
+	dp := path.Join(os.TempDir(), "testReplayData.db")
+	os.Remove(dp)
+	o.Store, o.DataPath = ms, dp
+	tngl, err := tangle.New(o)
+	assert.NoError(t, err)
+	vs := tngl.Tips()
+	for _, raw := range []string{"1337", "4242", "9001"} {
+		i := &img.Image{Raw: []byte(raw)}
+		h, _ := i.Hash()
+		o := &tangle.Object{Site: &site.Site{Content: h, Type: "image", Validates: vs}, Data: i}
+		o.Site.Mine(tngl.Required("image").Weight)
+		assert.NoError(t, tngl.Add(o))
+		vs = []*site.Site{o.Site, vs[0]}
+	}
+	return tngl
+}
+
+func TestReplay(t *testing.T) {
+	tngl := testTangle(t, tangle.Options{})
+	defer tngl.Close()
+	buf := &bytes.Buffer{}
+	assert.NoError(t, Export(tngl, buf))
+	log := buf.Bytes()
+
+	hs, err := Hashes(bytes.NewReader(log), tngl.Rules())
+	assert.NoError(t, err)
+	assert.Len(t, hs, 5)
+
+	res, err := Replay(bytes.NewReader(log), tngl.Rules(), hs, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, res.Steps)
+	assert.Nil(t, res.Divergence)
+	assert.Len(t, res.Tips, 1)
+	assert.Equal(t, tngl.Tips()[0].Hash(), res.Tips[0])
+
+	steps := 0
+	hs[1] = hash.New([]byte("diverged"))
+	res, err = Replay(bytes.NewReader(log), tngl.Rules(), hs, func(Step) { steps++ })
+	assert.NoError(t, err)
+	assert.Equal(t, 2, steps)
+	assert.NotNil(t, res.Divergence)
+	assert.Equal(t, 1, res.Divergence.Index)
+	assert.Equal(t, hs[1], res.Divergence.Expected)
+
+	res, err = Replay(bytes.NewReader(log), tngl.Rules(), append(hs, hs[0]), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, res.Divergence.Index)
+	res, err = Replay(bytes.NewReader(log[:0]), tngl.Rules(), hs, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "Log ended early", res.Divergence.Error)
+}
+
+func TestReplayRules(t *testing.T) {
+	gen := []*site.Site{{Content: hash.Hash{1}, Type: "genesis"}, {Content: hash.Hash{2}, Type: "genesis"}}
+	tngl := testTangle(t, tangle.Options{Genesis: gen, Requirements: map[string]tangle.Requirement{"image": {Weight: 2, Validations: 2}}})
+	defer tngl.Close()
+	buf := &bytes.Buffer{}
+	assert.NoError(t, Export(tngl, buf))
+	log := buf.Bytes()
+
+	hs, err := Hashes(bytes.NewReader(log), tngl.Rules())
+	assert.NoError(t, err)
+	assert.Len(t, hs, 5)
+	assert.Contains(t, hs, gen[0].Hash())
+	assert.Contains(t, hs, gen[1].Hash())
+
+	res, err := Replay(bytes.NewReader(log), tangle.Options{}, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, ErrUnknownGenesis.Error(), res.Divergence.Error)
+	res, err = Replay(bytes.NewReader(log), tangle.Options{Genesis: gen, MinWeight: 3}, nil, nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, res.Divergence)
+	assert.Contains(t, res.Divergence.Error, "below the required weight of 3")
+}
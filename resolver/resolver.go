@@ -0,0 +1,111 @@
+package resolver
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	typeA    = 1
+	typeAAAA = 28
+)
+
+// ErrNoAddresses is returned when a name does not resolve to any address
+var ErrNoAddresses = errors.New("Name did not resolve to any address")
+
+// Resolver looks up the addresses of peer hostnames
+type Resolver interface {
+	LookupIP(host string) ([]net.IP, error)
+}
+
+// System uses the resolver of the operating system
+type System struct{}
+
+// LookupIP resolves the host using the system resolver
+func (System) LookupIP(host string) ([]net.IP, error) {
+	return net.LookupIP(host)
+}
+
+// DoH resolves names with DNS-over-HTTPS using the JSON API offered by most public resolvers
+type DoH struct {
+	URL    string
+	Client *http.Client
+}
+
+// New returns a DoH resolver for the given url or the system resolver if the url is empty
+func New(u string) Resolver {
+	if u == "" {
+		return System{}
+	}
+	return &DoH{URL: u, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+type dohResponse struct {
+	Status int `json:"Status"`
+	Answer []struct {
+		Type int    `json:"type"`
+		Data string `json:"data"`
+	} `json:"Answer"`
+}
+
+// LookupIP resolves the A and AAAA records of the host
+func (d *DoH) LookupIP(host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, nil
+	}
+	ips := []net.IP{}
+	for _, t := range []string{"A", "AAAA"} {
+		r, err := d.query(host, t)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range r.Answer {
+			if a.Type != typeA && a.Type != typeAAAA {
+				continue
+			}
+			if ip := net.ParseIP(a.Data); ip != nil {
+				ips = append(ips, ip)
+			}
+		}
+	}
+	if len(ips) == 0 {
+		return nil, ErrNoAddresses
+	}
+	return ips, nil
+}
+
+func (d *DoH) query(host, t string) (*dohResponse, error) {
+	u, err := url.Parse(d.URL)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	q.Set("name", host)
+	q.Set("type", t)
+	u.RawQuery = q.Encode()
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/dns-json")
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("DNS-over-HTTPS resolver returned " + resp.Status)
+	}
+	r := &dohResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(r); err != nil {
+		return nil, err
+	}
+	if r.Status != 0 {
+		return nil, errors.New("DNS-over-HTTPS lookup of " + host + " failed")
+	}
+	return r, nil
+}
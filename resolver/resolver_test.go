@@ -0,0 +1,36 @@
+package resolver
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoH(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/dns-json", r.Header.Get("Accept"))
+		switch r.URL.Query().Get("name") + "/" + r.URL.Query().Get("type") {
+		case "peer.uspeak.io/A":
+			w.Write([]byte(`{"Status":0,"Answer":[{"type":5,"data":"alias.uspeak.io."},{"type":1,"data":"10.0.0.1"}]}`))
+		case "peer.uspeak.io/AAAA":
+			w.Write([]byte(`{"Status":0,"Answer":[{"type":28,"data":"fd00::1"}]}`))
+		default:
+			w.Write([]byte(`{"Status":3}`))
+		}
+	}))
+	defer srv.Close()
+	r := New(srv.URL)
+	ips, err := r.LookupIP("peer.uspeak.io")
+	assert.NoError(t, err)
+	assert.Len(t, ips, 2)
+	assert.True(t, ips[0].Equal(net.ParseIP("10.0.0.1")))
+	_, err = r.LookupIP("unknown.uspeak.io")
+	assert.Error(t, err)
+	ips, err = r.LookupIP("127.0.0.1")
+	assert.NoError(t, err)
+	assert.Len(t, ips, 1)
+	assert.IsType(t, System{}, New(""))
+}
@@ -0,0 +1,164 @@
+package schedule
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSpec is returned for expressions which can not be parsed
+var ErrInvalidSpec = errors.New("Invalid schedule")
+
+// Schedule computes when a task runs next
+type Schedule interface {
+	// Next returns the first activation after t, or the zero time if there is none
+	Next(t time.Time) time.Time
+}
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse reads a cron expression with the fields minute, hour, day of month, month and day of week.
+// Fields are lists of values, ranges and steps like "*/15" or "1-5,7". The macros @hourly, @daily, @weekly,
+// @monthly and @yearly are understood, as well as "@every <duration>" for fixed intervals
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil || d < time.Second {
+			return nil, ErrInvalidSpec
+		}
+		return every(d), nil
+	}
+	if m, ok := macros[spec]; ok {
+		spec = m
+	}
+	fs := strings.Fields(spec)
+	if len(fs) != 5 {
+		return nil, ErrInvalidSpec
+	}
+	c := &cron{}
+	var err error
+	if c.minute, err = field(fs[0], 0, 59); err != nil {
+		return nil, err
+	}
+	if c.hour, err = field(fs[1], 0, 23); err != nil {
+		return nil, err
+	}
+	if c.dom, err = field(fs[2], 1, 31); err != nil {
+		return nil, err
+	}
+	if c.month, err = field(fs[3], 1, 12); err != nil {
+		return nil, err
+	}
+	if c.dow, err = field(fs[4], 0, 7); err != nil {
+		return nil, err
+	}
+	// Sunday can be written as 0 or 7
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	c.anyDom = fs[2] == "*"
+	c.anyDow = fs[4] == "*"
+	return c, nil
+}
+
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+// cron keeps the allowed values of every field as bits
+type cron struct {
+	minute, hour, dom, month, dow uint64
+	anyDom, anyDow                bool
+}
+
+// maxLookahead bounds the search for expressions which never match like the 31st of February
+const maxLookahead = 5
+
+func (c *cron) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxLookahead, 0, 0)
+	for t.Before(limit) {
+		y, m, d := t.Date()
+		switch {
+		case !has(c.month, int(m)):
+			t = time.Date(y, m+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.day(t):
+			t = time.Date(y, m, d+1, 0, 0, 0, 0, t.Location())
+		case !has(c.hour, t.Hour()):
+			t = time.Date(y, m, d, t.Hour()+1, 0, 0, 0, t.Location())
+		case !has(c.minute, t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// day matches either day field if both are restricted, like cron does
+func (c *cron) day(t time.Time) bool {
+	dom := has(c.dom, t.Day())
+	dow := has(c.dow, int(t.Weekday()))
+	switch {
+	case c.anyDom && c.anyDow:
+		return true
+	case c.anyDom:
+		return dow
+	case c.anyDow:
+		return dom
+	}
+	return dom || dow
+}
+
+func has(bits uint64, v int) bool {
+	return bits&(1<<uint(v)) != 0
+}
+
+func field(s string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(s, ",") {
+		step := 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			var err error
+			if step, err = strconv.Atoi(item[i+1:]); err != nil || step < 1 {
+				return 0, ErrInvalidSpec
+			}
+			item = item[:i]
+		}
+		lo, hi := min, max
+		if item != "*" {
+			r := strings.SplitN(item, "-", 2)
+			var err error
+			if lo, err = strconv.Atoi(r[0]); err != nil {
+				return 0, ErrInvalidSpec
+			}
+			hi = lo
+			if len(r) == 2 {
+				if hi, err = strconv.Atoi(r[1]); err != nil {
+					return 0, ErrInvalidSpec
+				}
+			} else if step > 1 {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, ErrInvalidSpec
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
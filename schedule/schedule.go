@@ -0,0 +1,169 @@
+// Package schedule runs recurring maintenance tasks of the node at times given by cron expressions
+package schedule
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrUnknownTask is returned for task names which were never added
+	ErrUnknownTask = errors.New("Unknown task")
+	// ErrRunning is returned when a task is triggered while its last run has not finished
+	ErrRunning = errors.New("Task is already running")
+	// ErrDuplicateTask is returned when a task name is added twice
+	ErrDuplicateTask = errors.New("Task already exists")
+)
+
+// Status is the schedule and the outcome of the last run of a task
+type Status struct {
+	Name      string        `json:"name"`
+	Spec      string        `json:"spec"`
+	Next      time.Time     `json:"next"`
+	Running   bool          `json:"running"`
+	LastStart time.Time     `json:"last_start,omitempty"`
+	LastEnd   time.Time     `json:"last_end,omitempty"`
+	Duration  time.Duration `json:"duration"`
+	LastError string        `json:"last_error,omitempty"`
+	Runs      int           `json:"runs"`
+	Failures  int           `json:"failures"`
+	// Skipped counts activations dropped because the previous run was still going
+	Skipped int `json:"skipped"`
+}
+
+type task struct {
+	Status
+	schedule Schedule
+	run      func() error
+}
+
+// Scheduler runs tasks at their activations, delayed by a random jitter so nodes do not run them in lockstep.
+// A task never runs twice at the same time
+type Scheduler struct {
+	tasks  map[string]*task
+	jitter time.Duration
+	stop   chan struct{}
+	lock   sync.Mutex
+}
+
+// New returns a scheduler delaying every activation by up to jitter
+func New(jitter time.Duration) *Scheduler {
+	return &Scheduler{tasks: make(map[string]*task), jitter: jitter, stop: make(chan struct{})}
+}
+
+// Add registers a task running f at the times given by the cron expression spec
+func (s *Scheduler) Add(name, spec string, f func() error) error {
+	sc, err := Parse(spec)
+	if err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.tasks[name]; ok {
+		return ErrDuplicateTask
+	}
+	s.tasks[name] = &task{Status: Status{Name: name, Spec: spec}, schedule: sc, run: f}
+	return nil
+}
+
+// Start runs every task at its activations until Stop is called
+func (s *Scheduler) Start() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, t := range s.tasks {
+		go s.loop(t)
+	}
+}
+
+// Stop ends all schedules. Running tasks are not interrupted
+func (s *Scheduler) Stop() {
+	close(s.stop)
+}
+
+// Run starts the task immediately in the background
+func (s *Scheduler) Run(name string) error {
+	s.lock.Lock()
+	t, ok := s.tasks[name]
+	s.lock.Unlock()
+	if !ok {
+		return ErrUnknownTask
+	}
+	done, ok := s.begin(t, time.Now())
+	if !ok {
+		return ErrRunning
+	}
+	go done()
+	return nil
+}
+
+// List returns the status of all tasks ordered by name
+func (s *Scheduler) List() []Status {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	ss := []Status{}
+	for _, t := range s.tasks {
+		ss = append(ss, t.Status)
+	}
+	sort.Slice(ss, func(i, j int) bool { return ss[i].Name < ss[j].Name })
+	return ss
+}
+
+func (s *Scheduler) loop(t *task) {
+	for {
+		next := t.schedule.Next(time.Now())
+		if next.IsZero() {
+			return
+		}
+		s.lock.Lock()
+		t.Next = next
+		s.lock.Unlock()
+		wait := time.Until(next)
+		if s.jitter > 0 {
+			wait += time.Duration(rand.Int63n(int64(s.jitter)))
+		}
+		select {
+		case <-time.After(wait):
+		case <-s.stop:
+			return
+		}
+		done, ok := s.begin(t, time.Now())
+		if !ok {
+			log.Warnf("Skipping task %s, its last run has not finished", t.Name)
+			continue
+		}
+		go done()
+	}
+}
+
+// begin marks the task as running and returns the function executing it.
+// It reports false and counts a skipped activation if the task is already running
+func (s *Scheduler) begin(t *task, now time.Time) (func(), bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if t.Running {
+		t.Skipped++
+		return nil, false
+	}
+	t.Running = true
+	t.LastStart = now
+	return func() {
+		err := t.run()
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		t.Running = false
+		t.LastEnd = time.Now()
+		t.Duration = t.LastEnd.Sub(t.LastStart)
+		t.Runs++
+		t.LastError = ""
+		if err != nil {
+			log.Errorf("Task %s failed: %s", t.Name, err)
+			t.Failures++
+			t.LastError = err.Error()
+		}
+	}, true
+}
@@ -0,0 +1,69 @@
+package schedule
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func at(s string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParse(t *testing.T) {
+	for _, s := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "@every 10ms", "@every x"} {
+		_, err := Parse(s)
+		assert.Equal(t, ErrInvalidSpec, err, s)
+	}
+	cases := []struct {
+		spec, from, next string
+	}{
+		{"*/15 * * * *", "2020-01-01 10:07", "2020-01-01 10:15"},
+		{"0 3 * * *", "2020-01-01 10:07", "2020-01-02 03:00"},
+		{"30 2 1 * *", "2020-01-31 10:00", "2020-02-01 02:30"},
+		{"0 0 * * 7", "2020-01-01 00:00", "2020-01-05 00:00"},
+		{"0 0 13 * 5", "2020-01-01 00:00", "2020-01-03 00:00"},
+		{"@hourly", "2020-12-31 23:59", "2021-01-01 00:00"},
+		{"0 9-17/4 * * 1-5", "2020-01-03 18:00", "2020-01-06 09:00"},
+		{"@every 90s", "2020-01-01 10:00", "2020-01-01 10:01"},
+	}
+	for _, c := range cases {
+		s, err := Parse(c.spec)
+		assert.NoError(t, err, c.spec)
+		assert.Equal(t, at(c.next), s.Next(at(c.from)).Truncate(time.Minute), c.spec)
+	}
+	s, _ := Parse("0 0 31 2 *")
+	assert.True(t, s.Next(at("2020-01-01 00:00")).IsZero())
+}
+
+func TestScheduler(t *testing.T) {
+	s := New(0)
+	release := make(chan struct{})
+	assert.NoError(t, s.Add("slow", "@daily", func() error {
+		<-release
+		return errors.New("boom")
+	}))
+	assert.Equal(t, ErrDuplicateTask, s.Add("slow", "@daily", nil))
+	assert.Equal(t, ErrInvalidSpec, s.Add("bad", "never", nil))
+	assert.Equal(t, ErrUnknownTask, s.Run("missing"))
+
+	assert.NoError(t, s.Run("slow"))
+	assert.Equal(t, ErrRunning, s.Run("slow"))
+	st := s.List()[0]
+	assert.True(t, st.Running)
+	assert.Equal(t, 1, st.Skipped)
+
+	close(release)
+	assert.Eventually(t, func() bool { return !s.List()[0].Running }, time.Second, 10*time.Millisecond)
+	st = s.List()[0]
+	assert.Equal(t, 1, st.Runs)
+	assert.Equal(t, 1, st.Failures)
+	assert.Equal(t, "boom", st.LastError)
+	assert.NoError(t, s.Run("slow"))
+}
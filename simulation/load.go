@@ -0,0 +1,108 @@
+package simulation
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/u-speak/core/img"
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/site"
+
+	"golang.org/x/crypto/openpgp"
+)
+
+var words = []string{"tangle", "node", "site", "post", "image", "peer", "hash", "key", "weight", "tip", "#test", "#simulation"}
+
+func generateKeys(n int) ([]*openpgp.Entity, error) {
+	if n < 1 {
+		n = 1
+	}
+	keys := []*openpgp.Entity{}
+	for i := 0; i < n; i++ {
+		e, err := openpgp.NewEntity("Simulation", "", "simulation@uspeak.io", nil)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, e)
+	}
+	return keys, nil
+}
+
+func newPost(r *rand.Rand, e *openpgp.Entity, size int) (*tangle.Object, error) {
+	var b strings.Builder
+	for b.Len() < size {
+		b.WriteString(words[r.Intn(len(words))])
+		b.WriteString(" ")
+	}
+	content := b.String()
+	sig := bytes.NewBuffer(nil)
+	if err := openpgp.ArmoredDetachSignText(sig, e, strings.NewReader(content), nil); err != nil {
+		return nil, err
+	}
+	p := &post.Post{Content: content, Pubkey: e, Signature: sig.String(), Timestamp: time.Now().Unix()}
+	h, err := p.Hash()
+	if err != nil {
+		return nil, err
+	}
+	return &tangle.Object{Site: &site.Site{Content: h, Type: "post"}, Data: p}, nil
+}
+
+func newImage(r *rand.Rand, size int) (*tangle.Object, error) {
+	raw := make([]byte, size)
+	r.Read(raw)
+	i := &img.Image{Raw: raw}
+	h, err := i.Hash()
+	if err != nil {
+		return nil, err
+	}
+	return &tangle.Object{Site: &site.Site{Content: h, Type: "image"}, Data: i}, nil
+}
+
+// next returns the time of the next event of a poisson process with the rate per second
+func next(r *rand.Rand, from time.Time, rate float64) time.Time {
+	if rate <= 0 {
+		return time.Time{}
+	}
+	return from.Add(time.Duration(r.ExpFloat64() / rate * float64(time.Second)))
+}
+
+// generate creates posts and images at random nodes until stop
+func (n *network) generate(o Options, keys []*openpgp.Entity, stop time.Time) (posts, images, failed int) {
+	r := n.rand()
+	now := time.Now()
+	nextPost, nextImage := next(r, now, o.PostRate), next(r, now, o.ImageRate)
+	for {
+		isPost := !nextPost.IsZero() && (nextImage.IsZero() || nextPost.Before(nextImage))
+		at := nextImage
+		if isPost {
+			at = nextPost
+		}
+		if at.IsZero() || at.After(stop) {
+			return posts, images, failed
+		}
+		time.Sleep(time.Until(at))
+		var obj *tangle.Object
+		var err error
+		if isPost {
+			obj, err = newPost(r, keys[r.Intn(len(keys))], o.PostSize)
+			nextPost = next(r, at, o.PostRate)
+		} else {
+			obj, err = newImage(r, o.ImageSize)
+			nextImage = next(r, at, o.ImageRate)
+		}
+		if err == nil {
+			err = n.nodes[r.Intn(len(n.nodes))].create(obj)
+		}
+		switch {
+		case err != nil:
+			failed++
+		case isPost:
+			posts++
+		default:
+			images++
+		}
+	}
+}
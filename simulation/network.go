@@ -0,0 +1,268 @@
+package simulation
+
+import (
+	"math/rand"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/u-speak/core/img"
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/datastore"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+	"github.com/u-speak/core/tangle/store"
+	"github.com/u-speak/core/tangle/store/memorystore"
+)
+
+// message is a site in transmission, encoded like it is sent between real nodes
+type message struct {
+	from      int
+	hash      hash.Hash
+	validates []hash.Hash
+	nonce     uint64
+	content   hash.Hash
+	typ       string
+	data      []byte
+}
+
+// origin records where and when a site was created and when it arrived at the other nodes
+type origin struct {
+	node     int
+	created  time.Time
+	arrivals []time.Duration
+}
+
+type network struct {
+	nodes   []*simNode
+	latency time.Duration
+	jitter  time.Duration
+	rnd     *rand.Rand
+	rndLock sync.Mutex
+	sites   map[hash.Hash]*origin
+	lock    sync.Mutex
+}
+
+// simNode wraps a tangle like a node would. Sites with unknown parents wait until the parents arrive
+type simNode struct {
+	id      int
+	net     *network
+	tangle  *tangle.Tangle
+	peers   []int
+	from    int
+	waiting map[hash.Hash]*message
+	lock    sync.Mutex
+}
+
+func newNetwork(o Options, dir string, rnd *rand.Rand) (*network, error) {
+	n := &network{latency: o.Latency, jitter: o.Jitter, rnd: rnd, sites: make(map[hash.Hash]*origin)}
+	for i := 0; i < o.Nodes; i++ {
+		ms := &memorystore.MemoryStore{}
+		if err := ms.Init(store.Options{}); err != nil {
+			n.close()
+			return nil, err
+		}
+		t, err := tangle.New(tangle.Options{Store: ms, DataPath: path.Join(dir, "node-"+strconv.Itoa(i)+".db")})
+		if err != nil {
+			n.close()
+			return nil, err
+		}
+		sn := &simNode{id: i, net: n, tangle: t, waiting: make(map[hash.Hash]*message)}
+		t.OnAdd(sn.added)
+		n.nodes = append(n.nodes, sn)
+	}
+	n.link(o.Degree)
+	return n, nil
+}
+
+// link connects the nodes in a ring, so the network is always connected, and adds random links up to the degree
+func (n *network) link(degree int) {
+	linked := make(map[[2]int]bool)
+	connect := func(a, b int) {
+		if a == b || linked[[2]int{a, b}] {
+			return
+		}
+		linked[[2]int{a, b}], linked[[2]int{b, a}] = true, true
+		n.nodes[a].peers = append(n.nodes[a].peers, b)
+		n.nodes[b].peers = append(n.nodes[b].peers, a)
+	}
+	for i := range n.nodes {
+		connect(i, (i+1)%len(n.nodes))
+	}
+	for i := range n.nodes {
+		for tries := 0; len(n.nodes[i].peers) < degree && tries < 10*degree; tries++ {
+			connect(i, n.rand().Intn(len(n.nodes)))
+		}
+	}
+}
+
+func (n *network) rand() *rand.Rand {
+	n.rndLock.Lock()
+	defer n.rndLock.Unlock()
+	return rand.New(rand.NewSource(n.rnd.Int63()))
+}
+
+func (n *network) delay() time.Duration {
+	if n.jitter <= 0 {
+		return n.latency
+	}
+	return n.latency + time.Duration(n.rand().Int63n(int64(n.jitter)))
+}
+
+// send delivers the message to the node after the link delay
+func (n *network) send(to int, m *message) {
+	time.AfterFunc(n.delay(), func() { n.nodes[to].receive(m) })
+}
+
+// arrived records the arrival of a site at a node other than its origin
+func (n *network) arrived(node int, h hash.Hash) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	o, ok := n.sites[h]
+	if !ok || o.node == node {
+		return
+	}
+	o.arrivals = append(o.arrivals, time.Since(o.created))
+}
+
+// delays returns the propagation delay of every arrival and the delay until each site reached all nodes
+func (n *network) delays() (propagation, spread []time.Duration) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	for _, o := range n.sites {
+		propagation = append(propagation, o.arrivals...)
+		if len(o.arrivals) == len(n.nodes)-1 {
+			max := time.Duration(0)
+			for _, a := range o.arrivals {
+				if a > max {
+					max = a
+				}
+			}
+			spread = append(spread, max)
+		}
+	}
+	return propagation, spread
+}
+
+// wait blocks until every node stored every site or the timeout passed
+func (n *network) wait(timeout time.Duration) bool {
+	n.lock.Lock()
+	want := len(n.sites) + 2
+	n.lock.Unlock()
+	deadline := time.Now().Add(timeout)
+	for {
+		done := true
+		for _, sn := range n.nodes {
+			sn.lock.Lock()
+			size := sn.tangle.Size()
+			sn.lock.Unlock()
+			if size < want {
+				done = false
+				break
+			}
+		}
+		if done {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func (n *network) close() {
+	for _, sn := range n.nodes {
+		sn.lock.Lock()
+		sn.tangle.Close()
+		sn.lock.Unlock()
+	}
+}
+
+// create adds a new site to the node, like a client submitting it
+func (sn *simNode) create(o *tangle.Object) error {
+	sn.lock.Lock()
+	defer sn.lock.Unlock()
+	o.Site.Validates = sn.tangle.RecommendTips()
+	o.Site.Mine(tangle.MinimumWeight)
+	sn.net.lock.Lock()
+	sn.net.sites[o.Site.Hash()] = &origin{node: sn.id, created: time.Now()}
+	sn.net.lock.Unlock()
+	sn.from = -1
+	err := sn.tangle.Add(o)
+	if err != nil {
+		sn.net.lock.Lock()
+		delete(sn.net.sites, o.Site.Hash())
+		sn.net.lock.Unlock()
+	}
+	return err
+}
+
+// added forwards a stored site to all peers except the one it was received from
+func (sn *simNode) added(o *tangle.Object) {
+	h := o.Site.Hash()
+	sn.net.arrived(sn.id, h)
+	data, err := o.Data.Serialize()
+	if err != nil {
+		return
+	}
+	m := &message{from: sn.id, hash: h, nonce: o.Site.Nonce, content: o.Site.Content, typ: o.Site.Type, data: data}
+	for _, v := range o.Site.Validates {
+		m.validates = append(m.validates, v.Hash())
+	}
+	for _, p := range sn.peers {
+		if p != sn.from {
+			sn.net.send(p, m)
+		}
+	}
+}
+
+func (sn *simNode) receive(m *message) {
+	sn.lock.Lock()
+	defer sn.lock.Unlock()
+	if sn.tangle.GetSite(m.hash) != nil || sn.waiting[m.hash] != nil {
+		return
+	}
+	if !sn.inject(m) {
+		sn.waiting[m.hash] = m
+		return
+	}
+	for progress := true; progress; {
+		progress = false
+		for h, w := range sn.waiting {
+			if sn.inject(w) {
+				delete(sn.waiting, h)
+				progress = true
+			}
+		}
+	}
+}
+
+// inject adds the site if all validated sites are known. It reports false if the site has to wait
+func (sn *simNode) inject(m *message) bool {
+	vs := []*site.Site{}
+	for _, h := range m.validates {
+		v := sn.tangle.GetSite(h)
+		if v == nil {
+			return false
+		}
+		vs = append(vs, v)
+	}
+	var d datastore.Serializable
+	switch m.typ {
+	case "post":
+		d = &post.Post{}
+	case "image":
+		d = &img.Image{}
+	default:
+		return true
+	}
+	if err := d.Deserialize(m.data); err != nil {
+		return true
+	}
+	sn.from = m.from
+	_ = sn.tangle.Inject(&tangle.Object{Site: &site.Site{Validates: vs, Nonce: m.nonce, Content: m.content, Type: m.typ}, Data: d}, true)
+	return true
+}
@@ -0,0 +1,132 @@
+package simulation
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/u-speak/core/config"
+)
+
+// ErrTooFewNodes is returned when less than two nodes are simulated
+var ErrTooFewNodes = errors.New("A simulation needs at least two nodes")
+
+// Options configure the simulated network and the generated load
+type Options struct {
+	// Nodes is the size of the network, Degree the amount of links of every node
+	Nodes  int
+	Degree int
+	// Latency is added to every transmission, together with a random delay of up to Jitter
+	Latency time.Duration
+	Jitter  time.Duration
+	// Duration is how long load is generated, Timeout how long to wait for convergence afterwards
+	Duration time.Duration
+	Timeout  time.Duration
+	// PostRate and ImageRate are the amount of sites created per second
+	PostRate  float64
+	ImageRate float64
+	// PostSize and ImageSize are the payload sizes in bytes
+	PostSize  int
+	ImageSize int
+	// Keys is the amount of authors signing posts
+	Keys int
+	Seed int64
+	// Dir keeps the data stores of the nodes, a temporary directory is used if it is empty
+	Dir string
+}
+
+// FromConfig returns the simulation options of the configuration
+func FromConfig(c config.Configuration) Options {
+	s := c.Simulation
+	return Options{
+		Nodes:     s.Nodes,
+		Degree:    s.Degree,
+		Latency:   time.Duration(s.Latency) * time.Millisecond,
+		Jitter:    time.Duration(s.Jitter) * time.Millisecond,
+		Duration:  time.Duration(s.Duration) * time.Second,
+		Timeout:   time.Duration(s.Timeout) * time.Second,
+		PostRate:  s.PostRate,
+		ImageRate: s.ImageRate,
+		PostSize:  s.PostSize,
+		ImageSize: s.ImageSize,
+		Keys:      s.Keys,
+		Seed:      time.Now().UnixNano(),
+	}
+}
+
+// Percentiles summarize a distribution of delays
+type Percentiles struct {
+	P50 time.Duration `json:"p50"`
+	P90 time.Duration `json:"p90"`
+	P99 time.Duration `json:"p99"`
+	Max time.Duration `json:"max"`
+}
+
+func percentiles(ds []time.Duration) Percentiles {
+	if len(ds) == 0 {
+		return Percentiles{}
+	}
+	sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+	at := func(q float64) time.Duration { return ds[int(q*float64(len(ds)-1))] }
+	return Percentiles{P50: at(0.5), P90: at(0.9), P99: at(0.99), Max: ds[len(ds)-1]}
+}
+
+// Report summarizes a simulation run
+type Report struct {
+	Nodes  int `json:"nodes"`
+	Posts  int `json:"posts"`
+	Images int `json:"images"`
+	// Failed counts sites which could not be created
+	Failed int `json:"failed"`
+	// Propagation is the delay until a site was stored by another node, Spread until it was stored by all nodes
+	Propagation Percentiles `json:"propagation"`
+	Spread      Percentiles `json:"spread"`
+	// Convergence is the time from the end of the load until all nodes stored all sites
+	Convergence time.Duration `json:"convergence"`
+	Converged   bool          `json:"converged"`
+}
+
+func (r *Report) String() string {
+	return fmt.Sprintf("%d nodes, %d posts, %d images, %d failed\npropagation: %+v\nspread: %+v\nconverged: %t after %s",
+		r.Nodes, r.Posts, r.Images, r.Failed, r.Propagation, r.Spread, r.Converged, r.Convergence)
+}
+
+// Run simulates a network, generates load for the configured duration and reports how the sites propagated
+func Run(o Options) (*Report, error) {
+	if o.Nodes < 2 {
+		return nil, ErrTooFewNodes
+	}
+	dir := o.Dir
+	if dir == "" {
+		d, err := ioutil.TempDir("", "uspeak-simulation")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(d)
+		dir = d
+	}
+	rnd := rand.New(rand.NewSource(o.Seed))
+	keys, err := generateKeys(o.Keys)
+	if err != nil {
+		return nil, err
+	}
+	n, err := newNetwork(o, dir, rnd)
+	if err != nil {
+		return nil, err
+	}
+	defer n.close()
+
+	r := &Report{Nodes: o.Nodes}
+	r.Posts, r.Images, r.Failed = n.generate(o, keys, time.Now().Add(o.Duration))
+	stopped := time.Now()
+	r.Converged = n.wait(o.Timeout)
+	r.Convergence = time.Since(stopped)
+	propagation, spread := n.delays()
+	r.Propagation = percentiles(propagation)
+	r.Spread = percentiles(spread)
+	return r, nil
+}
@@ -0,0 +1,43 @@
+package simulation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	r, err := Run(Options{
+		Nodes:     5,
+		Degree:    2,
+		Latency:   time.Millisecond,
+		Jitter:    time.Millisecond,
+		Duration:  300 * time.Millisecond,
+		Timeout:   5 * time.Second,
+		PostRate:  20,
+		ImageRate: 20,
+		PostSize:  100,
+		ImageSize: 1024,
+		Keys:      1,
+		Seed:      1,
+	})
+	assert.NoError(t, err)
+	assert.True(t, r.Converged)
+	assert.Zero(t, r.Failed)
+	assert.NotZero(t, r.Posts+r.Images)
+	assert.True(t, r.Propagation.P50 >= time.Millisecond)
+	assert.True(t, r.Spread.Max >= r.Propagation.Max)
+
+	_, err = Run(Options{Nodes: 1})
+	assert.Equal(t, ErrTooFewNodes, err)
+}
+
+func TestPercentiles(t *testing.T) {
+	ds := []time.Duration{}
+	for i := 100; i > 0; i-- {
+		ds = append(ds, time.Duration(i))
+	}
+	assert.Equal(t, Percentiles{P50: 50, P90: 90, P99: 99, Max: 100}, percentiles(ds))
+	assert.Equal(t, Percentiles{}, percentiles(nil))
+}
@@ -0,0 +1,193 @@
+package sqlindex
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/site"
+
+	// Registers the sqlite3 driver
+	_ "github.com/mattn/go-sqlite3"
+	log "github.com/sirupsen/logrus"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS sites (
+	hash TEXT PRIMARY KEY,
+	type TEXT NOT NULL,
+	nonce INTEGER NOT NULL,
+	content TEXT NOT NULL,
+	weight INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS validations (
+	site TEXT NOT NULL,
+	validates TEXT NOT NULL,
+	PRIMARY KEY (site, validates)
+);
+CREATE TABLE IF NOT EXISTS posts (
+	hash TEXT PRIMARY KEY,
+	fingerprint TEXT NOT NULL,
+	timestamp INTEGER NOT NULL,
+	length INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS posts_fingerprint ON posts (fingerprint);
+CREATE INDEX IF NOT EXISTS posts_timestamp ON posts (timestamp);
+`
+
+var (
+	// ErrNotReadOnly is returned when a query is not a single SELECT statement
+	ErrNotReadOnly = errors.New("Only single SELECT statements are allowed")
+)
+
+// Index is a derived sqlite database of site headers and post metadata
+type Index struct {
+	db      *sql.DB
+	ro      *sql.DB
+	maxRows int
+	timeout time.Duration
+	work    func(*site.Site) int
+}
+
+// Result is the tabular output of a query
+type Result struct {
+	Columns   []string        `json:"columns"`
+	Rows      [][]interface{} `json:"rows"`
+	Truncated bool            `json:"truncated"`
+}
+
+// New opens the index at path, creating the schema if needed. Queries return at most maxRows rows and are interrupted after timeout.
+// The weight of sites is computed by work, usually the Work of the tangle
+func New(path string, maxRows int, timeout time.Duration, work func(*site.Site) int) (*Index, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	_, err = db.Exec(schema)
+	if err != nil {
+		return nil, err
+	}
+	ro, err := sql.Open("sqlite3", "file:"+path+"?mode=ro&_query_only=true")
+	if err != nil {
+		return nil, err
+	}
+	return &Index{db: db, ro: ro, maxRows: maxRows, timeout: timeout, work: work}, nil
+}
+
+// Add indexes a single object
+func (i *Index) Add(o *tangle.Object) error {
+	tx, err := i.db.Begin()
+	if err != nil {
+		return err
+	}
+	h := o.Site.Hash()
+	_, err = tx.Exec("INSERT OR IGNORE INTO sites (hash, type, nonce, content, weight) VALUES (?, ?, ?, ?, ?)",
+		h.String(), o.Site.Type, int64(o.Site.Nonce), o.Site.Content.String(), i.work(o.Site))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, v := range o.Site.Validates {
+		_, err = tx.Exec("INSERT OR IGNORE INTO validations (site, validates) VALUES (?, ?)", h.String(), v.Hash().String())
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if p, ok := o.Data.(*post.Post); ok && p.Pubkey != nil {
+		_, err = tx.Exec("INSERT OR IGNORE INTO posts (hash, fingerprint, timestamp, length) VALUES (?, ?, ?, ?)",
+			h.String(), p.Fingerprint(), p.Timestamp, len(p.Content))
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Indexed reports whether the object is reflected in the index
+func (i *Index) Indexed(o *tangle.Object) bool {
+	var n int
+	if err := i.db.QueryRow("SELECT COUNT(*) FROM sites WHERE hash = ?", o.Site.Hash().String()).Scan(&n); err != nil {
+		log.Errorf("Could not query sql index: %s", err)
+		return false
+	}
+	return n > 0
+}
+
+// Sync indexes all sites of the tangle missing from the index
+func (i *Index) Sync(t *tangle.Tangle) error {
+	var n int
+	err := i.db.QueryRow("SELECT COUNT(*) FROM sites").Scan(&n)
+	if err != nil {
+		return err
+	}
+	if n == t.Size() {
+		return nil
+	}
+	log.Infof("Rebuilding sql index (%d of %d sites indexed)", n, t.Size())
+	for _, h := range t.Hashes() {
+		o := t.Get(h)
+		if o == nil {
+			continue
+		}
+		err := i.Add(o)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Query runs a read-only statement against the index. It is interrupted once the context is done or the timeout passed
+func (i *Index) Query(ctx context.Context, q string) (*Result, error) {
+	q = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(q), ";"))
+	kw := strings.ToUpper(strings.SplitN(q, " ", 2)[0])
+	if (kw != "SELECT" && kw != "WITH") || strings.Contains(q, ";") {
+		return nil, ErrNotReadOnly
+	}
+	ctx, cancel := context.WithTimeout(ctx, i.timeout)
+	defer cancel()
+	rows, err := i.ro.QueryContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	res := &Result{Columns: cols, Rows: [][]interface{}{}}
+	for rows.Next() {
+		if len(res.Rows) >= i.maxRows {
+			res.Truncated = true
+			break
+		}
+		vals := make([]interface{}, len(cols))
+		ptrs := make([]interface{}, len(cols))
+		for j := range vals {
+			ptrs[j] = &vals[j]
+		}
+		err := rows.Scan(ptrs...)
+		if err != nil {
+			return nil, err
+		}
+		for j, v := range vals {
+			if b, ok := v.([]byte); ok {
+				vals[j] = string(b)
+			}
+		}
+		res.Rows = append(res.Rows, vals)
+	}
+	return res, rows.Err()
+}
+
+// Close closes both database connections
+func (i *Index) Close() {
+	_ = i.ro.Close()
+	_ = i.db.Close()
+}
@@ -0,0 +1,67 @@
+package sqlindex
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/img"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+)
+
+func index(t *testing.T, name string) *Index {
+	p := path.Join(os.TempDir(), name)
+	os.Remove(p)
+	i, err := New(p, 2, time.Second, func(s *site.Site) int { return int(s.Nonce) + 1 })
+	assert.NoError(t, err)
+	return i
+}
+
+func TestAddQuery(t *testing.T) {
+	i := index(t, "testSQLIndexAdd.sqlite")
+	defer i.Close()
+	gen := &site.Site{Content: hash.Hash{1}, Type: "genesis"}
+	for n := uint64(0); n < 3; n++ {
+		o := &tangle.Object{Site: &site.Site{Content: hash.Hash{2}, Nonce: n, Type: "image", Validates: []*site.Site{gen}}, Data: &img.Image{}}
+		assert.NoError(t, i.Add(o))
+		assert.NoError(t, i.Add(o))
+	}
+	r, err := i.Query(context.Background(), "SELECT type, COUNT(*) FROM sites GROUP BY type;")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"type", "COUNT(*)"}, r.Columns)
+	assert.Equal(t, [][]interface{}{{"image", int64(3)}}, r.Rows)
+
+	r, err = i.Query(context.Background(), "SELECT SUM(weight) FROM sites")
+	assert.NoError(t, err)
+	assert.Equal(t, [][]interface{}{{int64(6)}}, r.Rows)
+
+	r, err = i.Query(context.Background(), "SELECT validates FROM validations")
+	assert.NoError(t, err)
+	assert.True(t, r.Truncated)
+	assert.Len(t, r.Rows, 2)
+}
+
+func TestReadOnly(t *testing.T) {
+	i := index(t, "testSQLIndexRO.sqlite")
+	defer i.Close()
+	_, err := i.Query(context.Background(), "DELETE FROM sites")
+	assert.Equal(t, ErrNotReadOnly, err)
+	_, err = i.Query(context.Background(), "SELECT 1; DELETE FROM sites")
+	assert.Equal(t, ErrNotReadOnly, err)
+	_, err = i.Query(context.Background(), "WITH x AS (SELECT 1) DELETE FROM sites")
+	assert.Error(t, err)
+}
+
+func TestQueryTimeout(t *testing.T) {
+	i := index(t, "testSQLIndexTimeout.sqlite")
+	defer i.Close()
+	start := time.Now()
+	_, err := i.Query(context.Background(), "WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x+1 FROM c) SELECT COUNT(*) FROM c")
+	assert.Error(t, err)
+	assert.True(t, time.Since(start) < 5*time.Second)
+}
@@ -0,0 +1,173 @@
+package submission
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+const (
+	// Queued submissions are waiting for a worker
+	Queued = "queued"
+	// Validated submissions passed all checks
+	Validated = "validated"
+	// Accepted submissions were handed to the node
+	Accepted = "accepted"
+	// Propagated submissions were pushed to at least one peer
+	Propagated = "propagated"
+	// Failed submissions were rejected
+	Failed = "failed"
+)
+
+var (
+	// ErrQueueFull is returned when no more submissions can be queued
+	ErrQueueFull = errors.New("Submission queue is full, try again later")
+)
+
+// Submission is the progress of a single asynchronously submitted site
+type Submission struct {
+	ID      string    `json:"id"`
+	Hash    hash.Hash `json:"hash"`
+	State   string    `json:"state"`
+	Peers   int       `json:"peers"`
+	Error   string    `json:"error,omitempty"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+// Done reports whether the submission will not change anymore
+func (s *Submission) Done() bool {
+	return s.State == Accepted || s.State == Propagated || s.State == Failed
+}
+
+// Process validates and submits a site. It reports the stages reached through the submission
+type Process func(o *tangle.Object, s *Stage) error
+
+// Stage lets a Process report its progress
+type Stage struct {
+	id      string
+	tracker *Tracker
+}
+
+// Validated marks the submission as validated
+func (s *Stage) Validated() {
+	s.tracker.update(s.id, func(sub *Submission) { sub.State = Validated })
+}
+
+// Accepted marks the submission as accepted, or as propagated if it was pushed to at least one peer
+func (s *Stage) Accepted(peers int) {
+	s.tracker.update(s.id, func(sub *Submission) {
+		sub.State = Accepted
+		if peers > 0 {
+			sub.State = Propagated
+		}
+		sub.Peers = peers
+	})
+}
+
+type job struct {
+	id    string
+	o     *tangle.Object
+	check func() error
+}
+
+// Tracker queues submissions for a pool of workers and keeps their progress
+type Tracker struct {
+	process     Process
+	retention   time.Duration
+	submissions map[string]*Submission
+	jobs        chan job
+	lock        sync.RWMutex
+}
+
+// New starts workers processing up to queue pending submissions.
+// Finished submissions are kept for retention
+func New(p Process, workers, queue int, retention time.Duration) *Tracker {
+	t := &Tracker{
+		process:     p,
+		retention:   retention,
+		submissions: make(map[string]*Submission),
+		jobs:        make(chan job, queue),
+	}
+	for i := 0; i < workers; i++ {
+		go t.work()
+	}
+	return t
+}
+
+// Submit queues the object and returns the submission. check is run by the worker before the object is processed and may be nil
+func (t *Tracker) Submit(o *tangle.Object, check func() error) (*Submission, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	s := &Submission{ID: hex.EncodeToString(b), Hash: o.Site.Hash(), State: Queued, Created: now, Updated: now}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	select {
+	case t.jobs <- job{id: s.ID, o: o, check: check}:
+	default:
+		return nil, ErrQueueFull
+	}
+	t.submissions[s.ID] = s
+	c := *s
+	return &c, nil
+}
+
+// Get returns a copy of the submission or nil if the id is unknown
+func (t *Tracker) Get(id string) *Submission {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	s, ok := t.submissions[id]
+	if !ok {
+		return nil
+	}
+	c := *s
+	return &c
+}
+
+// Expire forgets finished submissions older than the retention
+func (t *Tracker) Expire(now time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for id, s := range t.submissions {
+		if s.Done() && now.Sub(s.Updated) > t.retention {
+			delete(t.submissions, id)
+		}
+	}
+}
+
+func (t *Tracker) update(id string, f func(*Submission)) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	s, ok := t.submissions[id]
+	if !ok {
+		return
+	}
+	f(s)
+	s.Updated = time.Now()
+}
+
+func (t *Tracker) work() {
+	for j := range t.jobs {
+		var err error
+		if j.check != nil {
+			err = j.check()
+		}
+		if err == nil {
+			err = t.process(j.o, &Stage{id: j.id, tracker: t})
+		}
+		if err != nil {
+			t.update(j.id, func(s *Submission) {
+				s.State = Failed
+				s.Error = err.Error()
+			})
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package submission
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/img"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/site"
+)
+
+func object(raw string) *tangle.Object {
+	i := &img.Image{Raw: []byte(raw)}
+	h, _ := i.Hash()
+	return &tangle.Object{Site: &site.Site{Content: h, Type: "image"}, Data: i}
+}
+
+func wait(t *Tracker, id string) *Submission {
+	for i := 0; i < 100; i++ {
+		if s := t.Get(id); s.Done() {
+			return s
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return t.Get(id)
+}
+
+func TestSubmit(t *testing.T) {
+	tr := New(func(o *tangle.Object, s *Stage) error {
+		s.Validated()
+		s.Accepted(len(o.Data.(*img.Image).Raw))
+		return nil
+	}, 2, 10, time.Minute)
+
+	o := object("1337")
+	s, err := tr.Submit(o, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, o.Site.Hash(), s.Hash)
+	s = wait(tr, s.ID)
+	assert.Equal(t, Propagated, s.State)
+	assert.Equal(t, 4, s.Peers)
+
+	s, _ = tr.Submit(object("x"), func() error { return errors.New("invalid") })
+	s = wait(tr, s.ID)
+	assert.Equal(t, Failed, s.State)
+	assert.Equal(t, "invalid", s.Error)
+
+	assert.Nil(t, tr.Get("unknown"))
+	tr.Expire(time.Now())
+	assert.NotNil(t, tr.Get(s.ID))
+	tr.Expire(time.Now().Add(2 * time.Minute))
+	assert.Nil(t, tr.Get(s.ID))
+}
+
+func TestQueueFull(t *testing.T) {
+	block := make(chan struct{})
+	tr := New(func(o *tangle.Object, s *Stage) error {
+		<-block
+		return nil
+	}, 0, 1, time.Minute)
+	_, err := tr.Submit(object("1"), nil)
+	assert.NoError(t, err)
+	_, err = tr.Submit(object("2"), nil)
+	assert.Equal(t, ErrQueueFull, err)
+	close(block)
+}
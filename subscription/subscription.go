@@ -0,0 +1,26 @@
+package subscription
+
+import (
+	"net/mail"
+
+	"github.com/u-speak/core/post"
+)
+
+// Subscription is a signed opt-in for the email digest. The signed content is the email address
+type Subscription struct {
+	post.Post
+}
+
+// Type implements tangle/datastore.serializable
+func (s *Subscription) Type() string {
+	return "subscription"
+}
+
+// Address returns the validated email address of the subscription
+func (s *Subscription) Address() (string, error) {
+	a, err := mail.ParseAddress(s.Content)
+	if err != nil {
+		return "", err
+	}
+	return a.Address, nil
+}
@@ -0,0 +1,68 @@
+package tags
+
+import (
+	"sync"
+
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+// Index maps #hashtags to the posts containing them
+type Index struct {
+	tags map[string][]hash.Hash
+	seen map[hash.Hash]bool
+	lock sync.RWMutex
+}
+
+// NewIndex returns an empty tag index
+func NewIndex() *Index {
+	return &Index{tags: make(map[string][]hash.Hash), seen: make(map[hash.Hash]bool)}
+}
+
+// Add indexes the tags of the object if it is a post
+func (i *Index) Add(o *tangle.Object) {
+	if o.Site.Type != "post" {
+		return
+	}
+	h := o.Site.Hash()
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if i.seen[h] {
+		return
+	}
+	i.seen[h] = true
+	for _, t := range o.Data.(*post.Post).Tags() {
+		i.tags[t] = append(i.tags[t], h)
+	}
+}
+
+// Indexed reports whether the object is reflected in the index. Only posts are indexed
+func (i *Index) Indexed(o *tangle.Object) bool {
+	if o.Site.Type != "post" {
+		return true
+	}
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	return i.seen[o.Site.Hash()]
+}
+
+// Sync indexes all posts stored in the tangle
+func (i *Index) Sync(t *tangle.Tangle) {
+	for _, h := range t.Hashes() {
+		s := t.GetSite(h)
+		if s == nil || s.Type != "post" {
+			continue
+		}
+		if o := t.Get(h); o != nil {
+			i.Add(o)
+		}
+	}
+}
+
+// Get returns the hashes of all posts with the tag
+func (i *Index) Get(tag string) []hash.Hash {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	return append([]hash.Hash{}, i.tags[tag]...)
+}
@@ -0,0 +1,23 @@
+package tags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+)
+
+func TestIndex(t *testing.T) {
+	i := NewIndex()
+	a := &tangle.Object{Site: &site.Site{Type: "post", Nonce: 1}, Data: &post.Post{Content: "hello #Go and #tangle"}}
+	b := &tangle.Object{Site: &site.Site{Type: "post", Nonce: 2}, Data: &post.Post{Content: "more #go"}}
+	i.Add(a)
+	i.Add(b)
+	i.Add(a)
+	assert.Equal(t, []hash.Hash{a.Site.Hash(), b.Site.Hash()}, i.Get("go"))
+	assert.Equal(t, []hash.Hash{a.Site.Hash()}, i.Get("tangle"))
+	assert.Empty(t, i.Get("none"))
+}
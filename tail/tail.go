@@ -0,0 +1,118 @@
+package tail
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+// ErrUnknownCursor is returned when the cursor is not part of the recent sites anymore
+var ErrUnknownCursor = errors.New("Cursor is not among the recent sites, please resync")
+
+type entry struct {
+	seq  uint64
+	hash hash.Hash
+	typ  string
+}
+
+// Log keeps the most recently added sites in the order they were added
+type Log struct {
+	size    int
+	seq     uint64
+	entries []entry
+	notify  chan struct{}
+	lock    sync.Mutex
+}
+
+// New creates a log remembering up to size sites
+func New(size int) *Log {
+	return &Log{size: size, notify: make(chan struct{})}
+}
+
+// Add appends a site to the log and wakes all waiting readers
+func (l *Log) Add(o *tangle.Object) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.seq++
+	l.entries = append(l.entries, entry{seq: l.seq, hash: o.Site.Hash(), typ: o.Site.Type})
+	if len(l.entries) > l.size {
+		l.entries = l.entries[len(l.entries)-l.size:]
+	}
+	close(l.notify)
+	l.notify = make(chan struct{})
+}
+
+// Hashes returns all sites in the log, oldest first
+func (l *Log) Hashes() []hash.Hash {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	hs := []hash.Hash{}
+	for _, e := range l.entries {
+		hs = append(hs, e.hash)
+	}
+	return hs
+}
+
+// Latest returns the most recently added site or the zero hash if the log is empty
+func (l *Log) Latest() hash.Hash {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if len(l.entries) == 0 {
+		return hash.Hash{}
+	}
+	return l.entries[len(l.entries)-1].hash
+}
+
+// cursor returns the sequence number of the site, or of the latest site if since is the zero hash
+func (l *Log) cursor(since hash.Hash) (uint64, error) {
+	if since == (hash.Hash{}) {
+		return l.seq, nil
+	}
+	for _, e := range l.entries {
+		if e.hash == since {
+			return e.seq, nil
+		}
+	}
+	return 0, ErrUnknownCursor
+}
+
+func (l *Log) after(seq uint64, typ string) []hash.Hash {
+	hs := []hash.Hash{}
+	for _, e := range l.entries {
+		if e.seq > seq && (typ == "" || e.typ == typ) {
+			hs = append(hs, e.hash)
+		}
+	}
+	return hs
+}
+
+// Wait returns the sites of the given type added after since, waiting up to timeout for new sites to arrive.
+// An empty type matches all sites and a zero since only returns sites added while waiting
+func (l *Log) Wait(since hash.Hash, typ string, timeout time.Duration) ([]hash.Hash, error) {
+	l.lock.Lock()
+	seq, err := l.cursor(since)
+	if err != nil {
+		l.lock.Unlock()
+		return nil, err
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		hs := l.after(seq, typ)
+		if len(hs) > 0 {
+			l.lock.Unlock()
+			return hs, nil
+		}
+		notify := l.notify
+		l.lock.Unlock()
+		select {
+		case <-notify:
+		case <-timer.C:
+			return []hash.Hash{}, nil
+		}
+		l.lock.Lock()
+	}
+}
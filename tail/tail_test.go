@@ -0,0 +1,47 @@
+package tail
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/img"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+)
+
+func object(raw, typ string) *tangle.Object {
+	i := &img.Image{Raw: []byte(raw)}
+	h, _ := i.Hash()
+	return &tangle.Object{Site: &site.Site{Content: h, Type: typ}, Data: i}
+}
+
+func TestWait(t *testing.T) {
+	l := New(3)
+	assert.Equal(t, hash.Hash{}, l.Latest())
+	a, b := object("a", "image"), object("b", "post")
+	l.Add(a)
+	l.Add(b)
+	assert.Equal(t, b.Site.Hash(), l.Latest())
+	assert.Equal(t, []hash.Hash{a.Site.Hash(), b.Site.Hash()}, l.Hashes())
+	hs, err := l.Wait(a.Site.Hash(), "", time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, []hash.Hash{b.Site.Hash()}, hs)
+	hs, err = l.Wait(a.Site.Hash(), "image", 10*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Empty(t, hs)
+
+	c := object("c", "image")
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		l.Add(object("d", "post"))
+		l.Add(c)
+	}()
+	hs, err = l.Wait(hash.Hash{}, "image", time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, []hash.Hash{c.Site.Hash()}, hs)
+
+	_, err = l.Wait(a.Site.Hash(), "", time.Second)
+	assert.Equal(t, ErrUnknownCursor, err)
+}
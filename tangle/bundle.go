@@ -0,0 +1,90 @@
+package tangle
+
+import (
+	"errors"
+
+	"github.com/u-speak/core/follow"
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/pow"
+	"github.com/u-speak/core/subscription"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tombstone"
+)
+
+// Bundle contains everything needed to verify a site independently of the node
+type Bundle struct {
+	Hash          hash.Hash   `json:"hash"`
+	Canonical     []byte      `json:"canonical"`
+	Weight        int         `json:"weight"`
+	PoW           string      `json:"pow"`
+	Validates     []hash.Hash `json:"validates"`
+	Type          string      `json:"type"`
+	Content       hash.Hash   `json:"content"`
+	DataCanonical []byte      `json:"data_canonical,omitempty"`
+	Signed        string      `json:"signed,omitempty"`
+	Signature     string      `json:"signature,omitempty"`
+	Pubkey        string      `json:"pubkey,omitempty"`
+}
+
+type canonical interface {
+	Canonical() []byte
+}
+
+// Bundle builds the verification bundle of an object, weighing the site with the proof of work algorithm of the network
+func (o *Object) Bundle(a pow.Algorithm) (*Bundle, error) {
+	h := o.Site.Hash()
+	b := &Bundle{
+		Hash:      h,
+		Canonical: o.Site.Canonical(),
+		Weight:    pow.Weight(a, o.Site),
+		PoW:       a.Name(),
+		Validates: []hash.Hash{},
+		Type:      o.Site.Type,
+		Content:   o.Site.Content,
+	}
+	for _, v := range o.Site.Validates {
+		b.Validates = append(b.Validates, v.Hash())
+	}
+	if c, ok := o.Data.(canonical); ok {
+		b.DataCanonical = c.Canonical()
+	}
+	var p *post.Post
+	switch d := o.Data.(type) {
+	case *post.Post:
+		p = d
+	case *subscription.Subscription:
+		p = &d.Post
+	case *follow.List:
+		p = &d.Post
+	case *tombstone.Tombstone:
+		p = &d.Post
+	}
+	if p != nil {
+		if err := p.JSON(); err != nil {
+			return nil, err
+		}
+		b.Signed = p.Content
+		b.Signature = p.Signature
+		b.Pubkey = p.PubkeyStr
+	}
+	return b, nil
+}
+
+// Verify checks that the hashes contained in the bundle match the canonical data
+// and that the weight matches the proof of the named algorithm
+func (b *Bundle) Verify() error {
+	if hash.New(b.Canonical) != b.Hash {
+		return errors.New("Site hash does not match the canonical site")
+	}
+	a, err := pow.Get(b.PoW)
+	if err != nil {
+		return err
+	}
+	if a.Proof(b.Canonical).Weight() != b.Weight {
+		return errors.New("Weight does not match the site hash")
+	}
+	if b.DataCanonical != nil && hash.New(b.DataCanonical) != b.Content {
+		return errors.New("Content hash does not match the canonical data")
+	}
+	return nil
+}
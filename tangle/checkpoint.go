@@ -0,0 +1,85 @@
+package tangle
+
+import (
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+)
+
+// SetCheckpoints pins trusted sites. The history below a pinned site is final: sites injected by peers
+// which only validate ancestors of a checkpoint are rejected, so peers can not grow an alternative history from old sites
+func (t *Tangle) SetCheckpoints(hs []hash.Hash) {
+	t.finalLock.Lock()
+	defer t.finalLock.Unlock()
+	t.checkpoints = hs
+	t.final = nil
+}
+
+// pinned returns the checkpoints
+func (t *Tangle) pinned() []hash.Hash {
+	t.finalLock.Lock()
+	defer t.finalLock.Unlock()
+	return t.checkpoints
+}
+
+// MissingCheckpoints returns the pinned sites which are not part of the tangle yet
+func (t *Tangle) MissingCheckpoints() []hash.Hash {
+	missing := []hash.Hash{}
+	for _, h := range t.pinned() {
+		if t.GetSite(h) == nil {
+			missing = append(missing, h)
+		}
+	}
+	return missing
+}
+
+// finalized returns all sites validated directly or indirectly by a known checkpoint.
+// The set is cached until a new checkpoint is added to the tangle. The cached set is never modified, only replaced
+func (t *Tangle) finalized() map[hash.Hash]bool {
+	t.finalLock.Lock()
+	defer t.finalLock.Unlock()
+	if t.final != nil {
+		return t.final
+	}
+	t.final = make(map[hash.Hash]bool)
+	bound := []*site.Site{}
+	for _, h := range t.checkpoints {
+		if s := t.GetSite(h); s != nil {
+			bound = append(bound, s.Validates...)
+		}
+	}
+	for len(bound) > 0 {
+		s := bound[len(bound)-1]
+		bound = bound[:len(bound)-1]
+		h := s.Hash()
+		if t.final[h] {
+			continue
+		}
+		t.final[h] = true
+		bound = append(bound, s.Validates...)
+	}
+	return t.final
+}
+
+// belowCheckpoint reports whether all sites validated by s are final
+func (t *Tangle) belowCheckpoint(s *site.Site) bool {
+	if len(t.pinned()) == 0 || len(s.Validates) == 0 {
+		return false
+	}
+	final := t.finalized()
+	for _, v := range s.Validates {
+		if !final[v.Hash()] {
+			return false
+		}
+	}
+	return true
+}
+
+// isCheckpoint reports whether the hash is pinned
+func (t *Tangle) isCheckpoint(h hash.Hash) bool {
+	for _, c := range t.pinned() {
+		if c == h {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package datastore
+
+import (
+	"errors"
+
+	"github.com/coreos/bbolt"
+	"github.com/klauspost/compress/dict"
+	"github.com/klauspost/compress/zstd"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+// Compression algorithms for stored payloads
+const (
+	None = "none"
+	Zstd = "zstd"
+)
+
+const (
+	// MaxDictSize is the largest dictionary trained for compression
+	MaxDictSize = 64 * 1024
+	// MinSamples is the least amount of payloads needed to train a dictionary
+	MinSamples = 32
+)
+
+var (
+	dictBucketName = []byte("dicts")
+	// zstdMagic starts every zstd frame. It is only used to upgrade stores without recorded formats
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	// ErrUnknownCompression is returned for unsupported compression algorithms
+	ErrUnknownCompression = errors.New("Unknown compression algorithm")
+	// ErrNotEnoughSamples is returned when training a dictionary on too few payloads
+	ErrNotEnoughSamples = errors.New("Not enough samples to train a dictionary")
+)
+
+// loadCodec prepares compression using the latest stored dictionary.
+// Payloads compressed with older dictionaries stay readable
+func (s *Store) loadCodec() error {
+	dicts := [][]byte{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(dictBucketName).ForEach(func(k, v []byte) error {
+			dicts = append(dicts, append([]byte{}, v...))
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	dec, err := zstd.NewReader(nil, zstd.WithDecoderDicts(dicts...))
+	if err != nil {
+		return err
+	}
+	var enc *zstd.Encoder
+	if s.compression == Zstd {
+		opts := []zstd.EOption{}
+		if len(dicts) > 0 {
+			opts = append(opts, zstd.WithEncoderDict(dicts[len(dicts)-1]))
+		}
+		enc, err = zstd.NewWriter(nil, opts...)
+		if err != nil {
+			return err
+		}
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.enc, s.dec, s.dicts = enc, dec, len(dicts)
+	return nil
+}
+
+// Train builds a compression dictionary from sample payloads and uses it for all following writes
+func (s *Store) Train(samples [][]byte) error {
+	if len(samples) < MinSamples {
+		return ErrNotEnoughSamples
+	}
+	d, err := dict.BuildZstdDict(samples, dict.Options{MaxDictSize: MaxDictSize, HashBytes: 6})
+	if err != nil {
+		return err
+	}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(dictBucketName)
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		return b.Put(itob(seq), d)
+	})
+	if err != nil {
+		return err
+	}
+	return s.loadCodec()
+}
+
+// Raw returns the serialized payload stored under the hash
+func (s *Store) Raw(h hash.Hash) ([]byte, error) {
+	var buff []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		buff = append([]byte{}, tx.Bucket(bucketname).Get(h.Slice())...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.decode(h, buff)
+}
+
+// Trained reports whether a compression dictionary exists
+func (s *Store) Trained() bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.dicts > 0
+}
+
+// compress returns the payload compressed with the configured algorithm and whether it was compressed at all
+func (s *Store) compress(d []byte) ([]byte, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if s.enc == nil {
+		return d, false
+	}
+	return s.enc.EncodeAll(d, nil), true
+}
+
+func (s *Store) decompress(d []byte) ([]byte, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.dec.DecodeAll(d, nil)
+}
+
+func itob(v uint64) []byte {
+	b := make([]byte, 8)
+	for i := 7; i >= 0; i-- {
+		b[i] = byte(v)
+		v >>= 8
+	}
+	return b
+}
@@ -1,14 +1,21 @@
 package datastore
 
 import (
+	"crypto/cipher"
 	"errors"
+	"sync"
+	"time"
 
 	"github.com/coreos/bbolt"
+	"github.com/klauspost/compress/zstd"
+	"github.com/u-speak/core/migrate"
 	"github.com/u-speak/core/tangle/hash"
 )
 
 var (
 	bucketname = []byte("data")
+	// ErrNotFound is returned when no payload is stored for the hash
+	ErrNotFound = errors.New("Payload not found")
 )
 
 // Serializable allows for the storage of any kind of data
@@ -23,26 +30,72 @@ type Serializable interface {
 
 // Store is responsible for storing the actual data on the tangle
 type Store struct {
-	db *bolt.DB
+	db          *bolt.DB
+	compression string
+	enc         *zstd.Encoder
+	dec         *zstd.Decoder
+	dicts       int
+	aead        cipher.AEAD
+	observe     func(string, time.Time)
+	lock        sync.RWMutex
 }
 
-// New returns an initialized Store
-func New(path string) (*Store, error) {
-	s := &Store{}
-	db, err := bolt.Open(path, 0644, nil)
+// Options configure how payloads are stored
+type Options struct {
+	// Compression is the algorithm used for new payloads, None if empty
+	Compression string
+	// Key enables AES-GCM encryption of new payloads if set. It has to be 16, 24 or 32 bytes long
+	Key []byte
+	// Observe is called after every put and get with the name and start of the operation
+	Observe func(op string, start time.Time)
+	// NoSync skips the fsync after every commit, see store.Options
+	NoSync bool
+}
+
+// New returns an initialized Store.
+// Existing payloads are read regardless of how they were stored
+func New(path string, o Options) (*Store, error) {
+	if o.Compression == "" {
+		o.Compression = None
+	}
+	if o.Compression != None && o.Compression != Zstd {
+		return nil, ErrUnknownCompression
+	}
+	s := &Store{compression: o.Compression, observe: o.Observe}
+	if o.Key != nil {
+		aead, err := newAEAD(o.Key)
+		if err != nil {
+			return nil, err
+		}
+		s.aead = aead
+	}
+	db, err := migrate.Open(path, 0644, s.migrations())
 	if err != nil {
 		return nil, err
 	}
+	db.NoSync = o.NoSync
 	s.db = db
 	err = s.db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(dictBucketName); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(tombBucketName); err != nil {
+			return err
+		}
 		_, err := tx.CreateBucketIfNotExists(bucketname)
 		return err
 	})
-	return s, err
+	if err != nil {
+		return s, err
+	}
+	return s, s.loadCodec()
 }
 
 // Put stores the serialized element in the database
 func (s *Store) Put(e Serializable) error {
+	if s.observe != nil {
+		defer s.observe("put", time.Now())
+	}
 	if e == nil {
 		return errors.New("element must not be nil")
 	}
@@ -50,10 +103,17 @@ func (s *Store) Put(e Serializable) error {
 	if err != nil {
 		return err
 	}
+	if _, buried := s.Buried(h); buried {
+		return nil
+	}
 	d, err := e.Serialize()
 	if err != nil {
 		return err
 	}
+	d, err = s.encode(h, d)
+	if err != nil {
+		return err
+	}
 	return s.db.Update(func(tx *bolt.Tx) error {
 		return tx.Bucket(bucketname).Put(h.Slice(), d)
 	})
@@ -61,6 +121,9 @@ func (s *Store) Put(e Serializable) error {
 
 // Get retrieves the serialized object
 func (s *Store) Get(dest Serializable, h hash.Hash) error {
+	if s.observe != nil {
+		defer s.observe("get", time.Now())
+	}
 	var buff []byte
 	err := s.db.View(func(tx *bolt.Tx) error {
 		buff = tx.Bucket(bucketname).Get(h.Slice())
@@ -69,6 +132,13 @@ func (s *Store) Get(dest Serializable, h hash.Hash) error {
 	if err != nil {
 		return err
 	}
+	if buff == nil {
+		return ErrNotFound
+	}
+	buff, err = s.decode(h, buff)
+	if err != nil {
+		return err
+	}
 	return dest.Deserialize(buff)
 }
 
@@ -0,0 +1,170 @@
+package datastore
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coreos/bbolt"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/img"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+func testStore(t *testing.T, o Options) (*Store, func()) {
+	dir, err := ioutil.TempDir("", "datastore")
+	assert.NoError(t, err)
+	s, err := New(filepath.Join(dir, "data.db"), o)
+	assert.NoError(t, err)
+	return s, func() {
+		s.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestCompression(t *testing.T) {
+	_, err := New("unused", Options{Compression: "lz4"})
+	assert.Equal(t, ErrUnknownCompression, err)
+
+	s, cleanup := testStore(t, Options{Compression: Zstd})
+	defer cleanup()
+	dest := &img.Image{}
+
+	assert.Equal(t, ErrNotEnoughSamples, s.Train([][]byte{[]byte("a")}))
+	assert.False(t, s.Trained())
+	samples := [][]byte{}
+	for i := 0; i < 64; i++ {
+		samples = append(samples, []byte(fmt.Sprintf("This is post number %d talking about the tangle and #uspeak, signed by the author %d", i, i%7)))
+	}
+	assert.NoError(t, s.Train(samples))
+	assert.True(t, s.Trained())
+
+	e := &img.Image{Raw: []byte("This is post number 99 talking about the tangle and #uspeak, signed by the author 3")}
+	assert.NoError(t, s.Put(e))
+	h, _ := e.Hash()
+	var stored []byte
+	s.db.View(func(tx *bolt.Tx) error {
+		stored = append([]byte{}, tx.Bucket(bucketname).Get(h.Slice())...)
+		return nil
+	})
+	assert.True(t, len(stored) < len(e.Raw))
+	assert.NoError(t, s.Get(dest, h))
+	assert.Equal(t, e.Raw, dest.Raw)
+	raw, err := s.Raw(h)
+	assert.NoError(t, err)
+	assert.Equal(t, e.Raw, raw)
+}
+
+func TestMagicPayloads(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	for _, o := range []Options{{}, {Compression: Zstd}, {Key: key}, {Compression: Zstd, Key: key}} {
+		s, cleanup := testStore(t, o)
+		for _, magic := range [][]byte{zstdMagic, encryptedMagic, {formatZstd | formatEncrypted}} {
+			e := &img.Image{Raw: append(append([]byte{}, magic...), "user chosen bytes"...)}
+			assert.NoError(t, s.Put(e))
+			h, _ := e.Hash()
+			dest := &img.Image{}
+			assert.NoError(t, s.Get(dest, h))
+			assert.Equal(t, e.Raw, dest.Raw)
+		}
+		cleanup()
+	}
+}
+
+func TestUpgradeFormats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datastore")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "data.db")
+	key := []byte("0123456789abcdef0123456789abcdef")
+	legacy := &Store{}
+	legacy.aead, _ = newAEAD(key)
+	enc, _ := zstd.NewWriter(nil)
+
+	plain := []byte("uncompressed legacy payload")
+	magic := append(append([]byte{}, zstdMagic...), "not a zstd frame"...)
+	compressed := []byte("compressed legacy payload")
+	secret := []byte("encrypted legacy payload")
+	records := map[string][]byte{}
+	for _, p := range [][]byte{plain, magic, compressed, secret} {
+		h, _ := (&img.Image{Raw: p}).Hash()
+		records[string(p)] = h.Slice()
+	}
+	db, err := bolt.Open(path, 0644, nil)
+	assert.NoError(t, err)
+	db.Update(func(tx *bolt.Tx) error {
+		b, _ := tx.CreateBucket(bucketname)
+		b.Put(records[string(plain)], plain)
+		b.Put(records[string(magic)], magic)
+		b.Put(records[string(compressed)], enc.EncodeAll(compressed, nil))
+		sealed, _ := legacy.encrypt(hash.FromSlice(records[string(secret)]), enc.EncodeAll(secret, nil))
+		return b.Put(records[string(secret)], append(append([]byte{}, encryptedMagic...), sealed...))
+	})
+	db.Close()
+
+	_, err = New(path, Options{})
+	assert.Equal(t, ErrNoKey, err)
+	s, err := New(path, Options{Key: key})
+	assert.NoError(t, err)
+	defer s.Close()
+	for p, h := range records {
+		dest := &img.Image{}
+		assert.NoError(t, s.Get(dest, hash.FromSlice(h)))
+		assert.Equal(t, p, string(dest.Raw))
+	}
+}
+
+func TestEncryption(t *testing.T) {
+	_, err := New("unused", Options{Key: []byte("short")})
+	assert.Error(t, err)
+
+	key := []byte("0123456789abcdef0123456789abcdef")
+	s, cleanup := testStore(t, Options{Compression: Zstd, Key: key})
+	defer cleanup()
+	e := &img.Image{Raw: []byte("secret payload")}
+	assert.NoError(t, s.Put(e))
+	h, _ := e.Hash()
+	var stored []byte
+	s.db.View(func(tx *bolt.Tx) error {
+		stored = append([]byte{}, tx.Bucket(bucketname).Get(h.Slice())...)
+		return nil
+	})
+	assert.NotContains(t, string(stored), "secret")
+	dest := &img.Image{}
+	assert.NoError(t, s.Get(dest, h))
+	assert.Equal(t, e.Raw, dest.Raw)
+
+	other := &img.Image{Raw: []byte("other")}
+	oh, _ := other.Hash()
+	_, err = s.decode(oh, stored)
+	assert.Error(t, err)
+
+	s.aead = nil
+	assert.Equal(t, ErrNoKey, s.Get(dest, h))
+}
+
+func TestBury(t *testing.T) {
+	s, cleanup := testStore(t, Options{})
+	defer cleanup()
+	i := &img.Image{Raw: []byte("takedown")}
+	h, _ := i.Hash()
+	assert.NoError(t, s.Put(i))
+	_, ok := s.Buried(h)
+	assert.False(t, ok)
+
+	by := hash.New([]byte("tombstone"))
+	assert.NoError(t, s.Bury(h, by))
+	b, ok := s.Buried(h)
+	assert.True(t, ok)
+	assert.Equal(t, by, b)
+
+	assert.NoError(t, s.Put(i))
+	s.db.View(func(tx *bolt.Tx) error {
+		assert.Nil(t, tx.Bucket(bucketname).Get(h.Slice()))
+		return nil
+	})
+	assert.Equal(t, ErrNotFound, s.Get(&img.Image{}, h))
+}
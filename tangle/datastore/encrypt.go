@@ -0,0 +1,46 @@
+package datastore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+
+	"github.com/u-speak/core/tangle/hash"
+)
+
+var (
+	// encryptedMagic prefixed encrypted payloads before formats were recorded, see upgradeFormats
+	encryptedMagic = []byte{0x00, 'E', 'N', 'C'}
+	// ErrNoKey is returned when reading an encrypted payload without a key configured
+	ErrNoKey = errors.New("Payload is encrypted but no key is configured")
+)
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	b, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(b)
+}
+
+// encrypt seals the payload stored under h, prefixed by the nonce.
+// The hash is authenticated as well, so payloads can not be swapped between keys
+func (s *Store) encrypt(h hash.Hash, d []byte) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return s.aead.Seal(nonce, nonce, d, h.Slice()), nil
+}
+
+func (s *Store) decrypt(h hash.Hash, d []byte) ([]byte, error) {
+	if s.aead == nil {
+		return nil, ErrNoKey
+	}
+	ns := s.aead.NonceSize()
+	if len(d) < ns {
+		return nil, errors.New("Encrypted payload is truncated")
+	}
+	return s.aead.Open(nil, d[:ns], d[ns:], h.Slice())
+}
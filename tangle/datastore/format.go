@@ -0,0 +1,114 @@
+package datastore
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/coreos/bbolt"
+	"github.com/klauspost/compress/zstd"
+	"github.com/u-speak/core/migrate"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+// Every stored payload starts with a byte recording how the store wrote it.
+// Payloads are never inspected to guess their format, since they are chosen by users
+const (
+	formatZstd byte = 1 << iota
+	formatEncrypted
+)
+
+// ErrUnknownFormat is returned for stored payloads with an empty or unsupported format
+var ErrUnknownFormat = errors.New("Stored payload has an unknown format")
+
+// encode compresses and encrypts the payload stored under h as configured and prefixes the format
+func (s *Store) encode(h hash.Hash, d []byte) ([]byte, error) {
+	f := byte(0)
+	d, compressed := s.compress(d)
+	if compressed {
+		f |= formatZstd
+	}
+	if s.aead != nil {
+		var err error
+		if d, err = s.encrypt(h, d); err != nil {
+			return nil, err
+		}
+		f |= formatEncrypted
+	}
+	return append([]byte{f}, d...), nil
+}
+
+// decode reverses encryption and compression of a stored payload according to its format
+func (s *Store) decode(h hash.Hash, d []byte) ([]byte, error) {
+	if len(d) == 0 || d[0]&^(formatZstd|formatEncrypted) != 0 {
+		return nil, ErrUnknownFormat
+	}
+	f, d := d[0], d[1:]
+	var err error
+	if f&formatEncrypted != 0 {
+		if d, err = s.decrypt(h, d); err != nil {
+			return nil, err
+		}
+	}
+	if f&formatZstd != 0 {
+		return s.decompress(d)
+	}
+	return d, nil
+}
+
+// migrations upgrade the payload database to the current format, see migrate.Open
+func (s *Store) migrations() []migrate.Migration {
+	return []migrate.Migration{migrate.None, s.upgradeFormats}
+}
+
+// upgradeFormats prefixes payloads written before formats were recorded with their format.
+// Those payloads were only recognizable by their magic bytes, so a payload is only taken as compressed
+// if it actually decompresses. Encrypted payloads can not be upgraded without the key
+func (s *Store) upgradeFormats(tx *bolt.Tx) error {
+	dicts := [][]byte{}
+	if b := tx.Bucket(dictBucketName); b != nil {
+		b.ForEach(func(k, v []byte) error {
+			dicts = append(dicts, append([]byte{}, v...))
+			return nil
+		})
+	}
+	dec, err := zstd.NewReader(nil, zstd.WithDecoderDicts(dicts...))
+	if err != nil {
+		return err
+	}
+	defer dec.Close()
+	b := tx.Bucket(bucketname)
+	if b == nil {
+		return nil
+	}
+	upgraded := map[string][]byte{}
+	err = b.ForEach(func(k, v []byte) error {
+		f, body, plain := byte(0), v, v
+		if bytes.HasPrefix(v, encryptedMagic) {
+			if s.aead == nil {
+				return ErrNoKey
+			}
+			body = v[len(encryptedMagic):]
+			var err error
+			if plain, err = s.decrypt(hash.FromSlice(k), body); err != nil {
+				return err
+			}
+			f |= formatEncrypted
+		}
+		if bytes.HasPrefix(plain, zstdMagic) {
+			if _, err := dec.DecodeAll(plain, nil); err == nil {
+				f |= formatZstd
+			}
+		}
+		upgraded[string(k)] = append([]byte{f}, body...)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for k, v := range upgraded {
+		if err := b.Put([]byte(k), v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package datastore
+
+import (
+	"github.com/coreos/bbolt"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+var tombBucketName = []byte("tombstones")
+
+// Bury deletes the payload with the content hash and remembers the tombstone which removed it.
+// Buried payloads are never stored again, even if peers send them
+func (s *Store) Bury(content, tombstone hash.Hash) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(bucketname).Delete(content.Slice()); err != nil {
+			return err
+		}
+		return tx.Bucket(tombBucketName).Put(content.Slice(), tombstone.Slice())
+	})
+}
+
+// Buried returns the tombstone which removed the payload with the content hash
+func (s *Store) Buried(content hash.Hash) (hash.Hash, bool) {
+	var by hash.Hash
+	ok := false
+	s.db.View(func(tx *bolt.Tx) error {
+		if b := tx.Bucket(tombBucketName).Get(content.Slice()); b != nil {
+			by = hash.FromSlice(b)
+			ok = true
+		}
+		return nil
+	})
+	return by, ok
+}
@@ -2,14 +2,35 @@ package tangle
 
 import (
 	"errors"
-	"strconv"
+	"fmt"
 )
 
 var (
-	// ErrWeightTooLow is returned when the weight does not exceed MinimumWeight
-	ErrWeightTooLow = errors.New("Weight too low. Has to be > " + strconv.Itoa(MinimumWeight))
 	// ErrNotValidating is returned when the site does not validate any current tip
 	ErrNotValidating = errors.New("Site does not validate any current tip")
 	// ErrTooFewValidations is returned when the site does not validate enough sites
 	ErrTooFewValidations = errors.New("Site does not validate enough sites")
+	// ErrBelowCheckpoint is returned when a site only validates sites below a pinned checkpoint
+	ErrBelowCheckpoint = errors.New("Site only validates sites below a pinned checkpoint")
+	// ErrBuried is returned for payloads removed by a tombstone or the operator
+	ErrBuried = errors.New("Payload has been removed")
+	// ErrNoTimestamp is returned for new sites without timestamp when tips have a maximum age
+	ErrNoTimestamp = errors.New("Site has no timestamp")
+	// ErrStale is returned when a site or the tips it validates are older than the maximum tip age
+	ErrStale = errors.New("Site validates tips older than the maximum tip age")
+	// ErrClockSkew is returned when a site is timestamped further in the future than the allowed clock skew
+	ErrClockSkew = errors.New("Site timestamp lies in the future")
+	// ErrOtherNetwork is returned when the store does not contain the genesis sites of the tangle
+	ErrOtherNetwork = errors.New("Store belongs to a network with other genesis sites")
 )
+
+// WeightError is returned when the weight of a site is below the requirement of its type
+type WeightError struct {
+	Type     string
+	Weight   int
+	Required int
+}
+
+func (e *WeightError) Error() string {
+	return fmt.Sprintf("Weight %d of %s site is below the required weight of %d", e.Weight, e.Type, e.Required)
+}
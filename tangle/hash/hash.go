@@ -2,6 +2,8 @@ package hash
 
 import (
 	"encoding/base64"
+	"errors"
+
 	"github.com/deckarep/golang-set"
 	"golang.org/x/crypto/blake2b"
 )
@@ -23,6 +25,24 @@ func (h Hash) String() string {
 	return base64.URLEncoding.EncodeToString(h[:])
 }
 
+// MarshalText encodes the hash like String, allowing hashes to be used in JSON
+func (h Hash) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+// UnmarshalText decodes a hash encoded by MarshalText
+func (h *Hash) UnmarshalText(b []byte) error {
+	d, err := base64.URLEncoding.DecodeString(string(b))
+	if err != nil {
+		return err
+	}
+	if len(d) != HashSize {
+		return errors.New("Invalid hash length")
+	}
+	copy(h[:], d)
+	return nil
+}
+
 // Weight is the difficulty (or number of leading zeroes) of a site
 func (h Hash) Weight() int {
 	weight := 0
@@ -1,6 +1,7 @@
 package hash
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -16,3 +17,14 @@ func TestWeight(t *testing.T) {
 func TestSlice(t *testing.T) {
 	assert.Equal(t, []byte{1, 3, 3, 7, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, Hash{1, 3, 3, 7}.Slice())
 }
+
+func TestJSON(t *testing.T) {
+	h := Hash{1, 3, 3, 7}
+	b, err := json.Marshal(h)
+	assert.NoError(t, err)
+	assert.Equal(t, `"`+h.String()+`"`, string(b))
+	var r Hash
+	assert.NoError(t, json.Unmarshal(b, &r))
+	assert.Equal(t, h, r)
+	assert.Error(t, json.Unmarshal([]byte(`"AQID"`), &r))
+}
@@ -0,0 +1,167 @@
+package tangle
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+
+	"github.com/u-speak/core/tangle/site"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vmihailenco/msgpack"
+)
+
+// sealedMagic prefixes journal entries encrypted with the payload key. Plain entries start with a msgpack map header instead
+var sealedMagic = []byte{0x00, 'E', 'N', 'C'}
+
+var (
+	// ErrSealedEntry is returned when recovering an encrypted journal entry without the key it was encrypted with
+	ErrSealedEntry = errors.New("Journal entry is encrypted but can not be opened with the configured key")
+	// errEntryMismatch marks a journal entry whose payload does not belong to its site
+	errEntryMismatch = errors.New("Journal entry payload does not match its site")
+)
+
+// entry is an addition recorded in the journal before the site store, the payload store and the listeners are updated
+type entry struct {
+	Site []byte
+	Type string
+	Data []byte
+	Tip  bool
+}
+
+// newSeal returns the cipher journal entries are encrypted with, so payloads of an encrypted data store are not written to the journal in plain text
+func newSeal(key []byte) (cipher.AEAD, error) {
+	if len(key) == 0 {
+		return nil, nil
+	}
+	b, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(b)
+}
+
+// begin records the addition in the journal and returns its sequence number
+func (t *Tangle) begin(o *Object, tip bool) (uint64, error) {
+	if t.journal == nil {
+		return 0, nil
+	}
+	d, err := o.Data.Serialize()
+	if err != nil {
+		return 0, err
+	}
+	b, err := msgpack.Marshal(&entry{Site: o.Site.Serialize(), Type: o.Site.Type, Data: d, Tip: tip})
+	if err != nil {
+		return 0, err
+	}
+	if b, err = t.sealEntry(b); err != nil {
+		return 0, err
+	}
+	return t.journal.Append(b)
+}
+
+// commit marks the addition as complete after all stores and listeners were updated
+func (t *Tangle) commit(seq uint64) error {
+	if t.journal == nil {
+		return nil
+	}
+	return t.journal.Done(seq)
+}
+
+// abort moves an addition which can never be applied to the quarantine file, so it is not retried on every start
+func (t *Tangle) abort(seq uint64, cause error) {
+	if t.journal == nil {
+		return
+	}
+	log.Errorf("Quarantining journal entry %d to %s: %s", seq, t.journal.QuarantinePath(), cause)
+	if err := t.journal.Quarantine(seq); err != nil {
+		log.Errorf("Could not quarantine journal entry %d: %s", seq, err)
+	}
+}
+
+func (t *Tangle) sealEntry(b []byte) ([]byte, error) {
+	if t.seal == nil {
+		return b, nil
+	}
+	nonce := make([]byte, t.seal.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return t.seal.Seal(append(append([]byte{}, sealedMagic...), nonce...), nonce, b, sealedMagic), nil
+}
+
+func (t *Tangle) openEntry(b []byte) ([]byte, error) {
+	if !bytes.HasPrefix(b, sealedMagic) {
+		return b, nil
+	}
+	if t.seal == nil {
+		return nil, ErrSealedEntry
+	}
+	b = b[len(sealedMagic):]
+	ns := t.seal.NonceSize()
+	if len(b) < ns {
+		return nil, ErrSealedEntry
+	}
+	p, err := t.seal.Open(nil, b[:ns], b[ns:], sealedMagic)
+	if err != nil {
+		return nil, ErrSealedEntry
+	}
+	return p, nil
+}
+
+// decode reads the addition recorded in a journal entry and checks that the payload belongs to the site.
+// Entries failing to decode can never be applied
+func (t *Tangle) decode(payload []byte) (*Object, bool, error) {
+	b, err := t.openEntry(payload)
+	if err != nil {
+		return nil, false, err
+	}
+	e := &entry{}
+	if err := msgpack.Unmarshal(b, e); err != nil {
+		return nil, false, err
+	}
+	s := &site.Site{}
+	if err := s.Deserialize(e.Site); err != nil {
+		return nil, false, err
+	}
+	d, err := newData(e.Type)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := d.Deserialize(e.Data); err != nil {
+		return nil, false, err
+	}
+	if h, err := d.Hash(); err != nil || h != s.Content {
+		return nil, false, errEntryMismatch
+	}
+	return &Object{Site: s, Data: d}, e.Tip, nil
+}
+
+// Recover applies the additions left in the journal by a crash or a failed store again, updating the stores and calling the listeners.
+// Additions which can not be decoded are quarantined. Additions failing to apply stay in the journal and are tried again on the next start,
+// the error returned only reports failures of the journal itself.
+// It has to be called after all listeners are registered and returns the amount of recovered sites
+func (t *Tangle) Recover() (int, error) {
+	if t.journal == nil {
+		return 0, nil
+	}
+	n := 0
+	for _, je := range t.journal.Pending() {
+		o, tip, err := t.decode(je.Payload)
+		if err != nil {
+			t.abort(je.Seq, err)
+			continue
+		}
+		if err := t.apply(o, tip); err != nil {
+			log.Errorf("Could not recover journal entry %d, keeping it for the next start: %s", je.Seq, err)
+			continue
+		}
+		if err := t.journal.Done(je.Seq); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
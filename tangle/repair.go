@@ -0,0 +1,133 @@
+package tangle
+
+import (
+	"errors"
+	"time"
+
+	"github.com/u-speak/core/tangle/datastore"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+)
+
+// Kinds of damage found by Check
+const (
+	// DanglingTip is a tip without a stored site, left when adding the site was interrupted
+	DanglingTip = "dangling_tip"
+	// Unreadable is a stored site which can not be decoded
+	Unreadable = "unreadable"
+	// MissingParent is a validated site which is not stored
+	MissingParent = "missing_parent"
+	// MissingPayload is a site whose payload was neither stored nor removed
+	MissingPayload = "missing_payload"
+)
+
+// ErrNotEmbedded is returned when the site does not validate the parent to restore
+var ErrNotEmbedded = errors.New("Site does not validate the parent")
+
+// Damage is an inconsistency of the stores, usually left by a crash while a site was added.
+// Missing is the hash of the missing site or, for payloads, the content hash
+type Damage struct {
+	Kind    string    `json:"kind"`
+	Site    hash.Hash `json:"site"`
+	Missing hash.Hash `json:"missing"`
+	Type    string    `json:"type,omitempty"`
+}
+
+// Check walks the stores and returns all damage found
+func (t *Tangle) Check() []Damage {
+	ds := []Damage{}
+	stored := make(map[hash.Hash]bool)
+	hs := t.store.Hashes()
+	for _, h := range hs {
+		stored[h] = true
+	}
+	for _, h := range t.store.GetTips() {
+		if !stored[h] {
+			ds = append(ds, Damage{Kind: DanglingTip, Site: h, Missing: h})
+		}
+	}
+	for _, h := range hs {
+		s := t.store.Get(h)
+		if s == nil {
+			ds = append(ds, Damage{Kind: Unreadable, Site: h, Missing: h})
+			continue
+		}
+		for _, v := range s.Validates {
+			if !stored[v.Hash()] {
+				ds = append(ds, Damage{Kind: MissingParent, Site: h, Missing: v.Hash()})
+			}
+		}
+		if s.Type == "genesis" {
+			continue
+		}
+		if _, err := t.GetData(s.Type, s.Content); err == datastore.ErrNotFound {
+			ds = append(ds, Damage{Kind: MissingPayload, Site: h, Missing: s.Content, Type: s.Type})
+		}
+	}
+	return ds
+}
+
+// RestoreParent stores the parent again from the copy embedded in the site validating it
+func (t *Tangle) RestoreParent(h, parent hash.Hash) error {
+	s := t.store.Get(h)
+	if s == nil {
+		return errors.New("Site not found")
+	}
+	for _, v := range s.Validates {
+		if v.Hash() == parent {
+			return t.store.Add(v)
+		}
+	}
+	return ErrNotEmbedded
+}
+
+// Restore stores the site again from the copy embedded in any site validating it
+func (t *Tangle) Restore(h hash.Hash) error {
+	for _, c := range t.store.Hashes() {
+		if err := t.RestoreParent(c, h); err != ErrNotEmbedded {
+			return err
+		}
+	}
+	return ErrNotEmbedded
+}
+
+// RestoreSite stores a site fetched to replace a missing or unreadable record.
+// The caller has to make sure it has the expected hash
+func (t *Tangle) RestoreSite(s *site.Site) error {
+	return t.store.Add(s)
+}
+
+// Truncate removes the site and its tip entry from the store.
+// Sites validating it are not removed, Check reports them as missing a parent
+func (t *Tangle) Truncate(h hash.Hash) error {
+	if err := t.store.Delete(h); err != nil {
+		return err
+	}
+	t.work.remove(h)
+	t.tipLock.Lock()
+	delete(t.tips, h)
+	t.tipLock.Unlock()
+	return nil
+}
+
+// RebuildTips marks all stored sites which are not validated by any other site as tips
+func (t *Tangle) RebuildTips() {
+	validated := make(map[hash.Hash]bool)
+	hs := t.store.Hashes()
+	for _, h := range hs {
+		if s := t.store.Get(h); s != nil {
+			for _, v := range s.Validates {
+				validated[v.Hash()] = true
+			}
+		}
+	}
+	t.tipLock.Lock()
+	defer t.tipLock.Unlock()
+	for _, h := range hs {
+		if _, ok := t.tips[h]; ok || validated[h] {
+			continue
+		}
+		t.tips[h] = time.Now()
+		t.store.SetTips(h, nil)
+	}
+}
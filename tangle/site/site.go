@@ -1,27 +1,55 @@
 package site
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/u-speak/core/tangle/hash"
 	"github.com/vmihailenco/msgpack"
 )
 
+// ErrNilValidation is returned when a deserialized site validates an empty site
+var ErrNilValidation = errors.New("Site validates an empty site")
+
 // Site represents a single storage node inside the tangle
 type Site struct {
 	Validates []*Site
 	Nonce     uint64
 	Content   hash.Hash
 	Type      string
+	// Timestamp is the unix time the site was mined at. Sites without timestamp hash like before it was introduced
+	Timestamp int64 `msgpack:",omitempty"`
 }
 
 // Hash computes the hash of the site
 func (s *Site) Hash() hash.Hash {
-	ts := "C" + s.Content.String() + "N" + strconv.FormatUint(s.Nonce, 10) + "T" + s.Type
-	for _, s := range s.Validates {
-		ts += "V" + s.Hash().String()
+	return hash.New(s.Canonical())
+}
+
+// Canonical returns the bytes the hash of the site is computed from
+func (s *Site) Canonical() []byte {
+	vs := make([]hash.Hash, len(s.Validates))
+	for i, v := range s.Validates {
+		vs[i] = v.Hash()
 	}
-	return hash.New([]byte(ts))
+	return CanonicalOf(s.Content, s.Nonce, s.Type, s.Timestamp, vs)
+}
+
+// HashOf computes the hash of a site from the hashes of the validated sites, without needing the sites themselves
+func HashOf(content hash.Hash, nonce uint64, typ string, timestamp int64, validates []hash.Hash) hash.Hash {
+	return hash.New(CanonicalOf(content, nonce, typ, timestamp, validates))
+}
+
+// CanonicalOf returns the canonical bytes of a site from the hashes of the validated sites
+func CanonicalOf(content hash.Hash, nonce uint64, typ string, timestamp int64, validates []hash.Hash) []byte {
+	ts := "C" + content.String() + "N" + strconv.FormatUint(nonce, 10) + "T" + typ
+	for _, v := range validates {
+		ts += "V" + v.String()
+	}
+	if timestamp != 0 {
+		ts += "S" + strconv.FormatInt(timestamp, 10)
+	}
+	return []byte(ts)
 }
 
 // Serialize converts the site to a slice of bytes
@@ -32,7 +60,23 @@ func (s *Site) Serialize() []byte {
 
 // Deserialize restores the site from a slice of bytes
 func (s *Site) Deserialize(b []byte) error {
-	return msgpack.Unmarshal(b, s)
+	if err := msgpack.Unmarshal(b, s); err != nil {
+		return err
+	}
+	return s.check()
+}
+
+// check rejects sites which cannot be hashed because of missing validations
+func (s *Site) check() error {
+	for _, v := range s.Validates {
+		if v == nil {
+			return ErrNilValidation
+		}
+		if err := v.check(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // Mine the block for a specifig weight
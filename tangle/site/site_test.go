@@ -21,6 +21,21 @@ func TestHash(t *testing.T) {
 
 	// Testing linked sites
 	assert.Equal(t, hash.Hash{0x8c, 0x98, 0xc5, 0x7d, 0xb8, 0x78, 0x76, 0x8c, 0xe8, 0xcf, 0xb, 0x2e, 0xfb, 0xfa, 0x9a, 0x69, 0xf, 0x6d, 0x77, 0xe5, 0x16, 0x9e, 0x29, 0xa6, 0x41, 0x44, 0x6a, 0x27, 0x74, 0x52, 0xae, 0x55}, dummySite.Hash())
+	assert.Equal(t, complexSite.Hash(), HashOf(dummyContent, 0, "", 0, []hash.Hash{dummySite.Hash(), complexSite.Validates[1].Hash()}))
+}
+
+func TestTimestamp(t *testing.T) {
+	s := &Site{Content: dummyContent, Validates: []*Site{&dummySite}}
+	legacy := s.Hash()
+	s.Timestamp = 1500000000
+	assert.NotEqual(t, legacy, s.Hash())
+	assert.Equal(t, s.Hash(), HashOf(dummyContent, 0, "", 1500000000, []hash.Hash{dummySite.Hash()}))
+
+	r := &Site{}
+	assert.NoError(t, r.Deserialize(s.Serialize()))
+	assert.Equal(t, s.Hash(), r.Hash())
+	s.Timestamp = 0
+	assert.NotContains(t, string(s.Serialize()), "Timestamp")
 }
 
 func BenchmarkSimpleSite(b *testing.B) {
@@ -41,3 +56,17 @@ func BenchmarkComplexSite(b *testing.B) {
 		complexSite.Hash()
 	}
 }
+
+func FuzzDeserialize(f *testing.F) {
+	f.Add(complexSite.Serialize())
+	f.Add(dummySite.Serialize())
+	f.Add([]byte{})
+	f.Fuzz(func(t *testing.T, b []byte) {
+		s := &Site{}
+		if err := s.Deserialize(b); err != nil {
+			return
+		}
+		s.Hash()
+		s.Serialize()
+	})
+}
@@ -0,0 +1,67 @@
+package tangle
+
+import (
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+)
+
+// Stats summarize the shape of the tangle. Depths are confirmation depths as returned by Depth
+type Stats struct {
+	Size          int     `json:"size"`
+	Tips          int     `json:"tips"`
+	AverageWeight float64 `json:"average_weight"`
+	AverageDepth  float64 `json:"average_depth"`
+	MaxDepth      int     `json:"max_depth"`
+}
+
+// Stats walks the tangle from the tips and computes its statistics
+func (t *Tangle) Stats() Stats {
+	hs := t.Hashes()
+	s := Stats{Size: len(hs), Tips: len(t.tipHashes())}
+	if len(hs) == 0 {
+		return s
+	}
+	weight := 0
+	for _, h := range hs {
+		weight += t.workOf(h)
+	}
+	s.AverageWeight = float64(weight) / float64(len(hs))
+	// Visiting the validated sites before a site orders the sites from the genesis up, so walking
+	// the order backwards passes every site before the sites it validates
+	seen := make(map[hash.Hash]bool)
+	order := []*site.Site{}
+	var visit func(*site.Site)
+	visit = func(c *site.Site) {
+		h := c.Hash()
+		if seen[h] {
+			return
+		}
+		seen[h] = true
+		for _, v := range c.Validates {
+			visit(v)
+		}
+		order = append(order, c)
+	}
+	for _, tip := range t.Tips() {
+		visit(tip)
+	}
+	depth := make(map[hash.Hash]int)
+	total := 0
+	for i := len(order) - 1; i >= 0; i-- {
+		c := order[i]
+		d := depth[c.Hash()]
+		total += d
+		if d > s.MaxDepth {
+			s.MaxDepth = d
+		}
+		for _, v := range c.Validates {
+			if vh := v.Hash(); depth[vh] < d+1 {
+				depth[vh] = d + 1
+			}
+		}
+	}
+	if len(order) > 0 {
+		s.AverageDepth = float64(total) / float64(len(order))
+	}
+	return s
+}
@@ -1,6 +1,10 @@
+// Package boltstore keeps the sites of the tangle in a bolt database.
+// Sites are stored msgpack encoded under their hash in the data bucket, tips are the keys of the tips bucket.
+// Both formats are independent of Go types, so stores can be inspected with any bolt and msgpack tooling
 package boltstore
 
 import (
+	"github.com/u-speak/core/migrate"
 	"github.com/u-speak/core/tangle/hash"
 	"github.com/u-speak/core/tangle/site"
 	"github.com/u-speak/core/tangle/store"
@@ -9,6 +13,9 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// migrations upgrade the site database to the current format, see migrate.Open
+var migrations = []migrate.Migration{migrate.None}
+
 var (
 	dataBucketName = []byte("data")
 	tipBucketName  = []byte("tips")
@@ -60,9 +67,19 @@ func (b *BoltStore) Get(h hash.Hash) *site.Site {
 	return &s
 }
 
+// Delete removes the site and its tip entry from the database
+func (b *BoltStore) Delete(h hash.Hash) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(dataBucketName).Delete(h.Slice()); err != nil {
+			return err
+		}
+		return tx.Bucket(tipBucketName).Delete(h.Slice())
+	})
+}
+
 // Init the store
 func (b *BoltStore) Init(o store.Options) error {
-	db, err := bolt.Open(o.Path, 0644, nil)
+	db, err := migrate.Open(o.Path, 0644, migrations)
 	if err != nil {
 		return err
 	}
@@ -80,6 +97,7 @@ func (b *BoltStore) Init(o store.Options) error {
 	if err != nil {
 		return err
 	}
+	db.NoSync = o.NoSync
 	b.db = db
 	return nil
 }
@@ -60,4 +60,10 @@ func TestAddGet(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, site3, s.Get(site3.Hash()))
 	assert.Equal(t, site2, s.Get(site3.Hash()).Validates[1])
+
+	s.SetTips(site3.Hash(), nil)
+	assert.NoError(t, s.Delete(site3.Hash()))
+	assert.Nil(t, s.Get(site3.Hash()))
+	assert.Empty(t, s.GetTips())
+	assert.Equal(t, 2, s.Size())
 }
@@ -1,6 +1,8 @@
 package memorystore
 
 import (
+	"sync"
+
 	"github.com/u-speak/core/tangle/hash"
 	"github.com/u-speak/core/tangle/site"
 	"github.com/u-speak/core/tangle/store"
@@ -10,10 +12,13 @@ import (
 type MemoryStore struct {
 	tips map[hash.Hash]bool
 	data map[hash.Hash]*site.Site
+	lock sync.RWMutex
 }
 
 // Init initializes the maps
 func (m *MemoryStore) Init(store.Options) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
 	m.tips = make(map[hash.Hash]bool)
 	m.data = make(map[hash.Hash]*site.Site)
 	return nil
@@ -21,17 +26,32 @@ func (m *MemoryStore) Init(store.Options) error {
 
 // Add adds the record to the data section
 func (m *MemoryStore) Add(s *site.Site) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
 	m.data[s.Hash()] = s
 	return nil
 }
 
 // Get returns the data
 func (m *MemoryStore) Get(h hash.Hash) *site.Site {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
 	return m.data[h]
 }
 
+// Delete removes the record and its tip entry
+func (m *MemoryStore) Delete(h hash.Hash) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	delete(m.data, h)
+	delete(m.tips, h)
+	return nil
+}
+
 // SetTips applies the delta
 func (m *MemoryStore) SetTips(add hash.Hash, del []*site.Site) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
 	for _, d := range del {
 		delete(m.tips, d.Hash())
 	}
@@ -40,6 +60,8 @@ func (m *MemoryStore) SetTips(add hash.Hash, del []*site.Site) {
 
 // GetTips returns the tips
 func (m *MemoryStore) GetTips() []hash.Hash {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
 	tips := []hash.Hash{}
 	for k := range m.tips {
 		tips = append(tips, k)
@@ -52,11 +74,15 @@ func (m *MemoryStore) Close() {}
 
 // Size returns the len of the data
 func (m *MemoryStore) Size() int {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
 	return len(m.data)
 }
 
 // Hashes returns all stored hashes
 func (m *MemoryStore) Hashes() []hash.Hash {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
 	hs := []hash.Hash{}
 	for k := range m.data {
 		hs = append(hs, k)
@@ -4,6 +4,7 @@ import (
 	"github.com/u-speak/core/tangle/hash"
 	"github.com/u-speak/core/tangle/site"
 	"github.com/u-speak/core/tangle/store"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -55,4 +56,31 @@ func TestAddGet(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, site3, s.Get(site3.Hash()))
 	assert.Equal(t, site2, s.Get(site3.Hash()).Validates[1])
+
+	s.SetTips(site3.Hash(), nil)
+	assert.NoError(t, s.Delete(site3.Hash()))
+	assert.Nil(t, s.Get(site3.Hash()))
+	assert.Empty(t, s.GetTips())
+	assert.Equal(t, 2, s.Size())
+}
+
+func TestConcurrent(t *testing.T) {
+	s := MemoryStore{}
+	err := s.Init(store.Options{})
+	assert.NoError(t, err)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			st := &site.Site{Content: hash.Hash{byte(i)}}
+			assert.NoError(t, s.Add(st))
+			s.SetTips(st.Hash(), nil)
+			assert.Equal(t, st, s.Get(st.Hash()))
+			s.Hashes()
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 8, s.Size())
+	assert.Len(t, s.GetTips(), 8)
 }
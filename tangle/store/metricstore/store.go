@@ -0,0 +1,90 @@
+package metricstore
+
+import (
+	"time"
+
+	"github.com/u-speak/core/logging"
+	"github.com/u-speak/core/metrics"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+	"github.com/u-speak/core/tangle/store"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Observer records the duration of store operations and logs slow ones
+type Observer struct {
+	Name string
+	Slow time.Duration
+}
+
+// Observe records an operation that started at start
+func (o *Observer) Observe(op string, start time.Time) {
+	took := time.Since(start)
+	metrics.StoreOperations.WithLabelValues(o.Name, op).Inc()
+	metrics.StoreLatency.WithLabelValues(o.Name, op).Observe(took.Seconds())
+	if o.Slow > 0 && took >= o.Slow {
+		log.Warnf("Slow store operation %s.%s took %s", o.Name, op, took)
+		return
+	}
+	logging.Debugf("store", "Store operation %s.%s took %s", o.Name, op, took)
+}
+
+// MetricStore wraps a store, instrumenting every operation
+type MetricStore struct {
+	store.Store
+	Observer
+}
+
+// New wraps s, reporting its operations under name
+func New(name string, s store.Store, slow time.Duration) *MetricStore {
+	return &MetricStore{Store: s, Observer: Observer{Name: name, Slow: slow}}
+}
+
+// Add implements store.Store
+func (m *MetricStore) Add(s *site.Site) error {
+	defer m.Observe("add", time.Now())
+	return m.Store.Add(s)
+}
+
+// Get implements store.Store
+func (m *MetricStore) Get(h hash.Hash) *site.Site {
+	defer m.Observe("get", time.Now())
+	return m.Store.Get(h)
+}
+
+// Init implements store.Store
+func (m *MetricStore) Init(o store.Options) error {
+	defer m.Observe("init", time.Now())
+	return m.Store.Init(o)
+}
+
+// Delete implements store.Store
+func (m *MetricStore) Delete(h hash.Hash) error {
+	defer m.Observe("delete", time.Now())
+	return m.Store.Delete(h)
+}
+
+// SetTips implements store.Store
+func (m *MetricStore) SetTips(add hash.Hash, del []*site.Site) {
+	defer m.Observe("set_tips", time.Now())
+	m.Store.SetTips(add, del)
+}
+
+// GetTips implements store.Store
+func (m *MetricStore) GetTips() []hash.Hash {
+	defer m.Observe("get_tips", time.Now())
+	return m.Store.GetTips()
+}
+
+// Hashes implements store.Store
+func (m *MetricStore) Hashes() []hash.Hash {
+	defer m.Observe("hashes", time.Now())
+	return m.Store.Hashes()
+}
+
+// Size implements store.Store
+func (m *MetricStore) Size() int {
+	defer m.Observe("size", time.Now())
+	return m.Store.Size()
+}
@@ -0,0 +1,25 @@
+package metricstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/metrics"
+	"github.com/u-speak/core/tangle/site"
+	"github.com/u-speak/core/tangle/store"
+	"github.com/u-speak/core/tangle/store/memorystore"
+)
+
+func TestMetricStore(t *testing.T) {
+	var s store.Store = New("test", &memorystore.MemoryStore{}, time.Second)
+	assert.NoError(t, s.Init(store.Options{}))
+	st := &site.Site{Type: "dummy", Nonce: 1}
+	assert.NoError(t, s.Add(st))
+	assert.Equal(t, st, s.Get(st.Hash()))
+	assert.Nil(t, s.Get((&site.Site{Nonce: 2}).Hash()))
+	assert.Equal(t, 1, s.Size())
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.StoreOperations.WithLabelValues("test", "get")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.StoreOperations.WithLabelValues("test", "add")))
+}
@@ -9,6 +9,7 @@ import (
 type Store interface {
 	Add(*site.Site) error
 	Get(hash.Hash) *site.Site
+	Delete(hash.Hash) error
 	Init(Options) error
 	SetTips(hash.Hash, []*site.Site)
 	GetTips() []hash.Hash
@@ -25,4 +26,6 @@ func Empty(s Store) bool {
 // Options for the store, used at initialization
 type Options struct {
 	Path string
+	// NoSync skips the fsync after every commit. Writes are faster but the last commits may be lost on power failure
+	NoSync bool
 }
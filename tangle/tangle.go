@@ -1,15 +1,26 @@
 package tangle
 
 import (
+	"crypto/cipher"
+	"errors"
+	"fmt"
 	"math/rand"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/u-speak/core/follow"
 	"github.com/u-speak/core/img"
+	"github.com/u-speak/core/journal"
 	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/pow"
+	"github.com/u-speak/core/subscription"
 	"github.com/u-speak/core/tangle/datastore"
 	"github.com/u-speak/core/tangle/hash"
 	"github.com/u-speak/core/tangle/site"
 	"github.com/u-speak/core/tangle/store"
+	"github.com/u-speak/core/tombstone"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -25,15 +36,54 @@ const (
 
 // Tangle stores the relation between different transactions
 type Tangle struct {
-	tips  map[hash.Hash]bool
-	store store.Store
-	data  *datastore.Store
+	tips        map[hash.Hash]time.Time
+	tipLock     sync.RWMutex
+	store       store.Store
+	data        *datastore.Store
+	listeners   []func(*Object)
+	checkpoints []hash.Hash
+	final       map[hash.Hash]bool
+	finalLock   sync.Mutex
+	required    map[string]Requirement
+	minWeight   int
+	pow         pow.Algorithm
+	work        *workCache
+	maxTipAge   time.Duration
+	maxSkew     time.Duration
+	journal     *journal.Journal
+	seal        cipher.AEAD
+	genesis     []*site.Site
+}
+
+// Requirement is the proof of work a new site has to provide
+type Requirement struct {
+	Weight      int `json:"weight"`
+	Validations int `json:"validations"`
 }
 
 // Options are used for initial configuration
 type Options struct {
-	Store    store.Store
-	DataPath string
+	Store         store.Store
+	DataPath      string
+	Compression   string
+	EncryptionKey []byte
+	Observe       func(op string, start time.Time)
+	NoSync        bool
+	// Genesis are the initial tips of a new tangle. The two builtin genesis sites are used if empty
+	Genesis []*site.Site
+	// MinWeight replaces MinimumWeight as the weight new sites of all types need if set
+	MinWeight int
+	// Requirements override MinWeight and MinimumValidations by site type
+	Requirements map[string]Requirement
+	// PoW is the proof of work algorithm of the network, pow.Default if nil
+	PoW pow.Algorithm
+	// MaxTipAge is the maximum age of the tips a new site validates, 0 accepts sites without timestamp.
+	// MaxClockSkew is how far timestamps may lie in the future
+	MaxTipAge    time.Duration
+	MaxClockSkew time.Duration
+	// Journal records every addition before the stores are written, see Recover. Additions are not journaled if nil.
+	// Entries are encrypted with EncryptionKey if set
+	Journal *journal.Journal
 }
 
 // Object is the exposed site including the content
@@ -44,7 +94,7 @@ type Object struct {
 
 // New returns a fresh initialized tangle
 func New(o Options) (*Tangle, error) {
-	ds, err := datastore.New(o.DataPath)
+	ds, err := datastore.New(o.DataPath, datastore.Options{Compression: o.Compression, Key: o.EncryptionKey, Observe: o.Observe, NoSync: o.NoSync})
 	if err != nil {
 		return nil, err
 	}
@@ -54,49 +104,93 @@ func New(o Options) (*Tangle, error) {
 
 // Init initializes the tangle with two genesis blocks
 func (t *Tangle) Init(o Options) error {
-	t.tips = make(map[hash.Hash]bool)
+	t.tips = make(map[hash.Hash]time.Time)
 	t.store = o.Store
+	t.required = o.Requirements
+	t.minWeight = o.MinWeight
+	if t.minWeight <= 0 {
+		t.minWeight = MinimumWeight
+	}
+	t.pow = o.PoW
+	if t.pow == nil {
+		t.pow = pow.Default
+	}
+	t.work = newWorkCache(MaxCachedWork)
+	t.maxTipAge = o.MaxTipAge
+	t.maxSkew = o.MaxClockSkew
+	t.journal = o.Journal
+	seal, err := newSeal(o.EncryptionKey)
+	if err != nil {
+		return err
+	}
+	t.seal = seal
+	gen := o.Genesis
+	if len(gen) == 0 {
+		gen = []*site.Site{
+			{Content: hash.Hash{24, 67, 68, 72, 132, 181}, Nonce: 373, Type: "genesis"},
+			{Content: hash.Hash{24, 67, 68, 72, 132, 182}, Nonce: 510, Type: "genesis"},
+		}
+	}
+	t.genesis = gen
 	if store.Empty(t.store) {
-		gen1 := &site.Site{Content: hash.Hash{24, 67, 68, 72, 132, 181}, Nonce: 373, Type: "genesis"}
-		gen2 := &site.Site{Content: hash.Hash{24, 67, 68, 72, 132, 182}, Nonce: 510, Type: "genesis"}
-		err := t.store.Add(gen1)
-		if err != nil {
-			return err
+		for _, g := range gen {
+			if err := t.store.Add(g); err != nil {
+				return err
+			}
+			t.store.SetTips(g.Hash(), nil)
 		}
-		err = t.store.Add(gen2)
-		if err != nil {
-			return err
+	}
+	for _, g := range gen {
+		if t.store.Get(g.Hash()) == nil {
+			return ErrOtherNetwork
 		}
-		t.store.SetTips(gen1.Hash(), nil)
-		t.store.SetTips(gen2.Hash(), nil)
 	}
 	for _, tip := range t.store.GetTips() {
-		t.tips[tip] = true
+		t.tips[tip] = time.Now()
 	}
 	return nil
 }
 
+// Rules returns the options defining the network of the tangle: its genesis sites, the required weights and the proof of work.
+// Storage options are left empty, so the result can be completed to open another tangle of the same network
+func (t *Tangle) Rules() Options {
+	return Options{
+		Genesis:      t.genesis,
+		MinWeight:    t.minWeight,
+		Requirements: t.required,
+		PoW:          t.pow,
+		MaxTipAge:    t.maxTipAge,
+		MaxClockSkew: t.maxSkew,
+	}
+}
+
 // Add Validates the site and adds it to the tangle
 // to be valid, a site has to:
 // * Validate at least one tip
-// * Have a weight of at least MinimumWeight
+// * Have at least the weight required for its type
 func (t *Tangle) Add(s *Object) error {
+	err := t.Validate(s)
+	if err != nil {
+		return err
+	}
+	return t.addSite(s, true)
+}
+
+// Validate checks whether the site could be added to the tangle without adding it
+func (t *Tangle) Validate(s *Object) error {
 	err := t.verifySite(s.Site)
 	if err != nil {
 		return err
 	}
-	v := func() bool {
-		for _, v := range s.Site.Validates {
-			if t.HasTip(v.Hash()) {
-				return true
-			}
+	if err := t.Fresh(s.Site, time.Now()); err != nil {
+		return err
+	}
+	for _, v := range s.Site.Validates {
+		if t.HasTip(v.Hash()) {
+			return nil
 		}
-		return false
-	}()
-	if !v {
-		return ErrNotValidating
 	}
-	return t.addSite(s, true)
+	return ErrNotValidating
 }
 
 // Size returns the amount of sites in the tangle
@@ -104,13 +198,13 @@ func (t *Tangle) Size() int {
 	return t.store.Size()
 }
 
-// Tips returns a list of unconfirmed tips
+// Tips returns a list of unconfirmed tips, including tips whose payload was removed
 func (t *Tangle) Tips() []*site.Site {
 	keys := []*site.Site{}
-	for h := range t.tips {
-		s := t.Get(h)
+	for _, h := range t.tipHashes() {
+		s := t.GetSite(h)
 		if s != nil {
-			keys = append(keys, s.Site)
+			keys = append(keys, s)
 		}
 	}
 	return keys
@@ -122,39 +216,77 @@ func (t *Tangle) Get(h hash.Hash) *Object {
 	if md == nil {
 		return nil
 	}
-	var data datastore.Serializable
-	switch md.Type {
+	if md.Type == "genesis" {
+		return &Object{Site: md, Data: &genesis{}}
+	}
+	if _, buried := t.data.Buried(md.Content); buried {
+		return nil
+	}
+	data, err := t.GetData(md.Type, md.Content)
+	if err != nil {
+		log.Error(err)
+		return nil
+	}
+	return &Object{Site: md, Data: data}
+}
+
+// GetData returns the stored payload of a site type by its content hash.
+// Payloads removed by a tombstone return ErrBuried
+func (t *Tangle) GetData(typ string, content hash.Hash) (datastore.Serializable, error) {
+	data, err := newData(typ)
+	if err != nil {
+		return nil, err
+	}
+	if _, buried := t.data.Buried(content); buried {
+		return nil, ErrBuried
+	}
+	if err := t.data.Get(data, content); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// newData returns an empty payload of the site type
+func newData(typ string) (datastore.Serializable, error) {
+	switch typ {
 	case "post":
-		p := &post.Post{}
-		err := t.data.Get(p, md.Content)
-		if err != nil {
-			log.Error(err)
-			return nil
-		}
-		data = p
-	case "genesis":
-		data = &genesis{}
+		return &post.Post{}, nil
 	case "image":
-		i := &img.Image{}
-		err := t.data.Get(i, md.Content)
-		if err != nil {
-			log.Error(err)
-			return nil
-		}
-		data = i
+		return &img.Image{}, nil
+	case "subscription":
+		return &subscription.Subscription{}, nil
+	case "follow":
+		return &follow.List{}, nil
+	case "tombstone":
+		return &tombstone.Tombstone{}, nil
 	case "dummy":
-		d := &dummydata{}
-		err := t.data.Get(d, md.Content)
-		if err != nil {
-			log.Error(err)
-			return nil
-		}
-		data = d
-	default:
-		log.Errorf("Type `%s' not implemented", md.Type)
-		return nil
+		return &dummydata{}, nil
 	}
-	return &Object{Site: md, Data: data}
+	return nil, fmt.Errorf("Type `%s' not implemented", typ)
+}
+
+// PutData stores a payload without a site, e.g. one fetched for a site whose payload was missing
+func (t *Tangle) PutData(d datastore.Serializable) error {
+	return t.data.Put(d)
+}
+
+// Bury removes the payload of the site while keeping the site as part of the tangle.
+// by is the hash of the tombstone site, or the zero hash if the operator removed the payload locally
+func (t *Tangle) Bury(h, by hash.Hash) error {
+	s := t.GetSite(h)
+	if s == nil {
+		return errors.New("Site not found")
+	}
+	return t.data.Bury(s.Content, by)
+}
+
+// Buried returns the tombstone which removed the payload of the site
+func (t *Tangle) Buried(h hash.Hash) (hash.Hash, bool) {
+	s := t.GetSite(h)
+	if s == nil {
+		return hash.Hash{}, false
+	}
+	return t.data.Buried(s.Content)
 }
 
 // GetSite returns the site without any data
@@ -166,11 +298,25 @@ func (t *Tangle) GetSite(h hash.Hash) *site.Site {
 func (t *Tangle) Close() {
 	t.store.Close()
 	t.data.Close()
+	if t.journal != nil {
+		t.journal.Close()
+	}
 }
 
 // HasTip checks if the specified hash is a tip of the current tangle
 func (t *Tangle) HasTip(h hash.Hash) bool {
-	return t.tips[h]
+	t.tipLock.RLock()
+	defer t.tipLock.RUnlock()
+	_, ok := t.tips[h]
+	return ok
+}
+
+// TipSince returns when the tip was added. Tips loaded from the store count from the start of the node
+func (t *Tangle) TipSince(h hash.Hash) (time.Time, bool) {
+	t.tipLock.RLock()
+	defer t.tipLock.RUnlock()
+	s, ok := t.tips[h]
+	return s, ok
 }
 
 // Weight returns the weight of a specific site inside the tangle
@@ -211,13 +357,13 @@ func (t *Tangle) Weight(s *site.Site) int {
 			}
 		}
 	}
-	w := s.Hash().Weight()
+	w := t.Work(s)
 	inject(rvl[s.Hash()])
 	for len(bound) != 0 {
 		for st := range bound {
 			delete(bound, st)
 			excl[st.Hash()] = true
-			w += st.Hash().Weight()
+			w += t.Work(st)
 			for _, v := range rvl[st.Hash()] {
 				if !excl[v.Hash()] {
 					bound[v] = true
@@ -228,6 +374,156 @@ func (t *Tangle) Weight(s *site.Site) int {
 	return w
 }
 
+// Weights returns the weights of the sites as returned by Weight. The sites validating each site are collected
+// in a single walk from the tips, so weighing many sites does not walk the whole tangle for every site
+func (t *Tangle) Weights(ss []*site.Site) map[hash.Hash]int {
+	rvl := make(map[hash.Hash][]*site.Site)
+	seen := make(map[hash.Hash]bool)
+	bound := t.Tips()
+	for _, tip := range bound {
+		seen[tip.Hash()] = true
+	}
+	for len(bound) != 0 {
+		st := bound[len(bound)-1]
+		bound = bound[:len(bound)-1]
+		for _, v := range st.Validates {
+			vh := v.Hash()
+			rvl[vh] = append(rvl[vh], st)
+			if !seen[vh] {
+				seen[vh] = true
+				bound = append(bound, v)
+			}
+		}
+	}
+	ws := make(map[hash.Hash]int, len(ss))
+	for _, s := range ss {
+		h := s.Hash()
+		w := t.Work(s)
+		excl := map[hash.Hash]bool{h: true}
+		bound = append(bound[:0], rvl[h]...)
+		for len(bound) != 0 {
+			st := bound[len(bound)-1]
+			bound = bound[:len(bound)-1]
+			sh := st.Hash()
+			if excl[sh] {
+				continue
+			}
+			excl[sh] = true
+			w += t.Work(st)
+			bound = append(bound, rvl[sh]...)
+		}
+		ws[h] = w
+	}
+	return ws
+}
+
+// Depth returns the confirmation depth of a site, the length of the longest path from a tip down to the site.
+// Tips have a depth of zero
+func (t *Tangle) Depth(s *site.Site) int {
+	target := s.Hash()
+	memo := make(map[hash.Hash]int)
+	var depth func(*site.Site) int
+	depth = func(c *site.Site) int {
+		h := c.Hash()
+		if h == target {
+			return 0
+		}
+		if d, ok := memo[h]; ok {
+			return d
+		}
+		d := -1
+		for _, v := range c.Validates {
+			if vd := depth(v); vd >= 0 && vd+1 > d {
+				d = vd + 1
+			}
+		}
+		memo[h] = d
+		return d
+	}
+	max := 0
+	for _, tip := range t.Tips() {
+		if d := depth(tip); d > max {
+			max = d
+		}
+	}
+	return max
+}
+
+// Approvers returns the hashes of all sites directly validating the site, ordered by hash
+func (t *Tangle) Approvers(h hash.Hash) []hash.Hash {
+	seen := make(map[hash.Hash]bool)
+	approvers := []hash.Hash{}
+	bound := t.Tips()
+	for len(bound) != 0 {
+		c := bound[len(bound)-1]
+		bound = bound[:len(bound)-1]
+		ch := c.Hash()
+		if seen[ch] || ch == h {
+			continue
+		}
+		seen[ch] = true
+		for _, v := range c.Validates {
+			if v.Hash() == h {
+				approvers = append(approvers, ch)
+			}
+			bound = append(bound, v)
+		}
+	}
+	sort.Slice(approvers, func(i, j int) bool { return approvers[i].String() < approvers[j].String() })
+	return approvers
+}
+
+// Verify checks that all tips are stored and that every stored site only validates known sites
+func (t *Tangle) Verify() error {
+	for _, h := range t.tipHashes() {
+		if t.GetSite(h) == nil {
+			return errors.New("Tip " + h.String() + " is missing from the store")
+		}
+	}
+	for _, h := range t.Hashes() {
+		s := t.GetSite(h)
+		if s == nil {
+			return errors.New("Site " + h.String() + " could not be loaded")
+		}
+		for _, v := range s.Validates {
+			if t.GetSite(v.Hash()) == nil {
+				return errors.New("Site " + h.String() + " validates unknown site " + v.Hash().String())
+			}
+		}
+	}
+	return nil
+}
+
+// TrainCompression trains the payload compression dictionary on up to max posts
+func (t *Tangle) TrainCompression(max int) error {
+	samples := [][]byte{}
+	for _, h := range t.Hashes() {
+		if len(samples) >= max {
+			break
+		}
+		s := t.GetSite(h)
+		if s == nil || s.Type != "post" {
+			continue
+		}
+		d, err := t.data.Raw(s.Content)
+		if err != nil {
+			return err
+		}
+		samples = append(samples, d)
+	}
+	return t.data.Train(samples)
+}
+
+// Compressed reports whether payloads are compressed using a trained dictionary
+func (t *Tangle) Compressed() bool {
+	return t.data.Trained()
+}
+
+// OnAdd registers a function which gets called after a site has been added to the tangle
+func (t *Tangle) OnAdd(f func(*Object)) {
+	t.listeners = append(t.listeners, f)
+}
+
 // Hashes returns all stored hashes
 func (t *Tangle) Hashes() []hash.Hash {
 	return t.store.Hashes()
@@ -235,9 +531,18 @@ func (t *Tangle) Hashes() []hash.Hash {
 
 // RecommendTips returns tips to be used
 func (t *Tangle) RecommendTips() []*site.Site {
+	return t.SelectTips(MaxRecommendations)
+}
+
+// SelectTips returns at most n tips to be validated by a new site.
+// If there are not enough tips to reach MinimumValidations, random sites are added
+func (t *Tangle) SelectTips(n int) []*site.Site {
 	recs := t.Tips()
+	if len(recs) > n {
+		return recs[:n]
+	}
 	if len(recs) > MinimumValidations {
-		return recs[:MaxRecommendations]
+		return recs
 	}
 	blst := make(map[hash.Hash]bool)
 	hashes := t.Hashes()
@@ -250,11 +555,11 @@ func (t *Tangle) RecommendTips() []*site.Site {
 			continue
 		}
 		blst[rndhash] = true
-		s := t.Get(rndhash)
+		s := t.GetSite(rndhash)
 		if s == nil {
 			continue
 		}
-		recs = append(recs, s.Site)
+		recs = append(recs, s)
 	}
 	return recs
 }
@@ -265,9 +570,30 @@ func (t *Tangle) Inject(s *Object, tip bool) error {
 	if err != nil {
 		return err
 	}
+	if t.belowCheckpoint(s.Site) {
+		return ErrBelowCheckpoint
+	}
 	return t.addSite(s, tip)
 }
 
+// InjectHeader adds a site whose payload was removed by the peer it was received from.
+// The payload stays missing until it is fetched from another peer, listeners are not called since there is nothing to index
+func (t *Tangle) InjectHeader(s *site.Site, tip bool) error {
+	if err := t.verifySite(s); err != nil {
+		return err
+	}
+	if t.belowCheckpoint(s) {
+		return ErrBelowCheckpoint
+	}
+	replaced := t.setTips(s, tip)
+	if err := t.store.Add(s); err != nil {
+		t.resetTips(s, replaced)
+		return err
+	}
+	t.storeTips(s, tip)
+	return nil
+}
+
 // Search performs a full text search for posts on the tangle
 func (t *Tangle) Search(s string) []*Object {
 	q := strings.ToLower(s)
@@ -304,32 +630,193 @@ func (t *Tangle) Search(s string) []*Object {
 	return results
 }
 
+// Required returns the weight and validations a new site of the type needs.
+// Zero values of configured requirements fall back to the minimum weight of the tangle and MinimumValidations
+func (t *Tangle) Required(typ string) Requirement {
+	r := t.required[typ]
+	if r.Weight == 0 {
+		r.Weight = t.minWeight
+	}
+	if r.Validations == 0 {
+		r.Validations = MinimumValidations
+	}
+	return r
+}
+
+// PoW returns the proof of work algorithm of the network
+func (t *Tangle) PoW() pow.Algorithm {
+	return t.pow
+}
+
+// Work returns the weight of the site under the proof of work algorithm of the network.
+// Proofs of other algorithms than the default are expensive to compute, so the weights of stored sites are cached.
+// Sites which were not added are never cached, so unknown sites can not flush the cache
+func (t *Tangle) Work(s *site.Site) int {
+	if t.pow == pow.Default {
+		return s.Hash().Weight()
+	}
+	h := s.Hash()
+	if w, ok := t.work.get(h); ok {
+		return w
+	}
+	w := pow.Weight(t.pow, s)
+	if t.store.Get(h) != nil {
+		t.work.put(h, w)
+	}
+	return w
+}
+
+// CanonicalWork returns the weight of a site whose validated sites are not known yet from its canonical bytes, see site.CanonicalOf.
+// Unlike Work it is never cached
+func (t *Tangle) CanonicalWork(canonical []byte) int {
+	return t.pow.Proof(canonical).Weight()
+}
+
+// workOf returns the weight of a stored site
+func (t *Tangle) workOf(h hash.Hash) int {
+	if t.pow == pow.Default {
+		return h.Weight()
+	}
+	s := t.GetSite(h)
+	if s == nil {
+		return 0
+	}
+	return t.Work(s)
+}
+
 func (t *Tangle) verifySite(s *site.Site) error {
-	if s.Hash().Weight() < MinimumWeight {
-		return ErrWeightTooLow
+	r := t.Required(s.Type)
+	if w := t.Work(s); w < r.Weight {
+		return &WeightError{Type: s.Type, Weight: w, Required: r.Weight}
 	}
-	if len(s.Validates) < MinimumValidations {
+	if len(s.Validates) < r.Validations {
 		return ErrTooFewValidations
 	}
+	return t.verifyTimes(s)
+}
+
+// Fresh checks that a new site was mined recently, so sites can not be mined in advance and released later.
+// Together with verifyTimes this binds the site to tips which were at most twice the maximum tip age old when it arrived.
+// Sites validating tips from before timestamps were introduced are only bound by their own timestamp
+func (t *Tangle) Fresh(s *site.Site, now time.Time) error {
+	if t.maxTipAge <= 0 {
+		return nil
+	}
+	if s.Timestamp == 0 {
+		return ErrNoTimestamp
+	}
+	ts := time.Unix(s.Timestamp, 0)
+	if now.Sub(ts) > t.maxTipAge {
+		return ErrStale
+	}
+	if ts.Sub(now) > t.maxSkew {
+		return ErrClockSkew
+	}
+	return nil
+}
+
+// verifyTimes checks that the timestamped sites validated by a timestamped site were at most the maximum tip age old when it was mined.
+// It only depends on the sites, so sites synchronized long after they were mined pass it as well
+func (t *Tangle) verifyTimes(s *site.Site) error {
+	if t.maxTipAge <= 0 || s.Timestamp == 0 {
+		return nil
+	}
+	ts := time.Unix(s.Timestamp, 0)
+	for _, v := range s.Validates {
+		if v.Timestamp == 0 {
+			continue
+		}
+		vt := time.Unix(v.Timestamp, 0)
+		if ts.Sub(vt) > t.maxTipAge {
+			return ErrStale
+		}
+		if vt.Sub(ts) > t.maxSkew {
+			return ErrClockSkew
+		}
+	}
 	return nil
 }
 
+// tipHashes returns the hashes of the current tips
+func (t *Tangle) tipHashes() []hash.Hash {
+	t.tipLock.RLock()
+	defer t.tipLock.RUnlock()
+	hs := make([]hash.Hash, 0, len(t.tips))
+	for h := range t.tips {
+		hs = append(hs, h)
+	}
+	return hs
+}
+
 func (t *Tangle) addSite(s *Object, tip bool) error {
-	for _, vs := range s.Site.Validates {
-		delete(t.tips, vs.Hash())
+	seq, err := t.begin(s, tip)
+	if err != nil {
+		return err
+	}
+	// The addition stays in the journal if it could not be applied, so it is applied again by Recover after a restart
+	if err := t.apply(s, tip); err != nil {
+		return err
+	}
+	return t.commit(seq)
+}
+
+// setTips replaces the sites validated by s with s as tip in memory. It returns the replaced tips, see resetTips
+func (t *Tangle) setTips(s *site.Site, tip bool) map[hash.Hash]time.Time {
+	t.tipLock.Lock()
+	defer t.tipLock.Unlock()
+	replaced := make(map[hash.Hash]time.Time)
+	for _, vs := range s.Validates {
+		if at, ok := t.tips[vs.Hash()]; ok {
+			replaced[vs.Hash()] = at
+			delete(t.tips, vs.Hash())
+		}
+	}
+	if tip {
+		if at, ok := t.tips[s.Hash()]; ok {
+			replaced[s.Hash()] = at
+		}
+		t.tips[s.Hash()] = time.Now()
+	}
+	return replaced
+}
+
+// resetTips restores the tips replaced by setTips when s could not be stored
+func (t *Tangle) resetTips(s *site.Site, replaced map[hash.Hash]time.Time) {
+	t.tipLock.Lock()
+	defer t.tipLock.Unlock()
+	delete(t.tips, s.Hash())
+	for h, at := range replaced {
+		t.tips[h] = at
 	}
+}
+
+// storeTips persists the tips set by setTips once s is stored
+func (t *Tangle) storeTips(s *site.Site, tip bool) {
 	if tip {
-		t.tips[s.Site.Hash()] = true
-		t.store.SetTips(s.Site.Hash(), s.Site.Validates)
+		t.store.SetTips(s.Hash(), s.Validates)
 	}
+}
 
-	err := t.store.Add(s.Site)
-	if err != nil {
+// apply updates the tips, the site store and the payload store and calls the listeners.
+// The tips are restored if a store fails
+func (t *Tangle) apply(s *Object, tip bool) error {
+	replaced := t.setTips(s.Site, tip)
+	if err := t.store.Add(s.Site); err != nil {
+		t.resetTips(s.Site, replaced)
 		return err
 	}
-	err = t.data.Put(s.Data)
-	if err != nil {
+	if err := t.data.Put(s.Data); err != nil {
+		t.resetTips(s.Site, replaced)
 		return err
 	}
+	t.storeTips(s.Site, tip)
+	if t.isCheckpoint(s.Site.Hash()) {
+		t.finalLock.Lock()
+		t.final = nil
+		t.finalLock.Unlock()
+	}
+	for _, l := range t.listeners {
+		l(s)
+	}
 	return nil
 }
@@ -1,11 +1,20 @@
 package tangle
 
 import (
+	"bytes"
+	"errors"
+	"io/ioutil"
 	"os"
 	"path"
+	"sort"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/journal"
+	"github.com/u-speak/core/pow"
 	"github.com/u-speak/core/tangle/hash"
 	"github.com/u-speak/core/tangle/site"
 	"github.com/u-speak/core/tangle/store"
@@ -28,6 +37,14 @@ func TestInit(t *testing.T) {
 	err := tngl.Init(Options{Store: ms()})
 	assert.NoError(t, err)
 	assert.Equal(t, 2, tngl.Size())
+
+	s := ms()
+	gen := []*site.Site{{Content: hash.Hash{1}, Type: "genesis"}, {Content: hash.Hash{2}, Type: "genesis"}}
+	assert.NoError(t, tngl.Init(Options{Store: s, Genesis: gen}))
+	assert.Len(t, tngl.Tips(), 2)
+	assert.NotNil(t, tngl.GetSite(gen[0].Hash()))
+	assert.NoError(t, tngl.Init(Options{Store: s, Genesis: gen}))
+	assert.Equal(t, ErrOtherNetwork, tngl.Init(Options{Store: s}))
 }
 
 func TestTips(t *testing.T) {
@@ -37,6 +54,167 @@ func TestTips(t *testing.T) {
 	assert.Len(t, tngl.Tips(), 2)
 }
 
+func TestConcurrentAdd(t *testing.T) {
+	tngl, err := New(Options{Store: ms(), DataPath: path.Join(os.TempDir(), "testconcurrentadd")})
+	assert.NoError(t, err)
+	tips := tngl.Tips()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			d := dd(strconv.Itoa(i))
+			h, _ := d.Hash()
+			o := &Object{Site: &site.Site{Content: h, Validates: tips, Type: "dummy"}, Data: d}
+			o.Site.Mine(1)
+			assert.NoError(t, tngl.Inject(o, true))
+		}(i)
+		go func() {
+			defer wg.Done()
+			tngl.Tips()
+			tngl.HasTip(tips[0].Hash())
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, tngl.Tips(), 8)
+}
+
+func TestRecover(t *testing.T) {
+	dir := path.Join(os.TempDir(), "testrecover")
+	assert.NoError(t, os.MkdirAll(dir, 0700))
+	defer os.RemoveAll(dir)
+	j, err := journal.Open(path.Join(dir, "journal.wal"), true)
+	assert.NoError(t, err)
+	tngl, err := New(Options{Store: ms(), DataPath: path.Join(dir, "data.db"), Journal: j})
+	assert.NoError(t, err)
+	defer tngl.Close()
+	tips := tngl.Tips()
+
+	added := 0
+	tngl.OnAdd(func(*Object) { added++ })
+	d := dd("journaled")
+	h, _ := d.Hash()
+	o := &Object{Site: &site.Site{Content: h, Validates: tips, Type: "dummy"}, Data: d}
+	o.Site.Mine(1)
+	assert.NoError(t, tngl.Add(o))
+	assert.Equal(t, 1, added)
+	assert.Empty(t, j.Pending())
+
+	// Simulates a crash after the addition was journaled but before the stores were written
+	d = dd("crashed")
+	h, _ = d.Hash()
+	c := &Object{Site: &site.Site{Content: h, Validates: []*site.Site{o.Site}, Type: "dummy"}, Data: d}
+	c.Site.Mine(1)
+	_, err = tngl.begin(c, true)
+	assert.NoError(t, err)
+	assert.Nil(t, tngl.Get(c.Site.Hash()))
+
+	n, err := tngl.Recover()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 2, added)
+	assert.NotNil(t, tngl.Get(c.Site.Hash()))
+	assert.True(t, tngl.HasTip(c.Site.Hash()))
+	assert.Empty(t, j.Pending())
+}
+
+// flakyData fails to serialize after it was journaled, like a payload store running out of space
+type flakyData struct {
+	dummydata
+	calls int
+}
+
+func (f *flakyData) Serialize() ([]byte, error) {
+	f.calls++
+	if f.calls > 1 {
+		return nil, errors.New("no space left on device")
+	}
+	return f.dummydata.Serialize()
+}
+
+func TestRecoverFailedPut(t *testing.T) {
+	dir := path.Join(os.TempDir(), "testrecoverfailedput")
+	assert.NoError(t, os.MkdirAll(dir, 0700))
+	defer os.RemoveAll(dir)
+	st := ms()
+	j, err := journal.Open(path.Join(dir, "journal.wal"), true)
+	assert.NoError(t, err)
+	tngl, err := New(Options{Store: st, DataPath: path.Join(dir, "data.db"), Journal: j})
+	assert.NoError(t, err)
+	tips := tngl.Tips()
+
+	d := &flakyData{dummydata: dummydata{content: "failed"}}
+	h, _ := d.Hash()
+	o := &Object{Site: &site.Site{Content: h, Validates: tips, Type: "dummy"}, Data: d}
+	o.Site.Mine(1)
+	assert.Error(t, tngl.Add(o))
+	assert.False(t, tngl.HasTip(o.Site.Hash()))
+	for _, s := range tips {
+		assert.True(t, tngl.HasTip(s.Hash()))
+	}
+	assert.Len(t, j.Pending(), 1)
+	tngl.Close()
+
+	j, err = journal.Open(path.Join(dir, "journal.wal"), true)
+	assert.NoError(t, err)
+	tngl, err = New(Options{Store: st, DataPath: path.Join(dir, "data.db"), Journal: j})
+	assert.NoError(t, err)
+	defer tngl.Close()
+	n, err := tngl.Recover()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.NotNil(t, tngl.Get(o.Site.Hash()))
+	assert.True(t, tngl.HasTip(o.Site.Hash()))
+	assert.Empty(t, j.Pending())
+	_, err = os.Stat(j.QuarantinePath())
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRecoverQuarantine(t *testing.T) {
+	dir := path.Join(os.TempDir(), "testrecoverquarantine")
+	assert.NoError(t, os.MkdirAll(dir, 0700))
+	defer os.RemoveAll(dir)
+	j, err := journal.Open(path.Join(dir, "journal.wal"), true)
+	assert.NoError(t, err)
+	key := bytes.Repeat([]byte{7}, 32)
+	tngl, err := New(Options{Store: ms(), DataPath: path.Join(dir, "data.db"), EncryptionKey: key, Journal: j})
+	assert.NoError(t, err)
+	defer tngl.Close()
+
+	_, err = j.Append([]byte("garbage"))
+	assert.NoError(t, err)
+	d := dd("confidential")
+	h, _ := d.Hash()
+	c := &Object{Site: &site.Site{Content: h, Validates: tngl.Tips(), Type: "dummy"}, Data: d}
+	c.Site.Mine(1)
+	_, err = tngl.begin(c, true)
+	assert.NoError(t, err)
+	raw, err := ioutil.ReadFile(path.Join(dir, "journal.wal"))
+	assert.NoError(t, err)
+	assert.False(t, bytes.Contains(raw, []byte("confidential")))
+
+	n, err := tngl.Recover()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.NotNil(t, tngl.Get(c.Site.Hash()))
+	assert.Empty(t, j.Pending())
+	q, err := ioutil.ReadFile(j.QuarantinePath())
+	assert.NoError(t, err)
+	assert.True(t, bytes.Contains(q, []byte("garbage")))
+
+	// Entries sealed with another key are quarantined as well
+	other, err := newSeal(bytes.Repeat([]byte{8}, 32))
+	assert.NoError(t, err)
+	tngl.seal, other = other, tngl.seal
+	_, err = tngl.begin(c, true)
+	assert.NoError(t, err)
+	tngl.seal = other
+	n, err = tngl.Recover()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.Empty(t, j.Pending())
+}
+
 func TestGet(t *testing.T) {
 	tngl, err := New(Options{Store: ms(), DataPath: path.Join(os.TempDir(), "testget")})
 	assert.NoError(t, err)
@@ -51,7 +229,7 @@ func TestAdd(t *testing.T) {
 	assert.NoError(t, err)
 	tips := tngl.Tips()
 	err = tngl.Add(&Object{Site: &site.Site{Content: hash.Hash{1, 3, 3, 7}, Nonce: 0}, Data: dd("1337")})
-	assert.Equal(t, ErrWeightTooLow, err)
+	assert.Equal(t, &WeightError{Weight: 0, Required: 1}, err)
 	st := &Object{Site: &site.Site{Content: hash.Hash{1, 3, 3, 7}}, Data: dd("1337")}
 	st.Site.Mine(1)
 	err = tngl.Add(st)
@@ -62,12 +240,95 @@ func TestAdd(t *testing.T) {
 	sub.Site.Mine(1)
 	err = tngl.Add(sub)
 	assert.NoError(t, err)
-	assert.False(t, tngl.tips[tips[0].Hash()])
-	assert.False(t, tngl.tips[tips[1].Hash()])
-	assert.True(t, tngl.tips[sub.Site.Hash()])
+	assert.False(t, tngl.HasTip(tips[0].Hash()))
+	assert.False(t, tngl.HasTip(tips[1].Hash()))
+	assert.True(t, tngl.HasTip(sub.Site.Hash()))
+	since, ok := tngl.TipSince(sub.Site.Hash())
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now(), since, time.Minute)
 	assert.Equal(t, sub, tngl.Get(sub.Site.Hash()))
 }
 
+func TestRequirements(t *testing.T) {
+	tngl, err := New(Options{Store: ms(), DataPath: path.Join(os.TempDir(), "testrequirements"), Requirements: map[string]Requirement{"dummy": {Weight: 2}}})
+	assert.NoError(t, err)
+	assert.Equal(t, Requirement{Weight: 2, Validations: MinimumValidations}, tngl.Required("dummy"))
+	assert.Equal(t, Requirement{Weight: MinimumWeight, Validations: MinimumValidations}, tngl.Required("post"))
+
+	tips := tngl.Tips()
+	h, _ := dd("heavy").Hash()
+	s := &Object{Site: &site.Site{Content: h, Validates: []*site.Site{tips[0], tips[1]}, Type: "dummy"}, Data: dd("heavy")}
+	for s.Site.Hash().Weight() != 1 {
+		s.Site.Nonce++
+	}
+	assert.Equal(t, &WeightError{Type: "dummy", Weight: 1, Required: 2}, tngl.Add(s))
+	s.Site.Mine(2)
+	assert.NoError(t, tngl.Add(s))
+
+	tngl, err = New(Options{Store: ms(), DataPath: path.Join(os.TempDir(), "testminweight"), MinWeight: 3, Requirements: map[string]Requirement{"dummy": {Weight: 1}}})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, tngl.Required("post").Weight)
+	assert.Equal(t, 1, tngl.Required("dummy").Weight)
+}
+
+func TestTipAge(t *testing.T) {
+	tngl, err := New(Options{Store: ms(), DataPath: path.Join(os.TempDir(), "testtipage"), MaxTipAge: 10 * time.Minute, MaxClockSkew: time.Minute})
+	assert.NoError(t, err)
+	tips := tngl.Tips()
+	mined := func(content string, ts time.Time, vs ...*site.Site) *Object {
+		h, _ := dd(content).Hash()
+		o := &Object{Site: &site.Site{Content: h, Validates: vs, Type: "dummy", Timestamp: ts.Unix()}, Data: dd(content)}
+		if ts.IsZero() {
+			o.Site.Timestamp = 0
+		}
+		o.Site.Mine(1)
+		return o
+	}
+	now := time.Now()
+	assert.Equal(t, ErrNoTimestamp, tngl.Add(mined("legacy", time.Time{}, tips...)))
+	assert.Equal(t, ErrStale, tngl.Add(mined("stockpiled", now.Add(-time.Hour), tips...)))
+	assert.Equal(t, ErrClockSkew, tngl.Add(mined("future", now.Add(time.Hour), tips...)))
+	a := mined("a", now.Add(-5*time.Minute), tips...)
+	assert.NoError(t, tngl.Add(a))
+
+	// Synchronised sites are only checked against the sites they validate
+	old := mined("old", now.Add(-time.Hour), tips...)
+	assert.NoError(t, tngl.Inject(old, false))
+	assert.Equal(t, ErrStale, tngl.Inject(mined("late", now, old.Site, a.Site), true))
+	assert.Equal(t, ErrClockSkew, tngl.Inject(mined("early", now.Add(-2*time.Hour), old.Site, tips[0]), true))
+	assert.NoError(t, tngl.Add(mined("b", now, a.Site, tips[0])))
+}
+
+func TestPoW(t *testing.T) {
+	a := pow.Argon2{Time: 1, Memory: 64, Threads: 1}
+	tngl, err := New(Options{Store: ms(), DataPath: path.Join(os.TempDir(), "testpow"), PoW: a})
+	assert.NoError(t, err)
+	assert.Equal(t, pow.Argon2id, tngl.PoW().Name())
+
+	tips := tngl.Tips()
+	h, _ := dd("memoryhard").Hash()
+	s := &Object{Site: &site.Site{Content: h, Validates: []*site.Site{tips[0], tips[1]}, Type: "dummy"}, Data: dd("memoryhard")}
+	for s.Site.Hash().Weight() < 1 || pow.Weight(a, s.Site) >= 1 {
+		s.Site.Nonce++
+	}
+	assert.Equal(t, &WeightError{Type: "dummy", Weight: 0, Required: 1}, tngl.Add(s))
+	pow.Mine(a, s.Site, 1)
+	assert.Equal(t, pow.Weight(a, s.Site), tngl.Work(s.Site))
+	assert.Equal(t, 0, tngl.work.len())
+	assert.NoError(t, tngl.Add(s))
+	assert.Equal(t, pow.Weight(a, s.Site), tngl.Work(s.Site))
+	assert.Equal(t, 1, tngl.work.len())
+
+	argon, _ := pow.Get(pow.Argon2id)
+	b, err := s.Bundle(argon)
+	assert.NoError(t, err)
+	assert.Equal(t, pow.Argon2id, b.PoW)
+	assert.Equal(t, pow.Weight(argon, s.Site), b.Weight)
+	assert.NoError(t, b.Verify())
+	b.Weight++
+	assert.Error(t, b.Verify())
+}
+
 func TestRestore(t *testing.T) {
 	dbpath := path.Join(os.TempDir(), "testRestore.db")
 	defer os.Remove(dbpath)
@@ -127,6 +388,27 @@ func TestWeight(t *testing.T) {
 	assert.EqualValues(t, s4.Site.Hash().Weight()+s3.Site.Hash().Weight(), tngl.Weight(s3.Site))
 	assert.EqualValues(t, s4.Site.Hash().Weight()+s3.Site.Hash().Weight()+s2.Site.Hash().Weight(), tngl.Weight(s2.Site))
 	assert.EqualValues(t, s4.Site.Hash().Weight()+s3.Site.Hash().Weight()+s2.Site.Hash().Weight()+s1.Site.Hash().Weight(), tngl.Weight(s1.Site))
+	ss := []*site.Site{gen1, gen2, s1.Site, s2.Site, s3.Site, s4.Site}
+	ws := tngl.Weights(ss)
+	assert.Len(t, ws, 6)
+	for _, s := range ss {
+		assert.Equal(t, tngl.Weight(s), ws[s.Hash()])
+	}
+	assert.Equal(t, 0, tngl.Depth(s4.Site))
+	assert.Equal(t, 1, tngl.Depth(s3.Site))
+	assert.Equal(t, 2, tngl.Depth(s2.Site))
+	assert.Equal(t, 3, tngl.Depth(s1.Site))
+	assert.Equal(t, 4, tngl.Depth(gen2))
+	approvers := []hash.Hash{s3.Site.Hash(), s4.Site.Hash()}
+	sort.Slice(approvers, func(i, j int) bool { return approvers[i].String() < approvers[j].String() })
+	assert.Equal(t, approvers, tngl.Approvers(s2.Site.Hash()))
+	assert.Empty(t, tngl.Approvers(s4.Site.Hash()))
+	st := tngl.Stats()
+	assert.Equal(t, 6, st.Size)
+	assert.Equal(t, 1, st.Tips)
+	assert.Equal(t, tngl.Depth(gen1), st.MaxDepth)
+	assert.Equal(t, float64(0+1+2+3+4+4)/6, st.AverageDepth)
+	assert.True(t, st.AverageWeight >= 0)
 }
 
 func BenchmarkWeight(b *testing.B) {
@@ -152,3 +434,139 @@ func BenchmarkWeight(b *testing.B) {
 		tngl.Weight(s1)
 	}
 }
+
+func TestBundle(t *testing.T) {
+	tngl, err := New(Options{Store: ms(), DataPath: path.Join(os.TempDir(), "testbundle")})
+	assert.NoError(t, err)
+	tips := tngl.Tips()
+	h, _ := dd("1337").Hash()
+	o := &Object{Site: &site.Site{Content: h, Validates: tips, Type: "dummy"}, Data: dd("1337")}
+	o.Site.Mine(1)
+	b, err := o.Bundle(tngl.PoW())
+	assert.NoError(t, err)
+	assert.NoError(t, b.Verify())
+	assert.Len(t, b.Validates, 2)
+	b.Canonical = append(b.Canonical, 'x')
+	assert.Error(t, b.Verify())
+}
+
+func TestCheckpoints(t *testing.T) {
+	dbpath := path.Join(os.TempDir(), "testcheckpoints.db")
+	defer os.Remove(dbpath)
+	tngl, err := New(Options{Store: ms(), DataPath: dbpath})
+	assert.NoError(t, err)
+	tips := tngl.Tips()
+	gen1, gen2 := tips[0], tips[1]
+	obj := func(c string, vs ...*site.Site) *Object {
+		d := dd(c)
+		h, _ := d.Hash()
+		o := &Object{Site: &site.Site{Content: h, Type: "dummy", Validates: vs}, Data: d}
+		o.Site.Mine(1)
+		return o
+	}
+	s1 := obj("s1", gen1, gen2)
+	s2 := obj("s2", s1.Site, gen2)
+	assert.NoError(t, tngl.Add(s1))
+	tngl.SetCheckpoints([]hash.Hash{s2.Site.Hash()})
+	assert.Equal(t, []hash.Hash{s2.Site.Hash()}, tngl.MissingCheckpoints())
+	assert.NoError(t, tngl.Inject(obj("before", gen1, gen2), false))
+
+	assert.NoError(t, tngl.Add(s2))
+	assert.Empty(t, tngl.MissingCheckpoints())
+	assert.Equal(t, ErrBelowCheckpoint, tngl.Inject(obj("old", gen1, s1.Site), false))
+	assert.NoError(t, tngl.Inject(obj("mixed", s2.Site, s1.Site), false))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tngl.SetCheckpoints([]hash.Hash{s2.Site.Hash()})
+			assert.Equal(t, ErrBelowCheckpoint, tngl.Inject(obj("concurrent "+strconv.Itoa(i), gen1, s1.Site), false))
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestBury(t *testing.T) {
+	dbpath := path.Join(os.TempDir(), "testbury.db")
+	defer os.Remove(dbpath)
+	tngl, err := New(Options{Store: ms(), DataPath: dbpath})
+	assert.NoError(t, err)
+	d := dd("removed")
+	h, _ := d.Hash()
+	o := &Object{Site: &site.Site{Content: h, Type: "dummy", Validates: tngl.Tips()}, Data: d}
+	o.Site.Mine(1)
+	assert.NoError(t, tngl.Add(o))
+	assert.NotNil(t, tngl.Get(o.Site.Hash()))
+	data, err := tngl.GetData("dummy", h)
+	assert.NoError(t, err)
+	assert.Equal(t, d, data)
+
+	by := hash.New([]byte("tombstone"))
+	assert.NoError(t, tngl.Bury(o.Site.Hash(), by))
+	assert.Nil(t, tngl.Get(o.Site.Hash()))
+	assert.NotNil(t, tngl.GetSite(o.Site.Hash()))
+	b, ok := tngl.Buried(o.Site.Hash())
+	assert.True(t, ok)
+	assert.Equal(t, by, b)
+	_, err = tngl.GetData("dummy", h)
+	assert.Equal(t, ErrBuried, err)
+	assert.Error(t, tngl.Bury(hash.Hash{}, by))
+	assert.Equal(t, []*site.Site{tngl.GetSite(o.Site.Hash())}, tngl.Tips())
+	assert.Len(t, tngl.SelectTips(MaxRecommendations), MinimumValidations)
+
+	header := &site.Site{Content: hash.New([]byte("removed elsewhere")), Type: "dummy", Validates: tngl.SelectTips(MaxRecommendations)}
+	header.Mine(1)
+	assert.NoError(t, tngl.InjectHeader(header, true))
+	assert.NotNil(t, tngl.GetSite(header.Hash()))
+	assert.True(t, tngl.HasTip(header.Hash()))
+	assert.False(t, tngl.HasTip(o.Site.Hash()))
+}
+
+func TestRepair(t *testing.T) {
+	dbpath := path.Join(os.TempDir(), "testrepair.db")
+	defer os.Remove(dbpath)
+	s := ms()
+	tngl, err := New(Options{Store: s, DataPath: dbpath})
+	assert.NoError(t, err)
+	assert.Empty(t, tngl.Check())
+
+	a := &Object{Site: &site.Site{Type: "dummy", Validates: tngl.Tips()}, Data: dd("a")}
+	a.Site.Content, _ = a.Data.Hash()
+	a.Site.Mine(1)
+	assert.NoError(t, tngl.Add(a))
+	b := &Object{Site: &site.Site{Type: "dummy", Validates: []*site.Site{a.Site, a.Site.Validates[0]}}, Data: dd("b")}
+	b.Site.Content, _ = b.Data.Hash()
+	b.Site.Mine(1)
+	assert.NoError(t, tngl.Add(b))
+
+	dangling := hash.New([]byte("dangling"))
+	s.SetTips(dangling, nil)
+	assert.NoError(t, s.Delete(a.Site.Hash()))
+	c := &site.Site{Type: "dummy", Content: hash.New([]byte("lost")), Validates: []*site.Site{b.Site}}
+	assert.NoError(t, s.Add(c))
+
+	ds := tngl.Check()
+	assert.ElementsMatch(t, []Damage{
+		{Kind: DanglingTip, Site: dangling, Missing: dangling},
+		{Kind: MissingParent, Site: b.Site.Hash(), Missing: a.Site.Hash()},
+		{Kind: MissingPayload, Site: c.Hash(), Missing: c.Content, Type: "dummy"},
+	}, ds)
+
+	assert.NoError(t, tngl.Truncate(dangling))
+	assert.NoError(t, tngl.RestoreParent(b.Site.Hash(), a.Site.Hash()))
+	assert.Equal(t, ErrNotEmbedded, tngl.RestoreParent(b.Site.Hash(), c.Hash()))
+	assert.NoError(t, tngl.Bury(c.Hash(), hash.Hash{}))
+	assert.Empty(t, tngl.Check())
+	assert.Equal(t, a.Site, tngl.GetSite(a.Site.Hash()))
+
+	assert.NoError(t, s.Delete(a.Site.Hash()))
+	assert.NoError(t, tngl.Restore(a.Site.Hash()))
+	assert.Equal(t, ErrNotEmbedded, tngl.Restore(dangling))
+
+	assert.False(t, tngl.HasTip(c.Hash()))
+	tngl.RebuildTips()
+	assert.True(t, tngl.HasTip(c.Hash()))
+	assert.Contains(t, s.GetTips(), c.Hash())
+}
@@ -0,0 +1,69 @@
+package tangle
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/u-speak/core/tangle/hash"
+)
+
+// MaxCachedWork is the amount of site weights kept by the work cache
+const MaxCachedWork = 1 << 16
+
+// workCache keeps the weights of the most recently used stored sites
+type workCache struct {
+	max     int
+	entries map[hash.Hash]*list.Element
+	order   *list.List
+	lock    sync.Mutex
+}
+
+type workEntry struct {
+	hash   hash.Hash
+	weight int
+}
+
+func newWorkCache(max int) *workCache {
+	return &workCache{max: max, entries: make(map[hash.Hash]*list.Element), order: list.New()}
+}
+
+func (c *workCache) get(h hash.Hash) (int, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	e, ok := c.entries[h]
+	if !ok {
+		return 0, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*workEntry).weight, true
+}
+
+func (c *workCache) put(h hash.Hash, w int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if e, ok := c.entries[h]; ok {
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[h] = c.order.PushFront(&workEntry{hash: h, weight: w})
+	for c.order.Len() > c.max {
+		e := c.order.Back()
+		c.order.Remove(e)
+		delete(c.entries, e.Value.(*workEntry).hash)
+	}
+}
+
+func (c *workCache) remove(h hash.Hash) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if e, ok := c.entries[h]; ok {
+		c.order.Remove(e)
+		delete(c.entries, h)
+	}
+}
+
+func (c *workCache) len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.order.Len()
+}
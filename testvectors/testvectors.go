@@ -0,0 +1,142 @@
+// Package testvectors contains canonical sites, posts and images with their expected hashes and serializations.
+// Alternative implementations of the protocol can validate themselves against vectors.json
+package testvectors
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/u-speak/core/img"
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+)
+
+//go:embed vectors.json
+var vectors []byte
+
+// Vector is a single input together with the output every implementation has to produce
+type Vector struct {
+	Name     string     `json:"name"`
+	Site     *SiteInput `json:"site,omitempty"`
+	Post     *post.Post `json:"post,omitempty"`
+	Image    []byte     `json:"image,omitempty"`
+	Expected Expected   `json:"expected"`
+}
+
+// SiteInput describes a site. ContentOf names an earlier vector whose hash is the content,
+// Validates the earlier site vectors the site validates
+type SiteInput struct {
+	Content   string   `json:"content,omitempty"`
+	ContentOf string   `json:"content_of,omitempty"`
+	Nonce     uint64   `json:"nonce"`
+	Type      string   `json:"type"`
+	Validates []string `json:"validates"`
+}
+
+// Expected are the canonical bytes, the resulting hash and the serialized form of a vector
+type Expected struct {
+	Canonical  string `json:"canonical"`
+	Hash       string `json:"hash"`
+	Serialized []byte `json:"serialized"`
+	Weight     int    `json:"weight,omitempty"`
+}
+
+// Failure describes a vector for which this implementation produced a different result
+type Failure struct {
+	Name     string   `json:"name"`
+	Expected Expected `json:"expected"`
+	Actual   Expected `json:"actual"`
+}
+
+// Load returns all vectors
+func Load() ([]Vector, error) {
+	vs := []Vector{}
+	return vs, json.Unmarshal(vectors, &vs)
+}
+
+// Compute calculates the results of all vectors with this implementation
+func Compute(vs []Vector) ([]Expected, error) {
+	sites := map[string]*site.Site{}
+	hashes := map[string]hash.Hash{}
+	results := []Expected{}
+	for _, v := range vs {
+		var e Expected
+		var err error
+		switch {
+		case v.Site != nil:
+			var s *site.Site
+			s, err = toSite(v.Site, sites, hashes)
+			if err == nil {
+				sites[v.Name] = s
+				e = Expected{Canonical: string(s.Canonical()), Serialized: s.Serialize(), Weight: s.Hash().Weight()}
+				hashes[v.Name] = s.Hash()
+			}
+		case v.Post != nil:
+			p := *v.Post
+			if err = p.ReInit(); err == nil {
+				e.Canonical = string(p.Canonical())
+				e.Serialized, err = p.Serialize()
+				hashes[v.Name], _ = p.Hash()
+			}
+		default:
+			i := &img.Image{Raw: v.Image}
+			e = Expected{Canonical: string(i.Canonical()), Serialized: i.Raw}
+			hashes[v.Name], _ = i.Hash()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Vector %s: %s", v.Name, err)
+		}
+		e.Hash = hashes[v.Name].String()
+		results = append(results, e)
+	}
+	return results, nil
+}
+
+func toSite(in *SiteInput, sites map[string]*site.Site, hashes map[string]hash.Hash) (*site.Site, error) {
+	s := &site.Site{Nonce: in.Nonce, Type: in.Type}
+	if in.ContentOf != "" {
+		h, ok := hashes[in.ContentOf]
+		if !ok {
+			return nil, fmt.Errorf("Unknown vector %s", in.ContentOf)
+		}
+		s.Content = h
+	} else {
+		b, err := base64.URLEncoding.DecodeString(in.Content)
+		if err != nil || len(b) != hash.HashSize {
+			return nil, fmt.Errorf("Invalid content %s", in.Content)
+		}
+		s.Content = hash.FromSlice(b)
+	}
+	for _, n := range in.Validates {
+		v, ok := sites[n]
+		if !ok {
+			return nil, fmt.Errorf("Unknown site vector %s", n)
+		}
+		s.Validates = append(s.Validates, v)
+	}
+	return s, nil
+}
+
+// Verify checks this implementation against all vectors and returns the vectors producing different results
+func Verify() ([]Failure, error) {
+	vs, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	results, err := Compute(vs)
+	if err != nil {
+		return nil, err
+	}
+	fs := []Failure{}
+	for i, v := range vs {
+		r := results[i]
+		if r.Canonical != v.Expected.Canonical || r.Hash != v.Expected.Hash || r.Weight != v.Expected.Weight || !bytes.Equal(r.Serialized, v.Expected.Serialized) {
+			fs = append(fs, Failure{Name: v.Name, Expected: v.Expected, Actual: r})
+		}
+	}
+	return fs, nil
+}
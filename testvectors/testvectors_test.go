@@ -0,0 +1,43 @@
+package testvectors
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "rewrite the expected results in vectors.json")
+
+func TestVerify(t *testing.T) {
+	if *update {
+		vs, err := Load()
+		assert.NoError(t, err)
+		results, err := Compute(vs)
+		assert.NoError(t, err)
+		for i := range vs {
+			vs[i].Expected = results[i]
+		}
+		b, err := json.MarshalIndent(vs, "", "  ")
+		assert.NoError(t, err)
+		assert.NoError(t, ioutil.WriteFile("vectors.json", append(b, '\n'), 0644))
+		return
+	}
+	fs, err := Verify()
+	assert.NoError(t, err)
+	assert.Empty(t, fs)
+}
+
+func TestPostSignature(t *testing.T) {
+	vs, err := Load()
+	assert.NoError(t, err)
+	for _, v := range vs {
+		if v.Post != nil {
+			assert.NoError(t, v.Post.ReInit())
+			_, err := v.Post.Verify()
+			assert.NoError(t, err, v.Name)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package timeindex
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/u-speak/core/follow"
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/subscription"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+// Index keeps the sites of every type ordered by their date
+type Index struct {
+	types map[string][]entry
+	lock  sync.RWMutex
+}
+
+type entry struct {
+	Time int64
+	Hash hash.Hash
+}
+
+// NewIndex returns an empty time index
+func NewIndex() *Index {
+	return &Index{types: make(map[string][]entry)}
+}
+
+// Date returns the unix timestamp the author gave the object. Only signed types carry a date
+func Date(o *tangle.Object) (int64, bool) {
+	switch d := o.Data.(type) {
+	case *post.Post:
+		return d.Timestamp, true
+	case *follow.List:
+		return d.Timestamp, true
+	case *subscription.Subscription:
+		return d.Timestamp, true
+	}
+	return 0, false
+}
+
+// Add indexes the object if it has a date
+func (i *Index) Add(o *tangle.Object) {
+	t, ok := Date(o)
+	if !ok {
+		return
+	}
+	e := entry{Time: t, Hash: o.Site.Hash()}
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	es := i.types[o.Site.Type]
+	n := sort.Search(len(es), func(j int) bool { return !less(es[j], e) })
+	if n < len(es) && es[n] == e {
+		return
+	}
+	es = append(es, entry{})
+	copy(es[n+1:], es[n:])
+	es[n] = e
+	i.types[o.Site.Type] = es
+}
+
+// Indexed reports whether the object is reflected in the index. Only objects with a date are indexed
+func (i *Index) Indexed(o *tangle.Object) bool {
+	t, ok := Date(o)
+	if !ok {
+		return true
+	}
+	e := entry{Time: t, Hash: o.Site.Hash()}
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	es := i.types[o.Site.Type]
+	n := sort.Search(len(es), func(j int) bool { return !less(es[j], e) })
+	return n < len(es) && es[n] == e
+}
+
+// Sync indexes all dated sites stored in the tangle
+func (i *Index) Sync(t *tangle.Tangle) {
+	for _, h := range t.Hashes() {
+		s := t.GetSite(h)
+		if s == nil {
+			continue
+		}
+		switch s.Type {
+		case "post", "follow", "subscription":
+			if o := t.Get(h); o != nil {
+				i.Add(o)
+			}
+		}
+	}
+}
+
+// Range returns the hashes of all sites of the type dated in [from, to), oldest first.
+// A to of zero leaves the range open ended
+func (i *Index) Range(typ string, from, to int64) []hash.Hash {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	es := i.types[typ]
+	start := sort.Search(len(es), func(j int) bool { return es[j].Time >= from })
+	hs := []hash.Hash{}
+	for _, e := range es[start:] {
+		if to != 0 && e.Time >= to {
+			break
+		}
+		hs = append(hs, e.Hash)
+	}
+	return hs
+}
+
+// Adjacent returns the sites of the same type dated directly before and after the object.
+// Missing neighbours are returned as nil
+func (i *Index) Adjacent(o *tangle.Object) (prev, next *hash.Hash) {
+	t, ok := Date(o)
+	if !ok {
+		return nil, nil
+	}
+	e := entry{Time: t, Hash: o.Site.Hash()}
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	es := i.types[o.Site.Type]
+	n := sort.Search(len(es), func(j int) bool { return !less(es[j], e) })
+	if n > 0 {
+		prev = &es[n-1].Hash
+	}
+	if n < len(es) && es[n] == e {
+		n++
+	}
+	if n < len(es) {
+		next = &es[n].Hash
+	}
+	return prev, next
+}
+
+func less(a, b entry) bool {
+	if a.Time != b.Time {
+		return a.Time < b.Time
+	}
+	return a.Hash.String() < b.Hash.String()
+}
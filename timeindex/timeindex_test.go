@@ -0,0 +1,47 @@
+package timeindex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/img"
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+)
+
+func testPost(ts int64) *tangle.Object {
+	return &tangle.Object{Site: &site.Site{Type: "post", Nonce: uint64(ts), Content: hash.New([]byte{byte(ts)})}, Data: &post.Post{Timestamp: ts}}
+}
+
+func TestRange(t *testing.T) {
+	i := NewIndex()
+	ps := []*tangle.Object{testPost(30), testPost(10), testPost(20), testPost(40)}
+	for _, p := range ps {
+		i.Add(p)
+	}
+	i.Add(ps[0])
+	i.Add(&tangle.Object{Site: &site.Site{Type: "image"}, Data: &img.Image{}})
+	assert.Equal(t, []hash.Hash{ps[1].Site.Hash(), ps[2].Site.Hash(), ps[0].Site.Hash()}, i.Range("post", 10, 40))
+	assert.Equal(t, []hash.Hash{ps[0].Site.Hash(), ps[3].Site.Hash()}, i.Range("post", 25, 0))
+	assert.Empty(t, i.Range("post", 50, 0))
+	assert.Empty(t, i.Range("image", 0, 0))
+}
+
+func TestAdjacent(t *testing.T) {
+	i := NewIndex()
+	ps := []*tangle.Object{testPost(10), testPost(20), testPost(30)}
+	for _, p := range ps {
+		i.Add(p)
+	}
+	prev, next := i.Adjacent(ps[1])
+	assert.Equal(t, ps[0].Site.Hash(), *prev)
+	assert.Equal(t, ps[2].Site.Hash(), *next)
+	prev, next = i.Adjacent(ps[0])
+	assert.Nil(t, prev)
+	assert.Equal(t, ps[1].Site.Hash(), *next)
+	prev, next = i.Adjacent(&tangle.Object{Site: &site.Site{Type: "image"}, Data: &img.Image{}})
+	assert.Nil(t, prev)
+	assert.Nil(t, next)
+}
@@ -0,0 +1,213 @@
+package timeline
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/u-speak/core/follow"
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrInvalidCursor is returned when the cursor does not reference a known post
+	ErrInvalidCursor = errors.New("Cursor does not reference a known post")
+)
+
+// Index keeps the latest follow list of every author and who follows whom
+type Index struct {
+	lists     map[string]*follow.List
+	sites     map[string]hash.Hash
+	followers map[string]map[string]bool
+	lock      sync.RWMutex
+}
+
+// Page is a part of a timeline. Next is the cursor for the following page, it is empty on the last page
+type Page struct {
+	Objects []*tangle.Object
+	Next    hash.Hash
+}
+
+// NewIndex returns an empty follow index
+func NewIndex() *Index {
+	return &Index{lists: make(map[string]*follow.List), sites: make(map[string]hash.Hash), followers: make(map[string]map[string]bool)}
+}
+
+// Add indexes the object if it is a follow list with a valid signature.
+// Only the most recent list of an author is kept
+func (i *Index) Add(o *tangle.Object) {
+	if o.Site.Type != "follow" {
+		return
+	}
+	l := o.Data.(*follow.List)
+	if _, err := l.Verify(); err != nil {
+		log.Errorf("Ignoring follow list with invalid signature: %s", err)
+		return
+	}
+	fp := l.Fingerprint()
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if old, ok := i.lists[fp]; ok && old.Timestamp >= l.Timestamp {
+		return
+	}
+	i.set(fp, l, o.Site.Hash())
+}
+
+// Replace indexes the follow list even if a list with the same timestamp is already indexed.
+// It is used when a conflicting list with the same timestamp became canonical
+func (i *Index) Replace(o *tangle.Object) {
+	l, ok := o.Data.(*follow.List)
+	if !ok {
+		return
+	}
+	fp := l.Fingerprint()
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if old, ok := i.lists[fp]; ok && old.Timestamp > l.Timestamp {
+		return
+	}
+	i.set(fp, l, o.Site.Hash())
+}
+
+// set replaces the list of the author while holding the lock
+func (i *Index) set(fp string, l *follow.List, h hash.Hash) {
+	if old, ok := i.lists[fp]; ok {
+		authors, _ := old.Entries()
+		for _, a := range authors {
+			delete(i.followers[a], fp)
+		}
+	}
+	i.lists[fp] = l
+	i.sites[fp] = h
+	authors, _ := l.Entries()
+	for _, a := range authors {
+		if i.followers[a] == nil {
+			i.followers[a] = make(map[string]bool)
+		}
+		i.followers[a][fp] = true
+	}
+}
+
+// Sync indexes all follow lists stored in the tangle
+func (i *Index) Sync(t *tangle.Tangle) {
+	for _, h := range t.Hashes() {
+		s := t.GetSite(h)
+		if s == nil || s.Type != "follow" {
+			continue
+		}
+		o := t.Get(h)
+		if o != nil {
+			i.Add(o)
+		}
+	}
+}
+
+// Sites returns the hashes of all indexed follow lists
+func (i *Index) Sites() []hash.Hash {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	hs := []hash.Hash{}
+	for _, h := range i.sites {
+		hs = append(hs, h)
+	}
+	return hs
+}
+
+// Get returns the follow list of the author, or nil if there is none
+func (i *Index) Get(fingerprint string) *follow.List {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	return i.lists[fingerprint]
+}
+
+// Following returns the fingerprints and tags followed by the author
+func (i *Index) Following(fingerprint string) (authors []string, tags []string) {
+	l := i.Get(fingerprint)
+	if l == nil {
+		return []string{}, []string{}
+	}
+	return l.Entries()
+}
+
+// Followers returns the fingerprints of all authors following the key
+func (i *Index) Followers(fingerprint string) []string {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	fs := []string{}
+	for f := range i.followers[fingerprint] {
+		fs = append(fs, f)
+	}
+	sort.Strings(fs)
+	return fs
+}
+
+// Assemble builds a page of the timeline of posts by followed authors or with followed tags, newest first.
+// An empty cursor starts at the newest post
+func Assemble(t *tangle.Tangle, l *follow.List, cursor hash.Hash, limit int) (*Page, error) {
+	authors, tags := l.Entries()
+	fa := make(map[string]bool)
+	for _, a := range authors {
+		fa[a] = true
+	}
+	ft := make(map[string]bool)
+	for _, tg := range tags {
+		ft[tg] = true
+	}
+	matches := func(p *post.Post) bool {
+		if fa[p.Fingerprint()] {
+			return true
+		}
+		for _, tg := range p.Tags() {
+			if ft[tg] {
+				return true
+			}
+		}
+		return false
+	}
+
+	type entry struct {
+		o  *tangle.Object
+		h  string
+		ts int64
+	}
+	es := []entry{}
+	for _, h := range t.Hashes() {
+		o := t.Get(h)
+		if o == nil || o.Site.Type != "post" {
+			continue
+		}
+		p := o.Data.(*post.Post)
+		if matches(p) {
+			es = append(es, entry{o: o, h: h.String(), ts: p.Timestamp})
+		}
+	}
+	before := func(a, b entry) bool {
+		if a.ts == b.ts {
+			return a.h < b.h
+		}
+		return a.ts > b.ts
+	}
+	sort.Slice(es, func(i, j int) bool { return before(es[i], es[j]) })
+
+	start := 0
+	if cursor != (hash.Hash{}) {
+		co := t.Get(cursor)
+		if co == nil || co.Site.Type != "post" {
+			return nil, ErrInvalidCursor
+		}
+		ce := entry{h: cursor.String(), ts: co.Data.(*post.Post).Timestamp}
+		start = sort.Search(len(es), func(i int) bool { return before(ce, es[i]) })
+	}
+	pg := &Page{Objects: []*tangle.Object{}}
+	for i := start; i < len(es) && len(pg.Objects) < limit; i++ {
+		pg.Objects = append(pg.Objects, es[i].o)
+	}
+	if start+limit < len(es) && len(pg.Objects) > 0 {
+		pg.Next = pg.Objects[len(pg.Objects)-1].Site.Hash()
+	}
+	return pg, nil
+}
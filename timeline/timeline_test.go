@@ -0,0 +1,135 @@
+package timeline
+
+import (
+	"bytes"
+	"crypto"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/follow"
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/datastore"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+	"github.com/u-speak/core/tangle/store"
+	"github.com/u-speak/core/tangle/store/memorystore"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/packet"
+)
+
+var pgpConfig = &packet.Config{DefaultHash: crypto.SHA256}
+
+func entity() *openpgp.Entity {
+	e, _ := openpgp.NewEntity("Test", "test", "test@example.com", pgpConfig)
+	return e
+}
+
+func sign(e *openpgp.Entity, content string, ts int64) post.Post {
+	buff := bytes.NewBuffer(nil)
+	_ = openpgp.ArmoredDetachSignText(buff, e, strings.NewReader(content), pgpConfig)
+	return post.Post{Content: content, Pubkey: e, Signature: buff.String(), Timestamp: ts}
+}
+
+func inject(t *testing.T, tngl *tangle.Tangle, vs []*site.Site, d datastore.Serializable) *tangle.Object {
+	h, _ := d.Hash()
+	o := &tangle.Object{Site: &site.Site{Content: h, Type: d.Type(), Validates: vs}, Data: d}
+	o.Site.Mine(1)
+	assert.NoError(t, tngl.Inject(o, false))
+	return o
+}
+
+func TestAssemble(t *testing.T) {
+	ms := &memorystore.MemoryStore{}
+	_ = ms.Init(store.Options{})
+	dp := path.Join(os.TempDir(), "testTimeline.db")
+	defer os.Remove(dp)
+	tngl, err := tangle.New(tangle.Options{Store: ms, DataPath: dp})
+	assert.NoError(t, err)
+	defer tngl.Close()
+	idx := NewIndex()
+	tngl.OnAdd(idx.Add)
+	gen := tngl.Tips()
+
+	alice, bob, carol := entity(), entity(), entity()
+	p1 := sign(bob, "first", 1)
+	p2 := sign(carol, "about #golang", 2)
+	p3 := sign(carol, "unrelated", 3)
+	p4 := sign(bob, "second", 4)
+	for _, p := range []post.Post{p1, p2, p3, p4} {
+		p := p
+		inject(t, tngl, gen, &p)
+	}
+	bp := &post.Post{Pubkey: bob}
+	l := &follow.List{Post: sign(alice, bp.Fingerprint()+"\n#GoLang", 5)}
+	inject(t, tngl, gen, l)
+	assert.NotNil(t, idx.Get(l.Fingerprint()))
+
+	pg, err := Assemble(tngl, l, hash.Hash{}, 2)
+	assert.NoError(t, err)
+	assert.Len(t, pg.Objects, 2)
+	assert.Equal(t, "second", pg.Objects[0].Data.(*post.Post).Content)
+	assert.Equal(t, "about #golang", pg.Objects[1].Data.(*post.Post).Content)
+	assert.NotEqual(t, hash.Hash{}, pg.Next)
+
+	pg, err = Assemble(tngl, l, pg.Next, 2)
+	assert.NoError(t, err)
+	assert.Len(t, pg.Objects, 1)
+	assert.Equal(t, "first", pg.Objects[0].Data.(*post.Post).Content)
+	assert.Equal(t, hash.Hash{}, pg.Next)
+
+	_, err = Assemble(tngl, l, hash.Hash{1}, 2)
+	assert.Equal(t, ErrInvalidCursor, err)
+}
+
+func TestFollowers(t *testing.T) {
+	idx := NewIndex()
+	alice, bob := entity(), entity()
+	bp := &post.Post{Pubkey: bob}
+	l1 := &follow.List{Post: sign(alice, bp.Fingerprint()+"\n#tag", 2)}
+	l2 := &follow.List{Post: sign(alice, "#other", 3)}
+	l0 := &follow.List{Post: sign(alice, bp.Fingerprint(), 1)}
+	add := func(l *follow.List) {
+		idx.Add(&tangle.Object{Site: &site.Site{Type: "follow"}, Data: l})
+	}
+
+	add(l1)
+	assert.Equal(t, []string{l1.Fingerprint()}, idx.Followers(bp.Fingerprint()))
+	a, tags := idx.Following(l1.Fingerprint())
+	assert.Equal(t, []string{bp.Fingerprint()}, a)
+	assert.Equal(t, []string{"tag"}, tags)
+
+	add(l0)
+	_, tags = idx.Following(l1.Fingerprint())
+	assert.Equal(t, []string{"tag"}, tags)
+
+	add(l2)
+	assert.Len(t, idx.Sites(), 1)
+	assert.Empty(t, idx.Followers(bp.Fingerprint()))
+	a, tags = idx.Following(l1.Fingerprint())
+	assert.Empty(t, a)
+	assert.Equal(t, []string{"other"}, tags)
+}
+
+func TestReplace(t *testing.T) {
+	idx := NewIndex()
+	alice := entity()
+	a := &follow.List{Post: sign(alice, "#a", 2)}
+	b := &follow.List{Post: sign(alice, "#b", 2)}
+	old := &follow.List{Post: sign(alice, "#old", 1)}
+	idx.Add(&tangle.Object{Site: &site.Site{Type: "follow"}, Data: a})
+	idx.Add(&tangle.Object{Site: &site.Site{Type: "follow"}, Data: b})
+	_, tags := idx.Following(a.Fingerprint())
+	assert.Equal(t, []string{"a"}, tags)
+
+	idx.Replace(&tangle.Object{Site: &site.Site{Type: "follow"}, Data: b})
+	_, tags = idx.Following(a.Fingerprint())
+	assert.Equal(t, []string{"b"}, tags)
+
+	idx.Replace(&tangle.Object{Site: &site.Site{Type: "follow"}, Data: old})
+	_, tags = idx.Following(a.Fingerprint())
+	assert.Equal(t, []string{"b"}, tags)
+}
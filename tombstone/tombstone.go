@@ -0,0 +1,41 @@
+package tombstone
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/tangle/datastore"
+	"github.com/u-speak/core/tangle/hash"
+)
+
+// ErrNotAuthor is returned when the tombstone was not signed by the author of the removed site
+var ErrNotAuthor = errors.New("Tombstone is not signed by the author of the site")
+
+// Tombstone is a signed request of an author to remove the payload of one of their sites.
+// The content is the hash of the site. Compliant nodes drop the payload but keep the site, so the tangle stays intact
+type Tombstone struct {
+	post.Post
+}
+
+// Type implements tangle/datastore.serializable
+func (t *Tombstone) Type() string {
+	return "tombstone"
+}
+
+// Target returns the hash of the site to remove
+func (t *Tombstone) Target() (hash.Hash, error) {
+	var h hash.Hash
+	err := h.UnmarshalText([]byte(strings.TrimSpace(t.Content)))
+	return h, err
+}
+
+// Authorize checks that the tombstone was signed with the key of the payload to remove.
+// Only signed payloads can be removed by their authors
+func (t *Tombstone) Authorize(d datastore.Serializable) error {
+	s, ok := d.(interface{ Fingerprint() string })
+	if !ok || s.Fingerprint() == "" || s.Fingerprint() != t.Fingerprint() {
+		return ErrNotAuthor
+	}
+	return nil
+}
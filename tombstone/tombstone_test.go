@@ -0,0 +1,37 @@
+package tombstone
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/img"
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/tangle/hash"
+
+	"golang.org/x/crypto/openpgp"
+)
+
+func TestTarget(t *testing.T) {
+	h := hash.New([]byte("site"))
+	ts := &Tombstone{Post: post.Post{Content: h.String() + "\n"}}
+	target, err := ts.Target()
+	assert.NoError(t, err)
+	assert.Equal(t, h, target)
+
+	ts.Content = "foo"
+	_, err = ts.Target()
+	assert.Error(t, err)
+}
+
+func TestAuthorize(t *testing.T) {
+	author, err := openpgp.NewEntity("Author", "", "author@example.com", nil)
+	assert.NoError(t, err)
+	other, err := openpgp.NewEntity("Other", "", "other@example.com", nil)
+	assert.NoError(t, err)
+	p := &post.Post{Content: "foo", Pubkey: author}
+
+	assert.NoError(t, (&Tombstone{Post: post.Post{Pubkey: author}}).Authorize(p))
+	assert.Equal(t, ErrNotAuthor, (&Tombstone{Post: post.Post{Pubkey: other}}).Authorize(p))
+	assert.Equal(t, ErrNotAuthor, (&Tombstone{Post: post.Post{Pubkey: author}}).Authorize(&img.Image{}))
+	assert.Equal(t, ErrNotAuthor, (&Tombstone{}).Authorize(&post.Post{}))
+}
@@ -0,0 +1,169 @@
+package tsa
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+var (
+	oidSHA256     = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSignedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidTSTInfo    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+)
+
+type algorithmIdentifier struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters asn1.RawValue `asn1:"optional"`
+}
+
+type messageImprint struct {
+	HashAlgorithm algorithmIdentifier
+	HashedMessage []byte
+}
+
+type timeStampReq struct {
+	Version        int
+	MessageImprint messageImprint
+	ReqPolicy      asn1.ObjectIdentifier `asn1:"optional"`
+	Nonce          *big.Int              `asn1:"optional"`
+	CertReq        bool                  `asn1:"optional,default:false"`
+}
+
+type pkiStatusInfo struct {
+	Status       int
+	StatusString []string       `asn1:"optional"`
+	FailInfo     asn1.BitString `asn1:"optional"`
+}
+
+type timeStampResp struct {
+	Status         pkiStatusInfo
+	TimeStampToken asn1.RawValue `asn1:"optional"`
+}
+
+// contentInfo is the CMS wrapper of a token, the content is the signed data
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"tag:0"`
+}
+
+type signedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	EncapContentInfo encapsulatedContentInfo
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue `asn1:"optional,tag:1"`
+	SignerInfos      asn1.RawValue
+}
+
+// encapsulatedContentInfo holds the DER encoded TSTInfo as an octet string
+type encapsulatedContentInfo struct {
+	EContentType asn1.ObjectIdentifier
+	EContent     asn1.RawValue `asn1:"optional,tag:0"`
+}
+
+type accuracy struct {
+	Seconds int `asn1:"optional"`
+	Millis  int `asn1:"optional,tag:0"`
+	Micros  int `asn1:"optional,tag:1"`
+}
+
+type tstInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint messageImprint
+	SerialNumber   *big.Int
+	GenTime        time.Time     `asn1:"generalized"`
+	Accuracy       accuracy      `asn1:"optional"`
+	Ordering       bool          `asn1:"optional,default:false"`
+	Nonce          *big.Int      `asn1:"optional"`
+	TSA            asn1.RawValue `asn1:"optional,tag:0"`
+	Extensions     asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+// NewRequest builds a DER encoded TimeStampReq for a SHA-256 digest. The returned nonce has to be passed to ParseResponse
+func NewRequest(digest []byte) ([]byte, *big.Int, error) {
+	nonce, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return nil, nil, err
+	}
+	req, err := asn1.Marshal(timeStampReq{
+		Version: 1,
+		MessageImprint: messageImprint{
+			HashAlgorithm: algorithmIdentifier{Algorithm: oidSHA256, Parameters: asn1.NullRawValue},
+			HashedMessage: digest,
+		},
+		Nonce:   nonce,
+		CertReq: true,
+	})
+	return req, nonce, err
+}
+
+// ParseResponse checks the status of a DER encoded TimeStampResp and returns the contained token.
+// The token has to timestamp the SHA-256 digest and carry the nonce of the request
+func ParseResponse(b, digest []byte, nonce *big.Int) ([]byte, error) {
+	resp := timeStampResp{}
+	rest, err := asn1.Unmarshal(b, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, errors.New("Trailing data after timestamp response")
+	}
+	// 0: granted, 1: grantedWithMods
+	if resp.Status.Status > 1 {
+		msg := "Timestamp request rejected with status " + strconv.Itoa(resp.Status.Status)
+		for _, s := range resp.Status.StatusString {
+			msg += ": " + s
+		}
+		return nil, errors.New(msg)
+	}
+	if len(resp.TimeStampToken.FullBytes) == 0 {
+		return nil, errors.New("Timestamp response did not contain a token")
+	}
+	info, err := parseToken(resp.TimeStampToken.FullBytes)
+	if err != nil {
+		return nil, err
+	}
+	if !info.MessageImprint.HashAlgorithm.Algorithm.Equal(oidSHA256) {
+		return nil, errors.New("Timestamp token uses an unexpected hash algorithm")
+	}
+	if !bytes.Equal(info.MessageImprint.HashedMessage, digest) {
+		return nil, errors.New("Timestamp token is for a different digest")
+	}
+	if info.Nonce == nil || info.Nonce.Cmp(nonce) != 0 {
+		return nil, errors.New("Timestamp token does not contain the nonce of the request")
+	}
+	return resp.TimeStampToken.FullBytes, nil
+}
+
+// parseToken extracts the TSTInfo from the signed data of a token
+func parseToken(token []byte) (*tstInfo, error) {
+	ci := contentInfo{}
+	if _, err := asn1.Unmarshal(token, &ci); err != nil {
+		return nil, err
+	}
+	if !ci.ContentType.Equal(oidSignedData) {
+		return nil, errors.New("Timestamp token is not signed data")
+	}
+	sd := signedData{}
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+		return nil, err
+	}
+	if !sd.EncapContentInfo.EContentType.Equal(oidTSTInfo) {
+		return nil, errors.New("Timestamp token does not contain a TSTInfo")
+	}
+	var content []byte
+	if _, err := asn1.Unmarshal(sd.EncapContentInfo.EContent.Bytes, &content); err != nil {
+		return nil, err
+	}
+	info := &tstInfo{}
+	if _, err := asn1.Unmarshal(content, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
@@ -0,0 +1,149 @@
+package tsa
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/coreos/bbolt"
+	"github.com/u-speak/core/migrate"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// migrations upgrade the token database to the current format, see migrate.Open
+var migrations = []migrate.Migration{migrate.None}
+
+// MaxResponseSize is the largest response accepted from the authority. Tokens including the signing certificates are a few KiB
+const MaxResponseSize = 1 << 16
+
+var (
+	tokenBucketName = []byte("tokens")
+	// ErrNoToken is returned when no token is stored for a site
+	ErrNoToken = errors.New("No timestamp token available for this site")
+)
+
+// Service requests RFC 3161 timestamp tokens for accepted sites and stores them.
+// Tokens are requested one at a time from a background worker
+type Service struct {
+	url     string
+	db      *bolt.DB
+	client  *http.Client
+	jobs    chan hash.Hash
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// New returns a service using the time-stamping authority at url, storing the tokens at path.
+// Requests time out after timeout, up to queue sites wait for their token
+func New(url, path string, timeout time.Duration, queue int) (*Service, error) {
+	db, err := migrate.Open(path, 0644, migrations)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(tokenBucketName)
+		return err
+	})
+	s := &Service{url: url, db: db, client: &http.Client{Timeout: timeout},
+		jobs: make(chan hash.Hash, queue), done: make(chan struct{}), stopped: make(chan struct{})}
+	go s.work()
+	return s, err
+}
+
+// Digest returns the SHA-256 digest submitted to the authority for a site hash
+func Digest(h hash.Hash) []byte {
+	d := sha256.Sum256(h.Slice())
+	return d[:]
+}
+
+// Add queues the site for a token without blocking. Sites are not timestamped if the queue is full
+func (s *Service) Add(o *tangle.Object) {
+	h := o.Site.Hash()
+	select {
+	case s.jobs <- h:
+	default:
+		log.Warnf("Timestamp queue is full, not timestamping site %s", h)
+	}
+}
+
+func (s *Service) work() {
+	defer close(s.stopped)
+	for {
+		select {
+		case h := <-s.jobs:
+			if err := s.Timestamp(h); err != nil {
+				log.Errorf("Could not timestamp site %s: %s", h, err)
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Timestamp requests and stores a token for the site hash
+func (s *Service) Timestamp(h hash.Hash) error {
+	digest := Digest(h)
+	req, nonce, err := NewRequest(digest)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Post(s.url, "application/timestamp-query", bytes.NewReader(req))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.New("Time-stamping authority returned " + resp.Status)
+	}
+	if ct, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); ct != "application/timestamp-reply" {
+		return errors.New("Time-stamping authority returned unexpected content type " + resp.Header.Get("Content-Type"))
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, MaxResponseSize+1))
+	if err != nil {
+		return err
+	}
+	if len(b) > MaxResponseSize {
+		return errors.New("Timestamp response is too large")
+	}
+	token, err := ParseResponse(b, digest, nonce)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(tokenBucketName).Put(h.Slice(), token)
+	})
+}
+
+// Token returns the stored DER encoded token of a site
+func (s *Service) Token(h hash.Hash) ([]byte, error) {
+	var token []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		t := tx.Bucket(tokenBucketName).Get(h.Slice())
+		if t != nil {
+			token = append([]byte{}, t...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, ErrNoToken
+	}
+	return token, nil
+}
+
+// Close stops the worker and closes the token database
+func (s *Service) Close() {
+	close(s.done)
+	<-s.stopped
+	_ = s.db.Close()
+}
@@ -0,0 +1,182 @@
+package tsa
+
+import (
+	"encoding/asn1"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/tangle"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tangle/site"
+)
+
+// fakeToken builds an unsigned token timestamping the digest
+func fakeToken(alg asn1.ObjectIdentifier, digest []byte, nonce *big.Int) asn1.RawValue {
+	info, _ := asn1.Marshal(tstInfo{
+		Version:        1,
+		Policy:         asn1.ObjectIdentifier{1, 3, 3, 7},
+		MessageImprint: messageImprint{HashAlgorithm: algorithmIdentifier{Algorithm: alg}, HashedMessage: digest},
+		SerialNumber:   big.NewInt(42),
+		GenTime:        time.Now().UTC().Truncate(time.Second),
+		Nonce:          nonce,
+	})
+	content, _ := asn1.Marshal(info)
+	set := asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true}
+	sd, _ := asn1.Marshal(signedData{
+		Version:          3,
+		DigestAlgorithms: set,
+		EncapContentInfo: encapsulatedContentInfo{
+			EContentType: oidTSTInfo,
+			EContent:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: content},
+		},
+		SignerInfos: set,
+	})
+	ci, _ := asn1.Marshal(contentInfo{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: sd},
+	})
+	return asn1.RawValue{FullBytes: ci}
+}
+
+// respond answers a DER encoded TimeStampReq with a fake token
+func respond(b []byte) []byte {
+	req := timeStampReq{}
+	_, _ = asn1.Unmarshal(b, &req)
+	resp, _ := asn1.Marshal(timeStampResp{
+		Status:         pkiStatusInfo{Status: 0},
+		TimeStampToken: fakeToken(req.MessageImprint.HashAlgorithm.Algorithm, req.MessageImprint.HashedMessage, req.Nonce),
+	})
+	return resp
+}
+
+func TestRequest(t *testing.T) {
+	d := Digest(hash.Hash{1, 3, 3, 7})
+	b, nonce, err := NewRequest(d)
+	assert.NoError(t, err)
+	req := timeStampReq{}
+	_, err = asn1.Unmarshal(b, &req)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, req.Version)
+	assert.Equal(t, nonce, req.Nonce)
+	assert.Equal(t, d, req.MessageImprint.HashedMessage)
+	assert.True(t, req.MessageImprint.HashAlgorithm.Algorithm.Equal(oidSHA256))
+}
+
+func TestParseResponse(t *testing.T) {
+	d := Digest(hash.Hash{1, 3, 3, 7})
+	nonce := big.NewInt(42)
+	token := fakeToken(oidSHA256, d, nonce)
+	b, _ := asn1.Marshal(timeStampResp{Status: pkiStatusInfo{Status: 0}, TimeStampToken: token})
+	parsed, err := ParseResponse(b, d, nonce)
+	assert.NoError(t, err)
+	assert.Equal(t, token.FullBytes, parsed)
+
+	b, _ = asn1.Marshal(struct{ Status pkiStatusInfo }{Status: pkiStatusInfo{Status: 2, StatusString: []string{"bad"}}})
+	_, err = ParseResponse(b, d, nonce)
+	assert.EqualError(t, err, "Timestamp request rejected with status 2: bad")
+
+	b, _ = asn1.Marshal(timeStampResp{Status: pkiStatusInfo{Status: 0}, TimeStampToken: fakeToken(oidSHA256, Digest(hash.Hash{4, 2}), nonce)})
+	_, err = ParseResponse(b, d, nonce)
+	assert.EqualError(t, err, "Timestamp token is for a different digest")
+
+	b, _ = asn1.Marshal(timeStampResp{Status: pkiStatusInfo{Status: 0}, TimeStampToken: fakeToken(asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}, d, nonce)})
+	_, err = ParseResponse(b, d, nonce)
+	assert.EqualError(t, err, "Timestamp token uses an unexpected hash algorithm")
+
+	b, _ = asn1.Marshal(timeStampResp{Status: pkiStatusInfo{Status: 0}, TimeStampToken: fakeToken(oidSHA256, d, big.NewInt(7))})
+	_, err = ParseResponse(b, d, nonce)
+	assert.EqualError(t, err, "Timestamp token does not contain the nonce of the request")
+
+	b, _ = asn1.Marshal(timeStampResp{Status: pkiStatusInfo{Status: 0}, TimeStampToken: fakeToken(oidSHA256, d, nil)})
+	_, err = ParseResponse(b, d, nonce)
+	assert.Error(t, err)
+}
+
+func TestTimestamp(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/timestamp-query", r.Header.Get("Content-Type"))
+		b, _ := ioutil.ReadAll(r.Body)
+		_, err := asn1.Unmarshal(b, &timeStampReq{})
+		assert.NoError(t, err)
+		w.Header().Set("Content-Type", "application/timestamp-reply")
+		w.Write(respond(b))
+	}))
+	defer srv.Close()
+	p := path.Join(os.TempDir(), "testTSA.db")
+	defer os.Remove(p)
+	s, err := New(srv.URL, p, time.Second, 1)
+	assert.NoError(t, err)
+	defer s.Close()
+
+	h := hash.Hash{1, 3, 3, 7}
+	_, err = s.Token(h)
+	assert.Equal(t, ErrNoToken, err)
+	assert.NoError(t, s.Timestamp(h))
+	token, err := s.Token(h)
+	assert.NoError(t, err)
+	info, err := parseToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, Digest(h), info.MessageImprint.HashedMessage)
+
+	o := &tangle.Object{Site: &site.Site{Content: hash.Hash{4, 2}, Type: "post"}}
+	s.Add(o)
+	assert.Eventually(t, func() bool {
+		_, err := s.Token(o.Site.Hash())
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+	p := path.Join(os.TempDir(), "testTSATimeout.db")
+	defer os.Remove(p)
+	s, err := New(srv.URL, p, 50*time.Millisecond, 1)
+	assert.NoError(t, err)
+	defer s.Close()
+
+	start := time.Now()
+	assert.Error(t, s.Timestamp(hash.Hash{1}))
+	assert.True(t, time.Since(start) < time.Second)
+	for i := byte(0); i < 10; i++ {
+		s.Add(&tangle.Object{Site: &site.Site{Content: hash.Hash{i}, Type: "post"}})
+	}
+	assert.True(t, len(s.jobs) <= 1)
+}
+
+func TestInvalidResponse(t *testing.T) {
+	status, ct, size := http.StatusOK, "text/html", 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", ct)
+		w.WriteHeader(status)
+		w.Write(append(respond(b), make([]byte, size)...))
+	}))
+	defer srv.Close()
+	p := path.Join(os.TempDir(), "testTSAInvalid.db")
+	defer os.Remove(p)
+	s, err := New(srv.URL, p, time.Second, 1)
+	assert.NoError(t, err)
+	defer s.Close()
+
+	h := hash.Hash{1, 3, 3, 7}
+	assert.EqualError(t, s.Timestamp(h), "Time-stamping authority returned unexpected content type text/html")
+	ct = "application/timestamp-reply"
+	status = http.StatusInternalServerError
+	assert.EqualError(t, s.Timestamp(h), "Time-stamping authority returned 500 Internal Server Error")
+	status, size = http.StatusOK, MaxResponseSize
+	assert.EqualError(t, s.Timestamp(h), "Timestamp response is too large")
+	_, err = s.Token(h)
+	assert.Equal(t, ErrNoToken, err)
+}
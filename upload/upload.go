@@ -0,0 +1,268 @@
+// Package upload keeps the partial data of resumable uploads on disk until they are complete.
+// Uploads follow the core protocol of tus (https://tus.io): the length is declared up front and
+// the data is appended in chunks at the offset the server reports
+package upload
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned for unknown or expired uploads
+	ErrNotFound = errors.New("Upload not found")
+	// ErrTooLarge is returned when the declared length exceeds the limit or more data than declared is sent
+	ErrTooLarge = errors.New("Upload too large")
+	// ErrOffset is returned when a chunk does not start at the current offset of the upload
+	ErrOffset = errors.New("Chunk does not start at the upload offset")
+	// ErrBusy is returned when a chunk is appended to an upload which is still receiving another one
+	ErrBusy = errors.New("Upload is receiving another chunk")
+	// ErrIncomplete is returned when the data of an upload is read before all of it arrived
+	ErrIncomplete = errors.New("Upload is not complete")
+	// ErrTooMany is returned when no more uploads can be started
+	ErrTooMany = errors.New("Too many unfinished uploads, try again later")
+	// ErrMetadata is returned for malformed Upload-Metadata headers
+	ErrMetadata = errors.New("Malformed upload metadata")
+)
+
+// Session is the progress of a single upload
+type Session struct {
+	ID       string            `json:"id"`
+	Length   int64             `json:"length"`
+	Offset   int64             `json:"offset"`
+	Metadata map[string]string `json:"metadata"`
+	Created  time.Time         `json:"created"`
+	Updated  time.Time         `json:"updated"`
+	busy     bool
+}
+
+// Complete reports whether all data of the upload arrived
+func (s *Session) Complete() bool {
+	return s.Offset == s.Length
+}
+
+// Store keeps the unfinished uploads in a directory. The data of every upload is stored in a .part file,
+// the metadata in a .json file next to it, so uploads survive restarts
+type Store struct {
+	dir      string
+	maxSize  int64
+	max      int
+	ttl      time.Duration
+	sessions map[string]*Session
+	lock     sync.Mutex
+}
+
+// New opens the store in dir, creating it if necessary, and loads the uploads left from earlier runs.
+// Uploads can be at most maxSize bytes, at most max are kept at once and unfinished ones expire after ttl
+func New(dir string, maxSize int64, max int, ttl time.Duration) (*Store, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	s := &Store{dir: dir, maxSize: maxSize, max: max, ttl: ttl, sessions: make(map[string]*Session)}
+	ms, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range ms {
+		b, err := ioutil.ReadFile(m)
+		if err != nil {
+			return nil, err
+		}
+		sess := &Session{}
+		if err := json.Unmarshal(b, sess); err != nil {
+			return nil, err
+		}
+		fi, err := os.Stat(s.part(sess.ID))
+		if err != nil {
+			os.Remove(m)
+			continue
+		}
+		sess.Offset = fi.Size()
+		s.sessions[sess.ID] = sess
+	}
+	return s, nil
+}
+
+// Create starts an upload of length bytes carrying the metadata
+func (s *Store) Create(length int64, metadata map[string]string) (*Session, error) {
+	if length <= 0 || length > s.maxSize {
+		return nil, ErrTooLarge
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	sess := &Session{ID: hex.EncodeToString(b), Length: length, Metadata: metadata, Created: now, Updated: now}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if len(s.sessions) >= s.max {
+		return nil, ErrTooMany
+	}
+	m, err := json.Marshal(sess)
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(s.part(sess.ID), nil, 0600); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(s.meta(sess.ID), m, 0600); err != nil {
+		os.Remove(s.part(sess.ID))
+		return nil, err
+	}
+	s.sessions[sess.ID] = sess
+	c := *sess
+	return &c, nil
+}
+
+// Get returns a copy of the upload or nil if the id is unknown
+func (s *Store) Get(id string) *Session {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	sess, ok := s.sessions[id]
+	if !ok {
+		return nil
+	}
+	c := *sess
+	return &c
+}
+
+// Append writes the chunk read from r at offset, which has to be the current offset of the upload.
+// If the reader fails the data received until then is kept, so the client can resume from the new offset.
+// The upload is returned with every error but ErrNotFound
+func (s *Store) Append(id string, offset int64, r io.Reader) (*Session, error) {
+	s.lock.Lock()
+	sess, ok := s.sessions[id]
+	if !ok {
+		s.lock.Unlock()
+		return nil, ErrNotFound
+	}
+	if sess.busy {
+		c := *sess
+		s.lock.Unlock()
+		return &c, ErrBusy
+	}
+	if offset != sess.Offset {
+		c := *sess
+		s.lock.Unlock()
+		return &c, ErrOffset
+	}
+	sess.busy = true
+	remaining := sess.Length - sess.Offset
+	s.lock.Unlock()
+
+	n, err := s.write(id, io.LimitReader(r, remaining))
+	if err == nil {
+		var extra [1]byte
+		if m, _ := r.Read(extra[:]); m > 0 {
+			err = ErrTooLarge
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	sess.busy = false
+	sess.Offset += n
+	sess.Updated = time.Now()
+	c := *sess
+	return &c, err
+}
+
+// Data returns the data of a complete upload
+func (s *Store) Data(id string) ([]byte, error) {
+	sess := s.Get(id)
+	if sess == nil {
+		return nil, ErrNotFound
+	}
+	if !sess.Complete() {
+		return nil, ErrIncomplete
+	}
+	return ioutil.ReadFile(s.part(id))
+}
+
+// Remove deletes the upload and its data
+func (s *Store) Remove(id string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.remove(id)
+}
+
+// Expire removes the uploads which were not updated within the ttl and returns their amount
+func (s *Store) Expire(now time.Time) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	n := 0
+	for id, sess := range s.sessions {
+		if !sess.busy && now.Sub(sess.Updated) > s.ttl {
+			s.remove(id)
+			n++
+		}
+	}
+	return n
+}
+
+// Len returns the amount of unfinished uploads
+func (s *Store) Len() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return len(s.sessions)
+}
+
+func (s *Store) write(id string, r io.Reader) (int64, error) {
+	f, err := os.OpenFile(s.part(id), os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(f, r)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return n, err
+}
+
+func (s *Store) remove(id string) {
+	delete(s.sessions, id)
+	os.Remove(s.part(id))
+	os.Remove(s.meta(id))
+}
+
+func (s *Store) part(id string) string {
+	return filepath.Join(s.dir, id+".part")
+}
+
+func (s *Store) meta(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// ParseMetadata decodes an Upload-Metadata header, a comma separated list of keys each followed by a space and its base64 encoded value
+func ParseMetadata(h string) (map[string]string, error) {
+	m := make(map[string]string)
+	for _, p := range strings.Split(h, ",") {
+		kv := strings.Fields(p)
+		if len(kv) == 0 {
+			continue
+		}
+		if len(kv) > 2 {
+			return nil, ErrMetadata
+		}
+		v := []byte{}
+		if len(kv) == 2 {
+			var err error
+			if v, err = base64.StdEncoding.DecodeString(kv[1]); err != nil {
+				return nil, ErrMetadata
+			}
+		}
+		m[kv[0]] = string(v)
+	}
+	return m, nil
+}
@@ -0,0 +1,78 @@
+package upload
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "uploads")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	s, err := New(dir, 10, 2, time.Hour)
+	assert.NoError(t, err)
+
+	_, err = s.Create(11, nil)
+	assert.Equal(t, ErrTooLarge, err)
+	sess, err := s.Create(10, map[string]string{"nonce": "1"})
+	assert.NoError(t, err)
+
+	_, err = s.Append(sess.ID, 3, bytes.NewReader([]byte("abc")))
+	assert.Equal(t, ErrOffset, err)
+	sess, err = s.Append(sess.ID, 0, bytes.NewReader([]byte("abcd")))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), sess.Offset)
+	_, err = s.Data(sess.ID)
+	assert.Equal(t, ErrIncomplete, err)
+
+	r, err := New(dir, 10, 2, time.Hour)
+	assert.NoError(t, err)
+	resumed := r.Get(sess.ID)
+	assert.NotNil(t, resumed)
+	assert.Equal(t, int64(4), resumed.Offset)
+	assert.Equal(t, "1", resumed.Metadata["nonce"])
+
+	sess, err = r.Append(sess.ID, 4, bytes.NewReader([]byte("efghijk")))
+	assert.Equal(t, ErrTooLarge, err)
+	assert.True(t, sess.Complete())
+	d, err := r.Data(sess.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("abcdefghij"), d)
+
+	r.Remove(sess.ID)
+	assert.Nil(t, r.Get(sess.ID))
+	_, err = r.Append(sess.ID, 10, bytes.NewReader(nil))
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestExpire(t *testing.T) {
+	dir, err := ioutil.TempDir("", "uploads")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	s, err := New(dir, 10, 1, time.Minute)
+	assert.NoError(t, err)
+	_, err = s.Create(5, nil)
+	assert.NoError(t, err)
+	_, err = s.Create(5, nil)
+	assert.Equal(t, ErrTooMany, err)
+	assert.Equal(t, 0, s.Expire(time.Now()))
+	assert.Equal(t, 1, s.Expire(time.Now().Add(2*time.Minute)))
+	assert.Equal(t, 0, s.Len())
+	fs, _ := ioutil.ReadDir(dir)
+	assert.Empty(t, fs)
+}
+
+func TestParseMetadata(t *testing.T) {
+	m, err := ParseMetadata("nonce MTI=, async,hash aGFzaA==")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"nonce": "12", "async": "", "hash": "hash"}, m)
+	_, err = ParseMetadata("nonce 1 2")
+	assert.Equal(t, ErrMetadata, err)
+	_, err = ParseMetadata("nonce !")
+	assert.Equal(t, ErrMetadata, err)
+}
@@ -0,0 +1,15 @@
+package util
+
+import (
+	"syscall"
+)
+
+// DiskUsage returns the free and total bytes of the filesystem containing path
+func DiskUsage(path string) (free uint64, total uint64, err error) {
+	var st syscall.Statfs_t
+	err = syscall.Statfs(path, &st)
+	if err != nil {
+		return 0, 0, err
+	}
+	return st.Bavail * uint64(st.Bsize), st.Blocks * uint64(st.Bsize), nil
+}
@@ -1,6 +1,8 @@
 package util
 
 import (
+	"errors"
+
 	"github.com/martinlindhe/bubblebabble"
 )
 
@@ -14,6 +16,15 @@ func EncodeBubbleBabble(h [32]byte) string {
 // DecodeBubbleBabble is a wrapper function to decode hashes from a human readable format
 func DecodeBubbleBabble(s string) ([32]byte, error) {
 	dst := [32]byte{}
-	_, err := bubblebabble.Decode(dst[:], []byte(s))
-	return dst, err
+	// Decoding into a buffer the size of the input keeps oversized input from overflowing the hash
+	buf := make([]byte, len(s))
+	n, err := bubblebabble.Decode(buf, []byte(s))
+	if err != nil {
+		return dst, err
+	}
+	if n != len(dst) {
+		return dst, errors.New("Invalid hash length")
+	}
+	copy(dst[:], buf)
+	return dst, nil
 }
@@ -0,0 +1,193 @@
+package validate
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/u-speak/core/follow"
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/subscription"
+	"github.com/u-speak/core/tangle/datastore"
+	"github.com/u-speak/core/tangle/hash"
+	"github.com/u-speak/core/tombstone"
+	"github.com/u-speak/core/util"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+)
+
+const (
+	// MaxNonce is the largest nonce accepted. Larger numbers can not be represented by JavaScript clients
+	// and would change the hash of the site on their way through JSON
+	MaxNonce = 1<<53 - 1
+	// MaxClockSkew is how far in the future the timestamp of a post may lie
+	MaxClockSkew = 10 * time.Minute
+)
+
+var (
+	// ErrHashLength is returned for hashes that do not decode to exactly hash.HashSize bytes
+	ErrHashLength = errors.New("Invalid hash length")
+	// ErrBase64 is returned for data in none of the accepted base64 encodings
+	ErrBase64 = errors.New("Could not parse base64 data")
+	// ErrType is returned for unknown site types
+	ErrType = errors.New("Invalid site type")
+	// ErrNonce is returned for nonces above MaxNonce
+	ErrNonce = errors.New("Nonce out of range")
+	// ErrTimestamp is returned for missing timestamps and timestamps too far in the future
+	ErrTimestamp = errors.New("Timestamp out of range")
+	// ErrPublicKey is returned for signed payloads whose public key can not be parsed
+	ErrPublicKey = errors.New("Malformed public key")
+	// ErrSignature is returned for signed payloads whose signature was not made by their public key
+	ErrSignature = errors.New("Signature does not match the public key")
+)
+
+// Types are the site types which can be submitted by clients and peers
+var Types = map[string]bool{
+	"post":         true,
+	"image":        true,
+	"subscription": true,
+	"follow":       true,
+	"tombstone":    true,
+}
+
+var encodings = []*base64.Encoding{
+	base64.URLEncoding,
+	base64.StdEncoding,
+	base64.RawURLEncoding,
+	base64.RawStdEncoding,
+}
+
+// Base64 decodes data in padded or raw, standard or url-safe base64
+func Base64(s string) ([]byte, error) {
+	for _, e := range encodings {
+		if b, err := e.DecodeString(s); err == nil {
+			return b, nil
+		}
+	}
+	return nil, ErrBase64
+}
+
+// Hash decodes a hash in bubblebabble or any base64 encoding
+func Hash(s string) (hash.Hash, error) {
+	if h, err := util.DecodeBubbleBabble(s); err == nil {
+		return h, nil
+	}
+	b, err := Base64(s)
+	if err != nil {
+		return hash.Hash{}, err
+	}
+	if err := HashBytes(b); err != nil {
+		return hash.Hash{}, err
+	}
+	return hash.FromSlice(b), nil
+}
+
+// HashBytes checks that a raw hash has the right length
+func HashBytes(b []byte) error {
+	if len(b) != hash.HashSize {
+		return ErrHashLength
+	}
+	return nil
+}
+
+// Armored checks that s is a single ASCII armored PGP block of the given type, like openpgp.PublicKeyType
+func Armored(s, blockType string) error {
+	b, err := armor.Decode(strings.NewReader(s))
+	if err != nil {
+		return errors.New("Invalid " + blockType + ": " + err.Error())
+	}
+	if b.Type != blockType {
+		return errors.New("Expected " + blockType + " but got " + b.Type)
+	}
+	return nil
+}
+
+// Timestamp checks that a unix timestamp is set and not too far in the future
+func Timestamp(ts int64) error {
+	if ts <= 0 || time.Unix(ts, 0).After(time.Now().Add(MaxClockSkew)) {
+		return ErrTimestamp
+	}
+	return nil
+}
+
+// Nonce checks that the nonce is within the range every client can represent
+func Nonce(n uint64) error {
+	if n > MaxNonce {
+		return ErrNonce
+	}
+	return nil
+}
+
+// Type checks that the site type can be submitted
+func Type(t string) error {
+	if !Types[t] {
+		return ErrType
+	}
+	return nil
+}
+
+// Post checks the armored fields and the timestamp of a post before it is decoded
+func Post(p *post.Post) error {
+	if err := Armored(p.PubkeyStr, openpgp.PublicKeyType); err != nil {
+		return err
+	}
+	if err := Armored(p.Signature, openpgp.SignatureType); err != nil {
+		return err
+	}
+	return Timestamp(p.Timestamp)
+}
+
+// Payload validates the data of a site. Only signed payloads carry fields which can be checked
+func Payload(d datastore.Serializable) error {
+	if p := signed(d); p != nil {
+		return Post(p)
+	}
+	return nil
+}
+
+// Signature checks that a signed payload carries a well formed public key which made its signature.
+// The public key of the payload is decoded as a side effect. Unsigned payloads pass
+func Signature(d datastore.Serializable) error {
+	p := signed(d)
+	if p == nil {
+		return nil
+	}
+	if err := Armored(p.PubkeyStr, openpgp.PublicKeyType); err != nil {
+		return ErrPublicKey
+	}
+	if err := p.ReInit(); err != nil {
+		return ErrPublicKey
+	}
+	if _, err := p.Verify(); err != nil {
+		return ErrSignature
+	}
+	return nil
+}
+
+// signed returns the signed post of a payload or nil if the payload is not signed
+func signed(d datastore.Serializable) *post.Post {
+	switch p := d.(type) {
+	case *post.Post:
+		return p
+	case *subscription.Subscription:
+		return &p.Post
+	case *follow.List:
+		return &p.Post
+	case *tombstone.Tombstone:
+		return &p.Post
+	}
+	return nil
+}
+
+// Site checks the fields of a submitted site which can be checked without knowing the tangle
+func Site(nonce uint64, typ string, d datastore.Serializable) error {
+	if err := Nonce(nonce); err != nil {
+		return err
+	}
+	if err := Type(typ); err != nil {
+		return err
+	}
+	return Payload(d)
+}
@@ -0,0 +1,73 @@
+package validate
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/base64"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/u-speak/core/img"
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/tangle/hash"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/packet"
+)
+
+func TestHash(t *testing.T) {
+	h := hash.New([]byte("foo"))
+	for _, e := range encodings {
+		d, err := Hash(e.EncodeToString(h[:]))
+		assert.NoError(t, err)
+		assert.Equal(t, h, d)
+	}
+	_, err := Hash(base64.URLEncoding.EncodeToString(h[:16]))
+	assert.Equal(t, ErrHashLength, err)
+	_, err = Hash("InVaLiDsTrInG")
+	assert.Equal(t, ErrBase64, err)
+}
+
+func TestRanges(t *testing.T) {
+	assert.NoError(t, Nonce(MaxNonce))
+	assert.Equal(t, ErrNonce, Nonce(math.MaxUint64))
+	assert.NoError(t, Timestamp(time.Now().Unix()))
+	assert.Equal(t, ErrTimestamp, Timestamp(0))
+	assert.Equal(t, ErrTimestamp, Timestamp(time.Now().Add(time.Hour).Unix()))
+	assert.NoError(t, Type("image"))
+	assert.Equal(t, ErrType, Type("genesis"))
+}
+
+func TestPayload(t *testing.T) {
+	c := &packet.Config{DefaultHash: crypto.SHA256}
+	e, err := openpgp.NewEntity("Test", "test", "test@example.com", c)
+	assert.NoError(t, err)
+	sig := bytes.NewBuffer(nil)
+	assert.NoError(t, openpgp.ArmoredDetachSignText(sig, e, strings.NewReader("foo"), c))
+	p := &post.Post{Content: "foo", Pubkey: e, Signature: sig.String(), Timestamp: time.Now().Unix()}
+	assert.NoError(t, p.JSON())
+
+	assert.NoError(t, Site(1, "post", p))
+	assert.NoError(t, Payload(&img.Image{}))
+	assert.Equal(t, ErrNonce, Site(math.MaxUint64, "post", p))
+
+	assert.NoError(t, Signature(p))
+	assert.NoError(t, Signature(&img.Image{}))
+	other, err := openpgp.NewEntity("Other", "other", "other@example.com", c)
+	assert.NoError(t, err)
+	forged := &post.Post{Content: "foo", Pubkey: other, Signature: sig.String(), Timestamp: p.Timestamp}
+	assert.NoError(t, forged.JSON())
+	assert.Equal(t, ErrSignature, Signature(forged))
+	broken := *forged
+	broken.PubkeyStr = strings.Replace(broken.PubkeyStr, "\n\n", "\n\nAAAA", 1)
+	assert.Equal(t, ErrPublicKey, Signature(&broken))
+
+	p.Signature, p.PubkeyStr = p.PubkeyStr, p.Signature
+	assert.Error(t, Payload(p))
+	assert.Equal(t, ErrPublicKey, Signature(p))
+	p.PubkeyStr = "foo"
+	assert.Error(t, Payload(p))
+}
@@ -0,0 +1,93 @@
+package warc
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/u-speak/core/img"
+	"github.com/u-speak/core/post"
+	"github.com/u-speak/core/tangle"
+)
+
+// Export writes an archive of all posts and images of the tangle, each followed by a metadata record.
+// base is the public api endpoint, used to build the target uris of the records
+func Export(t *tangle.Tangle, base, software string, w *Writer) error {
+	base = strings.TrimRight(base, "/")
+	now := time.Now()
+	info := &bytes.Buffer{}
+	info.WriteString("software: " + software + "\r\n")
+	info.WriteString("format: WARC File Format 1.1\r\n")
+	info.WriteString("description: u-speak tangle export of " + base + "\r\n")
+	if err := w.Write(&Record{Type: Info, ID: ID("warcinfo", base, now.String()), Date: now, ContentType: "application/warc-fields", Block: info.Bytes()}); err != nil {
+		return err
+	}
+	hs := t.Hashes()
+	sort.Slice(hs, func(i, j int) bool { return hs[i].String() < hs[j].String() })
+	for _, h := range hs {
+		s := t.GetSite(h)
+		if s == nil || (s.Type != "post" && s.Type != "image") {
+			continue
+		}
+		o := t.Get(h)
+		if o == nil {
+			continue
+		}
+		if err := exportObject(o, base, now, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func exportObject(o *tangle.Object, base string, now time.Time, w *Writer) error {
+	h := o.Site.Hash()
+	r := &Record{Type: Resource, ID: ID("resource", h.String()), Date: now}
+	meta := []Field{{"uspeak-hash", h.String()}, {"uspeak-type", o.Site.Type}}
+	for _, v := range o.Site.Validates {
+		meta = append(meta, Field{"uspeak-validates", v.Hash().String()})
+	}
+	switch d := o.Data.(type) {
+	case *post.Post:
+		if err := d.JSON(); err != nil {
+			return err
+		}
+		b, err := json.Marshal(d)
+		if err != nil {
+			return err
+		}
+		r.Date = time.Unix(d.Timestamp, 0)
+		r.TargetURI = base + "/api/v1/tangle/" + h.String()
+		r.ContentType = "application/json"
+		r.Block = b
+		meta = append(meta, Field{"uspeak-author", d.Fingerprint()}, Field{"uspeak-date", r.Date.UTC().Format(time.RFC3339)})
+		for _, tg := range d.Tags() {
+			meta = append(meta, Field{"uspeak-tag", tg})
+		}
+	case *img.Image:
+		r.TargetURI = base + "/api/v1/image/" + h.String()
+		r.ContentType = http.DetectContentType(d.Raw)
+		r.Block = d.Raw
+	default:
+		return nil
+	}
+	if err := w.Write(r); err != nil {
+		return err
+	}
+	block := &bytes.Buffer{}
+	for _, f := range meta {
+		block.WriteString(f.Name + ": " + f.Value + "\r\n")
+	}
+	return w.Write(&Record{
+		Type:        Metadata,
+		ID:          ID("metadata", h.String()),
+		Date:        r.Date,
+		TargetURI:   r.TargetURI,
+		ContentType: "application/warc-fields",
+		Fields:      []Field{{"WARC-Refers-To", r.ID}},
+		Block:       block.Bytes(),
+	})
+}
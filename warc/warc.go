@@ -0,0 +1,99 @@
+package warc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Version is the WARC format version written
+const Version = "WARC/1.1"
+
+// Record types
+const (
+	Info     = "warcinfo"
+	Resource = "resource"
+	Metadata = "metadata"
+)
+
+// Field is a named header of a record. Fields keep their order
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Record is a single WARC record
+type Record struct {
+	Type        string
+	ID          string
+	Date        time.Time
+	TargetURI   string
+	ContentType string
+	Fields      []Field
+	Block       []byte
+}
+
+// Writer writes WARC records to an underlying writer
+type Writer struct {
+	w        io.Writer
+	compress bool
+}
+
+// NewWriter returns a writer. If compress is set every record is written as its own gzip member, as expected for .warc.gz files
+func NewWriter(w io.Writer, compress bool) *Writer {
+	return &Writer{w: w, compress: compress}
+}
+
+// ID returns a record id derived from the given parts, so repeated exports produce the same ids
+func ID(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	b := h.Sum(nil)[:16]
+	b[6] = b[6]&0x0f | 0x50
+	b[8] = b[8]&0x3f | 0x80
+	s := hex.EncodeToString(b)
+	return "<urn:uuid:" + s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:] + ">"
+}
+
+// Write writes the record
+func (w *Writer) Write(r *Record) error {
+	buf := &bytes.Buffer{}
+	header := func(name, value string) {
+		if value != "" {
+			buf.WriteString(name + ": " + value + "\r\n")
+		}
+	}
+	digest := sha1.Sum(r.Block)
+	buf.WriteString(Version + "\r\n")
+	header("WARC-Type", r.Type)
+	header("WARC-Record-ID", r.ID)
+	header("WARC-Date", r.Date.UTC().Format(time.RFC3339))
+	header("WARC-Target-URI", r.TargetURI)
+	for _, f := range r.Fields {
+		header(f.Name, f.Value)
+	}
+	header("WARC-Block-Digest", "sha1:"+base32.StdEncoding.EncodeToString(digest[:]))
+	header("Content-Type", r.ContentType)
+	header("Content-Length", strconv.Itoa(len(r.Block)))
+	buf.WriteString("\r\n")
+	buf.Write(r.Block)
+	buf.WriteString("\r\n\r\n")
+	if !w.compress {
+		_, err := w.w.Write(buf.Bytes())
+		return err
+	}
+	gz := gzip.NewWriter(w.w)
+	if _, err := gz.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return gz.Close()
+}
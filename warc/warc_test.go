@@ -0,0 +1,44 @@
+package warc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf, false)
+	r := &Record{
+		Type:        Resource,
+		ID:          ID("resource", "a"),
+		Date:        time.Unix(0, 0),
+		TargetURI:   "https://example.com/a",
+		ContentType: "text/plain",
+		Block:       []byte("hello"),
+	}
+	assert.NoError(t, w.Write(r))
+	s := buf.String()
+	assert.True(t, strings.HasPrefix(s, "WARC/1.1\r\nWARC-Type: resource\r\nWARC-Record-ID: <urn:uuid:"))
+	assert.Contains(t, s, "WARC-Date: 1970-01-01T00:00:00Z\r\n")
+	assert.Contains(t, s, "WARC-Block-Digest: sha1:")
+	assert.True(t, strings.HasSuffix(s, "Content-Length: 5\r\n\r\nhello\r\n\r\n"))
+	assert.Equal(t, ID("resource", "a"), r.ID)
+	assert.NotEqual(t, ID("resource", "b"), r.ID)
+	assert.Len(t, r.ID, len("<urn:uuid:>")+36)
+
+	gz := &bytes.Buffer{}
+	w = NewWriter(gz, true)
+	assert.NoError(t, w.Write(r))
+	assert.NoError(t, w.Write(r))
+	zr, err := gzip.NewReader(gz)
+	assert.NoError(t, err)
+	b, err := ioutil.ReadAll(zr)
+	assert.NoError(t, err)
+	assert.Equal(t, s+s, string(b))
+}
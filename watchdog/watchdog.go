@@ -0,0 +1,82 @@
+package watchdog
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+
+	"github.com/u-speak/core/metrics"
+	"github.com/u-speak/core/util"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrDiskFull is returned when a store is running out of space and writes are refused
+	ErrDiskFull = errors.New("Not enough free disk space, refusing to store new sites")
+)
+
+// Usage is the state of the filesystem containing a store
+type Usage struct {
+	Path  string `json:"path"`
+	Free  uint64 `json:"free"`
+	Total uint64 `json:"total"`
+	Low   bool   `json:"low"`
+}
+
+// Watchdog monitors the free space on the filesystems used by the stores
+type Watchdog struct {
+	paths   []string
+	minFree uint64
+	usage   []Usage
+	lock    sync.RWMutex
+}
+
+// New creates a watchdog for the given store files refusing writes when less than minFree bytes are left
+func New(minFree uint64, paths ...string) *Watchdog {
+	w := &Watchdog{minFree: minFree}
+	for _, p := range paths {
+		w.paths = append(w.paths, filepath.Dir(p))
+	}
+	w.Check()
+	return w
+}
+
+// Check refreshes the usage of all monitored paths
+func (w *Watchdog) Check() {
+	us := []Usage{}
+	for _, p := range w.paths {
+		free, total, err := util.DiskUsage(p)
+		if err != nil {
+			log.Errorf("Could not determine disk usage of %s: %s", p, err)
+			continue
+		}
+		metrics.DiskFree.WithLabelValues(p).Set(float64(free))
+		metrics.DiskTotal.WithLabelValues(p).Set(float64(total))
+		u := Usage{Path: p, Free: free, Total: total, Low: free < w.minFree}
+		if u.Low {
+			log.Warnf("Low disk space on %s: %d bytes left", p, free)
+		}
+		us = append(us, u)
+	}
+	w.lock.Lock()
+	w.usage = us
+	w.lock.Unlock()
+}
+
+// Usage returns the usage determined by the last check
+func (w *Watchdog) Usage() []Usage {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+	return append([]Usage{}, w.usage...)
+}
+
+// Writable returns ErrDiskFull if any monitored filesystem is below the threshold
+func (w *Watchdog) Writable() error {
+	for _, u := range w.Usage() {
+		if u.Low {
+			return ErrDiskFull
+		}
+	}
+	return nil
+}
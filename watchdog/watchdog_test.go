@@ -0,0 +1,22 @@
+package watchdog
+
+import (
+	"math"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWritable(t *testing.T) {
+	p := path.Join(os.TempDir(), "test.db")
+	w := New(0, p)
+	assert.Len(t, w.Usage(), 1)
+	assert.Equal(t, os.TempDir(), w.Usage()[0].Path)
+	assert.NoError(t, w.Writable())
+
+	w = New(math.MaxUint64, p)
+	assert.True(t, w.Usage()[0].Low)
+	assert.Equal(t, ErrDiskFull, w.Writable())
+}